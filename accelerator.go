@@ -0,0 +1,187 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// AcceleratorJob 描述一次批量迭代: 对 Values 中的每个元素各应用 Iterations 次 τ 或 τ⁻¹，Values 原地更新
+// GPU 等外部实现一般只根据 P 与 Perm.Name() 选择内核; 纯 Go 实现可以直接调用 Perm
+type AcceleratorJob struct {
+	P          *big.Int
+	Perm       Permutation
+	Iterations uint64
+	Values     []*big.Int
+}
+
+// Accelerator 是批量工作负载 (VerifyBatchFast 的逆向迭代、ComputeBatch 的多输入计算) 的执行后端
+// 单个值的迭代仍然是顺序的，加速器只负责把许多相互独立的值同时推进相同的次数，
+// 因此 GPU 实现只需要提供 F_p 上 τ 与 τ⁻¹ 的内核。通过 WithAccelerator 在运行时选择，
+// 不影响参数指纹与证明格式
+type Accelerator interface {
+	// Name 返回加速器的唯一名称，用于 RegisterAccelerator 与 LookupAccelerator
+	Name() string
+	// Supports 报告加速器是否支持模数 p 与名为 permutation 的置换; 不支持时 Sloth 退回 CPU 路径
+	Supports(p *big.Int, permutation string) bool
+	// Forward 对 job.Values 中的每个元素各应用 job.Iterations 次 τ
+	Forward(ctx context.Context, job *AcceleratorJob) error
+	// Inverse 对 job.Values 中的每个元素各应用 job.Iterations 次 τ⁻¹
+	Inverse(ctx context.Context, job *AcceleratorJob) error
+}
+
+// CPUAccelerator 是 Accelerator 的参考实现，用 Workers 个协程并行推进各个元素
+// Workers <= 0 时使用 CPU 核数。逆向迭代按 batchLanes 个一组使用置换的批量路径
+type CPUAccelerator struct {
+	Workers int
+}
+
+var _ Accelerator = (*CPUAccelerator)(nil)
+
+func (a *CPUAccelerator) Name() string {
+	return "cpu"
+}
+
+func (a *CPUAccelerator) Supports(p *big.Int, permutation string) bool {
+	return true
+}
+
+func (a *CPUAccelerator) Forward(ctx context.Context, job *AcceleratorJob) error {
+	return a.run(ctx, job, 1, func(ctx context.Context, ws []*big.Int) error {
+		return forwardN(ctx, job.Perm, ws[0], job.Iterations)
+	})
+}
+
+func (a *CPUAccelerator) Inverse(ctx context.Context, job *AcceleratorJob) error {
+	return a.run(ctx, job, batchLanes, func(ctx context.Context, ws []*big.Int) error {
+		return inverseN(ctx, job.Perm, ws, job.Iterations, new(big.Int))
+	})
+}
+
+// run 把 job.Values 每 size 个分为一组，交给工作协程执行 fn，返回遇到的第一个错误
+func (a *CPUAccelerator) run(ctx context.Context, job *AcceleratorJob, size int, fn func(context.Context, []*big.Int) error) error {
+	if job == nil || job.Perm == nil {
+		return errors.New("accelerator job is incomplete")
+	}
+	if err := checkIterations(job.Iterations); err != nil {
+		return err
+	}
+	workers := a.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	groups := make(chan []*big.Int)
+	var wg sync.WaitGroup
+	for range min(workers, (len(job.Values)+size-1)/size) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ws := range groups {
+				if err := fn(ctx, ws); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+	for vs := job.Values; len(vs) > 0; {
+		n := min(size, len(vs))
+		groups <- vs[:n]
+		vs = vs[n:]
+	}
+	close(groups)
+	wg.Wait()
+	return context.Cause(ctx)
+}
+
+// forwardN 原地对 w 应用 n 次 τ，定期检查 ctx
+func forwardN(ctx context.Context, perm Permutation, w *big.Int, n uint64) error {
+	tmp := new(big.Int)
+	for i := uint64(0); i < n; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		perm.Forward(w, tmp)
+	}
+	return nil
+}
+
+// inverseN 原地对 ws 中的每个元素应用 n 次 τ⁻¹，tmp 是临时缓冲区
+// 置换支持批量路径时 (Field256 后端) 每 ctxCheckInterval 次迭代才与 big.Int 转换一次
+func inverseN(ctx context.Context, perm Permutation, ws []*big.Int, n uint64, tmp *big.Int) error {
+	bi, batch := perm.(batchInverter)
+	for i := uint64(0); i < n; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		k := min(ctxCheckInterval, n-i)
+		if batch = batch && bi.inverseBatch(ws, k); !batch {
+			for _, w := range ws {
+				for range k {
+					perm.Inverse(w, tmp)
+				}
+			}
+		}
+		i += k
+	}
+	return nil
+}
+
+var accelerators = struct {
+	sync.RWMutex
+	m map[string]Accelerator
+}{m: map[string]Accelerator{"cpu": new(CPUAccelerator)}}
+
+// RegisterAccelerator 注册一个加速器，使其可以按名称在运行时选择 (例如由配置或命令行参数指定)
+// GPU 实现一般在自己的包的 init 中调用; 名称重复时返回 error
+func RegisterAccelerator(a Accelerator) error {
+	if a == nil {
+		return errors.New("accelerator cannot be nil")
+	}
+	accelerators.Lock()
+	defer accelerators.Unlock()
+	if _, ok := accelerators.m[a.Name()]; ok {
+		return fmt.Errorf("accelerator %q is already registered", a.Name())
+	}
+	accelerators.m[a.Name()] = a
+	return nil
+}
+
+// LookupAccelerator 按名称返回已注册的加速器，"cpu" 总是可用
+func LookupAccelerator(name string) (Accelerator, error) {
+	accelerators.RLock()
+	defer accelerators.RUnlock()
+	a, ok := accelerators.m[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown accelerator %q", name)
+	}
+	return a, nil
+}
+
+// Accelerators 返回所有已注册加速器的名称，按字典序排列
+func Accelerators() []string {
+	accelerators.RLock()
+	defer accelerators.RUnlock()
+	names := make([]string, 0, len(accelerators.m))
+	for name := range accelerators.m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// accelerator 返回配置的加速器，未配置或不支持当前参数时返回 nil
+func (s *Sloth) accelerator() Accelerator {
+	if s.accel == nil || !s.accel.Supports(s.P, s.perm.Name()) {
+		return nil
+	}
+	return s.accel
+}
@@ -0,0 +1,148 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// failingAccelerator 用于测试不支持的参数与失败的加速器
+type failingAccelerator struct {
+	supported bool
+	calls     int
+}
+
+func (a *failingAccelerator) Name() string                          { return "failing" }
+func (a *failingAccelerator) Supports(p *big.Int, perm string) bool { return a.supported }
+
+func (a *failingAccelerator) Forward(ctx context.Context, job *AcceleratorJob) error {
+	a.calls++
+	return errors.New("device lost")
+}
+
+func (a *failingAccelerator) Inverse(ctx context.Context, job *AcceleratorJob) error {
+	a.calls++
+	return errors.New("device lost")
+}
+
+// faultyAccelerator 在 CPUAccelerator 的结果中改动第 bad 个元素，模拟出错的设备
+type faultyAccelerator struct {
+	CPUAccelerator
+	bad int
+}
+
+func (a *faultyAccelerator) Name() string { return "faulty" }
+
+func (a *faultyAccelerator) Forward(ctx context.Context, job *AcceleratorJob) error {
+	if err := a.CPUAccelerator.Forward(ctx, job); err != nil {
+		return err
+	}
+	job.Values[a.bad].Add(job.Values[a.bad], big.NewInt(1))
+	return nil
+}
+
+// TestAccelerator 检查通过 CPUAccelerator 的批量计算与验证和普通路径的结果一致
+func TestAccelerator(t *testing.T) {
+	cpu, err := LookupAccelerator("cpu")
+	if err != nil {
+		t.Fatalf("LookupAccelerator failed: %v", err)
+	}
+	for _, p := range []*big.Int{testVDF.P, ParamSets()[0].Prime()} {
+		vdf, err := New(p, 200, WithAccelerator(cpu))
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		var inputs [][]byte
+		for i := range 9 {
+			inputs = append(inputs, []byte(fmt.Sprintf("accelerated %d", i)))
+		}
+		proofs, errs := vdf.ComputeBatch(inputs, 0)
+		batch := make([]Proof, len(proofs))
+		for i, input := range inputs {
+			if errs[i] != nil {
+				t.Fatalf("ComputeBatch[%d] failed: %v", i, errs[i])
+			}
+			want, _ := vdf.ComputeProof(input)
+			if proofs[i].Witness.Cmp(want.Witness) != 0 || !slices.Equal(proofs[i].Hash, want.Hash) {
+				t.Fatalf("ComputeBatch[%d] differs from ComputeProof", i)
+			}
+			batch[i] = *proofs[i]
+		}
+		inputs[4] = []byte("wrong input")
+		results, err := vdf.VerifyBatchFast(inputs, batch)
+		if err != nil {
+			t.Fatalf("VerifyBatchFast failed: %v", err)
+		}
+		for i, ok := range results {
+			if ok != (i != 4) {
+				t.Errorf("results[%d] = %v", i, ok)
+			}
+		}
+	}
+}
+
+// TestAcceleratorFallback 检查不支持当前参数的加速器不会被调用，失败的加速器会返回错误
+func TestAcceleratorFallback(t *testing.T) {
+	inputs := [][]byte{[]byte("a"), []byte("b")}
+	unsupported := &failingAccelerator{}
+	vdf, _ := New(testVDF.P, 100, WithAccelerator(unsupported))
+	proofs, errs := vdf.ComputeBatch(inputs, 0)
+	if errs[0] != nil || errs[1] != nil || unsupported.calls != 0 {
+		t.Fatalf("unsupported accelerator was used: %v, %d calls", errs, unsupported.calls)
+	}
+
+	failing := &failingAccelerator{supported: true}
+	vdf, _ = New(testVDF.P, 100, WithAccelerator(failing))
+	if _, errs := vdf.ComputeBatch(inputs, 0); errs[0] == nil || errs[1] == nil {
+		t.Error("expected ComputeBatch to report the accelerator error")
+	}
+	if _, err := vdf.VerifyBatchFast(inputs, []Proof{*proofs[0], *proofs[1]}); err == nil {
+		t.Error("expected VerifyBatchFast to report the accelerator error")
+	}
+
+	// 错误的结果只影响对应的输入
+	vdf, _ = New(testVDF.P, 100, WithAccelerator(&faultyAccelerator{bad: 1}))
+	proofs, errs = vdf.ComputeBatch(inputs, 0)
+	if errs[0] != nil || proofs[0] == nil || !errors.Is(errs[1], ErrReversalMismatch) || proofs[1] != nil {
+		t.Errorf("faulty accelerator result was not caught: %v", errs)
+	}
+}
+
+func TestRegisterAccelerator(t *testing.T) {
+	if err := RegisterAccelerator(new(CPUAccelerator)); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+	if err := RegisterAccelerator(nil); err == nil {
+		t.Error("expected error for nil accelerator")
+	}
+	if err := RegisterAccelerator(&failingAccelerator{}); err != nil {
+		t.Fatalf("RegisterAccelerator failed: %v", err)
+	}
+	t.Cleanup(func() {
+		accelerators.Lock()
+		delete(accelerators.m, "failing")
+		accelerators.Unlock()
+	})
+	if got := Accelerators(); !slices.Equal(got, []string{"cpu", "failing"}) {
+		t.Errorf("Accelerators() = %v", got)
+	}
+	if _, err := LookupAccelerator("cuda"); err == nil {
+		t.Error("expected error for unknown accelerator")
+	}
+}
+
+// TestCPUAcceleratorCancel 检查取消的 ctx 会中止加速器任务
+func TestCPUAcceleratorCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job := &AcceleratorJob{P: testVDF.P, Perm: testVDF.perm, Iterations: 10, Values: []*big.Int{big.NewInt(5)}}
+	if err := new(CPUAccelerator).Forward(ctx, job); !errors.Is(err, context.Canceled) {
+		t.Errorf("Forward err = %v, want context.Canceled", err)
+	}
+	if err := new(CPUAccelerator).Inverse(ctx, job); !errors.Is(err, context.Canceled) {
+		t.Errorf("Inverse err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package attest 让证明方用 Ed25519 对自己算出的证明签名
+// 多个运营方各自计算信标时，使用方可以据此把每一轮归属到具体的运营方与主机。
+// 签名只说明 "谁声称算出了这个结果"，结果本身是否正确仍然要验证 Sloth 证明。
+//
+// 签名的消息为
+//
+//	"slothgo/attest/v1" | 公钥(32) | 参数指纹长度(1) | 参数指纹 | 迭代次数(8) | 输出长度(1) | 输出
+//	| SHA-256(输入)(32) | 主机标识长度(2) | 主机标识 | 开始时间(8) | 完成时间(8)
+//
+// 整数均为大端，时间为 Unix 纳秒，零值时间编码为 0
+package attest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// signDomain 是签名消息的域分隔前缀
+const signDomain = "slothgo/attest/v1"
+
+// maxHostIDLen 是主机标识的最大字节数
+const maxHostIDLen = math.MaxUint16
+
+var (
+	// ErrBadSignature 表示签名与证明、元数据或公钥不符
+	ErrBadSignature = errors.New("attest: signature verification failed")
+	// ErrUnknownSigner 表示签名方不在信任的运营方之中
+	ErrUnknownSigner = errors.New("attest: signer is not a trusted operator")
+)
+
+// Attestation 是证明方对一个证明的签名声明
+type Attestation struct {
+	PublicKey ed25519.PublicKey // 签名方公钥
+	HostID    string            // 计算所在的主机，由运营方自行命名
+	Started   time.Time         // 计算开始的时间，未知时为零值
+	Finished  time.Time         // 计算完成 (签名) 的时间
+	Signature []byte
+}
+
+// Signer 持有运营方的私钥，可以在多个协程中共用
+type Signer struct {
+	key    ed25519.PrivateKey
+	hostID string
+	now    func() time.Time // 测试时替换
+}
+
+// NewSigner 使用私钥 key 与主机标识 hostID 创建签名方
+func NewSigner(key ed25519.PrivateKey, hostID string) (*Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("private key must be %d bytes", ed25519.PrivateKeySize)
+	}
+	if len(hostID) > maxHostIDLen {
+		return nil, fmt.Errorf("host ID exceeds %d bytes", maxHostIDLen)
+	}
+	return &Signer{key: key, hostID: hostID, now: time.Now}, nil
+}
+
+// PublicKey 返回签名方的公钥，应当通过可信的渠道公布给使用方
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign 声明 proof 是 input 在 started 开始的计算结果，完成时间取当前时间
+func (s *Signer) Sign(input []byte, proof *slothgo.Proof, started time.Time) (*Attestation, error) {
+	if proof == nil {
+		return nil, errors.New("proof cannot be nil")
+	}
+	a := &Attestation{
+		PublicKey: s.PublicKey(),
+		HostID:    s.hostID,
+		Started:   started,
+		Finished:  s.now(),
+	}
+	msg, err := a.message(input, proof)
+	if err != nil {
+		return nil, err
+	}
+	a.Signature = ed25519.Sign(s.key, msg)
+	return a, nil
+}
+
+// SignRound 对信标的一轮签名，输入为这一轮的种子
+func (s *Signer) SignRound(r *beacon.Round, started time.Time) (*Attestation, error) {
+	return s.Sign(r.Seed, &r.Proof, started)
+}
+
+// Verify 检查签名覆盖了 input 与 proof 以及 a 中的元数据，失败时返回 ErrBadSignature
+// 它不验证 Sloth 证明本身
+func (a *Attestation) Verify(input []byte, proof *slothgo.Proof) error {
+	if proof == nil {
+		return errors.New("proof cannot be nil")
+	}
+	if len(a.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: public key must be %d bytes", ErrBadSignature, ed25519.PublicKeySize)
+	}
+	msg, err := a.message(input, proof)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(a.PublicKey, msg, a.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// VerifyRound 检查签名覆盖了信标的第 r 轮
+func (a *Attestation) VerifyRound(r *beacon.Round) error {
+	return a.Verify(r.Seed, &r.Proof)
+}
+
+// message 返回被签名的字节串
+func (a *Attestation) message(input []byte, proof *slothgo.Proof) ([]byte, error) {
+	if len(proof.Fingerprint) > math.MaxUint8 || len(proof.Hash) > math.MaxUint8 {
+		return nil, errors.New("proof field too long")
+	}
+	if len(a.HostID) > maxHostIDLen {
+		return nil, fmt.Errorf("host ID exceeds %d bytes", maxHostIDLen)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(signDomain)
+	buf.Write(a.PublicKey)
+	buf.WriteByte(byte(len(proof.Fingerprint)))
+	buf.Write(proof.Fingerprint)
+	binary.Write(&buf, binary.BigEndian, proof.Iterations)
+	buf.WriteByte(byte(len(proof.Hash)))
+	buf.Write(proof.Hash)
+	digest := sha256.Sum256(input)
+	buf.Write(digest[:])
+	binary.Write(&buf, binary.BigEndian, uint16(len(a.HostID)))
+	buf.WriteString(a.HostID)
+	binary.Write(&buf, binary.BigEndian, unixNano(a.Started))
+	binary.Write(&buf, binary.BigEndian, unixNano(a.Finished))
+	return buf.Bytes(), nil
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// Operators 是使用方信任的运营方，键为运营方名称
+type Operators map[string]ed25519.PublicKey
+
+// Attribute 验证 a 对第 r 轮的签名，并返回签名方在 ops 中的名称
+// 签名无效时返回 ErrBadSignature，签名方不在 ops 中时返回 ErrUnknownSigner
+func (ops Operators) Attribute(a *Attestation, r *beacon.Round) (string, error) {
+	if err := a.VerifyRound(r); err != nil {
+		return "", err
+	}
+	for name, key := range ops {
+		if key.Equal(a.PublicKey) {
+			return name, nil
+		}
+	}
+	return "", ErrUnknownSigner
+}
+
+// attestationJSON 是 Attestation 的 JSON 表示，字节数组为十六进制
+type attestationJSON struct {
+	PublicKey string    `json:"public_key"`
+	HostID    string    `json:"host_id,omitempty"`
+	Started   time.Time `json:"started,omitzero"`
+	Finished  time.Time `json:"finished"`
+	Signature string    `json:"signature"`
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (a Attestation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(attestationJSON{
+		PublicKey: hex.EncodeToString(a.PublicKey),
+		HostID:    a.HostID,
+		Started:   a.Started,
+		Finished:  a.Finished,
+		Signature: hex.EncodeToString(a.Signature),
+	})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (a *Attestation) UnmarshalJSON(data []byte) error {
+	var v attestationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(v.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid hex in public_key: %w", err)
+	}
+	sig, err := hex.DecodeString(v.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid hex in signature: %w", err)
+	}
+	*a = Attestation{PublicKey: key, HostID: v.HostID, Started: v.Started, Finished: v.Finished, Signature: sig}
+	return nil
+}
+
+// LoadPrivateKey 读取 PEM 编码 (PKCS #8，"PRIVATE KEY") 的 Ed25519 私钥，
+// 例如 openssl genpkey -algorithm ed25519 生成的文件
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: no PRIVATE KEY block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// MarshalPrivateKey 把私钥编码为 LoadPrivateKey 读取的 PEM 格式
+func MarshalPrivateKey(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
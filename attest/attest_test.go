@@ -0,0 +1,172 @@
+package attest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+func newTestRound(t *testing.T) *beacon.Round {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	if _, err := b.Contribute([]byte("entropy")); err != nil {
+		t.Fatalf("Contribute failed: %v", err)
+	}
+	r, err := b.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	return r
+}
+
+func newTestSigner(t *testing.T, hostID string) *Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	s, err := NewSigner(key, hostID)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	return s
+}
+
+func TestSignRound(t *testing.T) {
+	r := newTestRound(t)
+	s := newTestSigner(t, "prover-1")
+	finished := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	s.now = func() time.Time { return finished }
+	started := finished.Add(-time.Minute)
+
+	a, err := s.SignRound(r, started)
+	if err != nil {
+		t.Fatalf("SignRound failed: %v", err)
+	}
+	if !a.Finished.Equal(finished) || a.HostID != "prover-1" {
+		t.Errorf("unexpected metadata: %+v", a)
+	}
+	if err := a.VerifyRound(r); err != nil {
+		t.Fatalf("VerifyRound failed: %v", err)
+	}
+
+	// 修改证明或任何元数据都会使签名失效
+	other := newTestSigner(t, "prover-1")
+	for name, mutate := range map[string]func(a *Attestation, r *beacon.Round){
+		"host":       func(a *Attestation, r *beacon.Round) { a.HostID = "prover-2" },
+		"started":    func(a *Attestation, r *beacon.Round) { a.Started = time.Time{} },
+		"finished":   func(a *Attestation, r *beacon.Round) { a.Finished = a.Finished.Add(time.Nanosecond) },
+		"key":        func(a *Attestation, r *beacon.Round) { a.PublicKey = other.PublicKey() },
+		"hash":       func(a *Attestation, r *beacon.Round) { r.Proof.Hash[0] ^= 1 },
+		"iterations": func(a *Attestation, r *beacon.Round) { r.Proof.Iterations++ },
+		"seed":       func(a *Attestation, r *beacon.Round) { r.Seed[0] ^= 1 },
+	} {
+		a2 := *a
+		r2 := *r
+		r2.Seed = append([]byte(nil), r.Seed...)
+		r2.Proof.Hash = append([]byte(nil), r.Proof.Hash...)
+		mutate(&a2, &r2)
+		if err := a2.VerifyRound(&r2); !errors.Is(err, ErrBadSignature) {
+			t.Errorf("%s: VerifyRound returned %v", name, err)
+		}
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	r := newTestRound(t)
+	alice := newTestSigner(t, "a1")
+	bob := newTestSigner(t, "b1")
+	ops := Operators{"alice": alice.PublicKey()}
+
+	a, err := alice.SignRound(r, time.Time{})
+	if err != nil {
+		t.Fatalf("SignRound failed: %v", err)
+	}
+	if name, err := ops.Attribute(a, r); err != nil || name != "alice" {
+		t.Errorf("Attribute returned %q, %v", name, err)
+	}
+	b, err := bob.SignRound(r, time.Time{})
+	if err != nil {
+		t.Fatalf("SignRound failed: %v", err)
+	}
+	if _, err := ops.Attribute(b, r); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("unknown signer: Attribute returned %v", err)
+	}
+	// 把别人的签名换成 alice 的公钥不能冒充 alice
+	b.PublicKey = alice.PublicKey()
+	if _, err := ops.Attribute(b, r); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("forged signer: Attribute returned %v", err)
+	}
+}
+
+func TestAttestationJSON(t *testing.T) {
+	r := newTestRound(t)
+	a, err := newTestSigner(t, "host").SignRound(r, time.Now())
+	if err != nil {
+		t.Fatalf("SignRound failed: %v", err)
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got Attestation
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := got.VerifyRound(r); err != nil {
+		t.Errorf("decoded attestation does not verify: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"public_key":"zz"}`), &got); err == nil {
+		t.Error("invalid hex was accepted")
+	}
+}
+
+func TestPrivateKeyPEM(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	data, err := MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "prover.pem")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPrivateKey(path)
+	if err != nil {
+		t.Fatalf("LoadPrivateKey failed: %v", err)
+	}
+	if !key.Equal(got) {
+		t.Error("loaded key differs")
+	}
+	if err := os.WriteFile(path, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrivateKey(path); err == nil {
+		t.Error("garbage was accepted")
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	if _, err := NewSigner(make(ed25519.PrivateKey, 10), "h"); err == nil {
+		t.Error("short key was accepted")
+	}
+}
@@ -0,0 +1,242 @@
+package slothgo
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// VerifyBatch 使用与 CPU 核数相同的工作协程并发验证多个证明
+// inputs[i] 是 proofs[i] 对应的原始输入，每个工作协程复用自己的 big.Int 缓冲区
+// 返回的切片中 results[i] 表示 proofs[i] 是否验证通过;
+// 只有在参数本身不合法 (例如长度不一致) 时才返回 error
+func (s *Sloth) VerifyBatch(inputs [][]byte, proofs []Proof) ([]bool, error) {
+	if len(inputs) != len(proofs) {
+		return nil, errors.New("inputs and proofs must have the same length")
+	}
+
+	results := make([]bool, len(proofs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(proofs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc := newScratch()
+			for i := range jobs {
+				results[i] = s.verifyProofScratch(inputs[i], &proofs[i], sc)
+			}
+		}()
+	}
+	for i := range proofs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+// batchLanes 是 VerifyBatchFast 中一个工作协程同时推进的证明个数
+const batchLanes = 4
+
+// VerifyBatchFast 与 VerifyBatch 的语义相同，但为验证大量证明的吞吐量做了优化:
+// 先用廉价的参数与哈希检查排除无效证明，再把剩下的证明每 batchLanes 个分为一组，
+// 由工作协程对同一组的逆向迭代逐步交错推进。后端为 group.Field256 且 c = -1 时，
+// 整组在定长表示上完成全部迭代，省去每一步与 big.Int 之间的转换 (256 位模数上约快 1.6 倍);
+// 其他后端逐个逆向迭代。
+// 带检查点或状态承诺的证明按 VerifyBatch 的方式单独验证。
+// 配置了 WithAccelerator 时逆向迭代由加速器完成，加速器失败时返回 error
+func (s *Sloth) VerifyBatchFast(inputs [][]byte, proofs []Proof) ([]bool, error) {
+	if len(inputs) != len(proofs) {
+		return nil, errors.New("inputs and proofs must have the same length")
+	}
+
+	results := make([]bool, len(proofs))
+	var lanes, single []int
+	for i := range proofs {
+		proof := &proofs[i]
+		switch {
+		case len(proof.Checkpoints) > 0 || proof.StateRoot != nil:
+			single = append(single, i)
+		case s.precheckProof(inputs[i], proof):
+			lanes = append(lanes, i)
+		}
+	}
+
+	// 配置了加速器时，所有通过预检查的证明作为一个任务交给它
+	if a := s.accelerator(); a != nil && len(lanes) > 0 {
+		ys := make([]*big.Int, len(lanes))
+		for j, i := range lanes {
+			ys[j] = new(big.Int).Set(proofs[i].Witness)
+		}
+		job := &AcceleratorJob{P: s.P, Perm: s.perm, Iterations: s.Iterations, Values: ys}
+		if err := a.Inverse(context.Background(), job); err != nil {
+			return nil, fmt.Errorf("accelerator %s failed: %w", a.Name(), err)
+		}
+		for j, i := range lanes {
+			results[i] = ys[j].Cmp(s.initialValue(inputs[i])) == 0
+		}
+		lanes = nil
+	}
+
+	// 每个任务要么是一个需要单独验证的证明，要么是一组同时逆向迭代的证明
+	type job struct {
+		single int
+		lanes  []int
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(single)+(len(lanes)+batchLanes-1)/batchLanes) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc := newScratch()
+			for j := range jobs {
+				if j.lanes == nil {
+					results[j.single] = s.verifyProofScratch(inputs[j.single], &proofs[j.single], sc)
+				} else {
+					s.verifyLanes(inputs, proofs, j.lanes, results, sc)
+				}
+			}
+		}()
+	}
+	for _, i := range single {
+		jobs <- job{single: i}
+	}
+	for len(lanes) > 0 {
+		n := min(batchLanes, len(lanes))
+		jobs <- job{lanes: lanes[:n]}
+		lanes = lanes[n:]
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+// precheckProof 检查证明的参数、输入与见证范围以及见证的哈希，不做逆向迭代
+func (s *Sloth) precheckProof(input []byte, proof *Proof) bool {
+	if input == nil || s.checkProofParams(proof) != nil || proof.Hash == nil {
+		return false
+	}
+	w := proof.Witness
+	if w == nil || w.Sign() < 0 || w.Cmp(s.P) >= 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare(proof.Hash, s.outputHash(w)) == 1
+}
+
+// verifyLanes 对 idx 中的证明同时做逆向迭代，并与各自输入的初始值比较
+func (s *Sloth) verifyLanes(inputs [][]byte, proofs []Proof, idx []int, results []bool, sc *scratch) {
+	ys := make([]*big.Int, len(idx))
+	for j, i := range idx {
+		ys[j] = new(big.Int).Set(proofs[i].Witness)
+	}
+	inverseN(context.Background(), s.perm, ys, s.Iterations, sc.tmp)
+	for j, i := range idx {
+		results[i] = ys[j].Cmp(s.initialValue(inputs[i])) == 0
+	}
+}
+
+// ComputeBatch 并发地对多个相互独立的输入执行 VDF 计算，最多同时运行 workers 个计算
+// workers <= 0 时使用 CPU 核数。每个计算本身仍然是顺序的
+// proofs[i] 与 errs[i] 对应 inputs[i]; 如果配置了进度回调，它会被多个协程并发调用。
+// 配置了 WithAccelerator 且未启用区段检查点时，全部输入作为一个任务交给加速器，此时不调用进度回调，
+// workers 只限制检查加速器结果的逆向迭代
+func (s *Sloth) ComputeBatch(inputs [][]byte, workers int) (proofs []*Proof, errs []error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if a := s.accelerator(); a != nil && s.segmentInterval == 0 {
+		return s.computeBatchAccel(a, inputs, workers)
+	}
+
+	proofs = make([]*Proof, len(inputs))
+	errs = make([]error, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				proofs[i], errs[i] = s.ComputeProof(inputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return proofs, errs
+}
+
+// computeBatchAccel 用加速器一次推进所有输入的初始值; 加速器失败时每个输入都返回该错误
+// 外部设备或进程可能出错，每个结果都在 CPU 上逆向迭代检查之后才组装为证明，不一致的结果作为该输入的错误返回
+func (s *Sloth) computeBatchAccel(a Accelerator, inputs [][]byte, workers int) (proofs []*Proof, errs []error) {
+	proofs = make([]*Proof, len(inputs))
+	errs = make([]error, len(inputs))
+	starts := make([]*big.Int, len(inputs))
+	ws := make([]*big.Int, len(inputs))
+	for i, input := range inputs {
+		starts[i] = s.initialValue(input)
+		ws[i] = new(big.Int).Set(starts[i])
+	}
+	job := &AcceleratorJob{P: s.P, Perm: s.perm, Iterations: s.Iterations, Values: ws}
+	if err := a.Forward(context.Background(), job); err != nil {
+		err = fmt.Errorf("accelerator %s failed: %w", a.Name(), err)
+		for i := range errs {
+			errs[i] = err
+		}
+		return proofs, errs
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(inputs)) {
+		wg.Go(func() {
+			sc := newScratch()
+			for i := range jobs {
+				w := ws[i]
+				if w == nil || w.Sign() < 0 || w.Cmp(s.P) >= 0 {
+					errs[i] = fmt.Errorf("accelerator %s returned an invalid result: %w", a.Name(), ErrWitnessOutOfRange)
+					continue
+				}
+				hash := s.outputHash(w)
+				if _, err := s.verifyFromScratch(context.Background(), starts[i], hash, w, sc); err != nil {
+					errs[i] = fmt.Errorf("accelerator %s returned an invalid result: %w", a.Name(), err)
+					continue
+				}
+				proofs[i] = s.NewProof(hash, w)
+			}
+		})
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return proofs, errs
+}
+
+// verifyProofScratch 使用给定的缓冲区验证单个证明，任何错误都视为验证失败
+func (s *Sloth) verifyProofScratch(input []byte, proof *Proof, sc *scratch) (ok bool) {
+	done := s.track(metrics.Verify)
+	defer func() { done(verifyOutcome(ok, nil)) }()
+
+	if input == nil || s.checkProofParams(proof) != nil {
+		return false
+	}
+	if len(proof.Checkpoints) > 0 || proof.StateRoot != nil {
+		valid, err := s.verifyProof(context.Background(), input, proof)
+		return valid && err == nil
+	}
+	valid, err := s.verifyFromScratch(context.Background(), s.initialValue(input), proof.Hash, proof.Witness, sc)
+	return valid && err == nil
+}
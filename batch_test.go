@@ -0,0 +1,133 @@
+package slothgo
+
+import (
+	"fmt"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/alan22333/sloth_go/group"
+)
+
+// TestVerifyBatch 检查批量验证能区分有效与无效的证明
+func TestVerifyBatch(t *testing.T) {
+	var inputs [][]byte
+	var proofs []Proof
+	for i := range 8 {
+		input := []byte(fmt.Sprintf("batch input %d", i))
+		proof, err := testVDF.ComputeProof(input)
+		if err != nil {
+			t.Fatalf("ComputeProof failed: %v", err)
+		}
+		inputs = append(inputs, input)
+		proofs = append(proofs, *proof)
+	}
+
+	// 篡改其中两个证明
+	proofs[2].Witness = new(big.Int).Add(proofs[2].Witness, big.NewInt(1))
+	inputs[5] = []byte("wrong input")
+
+	results, err := testVDF.VerifyBatch(inputs, proofs)
+	if err != nil {
+		t.Fatalf("VerifyBatch failed: %v", err)
+	}
+	for i, ok := range results {
+		want := i != 2 && i != 5
+		if ok != want {
+			t.Errorf("results[%d] = %v, want %v", i, ok, want)
+		}
+	}
+
+	if _, err := testVDF.VerifyBatch(inputs[:1], proofs); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+}
+
+// TestComputeBatch 检查批量计算的结果与逐个计算一致
+func TestComputeBatch(t *testing.T) {
+	inputs := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	proofs, errs := testVDF.ComputeBatch(inputs, 2)
+	for i, input := range inputs {
+		if errs[i] != nil {
+			t.Fatalf("ComputeBatch[%d] failed: %v", i, errs[i])
+		}
+		_, witness, err := testVDF.Compute(input)
+		if err != nil {
+			t.Fatalf("Compute failed: %v", err)
+		}
+		if proofs[i].Witness.Cmp(witness) != 0 {
+			t.Errorf("proofs[%d] differs from Compute", i)
+		}
+	}
+}
+
+// TestVerifyBatchFast 检查批量快速验证与 VerifyBatch 的结果一致，覆盖 Field256 的定长路径、
+// 论文置换、通用后端以及带检查点的证明
+func TestVerifyBatchFast(t *testing.T) {
+	p := ParamSets()[0].Prime()
+	pf, _ := group.NewPrimeField(p)
+	cases := map[string][]Option{
+		"field256":   nil,
+		"paper":      {WithPaperConformance()},
+		"primefield": {WithField(pf)},
+		"segmented":  {WithSegmentCheckpoints(16)},
+	}
+	for name, opts := range cases {
+		vdf, err := New(p, 64, opts...)
+		if err != nil {
+			t.Fatalf("%s: New failed: %v", name, err)
+		}
+		var inputs [][]byte
+		var proofs []Proof
+		for i := range 11 {
+			input := []byte(fmt.Sprintf("batch input %d", i))
+			proof, err := vdf.ComputeProof(input)
+			if err != nil {
+				t.Fatalf("%s: ComputeProof failed: %v", name, err)
+			}
+			inputs = append(inputs, input)
+			proofs = append(proofs, *proof)
+		}
+		// 篡改见证 (同时修正哈希，使其通过预检查)、输入与哈希
+		proofs[1].Witness = new(big.Int).Add(proofs[1].Witness, big.NewInt(1))
+		proofs[1].Hash = vdf.outputHash(proofs[1].Witness)
+		inputs[6] = []byte("wrong input")
+		proofs[9].Hash = append([]byte(nil), proofs[8].Hash...)
+
+		want, _ := vdf.VerifyBatch(inputs, proofs)
+		got, err := vdf.VerifyBatchFast(inputs, proofs)
+		if err != nil {
+			t.Fatalf("%s: VerifyBatchFast failed: %v", name, err)
+		}
+		for i := range got {
+			if got[i] != want[i] || got[i] != (i != 1 && i != 6 && i != 9) {
+				t.Errorf("%s: results[%d] = %v, VerifyBatch = %v", name, i, got[i], want[i])
+			}
+		}
+	}
+
+	if _, err := testVDF.VerifyBatchFast(nil, make([]Proof, 1)); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	vdf, _ := New(ParamSets()[0].Prime(), 10_000)
+	inputs := make([][]byte, 4*runtime.GOMAXPROCS(0))
+	proofs := make([]Proof, len(inputs))
+	for i := range inputs {
+		inputs[i] = []byte(fmt.Sprintf("bench %d", i))
+		proof, _ := vdf.ComputeProof(inputs[i])
+		proofs[i] = *proof
+	}
+	b.Run("VerifyBatch", func(b *testing.B) {
+		for b.Loop() {
+			vdf.VerifyBatch(inputs, proofs)
+		}
+	})
+	b.Run("VerifyBatchFast", func(b *testing.B) {
+		for b.Loop() {
+			vdf.VerifyBatchFast(inputs, proofs)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package beacon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// Aggregate 是一轮全部提交的 Merkle 聚合: Root 进入 Sloth 的种子，Receipts[i] 是第 i 个提交者的凭据
+// 提交上千时应一次生成全部凭据，而不是对每个提交者调用 Round.ContributionProof (每次都要重建整棵树)
+type Aggregate struct {
+	Round    uint64
+	Root     []byte
+	Receipts []Receipt
+}
+
+// Receipt 是提交者确认自己的熵参与了某一轮计算的凭据: 提交在 Merkle 树中的位置与包含证明
+// 提交者只需要保留自己的提交与凭据，再取回这一轮的根即可验证，不必下载其他人的提交
+type Receipt struct {
+	Round uint64   // 轮次序号
+	Index int      // 提交的位置
+	Size  int      // 本轮的提交总数
+	Path  [][]byte // 从叶子到根方向的兄弟节点哈希
+}
+
+// NewAggregate 聚合第 round 轮的提交，Root 与 merkle.Root(contributions) 相同
+func NewAggregate(round uint64, contributions [][]byte) *Aggregate {
+	tree := merkle.NewTree(contributions)
+	a := &Aggregate{Round: round, Root: tree.Root(), Receipts: make([]Receipt, len(contributions))}
+	for i, path := range tree.Proofs() {
+		a.Receipts[i] = Receipt{Round: round, Index: i, Size: len(contributions), Path: path}
+	}
+	return a
+}
+
+// Aggregate 返回已发布轮次的聚合，Root 应当等于 r.ContributionsRoot
+func (r *Round) Aggregate() *Aggregate {
+	return NewAggregate(r.Index, r.Contributions)
+}
+
+// Verify 检查 data 是凭据所在位置的提交，并且包含在根为 root 的树中
+func (rc *Receipt) Verify(root, data []byte) bool {
+	return merkle.Verify(root, data, rc.Index, rc.Size, rc.Path)
+}
+
+// VerifyReceipt 检查提交 data 与凭据 rc 确实属于 r。r 本身 (Sloth 证明与链接) 应当先由 Verify 验证
+func (r *Round) VerifyReceipt(rc *Receipt, data []byte) error {
+	if rc == nil {
+		return errors.New("receipt cannot be nil")
+	}
+	if rc.Round != r.Index {
+		return fmt.Errorf("receipt is for round %d, not %d", rc.Round, r.Index)
+	}
+	if !rc.Verify(r.ContributionsRoot, data) {
+		return errors.New("contribution is not included in the round")
+	}
+	return nil
+}
+
+// Find 返回 data 在本轮提交中第一次出现的位置，没有时返回 -1
+func (r *Round) Find(data []byte) int {
+	for i, c := range r.Contributions {
+		if bytes.Equal(c, data) {
+			return i
+		}
+	}
+	return -1
+}
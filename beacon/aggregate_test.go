@@ -0,0 +1,57 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	b := New(newTestVDF(t), NewMemoryStore())
+	var contributions [][]byte
+	for i := range 2000 {
+		data := []byte(fmt.Sprintf("user %d", i))
+		if _, err := b.Contribute(data); err != nil {
+			t.Fatal(err)
+		}
+		contributions = append(contributions, data)
+	}
+	r, err := b.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	a := r.Aggregate()
+	if !bytes.Equal(a.Root, r.ContributionsRoot) || a.Round != r.Index || len(a.Receipts) != len(contributions) {
+		t.Fatal("aggregate does not match the round")
+	}
+	for _, i := range []int{0, 1, 999, 1024, 1999} {
+		rc := &a.Receipts[i]
+		if err := r.VerifyReceipt(rc, contributions[i]); err != nil {
+			t.Errorf("receipt %d rejected: %v", i, err)
+		}
+		if err := r.VerifyReceipt(rc, []byte("forged")); err == nil {
+			t.Errorf("receipt %d accepted a forged contribution", i)
+		}
+		if got := r.Find(contributions[i]); got != i {
+			t.Errorf("Find returned %d, want %d", got, i)
+		}
+		// 凭据与逐个生成的包含证明相同
+		path, _ := r.ContributionProof(i)
+		if !VerifyContribution(r.ContributionsRoot, contributions[i], i, len(contributions), path) || len(path) != len(rc.Path) {
+			t.Errorf("receipt %d differs from ContributionProof", i)
+		}
+	}
+	other := a.Receipts[0]
+	other.Round++
+	if err := r.VerifyReceipt(&other, contributions[0]); err == nil {
+		t.Error("receipt for another round was accepted")
+	}
+	if r.Find([]byte("nobody")) != -1 {
+		t.Error("Find found a missing contribution")
+	}
+	if empty := NewAggregate(3, nil); len(empty.Receipts) != 0 || empty.Root == nil {
+		t.Error("empty aggregate is malformed")
+	}
+}
@@ -0,0 +1,199 @@
+// Package beacon 实现 Sloth 论文中的 Unicorn 风格随机信标
+// 每一轮分为三个阶段:
+//  1. 提交阶段: 任何人都可以提交一段熵，所有提交按顺序构成一棵 Merkle 树
+//  2. 计算阶段: 以 (轮次, 上一轮随机数, Merkle 根) 派生的种子作为输入计算 Sloth
+//  3. 发布阶段: Sloth 的输出哈希即为本轮随机数，连同证明写入存储
+//
+// 由于 Sloth 的计算时间超过提交阶段的长度，最后一个提交者也无法预知并操纵结果。
+// 提交阶段还可以改为提交-揭示 (见 CommitReveal)，截止前只公布承诺，进一步排除对提交内容的筛选
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// MaxContributionSize 是单个提交的最大字节数
+const MaxContributionSize = 1024
+
+// seedDomain 是种子派生的域分隔前缀
+const seedDomain = "slothgo/beacon/seed/v1"
+
+// Round 是已发布的一轮信标输出
+type Round struct {
+	Index             uint64        // 轮次序号，从 0 开始
+	Previous          []byte        // 上一轮的随机数，第 0 轮为空
+	Contributions     [][]byte      // 本轮收集的全部提交，按提交顺序排列
+	ContributionsRoot []byte        // Contributions 的 Merkle 根
+	Seed              []byte        // Sloth 的输入
+	Proof             slothgo.Proof // Sloth 的计算结果
+	Randomness        []byte        // 本轮随机数，等于 Proof.Hash
+
+	// Elapsed 是发布方报告的计算 Proof 的实际耗时，不受证明保护，只作为难度调整的输入
+	Elapsed time.Duration
+}
+
+// Beacon 收集提交并按轮次发布随机数
+type Beacon struct {
+	vdf   *slothgo.Sloth
+	store Store
+
+	// Difficulty 不为空时每一轮的迭代次数由它按之前各轮算出 (见 DifficultyController.IterationsAt)，
+	// 第 0 轮使用 vdf 的迭代次数。必须在第一次 Publish 之前设置，验证方需要使用相同的参数
+	Difficulty *DifficultyController
+
+	mu      sync.Mutex // 保护 pending
+	pending [][]byte
+
+	publishMu sync.Mutex // 保证同一时间只有一轮在计算
+}
+
+// New 使用给定的 Sloth 实例和存储后端创建信标
+func New(vdf *slothgo.Sloth, store Store) *Beacon {
+	return &Beacon{vdf: vdf, store: store}
+}
+
+// VDF 返回信标使用的 Sloth 实例
+func (b *Beacon) VDF() *slothgo.Sloth {
+	return b.vdf
+}
+
+// Store 返回信标使用的存储后端
+func (b *Beacon) Store() Store {
+	return b.store
+}
+
+// Contribute 为下一轮提交一段熵，返回它在本轮提交中的位置
+func (b *Beacon) Contribute(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, errors.New("contribution cannot be empty")
+	}
+	if len(data) > MaxContributionSize {
+		return 0, fmt.Errorf("contribution exceeds %d bytes", MaxContributionSize)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, bytes.Clone(data))
+	return len(b.pending) - 1, nil
+}
+
+// Pending 返回当前已收集、尚未发布的提交数量
+func (b *Beacon) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Publish 结束当前的提交阶段，计算并发布新的一轮
+// 计算失败 (例如 ctx 被取消) 时，已收集的提交会保留到下一次 Publish
+func (b *Beacon) Publish(ctx context.Context) (*Round, error) {
+	b.publishMu.Lock()
+	defer b.publishMu.Unlock()
+
+	r := &Round{}
+	latest, err := b.store.Latest()
+	switch {
+	case err == nil:
+		r.Index = latest.Index + 1
+		r.Previous = latest.Randomness
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	b.mu.Lock()
+	r.Contributions = b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	r.ContributionsRoot = merkle.Root(r.Contributions)
+	r.Seed = Seed(r.Index, r.Previous, r.ContributionsRoot)
+	vdf, err := b.roundVDF(r.Index)
+	if err == nil {
+		start := time.Now()
+		var proof *slothgo.Proof
+		if proof, err = vdf.ComputeProofCtx(ctx, r.Seed); err == nil {
+			r.Proof = *proof
+			r.Randomness = proof.Hash
+			r.Elapsed = time.Since(start)
+			err = b.store.Put(r)
+		}
+	}
+	if err != nil {
+		b.mu.Lock()
+		b.pending = append(r.Contributions, b.pending...)
+		b.mu.Unlock()
+		return nil, err
+	}
+	return r, nil
+}
+
+// roundVDF 返回计算第 index 轮所用的实例，设置了 Difficulty 时按它调整迭代次数
+func (b *Beacon) roundVDF(index uint64) (*slothgo.Sloth, error) {
+	if b.Difficulty == nil {
+		return b.vdf, nil
+	}
+	iterations, err := b.Difficulty.IterationsAt(b.store, b.vdf.Iterations, index)
+	if err != nil {
+		return nil, err
+	}
+	return b.vdf.WithIterations(iterations)
+}
+
+// Seed 计算第 index 轮的 Sloth 输入:
+// SHA-256(域分隔前缀 || index(8, 大端) || len(previous)(1) || previous || root)
+func Seed(index uint64, previous, root []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(seedDomain))
+	binary.Write(h, binary.BigEndian, index)
+	h.Write([]byte{byte(len(previous))})
+	h.Write(previous)
+	h.Write(root)
+	return h.Sum(nil)
+}
+
+// Verify 检查 r 是紧接在 prev 之后的合法一轮，prev 为 nil 表示 r 是第 0 轮
+func Verify(vdf *slothgo.Sloth, r, prev *Round) error {
+	if r == nil {
+		return errors.New("round cannot be nil")
+	}
+	if prev == nil {
+		if r.Index != 0 || len(r.Previous) != 0 {
+			return errors.New("first round must have index 0 and no previous randomness")
+		}
+	} else if r.Index != prev.Index+1 || !bytes.Equal(r.Previous, prev.Randomness) {
+		return fmt.Errorf("round %d does not follow round %d", r.Index, prev.Index)
+	}
+	if !bytes.Equal(r.ContributionsRoot, merkle.Root(r.Contributions)) {
+		return errors.New("contributions root does not match contributions")
+	}
+	if !bytes.Equal(r.Seed, Seed(r.Index, r.Previous, r.ContributionsRoot)) {
+		return errors.New("seed does not match round data")
+	}
+	if !bytes.Equal(r.Randomness, r.Proof.Hash) {
+		return errors.New("randomness does not match proof")
+	}
+	if ok, err := vdf.VerifyProof(r.Seed, &r.Proof); !ok {
+		return fmt.Errorf("round %d: %w", r.Index, err)
+	}
+	return nil
+}
+
+// ContributionProof 返回第 index 个提交在本轮 Merkle 树中的包含证明
+// 提交者可以据此确认自己的熵确实参与了本轮的计算。每次调用都会重建整棵树，需要全部证明时使用 Aggregate
+func (r *Round) ContributionProof(index int) ([][]byte, error) {
+	return merkle.NewTree(r.Contributions).Proof(index)
+}
+
+// VerifyContribution 检查 data 是第 index 个提交并包含在 root 对应的 size 个提交中
+func VerifyContribution(root, data []byte, index, size int, path [][]byte) bool {
+	return merkle.Verify(root, data, index, size, path)
+}
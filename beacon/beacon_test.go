@@ -0,0 +1,94 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+func TestBeacon(t *testing.T) {
+	vdf := newTestVDF(t)
+	store := NewMemoryStore()
+	b := New(vdf, store)
+	ctx := context.Background()
+
+	if _, err := store.Latest(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from empty store, got %v", err)
+	}
+
+	var rounds []*Round
+	for i, contributions := range [][]string{{"alice", "bob"}, {}, {"carol"}} {
+		for _, c := range contributions {
+			if _, err := b.Contribute([]byte(c)); err != nil {
+				t.Fatalf("Contribute failed: %v", err)
+			}
+		}
+		r, err := b.Publish(ctx)
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if r.Index != uint64(i) || len(r.Contributions) != len(contributions) {
+			t.Fatalf("round %d has index %d and %d contributions", i, r.Index, len(r.Contributions))
+		}
+		rounds = append(rounds, r)
+	}
+	if b.Pending() != 0 {
+		t.Errorf("expected no pending contributions, got %d", b.Pending())
+	}
+
+	var prev *Round
+	for _, r := range rounds {
+		if err := Verify(vdf, r, prev); err != nil {
+			t.Fatalf("Verify(round %d) failed: %v", r.Index, err)
+		}
+		prev = r
+	}
+	if err := Verify(vdf, rounds[2], rounds[0]); err == nil {
+		t.Error("expected round 2 not to follow round 0")
+	}
+
+	path, err := rounds[0].ContributionProof(1)
+	if err != nil {
+		t.Fatalf("ContributionProof failed: %v", err)
+	}
+	if !VerifyContribution(rounds[0].ContributionsRoot, []byte("bob"), 1, 2, path) {
+		t.Error("expected contribution to be included")
+	}
+
+	// 篡改提交会改变 Merkle 根
+	tampered := *rounds[0]
+	tampered.Contributions = [][]byte{[]byte("mallory"), []byte("bob")}
+	if err := Verify(vdf, &tampered, nil); err == nil {
+		t.Error("expected tampered contributions to be rejected")
+	}
+}
+
+func TestPublish_KeepsContributionsOnFailure(t *testing.T) {
+	b := New(newTestVDF(t), NewMemoryStore())
+	if _, err := b.Contribute([]byte("entropy")); err != nil {
+		t.Fatalf("Contribute failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Publish(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if b.Pending() != 1 {
+		t.Errorf("expected contribution to be kept, got %d pending", b.Pending())
+	}
+}
@@ -0,0 +1,241 @@
+package beacon
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 提交-揭示 (commit–reveal)
+//
+// 直接提交熵时，最后一个提交者在截止前可以看到其他人的提交，并尝试多个候选值后只提交对自己有利的一个
+// (grinding)。提交-揭示把一轮分为两个阶段: 截止前参与者只公布承诺 H(提交 || salt)，截止后再公布
+// 提交与 salt，只有与某个承诺匹配的揭示才会进入 Sloth 的输入。截止后承诺集合固定，参与者无法再改变
+// 自己的提交，唯一剩下的选择是不揭示，最多带来 1 比特的影响，而且拒不揭示的承诺对所有人可见
+
+// SaltSize 是揭示中 salt 的字节数
+const SaltSize = 32
+
+// MaxRevealedSize 是揭示的提交的最大字节数，编码后的 Opening 不超过 MaxContributionSize
+const MaxRevealedSize = MaxContributionSize - SaltSize
+
+// commitDomain 是承诺的域分隔前缀
+const commitDomain = "slothgo/beacon/commit/v1"
+
+// Opening 是一次揭示: 提交本身与承诺时使用的 salt
+type Opening struct {
+	Contribution []byte
+	Salt         []byte
+}
+
+// NewOpening 为 contribution 生成随机的 salt
+func NewOpening(contribution []byte) (*Opening, error) {
+	if err := checkRevealed(contribution); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &Opening{Contribution: bytes.Clone(contribution), Salt: salt}, nil
+}
+
+// Commitment 计算 SHA-256(域分隔前缀 || salt || contribution)，截止前公布的就是它
+func (o *Opening) Commitment() []byte {
+	h := sha256.New()
+	h.Write([]byte(commitDomain))
+	h.Write(o.Salt)
+	h.Write(o.Contribution)
+	return h.Sum(nil)
+}
+
+// MarshalBinary 返回 salt || contribution，这就是进入 Merkle 树的提交
+func (o *Opening) MarshalBinary() ([]byte, error) {
+	if len(o.Salt) != SaltSize {
+		return nil, fmt.Errorf("salt must be %d bytes", SaltSize)
+	}
+	if err := checkRevealed(o.Contribution); err != nil {
+		return nil, err
+	}
+	return append(bytes.Clone(o.Salt), o.Contribution...), nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出
+func (o *Opening) UnmarshalBinary(data []byte) error {
+	if len(data) <= SaltSize {
+		return errors.New("opening too short")
+	}
+	if err := checkRevealed(data[SaltSize:]); err != nil {
+		return err
+	}
+	o.Salt = bytes.Clone(data[:SaltSize])
+	o.Contribution = bytes.Clone(data[SaltSize:])
+	return nil
+}
+
+func checkRevealed(contribution []byte) error {
+	if len(contribution) == 0 {
+		return errors.New("contribution cannot be empty")
+	}
+	if len(contribution) > MaxRevealedSize {
+		return fmt.Errorf("contribution exceeds %d bytes", MaxRevealedSize)
+	}
+	return nil
+}
+
+// CommitReveal 收集一轮的承诺与揭示
+// 先以 Commit 收集承诺，Close 表示截止，之后以 Reveal 收集揭示，最后由 Openings 或
+// Beacon.ContributeOpenings 把匹配的揭示按承诺的顺序作为本轮的提交。CommitReveal 是并发安全的
+type CommitReveal struct {
+	mu          sync.Mutex
+	closed      bool
+	commitments [][]byte
+	index       map[string]int // 承诺 -> 在 commitments 中的位置
+	openings    [][]byte       // openings[i] 是第 i 个承诺的揭示编码，未揭示时为 nil
+}
+
+// NewCommitReveal 创建一个处于提交阶段的 CommitReveal
+func NewCommitReveal() *CommitReveal {
+	return &CommitReveal{index: make(map[string]int)}
+}
+
+// Commit 在截止前登记一个承诺，返回它的位置。重复的承诺会被拒绝
+func (c *CommitReveal) Commit(commitment []byte) (int, error) {
+	if len(commitment) != sha256.Size {
+		return 0, fmt.Errorf("commitment must be %d bytes", sha256.Size)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errors.New("commit phase is closed")
+	}
+	if _, ok := c.index[string(commitment)]; ok {
+		return 0, errors.New("duplicate commitment")
+	}
+	c.index[string(commitment)] = len(c.commitments)
+	c.commitments = append(c.commitments, bytes.Clone(commitment))
+	c.openings = append(c.openings, nil)
+	return len(c.commitments) - 1, nil
+}
+
+// Close 结束提交阶段，之后不再接受承诺，开始接受揭示。重复调用没有影响
+func (c *CommitReveal) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+// Reveal 揭示一个承诺，返回被揭示的承诺的位置
+// 截止前、没有匹配的承诺或者该承诺已经揭示过时返回错误
+func (c *CommitReveal) Reveal(o *Opening) (int, error) {
+	data, err := o.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	commitment := o.Commitment()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		return 0, errors.New("commit phase is still open")
+	}
+	i, ok := c.index[string(commitment)]
+	switch {
+	case !ok:
+		return 0, errors.New("opening does not match any commitment")
+	case c.openings[i] != nil:
+		return 0, errors.New("commitment was already revealed")
+	}
+	c.openings[i] = data
+	return i, nil
+}
+
+// Commitments 返回全部承诺，按登记的顺序排列。截止后应与本轮一起公布，供 VerifyOpenings 使用
+func (c *CommitReveal) Commitments() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return cloneAll(c.commitments)
+}
+
+// Unrevealed 返回尚未揭示的承诺的位置
+func (c *CommitReveal) Unrevealed() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var missing []int
+	for i, o := range c.openings {
+		if o == nil {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// Openings 返回已揭示的提交 (Opening 的二进制编码)，按承诺的顺序排列，截止前返回错误
+func (c *CommitReveal) Openings() ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		return nil, errors.New("commit phase is still open")
+	}
+	var out [][]byte
+	for _, o := range c.openings {
+		if o != nil {
+			out = append(out, bytes.Clone(o))
+		}
+	}
+	return out, nil
+}
+
+// ContributeOpenings 把 c 中已揭示的提交作为下一轮的提交，返回提交的个数
+func (b *Beacon) ContributeOpenings(c *CommitReveal) (int, error) {
+	openings, err := c.Openings()
+	if err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, openings...)
+	return len(openings), nil
+}
+
+// VerifyOpenings 检查 contributions (通常是 Round.Contributions) 中的每一项都是对 commitments 中某个
+// 承诺的揭示，并且按承诺的顺序排列、没有重复。返回未被揭示的承诺的位置
+func VerifyOpenings(commitments, contributions [][]byte) ([]int, error) {
+	index := make(map[string]int, len(commitments))
+	for i, c := range commitments {
+		index[string(c)] = i
+	}
+	var missing []int
+	next := 0 // 下一个可以被揭示的承诺的位置
+	for j, data := range contributions {
+		var o Opening
+		if err := o.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("contribution %d: %w", j, err)
+		}
+		i, ok := index[string(o.Commitment())]
+		if !ok {
+			return nil, fmt.Errorf("contribution %d does not match any commitment", j)
+		}
+		if i < next {
+			return nil, fmt.Errorf("contribution %d is out of order or repeated", j)
+		}
+		for ; next < i; next++ {
+			missing = append(missing, next)
+		}
+		next = i + 1
+	}
+	for ; next < len(commitments); next++ {
+		missing = append(missing, next)
+	}
+	return missing, nil
+}
+
+func cloneAll(items [][]byte) [][]byte {
+	out := make([][]byte, len(items))
+	for i, b := range items {
+		out[i] = bytes.Clone(b)
+	}
+	return out
+}
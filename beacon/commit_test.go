@@ -0,0 +1,110 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCommitReveal(t *testing.T) {
+	cr := NewCommitReveal()
+	var openings []*Opening
+	for _, c := range []string{"alice", "bob", "carol"} {
+		o, err := NewOpening([]byte(c))
+		if err != nil {
+			t.Fatalf("NewOpening failed: %v", err)
+		}
+		if _, err := cr.Commit(o.Commitment()); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		openings = append(openings, o)
+	}
+	if _, err := cr.Commit(openings[0].Commitment()); err == nil {
+		t.Error("duplicate commitment was accepted")
+	}
+	if _, err := cr.Reveal(openings[0]); err == nil {
+		t.Error("reveal before the cut-off was accepted")
+	}
+	if _, err := cr.Openings(); err == nil {
+		t.Error("Openings before the cut-off succeeded")
+	}
+
+	cr.Close()
+	late, _ := NewOpening([]byte("mallory"))
+	if _, err := cr.Commit(late.Commitment()); err == nil {
+		t.Error("commitment after the cut-off was accepted")
+	}
+	if _, err := cr.Reveal(late); err == nil {
+		t.Error("opening without a commitment was accepted")
+	}
+	// 改变提交或 salt 都不再匹配原来的承诺
+	changed := &Opening{Contribution: []byte("carol!"), Salt: openings[2].Salt}
+	if _, err := cr.Reveal(changed); err == nil {
+		t.Error("changed contribution was accepted")
+	}
+
+	// 揭示的顺序不影响结果，bob 没有揭示
+	for _, i := range []int{2, 0} {
+		if got, err := cr.Reveal(openings[i]); err != nil || got != i {
+			t.Fatalf("Reveal(%d) returned %d, %v", i, got, err)
+		}
+	}
+	if _, err := cr.Reveal(openings[0]); err == nil {
+		t.Error("repeated reveal was accepted")
+	}
+	if missing := cr.Unrevealed(); !slices.Equal(missing, []int{1}) {
+		t.Errorf("Unrevealed returned %v", missing)
+	}
+
+	b := New(newTestVDF(t), NewMemoryStore())
+	if n, err := b.ContributeOpenings(cr); err != nil || n != 2 {
+		t.Fatalf("ContributeOpenings returned %d, %v", n, err)
+	}
+	r, err := b.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	for j, i := range []int{0, 2} {
+		var o Opening
+		if err := o.UnmarshalBinary(r.Contributions[j]); err != nil || !bytes.Equal(o.Contribution, openings[i].Contribution) {
+			t.Errorf("contribution %d is %q, %v", j, o.Contribution, err)
+		}
+	}
+
+	commitments := cr.Commitments()
+	missing, err := VerifyOpenings(commitments, r.Contributions)
+	if err != nil || !slices.Equal(missing, []int{1}) {
+		t.Errorf("VerifyOpenings returned %v, %v", missing, err)
+	}
+	for name, contributions := range map[string][][]byte{
+		"reordered": {r.Contributions[1], r.Contributions[0]},
+		"repeated":  {r.Contributions[0], r.Contributions[0]},
+		"foreign":   {r.Contributions[0], []byte(string(make([]byte, SaltSize)) + "mallory")},
+		"too short": {make([]byte, SaltSize)},
+	} {
+		if _, err := VerifyOpenings(commitments, contributions); err == nil {
+			t.Errorf("%s contributions were accepted", name)
+		}
+	}
+}
+
+func TestOpeningLimits(t *testing.T) {
+	if _, err := NewOpening(nil); err == nil {
+		t.Error("empty contribution was accepted")
+	}
+	if _, err := NewOpening(make([]byte, MaxRevealedSize+1)); err == nil {
+		t.Error("oversized contribution was accepted")
+	}
+	o, err := NewOpening(make([]byte, MaxRevealedSize))
+	if err != nil {
+		t.Fatalf("NewOpening failed: %v", err)
+	}
+	data, err := o.MarshalBinary()
+	if err != nil || len(data) != MaxContributionSize {
+		t.Errorf("encoded opening has %d bytes, %v", len(data), err)
+	}
+	if _, err := (&Opening{Contribution: []byte("x"), Salt: []byte("short")}).MarshalBinary(); err == nil {
+		t.Error("short salt was accepted")
+	}
+}
@@ -0,0 +1,161 @@
+package beacon
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// DifficultyConfig 是难度调整控制器的参数，零值字段使用括号中的默认值
+type DifficultyConfig struct {
+	Target        time.Duration // 每一轮计算的目标时长，必须为正
+	Window        int           // 估计速度时使用的最近轮数 (8)
+	Damping       float64       // 每轮校正偏差的比例，取值 (0, 1]，1 表示一步到位 (0.25)
+	MaxAdjust     float64       // 单轮迭代次数最多变为原来的 MaxAdjust 倍或 1/MaxAdjust，必须 >= 1 (2)
+	MinIterations uint64        // 迭代次数的下限 (1)
+	MaxIterations uint64        // 迭代次数的上限 (slothgo.MaxIterations)
+}
+
+// RoundTiming 是一轮计算的迭代次数与实际耗时
+type RoundTiming struct {
+	Iterations uint64
+	Elapsed    time.Duration
+}
+
+// DifficultyController 根据最近几轮的实际耗时调整下一轮的迭代次数，使每轮计算保持在目标时长附近，
+// 类似区块链的难度调整: 用窗口内的总迭代次数除以总耗时估计速度，得到理想迭代次数后
+// 只按 Damping 的比例 (在对数尺度上) 向它靠拢，并限制单轮变化幅度与绝对上下限，
+// 避免个别异常轮次 (例如机器短暂过载) 造成剧烈震荡。可以被并发调用
+//
+// 信标通过 Schedule 使用控制器: 每一轮记录发布方报告的计算耗时 (Round.Elapsed)，
+// 下一轮的迭代次数只由之前各轮决定，发布方 (Beacon.Difficulty) 与验证方 (p2p.Chain.Difficulty)
+// 使用相同的参数即可对每个高度算出相同的迭代次数
+type DifficultyController struct {
+	cfg DifficultyConfig
+
+	mu      sync.Mutex
+	history []RoundTiming // 最近 cfg.Window 轮，按时间顺序
+}
+
+// NewDifficultyController 检查参数并创建控制器
+func NewDifficultyController(cfg DifficultyConfig) (*DifficultyController, error) {
+	if cfg.Target <= 0 {
+		return nil, errors.New("target duration must be positive")
+	}
+	if cfg.Window == 0 {
+		cfg.Window = 8
+	}
+	if cfg.Damping == 0 {
+		cfg.Damping = 0.25
+	}
+	if cfg.MaxAdjust == 0 {
+		cfg.MaxAdjust = 2
+	}
+	if cfg.MinIterations == 0 {
+		cfg.MinIterations = 1
+	}
+	if cfg.MaxIterations == 0 {
+		cfg.MaxIterations = slothgo.MaxIterations
+	}
+	switch {
+	case cfg.Window < 0:
+		return nil, errors.New("window must be positive")
+	case !(cfg.Damping > 0 && cfg.Damping <= 1):
+		return nil, errors.New("damping must be in (0, 1]")
+	case !(cfg.MaxAdjust >= 1) || math.IsInf(cfg.MaxAdjust, 1):
+		return nil, errors.New("max adjustment must be a finite factor of at least 1")
+	case cfg.MaxIterations > slothgo.MaxIterations || cfg.MinIterations > cfg.MaxIterations:
+		return nil, errors.New("invalid iteration bounds")
+	}
+	return &DifficultyController{cfg: cfg}, nil
+}
+
+// Config 返回补全默认值之后的参数
+func (c *DifficultyController) Config() DifficultyConfig {
+	return c.cfg
+}
+
+// Observe 记录一轮用 iterations 次迭代实际耗时 elapsed，为 0 或非正的观测值被忽略
+func (c *DifficultyController) Observe(iterations uint64, elapsed time.Duration) {
+	if iterations == 0 || elapsed <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = append(c.history, RoundTiming{Iterations: iterations, Elapsed: elapsed})
+	if len(c.history) > c.cfg.Window {
+		c.history = c.history[len(c.history)-c.cfg.Window:]
+	}
+}
+
+// Next 返回当前为 current 次迭代时下一轮应使用的迭代次数
+// 还没有观测值时只把 current 限制在上下限内
+func (c *DifficultyController) Next(current uint64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.next(c.history, current)
+}
+
+// Schedule 返回紧接在 recent 之后的一轮应使用的迭代次数，recent 是按序号排列的之前各轮，只使用最后 Window 轮
+// 没有之前的轮次时返回限制在上下限内的 initial。结果只取决于 recent 中的迭代次数与报告的耗时，
+// 不使用也不改变 Observe 记录的观测值
+func (c *DifficultyController) Schedule(initial uint64, recent []*Round) uint64 {
+	if len(recent) == 0 {
+		return c.next(nil, initial)
+	}
+	recent = recent[max(len(recent)-c.cfg.Window, 0):]
+	history := make([]RoundTiming, 0, len(recent))
+	for _, r := range recent {
+		if r.Proof.Iterations > 0 && r.Elapsed > 0 {
+			history = append(history, RoundTiming{Iterations: r.Proof.Iterations, Elapsed: r.Elapsed})
+		}
+	}
+	return c.next(history, recent[len(recent)-1].Proof.Iterations)
+}
+
+// IterationsAt 返回 s 中第 index 轮应使用的迭代次数，第 0 轮为 initial
+// 第 index 轮之前的 Window 轮必须都在 s 中
+func (c *DifficultyController) IterationsAt(s Store, initial, index uint64) (uint64, error) {
+	recent := make([]*Round, 0, c.cfg.Window)
+	for i := index - min(index, uint64(c.cfg.Window)); i < index; i++ {
+		r, err := s.Get(i)
+		if err != nil {
+			return 0, err
+		}
+		recent = append(recent, r)
+	}
+	return c.Schedule(initial, recent), nil
+}
+
+// next 是 Next 与 Schedule 共用的计算，按 history 估计速度
+func (c *DifficultyController) next(history []RoundTiming, current uint64) uint64 {
+	var iters, secs float64
+	for _, h := range history {
+		iters += float64(h.Iterations)
+		secs += h.Elapsed.Seconds()
+	}
+	next := float64(current)
+	if secs > 0 && current > 0 {
+		ideal := iters / secs * c.cfg.Target.Seconds()
+		factor := math.Pow(ideal/float64(current), c.cfg.Damping)
+		factor = min(max(factor, 1/c.cfg.MaxAdjust), c.cfg.MaxAdjust)
+		next = math.Round(float64(current) * factor)
+	}
+	switch {
+	case !(next >= float64(c.cfg.MinIterations)):
+		return c.cfg.MinIterations
+	case next >= float64(c.cfg.MaxIterations):
+		return c.cfg.MaxIterations
+	}
+	return uint64(next)
+}
+
+// Reset 丢弃所有观测值，例如更换硬件或参数之后
+func (c *DifficultyController) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = nil
+}
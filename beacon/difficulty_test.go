@@ -0,0 +1,139 @@
+package beacon
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// TestDifficultyConverges 模拟一台每秒 1000 次迭代的机器，检查迭代次数收敛到目标时长对应的值
+func TestDifficultyConverges(t *testing.T) {
+	c, err := NewDifficultyController(DifficultyConfig{Target: 10 * time.Second, Window: 4, Damping: 0.5})
+	if err != nil {
+		t.Fatalf("NewDifficultyController failed: %v", err)
+	}
+	const rate = 1000
+	n := uint64(500)
+	for range 30 {
+		elapsed := time.Duration(float64(n) / rate * float64(time.Second))
+		c.Observe(n, elapsed)
+		next := c.Next(n)
+		// 单轮最多变为 2 倍
+		if next > 2*n || 2*next < n {
+			t.Fatalf("adjustment %d -> %d exceeds the default bound", n, next)
+		}
+		n = next
+	}
+	if math.Abs(float64(n)-10*rate) > 10 {
+		t.Errorf("iterations converged to %d, want about %d", n, 10*rate)
+	}
+}
+
+// TestDifficultyDamping 检查单个异常轮次只造成有限的调整，且上下限生效
+func TestDifficultyDamping(t *testing.T) {
+	c, err := NewDifficultyController(DifficultyConfig{
+		Target:        time.Second,
+		Window:        8,
+		MinIterations: 100,
+		MaxIterations: 5000,
+	})
+	if err != nil {
+		t.Fatalf("NewDifficultyController failed: %v", err)
+	}
+	if got := c.Next(1000); got != 1000 {
+		t.Errorf("Next without observations = %d, want 1000", got)
+	}
+	if got := c.Next(10); got != 100 {
+		t.Errorf("Next(10) = %d, want the lower bound 100", got)
+	}
+
+	for range 7 {
+		c.Observe(1000, time.Second)
+	}
+	// 一轮因过载慢了 10 倍: 窗口平均后速度估计约下降一半，再经阻尼只减少约 17%
+	c.Observe(1000, 10*time.Second)
+	if got := c.Next(1000); got < 800 || got >= 1000 {
+		t.Errorf("Next after one slow round = %d, want a damped decrease", got)
+	}
+
+	c.Reset()
+	for range 8 {
+		c.Observe(1000, time.Millisecond)
+	}
+	if got := c.Next(4000); got != 5000 {
+		t.Errorf("Next = %d, want the upper bound 5000", got)
+	}
+
+	c.Observe(0, time.Second)
+	c.Observe(10, 0)
+	if cfg := c.Config(); cfg.Damping != 0.25 || cfg.MaxAdjust != 2 {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+}
+
+// TestDifficultyConfig 检查非法参数被拒绝
+func TestDifficultyConfig(t *testing.T) {
+	for _, cfg := range []DifficultyConfig{
+		{},
+		{Target: time.Second, Window: -1},
+		{Target: time.Second, Damping: 1.5},
+		{Target: time.Second, MaxAdjust: 0.5},
+		{Target: time.Second, MaxAdjust: math.Inf(1)},
+		{Target: time.Second, MinIterations: 10, MaxIterations: 5},
+	} {
+		if _, err := NewDifficultyController(cfg); err == nil {
+			t.Errorf("config %+v was accepted", cfg)
+		}
+	}
+}
+
+// TestDifficultySchedule 检查 Schedule 只按之前各轮报告的耗时计算，与 Observe 记录的观测值一致，
+// 并且信标按它调整每一轮的迭代次数
+func TestDifficultySchedule(t *testing.T) {
+	c, err := NewDifficultyController(DifficultyConfig{Target: time.Second, Window: 2, MaxIterations: 4000})
+	if err != nil {
+		t.Fatalf("NewDifficultyController failed: %v", err)
+	}
+	if got := c.Schedule(500, nil); got != 500 {
+		t.Errorf("Schedule without rounds = %d, want 500", got)
+	}
+	round := func(iterations uint64, elapsed time.Duration) *Round {
+		return &Round{Proof: slothgo.Proof{Iterations: iterations}, Elapsed: elapsed}
+	}
+	// 只使用最后两轮: 第 0 轮的耗时被忽略
+	recent := []*Round{round(1000, time.Hour), round(1000, 2*time.Second), round(1000, 2*time.Second)}
+	c.Observe(1000, 2*time.Second)
+	if got, want := c.Schedule(500, recent), c.Next(1000); got != want || got >= 1000 {
+		t.Errorf("Schedule = %d, Next = %d, want the same decrease", got, want)
+	}
+
+	vdf := newTestVDF(t)
+	store := NewMemoryStore()
+	b := New(vdf, store)
+	b.Difficulty, err = NewDifficultyController(DifficultyConfig{Target: time.Hour, MaxIterations: 4000})
+	if err != nil {
+		t.Fatalf("NewDifficultyController failed: %v", err)
+	}
+	// 计算远快于目标时长，每轮按 MaxAdjust 翻倍直到上限
+	var prev *Round
+	for i, want := range []uint64{500, 1000, 2000, 4000, 4000} {
+		r, err := b.Publish(context.Background())
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if r.Proof.Iterations != want || r.Elapsed <= 0 {
+			t.Fatalf("round %d used %d iterations in %v, want %d", i, r.Proof.Iterations, r.Elapsed, want)
+		}
+		if got, err := b.Difficulty.IterationsAt(store, vdf.Iterations, r.Index); err != nil || got != want {
+			t.Errorf("IterationsAt(%d) = %d, %v", r.Index, got, err)
+		}
+		v, _ := vdf.WithIterations(want)
+		if err := Verify(v, r, prev); err != nil {
+			t.Errorf("Verify(round %d) failed: %v", r.Index, err)
+		}
+		prev = r
+	}
+}
@@ -0,0 +1,90 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// DrandSchemeID 是 drand 格式输出中使用的方案标识
+const DrandSchemeID = "sloth-sha256-chained"
+
+// DrandRound 是 drand HTTP API (/public/{round}) 格式的一轮输出
+// Sloth 的 witness 充当 drand 中的 "签名"，使用默认的 SHA-256 时
+// randomness = SHA-256(signature) 与 drand 的约定一致。drand 的轮次从 1 开始，
+// 因此第 Index 轮对应 drand 的第 Index+1 轮。
+// 注意客户端若按 BLS 方案验证签名会失败，验证应使用 Verify
+type DrandRound struct {
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Signature         string `json:"signature"`
+	PreviousSignature string `json:"previous_signature,omitempty"`
+}
+
+// DrandInfo 是 drand HTTP API (/info) 格式的链信息
+type DrandInfo struct {
+	PublicKey   string        `json:"public_key"`
+	Period      int64         `json:"period"`
+	GenesisTime int64         `json:"genesis_time"`
+	Hash        string        `json:"hash"`
+	GroupHash   string        `json:"groupHash"`
+	SchemeID    string        `json:"schemeID"`
+	Metadata    DrandMetadata `json:"metadata"`
+}
+
+// DrandMetadata 是 DrandInfo 中的元数据
+type DrandMetadata struct {
+	BeaconID string `json:"beaconID"`
+}
+
+// ToDrand 把第 r 轮转换为 drand 格式，prev 是上一轮 (第 0 轮时为 nil)
+// 签名使用 witness 的定长编码，与计算 randomness 时的编码一致
+func ToDrand(vdf *slothgo.Sloth, r, prev *Round) DrandRound {
+	d := DrandRound{
+		Round:      r.Index + 1,
+		Randomness: hex.EncodeToString(r.Randomness),
+		Signature:  hex.EncodeToString(vdf.EncodeWitness(r.Proof.Witness)),
+	}
+	if prev != nil {
+		d.PreviousSignature = hex.EncodeToString(vdf.EncodeWitness(prev.Proof.Witness))
+	}
+	return d
+}
+
+// NewDrandInfo 生成信标的 drand 格式链信息
+// public_key 为素数 p 的大端字节, groupHash 为参数指纹, hash 是其余字段的 SHA-256
+func NewDrandInfo(vdf *slothgo.Sloth, beaconID string, period time.Duration, genesis time.Time) DrandInfo {
+	info := DrandInfo{
+		PublicKey:   hex.EncodeToString(vdf.P.Bytes()),
+		Period:      int64(period / time.Second),
+		GenesisTime: genesis.Unix(),
+		GroupHash:   hex.EncodeToString(vdf.Fingerprint()),
+		SchemeID:    DrandSchemeID,
+		Metadata:    DrandMetadata{BeaconID: beaconID},
+	}
+	data, _ := json.Marshal(info)
+	h := sha256.Sum256(data)
+	info.Hash = hex.EncodeToString(h[:])
+	return info
+}
+
+// DrandRoundFrom 从 store 中读取 drand 第 round 轮 (即第 round-1 轮) 并转换为 drand 格式
+func DrandRoundFrom(vdf *slothgo.Sloth, store Store, round uint64) (DrandRound, error) {
+	if round == 0 {
+		return DrandRound{}, ErrNotFound
+	}
+	r, err := store.Get(round - 1)
+	if err != nil {
+		return DrandRound{}, err
+	}
+	var prev *Round
+	if r.Index > 0 {
+		if prev, err = store.Get(r.Index - 1); err != nil {
+			return DrandRound{}, err
+		}
+	}
+	return ToDrand(vdf, r, prev), nil
+}
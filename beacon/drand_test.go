@@ -0,0 +1,46 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrand(t *testing.T) {
+	vdf := newTestVDF(t)
+	store := NewMemoryStore()
+	b := New(vdf, store)
+	for range 2 {
+		if _, err := b.Publish(context.Background()); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	d, err := DrandRoundFrom(vdf, store, 2)
+	if err != nil {
+		t.Fatalf("DrandRoundFrom failed: %v", err)
+	}
+	first, _ := DrandRoundFrom(vdf, store, 1)
+	if d.Round != 2 || d.PreviousSignature != first.Signature || first.PreviousSignature != "" {
+		t.Errorf("unexpected drand rounds: %+v, %+v", first, d)
+	}
+
+	// 与 drand 一致: randomness = SHA-256(signature)
+	sig, _ := hex.DecodeString(d.Signature)
+	h := sha256.Sum256(sig)
+	if d.Randomness != hex.EncodeToString(h[:]) {
+		t.Error("randomness is not the SHA-256 of the signature")
+	}
+
+	if _, err := DrandRoundFrom(vdf, store, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for round 0, got %v", err)
+	}
+
+	info := NewDrandInfo(vdf, "sloth", 30*time.Second, time.Unix(1700000000, 0))
+	if info.Period != 30 || info.GenesisTime != 1700000000 || info.Hash == "" {
+		t.Errorf("unexpected drand info: %+v", info)
+	}
+}
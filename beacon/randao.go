@@ -0,0 +1,155 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// RANDAO + VDF
+//
+// 以太坊的 RANDAO 混合值由每个区块的提议者依次混入，最后一个提议者可以选择出块或不出块，
+// 从而在两个结果之间挑选。研究原型中的做法是以一个 epoch 结束时的混合值作为 VDF 输入，
+// 延迟超过提议者能够决策的时间，VDF 输出再作为之后 epoch 的种子。这里的布局沿用共识规范的约定:
+// uint64 按 SSZ 小端编码 (uint_to_bytes)，JSON 中的 uint64 是十进制字符串，字节串是带 0x 前缀的十六进制
+
+// randaoDomain 是 RANDAO 输入派生的域分隔前缀
+const randaoDomain = "slothgo/beacon/randao/v1"
+
+// RandaoMixSize 是 RANDAO 混合值的字节数
+const RandaoMixSize = 32
+
+// RandaoOutput 是一个 epoch 的 VDF 结果
+type RandaoOutput struct {
+	Epoch  uint64              // 混合值所属的 epoch
+	Mix    [RandaoMixSize]byte // 该 epoch 结束时的 RANDAO 混合值
+	Output []byte              // VDF 输出，Sloth 时为最终哈希
+	Proof  []byte              // VDF 证明，Sloth 时为 witness 的定长编码
+}
+
+// RandaoInput 返回 VDF 的输入: SHA-256(域分隔前缀 || uint_to_bytes(epoch) || mix)
+// epoch 进入输入，同一个混合值在不同 epoch 得到不同的结果，结果也不能挪用到其他 epoch
+func RandaoInput(epoch uint64, mix [RandaoMixSize]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(randaoDomain))
+	binary.Write(h, binary.LittleEndian, epoch)
+	h.Write(mix[:])
+	return h.Sum(nil)
+}
+
+// ComputeRandao 以第 epoch 个 epoch 的混合值运行 vdf 的延迟
+func ComputeRandao(ctx context.Context, vdf slothgo.VDF, epoch uint64, mix [RandaoMixSize]byte) (*RandaoOutput, error) {
+	output, proof, err := vdf.Evaluate(ctx, RandaoInput(epoch, mix))
+	if err != nil {
+		return nil, err
+	}
+	return &RandaoOutput{Epoch: epoch, Mix: mix, Output: output, Proof: proof}, nil
+}
+
+// VerifyRandao 检查 out 是其混合值与 epoch 的正确 VDF 结果
+func VerifyRandao(ctx context.Context, vdf slothgo.VDF, out *RandaoOutput) error {
+	if out == nil {
+		return errors.New("randao output cannot be nil")
+	}
+	ok, err := vdf.VerifyEvaluation(ctx, RandaoInput(out.Epoch, out.Mix), out.Output, out.Proof)
+	if !ok {
+		if err == nil {
+			err = errors.New("vdf output does not verify")
+		}
+		return fmt.Errorf("epoch %d: %w", out.Epoch, err)
+	}
+	return nil
+}
+
+// Seed 仿照共识规范的 get_seed 派生种子: SHA-256(domainType || uint_to_bytes(epoch) || output)
+// 与规范的区别是以 VDF 输出代替 RANDAO 混合值
+func (o *RandaoOutput) Seed(domainType [4]byte) [32]byte {
+	data := make([]byte, 0, 4+8+len(o.Output))
+	data = append(data, domainType[:]...)
+	data = binary.LittleEndian.AppendUint64(data, o.Epoch)
+	data = append(data, o.Output...)
+	return sha256.Sum256(data)
+}
+
+// randaoJSON 是 RandaoOutput 的 JSON 布局
+type randaoJSON struct {
+	Epoch     string `json:"epoch"`
+	RandaoMix string `json:"randao_mix"`
+	VDFInput  string `json:"vdf_input"`
+	VDFOutput string `json:"vdf_output"`
+	VDFProof  string `json:"vdf_proof"`
+}
+
+// MarshalJSON 实现 json.Marshaler，vdf_input 只供参考，解码时重新计算并检查
+func (o RandaoOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(randaoJSON{
+		Epoch:     strconv.FormatUint(o.Epoch, 10),
+		RandaoMix: hex0x(o.Mix[:]),
+		VDFInput:  hex0x(RandaoInput(o.Epoch, o.Mix)),
+		VDFOutput: hex0x(o.Output),
+		VDFProof:  hex0x(o.Proof),
+	})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (o *RandaoOutput) UnmarshalJSON(data []byte) error {
+	var v randaoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	epoch, err := strconv.ParseUint(v.Epoch, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid epoch: %w", err)
+	}
+	mix, err := parseHex0x("randao_mix", v.RandaoMix)
+	if err != nil {
+		return err
+	}
+	if len(mix) != RandaoMixSize {
+		return fmt.Errorf("randao_mix must be %d bytes, got %d", RandaoMixSize, len(mix))
+	}
+	out := RandaoOutput{Epoch: epoch}
+	copy(out.Mix[:], mix)
+	if v.VDFInput != "" {
+		input, err := parseHex0x("vdf_input", v.VDFInput)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(input, RandaoInput(out.Epoch, out.Mix)) {
+			return errors.New("vdf_input does not match epoch and randao_mix")
+		}
+	}
+	if out.Output, err = parseHex0x("vdf_output", v.VDFOutput); err != nil {
+		return err
+	}
+	if out.Proof, err = parseHex0x("vdf_proof", v.VDFProof); err != nil {
+		return err
+	}
+	*o = out
+	return nil
+}
+
+func hex0x(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func parseHex0x(field, s string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(s, "0x")
+	if !ok {
+		return nil, fmt.Errorf("%s must start with 0x", field)
+	}
+	b, err := hex.DecodeString(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return b, nil
+}
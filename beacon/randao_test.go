@@ -0,0 +1,61 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRandao(t *testing.T) {
+	ctx := context.Background()
+	vdf := newTestVDF(t)
+	var mix [RandaoMixSize]byte
+	copy(mix[:], "randao mix of epoch 41")
+
+	out, err := ComputeRandao(ctx, vdf, 41, mix)
+	if err != nil {
+		t.Fatalf("ComputeRandao failed: %v", err)
+	}
+	if err := VerifyRandao(ctx, vdf, out); err != nil {
+		t.Fatalf("VerifyRandao failed: %v", err)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"epoch":"41"`) || !strings.Contains(string(data), `"randao_mix":"0x72616e64`) {
+		t.Errorf("unexpected layout: %s", data)
+	}
+	var decoded RandaoOutput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := VerifyRandao(ctx, vdf, &decoded); err != nil {
+		t.Errorf("decoded output does not verify: %v", err)
+	}
+	if decoded.Seed([4]byte{7}) != out.Seed([4]byte{7}) || out.Seed([4]byte{7}) == out.Seed([4]byte{8}) {
+		t.Error("seed does not depend on exactly the domain type and output")
+	}
+
+	// epoch 绑定: 同一结果不能用于另一个 epoch
+	moved := *out
+	moved.Epoch++
+	if VerifyRandao(ctx, vdf, &moved) == nil {
+		t.Error("output verified for a different epoch")
+	}
+	edited := strings.Replace(string(data), `"epoch":"41"`, `"epoch":"42"`, 1)
+	if json.Unmarshal([]byte(edited), &decoded) == nil {
+		t.Error("vdf_input of another epoch was accepted")
+	}
+	for _, bad := range []string{
+		`{"epoch":"x","randao_mix":"0x00","vdf_output":"0x","vdf_proof":"0x"}`,
+		`{"epoch":"1","randao_mix":"0x00","vdf_output":"0x","vdf_proof":"0x"}`,
+		`{"epoch":"1","randao_mix":"` + strings.Repeat("00", 32) + `","vdf_output":"0x","vdf_proof":"0x"}`,
+	} {
+		if json.Unmarshal([]byte(bad), &decoded) == nil {
+			t.Errorf("invalid output was accepted: %s", bad)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFound 表示请求的轮次不存在
+var ErrNotFound = errors.New("beacon: round not found")
+
+// Store 是已发布轮次的存储后端，实现必须可以被并发调用
+type Store interface {
+	// Put 保存一个新发布的轮次，轮次必须按序号连续写入
+	Put(r *Round) error
+	// Get 返回第 index 轮，不存在时返回 ErrNotFound
+	Get(index uint64) (*Round, error)
+	// Latest 返回最新的一轮，还没有任何轮次时返回 ErrNotFound
+	Latest() (*Round, error)
+}
+
+// MemoryStore 是保存在内存中的 Store 实现
+type MemoryStore struct {
+	mu     sync.RWMutex
+	rounds []*Round
+}
+
+// NewMemoryStore 创建一个空的内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Put 实现 Store 接口
+func (m *MemoryStore) Put(r *Round) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r.Index != uint64(len(m.rounds)) {
+		return fmt.Errorf("beacon: expected round %d, got %d", len(m.rounds), r.Index)
+	}
+	m.rounds = append(m.rounds, r)
+	return nil
+}
+
+// Get 实现 Store 接口
+func (m *MemoryStore) Get(index uint64) (*Round, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if index >= uint64(len(m.rounds)) {
+		return nil, ErrNotFound
+	}
+	return m.rounds[index], nil
+}
+
+// Latest 实现 Store 接口
+func (m *MemoryStore) Latest() (*Round, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.rounds) == 0 {
+		return nil, ErrNotFound
+	}
+	return m.rounds[len(m.rounds)-1], nil
+}
@@ -0,0 +1,119 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// calibrationChunk 是测量时两次读取时钟之间的迭代次数，使 time.Now 的开销可以忽略
+const calibrationChunk = 256
+
+// Calibration 是在本机上测得的 τ 与 τ⁻¹ 的吞吐量 (每秒迭代次数)
+// 测量结果只对测量时使用的模数、置换与算术后端有效
+type Calibration struct {
+	P           *big.Int
+	Permutation string
+	ForwardRate float64 // 计算方向 τ 的每秒迭代次数
+	InverseRate float64 // 验证方向 τ⁻¹ 的每秒迭代次数
+}
+
+// Calibrate 在模 p 上分别用大约 sampleDuration 的时间测量 τ 与 τ⁻¹ 的单核吞吐量，
+// 代替部署时反复试错来确定迭代次数，例如:
+//
+//	c, _ := slothgo.Calibrate(p, time.Second)
+//	n := c.IterationsFor(30 * time.Second) // 本机上大约 30 秒的迭代次数
+//
+// opts 与 New 相同，用于选择置换与算术后端; 测量在调用协程上顺序进行，耗时约为 2·sampleDuration
+func Calibrate(p *big.Int, sampleDuration time.Duration, opts ...Option) (*Calibration, error) {
+	if sampleDuration <= 0 {
+		return nil, errors.New("sample duration must be positive")
+	}
+	s, err := New(p, 1, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := s.initialValue([]byte("sloth calibration"))
+	tmp := new(big.Int)
+	forward := measureRate(sampleDuration, func() {
+		for range calibrationChunk {
+			s.perm.Forward(w, tmp)
+		}
+	})
+	// 与 Verify 相同，逆向迭代经由 inverseN，Field256 后端上会使用定长表示的批量路径
+	lane := []*big.Int{w}
+	inverse := measureRate(sampleDuration, func() {
+		inverseN(context.Background(), s.perm, lane, calibrationChunk, tmp)
+	})
+
+	return &Calibration{
+		P:           new(big.Int).Set(s.P),
+		Permutation: s.perm.Name(),
+		ForwardRate: forward,
+		InverseRate: inverse,
+	}, nil
+}
+
+// measureRate 反复执行 chunk (每次 calibrationChunk 次迭代) 直到用完 d，返回每秒迭代次数
+// 先执行一次不计时的 chunk 作为预热
+func measureRate(d time.Duration, chunk func()) float64 {
+	chunk()
+	start := time.Now()
+	var n uint64
+	for {
+		chunk()
+		n += calibrationChunk
+		if elapsed := time.Since(start); elapsed >= d {
+			return float64(n) / elapsed.Seconds()
+		}
+	}
+}
+
+// IterationsFor 返回在本机上计算大约需要 d 的迭代次数，结果被限制在 [1, MaxIterations] 内
+func (c *Calibration) IterationsFor(d time.Duration) uint64 {
+	n := math.Round(c.ForwardRate * d.Seconds())
+	switch {
+	case !(n >= 1):
+		return 1
+	case n >= float64(MaxIterations):
+		return MaxIterations
+	}
+	return uint64(n)
+}
+
+// EstimateComputeTime 估计在本机上计算 iterations 次迭代的耗时
+func (c *Calibration) EstimateComputeTime(iterations uint64) time.Duration {
+	return rateDuration(iterations, c.ForwardRate)
+}
+
+// EstimateVerifyTime 估计在本机上验证 iterations 次迭代的耗时
+func (c *Calibration) EstimateVerifyTime(iterations uint64) time.Duration {
+	return rateDuration(iterations, c.InverseRate)
+}
+
+// rateDuration 返回以每秒 rate 次的速度完成 n 次迭代所需的时间，超出 time.Duration 范围时取最大值
+func rateDuration(n uint64, rate float64) time.Duration {
+	if n == 0 || !(rate > 0) {
+		return 0
+	}
+	d := float64(n) / rate * float64(time.Second)
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// Asymmetry 返回验证相对计算的加速比 (τ⁻¹ 与 τ 的吞吐量之比)
+func (c *Calibration) Asymmetry() float64 {
+	return c.InverseRate / c.ForwardRate
+}
+
+// String 返回便于日志输出的摘要
+func (c *Calibration) String() string {
+	return fmt.Sprintf("%d-bit %s: %.0f forward/s, %.0f inverse/s (%.1fx)",
+		c.P.BitLen(), c.Permutation, c.ForwardRate, c.InverseRate, c.Asymmetry())
+}
@@ -0,0 +1,54 @@
+package slothgo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestCalibrate 检查测得的吞吐量为正，且按推荐迭代次数计算的耗时与目标相符
+func TestCalibrate(t *testing.T) {
+	c, err := Calibrate(testVDF.P, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Calibrate failed: %v", err)
+	}
+	t.Log(c)
+	if c.ForwardRate <= 0 || c.InverseRate <= 0 || c.Permutation != "sqrt" {
+		t.Fatalf("unexpected calibration %+v", c)
+	}
+	// 验证只需平方，总是比计算快
+	if c.Asymmetry() <= 1 {
+		t.Errorf("inverse is not faster than forward: %v", c)
+	}
+
+	n := c.IterationsFor(50 * time.Millisecond)
+	vdf, _ := New(testVDF.P, n)
+	start := time.Now()
+	if _, _, err := vdf.Compute(testInput); err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	// 只检查数量级，避免在繁忙的 CI 机器上误报
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("%d iterations took %v, want about 50ms", n, elapsed)
+	}
+
+	if got := c.IterationsFor(0); got != 1 {
+		t.Errorf("IterationsFor(0) = %d, want 1", got)
+	}
+	fast := &Calibration{P: testVDF.P, ForwardRate: 1e12, InverseRate: 1e13}
+	if got := fast.IterationsFor(time.Duration(math.MaxInt64)); got != MaxIterations {
+		t.Errorf("IterationsFor(huge) = %d, want MaxIterations", got)
+	}
+	if got := fast.EstimateComputeTime(3e12); got != 3*time.Second {
+		t.Errorf("EstimateComputeTime = %v, want 3s", got)
+	}
+	if got := fast.EstimateVerifyTime(5e12); got != 500*time.Millisecond {
+		t.Errorf("EstimateVerifyTime = %v, want 500ms", got)
+	}
+	if got := (&Calibration{ForwardRate: 1e-9}).EstimateComputeTime(MaxIterations); got != math.MaxInt64 {
+		t.Errorf("EstimateComputeTime overflowed to %v", got)
+	}
+	if _, err := Calibrate(testVDF.P, 0); err == nil {
+		t.Error("expected error for zero sample duration")
+	}
+}
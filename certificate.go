@@ -0,0 +1,166 @@
+package slothgo
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// certBaseBits 是证书链底部素数的最大位数，这样的素数直接用试除验证
+const certBaseBits = 32
+
+// CertificateStep 是 Pocklington 证书链中的一步
+// P - 1 被上一步的素数 q 整除且 q² > P，A 是满足 Pocklington 判据的底数
+type CertificateStep struct {
+	P *big.Int
+	A *big.Int
+}
+
+// PrimeCertificate 是一条递归的 Pocklington 证书链
+// Steps[0].P 不超过 32 位，由验证方试除; 之后每一步都以前一步的素数作为 P - 1 的大素因子，
+// 最后一步的 P 就是被证明的素数。验证只需要每步两次模幂，不依赖任何概率性检验
+type PrimeCertificate struct {
+	Steps []CertificateStep
+}
+
+// Prime 返回证书所证明的素数
+func (c *PrimeCertificate) Prime() *big.Int {
+	if c == nil || len(c.Steps) == 0 {
+		return nil
+	}
+	return new(big.Int).Set(c.Steps[len(c.Steps)-1].P)
+}
+
+// GenerateCertifiedPrime 生成一个 bits 位、满足 p ≡ 3 (mod 4) 的素数及其 Pocklington 证书
+// 素数按 p = 2·R·q + 1 (R 为奇数，q 为已证明的约 bits/2 位素数) 递归构造，
+// 因此只有用本函数生成的素数才有证书; 任意给定的素数一般无法有效地分解 p - 1
+func GenerateCertifiedPrime(bits int) (*big.Int, *PrimeCertificate, error) {
+	if bits < 3 {
+		return nil, nil, errors.New("prime size must be at least 3 bits")
+	}
+	cert := new(PrimeCertificate)
+	if err := cert.grow(bits); err != nil {
+		return nil, nil, err
+	}
+	// 较大的素数由构造保证 p ≡ 3 (mod 4)，只有直接生成的小素数需要重试
+	for cert.Steps[0].P.Bit(1) == 0 && bits <= certBaseBits {
+		cert.Steps = nil
+		if err := cert.grow(bits); err != nil {
+			return nil, nil, err
+		}
+	}
+	return cert.Prime(), cert, nil
+}
+
+// grow 递归地把一个 bits 位的素数追加到证书链末尾
+func (c *PrimeCertificate) grow(bits int) error {
+	if bits <= certBaseBits {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			return fmt.Errorf("failed to generate base prime: %w", err)
+		}
+		c.Steps = append(c.Steps, CertificateStep{P: p})
+		return nil
+	}
+	// q 有 ⌈bits/2⌉ + 1 位，q² ≥ 2^bits > p
+	if err := c.grow((bits+1)/2 + 1); err != nil {
+		return err
+	}
+	q := c.Steps[len(c.Steps)-1].P
+	twoQ := new(big.Int).Lsh(q, 1)
+
+	// R ∈ [lo, lo + span)，使 p = 2·R·q + 1 恰好有 bits 位
+	lo := new(big.Int).Lsh(bigOne, uint(bits-1))
+	lo.Add(lo, twoQ).Sub(lo, bigOne).Div(lo, twoQ)
+	hi := new(big.Int).Lsh(bigOne, uint(bits))
+	hi.Sub(hi, bigTwo).Div(hi, twoQ)
+	span := new(big.Int).Sub(hi, lo)
+	span.Add(span, bigOne)
+
+	p, e, t := new(big.Int), new(big.Int), new(big.Int)
+	for {
+		r, err := rand.Int(rand.Reader, span)
+		if err != nil {
+			return fmt.Errorf("failed to generate candidate: %w", err)
+		}
+		r.Add(r, lo)
+		r.SetBit(r, 0, 1)
+		p.Mul(r, twoQ).Add(p, bigOne)
+		if p.BitLen() != bits || !p.ProbablyPrime(20) {
+			continue
+		}
+		// p 为素数时几乎所有底数都满足判据，从 2 开始搜索
+		e.Div(p, q) // (p - 1) / q，因为 p ≡ 1 (mod q)
+		for a := int64(2); a < 1000; a++ {
+			base := big.NewInt(a)
+			if t.Exp(base, e, p).Sub(t, bigOne).Sign() == 0 {
+				continue
+			}
+			if t.GCD(nil, nil, t, p).Cmp(bigOne) != 0 {
+				continue
+			}
+			if t.Exp(base, new(big.Int).Sub(p, bigOne), p).Cmp(bigOne) != 0 {
+				break
+			}
+			c.Steps = append(c.Steps, CertificateStep{P: new(big.Int).Set(p), A: base})
+			return nil
+		}
+	}
+}
+
+// VerifyPrimeCertificate 检查 cert 是否证明了 p 是素数
+func VerifyPrimeCertificate(p *big.Int, cert *PrimeCertificate) error {
+	if p == nil || cert == nil || len(cert.Steps) == 0 {
+		return errors.New("empty prime certificate")
+	}
+	base := cert.Steps[0].P
+	if base == nil || base.BitLen() > certBaseBits || !isSmallPrime(base.Uint64()) {
+		return errors.New("certificate base is not a small prime")
+	}
+	e, t := new(big.Int), new(big.Int)
+	for i := 1; i < len(cert.Steps); i++ {
+		q, step := cert.Steps[i-1].P, cert.Steps[i]
+		n, a := step.P, step.A
+		if n == nil || a == nil || n.Cmp(bigTwo) <= 0 || n.Bit(0) == 0 {
+			return fmt.Errorf("certificate step %d is malformed", i)
+		}
+		if a.Cmp(bigOne) <= 0 || a.Cmp(n) >= 0 {
+			return fmt.Errorf("certificate step %d has an invalid base", i)
+		}
+		// q² > n 且 q | n - 1
+		if t.Mul(q, q).Cmp(n) <= 0 {
+			return fmt.Errorf("certificate step %d: factor is too small", i)
+		}
+		nm1 := new(big.Int).Sub(n, bigOne)
+		if t.Mod(nm1, q).Sign() != 0 {
+			return fmt.Errorf("certificate step %d: factor does not divide p - 1", i)
+		}
+		// a^(n-1) ≡ 1 且 gcd(a^((n-1)/q) - 1, n) = 1
+		if t.Exp(a, nm1, n).Cmp(bigOne) != 0 {
+			return fmt.Errorf("certificate step %d: Fermat condition fails", i)
+		}
+		e.Div(nm1, q)
+		t.Exp(a, e, n).Sub(t, bigOne)
+		if t.GCD(nil, nil, t, n).Cmp(bigOne) != 0 {
+			return fmt.Errorf("certificate step %d: gcd condition fails", i)
+		}
+	}
+	if cert.Steps[len(cert.Steps)-1].P.Cmp(p) != 0 {
+		return errors.New("certificate does not prove the given prime")
+	}
+	return nil
+}
+
+// isSmallPrime 用试除判断 n 是否为素数，n 不超过 32 位
+func isSmallPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint64(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package slothgo
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPrimeCertificate(t *testing.T) {
+	for _, bits := range []int{7, 40, testPrimeBits, 1024} {
+		p, cert, err := GenerateCertifiedPrime(bits)
+		if err != nil {
+			t.Fatalf("GenerateCertifiedPrime(%d) failed: %v", bits, err)
+		}
+		if p.BitLen() != bits || p.Bit(1) != 1 || !p.ProbablyPrime(20) {
+			t.Fatalf("%d-bit certified value %x is not a prime congruent to 3 (mod 4)", bits, p)
+		}
+		if err := VerifyPrimeCertificate(p, cert); err != nil {
+			t.Fatalf("VerifyPrimeCertificate(%d bits) failed: %v", bits, err)
+		}
+	}
+
+	p, cert, err := GenerateCertifiedPrime(testPrimeBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(p, testIterations); err != nil {
+		t.Fatalf("certified prime rejected by New: %v", err)
+	}
+
+	// 证书与素数不匹配
+	if err := VerifyPrimeCertificate(new(big.Int).Add(p, bigTwo), cert); err == nil {
+		t.Error("certificate accepted for a different number")
+	}
+
+	// 篡改底数
+	last := &cert.Steps[len(cert.Steps)-1]
+	saved := last.A
+	last.A = new(big.Int).Sub(last.P, bigOne)
+	if err := VerifyPrimeCertificate(p, cert); err == nil {
+		t.Error("certificate with tampered base accepted")
+	}
+	last.A = saved
+
+	// 跳过中间一步后，因子不再满足 q² > P 或 q | P - 1
+	short := &PrimeCertificate{Steps: append([]CertificateStep{cert.Steps[0]}, cert.Steps[len(cert.Steps)-1])}
+	if len(cert.Steps) > 2 {
+		if err := VerifyPrimeCertificate(p, short); err == nil {
+			t.Error("certificate with a missing step accepted")
+		}
+	}
+
+	// 为合数伪造的证书: 底部不是素数
+	fake := &PrimeCertificate{Steps: []CertificateStep{{P: big.NewInt(91)}}}
+	if err := VerifyPrimeCertificate(big.NewInt(91), fake); err == nil {
+		t.Error("certificate for a composite accepted")
+	}
+}
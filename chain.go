@@ -0,0 +1,73 @@
+package slothgo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// chainDomain 是链式输入的域分隔前缀
+const chainDomain = "slothgo/chain/v1"
+
+// ChainInput 计算第 round 轮的输入: 域分隔前缀 || round(8, 大端) || prev
+// 第 0 轮的 prev 是创世输入，之后每一轮的 prev 是上一轮输出的哈希
+func ChainInput(prev []byte, round uint64) []byte {
+	input := make([]byte, 0, len(chainDomain)+8+len(prev))
+	input = append(input, chainDomain...)
+	input = binary.BigEndian.AppendUint64(input, round)
+	return append(input, prev...)
+}
+
+// ComputeChain 从 genesis 开始连续计算 rounds 轮，第 n 轮的输出哈希作为第 n+1 轮输入的一部分
+func (s *Sloth) ComputeChain(ctx context.Context, genesis []byte, rounds int) ([]Proof, error) {
+	return s.ExtendChain(ctx, genesis, nil, rounds)
+}
+
+// ExtendChain 在已有的链 chain 之后继续计算 rounds 轮，返回追加后的完整链
+// chain 为空时从 genesis 开始; 已有部分不会被重新验证
+func (s *Sloth) ExtendChain(ctx context.Context, genesis []byte, chain []Proof, rounds int) ([]Proof, error) {
+	if rounds < 0 {
+		return nil, errors.New("rounds must not be negative")
+	}
+	prev := genesis
+	if len(chain) > 0 {
+		prev = chain[len(chain)-1].Hash
+	}
+	for range rounds {
+		proof, err := s.ComputeProofCtx(ctx, ChainInput(prev, uint64(len(chain))))
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", len(chain), err)
+		}
+		chain = append(chain, *proof)
+		prev = proof.Hash
+	}
+	return chain, nil
+}
+
+// VerifyChain 检查 proofs 是从 genesis 开始的一条完整的链
+// 每一轮的输入都由上一轮的输出哈希确定，因此各轮可以通过 VerifyBatch 并行验证
+func (s *Sloth) VerifyChain(proofs []Proof, genesis []byte) (bool, error) {
+	if len(proofs) == 0 {
+		return false, errors.New("chain cannot be empty")
+	}
+	inputs := make([][]byte, len(proofs))
+	prev := genesis
+	for i := range proofs {
+		if proofs[i].Hash == nil {
+			return false, fmt.Errorf("round %d: hash cannot be nil", i)
+		}
+		inputs[i] = ChainInput(prev, uint64(i))
+		prev = proofs[i].Hash
+	}
+	results, err := s.VerifyBatch(inputs, proofs)
+	if err != nil {
+		return false, err
+	}
+	for i, ok := range results {
+		if !ok {
+			return false, fmt.Errorf("round %d failed verification", i)
+		}
+	}
+	return true, nil
+}
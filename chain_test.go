@@ -0,0 +1,44 @@
+package slothgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	genesis := []byte("genesis")
+
+	chain, err := testVDF.ComputeChain(ctx, genesis, 3)
+	if err != nil {
+		t.Fatalf("ComputeChain failed: %v", err)
+	}
+	chain, err = testVDF.ExtendChain(ctx, genesis, chain, 2)
+	if err != nil {
+		t.Fatalf("ExtendChain failed: %v", err)
+	}
+	if len(chain) != 5 {
+		t.Fatalf("expected 5 rounds, got %d", len(chain))
+	}
+	if ok, err := testVDF.VerifyChain(chain, genesis); !ok {
+		t.Fatalf("VerifyChain failed: %v", err)
+	}
+
+	// 一次性计算的链与分两次计算的链应当相同
+	full, err := testVDF.ComputeChain(ctx, genesis, 5)
+	if err != nil {
+		t.Fatalf("ComputeChain failed: %v", err)
+	}
+	if full[4].Witness.Cmp(chain[4].Witness) != 0 {
+		t.Error("extended chain differs from chain computed in one go")
+	}
+
+	if ok, _ := testVDF.VerifyChain(chain, []byte("other genesis")); ok {
+		t.Error("expected chain with a different genesis to be rejected")
+	}
+	swapped := append([]Proof(nil), chain...)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	if ok, _ := testVDF.VerifyChain(swapped, genesis); ok {
+		t.Error("expected reordered chain to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// checkpointVersion 是检查点二进制格式的版本号
+const checkpointVersion = 1
+
+// Checkpoint 记录长时间计算的中间状态，可以序列化后保存并在之后恢复计算
+type Checkpoint struct {
+	Iteration   uint64   // 已完成的迭代次数
+	W           *big.Int // 第 Iteration 次迭代后的值
+	Fingerprint []byte   // 产生该检查点的参数指纹
+
+	size int // W 的编码长度，由产生该检查点的实例或解码时的数据决定
+}
+
+// SaveFunc 在计算过程中接收检查点，返回错误会中止计算
+type SaveFunc func(cp *Checkpoint) error
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+// 格式: 版本(1) | 指纹长度(1) | 指纹 | 迭代次数(8, 大端) | w (定长大端编码，见 Sloth.EncodeWitness)
+func (cp *Checkpoint) MarshalBinary() ([]byte, error) {
+	if cp.W == nil {
+		return nil, errors.New("checkpoint witness cannot be nil")
+	}
+	if len(cp.Fingerprint) > 255 {
+		return nil, errors.New("checkpoint fingerprint too long")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(checkpointVersion)
+	buf.WriteByte(byte(len(cp.Fingerprint)))
+	buf.Write(cp.Fingerprint)
+	binary.Write(&buf, binary.BigEndian, cp.Iteration)
+	buf.Write(encodeFixed(cp.W, cp.size))
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (cp *Checkpoint) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("checkpoint data too short")
+	}
+	if data[0] != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %d", data[0])
+	}
+	fpLen := int(data[1])
+	data = data[2:]
+	if len(data) < fpLen+8 {
+		return errors.New("checkpoint data too short")
+	}
+	cp.Fingerprint = bytes.Clone(data[:fpLen])
+	cp.Iteration = binary.BigEndian.Uint64(data[fpLen:])
+	cp.W = new(big.Int).SetBytes(data[fpLen+8:])
+	cp.size = len(data[fpLen+8:])
+	return nil
+}
+
+// encodeFixed 把 w 编码为至少 size 字节的大端字节数组，不足时补前导零
+// 手工构造的检查点等没有关联实例时 size 为 0，此时退化为 w.Bytes()，但至少为 1 字节
+func encodeFixed(w *big.Int, size int) []byte {
+	if n := max((w.BitLen()+7)/8, 1); n > size {
+		size = n
+	}
+	return w.FillBytes(make([]byte, size))
+}
+
+// ComputeCheckpointed 与 ComputeCtx 相同，但每完成 interval 次迭代就调用一次 save 保存检查点
+// 计算中断后可以用 ResumeFrom 从最近保存的检查点继续
+func (s *Sloth) ComputeCheckpointed(ctx context.Context, input []byte, interval uint64, save SaveFunc) (hash []byte, witness *big.Int, err error) {
+	return s.computeCheckpointed(ctx, s.initialValue(input), 0, interval, save)
+}
+
+// ResumeFrom 从检查点继续计算剩余的迭代，并继续按 interval 调用 save
+func (s *Sloth) ResumeFrom(ctx context.Context, cp *Checkpoint, interval uint64, save SaveFunc) (hash []byte, witness *big.Int, err error) {
+	if cp == nil || cp.W == nil {
+		return nil, nil, errors.New("checkpoint cannot be nil")
+	}
+	if !bytes.Equal(cp.Fingerprint, s.Fingerprint()) {
+		return nil, nil, errors.New("checkpoint was produced with different parameters")
+	}
+	if err := s.checkState(cp.W, cp.Iteration); err != nil {
+		return nil, nil, err
+	}
+	return s.computeCheckpointed(ctx, new(big.Int).Set(cp.W), cp.Iteration, interval, save)
+}
+
+// ComputeFrom 从第 startIteration 次迭代后的中间值 w 出发，完成剩余的迭代
+// 这使得一台机器可以把中间状态交给另一台机器继续计算
+func (s *Sloth) ComputeFrom(w *big.Int, startIteration uint64) (hash []byte, witness *big.Int, err error) {
+	return s.ComputeFromCtx(context.Background(), w, startIteration)
+}
+
+// ComputeFromCtx 与 ComputeFrom 相同，但支持通过 ctx 取消
+func (s *Sloth) ComputeFromCtx(ctx context.Context, w *big.Int, startIteration uint64) (hash []byte, witness *big.Int, err error) {
+	if w == nil {
+		return nil, nil, errors.New("intermediate value cannot be nil")
+	}
+	if err := s.checkState(w, startIteration); err != nil {
+		return nil, nil, err
+	}
+	witness, err = s.iterate(ctx, new(big.Int).Set(w), startIteration, s.Iterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.outputHash(witness), witness, nil
+}
+
+// VerifyFrom 验证 witness 是从 w₀ = start 出发迭代 l 次的结果，用于初始值不是由输入哈希得到的场景
+func (s *Sloth) VerifyFrom(ctx context.Context, start *big.Int, hash []byte, witness *big.Int) (bool, error) {
+	if start == nil {
+		return false, errors.New("start value cannot be nil")
+	}
+	if err := s.checkState(start, 0); err != nil {
+		return false, err
+	}
+	return s.verifyFrom(ctx, start, hash, witness)
+}
+
+// checkState 检查中间状态 (w, iteration) 是否在合法范围内
+func (s *Sloth) checkState(w *big.Int, iteration uint64) error {
+	if iteration > s.Iterations {
+		return fmt.Errorf("iteration %d out of range [0, %d]", iteration, s.Iterations)
+	}
+	if w.Cmp(s.P) >= 0 || w.Sign() < 0 {
+		return errors.New("intermediate value must be in the range [0, p-1]")
+	}
+	return nil
+}
+
+func (s *Sloth) computeCheckpointed(ctx context.Context, w *big.Int, start, interval uint64, save SaveFunc) ([]byte, *big.Int, error) {
+	if interval == 0 {
+		return nil, nil, errors.New("checkpoint interval must be positive")
+	}
+	fingerprint := s.Fingerprint()
+	for i := start; i < s.Iterations; {
+		end := stepEnd(i, interval-i%interval, s.Iterations)
+		var err error
+		w, err = s.iterate(ctx, w, i, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		i = end
+		if save != nil && i < s.Iterations {
+			cp := &Checkpoint{Iteration: i, W: new(big.Int).Set(w), Fingerprint: fingerprint, size: s.WitnessSize()}
+			if err := save(cp); err != nil {
+				return nil, nil, fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+	}
+	witness := new(big.Int).Set(w)
+	return s.outputHash(witness), witness, nil
+}
@@ -0,0 +1,104 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// TestResumeFrom 模拟计算在中途崩溃，然后从序列化的检查点恢复
+func TestResumeFrom(t *testing.T) {
+	wantHash, wantWitness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	// 保存第二个检查点后模拟崩溃
+	errCrash := errors.New("crash")
+	var saved [][]byte
+	_, _, err = testVDF.ComputeCheckpointed(context.Background(), testInput, 300, func(cp *Checkpoint) error {
+		data, err := cp.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		saved = append(saved, data)
+		if len(saved) == 2 {
+			return errCrash
+		}
+		return nil
+	})
+	if !errors.Is(err, errCrash) {
+		t.Fatalf("expected crash error, got %v", err)
+	}
+
+	var cp Checkpoint
+	if err := cp.UnmarshalBinary(saved[len(saved)-1]); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if cp.Iteration != 600 {
+		t.Fatalf("checkpoint iteration = %d, want 600", cp.Iteration)
+	}
+
+	hash, witness, err := testVDF.ResumeFrom(context.Background(), &cp, 300, nil)
+	if err != nil {
+		t.Fatalf("ResumeFrom failed: %v", err)
+	}
+	if witness.Cmp(wantWitness) != 0 || string(hash) != string(wantHash) {
+		t.Error("resumed computation does not match uninterrupted computation")
+	}
+
+	// 参数不同的实例必须拒绝该检查点
+	other, err := New(testVDF.P, testIterations+1)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, _, err := other.ResumeFrom(context.Background(), &cp, 300, nil); err == nil {
+		t.Error("expected fingerprint mismatch error, got nil")
+	}
+}
+
+// TestComputeFrom 检查从中间值继续计算与完整计算的结果一致
+func TestComputeFrom(t *testing.T) {
+	wantHash, wantWitness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	var handoff *Checkpoint
+	_, _, err = testVDF.ComputeCheckpointed(context.Background(), testInput, 400, func(cp *Checkpoint) error {
+		if handoff == nil {
+			handoff = cp
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ComputeCheckpointed failed: %v", err)
+	}
+
+	hash, witness, err := testVDF.ComputeFrom(handoff.W, handoff.Iteration)
+	if err != nil {
+		t.Fatalf("ComputeFrom failed: %v", err)
+	}
+	if witness.Cmp(wantWitness) != 0 || string(hash) != string(wantHash) {
+		t.Error("ComputeFrom result differs from Compute")
+	}
+
+	if _, _, err := testVDF.ComputeFrom(handoff.W, testIterations+1); err == nil {
+		t.Error("expected error for out-of-range start iteration")
+	}
+}
+
+func TestVerifyFrom(t *testing.T) {
+	start := testVDF.Tau(big.NewInt(12345))
+	hash, witness, err := testVDF.ComputeFromCtx(context.Background(), start, 0)
+	if err != nil {
+		t.Fatalf("ComputeFromCtx failed: %v", err)
+	}
+	if ok, err := testVDF.VerifyFrom(context.Background(), start, hash, witness); !ok {
+		t.Fatalf("VerifyFrom failed: %v", err)
+	}
+	if ok, _ := testVDF.VerifyFrom(context.Background(), big.NewInt(12345), hash, witness); ok {
+		t.Error("expected VerifyFrom with a different start value to fail")
+	}
+}
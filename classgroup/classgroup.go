@@ -0,0 +1,291 @@
+// Package classgroup 实现虚二次域的类群运算
+// 群元素是判别式为 D < 0 的约化正定二元二次型 (a, b, c)，b² - 4ac = D。
+// 当 D = -p (p 为素数, p ≡ 3 mod 4) 时，类群的阶无法有效计算，
+// 并且判别式可以由公开种子派生，因此不需要任何可信参数
+package classgroup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/group"
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+var (
+	bigOne  = big.NewInt(1)
+	bigFour = big.NewInt(4)
+)
+
+// hashPrimeBits 是 HashToElement 中选取的素数 a 的位数
+const hashPrimeBits = 128
+
+// Form 是二元二次型 ax² + bxy + cy²
+type Form struct {
+	A, B, C *big.Int
+}
+
+// Group 是判别式为 D 的类群
+type Group struct {
+	D *big.Int // 判别式，D < 0 且 D ≡ 1 (mod 4)
+}
+
+var _ group.Group[*Form] = (*Group)(nil)
+
+// New 使用判别式 d 创建类群
+func New(d *big.Int) (*Group, error) {
+	if d == nil || d.Sign() >= 0 {
+		return nil, errors.New("discriminant must be negative")
+	}
+	if new(big.Int).Mod(d, bigFour).Cmp(bigOne) != 0 {
+		return nil, errors.New("discriminant must be congruent to 1 (mod 4)")
+	}
+	return &Group{D: new(big.Int).Set(d)}, nil
+}
+
+// DeriveDiscriminant 由公开种子确定性地派生 bits 位的判别式 D = -p，其中 p ≡ 3 (mod 4) 为素数
+// 任何人都可以用相同的种子复现该判别式，因此不需要可信设置
+func DeriveDiscriminant(seed []byte, bits int) *big.Int {
+	p := new(big.Int).SetBytes(hashutil.Expand("classgroup/discriminant", seed, (bits+7)/8))
+	p.Rsh(p, uint(p.BitLen()-bits))
+	p.SetBit(p, bits-1, 1)
+	// 调整为 p ≡ 3 (mod 4)，然后以 4 为步长寻找素数
+	p.SetBit(p, 0, 1)
+	p.SetBit(p, 1, 1)
+	for !p.ProbablyPrime(20) {
+		p.Add(p, bigFour)
+	}
+	return p.Neg(p)
+}
+
+// Identity 返回单位元 (1, 1, (1-D)/4)
+func (g *Group) Identity() *Form {
+	c := new(big.Int).Sub(bigOne, g.D)
+	c.Rsh(c, 2)
+	return &Form{A: big.NewInt(1), B: big.NewInt(1), C: c}
+}
+
+// Mul 使用 Cohen《A Course in Computational Algebraic Number Theory》算法 5.4.7 复合两个二次型
+func (g *Group) Mul(f1, f2 *Form) *Form {
+	if f1.A.Cmp(f2.A) > 0 {
+		f1, f2 = f2, f1
+	}
+	a1, b1 := f1.A, f1.B
+	a2, b2, c2 := f2.A, f2.B, f2.C
+
+	// s = (b1+b2)/2, n = b2 - s
+	s := new(big.Int).Add(b1, b2)
+	s.Rsh(s, 1)
+	n := new(big.Int).Sub(b2, s)
+
+	// u·a2 + v·a1 = d = gcd(a2, a1)，y1 = u
+	y1 := new(big.Int)
+	d := new(big.Int)
+	if new(big.Int).Mod(a2, a1).Sign() == 0 {
+		d.Set(a1)
+	} else {
+		d.GCD(y1, nil, a2, a1)
+	}
+
+	// x2·s + y2·d = d1 = gcd(s, d)，再令 y2 = -y2
+	x2 := new(big.Int)
+	y2 := new(big.Int)
+	d1 := new(big.Int)
+	if new(big.Int).Mod(s, d).Sign() == 0 {
+		y2.SetInt64(-1)
+		d1.Set(d)
+	} else {
+		d1.GCD(x2, y2, s, d)
+		y2.Neg(y2)
+	}
+
+	v1 := new(big.Int).Quo(a1, d1)
+	v2 := new(big.Int).Quo(a2, d1)
+
+	// r = (y1·y2·n - x2·c2) mod v1
+	r := new(big.Int).Mul(y1, y2)
+	r.Mul(r, n)
+	r.Sub(r, new(big.Int).Mul(x2, c2))
+	r.Mod(r, v1)
+
+	// b3 = b2 + 2·v2·r, a3 = v1·v2, c3 = (b3² - D)/(4·a3)
+	b3 := new(big.Int).Mul(v2, r)
+	b3.Lsh(b3, 1)
+	b3.Add(b3, b2)
+	a3 := new(big.Int).Mul(v1, v2)
+	return g.reduce(&Form{A: a3, B: b3, C: g.computeC(a3, b3)})
+}
+
+// Square 返回 f²
+func (g *Group) Square(f *Form) *Form {
+	return g.Mul(f, f)
+}
+
+// Exp 使用平方-乘算法计算 f^e
+func (g *Group) Exp(f *Form, e *big.Int) *Form {
+	r := g.Identity()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r = g.Square(r)
+		if e.Bit(i) == 1 {
+			r = g.Mul(r, f)
+		}
+	}
+	return r
+}
+
+// Equal 判断两个约化二次型是否相等
+func (g *Group) Equal(f1, f2 *Form) bool {
+	return f1.A.Cmp(f2.A) == 0 && f1.B.Cmp(f2.B) == 0 && f1.C.Cmp(f2.C) == 0
+}
+
+// Validate 检查 f 是判别式为 D 的约化正定二次型
+func (g *Group) Validate(f *Form) error {
+	if f == nil || f.A == nil || f.B == nil || f.C == nil {
+		return errors.New("form cannot be nil")
+	}
+	if f.A.Sign() <= 0 {
+		return errors.New("form must be positive definite")
+	}
+	disc := new(big.Int).Mul(f.B, f.B)
+	disc.Sub(disc, new(big.Int).Mul(new(big.Int).Lsh(f.A, 2), f.C))
+	if disc.Cmp(g.D) != 0 {
+		return errors.New("form has the wrong discriminant")
+	}
+	if !isReduced(f) {
+		return errors.New("form is not reduced")
+	}
+	return nil
+}
+
+// HashToElement 选取一个由哈希派生、且 D 为其二次剩余的素数 a，
+// 令 b 为 D 模 a 的奇数平方根，得到二次型 (a, b, (b²-D)/4a) 并约化
+func (g *Group) HashToElement(domain string, data []byte) *Form {
+	for counter := byte(0); ; counter++ {
+		a := hashutil.ToPrime(domain, append([]byte{counter}, data...), hashPrimeBits)
+		if big.Jacobi(g.D, a) != 1 {
+			continue
+		}
+		dModA := new(big.Int).Mod(g.D, a)
+		b := new(big.Int).ModSqrt(dModA, a)
+		if b.Bit(0) == 0 {
+			b.Sub(a, b)
+		}
+		return g.reduce(&Form{A: a, B: b, C: g.computeC(a, b)})
+	}
+}
+
+// Encode 把约化二次型编码为 a || sign(b) || |b|，其中 a、|b| 均按定长大端编码
+// 对于约化二次型有 |b| ≤ a ≤ √(|D|/3)，c 可以由判别式恢复
+func (g *Group) Encode(f *Form) []byte {
+	w := g.limbSize()
+	out := make([]byte, 2*w+1)
+	f.A.FillBytes(out[:w])
+	if f.B.Sign() < 0 {
+		out[w] = 1
+	}
+	new(big.Int).Abs(f.B).FillBytes(out[w+1:])
+	return out
+}
+
+// Decode 是 Encode 的逆运算，并会校验结果是约化二次型
+func (g *Group) Decode(data []byte) (*Form, error) {
+	w := g.limbSize()
+	if len(data) != 2*w+1 {
+		return nil, fmt.Errorf("form must be %d bytes", 2*w+1)
+	}
+	if data[w] > 1 {
+		return nil, errors.New("invalid sign byte")
+	}
+	a := new(big.Int).SetBytes(data[:w])
+	b := new(big.Int).SetBytes(data[w+1:])
+	if data[w] == 1 {
+		b.Neg(b)
+	}
+	if a.Sign() <= 0 {
+		return nil, errors.New("form must be positive definite")
+	}
+	num := new(big.Int).Mul(b, b)
+	num.Sub(num, g.D)
+	den := new(big.Int).Lsh(a, 2)
+	c, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, errors.New("form has the wrong discriminant")
+	}
+	f := &Form{A: a, B: b, C: c}
+	if err := g.Validate(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ElementSize 返回编码后元素的字节数
+func (g *Group) ElementSize() int {
+	return 2*g.limbSize() + 1
+}
+
+// limbSize 返回容纳 √|D| 所需的字节数
+func (g *Group) limbSize() int {
+	return (g.D.BitLen()/2 + 1 + 7) / 8
+}
+
+// computeC 由 a、b 和判别式计算 c = (b² - D)/(4a)
+func (g *Group) computeC(a, b *big.Int) *big.Int {
+	c := new(big.Int).Mul(b, b)
+	c.Sub(c, g.D)
+	return c.Quo(c, new(big.Int).Lsh(a, 2))
+}
+
+// normalize 把 b 调整到 (-a, a] 区间内
+func normalize(f *Form) *Form {
+	negA := new(big.Int).Neg(f.A)
+	if f.B.Cmp(negA) > 0 && f.B.Cmp(f.A) <= 0 {
+		return f
+	}
+	// r = ⌊(a - b) / 2a⌋, b' = b + 2ra, c' = ar² + br + c
+	twoA := new(big.Int).Lsh(f.A, 1)
+	r := new(big.Int).Sub(f.A, f.B)
+	r = floorDiv(r, twoA)
+	c := new(big.Int).Mul(f.A, r)
+	c.Add(c, f.B)
+	c.Mul(c, r)
+	c.Add(c, f.C)
+	b := new(big.Int).Mul(twoA, r)
+	b.Add(b, f.B)
+	return &Form{A: f.A, B: b, C: c}
+}
+
+// reduce 把二次型约化为其等价类中唯一的约化代表元
+func (g *Group) reduce(f *Form) *Form {
+	f = normalize(f)
+	for f.A.Cmp(f.C) > 0 || (f.A.Cmp(f.C) == 0 && f.B.Sign() < 0) {
+		// s = ⌊(c + b) / 2c⌋, (a, b, c) = (c, -b + 2sc, cs² - bs + a)
+		twoC := new(big.Int).Lsh(f.C, 1)
+		s := floorDiv(new(big.Int).Add(f.C, f.B), twoC)
+		b := new(big.Int).Mul(twoC, s)
+		b.Sub(b, f.B)
+		c := new(big.Int).Mul(f.C, s)
+		c.Sub(c, f.B)
+		c.Mul(c, s)
+		c.Add(c, f.A)
+		f = &Form{A: f.C, B: b, C: c}
+	}
+	return normalize(f)
+}
+
+// isReduced 判断 |b| ≤ a ≤ c，且当 |b| = a 或 a = c 时 b ≥ 0
+func isReduced(f *Form) bool {
+	absB := new(big.Int).Abs(f.B)
+	if absB.Cmp(f.A) > 0 || f.A.Cmp(f.C) > 0 {
+		return false
+	}
+	if (absB.Cmp(f.A) == 0 || f.A.Cmp(f.C) == 0) && f.B.Sign() < 0 {
+		return false
+	}
+	return true
+}
+
+// floorDiv 返回 ⌊x / y⌋，y > 0 时欧几里得除法与向下取整一致
+func floorDiv(x, y *big.Int) *big.Int {
+	return new(big.Int).Div(x, y)
+}
@@ -0,0 +1,70 @@
+package classgroup
+
+import (
+	"math/big"
+	"testing"
+)
+
+func testGroup(t *testing.T) *Group {
+	t.Helper()
+	g, err := New(DeriveDiscriminant([]byte("classgroup test"), 256))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return g
+}
+
+// TestGroupLaws 检查单位元、交换律、结合律与幂运算的一致性
+func TestGroupLaws(t *testing.T) {
+	g := testGroup(t)
+	x := g.HashToElement("test", []byte("x"))
+	y := g.HashToElement("test", []byte("y"))
+	z := g.HashToElement("test", []byte("z"))
+	for _, f := range []*Form{x, y, z, g.Identity()} {
+		if err := g.Validate(f); err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+	}
+
+	if !g.Equal(g.Mul(x, g.Identity()), x) {
+		t.Error("x·1 != x")
+	}
+	if !g.Equal(g.Mul(x, y), g.Mul(y, x)) {
+		t.Error("x·y != y·x")
+	}
+	if !g.Equal(g.Mul(g.Mul(x, y), z), g.Mul(x, g.Mul(y, z))) {
+		t.Error("(x·y)·z != x·(y·z)")
+	}
+
+	a, b := big.NewInt(12345), big.NewInt(6789)
+	lhs := g.Exp(x, new(big.Int).Add(a, b))
+	rhs := g.Mul(g.Exp(x, a), g.Exp(x, b))
+	if !g.Equal(lhs, rhs) {
+		t.Error("x^(a+b) != x^a·x^b")
+	}
+	if !g.Equal(g.Exp(x, big.NewInt(2)), g.Square(x)) {
+		t.Error("x^2 != Square(x)")
+	}
+}
+
+// TestEncodeDecode 检查编码往返以及对非法编码的拒绝
+func TestEncodeDecode(t *testing.T) {
+	g := testGroup(t)
+	x := g.Exp(g.HashToElement("test", []byte("x")), big.NewInt(1000))
+	data := g.Encode(x)
+	if len(data) != g.ElementSize() {
+		t.Fatalf("encoded length = %d, want %d", len(data), g.ElementSize())
+	}
+	y, err := g.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !g.Equal(x, y) {
+		t.Fatal("decoded form differs from original")
+	}
+
+	data[len(data)-1] ^= 1
+	if _, err := g.Decode(data); err == nil {
+		t.Error("expected error for corrupted encoding")
+	}
+}
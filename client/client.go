@@ -0,0 +1,169 @@
+// Package client 把 VDF 计算外包给远程的 slothd，同时在本地验证结果
+// Client 实现 slothgo.VDF: Evaluate 通过 slothd 的 REST 接口提交任务并等待完成，
+// 取回的证明在返回之前用本地实例验证，因此不需要信任服务端; VerifyEvaluation 完全在本地进行
+// Coordinator 把同一个输入分发给多个证明方，接受第一个有效证明并交叉检查其余的结果
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// ErrInvalidProof 表示服务端返回的证明没有通过本地验证
+var ErrInvalidProof = errors.New("client: server returned an invalid proof")
+
+// Option 用于在 New 中配置 Client 的可选行为
+type Option func(*Client)
+
+// WithHTTPClient 替换发送请求使用的 http.Client，默认为 http.DefaultClient
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.hc = hc
+	}
+}
+
+// WithPollInterval 设置查询任务状态的间隔，默认为 500ms
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.poll = d
+	}
+}
+
+// Client 是远程 slothd 的客户端
+type Client struct {
+	base *url.URL
+	vdf  *slothgo.Sloth
+	hc   *http.Client
+	poll time.Duration
+}
+
+var _ slothgo.VDF = (*Client)(nil)
+
+// New 创建连接 baseURL (例如 "http://slothd:8080") 上 REST 接口的客户端
+// vdf 决定本地验证使用的参数，服务端必须使用相同的参数，提交的任务使用 vdf.Iterations 次迭代
+func New(baseURL string, vdf *slothgo.Sloth, opts ...Option) (*Client, error) {
+	if vdf == nil {
+		return nil, errors.New("vdf cannot be nil")
+	}
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", base.Scheme)
+	}
+	c := &Client{base: base, vdf: vdf, hc: http.DefaultClient, poll: 500 * time.Millisecond}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.hc == nil || c.poll <= 0 {
+		return nil, errors.New("invalid client options")
+	}
+	return c, nil
+}
+
+// jobStatus 是 GET /jobs/{id} 响应中客户端用到的字段
+type jobStatus struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	Error string `json:"error"`
+}
+
+// ComputeProof 在远程计算 input 的证明，并在本地验证后返回
+// ctx 结束时停止等待并返回 ctx.Err()，服务端上的任务会继续运行
+func (c *Client) ComputeProof(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
+	}
+	var job jobStatus
+	req := map[string]any{"input": hex.EncodeToString(input), "iterations": c.vdf.Iterations}
+	if err := c.do(ctx, http.MethodPost, "/compute", req, &job); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(c.poll)
+	defer ticker.Stop()
+	for job.State != "done" {
+		if job.State == "failed" {
+			return nil, fmt.Errorf("slothd: job %s failed: %s", job.ID, job.Error)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		if err := c.do(ctx, http.MethodGet, "/jobs/"+job.ID, nil, &job); err != nil {
+			return nil, err
+		}
+	}
+
+	var proof slothgo.Proof
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+job.ID+"/proof", nil, &proof); err != nil {
+		return nil, err
+	}
+	ok, err := c.vdf.VerifyProofCtx(ctx, input, &proof)
+	if !ok {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	return &proof, nil
+}
+
+// Evaluate 实现 slothgo.VDF，输出与证明的编码与 slothgo.Sloth.Evaluate 相同
+func (c *Client) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	p, err := c.ComputeProof(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.Hash, c.vdf.EncodeWitness(p.Witness), nil
+}
+
+// VerifyEvaluation 实现 slothgo.VDF，在本地验证，不访问服务端
+func (c *Client) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	return c.vdf.VerifyEvaluation(ctx, input, output, proof)
+}
+
+// do 发送 JSON 请求并把 JSON 响应解码到 out，非 2xx 响应转换为 error
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&e)
+		return fmt.Errorf("slothd: %s %s: %s (status %d)", method, path, e.Error, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/service"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 1000)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// TestClient 检查通过 slothd 的计算结果与本地计算一致，并能在本地验证
+func TestClient(t *testing.T) {
+	vdf := newTestVDF(t)
+	q, err := service.NewQueue(vdf, service.Config{Workers: 1})
+	if err != nil {
+		t.Fatalf("NewQueue failed: %v", err)
+	}
+	defer q.Close()
+	ts := httptest.NewServer(service.NewHTTPHandler(q))
+	defer ts.Close()
+
+	c, err := New(ts.URL+"/", vdf, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var v slothgo.VDF = c
+	output, proof, err := v.Evaluate(context.Background(), []byte("remote"))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	wantOutput, wantProof, _ := vdf.Evaluate(context.Background(), []byte("remote"))
+	if string(output) != string(wantOutput) || string(proof) != string(wantProof) {
+		t.Error("remote evaluation differs from local evaluation")
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), []byte("remote"), output, proof); !ok {
+		t.Errorf("VerifyEvaluation failed: %v", err)
+	}
+
+	// 服务端拒绝超出上限的迭代次数
+	more, _ := vdf.WithIterations(5000)
+	c, _ = New(ts.URL, more, WithPollInterval(time.Millisecond))
+	if _, err := c.ComputeProof(context.Background(), []byte("remote")); err == nil {
+		t.Error("expected an error for iterations above the server limit")
+	}
+}
+
+// TestClientDishonestServer 检查服务端返回的错误证明被本地验证拒绝
+func TestClientDishonestServer(t *testing.T) {
+	vdf := newTestVDF(t)
+	proof, _ := vdf.ComputeProof([]byte("something else"))
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /compute", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "x", "state": "done"})
+	})
+	mux.HandleFunc("GET /jobs/x/proof", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(proof)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c, _ := New(ts.URL, vdf)
+	if _, _, err := c.Evaluate(context.Background(), []byte("input")); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("Evaluate = %v, want ErrInvalidProof", err)
+	}
+
+	forged := *proof
+	forged.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	proof = &forged
+	if _, err := c.ComputeProof(context.Background(), []byte("something else")); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("ComputeProof = %v, want ErrInvalidProof", err)
+	}
+}
+
+// TestClientCancel 检查 ctx 取消时停止等待
+func TestClientCancel(t *testing.T) {
+	vdf := newTestVDF(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /compute", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "x", "state": "running"})
+	})
+	mux.HandleFunc("GET /jobs/x", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"id": "x", "state": "running"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c, _ := New(ts.URL, vdf, WithPollInterval(time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.ComputeProof(ctx, []byte("input")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ComputeProof = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := New("ftp://host", vdf); err == nil {
+		t.Error("unsupported scheme was accepted")
+	}
+}
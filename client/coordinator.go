@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// ErrInconsistentProver 表示证明方返回了无效的证明，或者与已验证的证明不一致
+var ErrInconsistentProver = errors.New("client: prover returned an inconsistent result")
+
+// Prover 是可以计算证明的节点，*Client 实现了它，本地的 *slothgo.Sloth 可以用 ProverFunc 包装
+type Prover interface {
+	ComputeProof(ctx context.Context, input []byte) (*slothgo.Proof, error)
+}
+
+// ProverFunc 把普通函数适配为 Prover，例如 ProverFunc(vdf.ComputeProofCtx)
+type ProverFunc func(ctx context.Context, input []byte) (*slothgo.Proof, error)
+
+// ComputeProof 实现 Prover
+func (f ProverFunc) ComputeProof(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+	return f(ctx, input)
+}
+
+// Outcome 是一个证明方对一次分发的回应
+type Outcome struct {
+	Prover  string
+	Proof   *slothgo.Proof // 出错时为 nil
+	Err     error          // 证明方不一致时包装 ErrInconsistentProver
+	Elapsed time.Duration
+}
+
+// Fault 记录一次不一致的回应
+type Fault struct {
+	Prover string
+	Input  []byte
+	Err    error
+}
+
+// Dispatch 是一次分发的结果: 第一个通过验证的证明，以及之后陆续到达的其他回应
+type Dispatch struct {
+	Prover string         // 第一个返回有效证明的证明方
+	Proof  *slothgo.Proof // 它返回的证明
+
+	done     chan struct{}
+	outcomes []Outcome
+}
+
+// Wait 等待全部证明方回应 (或者交叉检查的宽限期结束) 后返回所有回应，按证明方名称排列
+func (d *Dispatch) Wait() []Outcome {
+	<-d.done
+	return d.outcomes
+}
+
+// CoordinatorOption 用于在 NewCoordinator 中配置 Coordinator 的可选行为
+type CoordinatorOption func(*Coordinator)
+
+// WithGracePeriod 设置得到第一个有效证明后继续等待其他证明方的时间，默认为 30s
+// 宽限期与调用方的 ctx 无关，Compute 返回后交叉检查仍会继续
+func WithGracePeriod(d time.Duration) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.grace = d
+	}
+}
+
+// WithFaultHandler 设置发现不一致的证明方时调用的函数，它可能在任意协程中被调用
+func WithFaultHandler(f func(Fault)) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.onFault = f
+	}
+}
+
+// Coordinator 把同一个输入分发给多个独立的证明方，接受第一个通过本地验证的证明，
+// 并检查其余证明方返回的结果与之一致。Sloth 的证明对给定参数与输入是唯一的，诚实的证明方必然
+// 给出相同的哈希与 witness，因此任何不同的结果都说明该证明方出错或作恶。被标记的证明方不再参与
+// 之后的分发，直到调用 Reinstate。Coordinator 是并发安全的
+type Coordinator struct {
+	vdf     *slothgo.Sloth
+	provers map[string]Prover
+	grace   time.Duration
+	onFault func(Fault)
+
+	mu      sync.Mutex
+	flagged map[string]Fault // 证明方 -> 最近一次不一致的回应
+}
+
+var _ slothgo.VDF = (*Coordinator)(nil)
+
+// NewCoordinator 创建分发给 provers 的协调者，键为证明方的名称，vdf 决定本地验证使用的参数
+func NewCoordinator(vdf *slothgo.Sloth, provers map[string]Prover, opts ...CoordinatorOption) (*Coordinator, error) {
+	if vdf == nil {
+		return nil, errors.New("vdf cannot be nil")
+	}
+	if len(provers) == 0 {
+		return nil, errors.New("at least one prover is required")
+	}
+	c := &Coordinator{
+		vdf:     vdf,
+		provers: make(map[string]Prover, len(provers)),
+		grace:   30 * time.Second,
+		flagged: make(map[string]Fault),
+	}
+	for name, p := range provers {
+		if p == nil {
+			return nil, fmt.Errorf("prover %q is nil", name)
+		}
+		c.provers[name] = p
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.grace < 0 {
+		return nil, errors.New("invalid coordinator options")
+	}
+	return c, nil
+}
+
+// Flagged 返回被标记为不一致的证明方及其最近一次不一致的回应
+func (c *Coordinator) Flagged() map[string]Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]Fault, len(c.flagged))
+	for name, f := range c.flagged {
+		out[name] = f
+	}
+	return out
+}
+
+// Reinstate 取消对证明方 name 的标记，使它重新参与分发
+func (c *Coordinator) Reinstate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.flagged, name)
+}
+
+// active 返回未被标记的证明方名称，按名称排列
+func (c *Coordinator) active() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var names []string
+	for name := range c.provers {
+		if _, ok := c.flagged[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (c *Coordinator) flag(f Fault) {
+	c.mu.Lock()
+	c.flagged[f.Prover] = f
+	c.mu.Unlock()
+	if c.onFault != nil {
+		c.onFault(f)
+	}
+}
+
+// Compute 把 input 分发给所有未被标记的证明方，返回第一个通过验证的证明
+// 其余证明方在宽限期内继续计算，它们的回应与第一个证明比较，不一致的证明方被标记，
+// Dispatch.Wait 返回全部回应。ctx 在得到有效证明之前结束时取消所有证明方并返回 ctx.Err()
+func (c *Coordinator) Compute(ctx context.Context, input []byte) (*Dispatch, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
+	}
+	names := c.active()
+	if len(names) == 0 {
+		return nil, errors.New("all provers are flagged")
+	}
+
+	// 证明方使用与 ctx 分离的上下文，得到有效证明之后由宽限期决定何时取消
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	results := make(chan Outcome, len(names))
+	for _, name := range names {
+		go func() {
+			start := time.Now()
+			proof, err := c.provers[name].ComputeProof(runCtx, input)
+			if err == nil && proof == nil {
+				err = errors.New("prover returned no proof")
+			}
+			results <- Outcome{Prover: name, Proof: proof, Err: err, Elapsed: time.Since(start)}
+		}()
+	}
+
+	d := &Dispatch{done: make(chan struct{})}
+	var errs []error
+	for received := 0; received < len(names); {
+		select {
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		case o := <-results:
+			received++
+			o = c.check(ctx, input, o, nil)
+			d.outcomes = append(d.outcomes, o)
+			if o.Err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", o.Prover, o.Err))
+				continue
+			}
+			d.Prover, d.Proof = o.Prover, o.Proof
+			timer := time.AfterFunc(c.grace, cancel)
+			go func() {
+				c.collect(d, input, results, len(names)-received, d.Proof)
+				timer.Stop()
+				cancel()
+			}()
+			return d, nil
+		}
+	}
+	cancel()
+	close(d.done)
+	return nil, fmt.Errorf("no prover returned a valid proof: %w", errors.Join(errs...))
+}
+
+// collect 接收剩余的 n 个回应，与已接受的证明 reference 比较
+func (c *Coordinator) collect(d *Dispatch, input []byte, results <-chan Outcome, n int, reference *slothgo.Proof) {
+	for range n {
+		d.outcomes = append(d.outcomes, c.check(context.Background(), input, <-results, reference))
+	}
+	slices.SortFunc(d.outcomes, func(a, b Outcome) int {
+		return strings.Compare(a.Prover, b.Prover)
+	})
+	close(d.done)
+}
+
+// check 判断回应是否一致并在不一致时标记证明方
+// 有 reference 时逐字段与它比较，否则在本地验证证明。网络错误、超时等不算作不一致
+func (c *Coordinator) check(ctx context.Context, input []byte, o Outcome, reference *slothgo.Proof) Outcome {
+	var fault error
+	switch {
+	case errors.Is(o.Err, ErrInvalidProof):
+		fault = o.Err
+	case o.Err != nil:
+		return o
+	case reference != nil:
+		if !sameResult(o.Proof, reference) {
+			fault = errors.New("result differs from the accepted proof")
+		}
+	default:
+		ok, err := c.vdf.VerifyProofCtx(ctx, input, o.Proof)
+		if !ok {
+			if ctx.Err() != nil {
+				o.Err = ctx.Err()
+				return o
+			}
+			fault = fmt.Errorf("proof failed verification: %w", err)
+		}
+	}
+	if fault != nil {
+		o.Proof = nil
+		o.Err = fmt.Errorf("%w: %w", ErrInconsistentProver, fault)
+		c.flag(Fault{Prover: o.Prover, Input: bytes.Clone(input), Err: o.Err})
+	}
+	return o
+}
+
+// sameResult 比较两个证明的参数、哈希与 witness，检查点不参与比较
+func sameResult(a, b *slothgo.Proof) bool {
+	return a.Iterations == b.Iterations &&
+		bytes.Equal(a.Fingerprint, b.Fingerprint) &&
+		bytes.Equal(a.Hash, b.Hash) &&
+		a.Witness != nil && b.Witness != nil && a.Witness.Cmp(b.Witness) == 0
+}
+
+// Evaluate 实现 slothgo.VDF，返回第一个有效证明的输出与 witness 编码
+func (c *Coordinator) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	d, err := c.Compute(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.Proof.Hash, c.vdf.EncodeWitness(d.Proof.Witness), nil
+}
+
+// VerifyEvaluation 实现 slothgo.VDF，在本地验证
+func (c *Coordinator) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	return c.vdf.VerifyEvaluation(ctx, input, output, proof)
+}
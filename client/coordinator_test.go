@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// TestCoordinator 检查协调者接受第一个有效证明，并标记先到的伪造证明与后到的不一致结果
+func TestCoordinator(t *testing.T) {
+	vdf := newTestVDF(t)
+	honest := ProverFunc(vdf.ComputeProofCtx)
+	// forger 立即返回另一个输入的证明，它先于诚实的证明方到达
+	forged, err := vdf.ComputeProof([]byte("other input"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	forger := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		return forged, nil
+	})
+	// straggler 等诚实的证明被接受之后才返回一个不同的 witness
+	release := make(chan struct{})
+	straggler := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		<-release
+		p, err := vdf.ComputeProofCtx(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		p.Witness = new(big.Int).Add(p.Witness, big.NewInt(1))
+		return p, nil
+	})
+	down := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	var faults []string
+	faulted := make(chan struct{}, 4)
+	c, err := NewCoordinator(vdf, map[string]Prover{
+		"honest": honest, "forger": forger, "straggler": straggler, "down": down,
+	}, WithFaultHandler(func(f Fault) {
+		faults = append(faults, f.Prover)
+		faulted <- struct{}{}
+	}))
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+
+	input := []byte("round input")
+	d, err := c.Compute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if d.Prover != "honest" {
+		t.Errorf("accepted proof from %q", d.Prover)
+	}
+	if ok, err := vdf.VerifyProof(input, d.Proof); !ok {
+		t.Errorf("accepted proof does not verify: %v", err)
+	}
+	close(release)
+	outcomes := d.Wait()
+	if len(outcomes) != 4 {
+		t.Fatalf("got %d outcomes", len(outcomes))
+	}
+	for _, o := range outcomes {
+		inconsistent := errors.Is(o.Err, ErrInconsistentProver)
+		if want := o.Prover == "forger" || o.Prover == "straggler"; inconsistent != want {
+			t.Errorf("%s: outcome error %v", o.Prover, o.Err)
+		}
+	}
+
+	flagged := c.Flagged()
+	if len(flagged) != 2 || flagged["forger"].Err == nil || flagged["straggler"].Err == nil {
+		t.Errorf("Flagged returned %v", flagged)
+	}
+	<-faulted
+	<-faulted
+	if len(faults) != 2 {
+		t.Errorf("fault handler was called for %v", faults)
+	}
+
+	// 被标记的证明方不再参与分发
+	d, err = c.Compute(context.Background(), []byte("next"))
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if got := d.Wait(); len(got) != 2 {
+		t.Errorf("flagged provers were dispatched: %v", got)
+	}
+	c.Reinstate("straggler")
+	if _, ok := c.Flagged()["straggler"]; ok {
+		t.Error("Reinstate did not clear the flag")
+	}
+}
+
+// TestCoordinatorNoValidProof 检查没有证明方返回有效证明时 Compute 返回错误
+func TestCoordinatorNoValidProof(t *testing.T) {
+	vdf := newTestVDF(t)
+	down := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		return nil, errors.New("connection refused")
+	})
+	empty := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		return nil, nil
+	})
+	c, err := NewCoordinator(vdf, map[string]Prover{"down": down, "empty": empty})
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+	if _, err := c.Compute(context.Background(), []byte("x")); err == nil {
+		t.Error("Compute succeeded without a valid proof")
+	}
+	if len(c.Flagged()) != 0 {
+		t.Errorf("unavailable provers were flagged: %v", c.Flagged())
+	}
+}
+
+// TestCoordinatorCancel 检查 ctx 结束时 Compute 返回并取消证明方
+func TestCoordinatorCancel(t *testing.T) {
+	vdf := newTestVDF(t)
+	canceled := make(chan struct{})
+	slow := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	})
+	c, err := NewCoordinator(vdf, map[string]Prover{"slow": slow})
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Compute(ctx, []byte("x")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Compute returned %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("prover was not canceled")
+	}
+}
+
+// TestCoordinatorGracePeriod 检查宽限期结束后不再等待剩余的证明方
+func TestCoordinatorGracePeriod(t *testing.T) {
+	vdf := newTestVDF(t)
+	stuck := ProverFunc(func(ctx context.Context, input []byte) (*slothgo.Proof, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	c, err := NewCoordinator(vdf, map[string]Prover{"local": ProverFunc(vdf.ComputeProofCtx), "stuck": stuck},
+		WithGracePeriod(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewCoordinator failed: %v", err)
+	}
+	var v slothgo.VDF = c
+	output, proof, err := v.Evaluate(context.Background(), []byte("x"))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), []byte("x"), output, proof); !ok {
+		t.Errorf("VerifyEvaluation failed: %v", err)
+	}
+	if len(c.Flagged()) != 0 {
+		t.Errorf("stuck prover was flagged: %v", c.Flagged())
+	}
+}
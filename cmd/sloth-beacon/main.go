@@ -0,0 +1,66 @@
+// sloth-beacon 运行一个基于 Sloth 的随机信标服务
+// 每隔 -period 结束一次提交阶段并发布新的一轮，接口见 internal/beaconnode/server.go:
+//
+//	GET  /params                            Sloth 参数
+//	POST /contributions                     提交一段熵 (请求体为原始字节)
+//	GET  /rounds/latest                     最新的一轮
+//	GET  /rounds/events                     新轮次的 Server-Sent Events 流
+//	GET  /rounds/{index}                    第 index 轮
+//	GET  /rounds/{index}/contributions/{i}  第 i 个提交及其 Merkle 包含证明
+//	GET  /metrics                           Prometheus 格式的计算与验证指标
+//
+// 另外提供 drand 兼容的 GET /info、GET /public/latest 与 GET /public/{round}。
+// 节点的组装见 internal/beaconnode，sloth beacon run 用配置文件启动相同的节点
+//
+// 指定 -p2p 或 -peers 时同时作为 p2p 节点运行: 发布的轮次转发给相邻节点，
+// 并从相邻节点同步历史轮次; 加上 -follow 时只同步与转发而不自己发布。
+// 指定 -data 时轮次保存在该目录中 (store.FileStore)，重启后从最新的一轮继续。
+//
+// 选项也可以写在 -config (或环境变量 SLOTH_BEACON_CONFIG) 指定的 YAML 配置文件中，或者用 SLOTH_BEACON_
+// 开头的环境变量设置 (例如 SLOTH_BEACON_PEERS)，键见 beaconnode.Settings; 显式指定的选项优先于环境变量，
+// 环境变量优先于配置文件。设置在启动时统一检查，有误时退出
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/alan22333/sloth_go/internal/beaconnode"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+func main() {
+	s := beaconnode.DefaultSettings()
+	s.Register(flag.CommandLine)
+	configFile := flag.String("config", os.Getenv(beaconnode.EnvPrefix+"CONFIG"), "配置文件 (YAML), 键见 beaconnode.Settings")
+	flag.Parse()
+	if err := config.Load(&s, *configFile, beaconnode.EnvPrefix, flag.CommandLine); err != nil {
+		log.Fatalf("加载设置失败: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		log.Fatalf("设置有误: %v", err)
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		log.Fatalf("加载素数失败: %v", err)
+	}
+	node, err := beaconnode.New(cfg)
+	if err != nil {
+		log.Fatalf("创建信标节点失败: %v", err)
+	}
+	defer node.Close()
+	lis, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		log.Fatalf("监听失败: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := node.Run(ctx, lis); err != nil {
+		log.Fatalf("HTTP 服务失败: %v", err)
+	}
+}
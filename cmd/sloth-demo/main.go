@@ -0,0 +1,55 @@
+// sloth-demo 演示如何使用 slothgo 库完成一次完整的 VDF 计算与验证
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func main() {
+	bits := flag.Int("bits", 256, "素数 p 的位数")
+	iterations := flag.Uint64("iters", 100000, "延迟迭代次数")
+	input := flag.String("input", "A random zoo: sloth, unicorn, and trx", "VDF 输入")
+	flag.Parse()
+
+	// 1. 生成满足 p ≡ 3 (mod 4) 的素数
+	fmt.Printf("正在生成 %d 位素数...\n", *bits)
+	p, err := slothgo.GenerateSlothPrime(*bits)
+	if err != nil {
+		log.Fatalf("生成素数失败: %v", err)
+	}
+	fmt.Printf("p = %x\n", p)
+
+	// 2. 创建 VDF 实例，每完成 10% 打印一次进度与剩余时间估计
+	progress := slothgo.WithProgressInfo(func(pi slothgo.ProgressInfo) {
+		fmt.Printf("  %3d%%  %.0f 次/秒  剩余约 %v\n", pi.Done*100/pi.Total, pi.Rate, pi.ETA.Round(time.Millisecond))
+	}, max(*iterations/10, 1))
+	vdf, err := slothgo.New(p, *iterations, progress)
+	if err != nil {
+		log.Fatalf("创建 VDF 实例失败: %v", err)
+	}
+
+	// 3. 计算（慢）
+	start := time.Now()
+	hash, witness, err := vdf.Compute([]byte(*input))
+	if err != nil {
+		log.Fatalf("计算失败: %v", err)
+	}
+	computeTime := time.Since(start)
+	fmt.Printf("计算完成, 耗时 %v\n", computeTime)
+	fmt.Printf("hash    = %x\n", hash)
+	fmt.Printf("witness = %x\n", witness)
+
+	// 4. 验证（快）
+	start = time.Now()
+	ok, err := vdf.Verify([]byte(*input), hash, witness)
+	if err != nil {
+		log.Fatalf("验证失败: %v", err)
+	}
+	verifyTime := time.Since(start)
+	fmt.Printf("验证结果: %v, 耗时 %v\n", ok, verifyTime)
+}
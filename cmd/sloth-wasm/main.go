@@ -0,0 +1,71 @@
+//go:build js && wasm
+
+// sloth-wasm 把验证方编译为 WebAssembly，浏览器可以在本地验证证明、信标轮次与时间锁的打开，
+// 不需要把验证交给服务器:
+//
+//	GOOS=js GOARCH=wasm go build -o sloth.wasm ./cmd/sloth-wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// 加载后在 globalThis.slothgo 上注册三个函数，参数与结果都是 JSON 字符串，返回 Promise:
+//
+//	verifyProof(req)    {params | prime, min_iterations, input (十六进制), proof}
+//	verifyRound(req)    {params | prime, min_iterations, round, contributions, previous}
+//	verifyOpening(req)  {params | prime, min_iterations, capsule, proof}，验证通过时返回明文
+//
+// 证明可以是 JSON 对象，也可以是 Proof.String 的文本形式。证明无效时 Promise 正常完成，结果中
+// valid 为 false 并给出 error; 请求本身有误时 Promise 被拒绝。
+//
+// 取回信标轮次用浏览器自己的 fetch 完成，WebAssembly 中不包含 HTTP 客户端。同目录下的 sloth.js
+// 是对这些函数的薄封装，负责加载、编码转换与从信标节点 (需要允许跨域读取) 取回轮次，页面中通常直接使用它
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("slothgo", map[string]any{
+		"verifyProof":   export(verifyProof),
+		"verifyRound":   export(verifyRound),
+		"verifyOpening": export(verifyOpening),
+	})
+	select {}
+}
+
+// export 把 f 包装为接受一个 JSON 字符串、返回 Promise 的 JS 函数
+// f 在单独的 goroutine 中运行，取回轮次等阻塞操作不会卡住浏览器的事件循环
+func export(f func(ctx context.Context, req []byte) (any, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return js.Global().Get("Promise").Call("reject", jsError(errors.New("expected a single JSON string argument")))
+		}
+		req := []byte(args[0].String())
+		var executor js.Func
+		executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+			executor.Release()
+			resolve, reject := args[0], args[1]
+			go func() {
+				res, err := f(context.Background(), req)
+				if err != nil {
+					reject.Invoke(jsError(err))
+					return
+				}
+				data, err := json.Marshal(res)
+				if err != nil {
+					reject.Invoke(jsError(err))
+					return
+				}
+				resolve.Invoke(string(data))
+			}()
+			return nil
+		})
+		return js.Global().Get("Promise").New(executor)
+	})
+}
+
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
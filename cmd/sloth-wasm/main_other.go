@@ -0,0 +1,14 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// main 只在 GOOS=js GOARCH=wasm 之外的平台上编译，使 go build ./... 不会失败
+func main() {
+	fmt.Fprintln(os.Stderr, "sloth-wasm must be built with GOOS=js GOARCH=wasm")
+	os.Exit(2)
+}
@@ -0,0 +1,84 @@
+// sloth.js 是 sloth.wasm 的薄封装，在浏览器中本地验证 Sloth 证明、信标轮次与时间锁的打开。
+// 使用前先用 <script> 引入 Go 发行版中的 wasm_exec.js (见 main.go):
+//
+//	import { load } from './sloth.js'
+//	const sloth = await load('sloth.wasm')
+//	const round = await sloth.verifyRound('https://beacon.example.com')
+//	if (round.valid) console.log(round.randomness)
+//
+// 所有方法返回 Promise，结果中 valid 表示是否通过验证，未通过时 error 给出原因。
+// options 指定参数: { params: 'sloth-256-t30s' } 或 { prime: '十六进制素数' }，以及可选的
+// minIterations; 都不指定时按证明的指纹匹配标准参数集，信标轮次则使用节点公布的参数。
+
+// load 加载并启动 sloth.wasm，source 可以是 URL、Response 或者 wasm 文件的字节
+export async function load(source = new URL('sloth.wasm', import.meta.url)) {
+	if (typeof globalThis.Go !== 'function') {
+		throw new Error('wasm_exec.js must be loaded before sloth.js')
+	}
+	const go = new globalThis.Go()
+	let result
+	if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+		result = await WebAssembly.instantiate(source, go.importObject)
+	} else {
+		const resp = source instanceof Response ? source : await fetch(source)
+		result = await WebAssembly.instantiate(await resp.arrayBuffer(), go.importObject)
+	}
+	go.run(result.instance) // main 注册完函数后一直运行，不等待它结束
+	const api = globalThis.slothgo
+	const call = async (name, request) => JSON.parse(await api[name](JSON.stringify(request)))
+
+	return {
+		// verifyProof 验证 proof (JSON 对象或文本形式) 是 input (字符串或 Uint8Array) 的正确计算结果
+		verifyProof: (proof, input, options = {}) =>
+			call('verifyProof', { ...spec(options), proof, input: toHex(input) }),
+
+		// verifyRound 从信标节点 url 取回第 round 轮 (省略时为最新一轮) 及其全部提交并验证，节点不需要被信任
+		async verifyRound(url, round, options = {}) {
+			const base = String(url).replace(/\/+$/, '')
+			const get = async (path) => {
+				const resp = await fetch(base + path)
+				if (!resp.ok) {
+					throw new Error(`GET ${path}: ${resp.status} ${resp.statusText}`)
+				}
+				return resp.json()
+			}
+			const request = spec(options)
+			if (!request.params && !request.prime) {
+				const params = await get('/params')
+				request.prime = params.p
+				request.min_iterations = Math.max(request.min_iterations ?? 0, params.iterations)
+			}
+			const r = await get(round === undefined ? '/rounds/latest' : `/rounds/${round}`)
+			if (round !== undefined && r.index !== Number(round)) {
+				throw new Error(`requested round ${round}, got ${r.index}`)
+			}
+			const contributions = await Promise.all(Array.from({ length: r.contributions }, (_, i) =>
+				get(`/rounds/${r.index}/contributions/${i}`).then((c) => c.data)))
+			const previous = r.index > 0 ? (await get(`/rounds/${r.index - 1}`)).randomness : ''
+			return call('verifyRound', { ...request, round: r, contributions, previous })
+		},
+
+		// verifyOpening 检查他人公布的 proof 打开了时间锁密文 capsule，通过时 plaintext 为解密后的字节
+		async verifyOpening(capsule, proof, options = {}) {
+			const res = await call('verifyOpening', { ...spec(options), capsule, proof })
+			if (res.plaintext !== undefined) {
+				res.plaintext = fromHex(res.plaintext)
+			}
+			return res
+		},
+	}
+}
+
+// spec 把 options 转换为请求中的参数字段
+function spec({ params, prime, minIterations } = {}) {
+	return { params, prime, min_iterations: minIterations }
+}
+
+function toHex(data) {
+	const bytes = typeof data === 'string' ? new TextEncoder().encode(data) : new Uint8Array(data)
+	return Array.from(bytes, (b) => b.toString(16).padStart(2, '0')).join('')
+}
+
+function fromHex(s) {
+	return Uint8Array.from(s.match(/../g) ?? [], (b) => parseInt(b, 16))
+}
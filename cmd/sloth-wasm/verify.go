@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+	"github.com/alan22333/sloth_go/timelock"
+)
+
+// paramsSpec 指定验证使用的参数: 标准参数集名称或十六进制素数，都为空时按指纹匹配标准参数集
+type paramsSpec struct {
+	Params        string `json:"params,omitempty"`
+	Prime         string `json:"prime,omitempty"`
+	MinIterations uint64 `json:"min_iterations,omitempty"` // 证明至少需要声明的迭代次数
+}
+
+// sloth 按 spec 构造迭代次数为 iterations 的实例，返回标准参数集的名称 (如果有)
+// 请求本身有误时返回 requestError，参数与证明不一致时返回普通错误
+func (spec paramsSpec) sloth(iterations uint64, fingerprint []byte) (*slothgo.Sloth, string, error) {
+	if spec.Params != "" && spec.Prime != "" {
+		return nil, "", requestError{errors.New("params and prime are mutually exclusive")}
+	}
+	skip := slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck)
+	switch {
+	case spec.Params != "":
+		ps, err := slothgo.LookupParamSet(spec.Params)
+		if err != nil {
+			return nil, "", requestError{err}
+		}
+		vdf, err := slothgo.New(ps.Prime(), iterations, skip)
+		return vdf, ps.Name, err
+	case spec.Prime != "":
+		p, ok := new(big.Int).SetString(strings.TrimPrefix(spec.Prime, "0x"), 16)
+		if !ok {
+			return nil, "", requestError{errors.New("prime: invalid hexadecimal prime")}
+		}
+		vdf, err := slothgo.New(p, iterations)
+		return vdf, "", err
+	}
+	for _, ps := range slothgo.ParamSets() {
+		vdf, err := slothgo.New(ps.Prime(), iterations, skip)
+		if err == nil && string(vdf.Fingerprint()) == string(fingerprint) {
+			return vdf, ps.Name, nil
+		}
+	}
+	return nil, "", errors.New("proof does not match any standard parameter set, specify params or prime")
+}
+
+// checkIterations 检查证明声明的迭代次数不少于 spec.MinIterations
+func (spec paramsSpec) checkIterations(iterations uint64) error {
+	if iterations < spec.MinIterations {
+		return fmt.Errorf("proof claims %d iterations, at least %d required", iterations, spec.MinIterations)
+	}
+	return nil
+}
+
+// requestError 表示请求本身有误，JS 一侧的 Promise 以它拒绝，而不是返回 valid: false
+type requestError struct{ err error }
+
+func (e requestError) Error() string { return e.err.Error() }
+
+// invalid 判断 err 是否表示证明无效，而不是请求有误或者验证被取消
+func invalid(err error) bool {
+	var bad requestError
+	return !errors.As(err, &bad) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// decodeProof 解码 JSON 格式的证明，或者 Proof.String 的文本形式 (作为 JSON 字符串传入)
+func decodeProof(raw json.RawMessage) (*slothgo.Proof, error) {
+	if len(raw) == 0 {
+		return nil, requestError{errors.New("proof is required")}
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		if !strings.HasPrefix(strings.TrimSpace(text), "{") {
+			return slothgo.ParseProof(strings.TrimSpace(text))
+		}
+		raw = json.RawMessage(text)
+	}
+	proof := new(slothgo.Proof)
+	if err := json.Unmarshal(raw, proof); err != nil {
+		if !errors.Is(err, slothgo.ErrInvalidProofEncoding) {
+			err = &slothgo.ProofEncodingError{Format: "json", Err: err}
+		}
+		return nil, err
+	}
+	return proof, nil
+}
+
+// proofRequest 是 verifyProof 的参数
+type proofRequest struct {
+	paramsSpec
+	Input string          `json:"input"` // 十六进制
+	Proof json.RawMessage `json:"proof"`
+}
+
+// proofResult 是 verifyProof 的结果，与 sloth verify --json 的输出对应
+type proofResult struct {
+	Valid       bool   `json:"valid"`
+	Error       string `json:"error,omitempty"`
+	Params      string `json:"params,omitempty"`
+	Iterations  uint64 `json:"iterations"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Output      string `json:"output,omitempty"` // 证明的输出 (Proof.Hash)，验证通过时才有
+}
+
+// verifyProof 验证 req 中的证明，证明无效时在结果中给出原因
+func verifyProof(ctx context.Context, data []byte) (any, error) {
+	var req proofRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	input, err := hex.DecodeString(req.Input)
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	var res proofResult
+	fail := func(err error) (any, error) {
+		if !invalid(err) {
+			return nil, err
+		}
+		res.Error = err.Error()
+		return res, nil
+	}
+	proof, err := decodeProof(req.Proof)
+	if err != nil {
+		return fail(err)
+	}
+	res.Iterations, res.Fingerprint = proof.Iterations, hex.EncodeToString(proof.Fingerprint)
+	if err := req.checkIterations(proof.Iterations); err != nil {
+		return fail(err)
+	}
+	vdf, name, err := req.sloth(proof.Iterations, proof.Fingerprint)
+	res.Params = name
+	if err != nil {
+		return fail(err)
+	}
+	if ok, err := vdf.VerifyProofCtx(ctx, input, proof); !ok {
+		if err == nil {
+			err = errors.New("verification failed")
+		}
+		return fail(err)
+	}
+	res.Valid, res.Output = true, hex.EncodeToString(proof.Hash)
+	return res, nil
+}
+
+// roundRequest 是 verifyRound 的参数，轮次与提交由页面从信标节点取回 (见 sloth.js)
+type roundRequest struct {
+	paramsSpec
+	Round         beaconapi.RoundResponse `json:"round"`         // GET /rounds/{index} 的响应
+	Contributions []string                `json:"contributions"` // 全部提交，十六进制
+	Previous      string                  `json:"previous"`      // 上一轮的随机数，第 0 轮为空
+}
+
+// roundResult 是 verifyRound 的结果，与 sloth beacon get --json 的输出对应
+type roundResult struct {
+	Valid             bool   `json:"valid"`
+	Error             string `json:"error,omitempty"`
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Previous          string `json:"previous"`
+	Contributions     int    `json:"contributions"`
+	ContributionsRoot string `json:"contributions_root"`
+	Iterations        uint64 `json:"iterations"`
+	Fingerprint       string `json:"fingerprint"`
+}
+
+// verifyRound 验证一轮信标输出及其与上一轮的链接，轮次来自不受信任的节点，格式有误同样视为无效
+func verifyRound(ctx context.Context, data []byte) (any, error) {
+	var req roundRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	resp := req.Round
+	res := roundResult{
+		Round:             resp.Index,
+		Randomness:        resp.Randomness,
+		Previous:          resp.Previous,
+		Contributions:     resp.Contributions,
+		ContributionsRoot: resp.ContributionsRoot,
+		Iterations:        resp.Proof.Iterations,
+		Fingerprint:       resp.Proof.Fingerprint,
+	}
+	fail := func(err error) (any, error) {
+		if !invalid(err) {
+			return nil, err
+		}
+		res.Error = err.Error()
+		return res, nil
+	}
+	if err := req.checkIterations(resp.Proof.Iterations); err != nil {
+		return fail(err)
+	}
+	fingerprint, err := hex.DecodeString(resp.Proof.Fingerprint)
+	if err != nil {
+		return fail(fmt.Errorf("fingerprint: %w", err))
+	}
+	vdf, _, err := req.sloth(resp.Proof.Iterations, fingerprint)
+	if err != nil {
+		return fail(err)
+	}
+	contributions := make([][]byte, len(req.Contributions))
+	for i, c := range req.Contributions {
+		if contributions[i], err = hex.DecodeString(c); err != nil {
+			return fail(fmt.Errorf("contribution %d: %w", i, err))
+		}
+	}
+	r, err := resp.Round(vdf, contributions)
+	if err != nil {
+		return fail(err)
+	}
+	var prev *beacon.Round
+	if r.Index > 0 {
+		prev = &beacon.Round{Index: r.Index - 1}
+		if prev.Randomness, err = hex.DecodeString(req.Previous); err != nil {
+			return fail(fmt.Errorf("previous: %w", err))
+		}
+	}
+	if err := beacon.Verify(vdf, r, prev); err != nil {
+		return fail(err)
+	}
+	res.Valid = true
+	return res, nil
+}
+
+// openingRequest 是 verifyOpening 的参数
+type openingRequest struct {
+	paramsSpec
+	Capsule *timelock.Capsule `json:"capsule"`
+	Proof   json.RawMessage   `json:"proof"`
+}
+
+// openingResult 是 verifyOpening 的结果
+type openingResult struct {
+	Valid      bool   `json:"valid"`
+	Error      string `json:"error,omitempty"`
+	Params     string `json:"params,omitempty"`
+	Iterations uint64 `json:"iterations"`
+	Plaintext  string `json:"plaintext,omitempty"` // 十六进制，验证通过时才有
+}
+
+// verifyOpening 检查他人公布的证明确实打开了时间锁密文，并用它解密
+func verifyOpening(ctx context.Context, data []byte) (any, error) {
+	var req openingRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	if req.Capsule == nil {
+		return nil, errors.New("capsule is required")
+	}
+	res := openingResult{Iterations: req.Capsule.Iterations}
+	fail := func(err error) (any, error) {
+		if !invalid(err) {
+			return nil, err
+		}
+		res.Error = err.Error()
+		return res, nil
+	}
+	proof, err := decodeProof(req.Proof)
+	if err != nil {
+		return fail(err)
+	}
+	if err := req.checkIterations(req.Capsule.Iterations); err != nil {
+		return fail(err)
+	}
+	vdf, name, err := req.sloth(req.Capsule.Iterations, req.Capsule.Fingerprint)
+	res.Params = name
+	if err != nil {
+		return fail(err)
+	}
+	plaintext, err := timelock.Decrypt(ctx, vdf, req.Capsule, proof)
+	if err != nil {
+		return fail(err)
+	}
+	res.Valid, res.Plaintext = true, hex.EncodeToString(plaintext)
+	return res, nil
+}
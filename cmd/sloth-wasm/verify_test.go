@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+	"github.com/alan22333/sloth_go/timelock"
+)
+
+// call 以 JSON 请求调用 f，并把结果重新解析为 JSON 对象，与 JS 一侧看到的一致
+func call(t *testing.T, f func(context.Context, []byte) (any, error), req any) (map[string]any, error) {
+	t.Helper()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := f(context.Background(), data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out, nil
+}
+
+func standardSloth(t *testing.T, iterations uint64) *slothgo.Sloth {
+	t.Helper()
+	ps, err := slothgo.LookupParamSet("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdf, err := slothgo.New(ps.Prime(), iterations, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vdf
+}
+
+func TestVerifyProof(t *testing.T) {
+	vdf := standardSloth(t, 300)
+	proof, err := vdf.ComputeProof([]byte("hello"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	input := hex.EncodeToString([]byte("hello"))
+
+	// 指纹匹配标准参数集，证明可以是 JSON 对象或文本形式
+	for _, p := range []any{proof, proof.String()} {
+		res, err := call(t, verifyProof, map[string]any{"input": input, "proof": p})
+		if err != nil || res["valid"] != true || res["params"] != "sloth-256-t30s" || res["output"] != hex.EncodeToString(proof.Hash) {
+			t.Errorf("valid proof returned %v, %v", res, err)
+		}
+	}
+
+	res, err := call(t, verifyProof, map[string]any{"input": input, "proof": proof, "params": "sloth-256-t30s", "min_iterations": 300})
+	if err != nil || res["valid"] != true {
+		t.Errorf("explicit parameter set returned %v, %v", res, err)
+	}
+	for name, req := range map[string]map[string]any{
+		"wrong input":   {"input": "00", "proof": proof},
+		"too few iters": {"input": input, "proof": proof, "min_iterations": 301},
+		"other prime":   {"input": input, "proof": proof, "prime": "17"},
+		"garbled proof": {"input": input, "proof": "not a proof"},
+	} {
+		res, err := call(t, verifyProof, req)
+		if err != nil || res["valid"] != false || res["error"] == "" {
+			t.Errorf("%s: expected an invalid result, got %v, %v", name, res, err)
+		}
+	}
+
+	// 请求本身有误时返回错误，JS 一侧的 Promise 被拒绝
+	for name, req := range map[string]map[string]any{
+		"missing proof":  {"input": input},
+		"bad input":      {"input": "zz", "proof": proof},
+		"unknown params": {"input": input, "proof": proof, "params": "nope"},
+		"both params":    {"input": input, "proof": proof, "params": "sloth-256-t30s", "prime": "17"},
+	} {
+		if _, err := call(t, verifyProof, req); err == nil {
+			t.Errorf("%s: request was accepted", name)
+		}
+	}
+}
+
+func TestVerifyRound(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	var rounds []*beacon.Round
+	for _, c := range []string{"first", "second"} {
+		b.Contribute([]byte(c))
+		r, err := b.Publish(context.Background())
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		rounds = append(rounds, r)
+	}
+	request := func(r *beacon.Round, previous []byte) map[string]any {
+		var contributions []string
+		for _, c := range r.Contributions {
+			contributions = append(contributions, hex.EncodeToString(c))
+		}
+		return map[string]any{
+			"prime":         p.Text(16),
+			"round":         beaconapi.NewRoundResponse(vdf, r),
+			"contributions": contributions,
+			"previous":      hex.EncodeToString(previous),
+		}
+	}
+
+	for i, req := range []map[string]any{request(rounds[0], nil), request(rounds[1], rounds[0].Randomness)} {
+		res, err := call(t, verifyRound, req)
+		if err != nil || res["valid"] != true || res["round"] != float64(i) {
+			t.Errorf("round %d returned %v, %v", i, res, err)
+		}
+	}
+
+	forged := request(rounds[1], rounds[0].Randomness)
+	forged["contributions"] = []string{hex.EncodeToString([]byte("forged"))}
+	unlinked := request(rounds[1], []byte("other"))
+	slow := request(rounds[1], rounds[0].Randomness)
+	slow["min_iterations"] = 201
+	for name, req := range map[string]map[string]any{"forged": forged, "unlinked": unlinked, "min_iterations": slow} {
+		res, err := call(t, verifyRound, req)
+		if err != nil || res["valid"] != false {
+			t.Errorf("%s round returned %v, %v", name, res, err)
+		}
+	}
+}
+
+func TestVerifyOpening(t *testing.T) {
+	vdf := standardSloth(t, 300)
+	c, err := timelock.Seal(vdf, []byte("sealed"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	_, proof, err := timelock.Open(context.Background(), vdf, c)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	res, err := call(t, verifyOpening, map[string]any{"capsule": c, "proof": proof})
+	if err != nil || res["valid"] != true || res["plaintext"] != hex.EncodeToString([]byte("sealed")) {
+		t.Errorf("valid opening returned %v, %v", res, err)
+	}
+	other, err := vdf.ComputeProof([]byte("unrelated"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = call(t, verifyOpening, map[string]any{"capsule": c, "proof": other})
+	if err != nil || res["valid"] != false || res["plaintext"] != nil {
+		t.Errorf("unrelated proof returned %v, %v", res, err)
+	}
+	if _, err := call(t, verifyOpening, map[string]any{"proof": proof}); err == nil {
+		t.Error("missing capsule was accepted")
+	}
+
+	var bad requestError
+	_, err = call(t, verifyOpening, map[string]any{"capsule": c, "proof": proof, "params": "nope"})
+	if !errors.As(err, &bad) || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("unknown parameter set returned %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+	"github.com/alan22333/sloth_go/internal/beaconnode"
+	"github.com/alan22333/sloth_go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newBeaconCmd() *cobra.Command {
+	return newGroupCmd("beacon", "运行信标节点或验证远程节点的轮次", newBeaconRunCmd(), newBeaconGetCmd())
+}
+
+// newBeaconRunCmd 创建 beacon run 命令，按配置文件启动完整的信标节点，直到收到中断信号
+// 配置文件的键见 beaconnode.Settings，SLOTH_BEACON_ 开头的环境变量覆盖配置文件中的值
+// 指定 --json 时节点的日志以每行一个 JSON 对象输出到标准输出
+func newBeaconRunCmd() *cobra.Command {
+	cmd := newCommand("run --config 文件 [--json]", "按配置文件启动信标节点")
+	fs := cmd.Flags()
+	configFile := fs.String("config", "", `YAML 配置文件, 键与 sloth-beacon 的选项对应, "-" 表示标准输入`)
+	asJSON := fs.Bool("json", false, `日志以每行一个 JSON 对象 {"time", "msg"} 输出到标准输出`)
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdin, stdout := cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout()
+		if *configFile == "" {
+			return usageError(cmd, "--config is required")
+		}
+		data, err := readInput(*configFile, stdin)
+		if err != nil {
+			return err
+		}
+		settings := beaconnode.DefaultSettings()
+		if err := config.Decode(data, &settings); err != nil {
+			return fmt.Errorf("%s: %w", *configFile, err)
+		}
+		if err := config.ApplyEnv(&settings, beaconnode.EnvPrefix, os.LookupEnv); err != nil {
+			return err
+		}
+		if err := settings.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", *configFile, err)
+		}
+		nc, err := settings.Config()
+		if err != nil {
+			return fmt.Errorf("%s: %w", *configFile, err)
+		}
+		if *asJSON {
+			flags, out := log.Flags(), log.Writer()
+			log.SetFlags(0)
+			log.SetOutput(jsonLog{stdout})
+			defer func() {
+				log.SetFlags(flags)
+				log.SetOutput(out)
+			}()
+		}
+		node, err := beaconnode.New(nc)
+		if err != nil {
+			return err
+		}
+		defer node.Close()
+		lis, err := net.Listen("tcp", settings.Addr)
+		if err != nil {
+			return err
+		}
+		return node.Run(ctx, lis)
+	}
+	return cmd
+}
+
+// jsonLog 把 log 包输出的每一行转换为一个 JSON 对象
+type jsonLog struct {
+	w io.Writer
+}
+
+func (l jsonLog) Write(p []byte) (int, error) {
+	data, err := json.Marshal(struct {
+		Time time.Time `json:"time"`
+		Msg  string    `json:"msg"`
+	}{time.Now(), strings.TrimSuffix(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// defaultMaxRoundIterations 是 beacon get 默认接受的最大迭代次数，
+// 为 sloth-256-t30s 推荐值的 250 倍
+const defaultMaxRoundIterations = 100_000_000
+
+// roundResult 是 beacon get 的输出，error 是轮次没有通过验证的原因
+type roundResult struct {
+	Valid             bool   `json:"valid"`
+	Error             string `json:"error,omitempty"`
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Previous          string `json:"previous"`
+	Contributions     int    `json:"contributions"`
+	ContributionsRoot string `json:"contributions_root"`
+	Iterations        uint64 `json:"iterations"`
+	Fingerprint       string `json:"fingerprint"`
+}
+
+// newBeaconGetCmd 创建 beacon get 命令，从远程节点取回一轮并在本地验证: 提交的 Merkle 根、种子、与上一轮的链接以及 Sloth 证明
+// 没有指定 --prime-file 或 --params 时使用节点公布的参数
+func newBeaconGetCmd() *cobra.Command {
+	cmd := newCommand("get --url 地址 [--round N] [--prime-file 文件 | --params 名称] [--iters N] [--max-iterations N] [--json]", "从远程节点取回一轮并在本地验证")
+	fs := cmd.Flags()
+	var pf paramFlags
+	pf.register(fs)
+	url := fs.String("url", "", "信标节点的 REST 地址, 例如 http://beacon:8080")
+	round := fs.Int64("round", -1, "轮次序号, 负数表示最新的一轮")
+	minIterations := fs.Uint64("iters", 0, "证明至少需要声明的迭代次数, 0 表示不限制")
+	maxIterations := fs.Uint64("max-iterations", defaultMaxRoundIterations, "轮次最多可以声明的迭代次数, 超过时不验证直接拒绝")
+	asJSON := fs.Bool("json", false, "以 JSON 输出轮次与验证结果, 验证失败时同样输出")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdin, stdout := cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout()
+		if *url == "" {
+			return usageError(cmd, "--url is required")
+		}
+		if *maxIterations < max(*minIterations, 1) {
+			return usageError(cmd, "--max-iterations must be at least --iters and positive")
+		}
+		if err := pf.check(cmd); err != nil {
+			return err
+		}
+		c, err := beaconapi.NewClient(*url, nil)
+		if err != nil {
+			return usageError(cmd, "--url: %v", err)
+		}
+
+		p, standard, err := pf.prime(stdin)
+		if err != nil {
+			return err
+		}
+		var iterations uint64
+		if p == nil {
+			if p, iterations, err = c.Params(ctx); err != nil {
+				return err
+			}
+		}
+		var opts []slothgo.Option
+		if standard {
+			opts = append(opts, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		}
+		vdf, err := slothgo.New(p, max(iterations, 1), opts...)
+		if err != nil {
+			return err
+		}
+
+		index := uint64(*round)
+		if *round < 0 {
+			if index, err = c.Latest(ctx); err != nil {
+				return err
+			}
+		}
+		r, err := c.Round(ctx, vdf, index)
+		if err != nil {
+			return err
+		}
+		var prev *beacon.Round
+		if r.Index > 0 {
+			prev = &beacon.Round{Index: r.Index - 1}
+			if prev.Randomness, err = c.Randomness(ctx, prev.Index); err != nil {
+				return err
+			}
+		}
+
+		res := roundResult{
+			Round:             r.Index,
+			Randomness:        hex.EncodeToString(r.Randomness),
+			Previous:          hex.EncodeToString(r.Previous),
+			Contributions:     len(r.Contributions),
+			ContributionsRoot: hex.EncodeToString(r.ContributionsRoot),
+			Iterations:        r.Proof.Iterations,
+			Fingerprint:       hex.EncodeToString(r.Proof.Fingerprint),
+		}
+		err = verifyRound(vdf, r, prev, max(*minIterations, iterations), *maxIterations)
+		var invalid *invalidProofError
+		switch {
+		case err == nil:
+			res.Valid = true
+		case errors.As(err, &invalid):
+			res.Error = invalid.err.Error()
+		default:
+			return err
+		}
+		if *asJSON {
+			if err := writeJSON(stdout, res); err != nil {
+				return err
+			}
+			return err
+		}
+		if !res.Valid {
+			return err
+		}
+		fmt.Fprintf(stdout, "轮次        %d\n", res.Round)
+		fmt.Fprintf(stdout, "随机数      %s\n", res.Randomness)
+		fmt.Fprintf(stdout, "上一轮      %s\n", res.Previous)
+		fmt.Fprintf(stdout, "提交        %d 个, 根 %s\n", res.Contributions, res.ContributionsRoot)
+		fmt.Fprintf(stdout, "迭代次数    %d\n", res.Iterations)
+		fmt.Fprintf(stdout, "参数指纹    %s\n", res.Fingerprint)
+		return nil
+	}
+	return cmd
+}
+
+// verifyRound 按轮次声明的迭代次数验证 r，迭代次数不在 [minIterations, maxIterations] 内或验证失败时返回 invalidProofError
+// 迭代次数由远程节点声明，先检查上限，避免一个恶意节点让验证长时间占用 CPU
+func verifyRound(vdf *slothgo.Sloth, r, prev *beacon.Round, minIterations, maxIterations uint64) error {
+	if r.Proof.Iterations < minIterations {
+		return &invalidProofError{err: fmt.Errorf("round claims %d iterations, at least %d required", r.Proof.Iterations, minIterations)}
+	}
+	if r.Proof.Iterations > maxIterations {
+		return &invalidProofError{err: fmt.Errorf("round claims %d iterations, at most %d allowed", r.Proof.Iterations, maxIterations)}
+	}
+	vdf, err := vdf.WithIterations(r.Proof.Iterations)
+	if err != nil {
+		return err
+	}
+	if err := beacon.Verify(vdf, r, prev); err != nil {
+		return &invalidProofError{err: err}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+	"github.com/alan22333/sloth_go/internal/beaconnode"
+)
+
+// TestBeaconRunConfig 检查 beacon run 在启动之前拒绝无效的配置，配置可以从标准输入读取
+func TestBeaconRunConfig(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		want   int
+	}{
+		{"port: 80\n", exitError},
+		{"params: sloth-256-t30s\nprime: 17\n", exitError},
+		{"period: 0s\n", exitError},
+		{"follow: true\n", exitError},
+	} {
+		if code, _, stderr := pipe(t, []byte(tc.config), "beacon", "run", "--config", "-"); code != tc.want {
+			t.Errorf("config %q exited with %d, want %d: %s", tc.config, code, tc.want, stderr)
+		}
+	}
+	t.Setenv("SLOTH_BEACON_PERIOD", "never")
+	if code, _, _ := pipe(t, []byte("period: 1s\n"), "beacon", "run", "--config", "-"); code != exitError {
+		t.Errorf("invalid environment variable exited with %d", code)
+	}
+	if code, _, _ := pipe(t, nil, "beacon", "run"); code != exitUsage {
+		t.Errorf("missing --config exited with %d", code)
+	}
+}
+
+// TestBeaconGet 启动一个信标节点，从中取回轮次并在本地验证; 被篡改的提交不能通过验证
+func TestBeaconGet(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	node, err := beaconnode.New(beaconnode.Config{Prime: p, Iterations: 200, Period: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("beaconnode.New failed: %v", err)
+	}
+	defer node.Close()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go node.Run(ctx, lis)
+	url := "http://" + lis.Addr().String()
+
+	c, _ := beaconapi.NewClient(url, nil)
+	for {
+		latest, err := c.Latest(ctx)
+		if err == nil && latest >= 1 {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("no rounds were published: %v", err)
+		}
+		if resp, err := http.Post(url+"/contributions", "application/octet-stream", strings.NewReader("entropy")); err == nil {
+			resp.Body.Close()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	get := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(ctx, append([]string{"beacon", "get"}, args...), strings.NewReader(""), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+	randomness, _ := c.Randomness(ctx, 1)
+	code, out, stderr := get("--url", url, "--round", "1")
+	if code != exitOK {
+		t.Fatalf("beacon get exited with %d: %s", code, stderr)
+	}
+	if !strings.Contains(out, hex.EncodeToString(randomness)) {
+		t.Errorf("output does not contain the randomness of round 1:\n%s", out)
+	}
+	var res roundResult
+	if code, out, stderr := get("--url", url, "--round", "1", "--json"); code != exitOK || json.Unmarshal([]byte(out), &res) != nil || !res.Valid || res.Round != 1 {
+		t.Errorf("beacon get --json exited with %d: %s%s", code, out, stderr)
+	}
+	if code, _, stderr := get("--url", url); code != exitOK {
+		t.Errorf("beacon get of the latest round exited with %d: %s", code, stderr)
+	}
+	if code, _, stderr := get("--url", url, "--round", "1", "--iters", "1000"); code != exitInvalid {
+		t.Errorf("round with too few iterations exited with %d: %s", code, stderr)
+	}
+	if code, _, stderr := get("--url", url, "--round", "1", "--max-iterations", "100"); code != exitInvalid || !strings.Contains(stderr, "at most 100") {
+		t.Errorf("round with too many iterations exited with %d: %s", code, stderr)
+	}
+	if code, _, _ := get("--url", url, "--iters", "1000", "--max-iterations", "100"); code != exitUsage {
+		t.Errorf("--max-iterations below --iters exited with %d", code)
+	}
+	if code, _, _ := get("--url", url, "--round", "1000000"); code != exitError {
+		t.Errorf("missing round exited with %d", code)
+	}
+	if code, _, _ := get("--round", "1"); code != exitUsage {
+		t.Errorf("missing --url exited with %d", code)
+	}
+
+	// 代理把所有提交替换成别的数据，Merkle 根不再一致
+	tampered := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/contributions/") {
+			node.Handler().ServeHTTP(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(beaconapi.ContributionResponse{Data: hex.EncodeToString([]byte("forged"))})
+	}))
+	defer tampered.Close()
+	for i := uint64(0); ; i++ {
+		r, err := c.Round(ctx, node.VDF(), i)
+		if err != nil {
+			t.Fatalf("no round with contributions: %v", err)
+		}
+		if len(r.Contributions) > 0 {
+			if code, _, stderr := get("--url", tampered.URL, "--round", strconv.FormatUint(r.Index, 10)); code != exitInvalid {
+				t.Errorf("tampered round exited with %d: %s", code, stderr)
+			}
+			code, out, _ := get("--url", tampered.URL, "--round", strconv.FormatUint(r.Index, 10), "--json")
+			if code != exitInvalid || json.Unmarshal([]byte(out), &res) != nil || res.Valid || res.Error == "" {
+				t.Errorf("tampered round with --json exited with %d: %s", code, out)
+			}
+			break
+		}
+	}
+}
+
+// TestJSONLog 检查 beacon run --json 的日志格式
+func TestJSONLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New(jsonLog{&buf}, "", 0)
+	l.Printf("第 %d 轮", 3)
+	var line struct {
+		Time time.Time `json:"time"`
+		Msg  string    `json:"msg"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil || line.Msg != "第 3 轮" || line.Time.IsZero() {
+		t.Errorf("unexpected log line %q: %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/spf13/cobra"
+)
+
+// tQuantile975 是自由度为 1..30 的 t 分布的 97.5% 分位数，用于小样本的 95% 置信区间
+var tQuantile975 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// interval 是一组测量值的均值与 95% 置信区间
+type interval struct {
+	Mean float64 `json:"mean"`
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// meanInterval 返回 xs 的均值及其 95% 置信区间 (t 分布)，只有一个样本时区间退化为均值
+func meanInterval(xs []float64) interval {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	n := float64(len(xs))
+	mean := sum / n
+	if len(xs) < 2 {
+		return interval{Mean: mean, Low: mean, High: mean}
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	t := 1.96
+	if df := len(xs) - 1; df <= len(tQuantile975) {
+		t = tQuantile975[df-1]
+	}
+	half := t * math.Sqrt(ss/(n-1)/n)
+	return interval{Mean: mean, Low: mean - half, High: mean + half}
+}
+
+// calibrateReport 是 sloth calibrate 的结果，速率为每秒迭代次数
+type calibrateReport struct {
+	Platform     string   `json:"platform"`
+	CPUs         int      `json:"cpus"`
+	Bits         int      `json:"bits"`
+	Params       string   `json:"params,omitempty"`
+	Rounds       int      `json:"rounds"`
+	ForwardRate  interval `json:"forward_rate"`
+	InverseRate  interval `json:"inverse_rate"`
+	TargetDelay  string   `json:"target_delay"`
+	Iterations   uint64   `json:"iterations"`
+	IterationsLo uint64   `json:"iterations_low"`
+	IterationsHi uint64   `json:"iterations_high"`
+	VerifyTime   string   `json:"verify_time"`
+	Asymmetry    float64  `json:"asymmetry"` // 计算与验证的耗时之比
+}
+
+func newCalibrateCmd() *cobra.Command {
+	cmd := newCommand("calibrate [--target 时长] [--bits N | --prime-file 文件 | --params 名称] [--rounds N] [--json]", "测量本机的计算与验证速度")
+	fs := cmd.Flags()
+	var pf paramFlags
+	pf.register(fs)
+	bits := fs.Int("bits", 256, "素数的位数, 有同样位数的标准参数集时使用其素数")
+	target := fs.Duration("target", 30*time.Second, "目标计算耗时")
+	rounds := fs.Int("rounds", 5, "测量次数, 用于估计置信区间")
+	sample := fs.Duration("sample", 500*time.Millisecond, "每次测量每个方向的时长")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdin, stdout := cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout()
+		if err := pf.check(cmd); err != nil {
+			return err
+		}
+		switch {
+		case *target <= 0 || *sample <= 0:
+			return usageError(cmd, "--target and --sample must be positive")
+		case *rounds < 1:
+			return usageError(cmd, "--rounds must be at least 1")
+		case *bits < 3:
+			return usageError(cmd, "--bits must be at least 3")
+		}
+
+		p, _, err := pf.prime(stdin)
+		if err != nil {
+			return err
+		}
+		name := pf.params
+		if p == nil {
+			p, name, err = primeWithBits(*bits)
+			if err != nil {
+				return err
+			}
+		}
+
+		forward := make([]float64, 0, *rounds)
+		inverse := make([]float64, 0, *rounds)
+		for range *rounds {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			c, err := slothgo.Calibrate(p, *sample, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+			if err != nil {
+				return err
+			}
+			forward = append(forward, c.ForwardRate)
+			inverse = append(inverse, c.InverseRate)
+		}
+		r := calibrateReport{
+			Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+			CPUs:        runtime.NumCPU(),
+			Bits:        p.BitLen(),
+			Params:      name,
+			Rounds:      *rounds,
+			ForwardRate: meanInterval(forward),
+			InverseRate: meanInterval(inverse),
+			TargetDelay: target.String(),
+		}
+		r.Iterations = iterationsFor(r.ForwardRate.Mean, *target)
+		r.IterationsLo = iterationsFor(r.ForwardRate.Low, *target)
+		r.IterationsHi = iterationsFor(r.ForwardRate.High, *target)
+		r.VerifyTime = time.Duration(float64(r.Iterations) / r.InverseRate.Mean * float64(time.Second)).Round(time.Microsecond).String()
+		r.Asymmetry = r.InverseRate.Mean / r.ForwardRate.Mean
+
+		if *asJSON {
+			return writeJSON(stdout, r)
+		}
+		label := fmt.Sprintf("%d 位", r.Bits)
+		if r.Params != "" {
+			label += " (" + r.Params + ")"
+		}
+		fmt.Fprintf(stdout, "平台      %s, %d 核 (单核测量 %d 次)\n", r.Platform, r.CPUs, r.Rounds)
+		fmt.Fprintf(stdout, "素数      %s\n", label)
+		fmt.Fprintf(stdout, "计算      %.0f 次/秒 (95%% 置信区间 %.0f – %.0f)\n", r.ForwardRate.Mean, r.ForwardRate.Low, r.ForwardRate.High)
+		fmt.Fprintf(stdout, "验证      %.0f 次/秒 (95%% 置信区间 %.0f – %.0f)\n", r.InverseRate.Mean, r.InverseRate.Low, r.InverseRate.High)
+		fmt.Fprintf(stdout, "建议      %s 对应 %d 次迭代 (95%% 置信区间 %d – %d)\n", r.TargetDelay, r.Iterations, r.IterationsLo, r.IterationsHi)
+		fmt.Fprintf(stdout, "验证耗时  约 %s, 计算/验证 %.0fx\n", r.VerifyTime, r.Asymmetry)
+		return nil
+	}
+	return cmd
+}
+
+// iterationsFor 返回以每秒 rate 次计算 d 所需的迭代次数，至少为 1
+func iterationsFor(rate float64, d time.Duration) uint64 {
+	n := math.Round(rate * d.Seconds())
+	switch {
+	case !(n >= 1):
+		return 1
+	case n >= float64(slothgo.MaxIterations):
+		return slothgo.MaxIterations
+	}
+	return uint64(n)
+}
+
+// primeWithBits 返回位数为 bits 的标准参数集的素数，没有时由固定的种子派生
+// 吞吐量只取决于素数的位数，因此不需要随机素数
+func primeWithBits(bits int) (*big.Int, string, error) {
+	for _, ps := range slothgo.ParamSets() {
+		if ps.Bits == bits {
+			return ps.Prime(), ps.Name, nil
+		}
+	}
+	p, err := slothgo.DerivePrime([]byte("sloth calibrate"), bits)
+	return p, "", err
+}
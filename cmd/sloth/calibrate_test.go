@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMeanInterval(t *testing.T) {
+	iv := meanInterval([]float64{9, 10, 11})
+	// 标准误差 1/√3，自由度 2 的分位数 4.303
+	half := 4.303 / math.Sqrt(3)
+	if iv.Mean != 10 || math.Abs(iv.Low-(10-half)) > 1e-9 || math.Abs(iv.High-(10+half)) > 1e-9 {
+		t.Errorf("meanInterval = %+v", iv)
+	}
+	if iv := meanInterval([]float64{5}); iv.Low != 5 || iv.High != 5 {
+		t.Errorf("single sample interval = %+v", iv)
+	}
+}
+
+// TestCalibrate 检查 JSON 输出的字段彼此一致
+func TestCalibrate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), strings.Fields("calibrate --bits 64 --target 2s --rounds 2 --sample 5ms --json"), nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("calibrate exited with %d: %s", code, stderr.String())
+	}
+	var r calibrateReport
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		t.Fatalf("malformed JSON: %v\n%s", err, stdout.String())
+	}
+	if r.Bits != 64 || r.Rounds != 2 || r.ForwardRate.Mean <= 0 || r.Asymmetry <= 0 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if r.Iterations != iterationsFor(r.ForwardRate.Mean, 2e9) || r.IterationsLo > r.Iterations || r.IterationsHi < r.Iterations {
+		t.Errorf("iterations %d not within [%d, %d]", r.Iterations, r.IterationsLo, r.IterationsHi)
+	}
+
+	for _, args := range []string{"calibrate --rounds 0", "calibrate --target 0s", "calibrate --params nope"} {
+		if code, _ := sloth(t, strings.Fields(args)...); code != exitUsage {
+			t.Errorf("sloth %s exited with %d, want %d", args, code, exitUsage)
+		}
+	}
+}
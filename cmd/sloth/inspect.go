@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/spf13/cobra"
+)
+
+// 证明的编码格式
+const (
+	formatBinary = "binary" // Proof.MarshalBinary
+	formatText   = "text"   // Proof.String，MarshalBinary 的 base64url
+	formatJSON   = "json"
+	formatCBOR   = "cbor"
+	formatDER    = "der"
+)
+
+// binaryMagic 与 binaryExtNames 对应 Proof.MarshalBinary 文档中的格式
+var (
+	binaryMagic    = []byte("SLTH")
+	binaryExtNames = map[byte]string{0x01: "区段检查点", 0x02: "状态承诺"}
+)
+
+// extension 是二进制格式中的一条扩展记录
+type extension struct {
+	Type     byte   `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Critical bool   `json:"critical"`
+	Length   int    `json:"length"`
+	Data     string `json:"data,omitempty"` // 未知扩展的内容 (十六进制)，已知扩展已解码到证明中
+}
+
+// inspection 是 sloth inspect 的结果
+type inspection struct {
+	Format      string         `json:"format"`
+	Version     int            `json:"version,omitempty"`
+	Size        int            `json:"size"`
+	Params      string         `json:"params,omitempty"` // 指纹一致的标准参数集
+	Proof       *slothgo.Proof `json:"proof"`
+	Extensions  []extension    `json:"extensions,omitempty"`
+	WitnessSize int            `json:"-"`
+}
+
+func newInspectCmd() *cobra.Command {
+	cmd := newCommand("inspect [--json] [--full] 文件", "查看证明")
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError(cmd, "exactly one proof file is required")
+		}
+		return nil
+	}
+	asJSON := cmd.Flags().Bool("json", false, "以 JSON 输出")
+	full := cmd.Flags().Bool("full", false, "不截断 witness 与检查点")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		data, err := readInput(args[0], cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		in, err := decodeProof(data)
+		if err != nil {
+			return &invalidProofError{err: err}
+		}
+		_, in.Params = matchParamSet(in.Proof)
+		if *asJSON {
+			return writeJSON(cmd.OutOrStdout(), in)
+		}
+		printInspection(cmd.OutOrStdout(), in, *full)
+		return nil
+	}
+	return cmd
+}
+
+// decodeProof 识别 data 的编码并解码
+func decodeProof(data []byte) (*inspection, error) {
+	in := &inspection{Size: len(data), Proof: new(slothgo.Proof)}
+	trimmed := bytes.TrimSpace(data)
+	var err error
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("%w: empty proof", slothgo.ErrInvalidProofEncoding)
+	case bytes.HasPrefix(data, binaryMagic):
+		in.Format = formatBinary
+		err = in.decodeBinary(data)
+	case trimmed[0] == '{':
+		in.Format = formatJSON
+		err = json.Unmarshal(trimmed, in.Proof)
+	case data[0] == 0x30:
+		in.Format, in.Version = formatDER, 1
+		err = in.Proof.UnmarshalDER(data)
+	case data[0]>>5 == 5: // CBOR 映射
+		in.Format = formatCBOR
+		err = in.Proof.UnmarshalCBOR(data)
+	default:
+		in.Format = formatText
+		var p *slothgo.Proof
+		if p, err = slothgo.ParseProof(string(trimmed)); err == nil {
+			in.Proof = p
+			raw, _ := p.MarshalBinary()
+			err = in.decodeBinary(raw)
+		}
+	}
+	if err != nil {
+		// JSON 语法错误发生在调用 Proof.UnmarshalJSON 之前，需要在这里补上格式
+		if !errors.Is(err, slothgo.ErrInvalidProofEncoding) {
+			err = &slothgo.ProofEncodingError{Format: in.Format, Err: err}
+		}
+		return nil, err
+	}
+	return in, nil
+}
+
+// decodeBinary 解码二进制证明，并按格式跳过核心字段列出其后的扩展记录
+func (in *inspection) decodeBinary(data []byte) error {
+	if err := in.Proof.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	// UnmarshalBinary 已经检查过长度，这里不会越界
+	in.Version = int(data[4])
+	off := 5
+	off += 1 + int(data[off]) // 指纹
+	off += 8                  // 迭代次数
+	off += 1 + int(data[off]) // 哈希
+	wLen := int(binary.BigEndian.Uint16(data[off:]))
+	in.WitnessSize = wLen
+	off += 2 + wLen
+	for off < len(data) {
+		typ := data[off]
+		n := int(binary.BigEndian.Uint32(data[off+1:]))
+		ext := extension{Type: typ, Name: binaryExtNames[typ], Critical: typ&0x80 != 0, Length: n}
+		if ext.Name == "" {
+			ext.Data = hex.EncodeToString(data[off+5 : off+5+n])
+		}
+		in.Extensions = append(in.Extensions, ext)
+		off += 5 + n
+	}
+	return nil
+}
+
+// printInspection 以表格形式输出证明的各个字段
+func printInspection(w io.Writer, in *inspection, full bool) {
+	p := in.Proof
+	format := in.Format
+	if in.Version > 0 {
+		format += fmt.Sprintf(" (版本 %d)", in.Version)
+	}
+	fmt.Fprintf(w, "格式        %s, %d 字节\n", format, in.Size)
+	fmt.Fprintf(w, "迭代次数    %d\n", p.Iterations)
+	fingerprint := hex.EncodeToString(p.Fingerprint)
+	if in.Params != "" {
+		fingerprint += " (标准参数集 " + in.Params + ")"
+	}
+	fmt.Fprintf(w, "参数指纹    %s\n", fingerprint)
+	fmt.Fprintf(w, "输出哈希    %s\n", hex.EncodeToString(p.Hash))
+	witness := fmt.Sprintf("%d 位", p.Witness.BitLen())
+	if in.WitnessSize > 0 {
+		witness = fmt.Sprintf("%d 字节", in.WitnessSize)
+	}
+	fmt.Fprintf(w, "witness     %s  %s\n", witness, truncateHex(p.Witness, in.WitnessSize, full))
+	if len(p.Checkpoints) > 0 {
+		fmt.Fprintf(w, "区段检查点  间隔 %d, 共 %d 个\n", p.CheckpointInterval, len(p.Checkpoints))
+		for i, c := range p.Checkpoints {
+			if !full && len(p.Checkpoints) > 4 && i >= 3 && i < len(p.Checkpoints)-1 {
+				if i == 3 {
+					fmt.Fprintf(w, "  ... 省略 %d 个 (--full 显示全部)\n", len(p.Checkpoints)-4)
+				}
+				continue
+			}
+			fmt.Fprintf(w, "  %12d  %s\n", uint64(i+1)*p.CheckpointInterval, truncateHex(c, in.WitnessSize, full))
+		}
+	}
+	if p.StateRoot != nil {
+		fmt.Fprintf(w, "状态承诺    间隔 %d, 根 %s\n", p.StateStride, hex.EncodeToString(p.StateRoot))
+	}
+	for _, ext := range in.Extensions {
+		name := ext.Name
+		if name == "" {
+			name = "未知扩展"
+		}
+		flags := ""
+		if ext.Critical {
+			flags = ", 关键"
+		}
+		fmt.Fprintf(w, "扩展 %#04x   %s (%d 字节%s)", ext.Type, name, ext.Length, flags)
+		if ext.Data != "" {
+			fmt.Fprintf(w, "  %s", truncate(ext.Data, full))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// truncateHex 返回 x 的十六进制表示 (size > 0 时为 size 字节的定长编码)，full 为 false 时只保留首尾各 16 个字符
+func truncateHex(x *big.Int, size int, full bool) string {
+	return "0x" + truncate(fmt.Sprintf("%0*x", size*2, x), full)
+}
+
+func truncate(s string, full bool) string {
+	if full || len(s) <= 40 {
+		return s
+	}
+	return s[:16] + "…" + s[len(s)-16:] + fmt.Sprintf(" (%d 个十六进制字符)", len(s))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// inspectFile 把 data 写入临时文件并运行 sloth inspect
+func inspectFile(t *testing.T, data []byte, flags ...string) (int, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "proof")
+	os.WriteFile(path, data, 0o600)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append(append([]string{"inspect"}, flags...), path), nil, &stdout, &stderr)
+	return code, stdout.String() + stderr.String()
+}
+
+// TestInspect 检查各种编码都能识别，并显示检查点、标准参数集与未知扩展
+func TestInspect(t *testing.T) {
+	ps, _ := slothgo.LookupParamSet("sloth-256-t30s")
+	vdf, err := slothgo.New(ps.Prime(), 1000, slothgo.WithSegmentCheckpoints(100), slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := vdf.ComputeProof([]byte("inspect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary, _ := proof.MarshalBinary()
+	cbor, _ := proof.MarshalCBOR()
+	der, _ := proof.MarshalDER()
+	js, _ := json.Marshal(proof)
+	for _, tc := range []struct {
+		format string
+		data   []byte
+	}{
+		{"binary (版本 1)", binary},
+		{"text (版本 1)", []byte(proof.String() + "\n")},
+		{"cbor", cbor},
+		{"der (版本 1)", der},
+		{"json", js},
+	} {
+		code, out := inspectFile(t, tc.data)
+		if code != exitOK {
+			t.Errorf("inspect %s exited with %d: %s", tc.format, code, out)
+			continue
+		}
+		for _, want := range []string{"格式        " + tc.format, "迭代次数    1000", "sloth-256-t30s", "间隔 100, 共 9 个", "省略 5 个"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("inspect %s output lacks %q:\n%s", tc.format, want, out)
+			}
+		}
+	}
+
+	// 非关键的未知扩展被列出，关键的未知扩展使证明无效
+	withExt := append(bytes.Clone(binary), 0x10, 0, 0, 0, 2, 0xbe, 0xef)
+	code, out := inspectFile(t, withExt, "--json")
+	var in inspection
+	if err := json.Unmarshal([]byte(out), &in); code != exitOK || err != nil {
+		t.Fatalf("inspect --json exited with %d: %v\n%s", code, err, out)
+	}
+	if len(in.Extensions) != 2 || in.Extensions[1].Type != 0x10 || in.Extensions[1].Data != "beef" || in.Params != "sloth-256-t30s" {
+		t.Errorf("unexpected inspection %+v", in)
+	}
+	critical := append(bytes.Clone(binary), 0x90, 0, 0, 0, 0)
+	if code, out := inspectFile(t, critical); code != exitInvalid {
+		t.Errorf("critical unknown extension: exit %d: %s", code, out)
+	}
+	if code, out := inspectFile(t, []byte("not a proof")); code != exitInvalid {
+		t.Errorf("garbage: exit %d: %s", code, out)
+	}
+	if code, _ := sloth(t, "inspect"); code != exitUsage {
+		t.Errorf("inspect without a file exited with %d", code)
+	}
+}
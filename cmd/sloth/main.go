@@ -0,0 +1,428 @@
+// sloth 是计算、验证与查看 Sloth 证明、生成参数、测量本机速度与运行信标节点的命令行工具，适合在脚本中使用:
+//
+//	sloth compute --prime-file p.hex --iters 5000000 --in message.txt --out proof.bin
+//	sloth verify --proof proof.bin --in message.txt
+//	sloth params gen --bits 2048 --safe --seed <十六进制> --out params.json
+//	sloth calibrate --target 30s --bits 256 --json
+//	sloth inspect proof.bin
+//	sloth beacon run --config beacon.yaml
+//	sloth beacon get --url http://beacon:8080 --round 42
+//
+// beacon run 按配置文件启动与 sloth-beacon 相同的信标节点，beacon get 从远程节点取回一轮并在本地验证。
+// 证明以 Proof.MarshalBinary 的二进制格式读写。证明本身不包含素数，verify 没有指定
+// --prime-file 或 --params 时在标准参数集中查找与证明指纹一致的参数。
+// 所有命令都接受 --json 把结果以 JSON 输出到标准输出，文件参数为 "-" 时从标准输入读取 (每条命令最多一个)，
+// 便于与 jq 等工具组成管道:
+//
+//	sloth compute --params sloth-256-t30s --in - --json < message.txt | jq -r .hash
+//	sloth compute --params sloth-256-t30s --in message.txt | sloth verify --proof - --in message.txt --json
+//
+// 退出码: 0 成功 (verify 时证明有效)，1 证明无效，2 用法错误，3 其他错误 (读写文件、计算被中断等)
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// 退出码
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitError   = 3
+)
+
+// errUsage 表示命令行参数错误，已经向 stderr 输出了用法
+var errUsage = errors.New("usage error")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run 执行 args 指定的子命令并返回退出码
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	root := newRootCmd()
+	// 参数为 nil 时 cobra 会改用 os.Args
+	root.SetArgs(append([]string{}, args...))
+	root.SetIn(stdin)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+	err := root.ExecuteContext(ctx)
+	var invalid *invalidProofError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.As(err, &invalid):
+		fmt.Fprintf(stderr, "sloth: invalid proof: %v\n", invalid.err)
+		return exitInvalid
+	default:
+		fmt.Fprintf(stderr, "sloth: %v\n", err)
+		return exitError
+	}
+}
+
+// usageTemplate 是各命令的用法说明，出错时输出到标准错误，--help 时输出到标准输出
+const usageTemplate = `用法: {{.UseLine}}{{if .HasAvailableSubCommands}} <命令> [选项]
+
+命令:{{range .Commands}}{{if .IsAvailableCommand}}
+  {{rpad .Name .NamePadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+选项:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if not .HasParent}}
+
+"sloth <命令> --help" 列出命令的选项
+所有命令都接受 --json 以 JSON 输出结果; 文件参数为 "-" 时从标准输入读取
+退出码: 0 成功, 1 证明无效, 2 用法错误, 3 其他错误{{end}}
+`
+
+// newRootCmd 创建 sloth 的命令树
+func newRootCmd() *cobra.Command {
+	root := newGroupCmd("sloth", "", newComputeCmd(), newVerifyCmd(), newParamsCmd(), newCalibrateCmd(), newInspectCmd(), newBeaconCmd())
+	root.SilenceErrors, root.SilenceUsage = true, true
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.PersistentFlags().BoolP("help", "h", false, "显示用法")
+	root.SetUsageTemplate(usageTemplate)
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(cmd, "%v", err)
+	})
+	return root
+}
+
+// newCommand 创建不接受位置参数的命令，use 是命令名加上选项的概要
+func newCommand(use, short string) *cobra.Command {
+	return &cobra.Command{Use: use, Short: short, Args: noArgs, DisableFlagsInUseLine: true}
+}
+
+// newGroupCmd 创建只包含子命令的命令，没有给出子命令或子命令不存在时是用法错误
+func newGroupCmd(use, short string, subs ...*cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   use,
+		Short:                 short,
+		DisableFlagsInUseLine: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return usageError(cmd, "unknown command %q", args[0])
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fmt.Fprint(cmd.ErrOrStderr(), cmd.UsageString())
+			return errUsage
+		},
+	}
+	cmd.AddCommand(subs...)
+	return cmd
+}
+
+// invalidProofError 表示证明没有通过验证，对应退出码 1
+type invalidProofError struct {
+	err error
+}
+
+func (e *invalidProofError) Error() string { return e.err.Error() }
+
+func (e *invalidProofError) Unwrap() error { return e.err }
+
+// paramFlags 是各命令共用的参数选项
+type paramFlags struct {
+	primeFile string
+	params    string
+}
+
+func (pf *paramFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&pf.primeFile, "prime-file", "", `十六进制素数 p 所在的文件, 或 sloth params gen 生成的参数文件, "-" 表示标准输入`)
+	fs.StringVar(&pf.params, "params", "", "标准参数集名称, 代替 --prime-file")
+}
+
+// check 检查选项之间没有冲突且参数集存在
+func (pf *paramFlags) check(cmd *cobra.Command) error {
+	if pf.primeFile != "" && pf.params != "" {
+		return usageError(cmd, "--prime-file and --params are mutually exclusive")
+	}
+	if pf.params != "" {
+		if _, err := slothgo.LookupParamSet(pf.params); err != nil {
+			return usageError(cmd, "%v", err)
+		}
+	}
+	return nil
+}
+
+// prime 返回选项指定的素数，以及是否来自标准参数集 (标准参数的素数不需要再做素性检验)
+func (pf *paramFlags) prime(stdin io.Reader) (p *big.Int, standard bool, err error) {
+	switch {
+	case pf.params != "":
+		ps, err := slothgo.LookupParamSet(pf.params)
+		if err != nil {
+			return nil, false, err
+		}
+		return ps.Prime(), true, nil
+	case pf.primeFile != "":
+		data, err := readInput(pf.primeFile, stdin)
+		if err != nil {
+			return nil, false, err
+		}
+		p, err := parsePrime(data, pf.primeFile)
+		return p, false, err
+	}
+	return nil, false, nil
+}
+
+// parsePrime 解析素数文件的内容，格式见 config.ParsePrime; path 只用于错误信息
+func parsePrime(data []byte, path string) (*big.Int, error) {
+	p, err := config.ParsePrime(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// noArgs 是不接受位置参数的命令的 Args
+func noArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return usageError(cmd, "unexpected argument %q", args[0])
+	}
+	return nil
+}
+
+// usageError 输出错误与用法并返回 errUsage
+func usageError(cmd *cobra.Command, format string, args ...any) error {
+	fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", args...)
+	fmt.Fprint(cmd.ErrOrStderr(), cmd.UsageString())
+	return errUsage
+}
+
+// readInput 读取输入文件，"-" 表示标准输入
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// checkStdin 检查最多只有一个选项从标准输入读取
+func checkStdin(cmd *cobra.Command, names ...string) error {
+	var stdin []string
+	for _, name := range names {
+		if cmd.Flags().Lookup(name).Value.String() == "-" {
+			stdin = append(stdin, "--"+name)
+		}
+	}
+	if len(stdin) > 1 {
+		return usageError(cmd, "only one of %s can read from standard input", strings.Join(stdin, " and "))
+	}
+	return nil
+}
+
+// writeJSON 把 v 以缩进的 JSON 写入 w
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func newComputeCmd() *cobra.Command {
+	cmd := newCommand("compute (--prime-file 文件 | --params 名称) [--iters N] --in 文件 [--out 文件]", "计算证明")
+	fs := cmd.Flags()
+	var pf paramFlags
+	pf.register(fs)
+	iterations := fs.Uint64("iters", 0, "迭代次数, 0 表示使用 --params 的推荐值")
+	in := fs.String("in", "", `输入文件, "-" 表示标准输入`)
+	out := fs.String("out", "-", `证明的输出文件, "-" 表示标准输出`)
+	progress := fs.Bool("progress", false, "在标准错误输出上报告进度")
+	asJSON := fs.Bool("json", false, "以 JSON 而不是二进制格式输出证明")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdin, stdout, stderr := cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+		if *in == "" {
+			return usageError(cmd, "--in is required")
+		}
+		if err := pf.check(cmd); err != nil {
+			return err
+		}
+		if err := checkStdin(cmd, "in", "prime-file"); err != nil {
+			return err
+		}
+		if pf.primeFile == "" && pf.params == "" {
+			return usageError(cmd, "one of --prime-file or --params is required")
+		}
+		if *iterations == 0 && pf.params != "" {
+			ps, _ := slothgo.LookupParamSet(pf.params)
+			*iterations = ps.Iterations
+		}
+		if *iterations == 0 {
+			return usageError(cmd, "--iters must be positive")
+		}
+		p, standard, err := pf.prime(stdin)
+		if err != nil {
+			return err
+		}
+
+		input, err := readInput(*in, stdin)
+		if err != nil {
+			return err
+		}
+		var opts []slothgo.Option
+		if standard {
+			opts = append(opts, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		}
+		if *progress {
+			opts = append(opts, slothgo.WithProgressInfo(func(pi slothgo.ProgressInfo) {
+				fmt.Fprintf(stderr, "%3d%%  %.0f 次/秒  剩余约 %v\n", pi.Done*100/pi.Total, pi.Rate, pi.ETA.Round(time.Second))
+			}, max(*iterations/20, 1)))
+		}
+		vdf, err := slothgo.New(p, *iterations, opts...)
+		if err != nil {
+			return err
+		}
+		proof, err := vdf.ComputeProofCtx(ctx, input)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		if *asJSON {
+			if data, err = json.MarshalIndent(proof, "", "  "); err == nil {
+				data = append(data, '\n')
+			}
+		} else {
+			data, err = proof.MarshalBinary()
+		}
+		if err != nil {
+			return err
+		}
+		if *out == "-" {
+			_, err = stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(*out, data, 0o644)
+	}
+	return cmd
+}
+
+// verifyResult 是 verify --json 的输出，error 是证明无效的原因
+type verifyResult struct {
+	Valid       bool   `json:"valid"`
+	Error       string `json:"error,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Params      string `json:"params,omitempty"`
+	Iterations  uint64 `json:"iterations,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+func newVerifyCmd() *cobra.Command {
+	cmd := newCommand("verify --proof 文件 --in 文件 [--prime-file 文件 | --params 名称] [--iters N] [--json]", "验证证明")
+	fs := cmd.Flags()
+	var pf paramFlags
+	pf.register(fs)
+	minIterations := fs.Uint64("iters", 0, "证明至少需要声明的迭代次数, 0 表示不限制")
+	proofFile := fs.String("proof", "", `证明文件 (二进制、文本、JSON、CBOR 或 DER 编码), "-" 表示标准输入`)
+	in := fs.String("in", "", `输入文件, "-" 表示标准输入`)
+	asJSON := fs.Bool("json", false, "以 JSON 输出验证结果, 证明无效时同样输出")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdin, stdout := cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout()
+		if *proofFile == "" || *in == "" {
+			return usageError(cmd, "--proof and --in are required")
+		}
+		if err := pf.check(cmd); err != nil {
+			return err
+		}
+		if err := checkStdin(cmd, "proof", "in", "prime-file"); err != nil {
+			return err
+		}
+		p, standard, err := pf.prime(stdin)
+		if err != nil {
+			return err
+		}
+		data, err := readInput(*proofFile, stdin)
+		if err != nil {
+			return err
+		}
+		input, err := readInput(*in, stdin)
+		if err != nil {
+			return err
+		}
+
+		res, err := verifyProof(ctx, data, input, p, standard, *minIterations)
+		var invalid *invalidProofError
+		if *asJSON && (err == nil || errors.As(err, &invalid)) {
+			if err := writeJSON(stdout, res); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	return cmd
+}
+
+// verifyProof 解码并验证证明，p 为 nil 时在标准参数集中查找参数; 证明无效时返回 invalidProofError
+func verifyProof(ctx context.Context, data, input []byte, p *big.Int, standard bool, minIterations uint64) (res verifyResult, err error) {
+	defer func() {
+		var invalid *invalidProofError
+		if errors.As(err, &invalid) {
+			res.Error = invalid.err.Error()
+		}
+	}()
+	in, err := decodeProof(data)
+	if err != nil {
+		return res, &invalidProofError{err: err}
+	}
+	proof := in.Proof
+	res.Format, res.Iterations, res.Fingerprint = in.Format, proof.Iterations, hex.EncodeToString(proof.Fingerprint)
+	if proof.Iterations < minIterations {
+		return res, &invalidProofError{err: fmt.Errorf("proof claims %d iterations, at least %d required", proof.Iterations, minIterations)}
+	}
+
+	var vdf *slothgo.Sloth
+	if p != nil {
+		var opts []slothgo.Option
+		if standard {
+			opts = append(opts, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		}
+		if vdf, err = slothgo.New(p, proof.Iterations, opts...); err != nil {
+			return res, err
+		}
+	} else if vdf, res.Params = matchParamSet(proof); vdf == nil {
+		return res, &invalidProofError{err: errors.New("proof does not match any standard parameter set, use --prime-file or --params")}
+	}
+	if ok, err := vdf.VerifyProofCtx(ctx, input, proof); !ok {
+		if ctx.Err() != nil {
+			return res, err
+		}
+		if err == nil {
+			err = errors.New("verification failed")
+		}
+		return res, &invalidProofError{err: err}
+	}
+	res.Valid = true
+	return res, nil
+}
+
+// matchParamSet 返回指纹与证明一致的标准参数集实例及其名称，没有时返回 nil
+func matchParamSet(proof *slothgo.Proof) (*slothgo.Sloth, string) {
+	for _, ps := range slothgo.ParamSets() {
+		vdf, err := slothgo.New(ps.Prime(), proof.Iterations, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		if err == nil && string(vdf.Fingerprint()) == string(proof.Fingerprint) {
+			return vdf, ps.Name
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// sloth 以 args 运行命令，返回退出码与标准错误输出
+func sloth(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader("stdin input"), &stdout, &stderr)
+	return code, stderr.String()
+}
+
+// TestComputeVerify 检查计算与验证的流程以及各种情况下的退出码
+func TestComputeVerify(t *testing.T) {
+	dir := t.TempDir()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	primeFile := filepath.Join(dir, "p.hex")
+	os.WriteFile(primeFile, []byte(fmt.Sprintf("0x%x\n", p)), 0o600)
+	in := filepath.Join(dir, "message.txt")
+	os.WriteFile(in, []byte("hello"), 0o600)
+	other := filepath.Join(dir, "other.txt")
+	os.WriteFile(other, []byte("world"), 0o600)
+	proof := filepath.Join(dir, "proof.bin")
+
+	if code, stderr := sloth(t, "compute", "--prime-file", primeFile, "--iters", "2000", "--in", in, "--out", proof); code != exitOK {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"verify", "--proof", proof, "--in", in, "--prime-file", primeFile}, exitOK},
+		{[]string{"verify", "--proof", proof, "--in", in, "--prime-file", primeFile, "--iters", "2000"}, exitOK},
+		{[]string{"verify", "--proof", proof, "--in", in, "--prime-file", primeFile, "--iters", "2001"}, exitInvalid},
+		{[]string{"verify", "--proof", proof, "--in", other, "--prime-file", primeFile}, exitInvalid},
+		{[]string{"verify", "--proof", in, "--in", in, "--prime-file", primeFile}, exitInvalid},
+		// 证明使用自定义素数，没有 --prime-file 时找不到参数
+		{[]string{"verify", "--proof", proof, "--in", in}, exitInvalid},
+		{[]string{"verify", "--proof", proof}, exitUsage},
+		{[]string{"verify", "--proof", proof, "--in", in, "--prime-file", primeFile, "--params", "sloth-256-t30s"}, exitUsage},
+		{[]string{"verify", "--proof", filepath.Join(dir, "missing"), "--in", in}, exitError},
+		{[]string{"compute", "--in", in}, exitUsage},
+		{[]string{"compute", "--prime-file", primeFile, "--in", in}, exitUsage},
+		{[]string{"compute", "--prime-file", primeFile, "--iters", "10", "--in", in, "extra"}, exitUsage},
+		{[]string{"compute", "--prime-file", in, "--iters", "10", "--in", in}, exitError},
+		{[]string{"compute", "--params", "nope", "--in", in}, exitUsage},
+		{[]string{"frobnicate"}, exitUsage},
+		{[]string{"verify", "--help"}, exitOK},
+		{nil, exitUsage},
+	} {
+		if code, stderr := sloth(t, tc.args...); code != tc.want {
+			t.Errorf("sloth %v exited with %d, want %d: %s", tc.args, code, tc.want, stderr)
+		}
+	}
+}
+
+// TestVerifyStandard 检查标准参数集的证明不需要指定参数即可验证，以及从标准输入读取输入
+func TestVerifyStandard(t *testing.T) {
+	proof := filepath.Join(t.TempDir(), "proof.bin")
+	if code, stderr := sloth(t, "compute", "--params", "sloth-256-t30s", "--iters", "1000", "--in", "-", "--out", proof); code != exitOK {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+	if code, stderr := sloth(t, "verify", "--proof", proof, "--in", "-"); code != exitOK {
+		t.Errorf("verify exited with %d: %s", code, stderr)
+	}
+}
+
+// pipe 以 args 运行命令，stdin 作为标准输入，返回退出码、标准输出与标准错误输出
+func pipe(t *testing.T, stdin []byte, args ...string) (int, []byte, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, bytes.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.Bytes(), stderr.String()
+}
+
+// TestJSONPipeline 检查 --json 输出与从标准输入读取的组合: compute 的 JSON 证明直接交给 verify
+func TestJSONPipeline(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	in := filepath.Join(t.TempDir(), "message.txt")
+	os.WriteFile(in, []byte("hello"), 0o600)
+
+	code, proof, stderr := pipe(t, []byte(p.Text(16)), "compute", "--prime-file", "-", "--iters", "1000", "--in", in, "--json")
+	if code != exitOK {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+	var decoded slothgo.Proof
+	if err := json.Unmarshal(proof, &decoded); err != nil || decoded.Iterations != 1000 {
+		t.Fatalf("compute --json wrote %s: %v", proof, err)
+	}
+
+	var res verifyResult
+	code, out, stderr := pipe(t, proof, "verify", "--proof", "-", "--in", in, "--prime-file", writeTemp(t, p.Text(16)), "--json")
+	if code != exitOK {
+		t.Fatalf("verify exited with %d: %s", code, stderr)
+	}
+	if err := json.Unmarshal(out, &res); err != nil || !res.Valid || res.Format != formatJSON || res.Iterations != 1000 {
+		t.Errorf("unexpected verify output %s: %v", out, err)
+	}
+
+	// 证明无效时仍然输出 JSON，退出码为 1
+	code, out, _ = pipe(t, []byte("world"), "verify", "--proof", writeTemp(t, string(proof)), "--in", "-", "--prime-file", writeTemp(t, p.Text(16)), "--json")
+	if code != exitInvalid {
+		t.Errorf("verify of another input exited with %d", code)
+	}
+	if err := json.Unmarshal(out, &res); err != nil || res.Valid || res.Error == "" {
+		t.Errorf("unexpected verify output %s: %v", out, err)
+	}
+
+	if code, _, _ := pipe(t, proof, "verify", "--proof", "-", "--in", "-", "--params", "sloth-256-t30s"); code != exitUsage {
+		t.Errorf("two inputs from standard input exited with %d", code)
+	}
+	if code, _, _ := pipe(t, nil, "params", "gen", "--bits", "64", "--iters", "10", "--json"); code != exitOK {
+		t.Errorf("params gen --json exited with %d", code)
+	}
+}
+
+// writeTemp 把 data 写入临时文件并返回路径
+func writeTemp(t *testing.T, data string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(data)
+	return f.Name()
+}
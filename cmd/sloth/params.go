@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/spf13/cobra"
+)
+
+// paramsFile 是 sloth params gen 输出的参数文件，可以直接放入协议的创世配置
+// Seed 不为空时任何人都可以用 DerivePrime / DeriveSafePrime 复现素数; Certificate 不为空时
+// 可以用 VerifyPrimeCertificate 确定地验证素性; Calibration 记录迭代次数建议的依据
+type paramsFile struct {
+	Params      *slothgo.Params           `json:"params"`
+	Fingerprint string                    `json:"fingerprint"`
+	Seed        string                    `json:"seed,omitempty"`
+	Safe        bool                      `json:"safe,omitempty"`
+	Certificate *slothgo.PrimeCertificate `json:"certificate,omitempty"`
+	Calibration *calibrationInfo          `json:"calibration,omitempty"`
+}
+
+// calibrationInfo 是在生成参数的机器上测得的吞吐量
+type calibrationInfo struct {
+	TargetDelay string  `json:"target_delay"`
+	ForwardRate float64 `json:"forward_rate"`
+	InverseRate float64 `json:"inverse_rate"`
+}
+
+func newParamsCmd() *cobra.Command {
+	return newGroupCmd("params", "生成参数", newParamsGenCmd())
+}
+
+func newParamsGenCmd() *cobra.Command {
+	cmd := newCommand("gen [--bits N] [--safe] [--seed 十六进制 | --cert] [--iters N | --target 时长] [--out 文件]", "生成素数与参数文件")
+	fs := cmd.Flags()
+	bits := fs.Int("bits", 2048, "素数的位数")
+	safe := fs.Bool("safe", false, "生成安全素数 p = 2q + 1 (q 也是素数)，大位数时可能需要数分钟")
+	seedHex := fs.String("seed", "", "十六进制的公开种子, 素数由种子确定地派生; 为空时使用随机种子并记录在文件中")
+	withCert := fs.Bool("cert", false, "生成带 Pocklington 证书的素数 (不能与 --seed、--safe 同时使用)")
+	iterations := fs.Uint64("iters", 0, "迭代次数, 0 表示在本机上测量并按 --target 建议")
+	target := fs.Duration("target", 30*time.Second, "建议迭代次数时的目标计算耗时")
+	sample := fs.Duration("sample", time.Second, "测量每个方向吞吐量的时长")
+	out := fs.String("out", "-", `参数文件, "-" 表示标准输出`)
+	fs.Bool("json", true, "参数文件总是 JSON 格式, 接受该选项只是为了与其他命令一致")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		ctx, stdout := cmd.Context(), cmd.OutOrStdout()
+		switch {
+		case *bits < 4:
+			return usageError(cmd, "--bits must be at least 4")
+		case *withCert && (*seedHex != "" || *safe):
+			return usageError(cmd, "--cert cannot be combined with --seed or --safe")
+		case *iterations == 0 && (*target <= 0 || *sample <= 0):
+			return usageError(cmd, "--target and --sample must be positive")
+		}
+
+		var f paramsFile
+		var p *big.Int
+		var err error
+		if *withCert {
+			p, f.Certificate, err = slothgo.GenerateCertifiedPrime(*bits)
+		} else {
+			var seed []byte
+			if *seedHex != "" {
+				if seed, err = hex.DecodeString(*seedHex); err != nil {
+					return usageError(cmd, "--seed: %v", err)
+				}
+			} else {
+				seed = make([]byte, 32)
+				if _, err := rand.Read(seed); err != nil {
+					return err
+				}
+			}
+			f.Seed, f.Safe = hex.EncodeToString(seed), *safe
+			p, err = derivePrime(ctx, seed, *bits, *safe)
+		}
+		if err != nil {
+			return err
+		}
+
+		if *iterations == 0 {
+			c, err := slothgo.Calibrate(p, *sample, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+			if err != nil {
+				return err
+			}
+			*iterations = c.IterationsFor(*target)
+			f.Calibration = &calibrationInfo{TargetDelay: target.String(), ForwardRate: c.ForwardRate, InverseRate: c.InverseRate}
+		}
+		f.Params = &slothgo.Params{P: p, Iterations: *iterations, Hash: slothgo.HashSHA256, Permutation: "sqrt"}
+		vdf, err := slothgo.NewFromParams(f.Params, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		if err != nil {
+			return err
+		}
+		f.Fingerprint = hex.EncodeToString(vdf.Fingerprint())
+
+		data, err := json.MarshalIndent(&f, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if *out == "-" {
+			_, err = stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(*out, data, 0o644)
+	}
+	return cmd
+}
+
+// derivePrime 在后台派生素数，ctx 结束时放弃等待 (搜索安全素数可能需要很长时间)
+func derivePrime(ctx context.Context, seed []byte, bits int, safe bool) (*big.Int, error) {
+	type result struct {
+		p   *big.Int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		if safe {
+			r.p, r.err = slothgo.DeriveSafePrime(seed, bits)
+		} else {
+			r.p, r.err = slothgo.DerivePrime(seed, bits)
+		}
+		done <- r
+	}()
+	select {
+	case r := <-done:
+		return r.p, r.err
+	case <-ctx.Done():
+		return nil, errors.Join(errors.New("prime search interrupted"), ctx.Err())
+	}
+}
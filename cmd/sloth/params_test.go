@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// readParamsFile 解码 params gen 输出的参数文件
+func readParamsFile(t *testing.T, path string) paramsFile {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f paramsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("malformed params file: %v\n%s", err, data)
+	}
+	return f
+}
+
+// TestParamsGen 检查由种子派生、安全素数、证书与测量迭代次数的参数文件，以及用参数文件计算和验证
+func TestParamsGen(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "params.json")
+	if code, stderr := sloth(t, "params", "gen", "--bits", "64", "--safe", "--seed", "abcd", "--iters", "1000", "--out", out); code != exitOK {
+		t.Fatalf("params gen exited with %d: %s", code, stderr)
+	}
+	f := readParamsFile(t, out)
+	want, _ := slothgo.DeriveSafePrime([]byte{0xab, 0xcd}, 64)
+	if f.Params.P.Cmp(want) != 0 || !f.Safe || f.Seed != "abcd" || f.Params.Iterations != 1000 || f.Calibration != nil {
+		t.Fatalf("unexpected params file %+v", f)
+	}
+	vdf, _ := slothgo.NewFromParams(f.Params)
+	if f.Fingerprint != hex.EncodeToString(vdf.Fingerprint()) {
+		t.Error("fingerprint does not match the params")
+	}
+
+	// 参数文件可以直接作为 --prime-file
+	in := filepath.Join(dir, "in.txt")
+	os.WriteFile(in, []byte("genesis"), 0o600)
+	proof := filepath.Join(dir, "proof.bin")
+	if code, stderr := sloth(t, "compute", "--prime-file", out, "--iters", "1000", "--in", in, "--out", proof); code != exitOK {
+		t.Fatalf("compute exited with %d: %s", code, stderr)
+	}
+	if code, stderr := sloth(t, "verify", "--prime-file", out, "--proof", proof, "--in", in); code != exitOK {
+		t.Fatalf("verify exited with %d: %s", code, stderr)
+	}
+
+	certOut := filepath.Join(dir, "cert.json")
+	if code, stderr := sloth(t, "params", "gen", "--bits", "128", "--cert", "--target", "10ms", "--sample", "10ms", "--out", certOut); code != exitOK {
+		t.Fatalf("params gen --cert exited with %d: %s", code, stderr)
+	}
+	f = readParamsFile(t, certOut)
+	if f.Certificate == nil || f.Seed != "" || f.Calibration == nil || f.Params.Iterations < 1 {
+		t.Fatalf("unexpected params file %+v", f)
+	}
+	if err := slothgo.VerifyPrimeCertificate(f.Params.P, f.Certificate); err != nil {
+		t.Errorf("certificate does not verify: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"params"},
+		{"params", "gen", "--cert", "--safe"},
+		{"params", "gen", "--cert", "--seed", "00"},
+		{"params", "gen", "--seed", "xyz"},
+		{"params", "gen", "--bits", "2"},
+	} {
+		if code, _ := sloth(t, args...); code != exitUsage {
+			t.Errorf("sloth %v exited with %d, want %d", args, code, exitUsage)
+		}
+	}
+}
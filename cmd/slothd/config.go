@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+// envPrefix 是 slothd 环境变量的前缀，例如 SLOTHD_VERIFY_CACHE_TTL 对应配置文件中的 verify_cache_ttl
+const envPrefix = "SLOTHD_"
+
+// settings 是 slothd 的全部设置，依次来自默认值、-config 指定的配置文件、SLOTHD_ 开头的环境变量
+// 与显式指定的命令行选项，后者覆盖前者。配置文件的格式见 internal/config
+type settings struct {
+	Addr           string        `config:"addr"`
+	HTTP           string        `config:"http"`
+	Debug          string        `config:"debug"`
+	Data           string        `config:"data"`
+	Params         string        `config:"params"`
+	Iterations     uint64        `config:"iters"`
+	MaxIterations  uint64        `config:"max_iters"`
+	Workers        int           `config:"workers"`
+	VerifyCache    int           `config:"verify_cache"`
+	VerifyCacheTTL time.Duration `config:"verify_cache_ttl"`
+	Keys           string        `config:"keys"`
+	AdminToken     string        `config:"admin_token"` // 没有对应的命令行选项，以免出现在进程列表中
+	TLSCert        string        `config:"tls_cert"`
+	TLSKey         string        `config:"tls_key"`
+	TLSClientCA    string        `config:"tls_client_ca"`
+	Drain          time.Duration `config:"drain"`
+}
+
+// register 把命令行选项绑定到 s 的字段上
+func (s *settings) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.Addr, "addr", ":7070", "gRPC 监听地址")
+	fs.StringVar(&s.HTTP, "http", "", "REST 监听地址, 为空时不提供 REST 接口")
+	fs.StringVar(&s.Data, "data", "", "任务持久化目录, 为空时只保存在内存中")
+	fs.StringVar(&s.Params, "params", "sloth-256-t30s", "标准参数集名称")
+	fs.Uint64Var(&s.Iterations, "iters", 0, "默认迭代次数, 0 表示使用参数集的推荐值")
+	fs.Uint64Var(&s.MaxIterations, "max-iters", 0, "单个任务允许的最大迭代次数, 0 表示等于默认迭代次数")
+	fs.IntVar(&s.Workers, "workers", 0, "同时计算的任务数, 0 表示 CPU 核数")
+	fs.IntVar(&s.VerifyCache, "verify-cache", 1024, "缓存最近验证通过的证明数, 0 表示不缓存")
+	fs.DurationVar(&s.VerifyCacheTTL, "verify-cache-ttl", 10*time.Minute, "验证缓存中证明的有效期, 0 表示不过期")
+	fs.StringVar(&s.Keys, "keys", "", "API 密钥文件, 不为空时 REST 接口要求 API 密钥")
+	fs.StringVar(&s.TLSCert, "tls-cert", "", "TLS 证书文件 (PEM)")
+	fs.StringVar(&s.TLSKey, "tls-key", "", "TLS 私钥文件 (PEM)")
+	fs.StringVar(&s.TLSClientCA, "tls-client-ca", "", "客户端证书的 CA 文件 (PEM), 不为空时要求客户端证书")
+	fs.DurationVar(&s.Drain, "drain", 30*time.Second, "关闭时等待运行中的任务写入计算日志的最长时间")
+	fs.StringVar(&s.Debug, "debug", "", "调试接口监听地址 (/debug/pprof/、/healthz、/readyz), 为空时不提供")
+}
+
+// validate 在启动之前检查设置，返回的错误指出对应的键
+func (s *settings) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(s.Addr != "", "addr cannot be empty")
+	_, err := slothgo.LookupParamSet(s.Params)
+	check(err == nil, "params: %v", err)
+	check(s.Iterations <= slothgo.MaxIterations, "iters cannot exceed %d", slothgo.MaxIterations)
+	check(s.MaxIterations <= slothgo.MaxIterations, "max_iters cannot exceed %d", slothgo.MaxIterations)
+	check(s.Workers >= 0, "workers cannot be negative")
+	check(s.VerifyCache >= 0, "verify_cache cannot be negative")
+	check(s.VerifyCacheTTL >= 0, "verify_cache_ttl cannot be negative")
+	check(s.Drain > 0, "drain must be positive")
+	check((s.TLSCert == "") == (s.TLSKey == ""), "tls_cert and tls_key must be set together")
+	check(s.TLSClientCA == "" || s.TLSCert != "", "tls_client_ca requires tls_cert and tls_key")
+	check(s.AdminToken == "" || s.Keys != "", "admin_token requires keys")
+	return errors.Join(errs...)
+}
+
+// loadSettings 解析命令行选项并合并配置文件与环境变量，-config 为空时使用环境变量 SLOTHD_CONFIG
+func loadSettings(args []string) (*settings, error) {
+	fs := flag.NewFlagSet("slothd", flag.ExitOnError)
+	s := new(settings)
+	s.register(fs)
+	configFile := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "配置文件 (YAML), 键为选项名中的 - 换成 _, 例如 verify_cache_ttl")
+	fs.Parse(args)
+	if err := config.Load(s, *configFile, envPrefix, fs); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
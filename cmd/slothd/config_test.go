@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadSettings 检查配置文件、环境变量与命令行选项的优先级以及启动时的检查
+func TestLoadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slothd.yaml")
+	os.WriteFile(path, []byte(`http: ":8080"
+workers: 2
+verify_cache_ttl: 1m
+max_iters: 5000000
+`), 0o600)
+	t.Setenv("SLOTHD_WORKERS", "4")
+	t.Setenv("SLOTHD_ADMIN_TOKEN", "secret")
+
+	s, err := loadSettings([]string{"-config", path, "-max-iters", "6000000", "-keys", "keys.json"})
+	if err != nil {
+		t.Fatalf("loadSettings failed: %v", err)
+	}
+	if s.HTTP != ":8080" || s.Workers != 4 || s.VerifyCacheTTL != time.Minute || s.MaxIterations != 6000000 {
+		t.Errorf("unexpected settings %+v", s)
+	}
+	if s.Addr != ":7070" || s.VerifyCache != 1024 || s.AdminToken != "secret" {
+		t.Errorf("defaults or environment were not applied: %+v", s)
+	}
+
+	// 配置文件路径也可以来自环境变量
+	t.Setenv("SLOTHD_CONFIG", path)
+	if s, err := loadSettings([]string{"-keys", "keys.json"}); err != nil || s.HTTP != ":8080" {
+		t.Errorf("SLOTHD_CONFIG was not used: %+v, %v", s, err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-params", "nope"}, "params"},
+		{[]string{"-workers", "-1", "-drain", "0"}, "drain must be positive"},
+		{[]string{"-tls-cert", "cert.pem"}, "tls_cert and tls_key"},
+		{[]string{"-tls-client-ca", "ca.pem"}, "tls_client_ca"},
+		{nil, "admin_token requires keys"},
+	} {
+		_, err := loadSettings(tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("loadSettings(%v) returned %v, want an error about %s", tc.args, err, tc.want)
+		}
+	}
+}
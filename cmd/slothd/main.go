@@ -0,0 +1,258 @@
+// slothd 是 Sloth 证明服务: 集群中的其他服务通过 gRPC 或 REST 提交计算任务并取回证明，无需链接本库
+// gRPC 接口见 service.GRPC 与 service/slothpb/sloth.proto (SubmitCompute、GetJob、CancelJob、StreamProgress、Verify)，
+// 指定 -http 时另外提供 service.NewHTTPHandler 的 REST 接口; 指定 -data 时任务保存在该目录中，重启后恢复。
+// 收到 SIGTERM 或 SIGINT 时停止接受新任务，运行中的任务在 -drain 之内把进度写入 -data 下的计算日志，重启后从日志继续。
+// 指定 -keys 时 REST 接口要求 API 密钥并按密钥限流，admin_token (环境变量 SLOTHD_ADMIN_TOKEN) 不为空时
+// 另外在 /admin/keys 提供密钥管理接口 (见 service.NewKeyAdminHandler)。gRPC 接口不做密钥认证。
+// 指定 -tls-cert 与 -tls-key 时 gRPC 与 REST 接口都使用 TLS，再指定 -tls-client-ca 时要求客户端证书 (mTLS)，
+// 收到 SIGHUP 时重新加载证书文件，只影响之后建立的连接。
+// REST 端口上的 /healthz 与 /readyz 供编排系统探测 (恢复保存的任务期间不就绪)，
+// 指定 -debug 时在该地址上另外提供 /debug/pprof/
+//
+// 所有选项也可以写在 -config (或环境变量 SLOTHD_CONFIG) 指定的 YAML 配置文件中，或者用 SLOTHD_ 开头的环境变量设置，
+// 键为选项名中的 - 换成 _ (例如 verify_cache_ttl、SLOTHD_VERIFY_CACHE_TTL)，见 config.go;
+// 显式指定的选项优先于环境变量，环境变量优先于配置文件。设置在启动时统一检查，有误时退出
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/alan22333/sloth_go/service"
+)
+
+func main() {
+	s, err := loadSettings(os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载设置失败: %v", err)
+	}
+	ps, _ := slothgo.LookupParamSet(s.Params)
+	if s.Iterations == 0 {
+		s.Iterations = ps.Iterations
+	}
+	// 标准参数中的素数可以公开复现，不需要再做素性检验
+	opts := []slothgo.Option{slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck)}
+	if s.VerifyCache > 0 {
+		opts = append(opts, slothgo.WithVerifyCache(slothgo.NewVerifyCache(s.VerifyCache, s.VerifyCacheTTL)))
+	}
+	vdf, err := slothgo.New(ps.Prime(), s.Iterations, opts...)
+	if err != nil {
+		log.Fatalf("创建 VDF 实例失败: %v", err)
+	}
+	cfg := service.Config{Workers: s.Workers, MaxIterations: s.MaxIterations}
+	if s.Data != "" {
+		if cfg.Store, err = service.NewFileStore(s.Data); err != nil {
+			log.Fatalf("打开任务目录失败: %v", err)
+		}
+		if cfg.JournalKey, err = loadJournalKey(filepath.Join(s.Data, "journal.key")); err != nil {
+			log.Fatalf("加载计算日志密钥失败: %v", err)
+		}
+		cfg.JournalDir = filepath.Join(s.Data, "journals")
+	}
+	var keys *service.Keyring
+	if s.Keys != "" {
+		if keys, err = service.NewKeyring(s.Keys); err != nil {
+			log.Fatalf("加载 API 密钥失败: %v", err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		log.Fatalf("监听失败: %v", err)
+	}
+	var tlsConfig *tls.Config
+	var grpcOpts []grpc.ServerOption
+	if s.TLSCert != "" {
+		certs, err := service.NewCertReloader(service.TLSFiles{CertFile: s.TLSCert, KeyFile: s.TLSKey, ClientCAFile: s.TLSClientCA})
+		if err != nil {
+			log.Fatalf("加载 TLS 证书失败: %v", err)
+		}
+		go reloadOnHangup(certs)
+		tlsConfig = certs.TLSConfig()
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		log.Printf("启用 TLS, 要求客户端证书: %t", s.TLSClientCA != "")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// HTTP 服务在恢复任务之前启动，恢复期间 /readyz 返回 503，编排系统不会把流量发给尚未就绪的实例
+	var st startup
+	health := service.NewHealthHandler(st.ready)
+	var servers []*http.Server
+	if s.HTTP != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /healthz", health)
+		mux.Handle("GET /readyz", health)
+		mux.Handle("/", &st)
+		servers = append(servers, serveHTTP(&http.Server{Addr: s.HTTP, Handler: mux, TLSConfig: tlsConfig}))
+		log.Printf("REST 接口监听于 %s", s.HTTP)
+	}
+	if s.Debug != "" {
+		servers = append(servers, serveHTTP(&http.Server{Addr: s.Debug, Handler: newDebugHandler(health)}))
+		log.Printf("调试接口监听于 %s", s.Debug)
+	}
+	q, err := service.NewQueue(vdf, cfg)
+	if err != nil {
+		log.Fatalf("创建任务队列失败: %v", err)
+	}
+	api, err := newAPIHandler(q, keys, s.AdminToken)
+	if err != nil {
+		log.Fatalf("创建 REST 接口失败: %v", err)
+	}
+	st.started(q, api)
+
+	grpcSrv := service.NewGRPCServer(q, grpcOpts...)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- grpcSrv.Serve(lis) }()
+	log.Printf("slothd 监听于 %s, 参数集 %s, 默认 %d 次迭代", lis.Addr(), ps.Name, vdf.Iterations)
+	select {
+	case err := <-serveErr:
+		log.Fatalf("gRPC 服务失败: %v", err)
+	case <-ctx.Done():
+	}
+	// GracefulStop 立即停止接受新的连接与调用，但要等进行中的调用结束才返回;
+	// 进度流在队列关闭后结束，剩下的调用在队列关闭后由 Stop 强制结束
+	go grpcSrv.GracefulStop()
+
+	// 然后关闭队列: 不再接受新任务 (REST 返回 503、/readyz 不就绪)，
+	// 运行中的任务写入计算日志，重启后从日志继续; 再关闭 gRPC 与 HTTP 服务，总共最多等待 -drain
+	log.Printf("正在关闭, 最多等待 %s", s.Drain)
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.Drain)
+	defer cancel()
+	if err := q.Shutdown(drainCtx); err != nil {
+		log.Printf("等待运行中的任务超时, 未写入日志的进度将丢失: %v", err)
+	}
+	grpcSrv.Stop()
+	for _, srv := range servers {
+		if err := srv.Shutdown(drainCtx); err != nil {
+			srv.Close()
+		}
+	}
+}
+
+// loadJournalKey 读取计算日志的 HMAC 密钥，文件不存在时生成随机密钥并保存
+func loadJournalKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// serveHTTP 在后台启动 srv，设置了 TLSConfig 时使用 TLS
+func serveHTTP(srv *http.Server) *http.Server {
+	go func() {
+		serve := srv.ListenAndServe
+		if srv.TLSConfig != nil {
+			serve = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP 服务失败: %v", err)
+		}
+	}()
+	return srv
+}
+
+// errStarting 是恢复任务期间 /readyz 与 REST 接口返回的错误
+var errStarting = errors.New("restoring saved jobs")
+
+// startup 在任务队列创建之前返回 503，创建之后把请求转发给 REST 接口
+type startup struct {
+	q   atomic.Pointer[service.Queue]
+	api atomic.Pointer[http.Handler]
+}
+
+func (s *startup) started(q *service.Queue, api http.Handler) {
+	s.api.Store(&api)
+	s.q.Store(q)
+}
+
+func (s *startup) ready() error {
+	q := s.q.Load()
+	if q == nil {
+		return errStarting
+	}
+	return q.Ready()
+}
+
+func (s *startup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api := s.api.Load()
+	if api == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": errStarting.Error()})
+		return
+	}
+	(*api).ServeHTTP(w, r)
+}
+
+// newDebugHandler 提供 /debug/pprof/ 性能剖析与健康检查，只应监听在内网或本机地址上
+func newDebugHandler(health http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /healthz", health)
+	mux.Handle("GET /readyz", health)
+	return mux
+}
+
+// reloadOnHangup 每次收到 SIGHUP 时重新加载证书，失败时继续使用原来的证书
+func reloadOnHangup(certs *service.CertReloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := certs.Reload(); err != nil {
+			log.Printf("重新加载 TLS 证书失败, 继续使用原来的证书: %v", err)
+			continue
+		}
+		log.Print("已重新加载 TLS 证书")
+	}
+}
+
+// newAPIHandler 返回 REST 接口，keys 不为 nil 时加上 API 密钥认证，adminToken 不为空时加上密钥管理接口
+func newAPIHandler(q *service.Queue, keys *service.Keyring, adminToken string) (http.Handler, error) {
+	api := service.NewHTTPHandler(q)
+	if keys == nil {
+		return api, nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", keys.Middleware(api))
+	if adminToken != "" {
+		admin, err := service.NewKeyAdminHandler(keys, adminToken)
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle("/admin/", admin)
+	}
+	log.Printf("REST 接口要求 API 密钥, 共 %d 个密钥", len(keys.List()))
+	return mux, nil
+}
@@ -0,0 +1,506 @@
+// Package abci 把信标实现为 CometBFT (Tendermint) 的 ABCI 应用，由验证者集合对提交排序并在出块过程中
+// 完成每一轮的 VDF 结果。
+//
+// 一轮在链上的生命周期:
+//  1. 提交交易按区块中的顺序加入当前一轮，排序由共识决定，任何单个节点都无法调整
+//  2. 距离上一次截止满 RoundBlocks 个区块并且没有等待结果的一轮时，区块末尾截止提交，
+//     按 beacon.Seed 确定种子; 之后的提交进入下一轮
+//  3. 任何节点在链外计算 Sloth，把结果作为交易提交; 区块执行时验证 (很快) 并写入 beacon.Store。
+//     开启 WithProver 的验证者在本地截止后自动计算，轮到自己提议区块时在 PrepareProposal 中放入结果
+//
+// Sloth 的计算比出块慢得多，因此不能在 FinalizeBlock 中计算; 链上只做验证，状态转换是确定的。
+// App 的方法只使用标准库类型，与 ABCI 2.0 (CometBFT v0.38) 的请求一一对应; 接入 CometBFT 的适配器是
+// 单独的模块 contrib/abci/cometbft，CometBFT 的依赖不进入主模块。设置了状态文件时 Commit 在状态
+// 同步到磁盘之后才返回，崩溃后重启的高度与状态哈希不会落后于已确认的区块
+package abci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// 交易的第一个字节是类型
+const (
+	txContribution byte = 1 // 提交: 类型 || 内容
+	txResult       byte = 2 // 结果: 类型 || 轮次 (8, 大端) || Proof.MarshalBinary
+)
+
+// 交易结果码，与 ABCI 的约定相同，0 表示成功
+const (
+	CodeOK       uint32 = 0
+	CodeInvalid  uint32 = 1 // 交易格式错误
+	CodeRejected uint32 = 2 // 格式正确但不适用于当前状态，例如结果不属于等待中的一轮或者无法通过验证
+)
+
+// appHashDomain 是应用状态哈希的域分隔前缀
+const appHashDomain = "slothgo/abci/apphash/v1"
+
+var (
+	// ErrInvalidTx 表示交易格式错误
+	ErrInvalidTx = errors.New("abci: invalid transaction")
+	// ErrRejectedTx 表示交易不适用于当前状态
+	ErrRejectedTx = errors.New("abci: transaction rejected")
+)
+
+// Option 用于在 NewApp 中配置应用
+type Option func(*App)
+
+// WithRoundBlocks 设置两次截止之间至少间隔的区块数，默认为 10
+func WithRoundBlocks(n int64) Option {
+	return func(a *App) {
+		a.roundBlocks = n
+	}
+}
+
+// WithProver 使本节点在每一轮截止后在后台计算 Sloth，并在自己提议的区块中放入结果
+func WithProver() Option {
+	return func(a *App) {
+		a.prover = true
+	}
+}
+
+// WithStateFile 在每次 Commit 时把应用状态写入 path，NewApp 时从中恢复，
+// 使 CometBFT 重启后只需要重放之后的区块。不设置时状态只在内存中，重启后从头重放
+func WithStateFile(path string) Option {
+	return func(a *App) {
+		a.stateFile = path
+	}
+}
+
+// closedRound 是已截止、等待 VDF 结果的一轮
+type closedRound struct {
+	Index         uint64   `json:"index"`
+	Previous      []byte   `json:"previous,omitempty"`
+	Contributions [][]byte `json:"contributions"`
+	Root          []byte   `json:"root"`
+	Seed          []byte   `json:"seed"`
+}
+
+// state 是共识状态，所有节点在同一高度上完全相同
+// 新一轮的序号与上一轮的随机数只取自这里，不取自 beacon.Store: 存储属于本地节点，
+// 重放时可能已经有之后的轮次，各个验证者的存储也不一定相同
+type state struct {
+	Height     int64        `json:"height"`
+	AppHash    []byte       `json:"app_hash"`
+	Pending    [][]byte     `json:"pending"`              // 当前一轮已排序的提交
+	Closed     *closedRound `json:"closed,omitempty"`     // 等待结果的一轮
+	LastClose  int64        `json:"last_close"`           // 上一次截止的高度
+	Finalized  uint64       `json:"finalized"`            // 已完成的轮数，即下一轮的序号
+	Randomness []byte       `json:"randomness,omitempty"` // 最近完成的一轮的随机数
+}
+
+// TxResult 是一笔交易的执行结果
+type TxResult struct {
+	Code uint32
+	Log  string
+}
+
+// Awaiting 是已截止、等待 VDF 结果的一轮，证明方以 Seed 为输入计算 Sloth
+type Awaiting struct {
+	Index uint64 `json:"index"`
+	Seed  []byte `json:"seed"`
+}
+
+// BlockResult 是 FinalizeBlock 的结果
+type BlockResult struct {
+	TxResults []TxResult
+	Finalized *beacon.Round // 本区块完成的一轮，没有时为 nil
+	Closed    *Awaiting     // 本区块截止的一轮，没有时为 nil
+	AppHash   []byte
+}
+
+// App 是信标的 ABCI 应用。CometBFT 在共识连接上顺序调用 PrepareProposal、ProcessProposal、
+// FinalizeBlock 与 Commit，CheckTx 与 Query 来自其他连接，可以并发调用
+type App struct {
+	vdf         *slothgo.Sloth
+	store       beacon.Store
+	roundBlocks int64
+	prover      bool
+	stateFile   string
+
+	mu        sync.Mutex
+	committed state  // 最近一次 Commit 的状态
+	working   *state // FinalizeBlock 之后、Commit 之前的状态
+
+	proveMu sync.Mutex
+	proving uint64             // 正在计算或已经算出的轮次 + 1，0 表示没有
+	ready   []byte             // 算出的结果交易
+	cancel  context.CancelFunc // 取消正在进行的计算
+}
+
+// NewApp 创建信标应用，已完成的轮次写入 store
+func NewApp(vdf *slothgo.Sloth, store beacon.Store, opts ...Option) (*App, error) {
+	if vdf == nil || store == nil {
+		return nil, errors.New("vdf and store cannot be nil")
+	}
+	a := &App{vdf: vdf, store: store, roundBlocks: 10}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.roundBlocks <= 0 {
+		return nil, errors.New("round blocks must be positive")
+	}
+	if a.stateFile != "" {
+		data, err := os.ReadFile(a.stateFile)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &a.committed); err != nil {
+				return nil, fmt.Errorf("invalid state file: %w", err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+	a.startProver(a.committed.Closed)
+	return a, nil
+}
+
+// Close 停止后台计算
+func (a *App) Close() error {
+	a.startProver(nil)
+	return nil
+}
+
+// ContributionTx 编码一笔提交交易
+func ContributionTx(data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data) > beacon.MaxContributionSize {
+		return nil, fmt.Errorf("contribution must be 1 to %d bytes", beacon.MaxContributionSize)
+	}
+	return append([]byte{txContribution}, data...), nil
+}
+
+// ResultTx 编码第 index 轮的结果交易
+func ResultTx(index uint64, proof *slothgo.Proof) ([]byte, error) {
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	tx := binary.BigEndian.AppendUint64([]byte{txResult}, index)
+	return append(tx, data...), nil
+}
+
+// decodeTx 检查交易格式，提交交易返回内容，结果交易返回轮次与证明
+func decodeTx(tx []byte) (contribution []byte, index uint64, proof *slothgo.Proof, err error) {
+	if len(tx) == 0 {
+		return nil, 0, nil, fmt.Errorf("%w: empty", ErrInvalidTx)
+	}
+	switch tx[0] {
+	case txContribution:
+		if len(tx) == 1 || len(tx)-1 > beacon.MaxContributionSize {
+			return nil, 0, nil, fmt.Errorf("%w: contribution must be 1 to %d bytes", ErrInvalidTx, beacon.MaxContributionSize)
+		}
+		return tx[1:], 0, nil, nil
+	case txResult:
+		if len(tx) < 9 {
+			return nil, 0, nil, fmt.Errorf("%w: result too short", ErrInvalidTx)
+		}
+		proof = new(slothgo.Proof)
+		if err := proof.UnmarshalBinary(tx[9:]); err != nil {
+			return nil, 0, nil, fmt.Errorf("%w: %w", ErrInvalidTx, err)
+		}
+		return nil, binary.BigEndian.Uint64(tx[1:9]), proof, nil
+	}
+	return nil, 0, nil, fmt.Errorf("%w: unknown type %d", ErrInvalidTx, tx[0])
+}
+
+// checkResult 检查结果交易适用于 s 中等待结果的一轮
+func (a *App) checkResult(s *state, index uint64, proof *slothgo.Proof) error {
+	if s.Closed == nil || s.Closed.Index != index {
+		return fmt.Errorf("%w: round %d is not awaiting a result", ErrRejectedTx, index)
+	}
+	if ok, err := a.vdf.VerifyProof(s.Closed.Seed, proof); !ok {
+		if err == nil {
+			err = errors.New("proof does not verify")
+		}
+		return fmt.Errorf("%w: round %d: %w", ErrRejectedTx, index, err)
+	}
+	return nil
+}
+
+// Info 返回最近一次 Commit 的高度与状态哈希，CometBFT 据此决定需要重放的区块
+func (a *App) Info() (height int64, appHash []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.committed.Height, bytes.Clone(a.committed.AppHash)
+}
+
+// CheckTx 在交易进入内存池之前检查它，结果交易按已提交的状态验证
+func (a *App) CheckTx(tx []byte) error {
+	_, index, proof, err := decodeTx(tx)
+	if err != nil || proof == nil {
+		return err
+	}
+	a.mu.Lock()
+	s := a.committed
+	a.mu.Unlock()
+	return a.checkResult(&s, index, proof)
+}
+
+// PrepareProposal 在本节点提议区块时选择交易: 去掉格式错误的交易与不适用的结果，不超过 maxBytes，
+// 每个区块最多一个结果。开启 WithProver 并且已经算出等待中的一轮时把自己的结果放在最前面
+func (a *App) PrepareProposal(txs [][]byte, maxBytes int64) [][]byte {
+	a.mu.Lock()
+	s := a.committed
+	a.mu.Unlock()
+	var out [][]byte
+	var size int64
+	haveResult := false
+	add := func(tx []byte) {
+		if size+int64(len(tx)) <= maxBytes {
+			out = append(out, tx)
+			size += int64(len(tx))
+		}
+	}
+	if tx := a.readyResult(); tx != nil {
+		add(tx)
+		haveResult = true
+	}
+	for _, tx := range txs {
+		_, index, proof, err := decodeTx(tx)
+		switch {
+		case err != nil:
+		case proof == nil:
+			add(tx)
+		case !haveResult && a.checkResult(&s, index, proof) == nil:
+			add(tx)
+			haveResult = true
+		}
+	}
+	return out
+}
+
+// ProcessProposal 检查其他节点提议的区块，含有格式错误的交易或无效的结果时拒绝
+func (a *App) ProcessProposal(txs [][]byte) bool {
+	a.mu.Lock()
+	s := a.committed
+	a.mu.Unlock()
+	for _, tx := range txs {
+		_, index, proof, err := decodeTx(tx)
+		if err != nil {
+			return false
+		}
+		// 同一区块中的第二个结果在执行时会被拒绝，但不影响区块的有效性
+		if proof != nil && s.Closed != nil && index == s.Closed.Index {
+			if a.checkResult(&s, index, proof) != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FinalizeBlock 按顺序执行区块中的交易，在区块末尾按需截止当前一轮
+func (a *App) FinalizeBlock(height int64, txs [][]byte) (*BlockResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.committed
+	s.Pending = append([][]byte(nil), s.Pending...)
+	s.Height = height
+	res := &BlockResult{TxResults: make([]TxResult, len(txs))}
+	for i, tx := range txs {
+		contribution, index, proof, err := decodeTx(tx)
+		switch {
+		case err != nil:
+			res.TxResults[i] = TxResult{Code: CodeInvalid, Log: err.Error()}
+		case proof == nil:
+			s.Pending = append(s.Pending, bytes.Clone(contribution))
+		default:
+			if err := a.checkResult(&s, index, proof); err != nil {
+				res.TxResults[i] = TxResult{Code: CodeRejected, Log: err.Error()}
+				continue
+			}
+			r, err := a.finalize(s.Closed, proof)
+			if err != nil {
+				return nil, err
+			}
+			res.Finalized = r
+			s.Closed, s.Finalized, s.Randomness = nil, r.Index+1, r.Randomness
+		}
+	}
+
+	if s.Closed == nil && height-s.LastClose >= a.roundBlocks {
+		c := closeRound(&s)
+		s.Closed, s.Pending, s.LastClose = c, nil, height
+		res.Closed = &Awaiting{Index: c.Index, Seed: c.Seed}
+	}
+	s.AppHash = appHash(&s)
+	res.AppHash = s.AppHash
+	a.working = &s
+	return res, nil
+}
+
+// finalize 把等待中的一轮与它的结果写入存储
+// 重放时存储中可能已经有这一轮 (写入存储之后、保存状态之前进程退出)，内容相同时直接使用
+func (a *App) finalize(c *closedRound, proof *slothgo.Proof) (*beacon.Round, error) {
+	r := &beacon.Round{
+		Index:             c.Index,
+		Previous:          c.Previous,
+		Contributions:     c.Contributions,
+		ContributionsRoot: c.Root,
+		Seed:              c.Seed,
+		Proof:             *proof,
+		Randomness:        proof.Hash,
+	}
+	if stored, err := a.store.Get(c.Index); err == nil {
+		if !bytes.Equal(stored.Seed, r.Seed) {
+			return nil, fmt.Errorf("stored round %d does not match the chain", c.Index)
+		}
+		return stored, nil
+	} else if !errors.Is(err, beacon.ErrNotFound) {
+		return nil, err
+	}
+	if err := a.store.Put(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// closeRound 以 s 中已排序的提交截止新的一轮，序号与上一轮的随机数取自 s
+func closeRound(s *state) *closedRound {
+	c := &closedRound{Index: s.Finalized, Previous: s.Randomness, Contributions: s.Pending}
+	c.Root = merkle.Root(c.Contributions)
+	c.Seed = beacon.Seed(c.Index, c.Previous, c.Root)
+	return c
+}
+
+// appHash 对共识状态做承诺:
+// SHA-256(域分隔前缀 || height || 提交数 || 提交的 Merkle 根 || 上一次截止的高度 ||
+// 已完成的轮数 || 最近一轮随机数的长度与内容 || 等待中一轮的序号与种子)
+func appHash(s *state) []byte {
+	h := sha256.New()
+	h.Write([]byte(appHashDomain))
+	binary.Write(h, binary.BigEndian, s.Height)
+	binary.Write(h, binary.BigEndian, uint64(len(s.Pending)))
+	h.Write(merkle.Root(s.Pending))
+	binary.Write(h, binary.BigEndian, s.LastClose)
+	binary.Write(h, binary.BigEndian, s.Finalized)
+	binary.Write(h, binary.BigEndian, uint64(len(s.Randomness)))
+	h.Write(s.Randomness)
+	if s.Closed != nil {
+		binary.Write(h, binary.BigEndian, s.Closed.Index)
+		h.Write(s.Closed.Seed)
+	}
+	return h.Sum(nil)
+}
+
+// Commit 使 FinalizeBlock 的状态生效，设置了状态文件时先把状态同步到磁盘，并按需开始后台计算
+func (a *App) Commit() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.working == nil {
+		return errors.New("commit without a finalized block")
+	}
+	if a.stateFile != "" {
+		data, err := json.Marshal(a.working)
+		if err != nil {
+			return err
+		}
+		if err := atomicfile.WriteFile(a.stateFile, data); err != nil {
+			return err
+		}
+	}
+	a.committed, a.working = *a.working, nil
+	a.startProver(a.committed.Closed)
+	return nil
+}
+
+// startProver 在开启 WithProver 时为等待中的一轮开始后台计算，同一轮只计算一次
+// c 为 nil 或者换成了另一轮时取消之前的计算
+func (a *App) startProver(c *closedRound) {
+	a.proveMu.Lock()
+	defer a.proveMu.Unlock()
+	if c != nil && a.proving == c.Index+1 {
+		return
+	}
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.proving, a.ready = 0, nil
+	if !a.prover || c == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.proving, a.cancel = c.Index+1, cancel
+	go func() {
+		proof, err := a.vdf.ComputeProofCtx(ctx, c.Seed)
+		if err != nil {
+			return
+		}
+		tx, err := ResultTx(c.Index, proof)
+		if err != nil {
+			return
+		}
+		a.proveMu.Lock()
+		if a.proving == c.Index+1 {
+			a.ready = tx
+		}
+		a.proveMu.Unlock()
+	}()
+}
+
+// readyResult 返回已经算出的、属于当前等待中一轮的结果交易
+func (a *App) readyResult() []byte {
+	a.mu.Lock()
+	closed := a.committed.Closed
+	a.mu.Unlock()
+	a.proveMu.Lock()
+	defer a.proveMu.Unlock()
+	if closed == nil || a.proving != closed.Index+1 {
+		return nil
+	}
+	return a.ready
+}
+
+// Query 回答只读查询:
+//
+//	/round/latest  最新完成的一轮 (JSON)
+//	/round/<序号>   第 <序号> 轮 (JSON)
+//	/pending       当前高度、当前一轮已排序的提交数与等待中的一轮 (JSON)
+func (a *App) Query(path string) ([]byte, error) {
+	switch {
+	case path == "/round/latest":
+		r, err := a.store.Latest()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(r)
+	case strings.HasPrefix(path, "/round/"):
+		index, err := strconv.ParseUint(strings.TrimPrefix(path, "/round/"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid round index: %w", err)
+		}
+		r, err := a.store.Get(index)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(r)
+	case path == "/pending":
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		v := struct {
+			Height        int64     `json:"height"`
+			Contributions int       `json:"contributions"`
+			Awaiting      *Awaiting `json:"awaiting,omitempty"`
+		}{Height: a.committed.Height, Contributions: len(a.committed.Pending)}
+		if c := a.committed.Closed; c != nil {
+			v.Awaiting = &Awaiting{Index: c.Index, Seed: c.Seed}
+		}
+		return json.Marshal(v)
+	}
+	return nil, fmt.Errorf("unknown query path %q", path)
+}
@@ -0,0 +1,259 @@
+package abci
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/store"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// block 在所有节点上执行同一个区块并检查状态哈希一致
+func block(t *testing.T, height int64, txs [][]byte, apps ...*App) *BlockResult {
+	t.Helper()
+	var first *BlockResult
+	for i, a := range apps {
+		if !a.ProcessProposal(txs) {
+			t.Fatalf("node %d rejected block %d", i, height)
+		}
+		res, err := a.FinalizeBlock(height, txs)
+		if err != nil {
+			t.Fatalf("node %d: FinalizeBlock(%d) failed: %v", i, height, err)
+		}
+		if err := a.Commit(); err != nil {
+			t.Fatalf("node %d: Commit failed: %v", i, err)
+		}
+		if first == nil {
+			first = res
+		} else if !bytes.Equal(res.AppHash, first.AppHash) {
+			t.Fatalf("node %d diverged at height %d", i, height)
+		}
+	}
+	return first
+}
+
+func contribution(t *testing.T, data string) []byte {
+	t.Helper()
+	tx, err := ContributionTx([]byte(data))
+	if err != nil {
+		t.Fatalf("ContributionTx failed: %v", err)
+	}
+	return tx
+}
+
+func TestApp(t *testing.T) {
+	vdf := newTestVDF(t)
+	proverStore, otherStore := beacon.NewMemoryStore(), beacon.NewMemoryStore()
+	prover, err := NewApp(vdf, proverStore, WithRoundBlocks(2), WithProver())
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	defer prover.Close()
+	other, err := NewApp(vdf, otherStore, WithRoundBlocks(2))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+
+	res := block(t, 1, [][]byte{contribution(t, "alice")}, prover, other)
+	if res.TxResults[0].Code != CodeOK || res.Closed != nil {
+		t.Fatalf("unexpected block 1 result: %+v", res)
+	}
+	res = block(t, 2, [][]byte{contribution(t, "bob")}, prover, other)
+	if res.Closed == nil || res.Closed.Index != 0 {
+		t.Fatalf("round 0 was not closed at height 2: %+v", res)
+	}
+	// 截止后的提交进入下一轮
+	block(t, 3, [][]byte{contribution(t, "carol")}, prover, other)
+
+	// 不是证明方的节点提议时没有结果
+	if txs := other.PrepareProposal(nil, 1<<20); len(txs) != 0 {
+		t.Fatalf("non-prover proposed %d transactions", len(txs))
+	}
+	var txs [][]byte
+	for deadline := time.Now().Add(10 * time.Second); len(txs) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("prover did not produce a result")
+		}
+		time.Sleep(time.Millisecond)
+		txs = prover.PrepareProposal([][]byte{{0}}, 1<<20)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("proposal has %d transactions, want only the result", len(txs))
+	}
+	if err := other.CheckTx(txs[0]); err != nil {
+		t.Fatalf("CheckTx rejected the result: %v", err)
+	}
+	res = block(t, 4, txs, prover, other)
+	if res.Finalized == nil || res.Finalized.Index != 0 || res.Closed == nil || res.Closed.Index != 1 {
+		t.Fatalf("unexpected block 4 result: %+v", res)
+	}
+	r, err := otherStore.Get(0)
+	if err != nil {
+		t.Fatalf("round 0 missing from store: %v", err)
+	}
+	if err := beacon.Verify(vdf, r, nil); err != nil {
+		t.Errorf("stored round does not verify: %v", err)
+	}
+	if len(r.Contributions) != 2 || string(r.Contributions[1]) != "bob" {
+		t.Errorf("unexpected contributions: %q", r.Contributions)
+	}
+
+	// 重复的结果在执行时被拒绝，区块本身有效
+	res = block(t, 5, txs, prover, other)
+	if res.TxResults[0].Code != CodeRejected || res.Finalized != nil {
+		t.Errorf("duplicate result was not rejected: %+v", res)
+	}
+	if err := other.CheckTx(txs[0]); !errors.Is(err, ErrRejectedTx) {
+		t.Errorf("CheckTx of a stale result returned %v", err)
+	}
+
+	data, err := other.Query("/pending")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var pending struct {
+		Awaiting *Awaiting `json:"awaiting"`
+	}
+	if err := json.Unmarshal(data, &pending); err != nil || pending.Awaiting == nil || pending.Awaiting.Index != 1 {
+		t.Errorf("unexpected pending state: %s", data)
+	}
+	if _, err := other.Query("/round/0"); err != nil {
+		t.Errorf("Query(/round/0) failed: %v", err)
+	}
+	if _, err := other.Query("/round/7"); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("Query(/round/7) returned %v", err)
+	}
+}
+
+func TestProcessProposalRejectsInvalidResult(t *testing.T) {
+	vdf := newTestVDF(t)
+	a, err := NewApp(vdf, beacon.NewMemoryStore(), WithRoundBlocks(1))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	block(t, 1, nil, a)
+	proof, err := vdf.ComputeProof([]byte("not the seed"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	tx, err := ResultTx(0, proof)
+	if err != nil {
+		t.Fatalf("ResultTx failed: %v", err)
+	}
+	if a.ProcessProposal([][]byte{tx}) {
+		t.Error("block with an invalid result was accepted")
+	}
+	if err := a.CheckTx(tx); !errors.Is(err, ErrRejectedTx) {
+		t.Errorf("CheckTx returned %v", err)
+	}
+	if a.ProcessProposal([][]byte{{txResult, 1}}) {
+		t.Error("block with a malformed transaction was accepted")
+	}
+	if txs := a.PrepareProposal([][]byte{{9, 9}, tx, contribution(t, "alice")}, 1<<20); len(txs) != 1 {
+		t.Errorf("PrepareProposal kept %d transactions, want only the contribution", len(txs))
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	vdf := newTestVDF(t)
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := beacon.NewMemoryStore()
+	a, err := NewApp(vdf, store, WithStateFile(path))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	block(t, 1, [][]byte{contribution(t, "alice")}, a)
+	height, hash := a.Info()
+
+	restarted, err := NewApp(vdf, store, WithStateFile(path))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	if h, got := restarted.Info(); h != height || !bytes.Equal(got, hash) {
+		t.Errorf("restored state is at height %d, want %d", h, height)
+	}
+	if err := restarted.Commit(); err == nil {
+		t.Error("Commit without FinalizeBlock succeeded")
+	}
+}
+
+// TestReplayWithStoredRounds 检查没有状态文件的节点重启后从头重放时，存储中已有的轮次不影响共识状态:
+// 重放的节点与使用空存储的节点得到与第一次执行相同的状态哈希
+func TestReplayWithStoredRounds(t *testing.T) {
+	vdf := newTestVDF(t)
+	dir := t.TempDir()
+	fs, err := store.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	a, err := NewApp(vdf, fs, WithRoundBlocks(1))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	var chain [][][]byte
+	var hashes [][]byte
+	txs := [][]byte{contribution(t, "alice")}
+	for height := int64(1); height <= 4; height++ {
+		res := block(t, height, txs, a)
+		chain, hashes = append(chain, txs), append(hashes, res.AppHash)
+		proof, err := vdf.ComputeProof(res.Closed.Seed)
+		if err != nil {
+			t.Fatalf("ComputeProof failed: %v", err)
+		}
+		result, err := ResultTx(res.Closed.Index, proof)
+		if err != nil {
+			t.Fatalf("ResultTx failed: %v", err)
+		}
+		txs = [][]byte{result, contribution(t, fmt.Sprintf("contribution %d", height))}
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	fs, err = store.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	defer fs.Close()
+	if latest, err := fs.Latest(); err != nil || latest.Index != 2 {
+		t.Fatalf("store should already hold rounds 0 to 2: %v, %v", latest, err)
+	}
+	replayed, err := NewApp(vdf, fs, WithRoundBlocks(1))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	fresh, err := NewApp(vdf, beacon.NewMemoryStore(), WithRoundBlocks(1))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	for i, txs := range chain {
+		res := block(t, int64(i+1), txs, replayed, fresh)
+		if !bytes.Equal(res.AppHash, hashes[i]) {
+			t.Fatalf("replay diverged from the original chain at height %d", i+1)
+		}
+		for j, r := range res.TxResults {
+			if r.Code != CodeOK {
+				t.Fatalf("height %d: transaction %d was rejected during replay: %s", i+1, j, r.Log)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package cometbft 把 abci.App 适配为 CometBFT v0.38 的 abcitypes.Application。
+//
+// 适配器是单独的模块，CometBFT 的依赖不进入主模块; 版本固定在 go.mod 中:
+//
+//	go get github.com/alan22333/sloth_go/contrib/abci/cometbft
+//
+// 进程内运行时把 NewApplication 的结果交给 proxy.NewLocalClientCreator，
+// 独立进程时交给 server.NewServer
+package cometbft
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
+	"github.com/alan22333/sloth_go/contrib/abci"
+)
+
+// Application 把 abci.App 适配为 abcitypes.Application，未实现的方法 (快照、投票扩展)
+// 使用 BaseApplication 的默认行为
+type Application struct {
+	abcitypes.BaseApplication
+	app *abci.App
+}
+
+var _ abcitypes.Application = (*Application)(nil)
+
+// NewApplication 包装 app
+func NewApplication(app *abci.App) *Application {
+	return &Application{app: app}
+}
+
+func (a *Application) Info(_ context.Context, _ *abcitypes.RequestInfo) (*abcitypes.ResponseInfo, error) {
+	height, hash := a.app.Info()
+	return &abcitypes.ResponseInfo{Data: "sloth-beacon", LastBlockHeight: height, LastBlockAppHash: hash}, nil
+}
+
+func (a *Application) CheckTx(_ context.Context, req *abcitypes.RequestCheckTx) (*abcitypes.ResponseCheckTx, error) {
+	if err := a.app.CheckTx(req.Tx); err != nil {
+		return &abcitypes.ResponseCheckTx{Code: code(err), Log: err.Error()}, nil
+	}
+	return &abcitypes.ResponseCheckTx{Code: abci.CodeOK}, nil
+}
+
+func (a *Application) PrepareProposal(_ context.Context, req *abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error) {
+	return &abcitypes.ResponsePrepareProposal{Txs: a.app.PrepareProposal(req.Txs, req.MaxTxBytes)}, nil
+}
+
+func (a *Application) ProcessProposal(_ context.Context, req *abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error) {
+	if a.app.ProcessProposal(req.Txs) {
+		return &abcitypes.ResponseProcessProposal{Status: abcitypes.ResponseProcessProposal_ACCEPT}, nil
+	}
+	return &abcitypes.ResponseProcessProposal{Status: abcitypes.ResponseProcessProposal_REJECT}, nil
+}
+
+func (a *Application) FinalizeBlock(_ context.Context, req *abcitypes.RequestFinalizeBlock) (*abcitypes.ResponseFinalizeBlock, error) {
+	res, err := a.app.FinalizeBlock(req.Height, req.Txs)
+	if err != nil {
+		return nil, err
+	}
+	out := &abcitypes.ResponseFinalizeBlock{AppHash: res.AppHash}
+	for _, r := range res.TxResults {
+		out.TxResults = append(out.TxResults, &abcitypes.ExecTxResult{Code: r.Code, Log: r.Log})
+	}
+	if r := res.Finalized; r != nil {
+		out.Events = append(out.Events, abcitypes.Event{
+			Type: "beacon_round",
+			Attributes: []abcitypes.EventAttribute{
+				{Key: "index", Value: strconv.FormatUint(r.Index, 10), Index: true},
+				{Key: "randomness", Value: hex.EncodeToString(r.Randomness)},
+			},
+		})
+	}
+	if c := res.Closed; c != nil {
+		out.Events = append(out.Events, abcitypes.Event{
+			Type: "beacon_closed",
+			Attributes: []abcitypes.EventAttribute{
+				{Key: "index", Value: strconv.FormatUint(c.Index, 10), Index: true},
+				{Key: "seed", Value: hex.EncodeToString(c.Seed)},
+			},
+		})
+	}
+	return out, nil
+}
+
+// Commit 在 App.Commit 把状态写入磁盘之后才返回，CometBFT 据此认为该高度已经持久化
+func (a *Application) Commit(_ context.Context, _ *abcitypes.RequestCommit) (*abcitypes.ResponseCommit, error) {
+	if err := a.app.Commit(); err != nil {
+		return nil, err
+	}
+	return &abcitypes.ResponseCommit{}, nil
+}
+
+func (a *Application) Query(_ context.Context, req *abcitypes.RequestQuery) (*abcitypes.ResponseQuery, error) {
+	value, err := a.app.Query(req.Path)
+	if err != nil {
+		return &abcitypes.ResponseQuery{Code: abci.CodeRejected, Log: err.Error()}, nil
+	}
+	return &abcitypes.ResponseQuery{Code: abci.CodeOK, Value: value}, nil
+}
+
+func code(err error) uint32 {
+	if errors.Is(err, abci.ErrInvalidTx) {
+		return abci.CodeInvalid
+	}
+	return abci.CodeRejected
+}
@@ -0,0 +1,66 @@
+package cometbft
+
+import (
+	"context"
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/contrib/abci"
+)
+
+// TestApplication 经由 CometBFT 的请求类型执行一个区块，检查交易结果、事件与 Info
+func TestApplication(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	app, err := abci.NewApp(vdf, beacon.NewMemoryStore(), abci.WithRoundBlocks(1))
+	if err != nil {
+		t.Fatalf("NewApp failed: %v", err)
+	}
+	defer app.Close()
+	a := NewApplication(app)
+	ctx := context.Background()
+
+	if res, _ := a.CheckTx(ctx, &abcitypes.RequestCheckTx{Tx: []byte{9}}); res.Code != abci.CodeInvalid {
+		t.Errorf("CheckTx of a malformed transaction returned code %d", res.Code)
+	}
+	tx, err := abci.ContributionTx([]byte("alice"))
+	if err != nil {
+		t.Fatalf("ContributionTx failed: %v", err)
+	}
+	if res, _ := a.CheckTx(ctx, &abcitypes.RequestCheckTx{Tx: tx}); res.Code != abci.CodeOK {
+		t.Errorf("CheckTx returned code %d: %s", res.Code, res.Log)
+	}
+	txs := [][]byte{tx}
+	if res, _ := a.ProcessProposal(ctx, &abcitypes.RequestProcessProposal{Txs: txs, Height: 1}); res.Status != abcitypes.ResponseProcessProposal_ACCEPT {
+		t.Fatalf("ProcessProposal returned %v", res.Status)
+	}
+	res, err := a.FinalizeBlock(ctx, &abcitypes.RequestFinalizeBlock{Txs: txs, Height: 1})
+	if err != nil {
+		t.Fatalf("FinalizeBlock failed: %v", err)
+	}
+	if len(res.TxResults) != 1 || res.TxResults[0].Code != abci.CodeOK {
+		t.Errorf("FinalizeBlock returned %v", res.TxResults)
+	}
+	if len(res.Events) != 1 || res.Events[0].Type != "beacon_closed" {
+		t.Errorf("FinalizeBlock emitted %v, want one beacon_closed event", res.Events)
+	}
+	if _, err := a.Commit(ctx, &abcitypes.RequestCommit{}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	info, _ := a.Info(ctx, &abcitypes.RequestInfo{})
+	if info.LastBlockHeight != 1 || string(info.LastBlockAppHash) != string(res.AppHash) {
+		t.Errorf("Info returned height %d", info.LastBlockHeight)
+	}
+	if q, _ := a.Query(ctx, &abcitypes.RequestQuery{Path: "/round/0"}); q.Code != abci.CodeRejected {
+		t.Errorf("Query of a missing round returned code %d", q.Code)
+	}
+}
@@ -0,0 +1,110 @@
+// sloth-evm 为一组 Sloth 参数生成 Solidity 验证合约，可选地同时生成带测试向量的 Foundry 测试:
+//
+//	sloth-evm -params sloth-256-t30s -interval 50000 -out src/SlothVerifier.sol \
+//	    -test-out test/SlothVerifier.t.sol -import ../src/SlothVerifier.sol
+//
+// 参数来自标准参数集 (-params)，或者素数文件 (-prime-file，十六进制或 sloth params gen 的输出) 与 -iters。
+// 测试向量在本机计算，迭代次数大时需要相应的时间
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/contrib/evm"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+func main() {
+	paramSet := flag.String("params", "", "标准参数集名称, 例如 sloth-256-t30s")
+	primeFile := flag.String("prime-file", "", "素数文件, 与 -iters 一起使用")
+	iterations := flag.Uint64("iters", 0, "迭代次数, 使用 -params 时覆盖参数集的值")
+	interval := flag.Uint64("interval", 0, "检查点间隔, 0 表示没有检查点")
+	name := flag.String("name", "SlothVerifier", "合约名称")
+	out := flag.String("out", "-", `合约文件, "-" 表示标准输出`)
+	testOut := flag.String("test-out", "", "Foundry 测试文件, 为空时不生成")
+	importPath := flag.String("import", "", "测试文件中导入合约的路径, 默认为 -out")
+	vectors := flag.Int("vectors", 2, "测试向量的个数")
+	flag.Parse()
+
+	vdf, err := newVDF(*paramSet, *primeFile, *iterations, *interval)
+	if err != nil {
+		log.Fatalf("加载参数失败: %v", err)
+	}
+	opts := []evm.Option{evm.WithContractName(*name)}
+	if *interval > 0 {
+		opts = append(opts, evm.WithCheckpointInterval(*interval))
+	}
+
+	var buf bytes.Buffer
+	if err := evm.Generate(&buf, vdf, opts...); err != nil {
+		log.Fatalf("生成合约失败: %v", err)
+	}
+	if err := write(*out, buf.Bytes()); err != nil {
+		log.Fatalf("写入合约失败: %v", err)
+	}
+	if *testOut == "" {
+		return
+	}
+
+	var vs []evm.Vector
+	for i := range *vectors {
+		input := []byte(fmt.Sprintf("sloth-evm test vector %d", i))
+		proof, err := vdf.ComputeProof(input)
+		if err != nil {
+			log.Fatalf("计算测试向量失败: %v", err)
+		}
+		vs = append(vs, evm.Vector{Input: input, Proof: proof})
+	}
+	if *importPath == "" {
+		*importPath = *out
+	}
+	buf.Reset()
+	if err := evm.GenerateTest(&buf, vdf, *importPath, vs, opts...); err != nil {
+		log.Fatalf("生成测试失败: %v", err)
+	}
+	if err := write(*testOut, buf.Bytes()); err != nil {
+		log.Fatalf("写入测试失败: %v", err)
+	}
+}
+
+// newVDF 按命令行选项创建 Sloth 实例，interval 大于 0 时证明带检查点
+func newVDF(paramSet, primeFile string, iterations, interval uint64) (*slothgo.Sloth, error) {
+	var opts []slothgo.Option
+	if interval > 0 {
+		opts = append(opts, slothgo.WithSegmentCheckpoints(interval))
+	}
+	switch {
+	case paramSet != "" && primeFile != "":
+		return nil, errors.New("-params and -prime-file cannot be used together")
+	case paramSet != "":
+		vdf, err := slothgo.NewStandard(paramSet, opts...)
+		if err != nil || iterations == 0 {
+			return vdf, err
+		}
+		return vdf.WithIterations(iterations)
+	case primeFile != "":
+		if iterations == 0 {
+			return nil, errors.New("-iters is required with -prime-file")
+		}
+		p, err := config.ReadPrime(primeFile)
+		if err != nil {
+			return nil, err
+		}
+		return slothgo.New(p, iterations, opts...)
+	}
+	return nil, errors.New("either -params or -prime-file is required")
+}
+
+func write(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,180 @@
+// Package evm 为一组 Sloth 参数生成验证证明的 Solidity 合约，使 EVM 链上的合约可以不依赖可信第三方
+// 使用信标的输出。验证方向只需要模 p 的平方 (mulmod)，因此只支持不超过 256 位的素数与默认选项
+// (sqrt 置换、SHA-256、取模输入映射、没有域标签与参数绑定)，与 embedded 子包的范围相同。
+//
+// 整体验证的 gas 与迭代次数成正比。证明方使用 slothgo.WithSegmentCheckpoints(k) 时，
+// 合约的 verifySegment 可以单独检查任意一个区段，配合 slothgo 的欺诈证明只需要在链上验证一个区段
+package evm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"math/big"
+	"text/template"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// Option 用于在 Generate 中配置生成的合约
+type Option func(*config)
+
+type config struct {
+	name     string
+	interval uint64
+}
+
+// WithContractName 设置合约名称，默认为 "SlothVerifier"
+func WithContractName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithCheckpointInterval 设置证明中检查点的间隔 k，应与证明方的 slothgo.WithSegmentCheckpoints(k) 相同
+// 默认没有检查点 (k 等于迭代次数)，整个计算是一个区段
+func WithCheckpointInterval(k uint64) Option {
+	return func(c *config) {
+		c.interval = k
+	}
+}
+
+// contract 是模板使用的数据
+type contract struct {
+	Name           string
+	P              string
+	Bits           int
+	Iterations     uint64
+	Interval       uint64
+	Segments       uint64
+	WitnessSize    int
+	Fingerprint    string
+	FingerprintHex string
+	EncodeWitness  string
+}
+
+var (
+	verifierTmpl = template.Must(template.New("verifier").Parse(verifierTemplate))
+	testTmpl     = template.Must(template.New("test").Parse(testTemplate))
+)
+
+// Generate 把 vdf 参数的验证合约写入 w
+func Generate(w io.Writer, vdf *slothgo.Sloth, opts ...Option) error {
+	c, err := newContract(vdf, opts)
+	if err != nil {
+		return err
+	}
+	return execute(w, verifierTmpl, c)
+}
+
+// Vector 是一个测试向量: 输入与带检查点的证明
+type Vector struct {
+	Input []byte
+	Proof *slothgo.Proof
+}
+
+// GenerateTest 写入 Foundry 测试合约，检查 Generate 生成的合约接受 vectors 中的证明并拒绝修改过的输入
+// importPath 是验证合约源文件相对于测试文件的路径，例如 "../src/SlothVerifier.sol"
+func GenerateTest(w io.Writer, vdf *slothgo.Sloth, importPath string, vectors []Vector, opts ...Option) error {
+	c, err := newContract(vdf, opts)
+	if err != nil {
+		return err
+	}
+	type vector struct {
+		Input       string
+		Hash        string
+		Witness     string
+		Checkpoints []string
+	}
+	data := struct {
+		Name    string
+		Import  string
+		Vectors []vector
+	}{Name: c.Name, Import: importPath}
+	for i, v := range vectors {
+		if v.Proof == nil || v.Proof.Witness == nil {
+			return fmt.Errorf("vector %d has no proof", i)
+		}
+		if uint64(len(v.Proof.Checkpoints)) != c.Segments-1 || (len(v.Proof.Checkpoints) > 0 && v.Proof.CheckpointInterval != c.Interval) {
+			return fmt.Errorf("vector %d: proof checkpoints do not match interval %d", i, c.Interval)
+		}
+		tv := vector{
+			Input:   hex.EncodeToString(v.Input),
+			Hash:    "0x" + hex.EncodeToString(v.Proof.Hash),
+			Witness: uint256(v.Proof.Witness),
+		}
+		for _, cp := range v.Proof.Checkpoints {
+			tv.Checkpoints = append(tv.Checkpoints, uint256(cp))
+		}
+		data.Vectors = append(data.Vectors, tv)
+	}
+	return execute(w, testTmpl, data)
+}
+
+// newContract 检查参数是否受支持并计算模板数据
+func newContract(vdf *slothgo.Sloth, opts []Option) (*contract, error) {
+	if vdf == nil {
+		return nil, errors.New("vdf cannot be nil")
+	}
+	params := vdf.Params()
+	switch {
+	case params.P.BitLen() > 256:
+		return nil, errors.New("p must not exceed 256 bits")
+	case params.Permutation != "sqrt":
+		return nil, fmt.Errorf("permutation %q is not supported, only sqrt", params.Permutation)
+	case params.Hash != slothgo.HashSHA256:
+		return nil, fmt.Errorf("hash %s is not supported, only SHA-256", params.Hash)
+	case len(params.DomainTag) > 0 || params.BindParams || params.HashToField:
+		return nil, errors.New("domain tags, parameter binding and hash-to-field are not supported")
+	}
+
+	cfg := config{name: "SlothVerifier", interval: params.Iterations}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !token.IsIdentifier(cfg.name) {
+		return nil, fmt.Errorf("invalid contract name %q", cfg.name)
+	}
+	if cfg.interval == 0 {
+		return nil, errors.New("checkpoint interval must be positive")
+	}
+	cfg.interval = min(cfg.interval, params.Iterations)
+
+	size := vdf.WitnessSize()
+	encode := "witness"
+	if size < 32 {
+		encode = fmt.Sprintf("bytes%d(bytes32(witness << %d))", size, 256-8*size)
+	}
+	fp := vdf.Fingerprint()
+	return &contract{
+		Name:           cfg.name,
+		P:              uint256(params.P),
+		Bits:           params.P.BitLen(),
+		Iterations:     params.Iterations,
+		Interval:       cfg.interval,
+		Segments:       (params.Iterations + cfg.interval - 1) / cfg.interval,
+		WitnessSize:    size,
+		Fingerprint:    hex.EncodeToString(fp),
+		FingerprintHex: "0x" + hex.EncodeToString(fp),
+		EncodeWitness:  encode,
+	}, nil
+}
+
+// uint256 把 x 格式化为 Solidity 的整数字面量
+// 使用十进制: 恰好 40 个十六进制数字的字面量会被 solc 当作地址并要求校验和
+func uint256(x *big.Int) string {
+	return x.String()
+}
+
+// execute 先在内存中渲染模板，避免出错时写出不完整的合约
+func execute(w io.Writer, t *template.Template, data any) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
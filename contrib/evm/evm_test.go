@@ -0,0 +1,169 @@
+package evm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func newTestVDF(t *testing.T, opts ...slothgo.Option) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(160)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 1000, opts...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// solidityInverse 与合约 reverse 中汇编循环的一次迭代逐条对应
+func solidityInverse(p, w *big.Int) *big.Int {
+	odd := w.Bit(0) == 1
+	w = new(big.Int).Mul(w, w)
+	w.Mod(w, p)
+	if odd && w.Sign() != 0 {
+		w.Sub(p, w)
+	}
+	switch {
+	case w.Bit(0) == 1:
+		w.Add(w, big.NewInt(1))
+	case w.Sign() != 0:
+		w.Sub(w, big.NewInt(1))
+	}
+	return w
+}
+
+// TestSolidityArithmetic 检查合约使用的逆向迭代、w₀ 与输出哈希和 Go 实现一致，
+// 并用它们验证一个带检查点的证明 (这里没有 solc，合约本身由 Foundry 测试检查，见 GenerateTest)
+func TestSolidityArithmetic(t *testing.T) {
+	vdf := newTestVDF(t, slothgo.WithSegmentCheckpoints(300))
+	p := vdf.P
+	for _, x := range []int64{0, 1, 2, 3, 12345} {
+		w := big.NewInt(x)
+		if got, want := solidityInverse(p, w), vdf.TauInverse(w); got.Cmp(want) != 0 {
+			t.Errorf("inverse(%d) = %v, want %v", x, got, want)
+		}
+	}
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	if got, want := solidityInverse(p, pm1), vdf.TauInverse(pm1); got.Cmp(want) != 0 {
+		t.Errorf("inverse(p-1) = %v, want %v", got, want)
+	}
+
+	input := []byte("evm")
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	digest := sha256.Sum256(input)
+	start := new(big.Int).Mod(new(big.Int).SetBytes(digest[:]), p)
+	ends := append(append([]*big.Int(nil), proof.Checkpoints...), proof.Witness)
+	for j, end := range ends {
+		w := end
+		for range min(uint64(j+1)*300, vdf.Iterations) - uint64(j)*300 {
+			w = solidityInverse(p, w)
+		}
+		if w.Cmp(start) != 0 {
+			t.Fatalf("segment %d does not reverse to its start", j)
+		}
+		start = end
+	}
+	if sum := sha256.Sum256(vdf.EncodeWitness(proof.Witness)); !bytes.Equal(sum[:], proof.Hash) {
+		t.Error("output hash differs from SHA-256 of the encoded witness")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	vdf := newTestVDF(t)
+	var buf bytes.Buffer
+	if err := Generate(&buf, vdf, WithContractName("BeaconVerifier"), WithCheckpointInterval(300)); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"contract BeaconVerifier {",
+		"uint256 public constant P = " + vdf.P.String() + ";",
+		"uint256 public constant ITERATIONS = 1000;",
+		"uint256 public constant CHECKPOINT_INTERVAL = 300;",
+		"uint256 public constant SEGMENTS = 4;",
+		"bytes20(bytes32(witness << 96))",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated contract does not contain %q", want)
+		}
+	}
+
+	std, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatalf("NewStandard failed: %v", err)
+	}
+	buf.Reset()
+	if err := Generate(&buf, std); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "abi.encodePacked(witness)") || !strings.Contains(buf.String(), "SEGMENTS = 1;") {
+		t.Error("256-bit contract should encode the witness as a full word without checkpoints")
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(160)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	for name, opts := range map[string][]slothgo.Option{
+		"domain tag": {slothgo.WithDomainTag([]byte("app"))},
+		"binding":    {slothgo.WithParamBinding()},
+		"hash":       {slothgo.WithHash(slothgo.HashSHA3_256)},
+	} {
+		vdf, err := slothgo.New(p, 10, opts...)
+		if err != nil {
+			t.Fatalf("%s: New failed: %v", name, err)
+		}
+		if err := Generate(&bytes.Buffer{}, vdf); err == nil {
+			t.Errorf("%s: unsupported parameters were accepted", name)
+		}
+	}
+	vdf, _ := slothgo.New(p, 10)
+	if err := Generate(&bytes.Buffer{}, vdf, WithContractName("not valid")); err == nil {
+		t.Error("invalid contract name was accepted")
+	}
+	if err := Generate(&bytes.Buffer{}, vdf, WithCheckpointInterval(0)); err == nil {
+		t.Error("zero interval was accepted")
+	}
+}
+
+func TestGenerateTest(t *testing.T) {
+	vdf := newTestVDF(t, slothgo.WithSegmentCheckpoints(300))
+	proof, err := vdf.ComputeProof([]byte("vector"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	var buf bytes.Buffer
+	vectors := []Vector{{Input: []byte("vector"), Proof: proof}}
+	if err := GenerateTest(&buf, vdf, "../src/SlothVerifier.sol", vectors, WithCheckpointInterval(300)); err != nil {
+		t.Fatalf("GenerateTest failed: %v", err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		`import {SlothVerifier} from "../src/SlothVerifier.sol";`,
+		"contract SlothVerifierTest is Test {",
+		"new uint256[](3);",
+		"checkpoints[2] = " + proof.Checkpoints[2].String() + ";",
+		`verifier.verify(hex"766563746f72", `,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated test does not contain %q", want)
+		}
+	}
+	// 检查点间隔与合约不一致的向量被拒绝
+	if err := GenerateTest(&bytes.Buffer{}, vdf, "V.sol", vectors); err == nil {
+		t.Error("vector with mismatched checkpoints was accepted")
+	}
+}
@@ -0,0 +1,140 @@
+package evm
+
+// verifierTemplate 是生成的验证合约，逆向迭代 (平方) 写成内联汇编以节省 gas
+const verifierTemplate = `// SPDX-License-Identifier: MIT
+// 由 github.com/alan22333/sloth_go/contrib/evm 生成，不要手工修改
+// 参数指纹 {{.Fingerprint}}
+pragma solidity ^0.8.20;
+
+/// @title Sloth 证明验证合约 ({{.Bits}} 位素数，{{.Iterations}} 次迭代)
+/// @notice 验证 sqrt 置换、SHA-256、取模输入映射下的 Sloth 证明与信标轮次。
+/// 验证方向只需要平方: 每次逆向迭代为 w := σ(±w² mod p)。
+/// 整体验证的 gas 与迭代次数成正比，带检查点的证明可以用 verifySegment 逐个区段检查。
+contract {{.Name}} {
+    /// @notice 模数 p
+    uint256 public constant P = {{.P}};
+    /// @notice 迭代次数 l
+    uint256 public constant ITERATIONS = {{.Iterations}};
+    /// @notice 检查点间隔 k，证明包含 ceil(l/k) - 1 个检查点
+    uint256 public constant CHECKPOINT_INTERVAL = {{.Interval}};
+    /// @notice 区段数 ceil(l/k)
+    uint256 public constant SEGMENTS = {{.Segments}};
+    /// @notice 与 slothgo.Sloth.Fingerprint 相同的参数指纹
+    bytes32 public constant FINGERPRINT = {{.FingerprintHex}};
+
+    uint256 private constant WITNESS_SIZE = {{.WitnessSize}};
+
+    /// @notice 信标种子的域分隔前缀，与 beacon.Seed 相同
+    bytes private constant SEED_DOMAIN = "slothgo/beacon/seed/v1";
+
+    /// @notice w₀ = SHA-256(input) mod p
+    function initialValue(bytes calldata input) public pure returns (uint256) {
+        return uint256(sha256(input)) % P;
+    }
+
+    /// @notice 输出哈希 g = SHA-256(witness 的 WITNESS_SIZE 字节大端编码)
+    function outputHash(uint256 witness) public pure returns (bytes32) {
+        return sha256(abi.encodePacked({{.EncodeWitness}}));
+    }
+
+    /// @notice 从 w 出发逆向迭代 n 次
+    function reverse(uint256 w, uint256 n) public pure returns (uint256) {
+        require(w < P, "witness out of range");
+        assembly {
+            let p := P
+            for { let i := 0 } lt(i, n) { i := add(i, 1) } {
+                let odd := and(w, 1)
+                w := mulmod(w, w, p)
+                if and(odd, iszero(iszero(w))) { w := sub(p, w) }
+                switch and(w, 1)
+                case 1 { w := add(w, 1) }
+                default { if w { w := sub(w, 1) } }
+            }
+        }
+        return w;
+    }
+
+    /// @notice 检查 hash 与 witness 是 input 的正确计算结果，checkpoints 为证明中的检查点
+    function verify(bytes calldata input, bytes32 hash, uint256 witness, uint256[] calldata checkpoints)
+        external
+        pure
+        returns (bool)
+    {
+        return verifyFrom(initialValue(input), hash, witness, checkpoints);
+    }
+
+    /// @notice 检查 witness 与各检查点依次连接 start，并且 witness 的哈希为 hash
+    function verifyFrom(uint256 start, bytes32 hash, uint256 witness, uint256[] calldata checkpoints)
+        internal
+        pure
+        returns (bool)
+    {
+        if (witness >= P || outputHash(witness) != hash || checkpoints.length != SEGMENTS - 1) {
+            return false;
+        }
+        for (uint256 j = 0; j < SEGMENTS; j++) {
+            uint256 end = j + 1 < SEGMENTS ? checkpoints[j] : witness;
+            if (!verifySegment(j, start, end)) {
+                return false;
+            }
+            start = end;
+        }
+        return true;
+    }
+
+    /// @notice 检查第 j 个区段: 从 end 逆向迭代该区段的次数后回到 start
+    function verifySegment(uint256 j, uint256 start, uint256 end) public pure returns (bool) {
+        if (j >= SEGMENTS || start >= P || end >= P) {
+            return false;
+        }
+        uint256 first = j * CHECKPOINT_INTERVAL;
+        uint256 last = first + CHECKPOINT_INTERVAL;
+        if (last > ITERATIONS) {
+            last = ITERATIONS;
+        }
+        return reverse(end, last - first) == start;
+    }
+
+    /// @notice 第 index 轮的种子，与 beacon.Seed 相同; previous 为上一轮随机数，第 0 轮为空
+    function seed(uint64 index, bytes calldata previous, bytes32 root) public pure returns (bytes32) {
+        require(previous.length < 256, "previous too long");
+        return sha256(abi.encodePacked(SEED_DOMAIN, index, uint8(previous.length), previous, root));
+    }
+
+    /// @notice 检查信标第 index 轮的随机数 randomness 由上一轮随机数与提交的 Merkle 根 root 计算得到
+    function verifyRound(
+        uint64 index,
+        bytes calldata previous,
+        bytes32 root,
+        bytes32 randomness,
+        uint256 witness,
+        uint256[] calldata checkpoints
+    ) external pure returns (bool) {
+        // 种子是 32 字节，它的 SHA-256 就是 w₀ 映射前的摘要
+        uint256 start = uint256(sha256(abi.encodePacked(seed(index, previous, root)))) % P;
+        return verifyFrom(start, randomness, witness, checkpoints);
+    }
+}
+`
+
+// testTemplate 是配合 Foundry (forge test) 使用的测试合约，向量由 Go 实现计算
+const testTemplate = `// SPDX-License-Identifier: MIT
+// 由 github.com/alan22333/sloth_go/contrib/evm 生成，不要手工修改
+pragma solidity ^0.8.20;
+
+import {Test} from "forge-std/Test.sol";
+import {{"{"}}{{.Name}}{{"}"}} from "{{.Import}}";
+
+contract {{.Name}}Test is Test {
+    {{.Name}} verifier = new {{.Name}}();
+{{range $i, $v := .Vectors}}
+    function testVector{{$i}}() public view {
+        uint256[] memory checkpoints = new uint256[]({{len $v.Checkpoints}});
+{{- range $j, $c := $v.Checkpoints}}
+        checkpoints[{{$j}}] = {{$c}};
+{{- end}}
+        assertTrue(verifier.verify(hex"{{$v.Input}}", {{$v.Hash}}, {{$v.Witness}}, checkpoints));
+        assertFalse(verifier.verify(hex"{{$v.Input}}ff", {{$v.Hash}}, {{$v.Witness}}, checkpoints));
+    }
+{{end}}}
+`
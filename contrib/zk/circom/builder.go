@@ -0,0 +1,196 @@
+package circom
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// bn254 是 circom 默认使用的 BN254 标量域的模数 r
+var bn254, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+// 大整数按 limbs 个 limbBits 位的 limb 表示，p 不超过 256 位
+const (
+	limbBits = 64
+	limbs    = 4
+)
+
+var limbBase = new(big.Int).Lsh(big.NewInt(1), limbBits)
+
+// term 是线性组合中的一项 coef·signal，signal 0 是常数 1
+type term struct {
+	signal int
+	coef   *big.Int
+}
+
+// lc 是信号的线性组合，系数是有符号整数，只在检查与输出时约减到 BN254
+type lc []term
+
+// constraint 是 R1CS 约束 a·b = c，a 为 nil 时表示线性约束 b = c
+type constraint struct {
+	a, b, c lc
+}
+
+// builder 同时记录约束与信号的取值，约束的结构与取值无关，因此可以用任意取值构建后输出模板
+// 信号 0 是常数 1，1..inputs 是模板的输入 (y 的各个 limb)，之后是辅助信号
+type builder struct {
+	values []*big.Int
+	cons   []constraint
+	inputs int
+}
+
+func newBuilder(inputs []*big.Int) *builder {
+	b := &builder{values: []*big.Int{big.NewInt(1)}, inputs: len(inputs)}
+	for _, v := range inputs {
+		b.values = append(b.values, new(big.Int).Set(v))
+	}
+	return b
+}
+
+// alloc 分配一个取值为 v 的辅助信号
+func (b *builder) alloc(v *big.Int) int {
+	b.values = append(b.values, new(big.Int).Set(v))
+	return len(b.values) - 1
+}
+
+func constant(c *big.Int) lc {
+	return lc{{0, new(big.Int).Set(c)}}
+}
+
+func sig(i int) lc {
+	return lc{{i, big.NewInt(1)}}
+}
+
+// plus 返回 x + c·y
+func (x lc) plus(c int64, y lc) lc {
+	out := append(lc(nil), x...)
+	for _, t := range y {
+		out = append(out, term{t.signal, new(big.Int).Mul(t.coef, big.NewInt(c))})
+	}
+	return out
+}
+
+// scaled 返回 c·x
+func (x lc) scaled(c *big.Int) lc {
+	out := make(lc, len(x))
+	for i, t := range x {
+		out[i] = term{t.signal, new(big.Int).Mul(t.coef, c)}
+	}
+	return out
+}
+
+// eval 在整数上计算线性组合的值
+func (b *builder) eval(x lc) *big.Int {
+	v := new(big.Int)
+	for _, t := range x {
+		v.Add(v, new(big.Int).Mul(t.coef, b.values[t.signal]))
+	}
+	return v
+}
+
+// assertMul 添加约束 x·y = z
+func (b *builder) assertMul(x, y, z lc) {
+	b.cons = append(b.cons, constraint{x, y, z})
+}
+
+// assertZero 添加线性约束 x = 0
+func (b *builder) assertZero(x lc) {
+	b.cons = append(b.cons, constraint{nil, x, nil})
+}
+
+// bits 把 x 分解为 n 个比特信号并约束 Σ 2^i·bᵢ = x，从而证明 0 <= x < 2^n
+func (b *builder) bits(x lc, n int) []int {
+	v := b.eval(x)
+	out := make([]int, n)
+	sum := lc{}
+	for i := range n {
+		out[i] = b.alloc(big.NewInt(int64(v.Bit(i))))
+		// bᵢ·(bᵢ - 1) = 0
+		b.assertMul(sig(out[i]), sig(out[i]).plus(-1, constant(big.NewInt(1))), nil)
+		sum = sum.plus(1, sig(out[i]).scaled(new(big.Int).Lsh(big.NewInt(1), uint(i))))
+	}
+	b.assertZero(sum.plus(-1, x))
+	return out
+}
+
+// number 分配 v 的各个 limb 并检查它们的范围，返回 limb 信号与最低 limb 的比特
+func (b *builder) number(v *big.Int) ([limbs]int, []int) {
+	var out [limbs]int
+	var low []int
+	for i := range limbs {
+		out[i] = b.alloc(limb(v, i))
+		bits := b.bits(sig(out[i]), limbBits)
+		if i == 0 {
+			low = bits
+		}
+	}
+	return out, low
+}
+
+// mul 分配 x·y 的积信号
+func (b *builder) mul(x, y int) int {
+	z := b.alloc(new(big.Int).Mul(b.values[x], b.values[y]))
+	b.assertMul(sig(x), sig(y), sig(z))
+	return z
+}
+
+// isZero 返回 [x == 0] 信号: x·inv = 1 - z，x·z = 0
+func (b *builder) isZero(x lc) int {
+	v := b.eval(x)
+	inv := new(big.Int)
+	z := big.NewInt(1)
+	if v.Sign() != 0 {
+		inv.ModInverse(new(big.Int).Mod(v, bn254), bn254)
+		z.SetInt64(0)
+	}
+	invSig, zSig := b.alloc(inv), b.alloc(z)
+	b.assertMul(x, sig(invSig), constant(big.NewInt(1)).plus(-1, sig(zSig)))
+	b.assertMul(x, sig(zSig), nil)
+	return zSig
+}
+
+// polyZero 约束 Σ coefs[j]·B^j = 0 (B = 2^limbBits) 在整数上成立:
+// 逐位进位 coefs[j] + c[j-1] = c[j]·B，最高位没有进位，每个进位 c 在 [-2^(m-1), 2^(m-1)) 内
+// 所有量都远小于 BN254 的模数，因此域上的等式就是整数等式
+func (b *builder) polyZero(coefs []lc, m int) {
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(m-1))
+	carry := lc{}
+	carryValue := new(big.Int)
+	for j, coef := range coefs {
+		total := coef.plus(1, carry)
+		if j == len(coefs)-1 {
+			b.assertZero(total)
+			return
+		}
+		// 诚实的取值下 total 能被 B 整除; 否则进位取向下取整，约束不会满足
+		carryValue.Add(b.eval(coef), carryValue)
+		next := new(big.Int).Div(carryValue, limbBase)
+		c := b.alloc(next)
+		carryValue.Set(next)
+		b.assertZero(total.plus(-1, sig(c).scaled(limbBase)))
+		b.bits(sig(c).plus(1, constant(offset)), m)
+		carry = sig(c)
+	}
+}
+
+// check 在 BN254 上检查全部约束，返回第一个不满足的约束
+func (b *builder) check() error {
+	mod := func(x lc) *big.Int {
+		return new(big.Int).Mod(b.eval(x), bn254)
+	}
+	for i, c := range b.cons {
+		left := mod(c.b)
+		if c.a != nil {
+			left.Mul(left, mod(c.a)).Mod(left, bn254)
+		}
+		if left.Cmp(mod(c.c)) != 0 {
+			return fmt.Errorf("constraint %d is not satisfied", i)
+		}
+	}
+	return nil
+}
+
+// limb 返回 v 的第 i 个 limb
+func limb(v *big.Int, i int) *big.Int {
+	x := new(big.Int).Rsh(v, uint(i*limbBits))
+	return x.And(x, new(big.Int).Sub(limbBase, big.NewInt(1)))
+}
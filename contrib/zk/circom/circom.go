@@ -0,0 +1,247 @@
+// Package circom 把 zk 包中的 Sloth 验证关系输出为 circom 模板，并在 Go 中生成对应的输入文件，
+// 供基于 circom/snarkjs 的证明栈使用 (gnark 子包是同一关系的 gnark 实现)。
+//
+// circom 默认在 BN254 的标量域上工作，Sloth 的模数与它不同，因此域元素按 4 个 64 位 limb 表示，
+// 模 p 的乘法用整数上的进位链检查。每次迭代的商、余数、进位与比特分解等中间量不由 circom 的见证
+// 计算生成，而是作为私有输入 aux 由 NewInput 在 Go 中算出，模板只负责检查它们; 这样模板中只有
+// 约束 (===)，没有需要与 Go 代码保持一致的见证计算逻辑。
+//
+// 生成与证明的流程:
+//
+//	sloth-circom -params sloth-256-t30s -iters 64 -out sloth.circom -input hello -input-out input.json
+//	circom sloth.circom --r1cs --wasm
+//	snarkjs wtns calculate sloth_js/sloth.wasm input.json witness.wtns，再 groth16 setup / prove
+//
+// 公开输入是 w₀ 与 witness 的 limb (低位在前)，验证方在电路外计算 w₀ = SHA-256(input) mod p
+// 并检查输出哈希 (见 zk.CheckOutput)。约束数与迭代次数成正比，完整的 40 万次迭代远超常见证明方的能力，
+// 实际使用时应配合检查点逐区段证明 (WithIterations 取检查点间隔)。
+package circom
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/contrib/zk"
+)
+
+// Option 用于在 Generate 中配置生成的模板
+type Option func(*config)
+
+type config struct {
+	iterations uint64
+}
+
+// WithIterations 设置电路证明的迭代次数，默认为 vdf.Iterations
+// 逐区段证明时取检查点间隔，区段两端的值由 NewInput 给出
+func WithIterations(n uint64) Option {
+	return func(c *config) {
+		c.iterations = n
+	}
+}
+
+// 模板中辅助信号从 aux[0] 开始，对应 builder 中的信号 1 + limbs
+const firstAux = 1 + limbs
+
+// Generate 为 vdf 的参数输出 circom 模板，主组件的公开输入为 start 与 witness
+func Generate(w io.Writer, vdf *slothgo.Sloth, opts ...Option) error {
+	if err := zk.CheckParams(vdf); err != nil {
+		return err
+	}
+	if vdf.P.BitLen() > limbs*limbBits {
+		return fmt.Errorf("modulus has %d bits, at most %d are supported", vdf.P.BitLen(), limbs*limbBits)
+	}
+	c := config{iterations: vdf.Iterations}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.iterations == 0 {
+		return errors.New("iterations must be positive")
+	}
+
+	// 约束的结构与取值无关，用 0 构建即可
+	st := newStep(vdf.P, new(big.Int))
+	wr := newWitnessRange(vdf.P, new(big.Int))
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "// Sloth 验证关系，由 github.com/alan22333/sloth_go/contrib/zk/circom 生成，请勿手工修改\n")
+	fmt.Fprintf(bw, "// p = %s，迭代次数 %d\n", vdf.P, c.iterations)
+	fmt.Fprintf(bw, "pragma circom 2.1.0;\n\n")
+
+	fmt.Fprintf(bw, "// 一次逆向迭代 next = σ(±y² mod p)，aux 由 Go 的 NewInput 生成\n")
+	fmt.Fprintf(bw, "template SlothStep() {\n")
+	fmt.Fprintf(bw, "    signal input y[%d];\n", limbs)
+	fmt.Fprintf(bw, "    signal input aux[%d];\n", st.auxCount())
+	fmt.Fprintf(bw, "    signal output next[%d];\n\n", limbs)
+	writeConstraints(bw, st.builder, "y")
+	for j, n := range st.next {
+		fmt.Fprintf(bw, "    next[%d] <== aux[%d];\n", j, n-firstAux)
+	}
+	fmt.Fprintf(bw, "}\n\n")
+
+	fmt.Fprintf(bw, "// witness < p\n")
+	fmt.Fprintf(bw, "template SlothWitnessRange() {\n")
+	fmt.Fprintf(bw, "    signal input w[%d];\n", limbs)
+	fmt.Fprintf(bw, "    signal input aux[%d];\n\n", wr.auxCount())
+	writeConstraints(bw, wr, "w")
+	fmt.Fprintf(bw, "}\n\n")
+
+	fmt.Fprintf(bw, "// 从 witness 出发逆向迭代 ITER 次得到 start\n")
+	fmt.Fprintf(bw, "template SlothVerify(ITER) {\n")
+	fmt.Fprintf(bw, "    signal input start[%d];\n", limbs)
+	fmt.Fprintf(bw, "    signal input witness[%d];\n", limbs)
+	fmt.Fprintf(bw, "    signal input witnessAux[%d];\n", wr.auxCount())
+	fmt.Fprintf(bw, "    signal input aux[ITER][%d];\n\n", st.auxCount())
+	fmt.Fprintf(bw, "    component witnessRange = SlothWitnessRange();\n")
+	fmt.Fprintf(bw, "    for (var j = 0; j < %d; j++) {\n", limbs)
+	fmt.Fprintf(bw, "        witnessRange.w[j] <== witness[j];\n")
+	fmt.Fprintf(bw, "    }\n")
+	fmt.Fprintf(bw, "    for (var k = 0; k < %d; k++) {\n", wr.auxCount())
+	fmt.Fprintf(bw, "        witnessRange.aux[k] <== witnessAux[k];\n")
+	fmt.Fprintf(bw, "    }\n\n")
+	fmt.Fprintf(bw, "    component steps[ITER];\n")
+	fmt.Fprintf(bw, "    for (var i = 0; i < ITER; i++) {\n")
+	fmt.Fprintf(bw, "        steps[i] = SlothStep();\n")
+	fmt.Fprintf(bw, "        for (var j = 0; j < %d; j++) {\n", limbs)
+	fmt.Fprintf(bw, "            if (i == 0) {\n")
+	fmt.Fprintf(bw, "                steps[i].y[j] <== witness[j];\n")
+	fmt.Fprintf(bw, "            } else {\n")
+	fmt.Fprintf(bw, "                steps[i].y[j] <== steps[i - 1].next[j];\n")
+	fmt.Fprintf(bw, "            }\n")
+	fmt.Fprintf(bw, "        }\n")
+	fmt.Fprintf(bw, "        for (var k = 0; k < %d; k++) {\n", st.auxCount())
+	fmt.Fprintf(bw, "            steps[i].aux[k] <== aux[i][k];\n")
+	fmt.Fprintf(bw, "        }\n")
+	fmt.Fprintf(bw, "    }\n")
+	fmt.Fprintf(bw, "    for (var j = 0; j < %d; j++) {\n", limbs)
+	fmt.Fprintf(bw, "        steps[ITER - 1].next[j] === start[j];\n")
+	fmt.Fprintf(bw, "    }\n")
+	fmt.Fprintf(bw, "}\n\n")
+
+	fmt.Fprintf(bw, "component main {public [start, witness]} = SlothVerify(%d);\n", c.iterations)
+	return bw.Flush()
+}
+
+// writeConstraints 把 b 的约束写成 circom 语句，输入信号命名为 input[j]，其余为 aux[k]
+func writeConstraints(w io.Writer, b *builder, input string) {
+	name := func(signal int) string {
+		if signal <= b.inputs {
+			return fmt.Sprintf("%s[%d]", input, signal-1)
+		}
+		return fmt.Sprintf("aux[%d]", signal-b.inputs-1)
+	}
+	for _, c := range b.cons {
+		if c.a == nil {
+			fmt.Fprintf(w, "    %s === %s;\n", formatLC(c.b, name), formatLC(c.c, name))
+			continue
+		}
+		fmt.Fprintf(w, "    (%s) * (%s) === %s;\n", formatLC(c.a, name), formatLC(c.b, name), formatLC(c.c, name))
+	}
+}
+
+// formatLC 把线性组合写成 circom 表达式，系数保留符号，由 circom 在域中约减
+func formatLC(x lc, name func(int) string) string {
+	if len(x) == 0 {
+		return "0"
+	}
+	var sb strings.Builder
+	for i, t := range x {
+		coef := new(big.Int).Set(t.coef)
+		switch {
+		case i == 0 && coef.Sign() < 0:
+			sb.WriteString("-")
+			coef.Neg(coef)
+		case i > 0 && coef.Sign() < 0:
+			sb.WriteString(" - ")
+			coef.Neg(coef)
+		case i > 0:
+			sb.WriteString(" + ")
+		}
+		switch {
+		case t.signal == 0:
+			sb.WriteString(coef.String())
+		case coef.Cmp(big.NewInt(1)) == 0:
+			sb.WriteString(name(t.signal))
+		default:
+			fmt.Fprintf(&sb, "%s*%s", coef, name(t.signal))
+		}
+	}
+	return sb.String()
+}
+
+// auxCount 返回辅助信号的个数
+func (b *builder) auxCount() int {
+	return len(b.values) - b.inputs - 1
+}
+
+// auxValues 以 BN254 中的十进制表示返回辅助信号的取值 (负的进位约减为 r - |c|)
+func (b *builder) auxValues() []string {
+	out := make([]string, 0, b.auxCount())
+	for _, v := range b.values[b.inputs+1:] {
+		out = append(out, new(big.Int).Mod(v, bn254).String())
+	}
+	return out
+}
+
+// Input 是 snarkjs 的输入文件 (input.json)，所有值都是十进制字符串
+type Input struct {
+	Start      []string   `json:"start"`
+	Witness    []string   `json:"witness"`
+	WitnessAux []string   `json:"witnessAux"`
+	Aux        [][]string `json:"aux"`
+}
+
+// NewInput 从 witness 出发逆向迭代 iterations 次，生成 Generate(..., WithIterations(iterations))
+// 输出的电路的输入; 终点不等于 start 时返回 slothgo.ErrReversalMismatch
+// 逐区段证明时 start 与 witness 取区段两端的值
+func NewInput(vdf *slothgo.Sloth, start, witness *big.Int, iterations uint64) (*Input, error) {
+	if err := zk.CheckParams(vdf); err != nil {
+		return nil, err
+	}
+	if iterations == 0 {
+		return nil, errors.New("iterations must be positive")
+	}
+	for _, v := range []*big.Int{start, witness} {
+		if v == nil || v.Sign() < 0 || v.Cmp(vdf.P) >= 0 {
+			return nil, slothgo.ErrWitnessOutOfRange
+		}
+	}
+	in := &Input{
+		Start:      limbStrings(start),
+		Witness:    limbStrings(witness),
+		WitnessAux: newWitnessRange(vdf.P, witness).auxValues(),
+		Aux:        make([][]string, 0, iterations),
+	}
+	y := witness
+	for range iterations {
+		st := newStep(vdf.P, y)
+		in.Aux = append(in.Aux, st.auxValues())
+		y = st.nextValue()
+	}
+	if y.Cmp(start) != 0 {
+		return nil, slothgo.ErrReversalMismatch
+	}
+	return in, nil
+}
+
+// ProofInput 检查 proof 并生成完整证明 (迭代次数为 vdf.Iterations) 的电路输入
+func ProofInput(ctx context.Context, vdf *slothgo.Sloth, input []byte, proof *slothgo.Proof) (*Input, error) {
+	a, err := zk.NewAssignment(ctx, vdf, input, proof)
+	if err != nil {
+		return nil, err
+	}
+	return NewInput(vdf, a.Start, a.Witness, vdf.Iterations)
+}
+
+func limbStrings(v *big.Int) []string {
+	out := make([]string, limbs)
+	for i := range limbs {
+		out[i] = limb(v, i).String()
+	}
+	return out
+}
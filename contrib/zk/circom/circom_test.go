@@ -0,0 +1,231 @@
+package circom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/contrib/zk"
+)
+
+func newTestVDF(t *testing.T, bits int) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(bits)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 20)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// TestStep 检查诚实的取值满足约束，并且 next 与 Sloth 的 τ⁻¹ 相同
+func TestStep(t *testing.T) {
+	for _, bits := range []int{128, 256} {
+		vdf := newTestVDF(t, bits)
+		values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), new(big.Int).Sub(vdf.P, big.NewInt(1))}
+		y := big.NewInt(987654321)
+		for range 20 {
+			values = append(values, y)
+			y = vdf.Tau(y)
+		}
+		for _, v := range values {
+			st := newStep(vdf.P, v)
+			if err := st.check(); err != nil {
+				t.Fatalf("%d bits: step(%v): %v", bits, v, err)
+			}
+			if got, want := st.nextValue(), vdf.TauInverse(v); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: step(%v) = %v, want %v", bits, v, got, want)
+			}
+		}
+	}
+}
+
+// TestStepSoundness 检查改动任何一类辅助信号都会违反约束
+func TestStepSoundness(t *testing.T) {
+	vdf := newTestVDF(t, 256)
+	y := vdf.Tau(big.NewInt(42))
+	st := newStep(vdf.P, y)
+	for i := st.inputs + 1; i < len(st.values); i += 97 {
+		orig := st.values[i]
+		st.values[i] = new(big.Int).Add(orig, big.NewInt(1))
+		if st.check() == nil {
+			t.Errorf("changing signal %d kept all constraints satisfied", i)
+		}
+		st.values[i] = orig
+	}
+
+	// witness 的范围约束必须拒绝 p
+	if newWitnessRange(vdf.P, vdf.P).check() == nil {
+		t.Error("witness p satisfied the range constraints")
+	}
+	if err := newWitnessRange(vdf.P, new(big.Int).Sub(vdf.P, big.NewInt(1))).check(); err != nil {
+		t.Errorf("witness p-1 violated the range constraints: %v", err)
+	}
+}
+
+func TestProofInput(t *testing.T) {
+	ctx := context.Background()
+	vdf := newTestVDF(t, 256)
+	input := []byte("circom")
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	in, err := ProofInput(ctx, vdf, input, proof)
+	if err != nil {
+		t.Fatalf("ProofInput failed: %v", err)
+	}
+	if len(in.Aux) != int(vdf.Iterations) {
+		t.Fatalf("got %d steps, want %d", len(in.Aux), vdf.Iterations)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(&buf, vdf); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := evalTemplate(buf.String(), in); err != nil {
+		t.Fatalf("emitted template rejected the input: %v", err)
+	}
+
+	// 替换一个中间值后，生成的模板必须拒绝
+	in.Aux[3][len(in.Aux[3])/2] = "12345"
+	if evalTemplate(buf.String(), in) == nil {
+		t.Error("emitted template accepted a tampered input")
+	}
+
+	bad := *proof
+	bad.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	if _, err := ProofInput(ctx, vdf, input, &bad); err == nil {
+		t.Error("ProofInput accepted an invalid proof")
+	}
+	if _, err := NewInput(vdf, zk.StartValue(vdf, []byte("other")), proof.Witness, vdf.Iterations); !errors.Is(err, slothgo.ErrReversalMismatch) {
+		t.Errorf("NewInput with a foreign start returned %v", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	vdf := newTestVDF(t, 256)
+	var buf bytes.Buffer
+	if err := Generate(&buf, vdf, WithIterations(7)); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "component main {public [start, witness]} = SlothVerify(7);") {
+		t.Error("main component does not use the configured iterations")
+	}
+	if err := Generate(&buf, vdf, WithIterations(0)); err == nil {
+		t.Error("Generate accepted zero iterations")
+	}
+	other, err := slothgo.New(vdf.P, 10, slothgo.WithHash(slothgo.HashBLAKE3))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := Generate(&buf, other); err == nil {
+		t.Error("Generate accepted unsupported parameters")
+	}
+}
+
+var (
+	templateRe = regexp.MustCompile(`(?s)template (\w+)\(\) \{(.*?)\n\}`)
+	termRe     = regexp.MustCompile(`^(?:(\d+)\*)?(\w+)\[(\d+)\]$`)
+)
+
+// evalTemplate 用 in 中的值逐条计算模板 SlothStep 与 SlothWitnessRange 的约束，
+// 并按 SlothVerify 的连接方式把上一步的 next 作为下一步的 y
+func evalTemplate(src string, in *Input) error {
+	bodies := map[string][]string{}
+	for _, m := range templateRe.FindAllStringSubmatch(src, -1) {
+		for line := range strings.SplitSeq(m[2], "\n") {
+			if line = strings.TrimSpace(line); strings.Contains(line, "===") || strings.Contains(line, "<==") {
+				bodies[m[1]] = append(bodies[m[1]], strings.TrimSuffix(line, ";"))
+			}
+		}
+	}
+	parse := func(values []string) []*big.Int {
+		out := make([]*big.Int, len(values))
+		for i, v := range values {
+			out[i], _ = new(big.Int).SetString(v, 10)
+		}
+		return out
+	}
+
+	if _, err := run(bodies["SlothWitnessRange"], map[string][]*big.Int{"w": parse(in.Witness), "aux": parse(in.WitnessAux)}); err != nil {
+		return fmt.Errorf("witness range: %w", err)
+	}
+	y := parse(in.Witness)
+	for i, aux := range in.Aux {
+		next, err := run(bodies["SlothStep"], map[string][]*big.Int{"y": y, "aux": parse(aux)})
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		y = next
+	}
+	for j, v := range parse(in.Start) {
+		if y[j].Cmp(v) != 0 {
+			return fmt.Errorf("final limb %d is %v, want %v", j, y[j], v)
+		}
+	}
+	return nil
+}
+
+// run 执行模板中的约束语句，返回 next 的取值
+func run(lines []string, signals map[string][]*big.Int) ([]*big.Int, error) {
+	eval := func(expr string) *big.Int {
+		expr = strings.ReplaceAll(strings.ReplaceAll(expr, " - ", " + -"), "(", "")
+		expr = strings.ReplaceAll(expr, ")", "")
+		sum := new(big.Int)
+		for t := range strings.SplitSeq(expr, " + ") {
+			sign := int64(1)
+			if strings.HasPrefix(t, "-") {
+				sign, t = -1, t[1:]
+			}
+			v, ok := new(big.Int).SetString(t, 10)
+			if !ok {
+				m := termRe.FindStringSubmatch(t)
+				if m == nil {
+					panic("cannot parse term " + t)
+				}
+				coef := big.NewInt(1)
+				if m[1] != "" {
+					coef.SetString(m[1], 10)
+				}
+				var idx int
+				fmt.Sscan(m[3], &idx)
+				v = new(big.Int).Mul(coef, signals[m[2]][idx])
+			}
+			sum.Add(sum, v.Mul(v, big.NewInt(sign)))
+		}
+		return sum.Mod(sum, bn254)
+	}
+
+	var next []*big.Int
+	for i, line := range lines {
+		if l, r, ok := strings.Cut(line, " <== "); ok {
+			next = append(next, eval(r))
+			if !strings.HasPrefix(l, "next[") {
+				return nil, fmt.Errorf("unexpected assignment %q", line)
+			}
+			continue
+		}
+		l, r, _ := strings.Cut(line, " === ")
+		var left *big.Int
+		if a, b, ok := strings.Cut(l, ") * ("); ok {
+			left = new(big.Int).Mul(eval(a), eval(b))
+			left.Mod(left, bn254)
+		} else {
+			left = eval(l)
+		}
+		if left.Cmp(eval(r)) != 0 {
+			return nil, fmt.Errorf("constraint %d (%.60s...) is not satisfied", i, line)
+		}
+	}
+	return next, nil
+}
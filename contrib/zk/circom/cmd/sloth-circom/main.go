@@ -0,0 +1,96 @@
+// sloth-circom 为一组 Sloth 参数生成 circom 模板，可选地同时计算一个证明并写出 snarkjs 的输入文件:
+//
+//	sloth-circom -params sloth-256-t30s -iters 64 -out sloth.circom \
+//	    -input "hello" -input-out input.json
+//
+// 参数来自标准参数集 (-params)，或者素数文件 (-prime-file，十六进制或 sloth params gen 的输出) 与 -iters。
+// 约束数与迭代次数成正比，-iters 应取证明方能够承受的值
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/contrib/zk/circom"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+func main() {
+	paramSet := flag.String("params", "", "标准参数集名称, 例如 sloth-256-t30s")
+	primeFile := flag.String("prime-file", "", "素数文件, 与 -iters 一起使用")
+	iterations := flag.Uint64("iters", 0, "迭代次数, 使用 -params 时覆盖参数集的值")
+	out := flag.String("out", "-", `模板文件, "-" 表示标准输出`)
+	input := flag.String("input", "", "计算证明的输入")
+	inputOut := flag.String("input-out", "", "snarkjs 输入文件, 为空时不生成")
+	flag.Parse()
+
+	vdf, err := newVDF(*paramSet, *primeFile, *iterations)
+	if err != nil {
+		log.Fatalf("加载参数失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := circom.Generate(&buf, vdf); err != nil {
+		log.Fatalf("生成模板失败: %v", err)
+	}
+	if err := write(*out, buf.Bytes()); err != nil {
+		log.Fatalf("写入模板失败: %v", err)
+	}
+	if *inputOut == "" {
+		return
+	}
+
+	proof, err := vdf.ComputeProof([]byte(*input))
+	if err != nil {
+		log.Fatalf("计算证明失败: %v", err)
+	}
+	in, err := circom.ProofInput(context.Background(), vdf, []byte(*input), proof)
+	if err != nil {
+		log.Fatalf("生成输入失败: %v", err)
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		log.Fatalf("编码输入失败: %v", err)
+	}
+	if err := write(*inputOut, data); err != nil {
+		log.Fatalf("写入输入失败: %v", err)
+	}
+}
+
+// newVDF 按命令行选项创建 Sloth 实例
+func newVDF(paramSet, primeFile string, iterations uint64) (*slothgo.Sloth, error) {
+	switch {
+	case paramSet != "" && primeFile != "":
+		return nil, errors.New("-params and -prime-file cannot be used together")
+	case paramSet != "":
+		vdf, err := slothgo.NewStandard(paramSet)
+		if err != nil || iterations == 0 {
+			return vdf, err
+		}
+		return vdf.WithIterations(iterations)
+	case primeFile != "":
+		if iterations == 0 {
+			return nil, errors.New("-iters is required with -prime-file")
+		}
+		p, err := config.ReadPrime(primeFile)
+		if err != nil {
+			return nil, err
+		}
+		return slothgo.New(p, iterations)
+	}
+	return nil, errors.New("either -params or -prime-file is required")
+}
+
+func write(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,148 @@
+package circom
+
+import (
+	"math/big"
+)
+
+// 进位的比特数: 乘法关系中系数不超过 2^131，进位不超过 2^68; 线性关系的进位绝对值不超过 3
+const (
+	mulCarryBits    = 72
+	linearCarryBits = 4
+)
+
+// step 是一次逆向迭代 y -> next 的约束系统，对应 zk.Step:
+//
+//	y² = q·p + s，0 <= s < p                (s + cs = p - 1，cs >= 0)
+//	t = s + odd·(p - 2s) - odd·[s = 0]·p     (odd 为 y 的最低位)
+//	next = t + 2·[t 为奇数] + [t = 0] - 1    (邻居交换 σ)
+//
+// y 的 limb 在这里检查范围; next 的 limb 由下一步 (作为 y) 或与公开的 w₀ 比较时检查
+type step struct {
+	*builder
+	next [limbs]int
+}
+
+// newStep 以 y 的取值构建一步，y 必须在 [0, p) 内才能满足约束
+func newStep(p, y *big.Int) *step {
+	var in []*big.Int
+	for i := range limbs {
+		in = append(in, limb(y, i))
+	}
+	b := newBuilder(in)
+	var ySig [limbs]int
+	var odd int
+	for i := range limbs {
+		ySig[i] = 1 + i
+		bits := b.bits(sig(ySig[i]), limbBits)
+		if i == 0 {
+			odd = bits[0]
+		}
+	}
+
+	sq := new(big.Int).Mul(y, y)
+	q, s := new(big.Int).DivMod(sq, p, new(big.Int))
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	qSig, _ := b.number(q)
+	sSig, _ := b.number(s)
+	csSig, _ := b.number(new(big.Int).Sub(pm1, s))
+
+	// y² - q·p - s = 0
+	coefs := make([]lc, 2*limbs-1)
+	for a := range limbs {
+		for c := a; c < limbs; c++ {
+			factor := int64(2)
+			if a == c {
+				factor = 1
+			}
+			coefs[a+c] = coefs[a+c].plus(factor, sig(b.mul(ySig[a], ySig[c])))
+		}
+		for c := range limbs {
+			coefs[a+c] = coefs[a+c].plus(-1, sig(qSig[a]).scaled(limb(p, c)))
+		}
+		coefs[a] = coefs[a].plus(-1, sig(sSig[a]))
+	}
+	b.polyZero(coefs, mulCarryBits)
+
+	// s + cs - (p - 1) = 0
+	coefs = make([]lc, limbs)
+	for j := range limbs {
+		coefs[j] = sig(sSig[j]).plus(1, sig(csSig[j])).plus(-1, constant(limb(pm1, j)))
+	}
+	b.polyZero(coefs, linearCarryBits)
+
+	// t - s - odd·p + 2·odd·s + odd·[s = 0]·p = 0
+	sSum := lc{}
+	for j := range limbs {
+		sSum = sSum.plus(1, sig(sSig[j]))
+	}
+	oz := b.mul(odd, b.isZero(sSum))
+	t := new(big.Int).Set(s)
+	if y.Bit(0) == 1 && s.Sign() != 0 {
+		t.Sub(p, s)
+	}
+	tSig, tLow := b.number(t)
+	coefs = make([]lc, limbs)
+	for j := range limbs {
+		os := b.mul(odd, sSig[j])
+		coefs[j] = sig(tSig[j]).plus(-1, sig(sSig[j])).
+			plus(-1, sig(odd).scaled(limb(p, j))).
+			plus(2, sig(os)).
+			plus(1, sig(oz).scaled(limb(p, j)))
+	}
+	b.polyZero(coefs, linearCarryBits)
+
+	// next - t - 2·[t 为奇数] - [t = 0] + 1 = 0
+	tSum := lc{}
+	for j := range limbs {
+		tSum = tSum.plus(1, sig(tSig[j]))
+	}
+	tz := b.isZero(tSum)
+	next := new(big.Int).Set(t)
+	switch {
+	case t.Bit(0) == 1:
+		next.Add(next, big.NewInt(1))
+	case t.Sign() != 0:
+		next.Sub(next, big.NewInt(1))
+	}
+	st := &step{builder: b}
+	coefs = make([]lc, limbs)
+	for j := range limbs {
+		st.next[j] = b.alloc(limb(next, j))
+		coefs[j] = sig(st.next[j]).plus(-1, sig(tSig[j]))
+	}
+	coefs[0] = coefs[0].plus(-2, sig(tLow[0])).plus(-1, sig(tz)).plus(1, constant(big.NewInt(1)))
+	b.polyZero(coefs, linearCarryBits)
+	return st
+}
+
+// nextValue 返回 next 的取值
+func (st *step) nextValue() *big.Int {
+	v := new(big.Int)
+	for j := limbs - 1; j >= 0; j-- {
+		v.Lsh(v, limbBits).Add(v, st.values[st.next[j]])
+	}
+	return v
+}
+
+// newWitnessRange 构建检查 witness < p 的约束: w + cw = p - 1，cw 的 limb 非负
+// witness 的 limb 本身由第一步检查范围
+func newWitnessRange(p, w *big.Int) *builder {
+	var in []*big.Int
+	for i := range limbs {
+		in = append(in, limb(w, i))
+	}
+	b := newBuilder(in)
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	cw := new(big.Int).Sub(pm1, w)
+	if cw.Sign() < 0 {
+		// 超出范围的 witness 无法满足约束，这里只需要让构建继续
+		cw.SetInt64(0)
+	}
+	cwSig, _ := b.number(cw)
+	coefs := make([]lc, limbs)
+	for j := range limbs {
+		coefs[j] = sig(1+j).plus(1, sig(cwSig[j])).plus(-1, constant(limb(pm1, j)))
+	}
+	b.polyZero(coefs, linearCarryBits)
+	return b
+}
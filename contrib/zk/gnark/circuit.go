@@ -0,0 +1,78 @@
+package gnark
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/math/emulated"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/contrib/zk"
+)
+
+// Sloth256 是 sloth-256-t30s 的模数 p = 2^256 - 189
+type Sloth256 struct{}
+
+func (Sloth256) NbLimbs() uint     { return 4 }
+func (Sloth256) BitsPerLimb() uint { return 64 }
+func (Sloth256) IsPrime() bool     { return true }
+func (Sloth256) Modulus() *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), 256)
+	return p.Sub(p, big.NewInt(189))
+}
+
+// Circuit 是 Sloth 验证关系，Iterations 是编译期常量
+type Circuit[T emulated.FieldParams] struct {
+	Start   emulated.Element[T] `gnark:",public"` // w₀
+	Witness emulated.Element[T] `gnark:",public"`
+
+	Iterations int `gnark:"-"`
+}
+
+// Define 实现 frontend.Circuit，每次迭代与 zk.Step 逐步对应
+func (c *Circuit[T]) Define(api frontend.API) error {
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return err
+	}
+	one := f.One()
+	y := &c.Witness
+	for range c.Iterations {
+		// 奇偶性必须取自规范表示 (小于 p)，否则证明方可以用 y + p 翻转它
+		odd := f.ToBitsCanonical(y)[0]
+		s := f.Mul(y, y)
+		s = f.Select(odd, f.Neg(s), s)
+
+		sBits := f.ToBitsCanonical(s)
+		y = f.Select(sBits[0], f.Add(s, one), f.Select(f.IsZero(s), s, f.Sub(s, one)))
+	}
+	f.AssertIsEqual(y, &c.Start)
+	return nil
+}
+
+// Compile 编译 iterations 次迭代的电路，得到 BN254 上的 R1CS，供 groth16.Setup 与 groth16.Prove 使用
+func Compile[T emulated.FieldParams](iterations int) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &Circuit[T]{Iterations: iterations})
+}
+
+// Assign 检查 proof 并生成电路的赋值，T 的模数必须等于 vdf.P
+func Assign[T emulated.FieldParams](ctx context.Context, vdf *slothgo.Sloth, input []byte, proof *slothgo.Proof) (*Circuit[T], error) {
+	var params T
+	if params.Modulus().Cmp(vdf.P) != 0 {
+		return nil, errors.New("field parameters do not match the Sloth modulus")
+	}
+	a, err := zk.NewAssignment(ctx, vdf, input, proof)
+	if err != nil {
+		return nil, err
+	}
+	return &Circuit[T]{
+		Start:      emulated.ValueOf[T](a.Start),
+		Witness:    emulated.ValueOf[T](a.Witness),
+		Iterations: int(vdf.Iterations),
+	}, nil
+}
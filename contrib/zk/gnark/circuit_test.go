@@ -0,0 +1,60 @@
+package gnark
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// TestCircuit 检查正确的赋值满足电路，篡改 witness 后不满足
+func TestCircuit(t *testing.T) {
+	const iterations = 3
+	vdf, err := slothgo.New(Sloth256{}.Modulus(), iterations)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	input := []byte("gnark circuit test")
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	assignment, err := Assign[Sloth256](context.Background(), vdf, input, proof)
+	if err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	circuit := &Circuit[Sloth256]{Iterations: iterations}
+	if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("valid assignment does not satisfy the circuit: %v", err)
+	}
+
+	forged := *assignment
+	w := new(big.Int).Add(proof.Witness, big.NewInt(1))
+	forged.Witness = emulated.ValueOf[Sloth256](w)
+	if err := test.IsSolved(circuit, &forged, ecc.BN254.ScalarField()); err == nil {
+		t.Error("forged witness satisfies the circuit")
+	}
+}
+
+func TestAssignRejectsOtherModulus(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 3)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := vdf.ComputeProof([]byte("x"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if _, err := Assign[Sloth256](context.Background(), vdf, []byte("x"), proof); err == nil {
+		t.Error("Assign accepted a VDF with a different modulus")
+	}
+}
@@ -0,0 +1,15 @@
+// Package gnark 用 gnark 的约束系统实现 zk 包中的 Sloth 验证关系，生成的 SNARK 证明
+// "从公开的 witness 出发逆向迭代 l 次得到公开的 w₀"，链上只需要一次配对检查与两次 sha256。
+//
+// 本包是单独的模块，gnark 的依赖不进入主模块; gnark 与 gnark-crypto 的版本固定在 go.mod 中:
+//
+//	go get github.com/alan22333/sloth_go/contrib/zk/gnark
+//
+// Sloth 的模数与 BN254 等曲线的标量域不同，域元素用 gnark 的非原生 (emulated) 算术表示，
+// 每次迭代需要一次非原生乘法和两次规范化的位分解，约束数与迭代次数成正比。完整的
+// sloth-256-t30s (40 万次迭代) 远超常见证明方的能力，实际使用时应配合检查点逐区段证明
+// (Circuit.Iterations 取检查点间隔) 或者选用较小的迭代次数。
+//
+// 模数是电路的编译期常量，需要实现 emulated.FieldParams 的类型; Sloth256 对应标准参数集
+// sloth-256-t30s 的 p = 2^256 - 189，其他素数可以仿照它定义
+package gnark
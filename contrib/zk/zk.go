@@ -0,0 +1,112 @@
+// Package zk 描述 Sloth 验证关系的算术电路形式，并在 Go 中生成电路的公开输入与见证，
+// 供 SNARK 电路 (gnark 与 circom 子包) 使用，使链上验证从 l 次平方缩减为一次配对检查。
+//
+// 电路只证明 "从 witness 出发逆向迭代 l 次得到 w₀"，两端都是公开输入:
+//
+//	y₀ = witness
+//	yᵢ = σ(±yᵢ₋₁² mod p)   (yᵢ₋₁ 为奇数时取负)，i = 1..l
+//	y_l = w₀
+//
+// 其中 σ 为邻居交换 (0 不动，奇数加一，非零偶数减一)。在模 p 的域中 p - s 就是 -s，并且 -0 = 0，
+// 因此取负不需要单独处理 0。w₀ = SHA-256(input) mod p 与输出哈希 SHA-256(witness) 不放进电路，
+// 由验证方在电路外计算 (链上是两次 sha256 预编译调用)，Assignment 在生成时检查它们。
+// 与 embedded、contrib/evm 相同，只支持默认选项 (sqrt 置换、SHA-256、取模输入映射)
+package zk
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// Assignment 是电路的公开输入
+type Assignment struct {
+	Start   *big.Int // w₀ = SHA-256(input) mod p
+	Witness *big.Int // 证明中的 witness
+}
+
+// NewAssignment 检查 proof 是 input 的正确结果，并返回电路的公开输入
+// 电路的证明方在此之后才生成 SNARK，无效的证明在这里就会被拒绝
+func NewAssignment(ctx context.Context, vdf *slothgo.Sloth, input []byte, proof *slothgo.Proof) (*Assignment, error) {
+	if err := CheckParams(vdf); err != nil {
+		return nil, err
+	}
+	if ok, err := vdf.VerifyProofCtx(ctx, input, proof); !ok {
+		return nil, fmt.Errorf("proof does not verify: %w", err)
+	}
+	return &Assignment{Start: StartValue(vdf, input), Witness: new(big.Int).Set(proof.Witness)}, nil
+}
+
+// CheckParams 检查 vdf 的参数是否可以用这里的关系表示
+func CheckParams(vdf *slothgo.Sloth) error {
+	if vdf == nil {
+		return errors.New("vdf cannot be nil")
+	}
+	params := vdf.Params()
+	switch {
+	case params.Permutation != "sqrt":
+		return fmt.Errorf("permutation %q is not supported, only sqrt", params.Permutation)
+	case params.Hash != slothgo.HashSHA256:
+		return fmt.Errorf("hash %s is not supported, only SHA-256", params.Hash)
+	case len(params.DomainTag) > 0 || params.BindParams || params.HashToField:
+		return errors.New("domain tags, parameter binding and hash-to-field are not supported")
+	}
+	return nil
+}
+
+// StartValue 返回 w₀ = SHA-256(input) mod p，即 Sloth 计算的起点
+func StartValue(vdf *slothgo.Sloth, input []byte) *big.Int {
+	digest := sha256.Sum256(input)
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest[:]), vdf.P)
+}
+
+// CheckOutput 检查 hash 是 witness 的输出哈希，即电路外由验证方完成的另一半检查
+func CheckOutput(vdf *slothgo.Sloth, hash []byte, witness *big.Int) error {
+	if witness == nil || witness.Sign() < 0 || witness.Cmp(vdf.P) >= 0 {
+		return slothgo.ErrWitnessOutOfRange
+	}
+	digest := sha256.Sum256(vdf.EncodeWitness(witness))
+	if subtle.ConstantTimeCompare(digest[:], hash) != 1 {
+		return slothgo.ErrHashMismatch
+	}
+	return nil
+}
+
+// Step 按电路中的写法计算一次逆向迭代: s = y² mod p，y 为奇数时取 -s，再做邻居交换
+func Step(p, y *big.Int) *big.Int {
+	s := new(big.Int).Mul(y, y)
+	s.Mod(s, p)
+	if y.Bit(0) == 1 {
+		s.Neg(s).Mod(s, p)
+	}
+	switch {
+	case s.Bit(0) == 1:
+		s.Add(s, big.NewInt(1))
+	case s.Sign() != 0:
+		s.Sub(s, big.NewInt(1))
+	}
+	return s
+}
+
+// Check 按电路的关系逐步检查 a，满足时返回 nil，用于在生成 SNARK 之前确认见证可以满足约束
+func Check(vdf *slothgo.Sloth, a *Assignment) error {
+	if a == nil || a.Start == nil || a.Witness == nil {
+		return errors.New("assignment is incomplete")
+	}
+	if a.Witness.Sign() < 0 || a.Witness.Cmp(vdf.P) >= 0 {
+		return slothgo.ErrWitnessOutOfRange
+	}
+	y := a.Witness
+	for range vdf.Iterations {
+		y = Step(vdf.P, y)
+	}
+	if y.Cmp(a.Start) != 0 {
+		return slothgo.ErrReversalMismatch
+	}
+	return nil
+}
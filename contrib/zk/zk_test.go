@@ -0,0 +1,95 @@
+package zk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// TestStep 检查电路写法的一次迭代与 Sloth 的 τ⁻¹ 相同，包括 0、1 与 p-1 等边界值
+func TestStep(t *testing.T) {
+	vdf := newTestVDF(t)
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3), new(big.Int).Sub(vdf.P, big.NewInt(1))}
+	y := big.NewInt(987654321)
+	for range 100 {
+		values = append(values, y)
+		y = vdf.Tau(y)
+	}
+	for _, v := range values {
+		if got, want := Step(vdf.P, v), vdf.TauInverse(v); got.Cmp(want) != 0 {
+			t.Fatalf("Step(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	ctx := context.Background()
+	vdf := newTestVDF(t)
+	input := []byte("zk")
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	a, err := NewAssignment(ctx, vdf, input, proof)
+	if err != nil {
+		t.Fatalf("NewAssignment failed: %v", err)
+	}
+	if err := Check(vdf, a); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if err := CheckOutput(vdf, proof.Hash, a.Witness); err != nil {
+		t.Errorf("CheckOutput failed: %v", err)
+	}
+
+	other := &Assignment{Start: StartValue(vdf, []byte("other")), Witness: a.Witness}
+	if err := Check(vdf, other); !errors.Is(err, slothgo.ErrReversalMismatch) {
+		t.Errorf("Check with a foreign start returned %v", err)
+	}
+	if err := CheckOutput(vdf, proof.Hash, new(big.Int).Add(a.Witness, big.NewInt(1))); !errors.Is(err, slothgo.ErrHashMismatch) {
+		t.Errorf("CheckOutput with a changed witness returned %v", err)
+	}
+	if err := Check(vdf, &Assignment{Start: a.Start, Witness: vdf.P}); !errors.Is(err, slothgo.ErrWitnessOutOfRange) {
+		t.Errorf("Check with witness p returned %v", err)
+	}
+
+	bad := *proof
+	bad.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	if _, err := NewAssignment(ctx, vdf, input, &bad); err == nil {
+		t.Error("NewAssignment accepted an invalid proof")
+	}
+}
+
+func TestCheckParams(t *testing.T) {
+	vdf := newTestVDF(t)
+	if err := CheckParams(vdf); err != nil {
+		t.Errorf("CheckParams rejected default parameters: %v", err)
+	}
+	for name, opt := range map[string]slothgo.Option{
+		"domain tag": slothgo.WithDomainTag([]byte("app")),
+		"hash":       slothgo.WithHash(slothgo.HashBLAKE3),
+	} {
+		other, err := slothgo.New(vdf.P, 10, opt)
+		if err != nil {
+			t.Fatalf("%s: New failed: %v", name, err)
+		}
+		if err := CheckParams(other); err == nil {
+			t.Errorf("%s: unsupported parameters were accepted", name)
+		}
+	}
+}
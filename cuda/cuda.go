@@ -0,0 +1,120 @@
+// Package cuda 是在 NVIDIA GPU 上执行批量迭代的 slothgo.Accelerator，名称为 "cuda"
+// 它依赖 CUDA 驱动与 NVRTC，只在启用 cgo 并指定 cuda 构建标签时编译:
+//
+//	CGO_CFLAGS=-I/usr/local/cuda/include CGO_LDFLAGS=-L/usr/local/cuda/lib64 go build -tags cuda
+//
+// 内核 (kernel.cu) 嵌入在二进制中，New 打开设备时按设备的计算能力编译，不需要 nvcc。
+// 导入本包时如果第 0 块 GPU 可用，init 会把它注册为 "cuda"，之后可以用 slothgo.LookupAccelerator 选择;
+// 未启用构建标签时 Available 为 false，New 返回 ErrUnavailable。
+//
+// 内核支持不超过 256 位、p ≡ 3 (mod 4) 的模数与 "sqrt"、"sqrt-lw15" 置换 (包括全部 256 位的标准参数集)，
+// 其他参数由 Sloth 退回 CPU 路径
+package cuda
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// Name 是加速器的注册名
+const Name = "cuda"
+
+// ErrUnavailable 表示当前构建不包含 CUDA 后端
+var ErrUnavailable = errors.New("cuda: built without the cuda build tag")
+
+// limbs 是内核中每个值占用的 64 位 limb 数
+const limbs = 4
+
+// supports 报告内核是否支持模数 p 与置换 permutation
+func supports(p *big.Int, permutation string) bool {
+	if p == nil || p.Sign() <= 0 || p.BitLen() > 64*limbs || p.Bit(0) == 0 || p.Bit(1) == 0 {
+		return false
+	}
+	return permutation == "sqrt" || permutation == "sqrt-lw15"
+}
+
+// params 是内核使用的模数常量，与 kernel.cu 中的 slothgo_params 对应
+type params struct {
+	p, r2, one, e [limbs]uint64
+	pinv          uint64
+	ebits         int
+	lw15          bool
+}
+
+// newParams 计算 p 的 Montgomery 常量，p 必须满足 supports
+func newParams(p *big.Int, permutation string) *params {
+	r := new(big.Int).Lsh(big.NewInt(1), 64*limbs)
+	e := new(big.Int).Add(p, big.NewInt(1))
+	e.Rsh(e, 2)
+	prm := &params{
+		p:     toLimbs(p),
+		r2:    toLimbs(new(big.Int).Exp(r, big.NewInt(2), p)),
+		one:   toLimbs(new(big.Int).Mod(r, p)),
+		e:     toLimbs(e),
+		ebits: e.BitLen(),
+		lw15:  permutation == "sqrt-lw15",
+	}
+	// 牛顿迭代求 p⁻¹ mod 2^64，每次迭代正确的位数翻倍
+	inv := prm.p[0]
+	for range 5 {
+		inv *= 2 - prm.p[0]*inv
+	}
+	prm.pinv = -inv
+	return prm
+}
+
+// toLimbs 把 x (0 <= x < 2^256) 转换为小端 limb
+func toLimbs(x *big.Int) [limbs]uint64 {
+	var buf [8 * limbs]byte
+	x.FillBytes(buf[:])
+	var l [limbs]uint64
+	for i := range l {
+		l[i] = binary.BigEndian.Uint64(buf[8*(limbs-1-i):])
+	}
+	return l
+}
+
+// setLimbs 把小端 limb 写回 x
+func setLimbs(x *big.Int, l []uint64) {
+	var buf [8 * limbs]byte
+	for i := range limbs {
+		binary.BigEndian.PutUint64(buf[8*(limbs-1-i):], l[i])
+	}
+	x.SetBytes(buf[:])
+}
+
+// encode 检查任务并把 job.Values 转换为内核的输入
+func encode(job *slothgo.AcceleratorJob) ([]uint64, error) {
+	if job == nil || job.P == nil || job.Perm == nil {
+		return nil, errors.New("accelerator job is incomplete")
+	}
+	if !supports(job.P, job.Perm.Name()) {
+		return nil, fmt.Errorf("cuda: unsupported modulus or permutation %q", job.Perm.Name())
+	}
+	if job.Iterations == 0 {
+		return nil, errors.New("iterations must be positive")
+	}
+	if job.Iterations > slothgo.MaxIterations {
+		return nil, fmt.Errorf("iterations %d exceed the maximum %d", job.Iterations, slothgo.MaxIterations)
+	}
+	data := make([]uint64, 0, limbs*len(job.Values))
+	for _, v := range job.Values {
+		if v.Sign() < 0 || v.Cmp(job.P) >= 0 {
+			return nil, errors.New("cuda: value is not reduced modulo p")
+		}
+		l := toLimbs(v)
+		data = append(data, l[:]...)
+	}
+	return data, nil
+}
+
+// decode 把内核的输出写回 job.Values
+func decode(job *slothgo.AcceleratorJob, data []uint64) {
+	for i, v := range job.Values {
+		setLimbs(v, data[limbs*i:])
+	}
+}
@@ -0,0 +1,136 @@
+package cuda
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// testPrimes 返回满足 p ≡ 3 (mod 4) 的不同位数的素数，包括 256 位的标准参数集
+func testPrimes(t *testing.T) []*big.Int {
+	t.Helper()
+	primes := []*big.Int{slothgo.ParamSets()[0].Prime()}
+	for _, bits := range []int{20, 64, 65, 130, 255} {
+		p, err := slothgo.GenerateSlothPrime(bits)
+		if err != nil {
+			t.Fatalf("GenerateSlothPrime failed: %v", err)
+		}
+		primes = append(primes, p)
+	}
+	return primes
+}
+
+func TestSupports(t *testing.T) {
+	p := slothgo.ParamSets()[0].Prime()
+	if !supports(p, "sqrt") || !supports(p, "sqrt-lw15") {
+		t.Error("standard 256-bit parameters are not supported")
+	}
+	if supports(p, "cbrt") {
+		t.Error("cbrt is supported")
+	}
+	for _, p := range []*big.Int{slothgo.ParamSets()[1].Prime(), big.NewInt(13), big.NewInt(22), nil} {
+		if supports(p, "sqrt") {
+			t.Errorf("modulus %v is supported", p)
+		}
+	}
+}
+
+// TestParams 检查 Montgomery 常量与 limb 转换
+func TestParams(t *testing.T) {
+	r := new(big.Int).Lsh(big.NewInt(1), 64*limbs)
+	for _, p := range testPrimes(t) {
+		prm := newParams(p, "sqrt")
+		if prm.p[0]*prm.pinv != ^uint64(0) {
+			t.Errorf("p = %v: pinv is not -p⁻¹ mod 2^64", p)
+		}
+		got := new(big.Int)
+		setLimbs(got, prm.r2[:])
+		if want := new(big.Int).Mod(new(big.Int).Mul(r, r), p); got.Cmp(want) != 0 {
+			t.Errorf("p = %v: R² = %v, want %v", p, got, want)
+		}
+		x, _ := rand.Int(rand.Reader, p)
+		l := toLimbs(x)
+		if setLimbs(got, l[:]); got.Cmp(x) != 0 {
+			t.Errorf("limb round trip of %v gave %v", x, got)
+		}
+	}
+}
+
+// TestAccelerator 把设备上的结果与 CPUAccelerator 比较; 未启用构建标签或没有可用的设备时跳过
+func TestAccelerator(t *testing.T) {
+	a, err := New(0)
+	if !Available {
+		if !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("New without the cuda build tag returned %v", err)
+		}
+		t.Skip("built without the cuda build tag")
+	}
+	if err != nil {
+		t.Skipf("no usable CUDA device: %v", err)
+	}
+	if a.Name() != Name {
+		t.Errorf("Name() = %q", a.Name())
+	}
+
+	ctx := context.Background()
+	cpu := &slothgo.CPUAccelerator{}
+	for _, p := range testPrimes(t) {
+		for _, name := range []string{"sqrt", "sqrt-lw15"} {
+			perm, err := slothgo.NewPermutation(name, p)
+			if err != nil {
+				t.Fatalf("NewPermutation failed: %v", err)
+			}
+			values := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(p, big.NewInt(1))}
+			for range 200 {
+				v, _ := rand.Int(rand.Reader, p)
+				values = append(values, v)
+			}
+			dev := &slothgo.AcceleratorJob{P: p, Perm: perm, Iterations: 7, Values: clone(values)}
+			ref := &slothgo.AcceleratorJob{P: p, Perm: perm, Iterations: 7, Values: clone(values)}
+			for _, step := range []struct {
+				name     string
+				dev, ref func(context.Context, *slothgo.AcceleratorJob) error
+			}{{"Forward", a.Forward, cpu.Forward}, {"Inverse", a.Inverse, cpu.Inverse}} {
+				if err := step.dev(ctx, dev); err != nil {
+					t.Fatalf("%s failed: %v", step.name, err)
+				}
+				step.ref(ctx, ref)
+				for i := range values {
+					if dev.Values[i].Cmp(ref.Values[i]) != 0 {
+						t.Fatalf("p = %v, %s, %s of %v: got %v, want %v", p, name, step.name, values[i], dev.Values[i], ref.Values[i])
+					}
+				}
+			}
+			for i := range values {
+				if dev.Values[i].Cmp(values[i]) != 0 {
+					t.Fatalf("p = %v, %s: Inverse did not undo Forward for %v", p, name, values[i])
+				}
+			}
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	p := slothgo.ParamSets()[0].Prime()
+	perm, _ := slothgo.NewPermutation("sqrt", p)
+	job := &slothgo.AcceleratorJob{P: p, Perm: perm, Iterations: 1, Values: []*big.Int{big.NewInt(3)}}
+	if err := a.Forward(canceled, job); !errors.Is(err, context.Canceled) {
+		t.Errorf("Forward with a canceled context returned %v", err)
+	}
+	job.Values[0] = new(big.Int).Set(p)
+	if err := a.Forward(ctx, job); err == nil {
+		t.Error("Forward accepted a value outside the field")
+	}
+}
+
+func clone(values []*big.Int) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i] = new(big.Int).Set(v)
+	}
+	return out
+}
@@ -0,0 +1,260 @@
+//go:build cgo && cuda
+
+package cuda
+
+/*
+#cgo LDFLAGS: -lcuda -lnvrtc
+#include <stdio.h>
+#include <stdlib.h>
+#include <cuda.h>
+#include <nvrtc.h>
+
+// slothgo_params 与 kernel.cu 中的同名结构体布局相同
+typedef struct {
+	unsigned long long p[4];
+	unsigned long long r2[4];
+	unsigned long long one[4];
+	unsigned long long e[4];
+	unsigned long long pinv;
+	int ebits;
+	int lw15;
+} slothgo_params;
+
+typedef struct {
+	CUcontext ctx;
+	CUmodule module;
+	CUfunction forward;
+	CUfunction inverse;
+} slothgo_cuda;
+
+// 驱动 API 的上下文绑定在线程上，而 goroutine 会在线程之间迁移，因此每个调用都先设置当前上下文
+
+// slothgo_cuda_open 打开第 ordinal 块设备并编译 src; NVRTC 失败时返回 CUDA_ERROR_INVALID_SOURCE，
+// 编译日志写入 log
+static CUresult slothgo_cuda_open(int ordinal, const char *src, slothgo_cuda *d, char *log, size_t logsize) {
+	CUdevice dev;
+	int major, minor;
+	CUresult err;
+	if ((err = cuInit(0)) != CUDA_SUCCESS ||
+		(err = cuDeviceGet(&dev, ordinal)) != CUDA_SUCCESS ||
+		(err = cuDeviceGetAttribute(&major, CU_DEVICE_ATTRIBUTE_COMPUTE_CAPABILITY_MAJOR, dev)) != CUDA_SUCCESS ||
+		(err = cuDeviceGetAttribute(&minor, CU_DEVICE_ATTRIBUTE_COMPUTE_CAPABILITY_MINOR, dev)) != CUDA_SUCCESS ||
+		(err = cuDevicePrimaryCtxRetain(&d->ctx, dev)) != CUDA_SUCCESS) {
+		return err;
+	}
+
+	char arch[64];
+	snprintf(arch, sizeof arch, "--gpu-architecture=compute_%d%d", major, minor);
+	const char *opts[] = {arch};
+	nvrtcProgram prog;
+	if (nvrtcCreateProgram(&prog, src, "kernel.cu", 0, NULL, NULL) != NVRTC_SUCCESS) {
+		cuDevicePrimaryCtxRelease(dev);
+		return CUDA_ERROR_INVALID_SOURCE;
+	}
+	if (nvrtcCompileProgram(prog, 1, opts) != NVRTC_SUCCESS) {
+		size_t n = 0;
+		nvrtcGetProgramLogSize(prog, &n);
+		if (n > 0 && n <= logsize) {
+			nvrtcGetProgramLog(prog, log);
+		}
+		nvrtcDestroyProgram(&prog);
+		cuDevicePrimaryCtxRelease(dev);
+		return CUDA_ERROR_INVALID_SOURCE;
+	}
+	size_t size;
+	nvrtcGetPTXSize(prog, &size);
+	char *ptx = malloc(size);
+	nvrtcGetPTX(prog, ptx);
+	nvrtcDestroyProgram(&prog);
+
+	if ((err = cuCtxSetCurrent(d->ctx)) == CUDA_SUCCESS &&
+		(err = cuModuleLoadData(&d->module, ptx)) == CUDA_SUCCESS &&
+		(err = cuModuleGetFunction(&d->forward, d->module, "slothgo_forward")) == CUDA_SUCCESS) {
+		err = cuModuleGetFunction(&d->inverse, d->module, "slothgo_inverse");
+	}
+	free(ptx);
+	if (err != CUDA_SUCCESS) {
+		cuDevicePrimaryCtxRelease(dev);
+	}
+	return err;
+}
+
+static CUresult slothgo_cuda_alloc(slothgo_cuda *d, CUdeviceptr *buf, unsigned long long *values, size_t size) {
+	CUresult err;
+	if ((err = cuCtxSetCurrent(d->ctx)) != CUDA_SUCCESS || (err = cuMemAlloc(buf, size)) != CUDA_SUCCESS) {
+		return err;
+	}
+	if ((err = cuMemcpyHtoD(*buf, values, size)) != CUDA_SUCCESS) {
+		cuMemFree(*buf);
+	}
+	return err;
+}
+
+static CUresult slothgo_cuda_free(slothgo_cuda *d, CUdeviceptr buf, unsigned long long *values, size_t size) {
+	CUresult err;
+	if ((err = cuCtxSetCurrent(d->ctx)) != CUDA_SUCCESS) {
+		return err;
+	}
+	if (values != NULL) {
+		err = cuMemcpyDtoH(values, buf, size);
+	}
+	cuMemFree(buf);
+	return err;
+}
+
+// slothgo_cuda_launch 让 buf 中的 n 个值各推进 iterations 次并等待内核结束
+static CUresult slothgo_cuda_launch(slothgo_cuda *d, int inverse, slothgo_params *p, CUdeviceptr buf, unsigned int n, unsigned long long iterations) {
+	CUresult err;
+	if ((err = cuCtxSetCurrent(d->ctx)) != CUDA_SUCCESS) {
+		return err;
+	}
+	void *args[] = {p, &buf, &n, &iterations};
+	unsigned int block = 128;
+	if ((err = cuLaunchKernel(inverse ? d->inverse : d->forward, (n + block - 1) / block, 1, 1, block, 1, 1,
+			0, NULL, args, NULL)) != CUDA_SUCCESS) {
+		return err;
+	}
+	return cuCtxSynchronize();
+}
+
+static const char *slothgo_cuda_error(CUresult err) {
+	const char *s = NULL;
+	cuGetErrorString(err, &s);
+	return s != NULL ? s : "unknown CUDA error";
+}
+*/
+import "C"
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"unsafe"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+//go:embed kernel.cu
+var kernelSource string
+
+// Available 报告当前构建是否包含 CUDA 后端
+const Available = true
+
+// 每次启动内核推进的迭代次数: 在两次启动之间检查 ctx，并让单次启动远低于显示设备的看门狗时限
+const (
+	forwardChunk = 1 << 10
+	inverseChunk = 1 << 16
+)
+
+func init() {
+	if a, err := New(0); err == nil {
+		slothgo.RegisterAccelerator(a)
+	}
+}
+
+// accelerator 在一块 GPU 上执行批量迭代，每个值占用一个线程
+type accelerator struct {
+	mu  sync.Mutex // 同一设备上的任务依次执行
+	dev C.slothgo_cuda
+}
+
+// New 打开第 ordinal 块 GPU 并编译内核
+func New(ordinal int) (slothgo.Accelerator, error) {
+	a := new(accelerator)
+	src := C.CString(kernelSource)
+	defer C.free(unsafe.Pointer(src))
+	var log [4096]C.char
+	if err := C.slothgo_cuda_open(C.int(ordinal), src, &a.dev, &log[0], C.size_t(len(log))); err != C.CUDA_SUCCESS {
+		if err == C.CUDA_ERROR_INVALID_SOURCE {
+			return nil, fmt.Errorf("cuda: failed to compile the kernel: %s", C.GoString(&log[0]))
+		}
+		return nil, cudaError(err)
+	}
+	return a, nil
+}
+
+func (a *accelerator) Name() string {
+	return Name
+}
+
+func (a *accelerator) Supports(p *big.Int, permutation string) bool {
+	return supports(p, permutation)
+}
+
+func (a *accelerator) Forward(ctx context.Context, job *slothgo.AcceleratorJob) error {
+	return a.run(ctx, job, false)
+}
+
+func (a *accelerator) Inverse(ctx context.Context, job *slothgo.AcceleratorJob) error {
+	return a.run(ctx, job, true)
+}
+
+// run 把 job.Values 复制到设备上，按块推进 job.Iterations 次后取回
+func (a *accelerator) run(ctx context.Context, job *slothgo.AcceleratorJob, inverse bool) error {
+	data, err := encode(job)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	if len(job.Values) > math.MaxInt32 {
+		return fmt.Errorf("cuda: too many values (%d)", len(job.Values))
+	}
+	prm := cParams(newParams(job.P, job.Perm.Name()))
+	chunk := uint64(forwardChunk)
+	if inverse {
+		chunk = inverseChunk
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	values := (*C.ulonglong)(unsafe.Pointer(&data[0]))
+	size := C.size_t(8 * len(data))
+	var buf C.CUdeviceptr
+	if err := C.slothgo_cuda_alloc(&a.dev, &buf, values, size); err != C.CUDA_SUCCESS {
+		return cudaError(err)
+	}
+	for done := uint64(0); done < job.Iterations; {
+		if err := ctx.Err(); err != nil {
+			C.slothgo_cuda_free(&a.dev, buf, nil, 0)
+			return err
+		}
+		k := min(chunk, job.Iterations-done)
+		if err := C.slothgo_cuda_launch(&a.dev, C.int(boolInt(inverse)), &prm, buf, C.uint(len(job.Values)), C.ulonglong(k)); err != C.CUDA_SUCCESS {
+			C.slothgo_cuda_free(&a.dev, buf, nil, 0)
+			return cudaError(err)
+		}
+		done += k
+	}
+	if err := C.slothgo_cuda_free(&a.dev, buf, values, size); err != C.CUDA_SUCCESS {
+		return cudaError(err)
+	}
+	decode(job, data)
+	return nil
+}
+
+func cParams(prm *params) C.slothgo_params {
+	var c C.slothgo_params
+	for i := range limbs {
+		c.p[i] = C.ulonglong(prm.p[i])
+		c.r2[i] = C.ulonglong(prm.r2[i])
+		c.one[i] = C.ulonglong(prm.one[i])
+		c.e[i] = C.ulonglong(prm.e[i])
+	}
+	c.pinv = C.ulonglong(prm.pinv)
+	c.ebits = C.int(prm.ebits)
+	c.lw15 = C.int(boolInt(prm.lw15))
+	return c
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func cudaError(err C.CUresult) error {
+	return fmt.Errorf("cuda: %s", C.GoString(C.slothgo_cuda_error(err)))
+}
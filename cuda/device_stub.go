@@ -0,0 +1,13 @@
+//go:build !cgo || !cuda
+
+package cuda
+
+import slothgo "github.com/alan22333/sloth_go"
+
+// Available 报告当前构建是否包含 CUDA 后端
+const Available = false
+
+// New 在未启用 cgo 或 cuda 构建标签时总是返回 ErrUnavailable
+func New(ordinal int) (slothgo.Accelerator, error) {
+	return nil, ErrUnavailable
+}
@@ -0,0 +1,180 @@
+// Sloth 的 CUDA 内核，由 device.go 在运行时用 NVRTC 编译
+// 每个线程推进一个值，值以 4 个小端 64 位 limb 存放，p < 2^256 且 p ≡ 3 (mod 4)。
+// 域运算使用 Montgomery 乘法 (CIOS)，值在两次迭代之间保持标准表示，以便按奇偶性选择根
+
+typedef unsigned long long u64;
+
+// slothgo_params 与 device.go 中的同名结构体布局相同
+typedef struct {
+	u64 p[4];    // 模数
+	u64 r2[4];   // R² mod p, R = 2^256
+	u64 one[4];  // R mod p, 即 Montgomery 表示的 1
+	u64 e[4];    // (p+1)/4
+	u64 pinv;    // -p⁻¹ mod 2^64
+	int ebits;   // e 的位数
+	int lw15;    // 非零时使用论文的 σ (sqrt-lw15)
+} slothgo_params;
+
+// mac 计算 t + a·b + c，低 64 位写回 t，高 64 位写回 c
+__device__ static inline void mac(u64 *t, u64 a, u64 b, u64 *c) {
+	u64 lo = a * b;
+	u64 hi = __umul64hi(a, b);
+	lo += *t;
+	hi += lo < *t;
+	lo += *c;
+	hi += lo < *c;
+	*t = lo;
+	*c = hi;
+}
+
+// geq 报告 a >= b
+__device__ static inline int geq(const u64 a[4], const u64 b[4]) {
+	for (int i = 3; i >= 0; i--) {
+		if (a[i] != b[i]) {
+			return a[i] > b[i];
+		}
+	}
+	return 1;
+}
+
+// sub 计算 r = a - b，返回借位
+__device__ static inline u64 sub(u64 r[4], const u64 a[4], const u64 b[4]) {
+	u64 borrow = 0;
+	for (int i = 0; i < 4; i++) {
+		u64 d = a[i] - b[i];
+		u64 b1 = a[i] < b[i];
+		r[i] = d - borrow;
+		borrow = b1 | (d < borrow);
+	}
+	return borrow;
+}
+
+// mont_mul 计算 r = a·b·R⁻¹ mod p，要求 a, b < p，结果完全约化; r 可以与 a 或 b 相同
+__device__ static void mont_mul(u64 r[4], const u64 a[4], const u64 b[4], const slothgo_params *P) {
+	u64 t[6] = {0, 0, 0, 0, 0, 0};
+	for (int i = 0; i < 4; i++) {
+		u64 c = 0;
+		for (int j = 0; j < 4; j++) {
+			mac(&t[j], a[j], b[i], &c);
+		}
+		t[4] += c;
+		t[5] = t[4] < c;
+
+		u64 m = t[0] * P->pinv;
+		c = 0;
+		mac(&t[0], m, P->p[0], &c);
+		for (int j = 1; j < 4; j++) {
+			mac(&t[j], m, P->p[j], &c);
+			t[j - 1] = t[j];
+		}
+		t[3] = t[4] + c;
+		t[4] = t[5] + (t[3] < c);
+	}
+	if (t[4] != 0 || geq(t, P->p)) {
+		sub(t, t, P->p);
+	}
+	for (int i = 0; i < 4; i++) {
+		r[i] = t[i];
+	}
+}
+
+__device__ static inline int is_zero(const u64 x[4]) {
+	return (x[0] | x[1] | x[2] | x[3]) == 0;
+}
+
+__device__ static inline int equal(const u64 a[4], const u64 b[4]) {
+	return ((a[0] ^ b[0]) | (a[1] ^ b[1]) | (a[2] ^ b[2]) | (a[3] ^ b[3])) == 0;
+}
+
+// neg 原地计算 x = -x mod p
+__device__ static inline void neg(u64 x[4], const slothgo_params *P) {
+	if (!is_zero(x)) {
+		sub(x, P->p, x);
+	}
+}
+
+// sigma 是 σ: 默认 0 不动、偶数减一、奇数加一; lw15 时 p-1 不动、其余翻转最低位
+__device__ static void sigma(u64 x[4], const slothgo_params *P) {
+	if (P->lw15) {
+		u64 top[4] = {P->p[0] - 1, P->p[1], P->p[2], P->p[3]};
+		if (!equal(x, top)) {
+			x[0] ^= 1;
+		}
+		return;
+	}
+	if (x[0] & 1) {
+		for (int i = 0; i < 4 && ++x[i] == 0; i++) {
+		}
+	} else if (!is_zero(x)) {
+		for (int i = 0; i < 4 && x[i]-- == 0; i++) {
+		}
+	}
+}
+
+// rho 是 ρ: r = x^((p+1)/4) 满足 r² = ±x，x 是二次剩余时取偶数的根，否则取奇数的根
+__device__ static void rho(u64 x[4], const slothgo_params *P) {
+	u64 a[4], r[4], sq[4];
+	mont_mul(a, x, P->r2, P);
+	for (int i = 0; i < 4; i++) {
+		r[i] = P->one[i];
+	}
+	for (int bit = P->ebits - 1; bit >= 0; bit--) {
+		mont_mul(r, r, r, P);
+		if ((P->e[bit / 64] >> (bit % 64)) & 1) {
+			mont_mul(r, r, a, P);
+		}
+	}
+	mont_mul(sq, r, r, P);
+	int residue = equal(sq, a);
+	const u64 unit[4] = {1, 0, 0, 0};
+	mont_mul(x, r, unit, P);
+	if (((x[0] & 1) == 0) != residue) {
+		neg(x, P);
+	}
+}
+
+// rho_inverse 是 ρ⁻¹: y 为偶数时 y²，为奇数时 -y²
+__device__ static void rho_inverse(u64 y[4], const slothgo_params *P) {
+	int odd = y[0] & 1;
+	mont_mul(y, y, y, P);
+	mont_mul(y, y, P->r2, P);
+	if (odd) {
+		neg(y, P);
+	}
+}
+
+extern "C" __global__ void slothgo_forward(slothgo_params P, u64 *values, unsigned int n, u64 iterations) {
+	unsigned int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i >= n) {
+		return;
+	}
+	u64 x[4];
+	for (int j = 0; j < 4; j++) {
+		x[j] = values[4 * i + j];
+	}
+	for (u64 k = 0; k < iterations; k++) {
+		sigma(x, &P);
+		rho(x, &P);
+	}
+	for (int j = 0; j < 4; j++) {
+		values[4 * i + j] = x[j];
+	}
+}
+
+extern "C" __global__ void slothgo_inverse(slothgo_params P, u64 *values, unsigned int n, u64 iterations) {
+	unsigned int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i >= n) {
+		return;
+	}
+	u64 x[4];
+	for (int j = 0; j < 4; j++) {
+		x[j] = values[4 * i + j];
+	}
+	for (u64 k = 0; k < iterations; k++) {
+		rho_inverse(x, &P);
+		sigma(x, &P);
+	}
+	for (int j = 0; j < 4; j++) {
+		values[4 * i + j] = x[j];
+	}
+}
@@ -0,0 +1,188 @@
+package slothgo
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// derVersion 是 DER 结构中的版本号
+const derVersion = 1
+
+// proofDER 是证明的 ASN.1 结构:
+//
+//	SlothProof ::= SEQUENCE {
+//	    version             INTEGER,
+//	    hash                OCTET STRING,
+//	    witness             OCTET STRING,  -- 定长大端编码
+//	    iterations          INTEGER,
+//	    fingerprint         OCTET STRING,
+//	    checkpointInterval  [0] IMPLICIT INTEGER OPTIONAL,
+//	    checkpoints         [1] IMPLICIT SEQUENCE OF OCTET STRING OPTIONAL,
+//	    stateStride         [2] IMPLICIT INTEGER OPTIONAL,
+//	    stateRoot           [3] IMPLICIT OCTET STRING OPTIONAL }
+//
+// INTEGER 字段在 Go 中为 int64，迭代次数不超过 MaxIterations，总能表示
+type proofDER struct {
+	Version            int
+	Hash               []byte
+	Witness            []byte
+	Iterations         int64
+	Fingerprint        []byte
+	CheckpointInterval int64    `asn1:"optional,tag:0"`
+	Checkpoints        [][]byte `asn1:"optional,tag:1"`
+	StateStride        int64    `asn1:"optional,tag:2"`
+	StateRoot          []byte   `asn1:"optional,tag:3"`
+}
+
+// paramsDER 是参数的 ASN.1 结构:
+//
+//	SlothParams ::= SEQUENCE {
+//	    version      INTEGER,
+//	    prime        INTEGER,
+//	    iterations   INTEGER,
+//	    hash         UTF8String,
+//	    permutation  UTF8String,
+//	    domainTag    [0] IMPLICIT OCTET STRING OPTIONAL,
+//	    bindParams   [1] IMPLICIT BOOLEAN OPTIONAL,
+//	    hashToField  [2] IMPLICIT BOOLEAN OPTIONAL }
+type paramsDER struct {
+	Version     int
+	P           *big.Int
+	Iterations  int64
+	Hash        string `asn1:"utf8"`
+	Permutation string `asn1:"utf8"`
+	DomainTag   []byte `asn1:"optional,tag:0"`
+	BindParams  bool   `asn1:"optional,tag:1"`
+	HashToField bool   `asn1:"optional,tag:2"`
+}
+
+// MarshalDER 把证明编码为 DER，可以嵌入 X.509 扩展等 PKI 结构中
+func (p *Proof) MarshalDER() ([]byte, error) {
+	if p.Witness == nil {
+		return nil, errors.New("proof witness cannot be nil")
+	}
+	if p.Iterations > MaxIterations || p.CheckpointInterval > MaxIterations || p.StateStride > MaxIterations {
+		return nil, fmt.Errorf("iteration counts cannot exceed %d", MaxIterations)
+	}
+	v := proofDER{
+		Version:            derVersion,
+		Hash:               p.Hash,
+		Witness:            encodeFixed(p.Witness, p.size),
+		Iterations:         int64(p.Iterations),
+		Fingerprint:        p.Fingerprint,
+		CheckpointInterval: int64(p.CheckpointInterval),
+		StateStride:        int64(p.StateStride),
+		StateRoot:          p.StateRoot,
+	}
+	for _, c := range p.Checkpoints {
+		v.Checkpoints = append(v.Checkpoints, encodeFixed(c, p.size))
+	}
+	return asn1.Marshal(v)
+}
+
+// UnmarshalDER 解码 MarshalDER 的输出，尾随数据会被拒绝
+func (p *Proof) UnmarshalDER(data []byte) error {
+	return proofEncodingError("der", p.unmarshalDER(data))
+}
+
+func (p *Proof) unmarshalDER(data []byte) error {
+	var v proofDER
+	rest, err := asn1.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data after DER proof")
+	}
+	if v.Version != derVersion {
+		return fmt.Errorf("unsupported DER proof version %d", v.Version)
+	}
+	if len(v.Witness) == 0 {
+		return errors.New("proof witness cannot be empty")
+	}
+	if v.Iterations < 0 || v.CheckpointInterval < 0 || v.StateStride < 0 {
+		return errors.New("iteration counts cannot be negative")
+	}
+	proof := Proof{
+		Hash:               v.Hash,
+		Witness:            new(big.Int).SetBytes(v.Witness),
+		Iterations:         uint64(v.Iterations),
+		Fingerprint:        v.Fingerprint,
+		CheckpointInterval: uint64(v.CheckpointInterval),
+		StateStride:        uint64(v.StateStride),
+		StateRoot:          v.StateRoot,
+		size:               len(v.Witness),
+	}
+	for _, c := range v.Checkpoints {
+		proof.Checkpoints = append(proof.Checkpoints, new(big.Int).SetBytes(c))
+	}
+	*p = proof
+	return nil
+}
+
+// X509Extension 把证明包装为 X.509 扩展，id 由使用方的 PKI 分配
+func (p *Proof) X509Extension(id asn1.ObjectIdentifier, critical bool) (pkix.Extension, error) {
+	value, err := p.MarshalDER()
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: id, Critical: critical, Value: value}, nil
+}
+
+// MarshalDER 把参数编码为 DER
+func (params *Params) MarshalDER() ([]byte, error) {
+	if params.P == nil {
+		return nil, errors.New("params prime cannot be nil")
+	}
+	if params.Iterations > MaxIterations {
+		return nil, fmt.Errorf("iterations cannot exceed %d", MaxIterations)
+	}
+	return asn1.Marshal(paramsDER{
+		Version:     derVersion,
+		P:           params.P,
+		Iterations:  int64(params.Iterations),
+		Hash:        params.Hash.String(),
+		Permutation: params.Permutation,
+		DomainTag:   params.DomainTag,
+		BindParams:  params.BindParams,
+		HashToField: params.HashToField,
+	})
+}
+
+// UnmarshalDER 解码 MarshalDER 的输出，尾随数据会被拒绝
+func (params *Params) UnmarshalDER(data []byte) error {
+	var v paramsDER
+	rest, err := asn1.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data after DER params")
+	}
+	if v.Version != derVersion {
+		return fmt.Errorf("unsupported DER params version %d", v.Version)
+	}
+	if v.P == nil || v.P.Sign() <= 0 {
+		return errors.New("params prime must be positive")
+	}
+	if v.Iterations < 0 {
+		return errors.New("iterations cannot be negative")
+	}
+	h, err := ParseHashID(v.Hash)
+	if err != nil {
+		return err
+	}
+	*params = Params{
+		P:           v.P,
+		Iterations:  uint64(v.Iterations),
+		Hash:        h,
+		Permutation: v.Permutation,
+		DomainTag:   v.DomainTag,
+		BindParams:  v.BindParams,
+		HashToField: v.HashToField,
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package slothgo
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestProof_DER(t *testing.T) {
+	s, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(300))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for name, tc := range map[string]*Sloth{"plain": testVDF, "segmented": s} {
+		proof, err := tc.ComputeProof(testInput)
+		if err != nil {
+			t.Fatalf("%s: ComputeProof failed: %v", name, err)
+		}
+		data, err := proof.MarshalDER()
+		if err != nil {
+			t.Fatalf("%s: MarshalDER failed: %v", name, err)
+		}
+		var decoded Proof
+		if err := decoded.UnmarshalDER(data); err != nil {
+			t.Fatalf("%s: UnmarshalDER failed: %v", name, err)
+		}
+		if ok, err := tc.VerifyProof(testInput, &decoded); !ok {
+			t.Fatalf("%s: VerifyProof of decoded proof failed: %v", name, err)
+		}
+		if again, _ := decoded.MarshalDER(); !bytes.Equal(again, data) {
+			t.Errorf("%s: DER did not round-trip", name)
+		}
+		if err := decoded.UnmarshalDER(append(data, 0)); err == nil {
+			t.Errorf("%s: expected trailing data to be rejected", name)
+		}
+	}
+
+	proof, _ := testVDF.ComputeProof(testInput)
+	ext, err := proof.X509Extension(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, false)
+	if err != nil {
+		t.Fatalf("X509Extension failed: %v", err)
+	}
+	var decoded Proof
+	if err := decoded.UnmarshalDER(ext.Value); err != nil {
+		t.Fatalf("UnmarshalDER of extension value failed: %v", err)
+	}
+}
+
+func TestParams_DER(t *testing.T) {
+	s, err := New(testVDF.P, 300, WithDomainTag([]byte("tsa")), WithParamBinding())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, inst := range []*Sloth{testVDF, s} {
+		data, err := inst.Params().MarshalDER()
+		if err != nil {
+			t.Fatalf("MarshalDER failed: %v", err)
+		}
+		var params Params
+		if err := params.UnmarshalDER(data); err != nil {
+			t.Fatalf("UnmarshalDER failed: %v", err)
+		}
+		restored, err := NewFromParams(&params)
+		if err != nil {
+			t.Fatalf("NewFromParams failed: %v", err)
+		}
+		if !bytes.Equal(restored.Fingerprint(), inst.Fingerprint()) {
+			t.Error("params did not round-trip through DER")
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package slothgo
+
+import (
+	"math/big"
+	"time"
+)
+
+// ComputeForDuration 从 input 出发不断迭代 τ，直到用完时间预算 d (至少迭代一次)
+// 返回的 Proof 记录了实际完成的迭代次数，验证方应使用该次数进行验证:
+//
+//	v, _ := s.WithIterations(proof.Iterations)
+//	ok, err := v.VerifyProof(input, proof)
+//
+// 该模式忽略 s.Iterations 以及进度回调
+func (s *Sloth) ComputeForDuration(input []byte, d time.Duration) (*Proof, error) {
+	deadline := time.Now().Add(d)
+	w := s.initialValue(input)
+	tmp := new(big.Int)
+	var n uint64
+	for n == 0 || time.Now().Before(deadline) {
+		s.perm.Forward(w, tmp)
+		n++
+	}
+
+	actual, err := s.WithIterations(n)
+	if err != nil {
+		return nil, err
+	}
+	witness := new(big.Int).Set(w)
+	return actual.NewProof(actual.outputHash(witness), witness), nil
+}
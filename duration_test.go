@@ -0,0 +1,27 @@
+package slothgo
+
+import (
+	"testing"
+	"time"
+)
+
+// TestComputeForDuration 检查按时间预算计算的证明可以用实际迭代次数验证
+func TestComputeForDuration(t *testing.T) {
+	proof, err := testVDF.ComputeForDuration(testInput, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ComputeForDuration failed: %v", err)
+	}
+	if proof.Iterations <= 0 {
+		t.Fatalf("expected positive iteration count, got %d", proof.Iterations)
+	}
+	t.Logf("completed %d iterations in 20ms", proof.Iterations)
+
+	verifier, err := testVDF.WithIterations(proof.Iterations)
+	if err != nil {
+		t.Fatalf("WithIterations failed: %v", err)
+	}
+	verified, err := verifier.VerifyProof(testInput, proof)
+	if err != nil || !verified {
+		t.Fatalf("VerifyProof failed: %v, %v", verified, err)
+	}
+}
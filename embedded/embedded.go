@@ -0,0 +1,259 @@
+// Package embedded 是面向 TinyGo 与小内存网关的精简验证方，只验证模数不超过 256 位、使用默认选项
+// (SHA-256、平方根置换、取模输入映射、没有域标签与参数绑定) 的证明，标准参数集 sloth-256-t30s 即属于此类。
+//
+// 与 slothgo.Sloth 相比，这里不使用 math/big、反射与 fmt: 域元素是栈上的 [4]uint64，乘法复用
+// internal/fp256 的定长内核 (TinyGo 下使用纯 Go 实现)，哈希的输入拼接在定长数组中，错误是预先分配的
+// 包级变量。New 之后的 Verify 与 VerifyRound 不分配堆内存，耗时只取决于迭代次数。
+//
+// 这里没有素性检验: p 应当来自标准参数集或者已经在别处检查过
+package embedded
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/alan22333/sloth_go/internal/fp256"
+)
+
+// 验证失败的原因
+var (
+	ErrWitnessSize     = errors.New("embedded: witness has the wrong length")
+	ErrWitnessRange    = errors.New("embedded: witness must be in the range [0, p-1]")
+	ErrHashMismatch    = errors.New("embedded: hash of witness does not match")
+	ErrReversal        = errors.New("embedded: reversed witness does not match initial value")
+	ErrPreviousTooLong = errors.New("embedded: previous randomness exceeds 255 bytes")
+)
+
+// 构造 Verifier 时的错误
+var (
+	errPrimeSize      = errors.New("embedded: p must be between 2 and 256 bits")
+	errPrimeForm      = errors.New("embedded: p must be congruent to 3 (mod 4)")
+	errIterations     = errors.New("embedded: iterations must be positive")
+	errPrimeNotStrict = errors.New("embedded: p must not have leading zero bytes")
+)
+
+// standardOffset 是 sloth-256-t30s 的 p = 2^256 - 189
+const standardOffset = 189
+
+// seedDomain 与 beacon.Seed 的域分隔前缀相同
+const seedDomain = "slothgo/beacon/seed/v1"
+
+// maxSeedInput 是信标种子哈希输入的最大长度: 前缀、轮次序号、previous 的长度与内容、Merkle 根
+const maxSeedInput = len(seedDomain) + 8 + 1 + 255 + sha256.Size
+
+// Verifier 保存一组参数预先计算好的常量，创建后只读，可以在多个协程中共用
+type Verifier struct {
+	m          [4]uint64 // p，小端 limb
+	mInv       uint64    // -p⁻¹ mod 2^64
+	rr         [4]uint64 // 2^512 mod p
+	size       int       // witness 的字节数 ⌈bits(p)/8⌉
+	iterations uint64
+
+	fingerprint [sha256.Size]byte
+}
+
+// NewStandard 返回标准参数集 sloth-256-t30s 的素数与给定迭代次数的验证方
+func NewStandard(iterations uint64) (*Verifier, error) {
+	var p [32]byte
+	for i := range p {
+		p[i] = 0xff
+	}
+	p[31] = 0xff - (standardOffset - 1)
+	return New(p[:], iterations)
+}
+
+// New 使用大端字节表示的素数 prime (不超过 32 字节，没有前导零) 与迭代次数创建验证方
+func New(prime []byte, iterations uint64) (*Verifier, error) {
+	if len(prime) == 0 || len(prime) > 32 || (len(prime) == 1 && prime[0] < 3) {
+		return nil, errPrimeSize
+	}
+	if prime[0] == 0 {
+		return nil, errPrimeNotStrict
+	}
+	if prime[len(prime)-1]&3 != 3 {
+		return nil, errPrimeForm
+	}
+	if iterations == 0 {
+		return nil, errIterations
+	}
+	v := &Verifier{size: len(prime), iterations: iterations}
+	v.m = limbs(prime)
+
+	// Newton 迭代求 p⁻¹ mod 2^64
+	inv := uint64(1)
+	for range 6 {
+		inv *= 2 - v.m[0]*inv
+	}
+	v.mInv = -inv
+
+	// 2^512 mod p: 从 1 开始做 512 次模 p 的倍加
+	v.rr = [4]uint64{1}
+	for range 512 {
+		v.rr = v.double(v.rr)
+	}
+	v.fingerprint = fingerprint(prime, iterations)
+	return v, nil
+}
+
+// Iterations 返回验证方要求的迭代次数
+func (v *Verifier) Iterations() uint64 {
+	return v.iterations
+}
+
+// WitnessSize 返回 witness 的字节数
+func (v *Verifier) WitnessSize() int {
+	return v.size
+}
+
+// Fingerprint 返回参数指纹，与 slothgo.Sloth.Fingerprint 相同
+func (v *Verifier) Fingerprint() [sha256.Size]byte {
+	return v.fingerprint
+}
+
+// Verify 检查 hash 与定长编码的 witness 是 input 的正确计算结果，通过时返回 nil
+func (v *Verifier) Verify(input, hash, witness []byte) error {
+	return v.verifyFrom(v.initialValue(sha256.Sum256(input)), hash, witness)
+}
+
+// VerifyRound 检查信标第 index 轮的随机数 randomness 与 witness 是由上一轮的随机数 previous 与本轮提交的
+// Merkle 根 root 计算得到的，与 beacon.Verify 中的 Sloth 证明部分相同 (Merkle 根本身需要调用方检查)
+func (v *Verifier) VerifyRound(index uint64, previous, root, randomness, witness []byte) error {
+	if len(previous) > 255 {
+		return ErrPreviousTooLong
+	}
+	var buf [maxSeedInput]byte
+	n := copy(buf[:], seedDomain)
+	binary.BigEndian.PutUint64(buf[n:], index)
+	n += 8
+	buf[n] = byte(len(previous))
+	n++
+	n += copy(buf[n:], previous)
+	n += copy(buf[n:], root)
+	seed := sha256.Sum256(buf[:n])
+	return v.Verify(seed[:], randomness, witness)
+}
+
+// verifyFrom 检查 witness 的哈希，再从 witness 逆向迭代 l 次并与 start 比较
+func (v *Verifier) verifyFrom(start [4]uint64, hash, witness []byte) error {
+	if len(witness) != v.size {
+		return ErrWitnessSize
+	}
+	w := limbs(witness)
+	if !less(&w, &v.m) {
+		return ErrWitnessRange
+	}
+	if sum := sha256.Sum256(witness); subtle.ConstantTimeCompare(sum[:], hash) != 1 {
+		return ErrHashMismatch
+	}
+	for range v.iterations {
+		w = v.inverse(w)
+	}
+	if w != start {
+		return ErrReversal
+	}
+	return nil
+}
+
+// initialValue 计算 w₀ = int(digest) mod p
+// digest < 2^256 ≤ p·2^256，一次 Montgomery 约减得到 digest·2^-256，再乘以 2^512 得到 digest mod p
+func (v *Verifier) initialValue(digest [sha256.Size]byte) [4]uint64 {
+	var t [8]uint64
+	d := limbs(digest[:])
+	copy(t[:4], d[:])
+	z := fp256.Reduce(&t, &v.m, v.mInv)
+	fp256.MontMul(&z, &z, &v.rr, &v.m, v.mInv)
+	return z
+}
+
+// inverse 计算一次 τ⁻¹ = σ ∘ ρ⁻¹: 平方，原值为奇数时取负，再做邻居交换
+func (v *Verifier) inverse(x [4]uint64) [4]uint64 {
+	odd := x[0]&1 == 1
+	var r [4]uint64
+	fp256.MontSqr(&r, &x, &v.m, v.mInv)
+	fp256.MontMul(&x, &r, &v.rr, &v.m, v.mInv)
+	if odd && x != [4]uint64{} {
+		var b uint64
+		x[0], b = bits.Sub64(v.m[0], x[0], 0)
+		x[1], b = bits.Sub64(v.m[1], x[1], b)
+		x[2], b = bits.Sub64(v.m[2], x[2], b)
+		x[3], _ = bits.Sub64(v.m[3], x[3], b)
+	}
+	return sigma(x)
+}
+
+// sigma 是邻居交换: 0 不动，偶数减一，奇数加一
+func sigma(x [4]uint64) [4]uint64 {
+	var c uint64
+	switch {
+	case x[0]&1 == 1:
+		x[0], c = bits.Add64(x[0], 1, 0)
+		x[1], c = bits.Add64(x[1], 0, c)
+		x[2], c = bits.Add64(x[2], 0, c)
+		x[3] += c
+	case x != [4]uint64{}:
+		x[0], c = bits.Sub64(x[0], 1, 0)
+		x[1], c = bits.Sub64(x[1], 0, c)
+		x[2], c = bits.Sub64(x[2], 0, c)
+		x[3] -= c
+	}
+	return x
+}
+
+// double 计算 2x mod p，要求 x < p
+func (v *Verifier) double(x [4]uint64) [4]uint64 {
+	var c, b uint64
+	x[0], c = bits.Add64(x[0], x[0], 0)
+	x[1], c = bits.Add64(x[1], x[1], c)
+	x[2], c = bits.Add64(x[2], x[2], c)
+	x[3], c = bits.Add64(x[3], x[3], c)
+	var z [4]uint64
+	z[0], b = bits.Sub64(x[0], v.m[0], 0)
+	z[1], b = bits.Sub64(x[1], v.m[1], b)
+	z[2], b = bits.Sub64(x[2], v.m[2], b)
+	z[3], b = bits.Sub64(x[3], v.m[3], b)
+	if c < b {
+		return x
+	}
+	return z
+}
+
+// fingerprint 与 slothgo.Sloth.Fingerprint 在默认选项下的计算方式相同
+func fingerprint(prime []byte, iterations uint64) [sha256.Size]byte {
+	const prefix = "slothgo/params/v1"
+	var buf [len(prefix) + 4 + 32 + 8 + 1 + len("sqrt") + 1 + len("sha256") + 1 + len("mod") + 2]byte
+	n := copy(buf[:], prefix)
+	binary.BigEndian.PutUint32(buf[n:], uint32(len(prime)))
+	n += 4
+	n += copy(buf[n:], prime)
+	binary.BigEndian.PutUint64(buf[n:], iterations)
+	n += 8
+	for _, name := range [...]string{"sqrt", "sha256", "mod"} {
+		buf[n] = byte(len(name))
+		n++
+		n += copy(buf[n:], name)
+	}
+	n += 2 // 域标签长度为 0
+	return sha256.Sum256(buf[:n])
+}
+
+// limbs 把不超过 32 字节的大端数转换为小端 limb
+func limbs(b []byte) (z [4]uint64) {
+	for i, c := range b {
+		shift := uint(len(b)-1-i) * 8
+		z[shift/64] |= uint64(c) << (shift % 64)
+	}
+	return z
+}
+
+// less 返回 x < y
+func less(x, y *[4]uint64) bool {
+	var b uint64
+	_, b = bits.Sub64(x[0], y[0], 0)
+	_, b = bits.Sub64(x[1], y[1], b)
+	_, b = bits.Sub64(x[2], y[2], b)
+	_, b = bits.Sub64(x[3], y[3], b)
+	return b == 1
+}
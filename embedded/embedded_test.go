@@ -0,0 +1,238 @@
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// TestMatchesSloth 检查不同位数的素数下，与 slothgo.Sloth 接受同样的证明、计算同样的指纹
+func TestMatchesSloth(t *testing.T) {
+	std, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdfs := []*slothgo.Sloth{std}
+	for _, bits := range []int{16, 64, 127, 200, 256} {
+		p, err := slothgo.GenerateSlothPrime(bits)
+		if err != nil {
+			t.Fatalf("GenerateSlothPrime(%d) failed: %v", bits, err)
+		}
+		vdf, err := slothgo.New(p, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vdfs = append(vdfs, vdf)
+	}
+	for _, base := range vdfs {
+		for _, iterations := range []uint64{1, 2, 150} {
+			vdf, err := base.WithIterations(iterations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := New(vdf.P.Bytes(), iterations)
+			if err != nil {
+				t.Fatalf("New(%x) failed: %v", vdf.P, err)
+			}
+			if fp := v.Fingerprint(); !bytes.Equal(fp[:], vdf.Fingerprint()) {
+				t.Errorf("p=%x: fingerprint differs", vdf.P)
+			}
+			for _, input := range []string{"", "gateway", "another input"} {
+				proof, err := vdf.ComputeProof([]byte(input))
+				if err != nil {
+					t.Fatalf("ComputeProof failed: %v", err)
+				}
+				witness := vdf.EncodeWitness(proof.Witness)
+				if err := v.Verify([]byte(input), proof.Hash, witness); err != nil {
+					t.Errorf("p=%x l=%d %q: valid proof rejected: %v", vdf.P, iterations, input, err)
+				}
+				if err := v.Verify([]byte(input+"!"), proof.Hash, witness); !errors.Is(err, ErrReversal) {
+					t.Errorf("p=%x l=%d %q: other input returned %v", vdf.P, iterations, input, err)
+				}
+			}
+		}
+	}
+}
+
+func TestStandard(t *testing.T) {
+	std, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewStandard(std.Iterations)
+	if err != nil {
+		t.Fatalf("NewStandard failed: %v", err)
+	}
+	if fp := v.Fingerprint(); !bytes.Equal(fp[:], std.Fingerprint()) || v.WitnessSize() != 32 {
+		t.Error("NewStandard does not match sloth-256-t30s")
+	}
+}
+
+func TestRejects(t *testing.T) {
+	vdf, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vdf, err = vdf.WithIterations(20); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewStandard(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []byte("gateway")
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := vdf.EncodeWitness(proof.Witness)
+
+	flipped := bytes.Clone(witness)
+	flipped[31] ^= 1
+	tooLarge := vdf.P.Bytes()
+	for name, tc := range map[string]struct {
+		hash, witness []byte
+		err           error
+	}{
+		"short witness":   {proof.Hash, witness[1:], ErrWitnessSize},
+		"witness p":       {proof.Hash, tooLarge, ErrWitnessRange},
+		"wrong hash":      {witness, witness, ErrHashMismatch},
+		"flipped witness": {proof.Hash, flipped, ErrHashMismatch},
+	} {
+		if err := v.Verify(input, tc.hash, tc.witness); err != tc.err {
+			t.Errorf("%s: got %v, want %v", name, err, tc.err)
+		}
+	}
+	other, _ := NewStandard(21)
+	if err := other.Verify(input, proof.Hash, witness); err != ErrReversal {
+		t.Errorf("different iterations returned %v", err)
+	}
+
+	for name, p := range map[string][]byte{
+		"empty":        nil,
+		"too long":     bytes.Repeat([]byte{0xff}, 33),
+		"leading zero": {0, 0xff},
+		"1 mod 4":      {13},
+		"too small":    {2},
+		"even modulus": {0x10},
+	} {
+		if _, err := New(p, 10); err == nil {
+			t.Errorf("%s: prime %x was accepted", name, p)
+		}
+	}
+	if _, err := New([]byte{7}, 0); err == nil {
+		t.Error("zero iterations were accepted")
+	}
+}
+
+// TestVerifyRound 检查与 beacon.Verify 接受同样的轮次
+func TestVerifyRound(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdf, err := slothgo.New(p, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := New(p.Bytes(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	var prev *beacon.Round
+	for i := range 3 {
+		b.Contribute([]byte("contribution " + strconv.Itoa(i)))
+		r, err := b.Publish(context.Background())
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if err := beacon.Verify(vdf, r, prev); err != nil {
+			t.Fatalf("beacon.Verify failed: %v", err)
+		}
+		witness := vdf.EncodeWitness(r.Proof.Witness)
+		if err := v.VerifyRound(r.Index, r.Previous, r.ContributionsRoot, r.Randomness, witness); err != nil {
+			t.Errorf("round %d rejected: %v", r.Index, err)
+		}
+		if err := v.VerifyRound(r.Index+1, r.Previous, r.ContributionsRoot, r.Randomness, witness); err != ErrReversal {
+			t.Errorf("round %d with the wrong index returned %v", r.Index, err)
+		}
+		if err := v.VerifyRound(r.Index, []byte("other"), r.ContributionsRoot, r.Randomness, witness); err != ErrReversal {
+			t.Errorf("round %d with the wrong previous randomness returned %v", r.Index, err)
+		}
+		prev = r
+	}
+	if err := v.VerifyRound(0, make([]byte, 256), nil, nil, nil); err != ErrPreviousTooLong {
+		t.Errorf("long previous randomness returned %v", err)
+	}
+}
+
+// TestAllocs 检查验证过程不分配堆内存
+func TestAllocs(t *testing.T) {
+	vdf, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vdf, err = vdf.WithIterations(50); err != nil {
+		t.Fatal(err)
+	}
+	v, _ := NewStandard(50)
+	input := []byte("gateway")
+	proof, _ := vdf.ComputeProof(input)
+	witness := vdf.EncodeWitness(proof.Witness)
+	previous, root := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+
+	if n := testing.AllocsPerRun(20, func() {
+		if err := v.Verify(input, proof.Hash, witness); err != nil {
+			t.Fatal(err)
+		}
+	}); n != 0 {
+		t.Errorf("Verify allocates %v times", n)
+	}
+	if n := testing.AllocsPerRun(20, func() {
+		_ = v.VerifyRound(7, previous, root, proof.Hash, witness)
+	}); n != 0 {
+		t.Errorf("VerifyRound allocates %v times", n)
+	}
+}
+
+// TestImports 检查本包不依赖反射、fmt 与 math/big
+func TestImports(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "embedded.go", nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, imp := range f.Imports {
+		switch path, _ := strconv.Unquote(imp.Path.Value); path {
+		case "fmt", "reflect", "math/big", "encoding/json", "strconv":
+			t.Errorf("embedded.go imports %s", path)
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	vdf, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if vdf, err = vdf.WithIterations(10_000); err != nil {
+		b.Fatal(err)
+	}
+	v, _ := NewStandard(10_000)
+	input := []byte("gateway")
+	proof, _ := vdf.ComputeProof(input)
+	witness := vdf.EncodeWitness(proof.Witness)
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := v.Verify(input, proof.Hash, witness); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
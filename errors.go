@@ -0,0 +1,47 @@
+package slothgo
+
+import "errors"
+
+// 以下错误用 %w 包装后返回，调用方应使用 errors.Is 判断失败原因，而不是匹配错误信息
+var (
+	// ErrNotPrime 表示模数 p 没有通过素性检验 (包括素数证书无效)
+	ErrNotPrime = errors.New("p is not a prime number")
+	// ErrBadCongruence 表示 p 不满足置换要求的同余条件，例如论文置换要求的 p ≡ 3 (mod 4)
+	ErrBadCongruence = errors.New("p does not satisfy the congruence required by the permutation")
+	// ErrWitnessOutOfRange 表示 witness 不在 [0, p-1] 内
+	ErrWitnessOutOfRange = errors.New("witness must be in the range [0, p-1]")
+	// ErrHashMismatch 表示 witness 的输出哈希与证明中的哈希不一致
+	ErrHashMismatch = errors.New("hash of witness does not match provided hash")
+	// ErrReversalMismatch 表示逆向迭代 (整体、区段或里程碑) 没有回到预期的起点
+	ErrReversalMismatch = errors.New("verification failed: reversed witness does not match initial value")
+	// ErrInvalidProofEncoding 表示证明无法解码，具体的格式与原因见 ProofEncodingError
+	ErrInvalidProofEncoding = errors.New("invalid proof encoding")
+)
+
+// ProofEncodingError 是 Proof 的各个 Unmarshal 方法与 ParseProof 返回的错误
+// errors.Is(err, ErrInvalidProofEncoding) 对它成立，errors.As 可以取出格式与底层原因
+type ProofEncodingError struct {
+	Format string // "binary"、"text"、"json"、"cbor" 或 "der"
+	Err    error
+}
+
+func (e *ProofEncodingError) Error() string {
+	return "invalid " + e.Format + " proof: " + e.Err.Error()
+}
+
+func (e *ProofEncodingError) Unwrap() error {
+	return e.Err
+}
+
+// Is 使 errors.Is(err, ErrInvalidProofEncoding) 成立
+func (e *ProofEncodingError) Is(target error) bool {
+	return target == ErrInvalidProofEncoding
+}
+
+// proofEncodingError 用格式名包装解码错误，err 为 nil 时返回 nil
+func proofEncodingError(format string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ProofEncodingError{Format: format, Err: err}
+}
@@ -0,0 +1,95 @@
+package slothgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// TestSentinelErrors 检查各类失败都可以用 errors.Is 区分
+func TestSentinelErrors(t *testing.T) {
+	hash, witness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	check := func(name string, err, want error) {
+		t.Helper()
+		if !errors.Is(err, want) {
+			t.Errorf("%s: got %v, want %v", name, err, want)
+		}
+	}
+
+	_, err = testVDF.Verify(testInput, hash, new(big.Int).Set(testVDF.P))
+	check("witness p", err, ErrWitnessOutOfRange)
+	_, err = testVDF.DecodeWitness(testVDF.P.FillBytes(make([]byte, testVDF.WitnessSize())))
+	check("DecodeWitness", err, ErrWitnessOutOfRange)
+	_, err = testVDF.Verify(testInput, []byte("wrong"), witness)
+	check("wrong hash", err, ErrHashMismatch)
+	_, err = testVDF.Verify([]byte("other"), hash, witness)
+	check("wrong input", err, ErrReversalMismatch)
+
+	// 带检查点的证明在出错的区段上报告同一个错误
+	segmented, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := segmented.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	_, err = segmented.VerifyProof([]byte("other"), proof)
+	check("segmented", err, ErrReversalMismatch)
+	proof.Witness = new(big.Int).Set(testVDF.P)
+	_, err = segmented.VerifyProof(testInput, proof)
+	check("segmented witness p", err, ErrWitnessOutOfRange)
+
+	_, err = New(big.NewInt(91), 10)
+	check("composite", err, ErrNotPrime)
+	_, cert, _ := GenerateCertifiedPrime(32)
+	_, err = New(big.NewInt(103), 10, WithPrimalityCheck(CertificateCheck(cert)))
+	check("certificate for another prime", err, ErrNotPrime)
+	_, err = New(big.NewInt(13), 10, WithPaperConformance())
+	check("paper permutation", err, ErrBadCongruence)
+	_, err = New(big.NewInt(13), 10, WithPermutation(NewCubeRootPermutation))
+	check("cube root", err, ErrBadCongruence)
+}
+
+func TestProofEncodingError(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := proof.MarshalBinary()
+	cbor, _ := proof.MarshalCBOR()
+	der, _ := proof.MarshalDER()
+
+	var p Proof
+	for name, tc := range map[string]struct {
+		err    error
+		format string
+	}{
+		"binary":    {p.UnmarshalBinary(data[:len(data)-1]), "binary"},
+		"magic":     {p.UnmarshalBinary([]byte("nope")), "binary"},
+		"cbor":      {p.UnmarshalCBOR(cbor[:len(cbor)-1]), "cbor"},
+		"der":       {p.UnmarshalDER(der[1:]), "der"},
+		"json":      {json.Unmarshal([]byte(`{"hash":"zz"}`), &p), "json"},
+		"text":      {func() error { _, err := ParseProof("!!"); return err }(), "text"},
+		"text body": {func() error { _, err := ParseProof("AAAA"); return err }(), "binary"},
+	} {
+		var encErr *ProofEncodingError
+		if !errors.Is(tc.err, ErrInvalidProofEncoding) || !errors.As(tc.err, &encErr) || encErr.Format != tc.format {
+			t.Errorf("%s: got %v", name, tc.err)
+		}
+	}
+	if err := p.UnmarshalBinary(data); err != nil {
+		t.Errorf("valid proof returned %v", err)
+	}
+
+	// 解码成功但验证失败的证明不是编码错误
+	p.Hash = []byte("wrong")
+	if _, err := testVDF.VerifyProofCtx(context.Background(), testInput, &p); errors.Is(err, ErrInvalidProofEncoding) || !errors.Is(err, ErrHashMismatch) {
+		t.Errorf("mismatched proof returned %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+// Package external 让 FPGA、ASIC 等外部设备以 slothgo.Accelerator 的形式接入:
+// 设备只负责迭代循环，输入哈希、证明组装与验证仍由 slothgo 完成。提供两种接入方式:
+//   - sidecar: 设备驱动作为独立进程运行，通过 JSON-RPC 1.0 (net/rpc/jsonrpc) 提供服务，可以用任何语言实现;
+//     Go 编写的 sidecar 可以直接用 Serve 包装一个 slothgo.Accelerator，调用方用 Dial 得到客户端
+//   - Go plugin: 以 -buildmode=plugin 编译、导出 NewAccelerator 构造函数的共享库，由 LoadPlugin 加载
+//
+// 两种方式得到的加速器都可以交给 slothgo.WithAccelerator 或 slothgo.RegisterAccelerator
+//
+// sidecar 协议的服务名为 "Accelerator"，域元素一律编码为不带前缀的小写十六进制字符串:
+//
+//	Accelerator.Info     {}                                          -> {"Name": string}
+//	Accelerator.Supports {"P", "Permutation"}                        -> bool
+//	Accelerator.Forward  {"P", "Permutation", "Iterations", "Values"} -> {"Values": [string]}
+//	Accelerator.Inverse  {"P", "Permutation", "Iterations", "Values"} -> {"Values": [string]}
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// serviceName 是 sidecar 协议中的 RPC 服务名
+const serviceName = "Accelerator"
+
+// InfoReply 是 Accelerator.Info 的返回值
+type InfoReply struct {
+	Name string
+}
+
+// SupportsArgs 是 Accelerator.Supports 的参数
+type SupportsArgs struct {
+	P           string
+	Permutation string
+}
+
+// JobArgs 是 Accelerator.Forward 与 Accelerator.Inverse 的参数
+type JobArgs struct {
+	P           string
+	Permutation string
+	Iterations  uint64
+	Values      []string
+}
+
+// JobReply 是 Accelerator.Forward 与 Accelerator.Inverse 的返回值，Values 与参数一一对应
+type JobReply struct {
+	Values []string
+}
+
+// Client 是通过 sidecar 协议访问远程加速器的 slothgo.Accelerator
+// 远程调用无法中途取消: ctx 取消时 Forward/Inverse 立即返回 ctx.Err()，但 sidecar 上的任务会继续运行
+type Client struct {
+	rpc  *rpc.Client
+	name string
+}
+
+var _ slothgo.Accelerator = (*Client)(nil)
+
+// Dial 连接 network/address 上的 sidecar，例如 Dial("unix", "/run/sloth-fpga.sock")
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to accelerator: %w", err)
+	}
+	return NewClient(conn)
+}
+
+// NewClient 在已建立的连接上创建客户端 (例如子进程的标准输入输出)，并查询加速器名称
+func NewClient(conn io.ReadWriteCloser) (*Client, error) {
+	c := &Client{rpc: jsonrpc.NewClient(conn)}
+	var info InfoReply
+	if err := c.rpc.Call(serviceName+".Info", struct{}{}, &info); err != nil {
+		c.rpc.Close()
+		return nil, fmt.Errorf("failed to query accelerator: %w", err)
+	}
+	c.name = info.Name
+	return c, nil
+}
+
+// Close 关闭与 sidecar 的连接
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Name 返回 sidecar 报告的加速器名称
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Supports 询问 sidecar 是否支持给定参数，通信失败时视为不支持
+func (c *Client) Supports(p *big.Int, permutation string) bool {
+	var ok bool
+	err := c.rpc.Call(serviceName+".Supports", SupportsArgs{P: p.Text(16), Permutation: permutation}, &ok)
+	return err == nil && ok
+}
+
+func (c *Client) Forward(ctx context.Context, job *slothgo.AcceleratorJob) error {
+	return c.run(ctx, serviceName+".Forward", job)
+}
+
+func (c *Client) Inverse(ctx context.Context, job *slothgo.AcceleratorJob) error {
+	return c.run(ctx, serviceName+".Inverse", job)
+}
+
+// run 发送任务并把结果写回 job.Values; 返回的每个值都必须在 [0, p-1] 内
+func (c *Client) run(ctx context.Context, method string, job *slothgo.AcceleratorJob) error {
+	if job == nil || job.P == nil || job.Perm == nil {
+		return errors.New("accelerator job is incomplete")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	args := JobArgs{
+		P:           job.P.Text(16),
+		Permutation: job.Perm.Name(),
+		Iterations:  job.Iterations,
+		Values:      encodeValues(job.Values),
+	}
+	var reply JobReply
+	call := c.rpc.Go(method, args, &reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-call.Done:
+	}
+	if call.Error != nil {
+		return call.Error
+	}
+	values, err := decodeValues(reply.Values, job.P)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(job.Values) {
+		return fmt.Errorf("accelerator returned %d values, want %d", len(values), len(job.Values))
+	}
+	for i, v := range values {
+		job.Values[i].Set(v)
+	}
+	return nil
+}
+
+// Server 把一个 slothgo.Accelerator 按 sidecar 协议导出，方法只供 net/rpc 调用
+type Server struct {
+	a slothgo.Accelerator
+
+	mu    sync.Mutex
+	perms map[SupportsArgs]slothgo.Permutation // 按 (p, 名称) 缓存的置换
+}
+
+// NewServer 创建导出 a 的服务端
+func NewServer(a slothgo.Accelerator) *Server {
+	return &Server{a: a, perms: make(map[SupportsArgs]slothgo.Permutation)}
+}
+
+// Serve 在 lis 上接受连接并为每个连接提供 a 的服务，直到 lis 关闭
+func Serve(lis net.Listener, a slothgo.Accelerator) error {
+	srv, err := newRPCServer(a)
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// ServeConn 在单个连接上提供 a 的服务，直到连接关闭
+func ServeConn(conn io.ReadWriteCloser, a slothgo.Accelerator) error {
+	srv, err := newRPCServer(a)
+	if err != nil {
+		return err
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+func newRPCServer(a slothgo.Accelerator) (*rpc.Server, error) {
+	if a == nil {
+		return nil, errors.New("accelerator cannot be nil")
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, NewServer(a)); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+func (s *Server) Info(args struct{}, reply *InfoReply) error {
+	reply.Name = s.a.Name()
+	return nil
+}
+
+func (s *Server) Supports(args SupportsArgs, reply *bool) error {
+	p, ok := new(big.Int).SetString(args.P, 16)
+	if !ok {
+		return errors.New("malformed modulus")
+	}
+	*reply = s.a.Supports(p, args.Permutation)
+	return nil
+}
+
+func (s *Server) Forward(args JobArgs, reply *JobReply) error {
+	return s.run(args, reply, s.a.Forward)
+}
+
+func (s *Server) Inverse(args JobArgs, reply *JobReply) error {
+	return s.run(args, reply, s.a.Inverse)
+}
+
+// run 从参数恢复置换与输入值，在本地加速器上执行任务
+func (s *Server) run(args JobArgs, reply *JobReply, fn func(context.Context, *slothgo.AcceleratorJob) error) error {
+	p, ok := new(big.Int).SetString(args.P, 16)
+	if !ok || p.Sign() <= 0 {
+		return errors.New("malformed modulus")
+	}
+	perm, err := s.permutation(args, p)
+	if err != nil {
+		return err
+	}
+	values, err := decodeValues(args.Values, p)
+	if err != nil {
+		return err
+	}
+	job := &slothgo.AcceleratorJob{P: p, Perm: perm, Iterations: args.Iterations, Values: values}
+	if err := fn(context.Background(), job); err != nil {
+		return err
+	}
+	reply.Values = encodeValues(values)
+	return nil
+}
+
+func (s *Server) permutation(args JobArgs, p *big.Int) (slothgo.Permutation, error) {
+	key := SupportsArgs{P: args.P, Permutation: args.Permutation}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if perm, ok := s.perms[key]; ok {
+		return perm, nil
+	}
+	perm, err := slothgo.NewPermutation(args.Permutation, p)
+	if err != nil {
+		return nil, err
+	}
+	s.perms[key] = perm
+	return perm, nil
+}
+
+func encodeValues(values []*big.Int) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.Text(16)
+	}
+	return out
+}
+
+// decodeValues 解析十六进制的域元素，并检查它们在 [0, p-1] 内
+func decodeValues(values []string, p *big.Int) ([]*big.Int, error) {
+	out := make([]*big.Int, len(values))
+	for i, s := range values {
+		v, ok := new(big.Int).SetString(s, 16)
+		if !ok || v.Sign() < 0 || v.Cmp(p) >= 0 {
+			return nil, fmt.Errorf("value %d is not a field element", i)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+package external
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// pipeClient 在内存管道上启动一个包装 a 的 sidecar 并返回连接它的客户端
+func pipeClient(t *testing.T, a slothgo.Accelerator) *Client {
+	t.Helper()
+	server, conn := net.Pipe()
+	go ServeConn(server, a)
+	c, err := NewClient(conn)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// checkAccelerator 检查通过 a 的批量计算与验证和 CPU 路径的结果一致
+func checkAccelerator(t *testing.T, a slothgo.Accelerator) {
+	t.Helper()
+	p := slothgo.ParamSets()[0].Prime()
+	inputs := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	vdf, err := slothgo.New(p, 100, slothgo.WithAccelerator(a))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	plain, _ := slothgo.New(p, 100)
+
+	proofs, errs := vdf.ComputeBatch(inputs, 0)
+	batch := make([]slothgo.Proof, len(inputs))
+	for i, input := range inputs {
+		if errs[i] != nil {
+			t.Fatalf("ComputeBatch failed: %v", errs[i])
+		}
+		_, witness, _ := plain.Compute(input)
+		if proofs[i].Witness.Cmp(witness) != 0 {
+			t.Fatalf("input %d: accelerator produced a different witness", i)
+		}
+		batch[i] = *proofs[i]
+	}
+	batch[1].Witness = new(big.Int).Add(batch[1].Witness, big.NewInt(1))
+	results, err := vdf.VerifyBatchFast(inputs, batch)
+	if err != nil {
+		t.Fatalf("VerifyBatchFast failed: %v", err)
+	}
+	if !results[0] || results[1] || !results[2] {
+		t.Errorf("unexpected results %v", results)
+	}
+}
+
+// TestSidecar 检查 sidecar 协议的往返结果与本地 CPU 加速器一致
+func TestSidecar(t *testing.T) {
+	c := pipeClient(t, &slothgo.CPUAccelerator{Workers: 2})
+	if c.Name() != "cpu" {
+		t.Errorf("Name() = %q, want cpu", c.Name())
+	}
+	if !c.Supports(big.NewInt(23), "sqrt") {
+		t.Error("Supports returned false")
+	}
+	checkAccelerator(t, c)
+}
+
+// TestSidecarErrors 检查未知置换、非法迭代次数与取消的 ctx 都以 error 返回
+func TestSidecarErrors(t *testing.T) {
+	c := pipeClient(t, &slothgo.CPUAccelerator{Workers: 1})
+	p := big.NewInt(23)
+	perm, err := slothgo.NewPermutation("sqrt", p)
+	if err != nil {
+		t.Fatalf("NewPermutation failed: %v", err)
+	}
+
+	job := &slothgo.AcceleratorJob{P: p, Perm: perm, Iterations: 5, Values: []*big.Int{big.NewInt(3)}}
+	if err := c.Forward(context.Background(), job); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if err := c.Inverse(context.Background(), job); err != nil || job.Values[0].Int64() != 3 {
+		t.Fatalf("Inverse = %v, %v", job.Values[0], err)
+	}
+
+	if _, err := slothgo.NewPermutation("nope", p); err == nil {
+		t.Error("NewPermutation accepted an unknown name")
+	}
+	args := JobArgs{P: "17", Permutation: "nope", Iterations: 1, Values: []string{"3"}}
+	if err := c.rpc.Call(serviceName+".Forward", args, new(JobReply)); err == nil {
+		t.Error("sidecar accepted an unknown permutation")
+	}
+	args = JobArgs{P: "17", Permutation: "sqrt", Iterations: 1, Values: []string{"17"}}
+	if err := c.rpc.Call(serviceName+".Forward", args, new(JobReply)); err == nil {
+		t.Error("sidecar accepted a value outside the field")
+	}
+
+	job.Iterations = slothgo.MaxIterations + 1
+	if err := c.Forward(context.Background(), job); err == nil {
+		t.Error("sidecar accepted more than MaxIterations")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job.Iterations = 5
+	if err := c.Forward(ctx, job); err == nil {
+		t.Error("Forward ignored a cancelled context")
+	}
+}
+
+// TestLoadPlugin 编译 testdata/cpuplugin 并检查加载得到的加速器
+func TestLoadPlugin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	path := filepath.Join(t.TempDir(), "cpuplugin.so")
+	// 插件必须与测试二进制使用相同的构建标签，否则 group 等包的版本不一致
+	args := []string{"build", "-buildmode=plugin", "-o", path}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			if s.Key == "-tags" {
+				args = append(args, "-tags", s.Value)
+			}
+		}
+	}
+	cmd := exec.Command(gobin, append(args, "./testdata/cpuplugin")...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("plugins are not supported here: %v\n%s", err, out)
+	}
+	a, err := LoadPlugin(path)
+	if err != nil {
+		t.Fatalf("LoadPlugin failed: %v", err)
+	}
+	checkAccelerator(t, a)
+
+	if _, err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("LoadPlugin accepted a missing file")
+	}
+}
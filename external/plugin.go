@@ -0,0 +1,36 @@
+package external
+
+import (
+	"fmt"
+	"plugin"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// PluginSymbol 是插件必须导出的构造函数名，其类型必须为 func() (slothgo.Accelerator, error)
+const PluginSymbol = "NewAccelerator"
+
+// LoadPlugin 加载以 -buildmode=plugin 编译的加速器插件并调用其构造函数
+// 插件必须与宿主程序使用相同的 Go 版本与 slothgo 版本编译; Go plugin 只支持启用 cgo 的 Linux、macOS 与 FreeBSD
+func LoadPlugin(path string) (slothgo.Accelerator, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open accelerator plugin: %w", err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("accelerator plugin: %w", err)
+	}
+	newAccel, ok := sym.(func() (slothgo.Accelerator, error))
+	if !ok {
+		return nil, fmt.Errorf("accelerator plugin: %s has type %T", PluginSymbol, sym)
+	}
+	a, err := newAccel()
+	if err != nil {
+		return nil, fmt.Errorf("accelerator plugin: %w", err)
+	}
+	if a == nil {
+		return nil, fmt.Errorf("accelerator plugin: %s returned nil", PluginSymbol)
+	}
+	return a, nil
+}
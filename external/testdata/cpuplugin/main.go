@@ -0,0 +1,10 @@
+// cpuplugin 是 LoadPlugin 测试使用的插件，它导出 slothgo.CPUAccelerator
+package main
+
+import slothgo "github.com/alan22333/sloth_go"
+
+func NewAccelerator() (slothgo.Accelerator, error) {
+	return &slothgo.CPUAccelerator{Workers: 2}, nil
+}
+
+func main() {}
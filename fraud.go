@@ -0,0 +1,247 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// 欺诈证明 (fraud proof)
+//
+// 乐观方案中证明方只公布 Claim: 输出哈希与对区段端点 (w₀、各检查点与最终 witness) 的 Merkle 承诺，
+// 仲裁方 (例如链上合约) 默认接受，只在有人挑战时介入。挑战者持有完整的带检查点证明，找出第一个
+// 错误的区段，出示它的两个端点及各自的 Merkle 路径。仲裁方只需要逆向迭代这一个区段 (不超过
+// CheckpointInterval 次平方)，而不是整个计算。叶子的编码与 StateTree 相同，
+// StateStride 等于 CheckpointInterval 时 CheckpointRoot 就是 StateRoot
+
+var (
+	// ErrNoFraud 表示证明的每个区段都正确，无法构造欺诈证明
+	ErrNoFraud = errors.New("proof is valid, no fraud to prove")
+	// ErrFraudRejected 表示欺诈证明没有成立: 它的端点不在承诺中，或者所指的区段是正确的
+	ErrFraudRejected = errors.New("fraud proof rejected")
+)
+
+// Claim 是证明方对一次计算的简洁声明
+type Claim struct {
+	Hash               []byte
+	Iterations         uint64
+	Fingerprint        []byte
+	CheckpointInterval uint64
+	CheckpointRoot     []byte   // 区段端点的 Merkle 根，第 j 个叶子为第 min(j*k, l) 次迭代后的值
+	Start              *big.Int // 承诺的第一个端点 w₀，挑战者用它出示第 0 个叶子
+}
+
+// FraudProof 指出 Claim 中的一个错误区段
+// Segment 小于区段数时表示从端点 Start 出发迭代该区段的次数得不到下一个端点 End；
+// 等于区段数时表示最终 witness End 的输出哈希与 Claim.Hash 不符，此时 Start 与 StartPath 为空
+type FraudProof struct {
+	Segment   int
+	Start     *big.Int
+	End       *big.Int
+	StartPath [][]byte
+	EndPath   [][]byte
+}
+
+// NewClaim 从带检查点的证明生成声明，input 用于得到第一个端点 w₀
+func (s *Sloth) NewClaim(input []byte, proof *Proof) (*Claim, error) {
+	boundaries, err := s.boundaries(input, proof)
+	if err != nil {
+		return nil, err
+	}
+	return &Claim{
+		Hash:               bytes.Clone(proof.Hash),
+		Iterations:         proof.Iterations,
+		Fingerprint:        bytes.Clone(proof.Fingerprint),
+		CheckpointInterval: proof.CheckpointInterval,
+		CheckpointRoot:     s.boundaryTree(proof.CheckpointInterval, boundaries).Root(),
+		Start:              new(big.Int).Set(boundaries[0]),
+	}, nil
+}
+
+// ProveFraud 找出 claim 中第一个错误的区段并生成欺诈证明，proof 是证明方与 claim 一起公布的完整证明
+// 承诺的 w₀ 不是 input 对应的值时第 0 个区段即为错误; 否则各区段并行逆向检查，代价与一次验证相同。
+// proof 与 claim 的承诺不一致时返回错误，proof 正确时返回 ErrNoFraud
+func (s *Sloth) ProveFraud(ctx context.Context, input []byte, claim *Claim, proof *Proof) (*FraudProof, error) {
+	if claim == nil {
+		return nil, errors.New("claim cannot be nil")
+	}
+	boundaries, err := s.boundaries(input, proof)
+	if err != nil {
+		return nil, err
+	}
+	k := proof.CheckpointInterval
+	if claim.CheckpointInterval != k {
+		return nil, errors.New("proof does not match the claim")
+	}
+	// Merkle 路径必须对应 claim 承诺的叶子，因此第 0 个叶子取承诺的 w₀ 而不是重新计算的值
+	w0 := boundaries[0]
+	if v := claim.Start; v != nil {
+		if v.Sign() < 0 || v.BitLen() > 8*s.WitnessSize() {
+			return nil, errors.New("claimed w₀ cannot be encoded")
+		}
+		boundaries[0] = v
+	}
+	tree := s.boundaryTree(k, boundaries)
+	if !bytes.Equal(tree.Root(), claim.CheckpointRoot) {
+		return nil, errors.New("proof does not match the claim")
+	}
+	segments := len(boundaries) - 1
+	if boundaries[0].Cmp(w0) != 0 {
+		fp := &FraudProof{Segment: 0, Start: new(big.Int).Set(boundaries[0]), End: new(big.Int).Set(boundaries[1])}
+		fp.StartPath, _ = tree.Proof(0)
+		fp.EndPath, _ = tree.Proof(1)
+		return fp, nil
+	}
+
+	// holds[j] 报告第 j 个区段是否正确，各区段相互独立，可以并行检查
+	holds := make([]bool, segments)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), segments) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc := newScratch()
+			for j := range jobs {
+				holds[j] = s.segmentHolds(ctx, k, j, boundaries[j], boundaries[j+1], sc)
+			}
+		}()
+	}
+	for j := range segments {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	bad := slices.Index(holds, false) // 第一个错误区段的位置
+	if bad < 0 {
+		bad = segments
+	}
+	fp := &FraudProof{Segment: bad}
+	if bad == segments {
+		if s.inRange(proof.Witness) && subtle.ConstantTimeCompare(proof.Hash, s.outputHash(proof.Witness)) == 1 {
+			return nil, ErrNoFraud
+		}
+	} else {
+		fp.Start = new(big.Int).Set(boundaries[bad])
+		fp.StartPath, _ = tree.Proof(bad)
+	}
+	end := min(bad+1, segments)
+	fp.End = new(big.Int).Set(boundaries[end])
+	fp.EndPath, _ = tree.Proof(end)
+	return fp, nil
+}
+
+// VerifyFraud 检查 fp 是否证明了 claim 是错误的，成立时返回 nil，不成立时返回包装 ErrFraudRejected 的错误
+// 仲裁方只需要 input、claim 与 fp，代价为一个区段的逆向迭代与两条 Merkle 路径
+func (s *Sloth) VerifyFraud(ctx context.Context, input []byte, claim *Claim, fp *FraudProof) error {
+	if claim == nil || fp == nil {
+		return errors.New("claim and fraud proof cannot be nil")
+	}
+	if err := s.checkProofParams(&Proof{Iterations: claim.Iterations, Fingerprint: claim.Fingerprint}); err != nil {
+		return err
+	}
+	k := claim.CheckpointInterval
+	if k == 0 {
+		return errors.New("checkpoint interval must be positive")
+	}
+	segments := int(ceilDiv(s.Iterations, k))
+	if fp.Segment < 0 || fp.Segment > segments {
+		return fmt.Errorf("%w: segment %d out of range [0, %d]", ErrFraudRejected, fp.Segment, segments)
+	}
+	if fp.End == nil {
+		return fmt.Errorf("%w: end value is missing", ErrFraudRejected)
+	}
+	if !s.boundaryIncluded(claim, segments, min(fp.Segment+1, segments), fp.End, fp.EndPath) {
+		return fmt.Errorf("%w: end value is not committed", ErrFraudRejected)
+	}
+	if fp.Segment == segments {
+		if s.inRange(fp.End) && subtle.ConstantTimeCompare(claim.Hash, s.outputHash(fp.End)) == 1 {
+			return fmt.Errorf("%w: witness matches the claimed hash", ErrFraudRejected)
+		}
+		return nil
+	}
+
+	if fp.Start == nil {
+		return fmt.Errorf("%w: start value is missing", ErrFraudRejected)
+	}
+	if !s.boundaryIncluded(claim, segments, fp.Segment, fp.Start, fp.StartPath) {
+		return fmt.Errorf("%w: start value is not committed", ErrFraudRejected)
+	}
+	// 起点已确认在承诺中，第一个区段承诺的 w₀ 不是输入对应的值本身就证明了声明是错误的
+	if fp.Segment == 0 && fp.Start.Cmp(s.initialValue(input)) != 0 {
+		return nil
+	}
+	if s.segmentHolds(ctx, k, fp.Segment, fp.Start, fp.End, newScratch()) {
+		return fmt.Errorf("%w: segment %d is correct", ErrFraudRejected, fp.Segment)
+	}
+	return ctx.Err()
+}
+
+// boundaries 返回区段端点: w₀、各检查点与最终 witness
+func (s *Sloth) boundaries(input []byte, proof *Proof) ([]*big.Int, error) {
+	if err := s.checkProofParams(proof); err != nil {
+		return nil, err
+	}
+	k := proof.CheckpointInterval
+	if k == 0 {
+		return nil, errors.New("proof has no checkpoints")
+	}
+	if want := ceilDiv(s.Iterations, k) - 1; uint64(len(proof.Checkpoints)) != want {
+		return nil, fmt.Errorf("proof has %d checkpoints, expected %d", len(proof.Checkpoints), want)
+	}
+	boundaries := make([]*big.Int, 0, len(proof.Checkpoints)+2)
+	boundaries = append(boundaries, s.initialValue(input))
+	boundaries = append(boundaries, proof.Checkpoints...)
+	boundaries = append(boundaries, proof.Witness)
+	// 超出 [0, p-1] 的值仍然可以承诺并被证明为错误，但必须能够编码为定长的叶子
+	for _, b := range boundaries {
+		if b == nil || b.Sign() < 0 || b.BitLen() > 8*s.WitnessSize() {
+			return nil, errors.New("checkpoint or witness cannot be encoded")
+		}
+	}
+	return boundaries, nil
+}
+
+// boundaryTree 对区段端点构建 Merkle 树
+func (s *Sloth) boundaryTree(k uint64, boundaries []*big.Int) *merkle.Tree {
+	leaves := make([][]byte, len(boundaries))
+	for j, b := range boundaries {
+		leaves[j] = s.stateLeaf(min(uint64(j)*k, s.Iterations), b)
+	}
+	return merkle.NewTree(leaves)
+}
+
+// boundaryIncluded 检查 v 是 claim 承诺的第 j 个端点
+func (s *Sloth) boundaryIncluded(claim *Claim, segments, j int, v *big.Int, path [][]byte) bool {
+	if v.Sign() < 0 || v.BitLen() > 8*s.WitnessSize() {
+		return false
+	}
+	leaf := s.stateLeaf(min(uint64(j)*claim.CheckpointInterval, s.Iterations), v)
+	return merkle.Verify(claim.CheckpointRoot, leaf, j, segments+1, path)
+}
+
+// segmentHolds 报告第 j 个区段从 start 出发是否确实到达 end，任一端点超出 [0, p-1] 时不成立
+func (s *Sloth) segmentHolds(ctx context.Context, k uint64, j int, start, end *big.Int, sc *scratch) bool {
+	if !s.inRange(start) || !s.inRange(end) {
+		return false
+	}
+	n := min(uint64(j+1)*k, s.Iterations) - uint64(j)*k
+	w, err := s.reverse(ctx, end, n, sc)
+	return err == nil && w.Cmp(start) == 0
+}
+
+func (s *Sloth) inRange(w *big.Int) bool {
+	return w.Sign() >= 0 && w.Cmp(s.P) < 0
+}
@@ -0,0 +1,153 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func newFraudTestProof(t *testing.T) (*Sloth, *Proof) {
+	t.Helper()
+	vdf, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(300))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := vdf.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	return vdf, proof
+}
+
+// TestFraudProof 检查篡改检查点后可以生成只涉及一个区段的欺诈证明，并且仲裁方接受它
+func TestFraudProof(t *testing.T) {
+	ctx := context.Background()
+	vdf, proof := newFraudTestProof(t)
+	honest, err := vdf.NewClaim(testInput, proof)
+	if err != nil {
+		t.Fatalf("NewClaim failed: %v", err)
+	}
+	if _, err := vdf.ProveFraud(ctx, testInput, honest, proof); !errors.Is(err, ErrNoFraud) {
+		t.Fatalf("ProveFraud on a valid proof returned %v", err)
+	}
+
+	bad := *proof
+	bad.Checkpoints = append([]*big.Int(nil), proof.Checkpoints...)
+	bad.Checkpoints[1] = new(big.Int).Add(proof.Checkpoints[1], big.NewInt(1))
+	claim, err := vdf.NewClaim(testInput, &bad)
+	if err != nil {
+		t.Fatalf("NewClaim failed: %v", err)
+	}
+	if _, err := vdf.ProveFraud(ctx, testInput, honest, &bad); err == nil {
+		t.Error("ProveFraud accepted a proof that does not match the claim")
+	}
+	fp, err := vdf.ProveFraud(ctx, testInput, claim, &bad)
+	if err != nil {
+		t.Fatalf("ProveFraud failed: %v", err)
+	}
+	// 第 1 个区段 (迭代 300..600) 的终点被篡改
+	if fp.Segment != 1 || fp.Start.Cmp(proof.Checkpoints[0]) != 0 || fp.End.Cmp(bad.Checkpoints[1]) != 0 {
+		t.Fatalf("fraud proof points at segment %d", fp.Segment)
+	}
+	if err := testVDF.VerifyFraud(ctx, testInput, claim, fp); err != nil {
+		t.Fatalf("VerifyFraud rejected a valid fraud proof: %v", err)
+	}
+
+	// 同一个欺诈证明不能用来挑战正确的声明
+	if err := vdf.VerifyFraud(ctx, testInput, honest, fp); !errors.Is(err, ErrFraudRejected) {
+		t.Errorf("fraud proof against an honest claim returned %v", err)
+	}
+
+	for name, mutate := range map[string]func(fp *FraudProof){
+		"wrong segment": func(fp *FraudProof) { fp.Segment = 2 },
+		"wrong start":   func(fp *FraudProof) { fp.Start = new(big.Int).Add(fp.Start, big.NewInt(1)) },
+		"missing path":  func(fp *FraudProof) { fp.EndPath = nil },
+		"out of range":  func(fp *FraudProof) { fp.Segment = 9 },
+	} {
+		forged := *fp
+		mutate(&forged)
+		if err := vdf.VerifyFraud(ctx, testInput, claim, &forged); !errors.Is(err, ErrFraudRejected) {
+			t.Errorf("%s: VerifyFraud returned %v", name, err)
+		}
+	}
+}
+
+// TestFraudProofWitness 检查最终 witness 与哈希不符时的欺诈证明
+func TestFraudProofWitness(t *testing.T) {
+	ctx := context.Background()
+	vdf, proof := newFraudTestProof(t)
+	bad := *proof
+	bad.Hash = append([]byte(nil), proof.Hash...)
+	bad.Hash[0] ^= 1
+	claim, err := vdf.NewClaim(testInput, &bad)
+	if err != nil {
+		t.Fatalf("NewClaim failed: %v", err)
+	}
+	fp, err := vdf.ProveFraud(ctx, testInput, claim, &bad)
+	if err != nil {
+		t.Fatalf("ProveFraud failed: %v", err)
+	}
+	if fp.Segment != len(proof.Checkpoints)+1 || fp.Start != nil {
+		t.Fatalf("fraud proof points at segment %d", fp.Segment)
+	}
+	if err := vdf.VerifyFraud(ctx, testInput, claim, fp); err != nil {
+		t.Fatalf("VerifyFraud rejected a valid fraud proof: %v", err)
+	}
+	honest, _ := vdf.NewClaim(testInput, proof)
+	if err := vdf.VerifyFraud(ctx, testInput, honest, fp); !errors.Is(err, ErrFraudRejected) {
+		t.Errorf("fraud proof against an honest claim returned %v", err)
+	}
+}
+
+// TestFraudProofInitialValue 检查承诺了错误 w₀ 的声明可以被挑战，而正确的 w₀ 不能被诬告
+func TestFraudProofInitialValue(t *testing.T) {
+	ctx := context.Background()
+	vdf, proof := newFraudTestProof(t)
+	// 检查点与 witness 都正确，只有承诺的 w₀ 被篡改
+	claim, err := vdf.NewClaim(testInput, proof)
+	if err != nil {
+		t.Fatalf("NewClaim failed: %v", err)
+	}
+	boundaries, err := vdf.boundaries(testInput, proof)
+	if err != nil {
+		t.Fatalf("boundaries failed: %v", err)
+	}
+	boundaries[0] = new(big.Int).Add(boundaries[0], big.NewInt(1))
+	claim.Start = boundaries[0]
+	claim.CheckpointRoot = vdf.boundaryTree(proof.CheckpointInterval, boundaries).Root()
+
+	fp, err := vdf.ProveFraud(ctx, testInput, claim, proof)
+	if err != nil {
+		t.Fatalf("ProveFraud failed: %v", err)
+	}
+	if fp.Segment != 0 || fp.Start.Cmp(claim.Start) != 0 {
+		t.Fatalf("fraud proof points at segment %d", fp.Segment)
+	}
+	if err := vdf.VerifyFraud(ctx, testInput, claim, fp); err != nil {
+		t.Errorf("VerifyFraud rejected a valid fraud proof: %v", err)
+	}
+
+	// 正确的声明中第 0 个区段成立，出示承诺的 w₀ 不构成欺诈
+	honest, err := vdf.NewClaim(testInput, proof)
+	if err != nil {
+		t.Fatalf("NewClaim failed: %v", err)
+	}
+	tree := vdf.boundaryTree(proof.CheckpointInterval, append([]*big.Int{honest.Start}, boundaries[1:]...))
+	forged := &FraudProof{Segment: 0, Start: honest.Start, End: proof.Checkpoints[0]}
+	forged.StartPath, _ = tree.Proof(0)
+	forged.EndPath, _ = tree.Proof(1)
+	if err := vdf.VerifyFraud(ctx, testInput, honest, forged); !errors.Is(err, ErrFraudRejected) {
+		t.Errorf("fraud proof against the honest w₀ returned %v", err)
+	}
+}
+
+func TestClaimRequiresCheckpoints(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if _, err := testVDF.NewClaim(testInput, proof); err == nil {
+		t.Error("NewClaim accepted a proof without checkpoints")
+	}
+}
@@ -0,0 +1,81 @@
+package group
+
+import "math/big"
+
+// maxWindow 是滑动窗口的最大宽度，对应 2^(maxWindow-1) = 16 个奇数次幂的预计算表
+const maxWindow = 5
+
+// expStep 是幂运算调度中的一步: 先平方 sqr 次，再乘以预计算表中的 x^(2·idx+1)
+type expStep struct {
+	sqr int
+	idx int
+}
+
+// expSchedule 是固定指数的滑动窗口调度
+// ρ 的指数 (p+1)/4 在整个计算中不变，因此在构造域时一次性扫描指数的比特，
+// 选出代价最小的窗口宽度，并把每个窗口的平方次数与表下标展开成步骤列表;
+// 热循环只需按步骤执行乘法，不再逐位读取 big.Int
+type expSchedule struct {
+	window int
+	first  int       // 第一个窗口的表下标，累加器直接由它初始化
+	steps  []expStep // 之后的各个窗口
+	tail   int       // 最后一个窗口之后的平方次数
+}
+
+// newExpSchedule 为 e > 0 构造代价 (平方与乘法的总次数，含预计算表) 最小的滑动窗口调度
+func newExpSchedule(e *big.Int) *expSchedule {
+	var best *expSchedule
+	bestCost := 0
+	for w := 1; w <= maxWindow; w++ {
+		s := slidingWindow(e, w)
+		if cost := s.cost(); best == nil || cost < bestCost {
+			best, bestCost = s, cost
+		}
+	}
+	return best
+}
+
+// slidingWindow 从最高位开始把 e 划分为以 1 开头和结尾、宽度不超过 w 的窗口
+func slidingWindow(e *big.Int, w int) *expSchedule {
+	s := &expSchedule{window: w, first: -1}
+	sqr := 0
+	for i := e.BitLen() - 1; i >= 0; {
+		if e.Bit(i) == 0 {
+			sqr++
+			i--
+			continue
+		}
+		// 窗口 [j, i] 的最低位也必须是 1，这样窗口的值是奇数
+		j := max(i-w+1, 0)
+		for e.Bit(j) == 0 {
+			j++
+		}
+		val := 0
+		for k := i; k >= j; k-- {
+			val = val<<1 | int(e.Bit(k))
+		}
+		if s.first < 0 {
+			s.first = val >> 1
+		} else {
+			s.steps = append(s.steps, expStep{sqr: sqr + i - j + 1, idx: val >> 1})
+		}
+		sqr = 0
+		i = j - 1
+	}
+	s.tail = sqr
+	return s
+}
+
+// cost 返回按该调度做一次幂运算所需的平方与乘法总次数
+func (s *expSchedule) cost() int {
+	n := s.tableSize() // 1 次平方加 tableSize-1 次乘法
+	for _, st := range s.steps {
+		n += st.sqr + 1
+	}
+	return n + s.tail
+}
+
+// tableSize 返回预计算表的项数，表中依次是 x, x³, x⁵, ..., x^(2^w - 1)
+func (s *expSchedule) tableSize() int {
+	return 1 << (s.window - 1)
+}
@@ -0,0 +1,52 @@
+package group
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// evalSchedule 按调度在整数上重建指数: 乘以 x^(2·idx+1) 对应加上 2·idx+1，平方对应乘以 2
+func evalSchedule(s *expSchedule) *big.Int {
+	e := big.NewInt(int64(2*s.first + 1))
+	for _, st := range s.steps {
+		e.Lsh(e, uint(st.sqr))
+		e.Add(e, big.NewInt(int64(2*st.idx+1)))
+	}
+	return e.Lsh(e, uint(s.tail))
+}
+
+// TestExpSchedule 检查各种宽度的滑动窗口调度都能精确重建指数，且选中的调度不比 4 位固定窗口慢
+func TestExpSchedule(t *testing.T) {
+	exps := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(0b1011000), big.NewInt(1 << 40)}
+	for _, bits := range []int{64, 255, 1024} {
+		e, err := rand.Int(rand.Reader, new(big.Int).Lsh(bigOne, uint(bits)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exps = append(exps, e.SetBit(e, 0, 1))
+	}
+	p := new(big.Int).Lsh(bigOne, 256)
+	p.Sub(p, big.NewInt(189))
+	exps = append(exps, new(big.Int).Rsh(p.Add(p, bigOne), 2)) // (p+1)/4 = 2^254 - 47
+
+	for _, e := range exps {
+		for w := 1; w <= maxWindow; w++ {
+			s := slidingWindow(e, w)
+			if got := evalSchedule(s); got.Cmp(e) != 0 {
+				t.Fatalf("window %d: schedule for %x evaluates to %x", w, e, got)
+			}
+			for _, st := range s.steps {
+				if st.idx >= s.tableSize() {
+					t.Fatalf("window %d: table index %d out of range", w, st.idx)
+				}
+			}
+		}
+		best := newExpSchedule(e)
+		// 4 位固定窗口: 每位一次平方，每个非零半字节一次乘法，外加 14 次建表乘法
+		fixed := e.BitLen() + (e.BitLen()+3)/4 + 14
+		if e.BitLen() > 64 && best.cost() > fixed {
+			t.Errorf("schedule for %d-bit exponent costs %d, fixed window costs %d", e.BitLen(), best.cost(), fixed)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Field 是素数域 F_p 上的运算，元素以 [0, p-1] 内的 *big.Int 表示
+// 与 Group 不同，Field 的方法采用 math/big 的目标参数风格: 结果写入 z 并返回 z，
+// 这样 Sloth 的热循环可以复用内存。不同的后端 (通用 big.Int、Montgomery、定长 limb 等)
+// 只需实现该接口即可替换 Sloth 的底层算术
+type Field interface {
+	// Modulus 返回域的特征 p
+	Modulus() *big.Int
+	// Add 设置 z = a + b
+	Add(z, a, b *big.Int) *big.Int
+	// Sub 设置 z = a - b
+	Sub(z, a, b *big.Int) *big.Int
+	// Neg 设置 z = -a
+	Neg(z, a *big.Int) *big.Int
+	// Mul 设置 z = a·b
+	Mul(z, a, b *big.Int) *big.Int
+	// Square 设置 z = a²
+	Square(z, a *big.Int) *big.Int
+	// Exp 设置 z = a^e, e >= 0
+	Exp(z, a, e *big.Int) *big.Int
+	// Legendre 返回 a 的勒让德符号 (0, 1 或 -1)
+	Legendre(a *big.Int) int
+	// Sqrt 在 a 是二次剩余时把 a 的一个平方根写入 z 并返回 true，否则返回 false
+	Sqrt(z, a *big.Int) bool
+	// Encode 把元素编码为长度为 ElementSize() 的大端字节数组
+	Encode(a *big.Int) []byte
+	// Decode 是 Encode 的逆运算，并会检查元素在 [0, p-1] 内
+	Decode(data []byte) (*big.Int, error)
+	// ElementSize 返回编码后元素的字节数
+	ElementSize() int
+}
+
+// CombinedSqrt 是 Field 的可选扩展，仅适用于 p ≡ 3 (mod 4)
+// 此时 r = a^((p+1)/4) 满足 r² = ±a，一次幂运算即可同时得到平方根与二次剩余性，省去 Legendre 符号的计算
+type CombinedSqrt interface {
+	// SqrtOrNeg 在 a 是二次剩余时把 a 的一个平方根写入 z 并返回 true，
+	// 否则把 -a 的一个平方根写入 z 并返回 false
+	SqrtOrNeg(z, a *big.Int) bool
+}
+
+// PrimeField 是基于 math/big 的通用 Field 实现
+type PrimeField struct {
+	p       *big.Int
+	sqrtExp *big.Int // p ≡ 3 (mod 4) 时为 (p+1)/4，否则为 nil
+
+	// Tonelli–Shanks 的预计算值 (仅 p ≡ 1 (mod 4) 时使用): p-1 = q·2^s
+	tsS       int
+	tsHalfExp *big.Int // (q-1)/2
+	tsC       *big.Int // z^q，z 为一个二次非剩余
+
+	barrettMu *big.Int  // ⌊4^k / p⌋，k = p 的位数，用于 Barrett 约减
+	scratch   sync.Pool // *primeScratch
+}
+
+// primeScratch 是 PrimeField 单次运算使用的临时值
+type primeScratch struct {
+	q, r, t, u big.Int
+}
+
+func (f *PrimeField) getScratch() *primeScratch {
+	if sc, ok := f.scratch.Get().(*primeScratch); ok {
+		return sc
+	}
+	return new(primeScratch)
+}
+
+var _ Field = (*PrimeField)(nil)
+
+// NewPrimeField 创建模 p 的素数域，调用方需保证 p 是奇素数
+func NewPrimeField(p *big.Int) (*PrimeField, error) {
+	if p == nil || p.Sign() <= 0 || p.Bit(0) == 0 {
+		return nil, errors.New("p must be an odd prime")
+	}
+	f := &PrimeField{p: p}
+	f.barrettMu = new(big.Int).Lsh(bigOne, uint(2*p.BitLen()))
+	f.barrettMu.Div(f.barrettMu, p)
+	if p.Bit(1) == 1 { // p ≡ 3 (mod 4)
+		f.sqrtExp = new(big.Int).Add(p, bigOne)
+		f.sqrtExp.Rsh(f.sqrtExp, 2)
+		return f, nil
+	}
+
+	// p ≡ 1 (mod 4): 为 Tonelli–Shanks 预计算 q、s 和 z^q
+	q := new(big.Int).Sub(p, bigOne)
+	f.tsS = int(q.TrailingZeroBits())
+	q.Rsh(q, uint(f.tsS))
+	f.tsHalfExp = new(big.Int).Rsh(q, 1)
+	f.tsC = new(big.Int).Exp(f.NonResidue(), q, p)
+	return f, nil
+}
+
+// NonResidue 返回最小的二次非剩余 z >= 2
+func (f *PrimeField) NonResidue() *big.Int {
+	z := big.NewInt(2)
+	for big.Jacobi(z, f.p) != -1 {
+		z.Add(z, bigOne)
+	}
+	return z
+}
+
+func (f *PrimeField) Modulus() *big.Int {
+	return f.p
+}
+
+func (f *PrimeField) Add(z, a, b *big.Int) *big.Int {
+	z.Add(a, b)
+	if z.Cmp(f.p) >= 0 {
+		z.Sub(z, f.p)
+	}
+	return z
+}
+
+func (f *PrimeField) Sub(z, a, b *big.Int) *big.Int {
+	z.Sub(a, b)
+	if z.Sign() < 0 {
+		z.Add(z, f.p)
+	}
+	return z
+}
+
+func (f *PrimeField) Neg(z, a *big.Int) *big.Int {
+	if a.Sign() == 0 {
+		return z.SetInt64(0)
+	}
+	return z.Sub(f.p, a)
+}
+
+// Mul 用 QuoRem 取模并复用存放商的临时值，避免 Mod 每次分配商
+func (f *PrimeField) Mul(z, a, b *big.Int) *big.Int {
+	sc := f.getScratch()
+	defer f.scratch.Put(sc)
+	sc.t.Mul(a, b)
+	return f.mod(z, &sc.t, sc)
+}
+
+func (f *PrimeField) Square(z, a *big.Int) *big.Int {
+	sc := f.getScratch()
+	defer f.scratch.Put(sc)
+	sc.t.Mul(a, a)
+	return f.mod(z, &sc.t, sc)
+}
+
+// mod 把 x mod p 写入 z，x 不能是 sc 中的 q 或 r
+// 对 0 <= x < p² 使用 Barrett 约减: 只需乘法与移位，不像 big.Int 的除法那样每次分配临时内存
+func (f *PrimeField) mod(z, x *big.Int, sc *primeScratch) *big.Int {
+	k := uint(f.p.BitLen())
+	if x.Sign() < 0 || uint(x.BitLen()) > 2*k {
+		return z.Mod(x, f.p)
+	}
+	// q = ⌊⌊x / 2^(k-1)⌋·μ / 2^(k+1)⌋ 与 ⌊x / p⌋ 最多相差 2
+	// big.Int.Mul 的目标与操作数相同时会重新分配，因此这里轮流使用 q 与 r
+	sc.r.Rsh(x, k-1)
+	sc.q.Mul(&sc.r, f.barrettMu)
+	sc.q.Rsh(&sc.q, k+1)
+	z.Sub(x, sc.r.Mul(&sc.q, f.p))
+	for z.Cmp(f.p) >= 0 {
+		z.Sub(z, f.p)
+	}
+	return z
+}
+
+func (f *PrimeField) Exp(z, a, e *big.Int) *big.Int {
+	return z.Exp(a, e, f.p)
+}
+
+func (f *PrimeField) Legendre(a *big.Int) int {
+	return big.Jacobi(a, f.p)
+}
+
+// Sqrt 在 p ≡ 3 (mod 4) 时用一次 a^((p+1)/4) 同时完成开方与二次剩余判定，否则使用预计算的 Tonelli–Shanks 算法
+func (f *PrimeField) Sqrt(z, a *big.Int) bool {
+	if f.sqrtExp != nil {
+		sc := f.getScratch()
+		defer f.scratch.Put(sc)
+		if !f.sqrtOrNeg(&sc.u, a, sc) {
+			return false
+		}
+		z.Set(&sc.u)
+		return true
+	}
+	if f.Legendre(a) == -1 {
+		return false
+	}
+	f.tonelliShanks(z, a)
+	return true
+}
+
+// SqrtOrNeg 实现 CombinedSqrt，要求 p ≡ 3 (mod 4)
+func (f *PrimeField) SqrtOrNeg(z, a *big.Int) bool {
+	sc := f.getScratch()
+	defer f.scratch.Put(sc)
+	return f.sqrtOrNeg(z, a, sc)
+}
+
+// sqrtOrNeg 把 a^((p+1)/4) 写入 z 并返回 a 是否为二次剩余; z 不能是 sc 中的 r 或 t
+func (f *PrimeField) sqrtOrNeg(z, a *big.Int, sc *primeScratch) bool {
+	sc.t.Exp(a, f.sqrtExp, f.p)
+	z.Set(&sc.t)
+	sc.t.Mul(z, z)
+	return f.mod(&sc.r, &sc.t, sc).Cmp(a) == 0
+}
+
+// tonelliShanks 计算二次剩余 a 的平方根
+// 初始化 r = a^((q+1)/2), t = a^q, c = z^q, m = s，
+// 然后不断用 c 的适当平方修正 r 和 t，直到 t = 1
+func (f *PrimeField) tonelliShanks(z, a *big.Int) {
+	if a.Sign() == 0 {
+		z.SetInt64(0)
+		return
+	}
+	// 只做一次幂运算: u = a^((q-1)/2), r = u·a, t = u²·a
+	u := new(big.Int).Exp(a, f.tsHalfExp, f.p)
+	r := f.Mul(new(big.Int), u, a)
+	t := f.Mul(new(big.Int), r, u)
+	c := new(big.Int).Set(f.tsC)
+	m := f.tsS
+
+	b := new(big.Int)
+	tt := new(big.Int)
+	for t.Cmp(bigOne) != 0 {
+		// 找到最小的 i 使得 t^(2^i) = 1
+		i := 0
+		for tt.Set(t); tt.Cmp(bigOne) != 0; i++ {
+			f.Square(tt, tt)
+		}
+		// b = c^(2^(m-i-1))
+		b.Set(c)
+		for range m - i - 1 {
+			f.Square(b, b)
+		}
+		m = i
+		f.Square(c, b)
+		f.Mul(t, t, c)
+		f.Mul(r, r, b)
+	}
+	z.Set(r)
+}
+
+func (f *PrimeField) Encode(a *big.Int) []byte {
+	return a.FillBytes(make([]byte, f.ElementSize()))
+}
+
+func (f *PrimeField) Decode(data []byte) (*big.Int, error) {
+	if len(data) != f.ElementSize() {
+		return nil, fmt.Errorf("field element must be %d bytes", f.ElementSize())
+	}
+	a := new(big.Int).SetBytes(data)
+	if a.Cmp(f.p) >= 0 {
+		return nil, errors.New("field element must be in the range [0, p-1]")
+	}
+	return a, nil
+}
+
+func (f *PrimeField) ElementSize() int {
+	return (f.p.BitLen() + 7) / 8
+}
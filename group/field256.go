@@ -0,0 +1,238 @@
+package group
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/alan22333/sloth_go/internal/fp256"
+)
+
+// limbs256 是小端排列的 4 个 64 位 limb
+type limbs256 = [4]uint64
+
+// Field256 是不超过 256 位模数的定长 limb 后端
+// 与 MontgomeryField 相同，乘法与幂运算在 Montgomery 形式 (R = 2^256) 下进行，
+// 但所有中间值都是栈上的 [4]uint64，乘法与平方由 internal/fp256 的定长内核完成 (amd64 上为汇编)，
+// 幂运算过程中没有任何堆分配
+type Field256 struct {
+	*PrimeField
+
+	m    limbs256 // p
+	mInv uint64   // -p⁻¹ mod 2^64
+	rr   limbs256 // R² mod p
+	one  limbs256 // R mod p，即 Montgomery 形式的 1
+
+	sqrtChain *expSchedule // (p+1)/4 的滑动窗口调度，仅 p ≡ 3 (mod 4) 时非 nil
+}
+
+var (
+	_ Field        = (*Field256)(nil)
+	_ CombinedSqrt = (*Field256)(nil)
+)
+
+// NewField256 创建模 p 的定长 limb 域，要求 p 是不超过 256 位的奇素数
+func NewField256(p *big.Int) (*Field256, error) {
+	if p != nil && p.BitLen() > 256 {
+		return nil, errors.New("p must be at most 256 bits")
+	}
+	pf, err := NewPrimeField(p)
+	if err != nil {
+		return nil, err
+	}
+	f := &Field256{PrimeField: pf, m: toLimbs256(p)}
+
+	// Newton 迭代求 p⁻¹ mod 2^64
+	inv := uint64(1)
+	for range 6 {
+		inv *= 2 - f.m[0]*inv
+	}
+	f.mInv = -inv
+
+	r := new(big.Int).Lsh(bigOne, 256)
+	f.one = toLimbs256(new(big.Int).Mod(r, p))
+	rr := new(big.Int).Lsh(bigOne, 512)
+	f.rr = toLimbs256(rr.Mod(rr, p))
+	if pf.sqrtExp != nil {
+		f.sqrtChain = newExpSchedule(pf.sqrtExp)
+	}
+	return f, nil
+}
+
+// toLimbs256 把不超过 256 位的非负整数转换为 limb
+func toLimbs256(a *big.Int) (z limbs256) {
+	var buf [32]byte
+	a.FillBytes(buf[:])
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+	return z
+}
+
+// setLimbs256 把 limb 写回 z
+func setLimbs256(z *big.Int, x *limbs256) *big.Int {
+	var buf [32]byte
+	for i, w := range x {
+		binary.BigEndian.PutUint64(buf[24-8*i:], w)
+	}
+	return z.SetBytes(buf[:])
+}
+
+// montMul 计算 x·y·R⁻¹ mod p
+func (f *Field256) montMul(x, y *limbs256) (z limbs256) {
+	fp256.MontMul(&z, x, y, &f.m, f.mInv)
+	return z
+}
+
+// montSqr 计算 x²·R⁻¹ mod p
+func (f *Field256) montSqr(x *limbs256) (z limbs256) {
+	fp256.MontSqr(&z, x, &f.m, f.mInv)
+	return z
+}
+
+// canonical 返回 a mod p (a 已在范围内时直接返回 a)
+func (f *Field256) canonical(a *big.Int) *big.Int {
+	if a.Sign() < 0 || a.Cmp(f.p) >= 0 {
+		return new(big.Int).Mod(a, f.p)
+	}
+	return a
+}
+
+// toMont 返回 a·R mod p
+func (f *Field256) toMont(a *big.Int) limbs256 {
+	x := toLimbs256(f.canonical(a))
+	return f.montMul(&x, &f.rr)
+}
+
+// fromMont 把 Montgomery 形式的 x 转换回普通形式并写入 z
+func (f *Field256) fromMont(z *big.Int, x *limbs256) *big.Int {
+	t := [8]uint64{x[0], x[1], x[2], x[3]}
+	r := fp256.Reduce(&t, &f.m, f.mInv)
+	return setLimbs256(z, &r)
+}
+
+// montExp 计算 x^e (均为 Montgomery 形式)，使用 4 位固定窗口
+func (f *Field256) montExp(x *limbs256, e *big.Int) limbs256 {
+	var table [16]limbs256
+	table[0] = f.one
+	table[1] = *x
+	for i := 2; i < len(table); i++ {
+		table[i] = f.montMul(&table[i-1], x)
+	}
+	acc := f.one
+	for i := (e.BitLen() + 3) / 4 * 4; i > 0; i -= 4 {
+		for range 4 {
+			acc = f.montSqr(&acc)
+		}
+		if nibble := e.Bit(i-1)<<3 | e.Bit(i-2)<<2 | e.Bit(i-3)<<1 | e.Bit(i-4); nibble != 0 {
+			acc = f.montMul(&acc, &table[nibble])
+		}
+	}
+	return acc
+}
+
+// montExpSchedule 按预计算的滑动窗口调度计算 x^e (均为 Montgomery 形式)
+func (f *Field256) montExpSchedule(x *limbs256, s *expSchedule) limbs256 {
+	var table [1 << (maxWindow - 1)]limbs256
+	table[0] = *x
+	if n := s.tableSize(); n > 1 {
+		x2 := f.montSqr(x)
+		for i := 1; i < n; i++ {
+			table[i] = f.montMul(&table[i-1], &x2)
+		}
+	}
+	acc := table[s.first]
+	for _, st := range s.steps {
+		for range st.sqr {
+			acc = f.montSqr(&acc)
+		}
+		acc = f.montMul(&acc, &table[st.idx])
+	}
+	for range s.tail {
+		acc = f.montSqr(&acc)
+	}
+	return acc
+}
+
+// Mul 计算 (a·R)·b·R⁻¹ = a·b
+func (f *Field256) Mul(z, a, b *big.Int) *big.Int {
+	x := f.toMont(a)
+	y := toLimbs256(f.canonical(b))
+	r := f.montMul(&x, &y)
+	return setLimbs256(z, &r)
+}
+
+// Square 先用 montSqr 得到 a²·R⁻¹，再乘以 R² 消去 R⁻¹
+func (f *Field256) Square(z, a *big.Int) *big.Int {
+	x := toLimbs256(f.canonical(a))
+	r := f.montSqr(&x)
+	r = f.montMul(&r, &f.rr)
+	return setLimbs256(z, &r)
+}
+
+func (f *Field256) Exp(z, a, e *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		panic(errors.New("group: negative exponent"))
+	}
+	x := f.toMont(a)
+	r := f.montExp(&x, e)
+	return f.fromMont(z, &r)
+}
+
+// Sqrt 在 p ≡ 3 (mod 4) 时用一次幂运算同时完成开方与二次剩余判定
+func (f *Field256) Sqrt(z, a *big.Int) bool {
+	if f.sqrtExp == nil {
+		return f.PrimeField.Sqrt(z, a)
+	}
+	x := f.toMont(a)
+	r := f.montExpSchedule(&x, f.sqrtChain)
+	if f.montSqr(&r) != x {
+		return false
+	}
+	f.fromMont(z, &r)
+	return true
+}
+
+// SqrtOrNeg 实现 CombinedSqrt，要求 p ≡ 3 (mod 4)
+func (f *Field256) SqrtOrNeg(z, a *big.Int) bool {
+	x := f.toMont(a)
+	r := f.montExpSchedule(&x, f.sqrtChain)
+	residue := f.montSqr(&r) == x
+	f.fromMont(z, &r)
+	return residue
+}
+
+// Elem256 是 Field256 元素的定长表示: 普通形式 (非 Montgomery 形式) 的小端 limb
+// 需要连续做大量运算的调用方 (例如批量验证) 可以直接在 Elem256 上计算，省去每一步与 big.Int 之间的转换
+type Elem256 = [4]uint64
+
+// Elem 把 a mod p 转换为 Elem256
+func (f *Field256) Elem(a *big.Int) Elem256 {
+	return toLimbs256(f.canonical(a))
+}
+
+// SetElem 把 x 写回 z
+func (f *Field256) SetElem(z *big.Int, x *Elem256) *big.Int {
+	return setLimbs256(z, x)
+}
+
+// SquareElems 原地计算 xs 中每个元素的平方，结果仍是普通形式
+func (f *Field256) SquareElems(xs []Elem256) {
+	for i := range xs {
+		r := f.montSqr(&xs[i])
+		xs[i] = f.montMul(&r, &f.rr)
+	}
+}
+
+// NegElem 原地计算 x = -x
+func (f *Field256) NegElem(x *Elem256) {
+	if x[0]|x[1]|x[2]|x[3] == 0 {
+		return
+	}
+	var b uint64
+	x[0], b = bits.Sub64(f.m[0], x[0], 0)
+	x[1], b = bits.Sub64(f.m[1], x[1], b)
+	x[2], b = bits.Sub64(f.m[2], x[2], b)
+	x[3], _ = bits.Sub64(f.m[3], x[3], b)
+}
@@ -0,0 +1,114 @@
+package group
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// TestField256 把 Field256 与 PrimeField 的结果逐一比较，包括恰好 256 位、接近 2^256 的模数
+func TestField256(t *testing.T) {
+	p256 := new(big.Int).Lsh(bigOne, 256)
+	p256.Sub(p256, big.NewInt(189)) // 2^256 - 189
+	primes := []*big.Int{big.NewInt(1000003), big.NewInt(1000033), p256}
+	for _, bits := range []int{64, 127, 192, 255, 256} {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		primes = append(primes, p)
+	}
+
+	for _, p := range primes {
+		f, err := NewField256(p)
+		if err != nil {
+			t.Fatalf("NewField256(%x) failed: %v", p, err)
+		}
+		pf, _ := NewPrimeField(p)
+		pm1 := new(big.Int).Sub(p, bigOne)
+		values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), pm1}
+		for range 20 {
+			a, _ := rand.Int(rand.Reader, p)
+			values = append(values, a)
+		}
+		for i, a := range values {
+			b := values[(i+1)%len(values)]
+			if got, want := f.Mul(new(big.Int), a, b), pf.Mul(new(big.Int), a, b); got.Cmp(want) != 0 {
+				t.Fatalf("p=%x: Mul(%x, %x) = %x, want %x", p, a, b, got, want)
+			}
+			if got, want := f.Square(new(big.Int), a), pf.Square(new(big.Int), a); got.Cmp(want) != 0 {
+				t.Fatalf("p=%x: Square(%x) = %x, want %x", p, a, got, want)
+			}
+			if got, want := f.Exp(new(big.Int), a, b), pf.Exp(new(big.Int), a, b); got.Cmp(want) != 0 {
+				t.Fatalf("p=%x: Exp mismatch", p)
+			}
+			root := new(big.Int)
+			ok := f.Sqrt(root, a)
+			if ok != (pf.Legendre(a) >= 0) {
+				t.Fatalf("p=%x: Sqrt residuosity mismatch for %x", p, a)
+			}
+			if ok && f.Square(new(big.Int), root).Cmp(a) != 0 {
+				t.Fatalf("p=%x: Sqrt(%x)² != a", p, a)
+			}
+		}
+	}
+
+	if _, err := NewField256(new(big.Int).Lsh(p256, 1).Add(p256, p256)); err == nil {
+		t.Error("expected error for a modulus over 256 bits")
+	}
+}
+
+// TestNewField 检查按模数大小自动选择的后端
+func TestNewField(t *testing.T) {
+	large := "*group.PrimeField"
+	if GMPAvailable {
+		large = "*group.GMPField"
+	}
+	for _, c := range []struct {
+		bits int
+		want string
+	}{{256, "*group.Field256"}, {320, "*group.MontgomeryField"}, {512, large}} {
+		p, _ := rand.Prime(rand.Reader, c.bits)
+		f, err := NewField(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprintf("%T", f); got != c.want {
+			t.Errorf("NewField(%d bits) = %s, want %s", c.bits, got, c.want)
+		}
+	}
+}
+
+func BenchmarkField256_Sqrt(b *testing.B) {
+	p := new(big.Int).Lsh(bigOne, 256)
+	p.Sub(p, big.NewInt(189))
+	f, _ := NewField256(p)
+	a := new(big.Int).Sub(p, big.NewInt(12345))
+	z := new(big.Int)
+	for range b.N {
+		f.SqrtOrNeg(z, a)
+	}
+}
+
+func BenchmarkMontgomeryField_Sqrt(b *testing.B) {
+	p := new(big.Int).Lsh(bigOne, 256)
+	p.Sub(p, big.NewInt(189))
+	f, _ := NewMontgomeryField(p)
+	a := new(big.Int).Sub(p, big.NewInt(12345))
+	z := new(big.Int)
+	for range b.N {
+		f.SqrtOrNeg(z, a)
+	}
+}
+
+func BenchmarkPrimeField_Sqrt(b *testing.B) {
+	p := new(big.Int).Lsh(bigOne, 256)
+	p.Sub(p, big.NewInt(189))
+	f, _ := NewPrimeField(p)
+	a := new(big.Int).Sub(p, big.NewInt(12345))
+	z := new(big.Int)
+	for range b.N {
+		f.SqrtOrNeg(z, a)
+	}
+}
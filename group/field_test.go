@@ -0,0 +1,51 @@
+package group
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestPrimeField_Sqrt 检查 p ≡ 3 (mod 4) 与 p ≡ 1 (mod 4) 两种情况下的平方根
+func TestPrimeField_Sqrt(t *testing.T) {
+	for _, p := range []int64{1000003, 1000033} { // 分别为 3 (mod 4) 与 1 (mod 4)
+		f, err := NewPrimeField(big.NewInt(p))
+		if err != nil {
+			t.Fatalf("NewPrimeField(%d) failed: %v", p, err)
+		}
+		residues, nonResidues := 0, 0
+		for a := int64(0); a < 2000; a++ {
+			x := big.NewInt(a)
+			root := new(big.Int)
+			if !f.Sqrt(root, x) {
+				nonResidues++
+				if f.Legendre(x) != -1 {
+					t.Fatalf("p=%d: Sqrt(%d) failed for a residue", p, a)
+				}
+				continue
+			}
+			residues++
+			if f.Square(new(big.Int), root).Cmp(x) != 0 {
+				t.Fatalf("p=%d: Sqrt(%d)² != %d", p, a, a)
+			}
+		}
+		if residues == 0 || nonResidues == 0 {
+			t.Fatalf("p=%d: expected both residues and non-residues", p)
+		}
+	}
+}
+
+// TestPrimeField_EncodeDecode 检查定长编码往返以及越界元素的拒绝
+func TestPrimeField_EncodeDecode(t *testing.T) {
+	f, err := NewPrimeField(big.NewInt(1000003))
+	if err != nil {
+		t.Fatalf("NewPrimeField failed: %v", err)
+	}
+	x := big.NewInt(42)
+	y, err := f.Decode(f.Encode(x))
+	if err != nil || y.Cmp(x) != 0 {
+		t.Fatalf("round trip failed: %v, %v", y, err)
+	}
+	if _, err := f.Decode(f.Encode(big.NewInt(1000003))); err == nil {
+		t.Error("expected error for element >= p")
+	}
+}
@@ -0,0 +1,239 @@
+//go:build cgo && gmp
+
+package group
+
+/*
+#cgo LDFLAGS: -lgmp
+#include <stdlib.h>
+#include <gmp.h>
+
+// 所有整数在 Go 与 C 之间都以大端字节串传递，每个运算只跨越一次 cgo 边界
+
+static mpz_ptr slothgo_mpz_new(const unsigned char *buf, size_t n) {
+	mpz_ptr x = malloc(sizeof(__mpz_struct));
+	mpz_init(x);
+	mpz_import(x, n, 1, 1, 1, 0, buf);
+	return x;
+}
+
+static void slothgo_mpz_free(mpz_ptr x) {
+	mpz_clear(x);
+	free(x);
+}
+
+static size_t slothgo_export(unsigned char *out, mpz_srcptr x) {
+	size_t n = 0;
+	mpz_export(out, &n, 1, 1, 1, 0, x);
+	return n;
+}
+
+// out = a^e mod m
+static size_t slothgo_powm(unsigned char *out, const unsigned char *a, size_t alen,
+		const unsigned char *e, size_t elen, mpz_srcptr m) {
+	mpz_t x, y;
+	mpz_init(x);
+	mpz_init(y);
+	mpz_import(x, alen, 1, 1, 1, 0, a);
+	mpz_import(y, elen, 1, 1, 1, 0, e);
+	mpz_powm(x, x, y, m);
+	size_t n = slothgo_export(out, x);
+	mpz_clear(x);
+	mpz_clear(y);
+	return n;
+}
+
+// out = a·b mod m
+static size_t slothgo_mulmod(unsigned char *out, const unsigned char *a, size_t alen,
+		const unsigned char *b, size_t blen, mpz_srcptr m) {
+	mpz_t x, y;
+	mpz_init(x);
+	mpz_init(y);
+	mpz_import(x, alen, 1, 1, 1, 0, a);
+	mpz_import(y, blen, 1, 1, 1, 0, b);
+	mpz_mul(x, x, y);
+	mpz_mod(x, x, m);
+	size_t n = slothgo_export(out, x);
+	mpz_clear(x);
+	mpz_clear(y);
+	return n;
+}
+
+// out = a^e mod m，其中 e = (m+1)/4; 返回 out² ≡ a 是否成立
+static int slothgo_sqrt3(unsigned char *out, size_t *outlen, const unsigned char *a, size_t alen,
+		mpz_srcptr e, mpz_srcptr m) {
+	mpz_t x, r;
+	mpz_init(x);
+	mpz_init(r);
+	mpz_import(x, alen, 1, 1, 1, 0, a);
+	mpz_mod(x, x, m);
+	mpz_powm(r, x, e, m);
+	*outlen = slothgo_export(out, r);
+	mpz_mul(r, r, r);
+	mpz_mod(r, r, m);
+	int residue = mpz_cmp(r, x) == 0;
+	mpz_clear(x);
+	mpz_clear(r);
+	return residue;
+}
+
+static int slothgo_jacobi(const unsigned char *a, size_t alen, mpz_srcptr m) {
+	mpz_t x;
+	mpz_init(x);
+	mpz_import(x, alen, 1, 1, 1, 0, a);
+	int j = mpz_jacobi(x, m);
+	mpz_clear(x);
+	return j;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// GMPAvailable 报告当前构建是否包含 GMP 后端
+const GMPAvailable = true
+
+// GMPField 使用 GMP 的 mpz_powm、mpz_jacobi 等函数实现幂运算与勒让德符号，
+// 在大模数上比 math/big 快得多。只在同时启用 cgo 与 gmp 构建标签时可用
+type GMPField struct {
+	*PrimeField
+
+	m *C.__mpz_struct // C 堆上的 p
+	e *C.__mpz_struct // C 堆上的 (p+1)/4，仅 p ≡ 3 (mod 4) 时非 nil
+
+	bufs sync.Pool // *gmpScratch
+}
+
+// gmpScratch 是一次运算在 Go 与 C 之间传递参数和结果的缓冲区，每个长度均为 ElementSize()
+type gmpScratch struct {
+	x, y, out []byte
+}
+
+func (f *GMPField) getScratch() *gmpScratch {
+	if sc, ok := f.bufs.Get().(*gmpScratch); ok {
+		return sc
+	}
+	n := f.ElementSize()
+	return &gmpScratch{x: make([]byte, n), y: make([]byte, n), out: make([]byte, n)}
+}
+
+var (
+	_ Field        = (*GMPField)(nil)
+	_ CombinedSqrt = (*GMPField)(nil)
+)
+
+// NewGMPField 创建模 p 的 GMP 域，调用方需保证 p 是奇素数
+func NewGMPField(p *big.Int) (Field, error) {
+	pf, err := NewPrimeField(p)
+	if err != nil {
+		return nil, err
+	}
+	f := &GMPField{PrimeField: pf, m: newMpz(p)}
+	if pf.sqrtExp != nil {
+		f.e = newMpz(pf.sqrtExp)
+	}
+	runtime.AddCleanup(f, func(xs [2]*C.__mpz_struct) {
+		for _, x := range xs {
+			if x != nil {
+				C.slothgo_mpz_free(x)
+			}
+		}
+	}, [2]*C.__mpz_struct{f.m, f.e})
+	return f, nil
+}
+
+// newLargeField 是 NewField 对超过 320 位的模数选择的后端
+func newLargeField(p *big.Int) (Field, error) {
+	return NewGMPField(p)
+}
+
+func newMpz(a *big.Int) *C.__mpz_struct {
+	buf := a.Bytes()
+	return C.slothgo_mpz_new(bytesPtr(buf), C.size_t(len(buf)))
+}
+
+// bytesPtr 返回 buf 的首地址，空切片返回一个有效的占位地址
+func bytesPtr(buf []byte) *C.uchar {
+	if len(buf) == 0 {
+		buf = []byte{0}
+	}
+	return (*C.uchar)(unsafe.Pointer(&buf[0]))
+}
+
+// canonical 把 a mod p 以定长大端形式写入 buf 并返回 buf
+func (f *GMPField) canonical(buf []byte, a *big.Int) []byte {
+	if a.Sign() < 0 || a.Cmp(f.p) >= 0 {
+		a = new(big.Int).Mod(a, f.p)
+	}
+	return a.FillBytes(buf)
+}
+
+func (f *GMPField) Mul(z, a, b *big.Int) *big.Int {
+	sc := f.getScratch()
+	defer f.bufs.Put(sc)
+	x, y := f.canonical(sc.x, a), f.canonical(sc.y, b)
+	n := C.slothgo_mulmod(bytesPtr(sc.out), bytesPtr(x), C.size_t(len(x)), bytesPtr(y), C.size_t(len(y)), f.m)
+	return z.SetBytes(sc.out[:n])
+}
+
+func (f *GMPField) Square(z, a *big.Int) *big.Int {
+	return f.Mul(z, a, a)
+}
+
+func (f *GMPField) Exp(z, a, e *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		panic(errors.New("group: negative exponent"))
+	}
+	sc := f.getScratch()
+	defer f.bufs.Put(sc)
+	x, y := f.canonical(sc.x, a), e.Bytes()
+	n := C.slothgo_powm(bytesPtr(sc.out), bytesPtr(x), C.size_t(len(x)), bytesPtr(y), C.size_t(len(y)), f.m)
+	return z.SetBytes(sc.out[:n])
+}
+
+func (f *GMPField) Legendre(a *big.Int) int {
+	sc := f.getScratch()
+	defer f.bufs.Put(sc)
+	x := f.canonical(sc.x, a)
+	return int(C.slothgo_jacobi(bytesPtr(x), C.size_t(len(x)), f.m))
+}
+
+// Sqrt 在 p ≡ 3 (mod 4) 时用一次 mpz_powm 同时完成开方与二次剩余判定，否则使用 Tonelli–Shanks
+func (f *GMPField) Sqrt(z, a *big.Int) bool {
+	if f.e == nil {
+		if f.Legendre(a) == -1 {
+			return false
+		}
+		f.tonelliShanks(z, a)
+		return true
+	}
+	sc := f.getScratch()
+	defer f.bufs.Put(sc)
+	n, residue := f.sqrt3(a, sc)
+	if residue {
+		z.SetBytes(sc.out[:n])
+	}
+	return residue
+}
+
+// SqrtOrNeg 实现 CombinedSqrt，要求 p ≡ 3 (mod 4)
+func (f *GMPField) SqrtOrNeg(z, a *big.Int) bool {
+	sc := f.getScratch()
+	defer f.bufs.Put(sc)
+	n, residue := f.sqrt3(a, sc)
+	z.SetBytes(sc.out[:n])
+	return residue
+}
+
+// sqrt3 把 a^((p+1)/4) 写入 sc.out，返回结果的字节数以及 a 是否为二次剩余
+func (f *GMPField) sqrt3(a *big.Int, sc *gmpScratch) (int, bool) {
+	x := f.canonical(sc.x, a)
+	var n C.size_t
+	residue := C.slothgo_sqrt3(bytesPtr(sc.out), &n, bytesPtr(x), C.size_t(len(x)), f.e, f.m)
+	return int(n), residue != 0
+}
@@ -0,0 +1,18 @@
+//go:build !cgo || !gmp
+
+package group
+
+import "math/big"
+
+// GMPAvailable 报告当前构建是否包含 GMP 后端
+const GMPAvailable = false
+
+// NewGMPField 在未启用 cgo 或 gmp 构建标签时退回纯 Go 后端，等价于 NewField
+func NewGMPField(p *big.Int) (Field, error) {
+	return NewField(p)
+}
+
+// newLargeField 是 NewField 对超过 320 位的模数选择的后端
+func newLargeField(p *big.Int) (Field, error) {
+	return NewPrimeField(p)
+}
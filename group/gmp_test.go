@@ -0,0 +1,42 @@
+package group
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// TestGMPField 把 NewGMPField 返回的后端与 PrimeField 比较; 未启用 gmp 构建标签时检查的是纯 Go 的回退后端
+func TestGMPField(t *testing.T) {
+	for _, bits := range []int{64, 256, 1024, 2048} {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := NewGMPField(p)
+		if err != nil {
+			t.Fatalf("NewGMPField(%d bits) failed: %v", bits, err)
+		}
+		pf, _ := NewPrimeField(p)
+		for range 10 {
+			a, _ := rand.Int(rand.Reader, p)
+			b, _ := rand.Int(rand.Reader, p)
+			if got, want := f.Mul(new(big.Int), a, b), pf.Mul(new(big.Int), a, b); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: Mul mismatch", bits)
+			}
+			if got, want := f.Exp(new(big.Int), a, b), pf.Exp(new(big.Int), a, b); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: Exp mismatch", bits)
+			}
+			if f.Legendre(a) != pf.Legendre(a) {
+				t.Fatalf("%d bits: Legendre mismatch", bits)
+			}
+			root := new(big.Int)
+			if ok := f.Sqrt(root, a); ok != (pf.Legendre(a) >= 0) || ok && f.Square(new(big.Int), root).Cmp(a) != 0 {
+				t.Fatalf("%d bits: Sqrt is wrong", bits)
+			}
+		}
+		if f.Exp(new(big.Int), big.NewInt(0), big.NewInt(0)).Cmp(bigOne) != 0 {
+			t.Fatalf("%d bits: 0^0 != 1", bits)
+		}
+	}
+}
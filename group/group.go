@@ -0,0 +1,30 @@
+// Package group 定义 VDF 构造所使用的群运算抽象
+// Wesolowski 和 Pietrzak 等基于重复平方的构造只依赖这里的接口，
+// 因此可以在 RSA 群 (需要可信模数) 与类群 (无需可信参数) 之间自由切换
+package group
+
+import "math/big"
+
+// Group 是元素类型为 E 的交换群
+type Group[E any] interface {
+	// Identity 返回单位元
+	Identity() E
+	// Mul 返回 a·b
+	Mul(a, b E) E
+	// Square 返回 a²
+	Square(a E) E
+	// Exp 返回 a^e, e >= 0
+	Exp(a E, e *big.Int) E
+	// Equal 判断两个元素是否相等
+	Equal(a, b E) bool
+	// Validate 检查 a 是否是群中合法的 (规范表示的) 元素
+	Validate(a E) error
+	// HashToElement 把任意数据确定性地映射为群元素
+	HashToElement(domain string, data []byte) E
+	// Encode 把元素编码为长度为 ElementSize() 的字节数组
+	Encode(a E) []byte
+	// Decode 是 Encode 的逆运算，并会校验元素的合法性
+	Decode(data []byte) (E, error)
+	// ElementSize 返回编码后元素的字节数
+	ElementSize() int
+}
@@ -0,0 +1,297 @@
+package group
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// MontgomeryField 是以 Montgomery 形式 (x·R mod p，R = 2^(64n)) 做乘法与幂运算的 Field 实现
+// 元素在接口上仍以 [0, p-1] 内的 *big.Int 表示，只在每次运算的入口与出口做一次 Montgomery 乘法转换;
+// 幂运算的中间值全程保持 Montgomery 形式，不经过 big.Int 的除法。所有 limb 缓冲区都取自 sync.Pool，
+// 稳定运行后每次运算没有堆分配，并且可以被多个 goroutine 同时使用。
+// 加减法、编码等其余运算直接复用 PrimeField
+type MontgomeryField struct {
+	*PrimeField
+
+	n    int      // limb 个数
+	m    []uint64 // p 的小端 limb
+	mInv uint64   // -p⁻¹ mod 2^64
+	rr   []uint64 // R² mod p，用于转换到 Montgomery 形式
+	one  []uint64 // 整数 1，用于转换出 Montgomery 形式
+	oneM []uint64 // R mod p，即 Montgomery 形式的 1
+
+	sqrtChain *expSchedule // (p+1)/4 的滑动窗口调度，仅 p ≡ 3 (mod 4) 时非 nil
+
+	pool sync.Pool // *montScratch
+}
+
+// montScratch 是一次运算使用的全部缓冲区
+type montScratch struct {
+	x, y, r []uint64
+	t       []uint64 // n+2 个 limb
+	table   [16][]uint64
+	buf     []byte // 8n 字节，用于与 big.Int 互相转换
+}
+
+var (
+	_ Field        = (*MontgomeryField)(nil)
+	_ CombinedSqrt = (*MontgomeryField)(nil)
+	_ CombinedSqrt = (*PrimeField)(nil)
+)
+
+// montgomeryMaxBits 是 NewField 选择 MontgomeryField 的最大模数位数
+// 在更大的模数上，math/big 带汇编内层循环的幂运算比这里的纯 Go 实现更快
+const montgomeryMaxBits = 320
+
+// NewField 为 p 选择最快的算术后端: 不超过 256 位时使用 Field256，不超过 320 位时使用 MontgomeryField，
+// 否则使用 PrimeField (以 cgo 与 gmp 构建标签编译时改用 GMPField)
+func NewField(p *big.Int) (Field, error) {
+	switch {
+	case p == nil:
+		return NewPrimeField(p)
+	case p.BitLen() <= 256:
+		return NewField256(p)
+	case p.BitLen() <= montgomeryMaxBits:
+		return NewMontgomeryField(p)
+	}
+	return newLargeField(p)
+}
+
+// NewMontgomeryField 创建模 p 的 Montgomery 域，调用方需保证 p 是奇素数
+func NewMontgomeryField(p *big.Int) (*MontgomeryField, error) {
+	pf, err := NewPrimeField(p)
+	if err != nil {
+		return nil, err
+	}
+	n := (p.BitLen() + 63) / 64
+	f := &MontgomeryField{PrimeField: pf, n: n, one: make([]uint64, n)}
+	f.pool.New = func() any {
+		sc := &montScratch{
+			x:   make([]uint64, n),
+			y:   make([]uint64, n),
+			r:   make([]uint64, n),
+			t:   make([]uint64, n+2),
+			buf: make([]byte, 8*n),
+		}
+		for i := range sc.table {
+			sc.table[i] = make([]uint64, n)
+		}
+		return sc
+	}
+	f.m = f.limbs(make([]uint64, n), p, make([]byte, 8*n))
+	f.one[0] = 1
+
+	// Newton 迭代求 p⁻¹ mod 2^64: 每次迭代正确的位数翻倍
+	inv := uint64(1)
+	for range 6 {
+		inv *= 2 - f.m[0]*inv
+	}
+	f.mInv = -inv
+
+	r := new(big.Int).Lsh(bigOne, uint(64*n))
+	f.oneM = f.limbs(make([]uint64, n), new(big.Int).Mod(r, p), make([]byte, 8*n))
+	rr := new(big.Int).Lsh(bigOne, uint(128*n))
+	f.rr = f.limbs(make([]uint64, n), rr.Mod(rr, p), make([]byte, 8*n))
+	if pf.sqrtExp != nil {
+		f.sqrtChain = newExpSchedule(pf.sqrtExp)
+	}
+	return f, nil
+}
+
+// limbs 把 [0, p-1] 内的 a 转换为小端 limb 写入 z，buf 是 8n 字节的临时缓冲区
+func (f *MontgomeryField) limbs(z []uint64, a *big.Int, buf []byte) []uint64 {
+	a.FillBytes(buf)
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(buf[len(buf)-8*(i+1):])
+	}
+	return z
+}
+
+// setLimbs 把小端 limb 写回 z
+func (f *MontgomeryField) setLimbs(z *big.Int, x []uint64, buf []byte) *big.Int {
+	for i, w := range x {
+		binary.BigEndian.PutUint64(buf[len(buf)-8*(i+1):], w)
+	}
+	return z.SetBytes(buf)
+}
+
+// toMont 把 a·R mod p 写入 z
+func (f *MontgomeryField) toMont(z []uint64, a *big.Int, sc *montScratch) {
+	f.limbs(z, f.reduce(a), sc.buf)
+	f.montMul(z, z, f.rr, sc.t)
+}
+
+// fromMont 把 Montgomery 形式的 x 转换回普通形式并写入 z，会覆盖 sc.y
+func (f *MontgomeryField) fromMont(z *big.Int, x []uint64, sc *montScratch) *big.Int {
+	f.montMul(sc.y, x, f.one, sc.t)
+	return f.setLimbs(z, sc.y, sc.buf)
+}
+
+// montMul 用 CIOS 算法计算 z = x·y·R⁻¹ mod p，t 是长度为 n+2 的临时缓冲区
+// z 可以与 x 或 y 相同
+func (f *MontgomeryField) montMul(z, x, y, t []uint64) {
+	n, m := f.n, f.m
+	x, y, t = x[:n], y[:n], t[:n+2] // 让编译器消除内层循环的边界检查
+	clear(t)
+	for i := range n {
+		// t += x·y[i]
+		var c uint64
+		for j := range n {
+			hi, lo := bits.Mul64(x[j], y[i])
+			lo, cc := bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		var cc uint64
+		t[n], cc = bits.Add64(t[n], c, 0)
+		t[n+1] = cc
+
+		// t = (t + q·p) / 2^64，q 使 t 的最低 limb 为 0
+		q := t[0] * f.mInv
+		hi, lo := bits.Mul64(q, m[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo := bits.Mul64(q, m[j])
+			lo, cc := bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[n-1], cc = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + cc
+	}
+
+	// 结果小于 2p，最多再减一次 p
+	var b uint64
+	for j := range n {
+		z[j], b = bits.Sub64(t[j], m[j], b)
+	}
+	if t[n] < b { // t < p，撤销减法
+		copy(z, t[:n])
+	}
+}
+
+// montExp 计算 z = x^e (均为 Montgomery 形式)，使用 4 位固定窗口; z 不能与 x 相同
+func (f *MontgomeryField) montExp(z, x []uint64, e *big.Int, sc *montScratch) {
+	table := &sc.table
+	copy(table[0], f.oneM)
+	copy(table[1], x)
+	for i := 2; i < len(table); i++ {
+		f.montMul(table[i], table[i-1], x, sc.t)
+	}
+
+	copy(z, f.oneM)
+	for i := (e.BitLen() + 3) / 4 * 4; i > 0; i -= 4 {
+		for range 4 {
+			f.montMul(z, z, z, sc.t)
+		}
+		nibble := e.Bit(i-1)<<3 | e.Bit(i-2)<<2 | e.Bit(i-3)<<1 | e.Bit(i-4)
+		if nibble != 0 {
+			f.montMul(z, z, table[nibble], sc.t)
+		}
+	}
+}
+
+// montExpSchedule 按预计算的滑动窗口调度计算 z = x^e (均为 Montgomery 形式); z 不能与 x 相同
+// 预计算表中存放 x 的奇数次幂，sc.y 用作 x² 的临时值
+func (f *MontgomeryField) montExpSchedule(z, x []uint64, s *expSchedule, sc *montScratch) {
+	table := &sc.table
+	copy(table[0], x)
+	if n := s.tableSize(); n > 1 {
+		f.montMul(sc.y, x, x, sc.t)
+		for i := 1; i < n; i++ {
+			f.montMul(table[i], table[i-1], sc.y, sc.t)
+		}
+	}
+	copy(z, table[s.first])
+	for _, st := range s.steps {
+		for range st.sqr {
+			f.montMul(z, z, z, sc.t)
+		}
+		f.montMul(z, z, table[st.idx], sc.t)
+	}
+	for range s.tail {
+		f.montMul(z, z, z, sc.t)
+	}
+}
+
+// Mul 计算 (a·R)·b·R⁻¹ = a·b，只需两次 Montgomery 乘法
+func (f *MontgomeryField) Mul(z, a, b *big.Int) *big.Int {
+	sc := f.pool.Get().(*montScratch)
+	defer f.pool.Put(sc)
+	f.toMont(sc.x, a, sc)
+	f.limbs(sc.y, f.reduce(b), sc.buf)
+	f.montMul(sc.x, sc.x, sc.y, sc.t)
+	return f.setLimbs(z, sc.x, sc.buf)
+}
+
+func (f *MontgomeryField) Square(z, a *big.Int) *big.Int {
+	return f.Mul(z, a, a)
+}
+
+func (f *MontgomeryField) Exp(z, a, e *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		panic(errors.New("group: negative exponent"))
+	}
+	sc := f.pool.Get().(*montScratch)
+	defer f.pool.Put(sc)
+	f.toMont(sc.x, a, sc)
+	f.montExp(sc.r, sc.x, e, sc)
+	return f.fromMont(z, sc.r, sc)
+}
+
+// Sqrt 在 p ≡ 3 (mod 4) 时用一次幂运算同时完成开方与二次剩余判定，不调用 Jacobi
+func (f *MontgomeryField) Sqrt(z, a *big.Int) bool {
+	if f.sqrtExp == nil {
+		return f.PrimeField.Sqrt(z, a)
+	}
+	sc := f.pool.Get().(*montScratch)
+	defer f.pool.Put(sc)
+	if !f.sqrtOrNeg(a, sc) {
+		return false
+	}
+	f.fromMont(z, sc.r, sc)
+	return true
+}
+
+// SqrtOrNeg 实现 CombinedSqrt，要求 p ≡ 3 (mod 4)
+func (f *MontgomeryField) SqrtOrNeg(z, a *big.Int) bool {
+	sc := f.pool.Get().(*montScratch)
+	defer f.pool.Put(sc)
+	residue := f.sqrtOrNeg(a, sc)
+	f.fromMont(z, sc.r, sc)
+	return residue
+}
+
+// sqrtOrNeg 把 a^((p+1)/4) 的 Montgomery 形式写入 sc.r，并返回 a 是否为二次剩余
+func (f *MontgomeryField) sqrtOrNeg(a *big.Int, sc *montScratch) bool {
+	f.toMont(sc.x, a, sc)
+	f.montExpSchedule(sc.r, sc.x, f.sqrtChain, sc)
+	f.montMul(sc.y, sc.r, sc.r, sc.t)
+	return equalLimbs(sc.y, sc.x)
+}
+
+// reduce 返回 a mod p (a 已在范围内时直接返回 a)
+func (f *MontgomeryField) reduce(a *big.Int) *big.Int {
+	if a.Sign() < 0 || a.Cmp(f.p) >= 0 {
+		return new(big.Int).Mod(a, f.p)
+	}
+	return a
+}
+
+// equalLimbs 比较两个等长的 limb 数组
+func equalLimbs(x, y []uint64) bool {
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package group
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// TestMontgomeryField 在不同大小的模数上把 MontgomeryField 与 PrimeField 的结果逐一比较
+func TestMontgomeryField(t *testing.T) {
+	for _, bits := range []int{61, 64, 127, 256, 320, 521, 1024} {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mf, err := NewMontgomeryField(p)
+		if err != nil {
+			t.Fatalf("NewMontgomeryField(%d bits) failed: %v", bits, err)
+		}
+		pf, _ := NewPrimeField(p)
+
+		for range 20 {
+			a, _ := rand.Int(rand.Reader, p)
+			b, _ := rand.Int(rand.Reader, p)
+			e, _ := rand.Int(rand.Reader, p)
+			if got, want := mf.Mul(new(big.Int), a, b), pf.Mul(new(big.Int), a, b); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: Mul mismatch", bits)
+			}
+			if got, want := mf.Square(new(big.Int), a), pf.Square(new(big.Int), a); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: Square mismatch", bits)
+			}
+			if got, want := mf.Exp(new(big.Int), a, e), pf.Exp(new(big.Int), a, e); got.Cmp(want) != 0 {
+				t.Fatalf("%d bits: Exp mismatch", bits)
+			}
+
+			root := new(big.Int)
+			ok := mf.Sqrt(root, a)
+			if ok != (pf.Legendre(a) >= 0) {
+				t.Fatalf("%d bits: Sqrt residuosity mismatch", bits)
+			}
+			if ok && mf.Square(new(big.Int), root).Cmp(a) != 0 {
+				t.Fatalf("%d bits: Sqrt(a)² != a", bits)
+			}
+		}
+
+		// 边界值
+		pm1 := new(big.Int).Sub(p, bigOne)
+		if mf.Mul(new(big.Int), pm1, pm1).Cmp(bigOne) != 0 {
+			t.Fatalf("%d bits: (p-1)² != 1", bits)
+		}
+		if mf.Exp(new(big.Int), pm1, big.NewInt(0)).Cmp(bigOne) != 0 {
+			t.Fatalf("%d bits: a^0 != 1", bits)
+		}
+	}
+}
+
+// TestSqrtOrNeg 检查 p ≡ 3 (mod 4) 时 SqrtOrNeg 返回 a 或 -a 的平方根
+func TestSqrtOrNeg(t *testing.T) {
+	var p *big.Int
+	for p == nil || p.Bit(1) == 0 {
+		p, _ = rand.Prime(rand.Reader, 256)
+	}
+	mf, _ := NewMontgomeryField(p)
+	pf, _ := NewPrimeField(p)
+	for _, f := range []interface {
+		Field
+		CombinedSqrt
+	}{mf, pf} {
+		for a := int64(0); a < 100; a++ {
+			x := big.NewInt(a)
+			root := new(big.Int)
+			residue := f.SqrtOrNeg(root, x)
+			want := new(big.Int).Set(x)
+			if !residue {
+				f.Neg(want, want)
+			}
+			if residue != (f.Legendre(x) >= 0) || f.Square(new(big.Int), root).Cmp(want) != 0 {
+				t.Fatalf("%T: SqrtOrNeg(%d) is wrong", f, a)
+			}
+		}
+	}
+}
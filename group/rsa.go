@@ -0,0 +1,98 @@
+package group
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+var bigOne = big.NewInt(1)
+
+// RSA 是乘法群 (Z/NZ)*，其中 N 的分解必须未知
+type RSA struct {
+	N *big.Int
+}
+
+var _ Group[*big.Int] = (*RSA)(nil)
+
+// NewRSA 使用模数 n 创建 RSA 群
+func NewRSA(n *big.Int) (*RSA, error) {
+	if n == nil || n.Sign() <= 0 || n.Bit(0) == 0 {
+		return nil, errors.New("modulus must be a positive odd integer")
+	}
+	return &RSA{N: n}, nil
+}
+
+// GenerateModulus 生成一个 bits 位的 RSA 模数 N = p·q 并丢弃其分解
+// 调用方需要信任执行该函数的一方确实丢弃了 p 和 q
+func GenerateModulus(bits int) (*big.Int, error) {
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate prime: %w", err)
+		}
+		q, err := rand.Prime(rand.Reader, bits-bits/2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate prime: %w", err)
+		}
+		if p.Cmp(q) != 0 {
+			return new(big.Int).Mul(p, q), nil
+		}
+	}
+}
+
+func (g *RSA) Identity() *big.Int {
+	return big.NewInt(1)
+}
+
+func (g *RSA) Mul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, g.N)
+}
+
+func (g *RSA) Square(a *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, a)
+	return r.Mod(r, g.N)
+}
+
+func (g *RSA) Exp(a *big.Int, e *big.Int) *big.Int {
+	return new(big.Int).Exp(a, e, g.N)
+}
+
+func (g *RSA) Equal(a, b *big.Int) bool {
+	return a.Cmp(b) == 0
+}
+
+func (g *RSA) Validate(a *big.Int) error {
+	if a == nil || a.Sign() <= 0 || a.Cmp(g.N) >= 0 {
+		return errors.New("element must be in the range [1, N-1]")
+	}
+	return nil
+}
+
+// HashToElement 先扩展出比 N 多 128 位的哈希值再取模，以降低取模偏差
+func (g *RSA) HashToElement(domain string, data []byte) *big.Int {
+	return hashutil.ToModulus(domain, data, g.N)
+}
+
+func (g *RSA) Encode(a *big.Int) []byte {
+	return a.FillBytes(make([]byte, g.ElementSize()))
+}
+
+func (g *RSA) Decode(data []byte) (*big.Int, error) {
+	if len(data) != g.ElementSize() {
+		return nil, fmt.Errorf("element must be %d bytes", g.ElementSize())
+	}
+	a := new(big.Int).SetBytes(data)
+	if err := g.Validate(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (g *RSA) ElementSize() int {
+	return (g.N.BitLen() + 7) / 8
+}
@@ -0,0 +1,67 @@
+package slothgo
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+
+	"github.com/alan22333/sloth_go/internal/blake3"
+)
+
+// HashID 标识 Sloth 用于派生 w₀ 和计算最终输出的哈希函数
+// 哈希函数是参数的一部分，会写入 Fingerprint，因此验证方与计算方必须使用相同的哈希
+type HashID uint8
+
+const (
+	HashSHA256   HashID = iota // SHA-256，默认值
+	HashSHA3_256               // SHA3-256
+	HashSHA512                 // SHA-512
+	HashBLAKE3                 // BLAKE3 (32 字节输出)
+)
+
+// String 返回哈希函数的名称，该名称会写入参数指纹
+func (h HashID) String() string {
+	switch h {
+	case HashSHA256:
+		return "sha256"
+	case HashSHA3_256:
+		return "sha3-256"
+	case HashSHA512:
+		return "sha512"
+	case HashBLAKE3:
+		return "blake3"
+	}
+	return fmt.Sprintf("HashID(%d)", uint8(h))
+}
+
+// ParseHashID 是 String 的逆运算
+func ParseHashID(name string) (HashID, error) {
+	for h := HashSHA256; h.Available(); h++ {
+		if h.String() == name {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hash function %q", name)
+}
+
+// New 返回该哈希函数的一个新实例，未知的 HashID 返回 nil
+func (h HashID) New() hash.Hash {
+	switch h {
+	case HashSHA256:
+		return sha256.New()
+	case HashSHA3_256:
+		return sha3.New256()
+	case HashSHA512:
+		return sha512.New()
+	case HashBLAKE3:
+		return blake3.New()
+	}
+	return nil
+}
+
+// Available 报告 h 是否是已知的哈希函数
+func (h HashID) Available() bool {
+	return h <= HashBLAKE3
+}
@@ -0,0 +1,110 @@
+package slothgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWithHash(t *testing.T) {
+	fingerprints := make(map[string]HashID)
+	for _, h := range []HashID{HashSHA256, HashSHA3_256, HashSHA512, HashBLAKE3} {
+		s, err := New(testVDF.P, 200, WithHash(h))
+		if err != nil {
+			t.Fatalf("New(%v) failed: %v", h, err)
+		}
+		if s.Hash() != h {
+			t.Errorf("Hash() = %v, want %v", s.Hash(), h)
+		}
+		proof, err := s.ComputeProof(testInput)
+		if err != nil {
+			t.Fatalf("ComputeProof(%v) failed: %v", h, err)
+		}
+		if len(proof.Hash) != s.HashFunc().Size() {
+			t.Errorf("%v: hash has %d bytes", h, len(proof.Hash))
+		}
+		if ok, err := s.VerifyProof(testInput, proof); !ok {
+			t.Errorf("VerifyProof(%v) failed: %v", h, err)
+		}
+		if other, ok := fingerprints[string(s.Fingerprint())]; ok {
+			t.Errorf("%v and %v share a fingerprint", h, other)
+		}
+		fingerprints[string(s.Fingerprint())] = h
+	}
+
+	// 默认实例与显式选择 SHA-256 的实例参数相同
+	def, _ := New(testVDF.P, 200)
+	explicit, _ := New(testVDF.P, 200, WithHash(HashSHA256))
+	if !bytes.Equal(def.Fingerprint(), explicit.Fingerprint()) {
+		t.Error("default hash should be SHA-256")
+	}
+
+	// 用 BLAKE3 计算的证明不能在 SHA-256 实例上通过验证
+	b3, _ := New(testVDF.P, 200, WithHash(HashBLAKE3))
+	proof, _ := b3.ComputeProof(testInput)
+	if ok, _ := def.VerifyProof(testInput, proof); ok {
+		t.Error("expected proof computed with another hash to be rejected")
+	}
+
+	if _, err := New(testVDF.P, 200, WithHash(HashID(99))); err == nil {
+		t.Error("expected error for unknown hash")
+	}
+}
+
+func TestWithDomainTag(t *testing.T) {
+	a, err := New(testVDF.P, 200, WithDomainTag([]byte("protocol-a")))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b, _ := New(testVDF.P, 200, WithDomainTag([]byte("protocol-b")))
+	untagged, _ := New(testVDF.P, 200)
+
+	proof, err := a.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if ok, err := a.VerifyProof(testInput, proof); !ok {
+		t.Fatalf("VerifyProof failed: %v", err)
+	}
+
+	// 即使伪造参数指纹，另一个协议的实例也会拒绝该证明
+	for name, other := range map[string]*Sloth{"other tag": b, "no tag": untagged} {
+		replayed := *proof
+		replayed.Fingerprint = other.Fingerprint()
+		if ok, _ := other.VerifyProof(testInput, &replayed); ok {
+			t.Errorf("%s: expected replayed proof to be rejected", name)
+		}
+	}
+	if bytes.Equal(a.Fingerprint(), b.Fingerprint()) {
+		t.Error("domain tag should be part of the fingerprint")
+	}
+}
+
+func TestWithParamBinding(t *testing.T) {
+	bound, err := New(testVDF.P, 200, WithParamBinding())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := bound.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if ok, err := bound.VerifyProof(testInput, proof); !ok {
+		t.Fatalf("VerifyProof failed: %v", err)
+	}
+
+	// 同一个 witness 在未绑定参数的实例下得到不同的哈希
+	plain, _ := New(testVDF.P, 200)
+	plainHash, plainWitness, _ := plain.Compute(testInput)
+	if plainWitness.Cmp(proof.Witness) != 0 {
+		t.Fatal("parameter binding should not change the witness")
+	}
+	if bytes.Equal(plainHash, proof.Hash) {
+		t.Error("bound hash should differ from the plain hash")
+	}
+
+	// 声称使用了其他迭代次数时，哈希无法匹配
+	claimed, _ := bound.WithIterations(100)
+	if ok, _ := claimed.Verify(testInput, proof.Hash, proof.Witness); ok {
+		t.Error("expected hash to be rejected under different parameters")
+	}
+}
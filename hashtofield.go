@@ -0,0 +1,37 @@
+package slothgo
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+// h2fSecurityBits 是 hash_to_field 的目标安全级别 k，多取 k 位使取模偏差可以忽略
+const h2fSecurityBits = 128
+
+// inputMapping 返回 w₀ 派生方式的名称，写入参数指纹
+func (s *Sloth) inputMapping() string {
+	if s.xmd {
+		return "xmd"
+	}
+	return "mod"
+}
+
+// hashToFieldDST 返回 hash_to_field 使用的域分隔标签，设置了域标签时附加在末尾
+func (s *Sloth) hashToFieldDST() []byte {
+	dst := []byte("SLOTHGO-V01-CS01-with-" + s.hash.String() + "_XMD_")
+	return append(dst, s.tag...)
+}
+
+// hashToField 按 RFC 9380 第 5.2 节把输入映射为 F_p 中的一个元素 (count = 1, m = 1):
+// L = ⌈(⌈log2 p⌉ + k) / 8⌉，w₀ = OS2IP(expand_message_xmd(msg, DST, L)) mod p
+func (s *Sloth) hashToField(r io.Reader) (*big.Int, error) {
+	l := (s.P.BitLen() + h2fSecurityBits + 7) / 8
+	uniform, err := hashutil.ExpandMessageXMDReader(s.HashFunc, r, s.hashToFieldDST(), l)
+	if err != nil {
+		return nil, err
+	}
+	w := new(big.Int).SetBytes(uniform)
+	return w.Mod(w, s.P), nil
+}
@@ -0,0 +1,41 @@
+package slothgo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+func TestWithHashToField(t *testing.T) {
+	s, err := New(testVDF.P, 200, WithHashToField())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// w₀ 应当等于 expand_message_xmd 输出对 p 取模
+	uniform, err := hashutil.ExpandMessageXMD(sha256.New, testInput, []byte("SLOTHGO-V01-CS01-with-sha256_XMD_"), (s.P.BitLen()+128+7)/8)
+	if err != nil {
+		t.Fatalf("ExpandMessageXMD failed: %v", err)
+	}
+	want := new(big.Int).Mod(new(big.Int).SetBytes(uniform), s.P)
+	if st := s.NewStepper(testInput); st.Value().Cmp(want) != 0 {
+		t.Errorf("w₀ = %v, want %v", st.Value(), want)
+	}
+
+	proof, err := s.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if ok, err := s.VerifyProof(testInput, proof); !ok {
+		t.Fatalf("VerifyProof failed: %v", err)
+	}
+	if ok, err := s.VerifyReader(bytes.NewReader(testInput), proof.Hash, proof.Witness); !ok {
+		t.Fatalf("VerifyReader failed: %v", err)
+	}
+	if bytes.Equal(s.Fingerprint(), testVDF.Fingerprint()) {
+		t.Error("input mapping should be part of the fingerprint")
+	}
+}
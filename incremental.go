@@ -0,0 +1,183 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// milestoneVersion 是部分证明二进制格式的版本号
+const milestoneVersion = 1
+
+// Milestone 是计算过程中发布的部分证明: 第 Start 次迭代后的值 From 经过 End-Start 次 τ 得到 To
+// 相邻的部分证明首尾相接，第一个的 From 是 w₀，最后一个的 To 是最终的 witness，
+// 中继方可以逐个验证进行中的计算，而不必等到全部完成
+type Milestone struct {
+	Start       uint64
+	End         uint64
+	From        *big.Int
+	To          *big.Int
+	Fingerprint []byte // 产生该部分证明的参数指纹
+
+	size int // From 与 To 的编码长度，见 Checkpoint
+}
+
+// MilestoneFunc 在计算过程中接收部分证明，返回错误会中止计算
+type MilestoneFunc func(m *Milestone) error
+
+// ComputeIncremental 与 ComputeProof 相同，但每完成 interval 次迭代就通过 emit 发布一个部分证明
+// 最后一个部分证明在计算结束时发布，其 End 等于总迭代次数
+func (s *Sloth) ComputeIncremental(ctx context.Context, input []byte, interval uint64, emit MilestoneFunc) (*Proof, error) {
+	if interval == 0 {
+		return nil, errors.New("milestone interval must be positive")
+	}
+	fingerprint := s.Fingerprint()
+	w := s.initialValue(input)
+	for i := uint64(0); i < s.Iterations; {
+		end := stepEnd(i, interval, s.Iterations)
+		from := new(big.Int).Set(w)
+		var err error
+		w, err = s.iterate(ctx, w, i, end)
+		if err != nil {
+			return nil, err
+		}
+		if emit != nil {
+			m := &Milestone{Start: i, End: end, From: from, To: new(big.Int).Set(w), Fingerprint: fingerprint, size: s.WitnessSize()}
+			if err := emit(m); err != nil {
+				return nil, fmt.Errorf("failed to emit milestone: %w", err)
+			}
+		}
+		i = end
+	}
+	witness := new(big.Int).Set(w)
+	return s.NewProof(s.outputHash(witness), witness), nil
+}
+
+// VerifyMilestone 单独验证一个部分证明: 从 To 逆向迭代 End-Start 次应回到 From
+// 它不检查该部分证明与输入或前一个部分证明的衔接，衔接由 ProgressVerifier 负责
+func (s *Sloth) VerifyMilestone(ctx context.Context, m *Milestone) error {
+	return s.verifyMilestone(ctx, m, newScratch())
+}
+
+func (s *Sloth) verifyMilestone(ctx context.Context, m *Milestone, sc *scratch) error {
+	if m == nil || m.From == nil || m.To == nil {
+		return errors.New("milestone is incomplete")
+	}
+	if !bytes.Equal(m.Fingerprint, s.Fingerprint()) {
+		return errors.New("milestone was produced with different parameters")
+	}
+	if m.Start >= m.End || m.End > s.Iterations {
+		return fmt.Errorf("milestone range [%d, %d] out of range [0, %d]", m.Start, m.End, s.Iterations)
+	}
+	if err := s.checkState(m.From, m.Start); err != nil {
+		return err
+	}
+	if err := s.checkState(m.To, m.End); err != nil {
+		return err
+	}
+	w, err := s.reverse(ctx, m.To, m.End-m.Start, sc)
+	if err != nil {
+		return err
+	}
+	if w.Cmp(m.From) != 0 {
+		return fmt.Errorf("milestone [%d, %d]: %w", m.Start, m.End, ErrReversalMismatch)
+	}
+	return nil
+}
+
+// ProgressVerifier 按顺序接收同一计算的部分证明，检查它们与输入首尾相接并逐段验证
+type ProgressVerifier struct {
+	s  *Sloth
+	w  *big.Int // 已验证到的值
+	i  uint64   // 已验证到的迭代次数
+	sc *scratch
+}
+
+// NewProgressVerifier 为输入 input 的计算创建一个进度验证器
+func (s *Sloth) NewProgressVerifier(input []byte) *ProgressVerifier {
+	return &ProgressVerifier{s: s, w: s.initialValue(input), sc: newScratch()}
+}
+
+// Accept 验证下一个部分证明，只有其起点与上一个已验证的终点一致才会接受
+func (v *ProgressVerifier) Accept(ctx context.Context, m *Milestone) error {
+	if m == nil || m.From == nil {
+		return errors.New("milestone is incomplete")
+	}
+	if m.Start != v.i || m.From.Cmp(v.w) != 0 {
+		return fmt.Errorf("milestone starting at %d does not continue from iteration %d", m.Start, v.i)
+	}
+	if err := v.s.verifyMilestone(ctx, m, v.sc); err != nil {
+		return err
+	}
+	v.w.Set(m.To)
+	v.i = m.End
+	return nil
+}
+
+// Verified 返回目前已经验证过的迭代次数
+func (v *ProgressVerifier) Verified() uint64 {
+	return v.i
+}
+
+// Done 报告是否已经验证到最后一次迭代
+func (v *ProgressVerifier) Done() bool {
+	return v.i == v.s.Iterations
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+// 格式: 版本(1) | 指纹长度(1) | 指纹 | Start(8) | End(8) | From 长度(2) | From | To
+func (m *Milestone) MarshalBinary() ([]byte, error) {
+	if m.From == nil || m.To == nil {
+		return nil, errors.New("milestone is incomplete")
+	}
+	if len(m.Fingerprint) > 255 {
+		return nil, errors.New("milestone fingerprint too long")
+	}
+	from := encodeFixed(m.From, m.size)
+	if len(from) > 0xffff {
+		return nil, errors.New("milestone value too large")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(milestoneVersion)
+	buf.WriteByte(byte(len(m.Fingerprint)))
+	buf.Write(m.Fingerprint)
+	binary.Write(&buf, binary.BigEndian, m.Start)
+	binary.Write(&buf, binary.BigEndian, m.End)
+	binary.Write(&buf, binary.BigEndian, uint16(len(from)))
+	buf.Write(from)
+	buf.Write(encodeFixed(m.To, m.size))
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (m *Milestone) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("milestone data too short")
+	}
+	if data[0] != milestoneVersion {
+		return fmt.Errorf("unsupported milestone version %d", data[0])
+	}
+	fpLen := int(data[1])
+	data = data[2:]
+	if len(data) < fpLen+18 {
+		return errors.New("milestone data too short")
+	}
+	fingerprint := bytes.Clone(data[:fpLen])
+	data = data[fpLen:]
+	start := binary.BigEndian.Uint64(data)
+	end := binary.BigEndian.Uint64(data[8:])
+	fromLen := int(binary.BigEndian.Uint16(data[16:]))
+	data = data[18:]
+	if len(data) < fromLen {
+		return errors.New("milestone data too short")
+	}
+	m.Fingerprint = fingerprint
+	m.Start, m.End = start, end
+	m.From = new(big.Int).SetBytes(data[:fromLen])
+	m.To = new(big.Int).SetBytes(data[fromLen:])
+	m.size = fromLen
+	return nil
+}
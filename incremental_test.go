@@ -0,0 +1,62 @@
+package slothgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestComputeIncremental(t *testing.T) {
+	ctx := context.Background()
+	var milestones []*Milestone
+	proof, err := testVDF.ComputeIncremental(ctx, testInput, 300, func(m *Milestone) error {
+		// 模拟经过网络传输
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		var decoded Milestone
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			return err
+		}
+		milestones = append(milestones, &decoded)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ComputeIncremental failed: %v", err)
+	}
+	if len(milestones) != 4 {
+		t.Fatalf("expected 4 milestones, got %d", len(milestones))
+	}
+	if ok, err := testVDF.VerifyProof(testInput, proof); !ok {
+		t.Fatalf("VerifyProof failed: %v", err)
+	}
+
+	v := testVDF.NewProgressVerifier(testInput)
+	for _, m := range milestones {
+		if err := v.Accept(ctx, m); err != nil {
+			t.Fatalf("Accept(%d..%d) failed: %v", m.Start, m.End, err)
+		}
+	}
+	if !v.Done() || v.Verified() != testVDF.Iterations {
+		t.Errorf("verifier stopped at %d of %d iterations", v.Verified(), testVDF.Iterations)
+	}
+	if milestones[len(milestones)-1].To.Cmp(proof.Witness) != 0 {
+		t.Error("last milestone does not end at the witness")
+	}
+
+	// 跳过一个部分证明或使用别的输入都不能衔接
+	v = testVDF.NewProgressVerifier(testInput)
+	if err := v.Accept(ctx, milestones[1]); err == nil {
+		t.Error("expected out-of-order milestone to be rejected")
+	}
+	if err := testVDF.NewProgressVerifier([]byte("other")).Accept(ctx, milestones[0]); err == nil {
+		t.Error("expected milestone for a different input to be rejected")
+	}
+
+	// 篡改终点会让单段验证失败
+	bad := *milestones[2]
+	bad.To = testVDF.Tau(bad.To)
+	if err := testVDF.VerifyMilestone(ctx, &bad); err == nil {
+		t.Error("expected tampered milestone to be rejected")
+	}
+}
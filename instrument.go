@@ -0,0 +1,41 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// track 通知监控收集器一次操作开始，返回在操作结束时以结果调用的函数
+// 未配置 WithMetrics 时不做任何事
+func (s *Sloth) track(op metrics.Op) func(metrics.Outcome) {
+	if s.metrics == nil {
+		return func(metrics.Outcome) {}
+	}
+	s.metrics.Begin(op)
+	start := time.Now()
+	return func(o metrics.Outcome) {
+		s.metrics.End(op, s.Iterations, time.Since(start), o)
+	}
+}
+
+// computeOutcome 把计算返回的 error 转换为监控结果
+func computeOutcome(err error) metrics.Outcome {
+	if err != nil {
+		return metrics.Error
+	}
+	return metrics.OK
+}
+
+// verifyOutcome 把验证的返回值转换为监控结果: 被取消或超时记为 Error，其余失败都是证明无效
+func verifyOutcome(ok bool, err error) metrics.Outcome {
+	switch {
+	case ok && err == nil:
+		return metrics.OK
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return metrics.Error
+	}
+	return metrics.Rejected
+}
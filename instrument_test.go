@@ -0,0 +1,77 @@
+package slothgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// recordingCollector 记录每种操作与结果的次数
+type recordingCollector struct {
+	mu     sync.Mutex
+	active map[metrics.Op]int
+	ends   map[metrics.Op]map[metrics.Outcome]int
+}
+
+func newRecordingCollector() *recordingCollector {
+	return &recordingCollector{active: make(map[metrics.Op]int), ends: make(map[metrics.Op]map[metrics.Outcome]int)}
+}
+
+func (c *recordingCollector) Begin(op metrics.Op) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active[op]++
+}
+
+func (c *recordingCollector) End(op metrics.Op, iterations uint64, elapsed time.Duration, o metrics.Outcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active[op]--
+	if c.ends[op] == nil {
+		c.ends[op] = make(map[metrics.Outcome]int)
+	}
+	c.ends[op][o]++
+}
+
+// TestWithMetrics 检查每个入口恰好报告一次，并区分无效证明与被取消的操作
+func TestWithMetrics(t *testing.T) {
+	c := newRecordingCollector()
+	vdf, err := New(testVDF.P, 200, WithMetrics(c))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := vdf.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	vdf.Verify(testInput, proof.Hash, proof.Witness)
+	vdf.VerifyProof(testInput, proof)
+	vdf.VerifyProof([]byte("other"), proof)
+	vdf.VerifyBatch([][]byte{testInput, testInput}, []Proof{*proof, *proof})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	vdf.ComputeCtx(ctx, testInput)
+	vdf.VerifyProofCtx(ctx, testInput, proof)
+
+	segmented, _ := New(testVDF.P, 200, WithMetrics(c), WithSegmentCheckpoints(50))
+	segmented.ComputeProof(testInput)
+
+	want := map[metrics.Op]map[metrics.Outcome]int{
+		metrics.Compute: {metrics.OK: 2, metrics.Error: 1},
+		metrics.Verify:  {metrics.OK: 4, metrics.Rejected: 1, metrics.Error: 1},
+	}
+	for op, outcomes := range want {
+		if c.active[op] != 0 {
+			t.Errorf("%s: %d operations still active", op, c.active[op])
+		}
+		for o, n := range outcomes {
+			if c.ends[op][o] != n {
+				t.Errorf("%s/%s reported %d times, want %d (all: %v)", op, o, c.ends[op][o], n, c.ends)
+			}
+		}
+	}
+}
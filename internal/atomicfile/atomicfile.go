@@ -0,0 +1,37 @@
+// Package atomicfile 提供崩溃安全的文件替换，供各个持久化存储共享
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile 原子地把 path 的内容替换为 data: 先写入并同步同一目录中的临时文件，
+// 再重命名为 path 并同步目录，返回 nil 时新内容在崩溃后仍然存在。
+// 文件权限与 os.CreateTemp 相同 (0600)
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,108 @@
+// Package beaconapi 定义信标节点 REST 接口的 JSON 格式，并提供从远程节点取回轮次的 Client
+// 它不依赖节点本身的实现 (存储、p2p、指标)，浏览器中的验证方 (cmd/sloth-wasm) 用它解码页面取回的轮次
+package beaconapi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// ParamsResponse 是 GET /params 的响应，验证方据此构造相同的 Sloth 实例
+type ParamsResponse struct {
+	P           string `json:"p"`
+	Iterations  uint64 `json:"iterations"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ProofResponse 是证明的 JSON 表示，所有字节均为十六进制，witness 使用定长编码
+type ProofResponse struct {
+	Hash        string `json:"hash"`
+	Witness     string `json:"witness"`
+	Iterations  uint64 `json:"iterations"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// RoundResponse 是一轮信标输出的 JSON 表示
+type RoundResponse struct {
+	Index             uint64        `json:"index"`
+	Randomness        string        `json:"randomness"`
+	Previous          string        `json:"previous"`
+	Seed              string        `json:"seed"`
+	ContributionsRoot string        `json:"contributions_root"`
+	Contributions     int           `json:"contributions"`
+	Proof             ProofResponse `json:"proof"`
+	ElapsedNanos      int64         `json:"elapsed_ns,omitempty"`
+}
+
+// NewRoundResponse 返回 r 的 JSON 表示，witness 按 vdf 的定长编码
+func NewRoundResponse(vdf *slothgo.Sloth, r *beacon.Round) RoundResponse {
+	return RoundResponse{
+		Index:             r.Index,
+		Randomness:        hex.EncodeToString(r.Randomness),
+		Previous:          hex.EncodeToString(r.Previous),
+		Seed:              hex.EncodeToString(r.Seed),
+		ContributionsRoot: hex.EncodeToString(r.ContributionsRoot),
+		Contributions:     len(r.Contributions),
+		Proof: ProofResponse{
+			Hash:        hex.EncodeToString(r.Proof.Hash),
+			Witness:     hex.EncodeToString(vdf.EncodeWitness(r.Proof.Witness)),
+			Iterations:  r.Proof.Iterations,
+			Fingerprint: hex.EncodeToString(r.Proof.Fingerprint),
+		},
+		ElapsedNanos: r.Elapsed.Nanoseconds(),
+	}
+}
+
+// Round 把响应与取回的全部提交组装为 beacon.Round，witness 按 vdf 的定长编码解码
+// 组装出的轮次还需要用 beacon.Verify 验证
+func (resp *RoundResponse) Round(vdf *slothgo.Sloth, contributions [][]byte) (*beacon.Round, error) {
+	if len(contributions) != resp.Contributions {
+		return nil, fmt.Errorf("round %d has %d contributions, got %d", resp.Index, resp.Contributions, len(contributions))
+	}
+	r := &beacon.Round{Index: resp.Index, Contributions: contributions}
+	var witness []byte
+	for _, f := range []struct {
+		dst *[]byte
+		src string
+	}{
+		{&r.Randomness, resp.Randomness},
+		{&r.Previous, resp.Previous},
+		{&r.Seed, resp.Seed},
+		{&r.ContributionsRoot, resp.ContributionsRoot},
+		{&r.Proof.Hash, resp.Proof.Hash},
+		{&r.Proof.Fingerprint, resp.Proof.Fingerprint},
+		{&witness, resp.Proof.Witness},
+	} {
+		b, err := hex.DecodeString(f.src)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", resp.Index, err)
+		}
+		*f.dst = b
+	}
+	w, err := vdf.DecodeWitness(witness)
+	if err != nil {
+		return nil, fmt.Errorf("round %d: %w", resp.Index, err)
+	}
+	r.Proof.Witness = w
+	r.Proof.Iterations = resp.Proof.Iterations
+	r.Elapsed = time.Duration(resp.ElapsedNanos)
+	return r, nil
+}
+
+// ContributionResponse 是单个提交及其包含证明
+type ContributionResponse struct {
+	Round uint64   `json:"round"`
+	Index int      `json:"index"`
+	Size  int      `json:"size"`
+	Data  string   `json:"data"`
+	Path  []string `json:"path"`
+}
+
+// SubmitResponse 是 POST /contributions 的响应
+type SubmitResponse struct {
+	Position int `json:"position"`
+}
@@ -0,0 +1,53 @@
+package beaconapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// TestRoundResponse 检查轮次经过 JSON 表示往返之后仍然能够通过验证
+func TestRoundResponse(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	b.Contribute([]byte("a"))
+	b.Contribute([]byte("b"))
+	round, err := b.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	data, err := json.Marshal(NewRoundResponse(vdf, round))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var resp RoundResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	r, err := resp.Round(vdf, round.Contributions)
+	if err != nil {
+		t.Fatalf("Round failed: %v", err)
+	}
+	if err := beacon.Verify(vdf, r, nil); err != nil {
+		t.Errorf("decoded round does not verify: %v", err)
+	}
+
+	if _, err := resp.Round(vdf, round.Contributions[:1]); err == nil {
+		t.Error("missing contribution was accepted")
+	}
+	resp.Seed = "xyz"
+	if _, err := resp.Round(vdf, round.Contributions); err == nil {
+		t.Error("invalid hex was accepted")
+	}
+}
@@ -0,0 +1,120 @@
+package beaconapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// Client 从远程信标节点的 REST 接口取回轮次
+// 取回的轮次需要调用方用 beacon.Verify 验证，节点本身不需要被信任
+type Client struct {
+	base string
+	hc   *http.Client
+}
+
+// NewClient 创建连接 baseURL (例如 "http://beacon:8080") 的客户端，hc 为 nil 时使用 http.DefaultClient
+func NewClient(baseURL string, hc *http.Client) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", base.Scheme)
+	}
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &Client{base: base.String(), hc: hc}, nil
+}
+
+// Params 返回节点公布的素数与迭代次数
+func (c *Client) Params(ctx context.Context) (*big.Int, uint64, error) {
+	var resp ParamsResponse
+	if err := c.get(ctx, "/params", &resp); err != nil {
+		return nil, 0, err
+	}
+	p, ok := new(big.Int).SetString(resp.P, 16)
+	if !ok || p.Sign() <= 0 {
+		return nil, 0, fmt.Errorf("invalid prime %q", resp.P)
+	}
+	return p, resp.Iterations, nil
+}
+
+// Latest 返回最新一轮的序号
+func (c *Client) Latest(ctx context.Context) (uint64, error) {
+	var resp RoundResponse
+	if err := c.get(ctx, "/rounds/latest", &resp); err != nil {
+		return 0, err
+	}
+	return resp.Index, nil
+}
+
+// Randomness 返回第 index 轮的随机数，用于检查下一轮与它的链接，不取回提交
+func (c *Client) Randomness(ctx context.Context, index uint64) ([]byte, error) {
+	var resp RoundResponse
+	if err := c.get(ctx, fmt.Sprintf("/rounds/%d", index), &resp); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(resp.Randomness)
+}
+
+// Round 取回第 index 轮及其全部提交，witness 按 vdf 的定长编码解码
+func (c *Client) Round(ctx context.Context, vdf *slothgo.Sloth, index uint64) (*beacon.Round, error) {
+	var resp RoundResponse
+	if err := c.get(ctx, fmt.Sprintf("/rounds/%d", index), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Index != index {
+		return nil, fmt.Errorf("requested round %d, got %d", index, resp.Index)
+	}
+	contributions := make([][]byte, resp.Contributions)
+	for i := range contributions {
+		var cr ContributionResponse
+		if err := c.get(ctx, fmt.Sprintf("/rounds/%d/contributions/%d", index, i), &cr); err != nil {
+			return nil, err
+		}
+		var err error
+		if contributions[i], err = hex.DecodeString(cr.Data); err != nil {
+			return nil, fmt.Errorf("round %d contribution %d: %w", index, i, err)
+		}
+	}
+	return resp.Round(vdf, contributions)
+}
+
+// get 发送 GET 请求并把 JSON 响应解析到 out，非 200 响应返回其中的错误信息
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("GET %s: %w", path, beacon.ErrNotFound)
+		}
+		return fmt.Errorf("GET %s: %s", path, e.Error)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package beaconnode 组装完整的信标节点: 收集提交、按周期计算并发布轮次、通过 REST 接口与 p2p 网络分发，
+// 供 sloth-beacon 与 sloth beacon run 共用。REST 接口见 server.go，其 JSON 格式与客户端见 internal/beaconapi
+package beaconnode
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+	"github.com/alan22333/sloth_go/metrics"
+	"github.com/alan22333/sloth_go/ots"
+	"github.com/alan22333/sloth_go/p2p"
+	"github.com/alan22333/sloth_go/store"
+)
+
+// Config 是信标节点的配置
+type Config struct {
+	Prime      *big.Int      // Sloth 的素数 p
+	Iterations uint64        // 每一轮的延迟迭代次数
+	Period     time.Duration // 每一轮提交阶段的长度
+	BeaconID   string        // drand 格式中的 beaconID, 为空时为 "sloth"
+	DataDir    string        // 轮次持久化目录, 为空时只保存在内存中
+	P2PAddr    string        // p2p 监听地址, 为空时不接受入站连接
+	Peers      []string      // 相邻节点地址
+	Follow     bool          // 只从相邻节点同步轮次, 不自己发布
+
+	// OTSCalendars 不为空时把每一轮锚定到这些 OpenTimestamps 日历服务器，锚定保存在 DataDir/ots 中
+	OTSCalendars []string
+
+	// Difficulty 不为空时按它调整每一轮的迭代次数，Iterations 为第 0 轮的迭代次数
+	// 同一网络中的发布方与跟随节点必须使用相同的参数
+	Difficulty *beacon.DifficultyConfig
+
+	// PrimeChecked 表示素数来自标准参数集等可信来源，不需要再做素性检验
+	PrimeChecked bool
+
+	// NewNetwork 不为空时用它创建分发轮次的网络 (例如 p2p/gossip 模块中基于 libp2p 的实现)，
+	// 此时 P2PAddr 与 Peers 由它解释; 为空时使用 p2p.Node 的 TCP 网络
+	NewNetwork func(chain *p2p.Chain) (Network, error)
+}
+
+// Network 是节点分发轮次的 p2p 网络，收到的轮次交给创建它时传入的 p2p.Chain
+type Network interface {
+	// Start 开始监听与连接相邻节点并在后台同步轮次，直到 ctx 被取消
+	Start(ctx context.Context) error
+	// Announce 把本地发布的一轮转发给网络
+	Announce(r *beacon.Round) error
+}
+
+// Node 是一个信标节点
+type Node struct {
+	cfg    Config
+	vdf    *slothgo.Sloth
+	s      *server
+	net    Network
+	prom   *metrics.Prometheus
+	ots    *ots.Store
+	closer func() error
+}
+
+// New 按 cfg 创建节点，指定了 DataDir 时打开其中的轮次存储，用完之后调用 Close
+func New(cfg Config) (*Node, error) {
+	if cfg.Prime == nil {
+		return nil, errors.New("prime cannot be nil")
+	}
+	if cfg.Period <= 0 {
+		return nil, errors.New("period must be positive")
+	}
+	if cfg.BeaconID == "" {
+		cfg.BeaconID = "sloth"
+	}
+	prom, err := metrics.NewPrometheus("sloth_beacon")
+	if err != nil {
+		return nil, err
+	}
+	opts := []slothgo.Option{slothgo.WithMetrics(prom)}
+	if cfg.PrimeChecked {
+		opts = append(opts, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+	}
+	vdf, err := slothgo.New(cfg.Prime, cfg.Iterations, opts...)
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{cfg: cfg, vdf: vdf, prom: prom, closer: func() error { return nil }}
+	var rounds beacon.Store = beacon.NewMemoryStore()
+	if cfg.DataDir != "" {
+		fs, err := store.OpenFileStore(cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		rounds, n.closer = fs, fs.Close
+	}
+	if len(cfg.OTSCalendars) > 0 {
+		if cfg.DataDir == "" {
+			return nil, errors.New("anchoring requires a data directory")
+		}
+		client := &ots.Client{Calendars: cfg.OTSCalendars}
+		n.ots, err = ots.NewStore(rounds, filepath.Join(cfg.DataDir, "ots"), client, ots.WithErrorHandler(func(index uint64, err error) {
+			log.Printf("第 %d 轮锚定失败: %v", index, err)
+		}))
+		if err != nil {
+			n.closer()
+			return nil, err
+		}
+		rounds, n.closer = n.ots, n.ots.Close
+	}
+	b := beacon.New(vdf, rounds)
+	chain := p2p.NewChain(vdf, rounds)
+	if cfg.Difficulty != nil {
+		dc, err := beacon.NewDifficultyController(*cfg.Difficulty)
+		if err != nil {
+			n.closer()
+			return nil, err
+		}
+		b.Difficulty, chain.Difficulty = dc, dc
+	}
+	n.s = newServer(b, beaconapi.ParamsResponse{
+		P:           cfg.Prime.Text(16),
+		Iterations:  vdf.Iterations,
+		Fingerprint: hex.EncodeToString(vdf.Fingerprint()),
+	}, beacon.NewDrandInfo(vdf, cfg.BeaconID, cfg.Period, time.Now().Add(cfg.Period)))
+	chain.OnRound = func(r *beacon.Round) {
+		log.Printf("同步第 %d 轮: 随机数 %x", r.Index, r.Randomness)
+		n.s.notify()
+	}
+	if cfg.NewNetwork == nil {
+		n.net = &tcpNetwork{node: p2p.NewChainNode(chain), addr: cfg.P2PAddr, peers: cfg.Peers}
+	} else if n.net, err = cfg.NewNetwork(chain); err != nil {
+		n.closer()
+		return nil, err
+	}
+	return n, nil
+}
+
+// VDF 返回节点使用的 Sloth 实例
+func (n *Node) VDF() *slothgo.Sloth {
+	return n.vdf
+}
+
+// Handler 返回节点的 REST 接口与 /metrics
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", n.prom)
+	mux.Handle("/", n.s.routes())
+	return mux
+}
+
+// Run 在 lis 上提供 REST 接口，启动 p2p 连接与发布循环，直到 ctx 被取消
+func (n *Node) Run(ctx context.Context, lis net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := n.net.Start(ctx); err != nil {
+		return err
+	}
+	if !n.cfg.Follow {
+		go n.publishLoop(ctx)
+	}
+	if n.ots != nil {
+		go n.upgradeLoop(ctx)
+	}
+
+	srv := &http.Server{Handler: n.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("信标服务监听于 %s, p = %x, 每轮 %d 次迭代", lis.Addr(), n.cfg.Prime, n.vdf.Iterations)
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close 关闭轮次存储
+func (n *Node) Close() error {
+	return n.closer()
+}
+
+// publishLoop 每隔 Period 发布一轮、通知事件流并转发给相邻节点，直到 ctx 被取消
+func (n *Node) publishLoop(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.Period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r, err := n.s.b.Publish(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("发布失败: %v", err)
+				}
+				continue
+			}
+			log.Printf("第 %d 轮: %d 个提交, %d 次迭代用时 %v, 随机数 %x", r.Index, len(r.Contributions), r.Proof.Iterations, r.Elapsed.Round(time.Millisecond), r.Randomness)
+			n.s.notify()
+			if err := n.net.Announce(r); err != nil {
+				log.Printf("转发失败: %v", err)
+			}
+		}
+	}
+}
+
+// upgradeLoop 每小时向日历服务器查询最近一天内仍然待定的锚定，直到 ctx 被取消
+func (n *Node) upgradeLoop(ctx context.Context) {
+	const interval = time.Hour
+	window := uint64(24*time.Hour/n.cfg.Period) + 1
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latest, err := n.ots.Store.Latest()
+			if err != nil {
+				continue
+			}
+			from := latest.Index - min(latest.Index, window)
+			upgraded, err := n.ots.Upgrade(ctx, from, latest.Index)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("升级锚定失败: %v", err)
+			}
+			if upgraded > 0 {
+				log.Printf("%d 轮的锚定已写入比特币区块", upgraded)
+			}
+		}
+	}
+}
+
+// tcpNetwork 是默认的网络: p2p.Node 监听 addr 并保持与 peers 的连接
+type tcpNetwork struct {
+	node  *p2p.Node
+	addr  string
+	peers []string
+}
+
+func (t *tcpNetwork) Start(ctx context.Context) error {
+	if t.addr != "" {
+		lis, err := net.Listen("tcp", t.addr)
+		if err != nil {
+			return err
+		}
+		log.Printf("p2p 节点监听于 %s", lis.Addr())
+		go t.node.Listen(ctx, lis)
+	}
+	for _, peer := range t.peers {
+		go t.dialLoop(ctx, peer)
+	}
+	return nil
+}
+
+func (t *tcpNetwork) Announce(r *beacon.Round) error {
+	return t.node.Announce(r)
+}
+
+// dialLoop 保持与 addr 的连接，断开后等待一段时间重连，直到 ctx 被取消
+func (t *tcpNetwork) dialLoop(ctx context.Context, addr string) {
+	const retry = 5 * time.Second
+	for {
+		err := t.node.Dial(ctx, addr)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("与节点 %s 的连接断开: %v, %v 后重连", addr, err, retry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package beaconnode
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+)
+
+// keepAlive 是事件流在没有新轮次时发送注释行的间隔，避免代理关闭空闲连接
+const keepAlive = 15 * time.Second
+
+// server 把 beacon.Beacon 暴露为 REST 接口
+type server struct {
+	b      *beacon.Beacon
+	params beaconapi.ParamsResponse
+	info   beacon.DrandInfo
+
+	// changed 在每次有新轮次写入存储时关闭并替换，用于唤醒事件流
+	mu      sync.Mutex
+	changed chan struct{}
+
+	// agg 是最近一次请求的轮次的聚合，提交者通常在一轮发布后集中取回各自的包含证明
+	aggMu sync.Mutex
+	agg   *beacon.Aggregate
+}
+
+func newServer(b *beacon.Beacon, params beaconapi.ParamsResponse, info beacon.DrandInfo) *server {
+	return &server{b: b, params: params, info: info, changed: make(chan struct{})}
+}
+
+// notify 通知事件流存储中有了新的轮次，发布或同步一轮之后调用
+func (s *server) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// watch 返回下一次 notify 时关闭的 channel
+func (s *server) watch() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changed
+}
+
+// routes 注册全部接口
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /params", s.handleParams)
+	mux.HandleFunc("POST /contributions", s.handleContribute)
+	mux.HandleFunc("GET /rounds/latest", s.handleLatest)
+	mux.HandleFunc("GET /rounds/events", s.handleEvents)
+	mux.HandleFunc("GET /rounds/{index}", s.handleRound)
+	mux.HandleFunc("GET /rounds/{index}/contributions/{i}", s.handleContribution)
+
+	// drand 兼容接口
+	mux.HandleFunc("GET /info", s.handleDrandInfo)
+	mux.HandleFunc("GET /public/latest", s.handleDrandLatest)
+	mux.HandleFunc("GET /public/{round}", s.handleDrandRound)
+	return allowCrossOrigin(mux)
+}
+
+// allowCrossOrigin 允许任意来源的网页读取轮次，浏览器中的验证方 (见 cmd/sloth-wasm) 可以直接取回轮次
+// 轮次本身是公开数据；提交接口不允许跨域调用
+func allowCrossOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleParams(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.params)
+}
+
+// handleContribute 接收请求体中的原始字节作为一个提交
+func (s *server) handleContribute(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, beacon.MaxContributionSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	pos, err := s.b.Contribute(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, beaconapi.SubmitResponse{Position: pos})
+}
+
+func (s *server) handleLatest(w http.ResponseWriter, r *http.Request) {
+	round, err := s.b.Store().Latest()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, beaconapi.NewRoundResponse(s.b.VDF(), round))
+}
+
+// handleEvents 以 Server-Sent Events 推送新发布的轮次，事件名为 round，事件 ID 是轮次序号
+// 默认从下一轮开始; ?from=i 或重连时的 Last-Event-ID 头可以先补发存储中已有的轮次
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	next, ok := s.firstEvent(w, r)
+	if !ok {
+		return
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if rc.Flush() != nil {
+		return
+	}
+	for {
+		// 先取得 channel 再读存储，读完之后写入的轮次一定会唤醒下面的等待
+		changed := s.watch()
+		for {
+			round, err := s.b.Store().Get(next)
+			if errors.Is(err, beacon.ErrNotFound) {
+				break
+			}
+			if err != nil {
+				return
+			}
+			if err := writeEvent(w, round.Index, "round", beaconapi.NewRoundResponse(s.b.VDF(), round)); err != nil {
+				return
+			}
+			next++
+		}
+		if rc.Flush() != nil {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-time.After(keepAlive):
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// firstEvent 返回事件流的第一轮序号，失败时已经写好错误响应
+func (s *server) firstEvent(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		last, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID %q", id))
+			return 0, false
+		}
+		return last + 1, true
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		next, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid round index %q", from))
+			return 0, false
+		}
+		return next, true
+	}
+	latest, err := s.b.Store().Latest()
+	switch {
+	case errors.Is(err, beacon.ErrNotFound):
+		return 0, true
+	case err != nil:
+		writeStoreError(w, err)
+		return 0, false
+	}
+	return latest.Index + 1, true
+}
+
+func (s *server) handleRound(w http.ResponseWriter, r *http.Request) {
+	round, ok := s.lookupRound(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, beaconapi.NewRoundResponse(s.b.VDF(), round))
+}
+
+func (s *server) handleContribution(w http.ResponseWriter, r *http.Request) {
+	round, ok := s.lookupRound(w, r)
+	if !ok {
+		return
+	}
+	i, err := strconv.Atoi(r.PathValue("i"))
+	if err != nil || i < 0 || i >= len(round.Contributions) {
+		writeError(w, http.StatusNotFound, errors.New("contribution not found"))
+		return
+	}
+	path := s.aggregate(round).Receipts[i].Path
+	resp := beaconapi.ContributionResponse{
+		Round: round.Index,
+		Index: i,
+		Size:  len(round.Contributions),
+		Data:  hex.EncodeToString(round.Contributions[i]),
+		Path:  make([]string, len(path)),
+	}
+	for k, p := range path {
+		resp.Path[k] = hex.EncodeToString(p)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) handleDrandInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.info)
+}
+
+func (s *server) handleDrandLatest(w http.ResponseWriter, r *http.Request) {
+	latest, err := s.b.Store().Latest()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	s.writeDrandRound(w, latest.Index+1)
+}
+
+func (s *server) handleDrandRound(w http.ResponseWriter, r *http.Request) {
+	round, err := strconv.ParseUint(r.PathValue("round"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid round %q", r.PathValue("round")))
+		return
+	}
+	s.writeDrandRound(w, round)
+}
+
+func (s *server) writeDrandRound(w http.ResponseWriter, round uint64) {
+	d, err := beacon.DrandRoundFrom(s.b.VDF(), s.b.Store(), round)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// aggregate 返回 round 的聚合，与缓存的轮次相同时直接复用
+func (s *server) aggregate(round *beacon.Round) *beacon.Aggregate {
+	s.aggMu.Lock()
+	defer s.aggMu.Unlock()
+	if a := s.agg; a != nil && a.Round == round.Index && bytes.Equal(a.Root, round.ContributionsRoot) {
+		return a
+	}
+	s.agg = round.Aggregate()
+	return s.agg
+}
+
+// lookupRound 解析路径中的轮次并从存储中读取，失败时已经写好错误响应
+func (s *server) lookupRound(w http.ResponseWriter, r *http.Request) (*beacon.Round, bool) {
+	index, err := strconv.ParseUint(r.PathValue("index"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid round index %q", r.PathValue("index")))
+		return nil, false
+	}
+	round, err := s.b.Store().Get(index)
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+	return round, true
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, beacon.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// writeEvent 写入一个 Server-Sent Events 事件，数据为 v 的单行 JSON
+func writeEvent(w http.ResponseWriter, id uint64, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
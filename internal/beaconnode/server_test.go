@@ -0,0 +1,200 @@
+package beaconnode
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/beaconapi"
+)
+
+func TestServer(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	ts := httptest.NewServer(newServer(b, beaconapi.ParamsResponse{P: p.Text(16), Iterations: 200}, beacon.NewDrandInfo(vdf, "test", time.Second, time.Now())).routes())
+	defer ts.Close()
+
+	get := func(path string, v any) int {
+		t.Helper()
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("decoding %s failed: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	if code := get("/rounds/latest", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 before the first round, got %d", code)
+	}
+
+	resp, err := http.Post(ts.URL+"/contributions", "application/octet-stream", strings.NewReader("entropy"))
+	if err != nil {
+		t.Fatalf("POST /contributions failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("contributions must not be open to other origins")
+	}
+
+	round, err := b.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	var latest beaconapi.RoundResponse
+	if code := get("/rounds/latest", &latest); code != http.StatusOK {
+		t.Fatalf("GET /rounds/latest returned %d", code)
+	}
+	if latest.Randomness != hex.EncodeToString(round.Randomness) || latest.Contributions != 1 {
+		t.Errorf("unexpected latest round: %+v", latest)
+	}
+	if resp, err := http.Get(ts.URL + "/rounds/0"); err != nil || resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("rounds are not readable from other origins: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+	var byIndex beaconapi.RoundResponse
+	if code := get("/rounds/0", &byIndex); code != http.StatusOK || byIndex != latest {
+		t.Errorf("GET /rounds/0 returned %d, %+v", code, byIndex)
+	}
+	if code := get("/rounds/1", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a future round, got %d", code)
+	}
+
+	var c beaconapi.ContributionResponse
+	if code := get("/rounds/0/contributions/0", &c); code != http.StatusOK {
+		t.Fatalf("GET contribution returned %d", code)
+	}
+	if c.Data != hex.EncodeToString([]byte("entropy")) || c.Size != 1 {
+		t.Errorf("unexpected contribution: %+v", c)
+	}
+
+	var d beacon.DrandRound
+	if code := get("/public/latest", &d); code != http.StatusOK {
+		t.Fatalf("GET /public/latest returned %d", code)
+	}
+	if d.Round != 1 || d.Randomness != latest.Randomness {
+		t.Errorf("unexpected drand round: %+v", d)
+	}
+	var info beacon.DrandInfo
+	if code := get("/info", &info); code != http.StatusOK || info.SchemeID != beacon.DrandSchemeID {
+		t.Errorf("GET /info returned %d, %+v", code, info)
+	}
+}
+
+// TestEvents 检查事件流先补发请求的历史轮次，再推送之后发布的轮次
+func TestEvents(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	s := newServer(b, beaconapi.ParamsResponse{}, beacon.NewDrandInfo(vdf, "test", time.Second, time.Now()))
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for range 2 {
+		if _, err := b.Publish(ctx); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	open := func(path, lastID string) *bufio.Scanner {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+path, nil)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("GET %s returned %d %q", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		return bufio.NewScanner(resp.Body)
+	}
+	// next 读取下一个事件，返回其 ID 与数据
+	next := func(sc *bufio.Scanner) (string, beaconapi.RoundResponse) {
+		t.Helper()
+		var id string
+		var round beaconapi.RoundResponse
+		for sc.Scan() && sc.Text() != "" {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case line == "event: round":
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &round); err != nil {
+					t.Fatalf("malformed data %q: %v", line, err)
+				}
+			default:
+				t.Fatalf("unexpected line %q", line)
+			}
+		}
+		if id == "" {
+			t.Fatalf("stream ended: %v", sc.Err())
+		}
+		return id, round
+	}
+
+	fromOne := open("/rounds/events?from=1", "")
+	resumed := open("/rounds/events", "0")
+	live := open("/rounds/events", "")
+	for _, sc := range []*bufio.Scanner{fromOne, resumed} {
+		if id, round := next(sc); id != "1" || round.Index != 1 {
+			t.Fatalf("expected round 1 first, got %s %+v", id, round)
+		}
+	}
+
+	r, err := b.Publish(ctx)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	s.notify()
+	for _, sc := range []*bufio.Scanner{fromOne, resumed, live} {
+		if id, round := next(sc); id != "2" || round.Randomness != hex.EncodeToString(r.Randomness) {
+			t.Fatalf("expected the new round 2, got %s %+v", id, round)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/rounds/events?from=x")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid from returned %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,148 @@
+package beaconnode
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+// EnvPrefix 是信标节点环境变量的前缀，例如 SLOTH_BEACON_PEERS 对应配置文件中的 peers
+const EnvPrefix = "SLOTH_BEACON_"
+
+// defaultIterations 是既没有指定迭代次数也没有指定参数集时每一轮的迭代次数
+const defaultIterations = 100000
+
+// Settings 是 sloth-beacon 与 sloth beacon run 共用的设置，可以来自 YAML 配置文件 (格式见 internal/config)、
+// SLOTH_BEACON_ 开头的环境变量与命令行选项:
+//
+//	addr: ":8080"            # HTTP 监听地址
+//	params: sloth-256-t30s   # 标准参数集, 或者用 prime_file / prime 指定素数, 都不指定时随机生成 bits 位的素数
+//	iterations: 100000       # 每一轮的迭代次数, 默认为参数集的推荐值
+//	period: 1m               # 每一轮提交阶段的长度
+//	target: 30s              # 不为 0 时调整迭代次数使每一轮的计算耗时接近它, 第 0 轮使用 iterations
+//	id: sloth                # drand 格式中的 beaconID
+//	data: /var/lib/beacon    # 轮次持久化目录
+//	p2p: ":9000"             # p2p 监听地址
+//	peers:                   # 相邻节点, 也可以写成 [a, b]
+//	  - beacon-1:9000
+//	follow: false            # 只同步不发布
+//	ots: [https://a.pool.opentimestamps.org]  # 把每一轮锚定到 OpenTimestamps 日历服务器, 需要 data
+type Settings struct {
+	Addr       string        `config:"addr"`
+	Params     string        `config:"params"`
+	PrimeFile  string        `config:"prime_file"`
+	Prime      string        `config:"prime"`
+	Bits       int           `config:"bits"`
+	Iterations uint64        `config:"iterations"`
+	Period     time.Duration `config:"period"`
+	Target     time.Duration `config:"target"`
+	ID         string        `config:"id"`
+	Data       string        `config:"data"`
+	P2P        string        `config:"p2p"`
+	Peers      config.List   `config:"peers"`
+	Follow     bool          `config:"follow"`
+	OTS        config.List   `config:"ots"`
+}
+
+// DefaultSettings 返回默认设置
+func DefaultSettings() Settings {
+	return Settings{Addr: ":8080", Bits: 256, Period: time.Minute, ID: "sloth"}
+}
+
+// Register 把命令行选项绑定到 s 的字段上，选项的默认值是字段的当前值
+func (s *Settings) Register(fs *flag.FlagSet) {
+	fs.StringVar(&s.Addr, "addr", s.Addr, "HTTP 监听地址")
+	fs.StringVar(&s.Params, "params", s.Params, "标准参数集名称")
+	fs.StringVar(&s.PrimeFile, "prime-file", s.PrimeFile, "十六进制素数 p 所在的文件, 或 sloth params gen 生成的参数文件")
+	fs.StringVar(&s.Prime, "prime", s.Prime, "十六进制表示的素数 p, 与 -params、-prime-file 都为空时随机生成")
+	fs.IntVar(&s.Bits, "bits", s.Bits, "随机生成素数时的位数")
+	fs.Uint64Var(&s.Iterations, "iters", s.Iterations, fmt.Sprintf("每一轮的延迟迭代次数, 0 表示参数集的推荐值, 没有参数集时为 %d", defaultIterations))
+	fs.DurationVar(&s.Period, "period", s.Period, "每一轮提交阶段的长度")
+	fs.DurationVar(&s.Target, "target", s.Target, "每一轮计算的目标耗时, 不为 0 时按之前各轮的耗时调整迭代次数 (所有节点必须相同)")
+	fs.StringVar(&s.ID, "id", s.ID, "drand 格式中的 beaconID")
+	fs.StringVar(&s.P2P, "p2p", s.P2P, "p2p 监听地址, 为空时不接受入站连接")
+	fs.Var(&s.Peers, "peers", "逗号分隔的相邻节点地址")
+	fs.StringVar(&s.Data, "data", s.Data, "轮次持久化目录, 为空时只保存在内存中")
+	fs.Var(&s.OTS, "ots", "逗号分隔的 OpenTimestamps 日历服务器地址, 不为空时把每一轮锚定到比特币 (需要 -data)")
+	fs.BoolVar(&s.Follow, "follow", s.Follow, "只从相邻节点同步轮次, 不自己发布 (所有节点必须使用相同的素数与迭代次数)")
+}
+
+// Validate 在启动之前检查设置，返回的错误指出对应的键
+func (s *Settings) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(s.Addr != "", "addr cannot be empty")
+	n := 0
+	for _, v := range []string{s.Params, s.PrimeFile, s.Prime} {
+		if v != "" {
+			n++
+		}
+	}
+	check(n <= 1, "params, prime_file and prime are mutually exclusive")
+	if s.Params != "" {
+		_, err := slothgo.LookupParamSet(s.Params)
+		check(err == nil, "params: %v", err)
+	}
+	check(n > 0 || s.Bits >= 2, "bits must be at least 2")
+	check(s.Iterations <= slothgo.MaxIterations, "iterations cannot exceed %d", slothgo.MaxIterations)
+	check(s.Period > 0, "period must be positive")
+	check(s.Target >= 0, "target cannot be negative")
+	check(!s.Follow || len(s.Peers) > 0 || s.P2P != "", "follow requires peers or p2p")
+	check(len(s.OTS) == 0 || s.Data != "", "ots requires data")
+	return errors.Join(errs...)
+}
+
+// Config 把设置转换为 Config，读取素数文件，没有指定素数时随机生成
+func (s *Settings) Config() (Config, error) {
+	cfg := Config{
+		Iterations: s.Iterations,
+		Period:     s.Period,
+		BeaconID:   s.ID,
+		DataDir:    s.Data,
+		P2PAddr:    s.P2P,
+		Peers:      s.Peers,
+		Follow:     s.Follow,
+
+		OTSCalendars: s.OTS,
+	}
+	var err error
+	switch {
+	case s.Params != "":
+		ps, err := slothgo.LookupParamSet(s.Params)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Prime, cfg.PrimeChecked = ps.Prime(), true
+		if cfg.Iterations == 0 {
+			cfg.Iterations = ps.Iterations
+		}
+	case s.PrimeFile != "":
+		cfg.Prime, err = config.ReadPrime(s.PrimeFile)
+	case s.Prime != "":
+		p, ok := new(big.Int).SetString(strings.TrimPrefix(s.Prime, "0x"), 16)
+		if !ok {
+			return cfg, errors.New("prime: invalid hexadecimal prime")
+		}
+		cfg.Prime = p
+	default:
+		cfg.Prime, err = slothgo.GenerateSlothPrime(s.Bits)
+	}
+	if cfg.Iterations == 0 {
+		cfg.Iterations = defaultIterations
+	}
+	if s.Target > 0 {
+		cfg.Difficulty = &beacon.DifficultyConfig{Target: s.Target}
+	}
+	return cfg, err
+}
@@ -0,0 +1,93 @@
+package beaconnode
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/config"
+)
+
+// TestSettings 检查配置文件、命令行选项与启动时的检查
+func TestSettings(t *testing.T) {
+	s := DefaultSettings()
+	err := config.Decode([]byte(`# 测试节点
+addr: "127.0.0.1:8080"   # 只监听本机
+params: sloth-256-t30s
+period: 30s
+target: 20s
+id: 'test#1'
+peers:
+  - beacon-1:9000
+  - "beacon-2:9000"
+follow: true
+`), &s)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := Settings{
+		Addr:   "127.0.0.1:8080",
+		Params: "sloth-256-t30s",
+		Bits:   256,
+		Period: 30 * time.Second,
+		Target: 20 * time.Second,
+		ID:     "test#1",
+		Peers:  config.List{"beacon-1:9000", "beacon-2:9000"},
+		Follow: true,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+	ps, _ := slothgo.LookupParamSet("sloth-256-t30s")
+	cfg, err := s.Config()
+	if err != nil || !cfg.PrimeChecked || cfg.Iterations != ps.Iterations || cfg.Difficulty == nil || cfg.Difficulty.Target != 20*time.Second {
+		t.Errorf("unexpected node config %+v, %v", cfg, err)
+	}
+
+	// sloth-beacon 的命令行选项绑定到同样的字段
+	s = DefaultSettings()
+	fs := flag.NewFlagSet("sloth-beacon", flag.ContinueOnError)
+	s.Register(fs)
+	if err := fs.Parse([]string{"-prime", "0x17", "-iters", "50", "-peers", "a:1,b:2"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := s.Config(); err != nil || cfg.Prime.Int64() != 0x17 || cfg.Iterations != 50 || len(cfg.Peers) != 2 {
+		t.Errorf("unexpected node config %+v, %v", cfg, err)
+	}
+
+	primeFile := filepath.Join(t.TempDir(), "p.hex")
+	os.WriteFile(primeFile, []byte("0x17\n"), 0o600)
+	s = DefaultSettings()
+	s.PrimeFile = primeFile
+	if cfg, err := s.Config(); err != nil || cfg.Prime.Int64() != 0x17 || cfg.Iterations != defaultIterations || cfg.Difficulty != nil {
+		t.Errorf("unexpected node config %+v, %v", cfg, err)
+	}
+
+	for _, tc := range []struct {
+		change func(*Settings)
+		want   string
+	}{
+		{func(s *Settings) { s.Params, s.Prime = "sloth-256-t30s", "17" }, "mutually exclusive"},
+		{func(s *Settings) { s.Params = "nope" }, "params"},
+		{func(s *Settings) { s.Period = 0 }, "period"},
+		{func(s *Settings) { s.Target = -time.Second }, "target"},
+		{func(s *Settings) { s.Iterations = slothgo.MaxIterations + 1 }, "iterations"},
+		{func(s *Settings) { s.Follow = true }, "follow requires"},
+		{func(s *Settings) { s.Addr = "" }, "addr"},
+		{func(s *Settings) { s.OTS = config.List{"http://calendar"} }, "ots requires data"},
+	} {
+		s := DefaultSettings()
+		tc.change(&s)
+		if err := s.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Validate returned %v, want an error about %s", err, tc.want)
+		}
+	}
+}
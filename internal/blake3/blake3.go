@@ -0,0 +1,234 @@
+// Package blake3 是 BLAKE3 哈希模式的一个精简移植，只支持 32 字节的默认输出
+// 结构与官方参考实现 (reference_impl.rs) 一一对应，不包含 SIMD 优化
+package blake3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// Size 是 BLAKE3 默认输出的字节数
+	Size = 32
+	// BlockSize 是 BLAKE3 压缩函数的分组字节数
+	BlockSize = 64
+
+	chunkLen = 1024
+
+	flagChunkStart = 1 << 0
+	flagChunkEnd   = 1 << 1
+	flagParent     = 1 << 2
+	flagRoot       = 1 << 3
+)
+
+var iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var msgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func g(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] += s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] += s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+func round(s *[16]uint32, m *[16]uint32) {
+	// 列
+	g(s, 0, 4, 8, 12, m[0], m[1])
+	g(s, 1, 5, 9, 13, m[2], m[3])
+	g(s, 2, 6, 10, 14, m[4], m[5])
+	g(s, 3, 7, 11, 15, m[6], m[7])
+	// 对角线
+	g(s, 0, 5, 10, 15, m[8], m[9])
+	g(s, 1, 6, 11, 12, m[10], m[11])
+	g(s, 2, 7, 8, 13, m[12], m[13])
+	g(s, 3, 4, 9, 14, m[14], m[15])
+}
+
+func compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen, flags uint32) [16]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for r := range 7 {
+		round(&s, &m)
+		if r < 6 {
+			var p [16]uint32
+			for i, j := range msgPermutation {
+				p[i] = m[j]
+			}
+			m = p
+		}
+	}
+	for i := range 8 {
+		s[i] ^= s[i+8]
+		s[i+8] ^= cv[i]
+	}
+	return s
+}
+
+func first8(w [16]uint32) [8]uint32 {
+	return [8]uint32(w[:8])
+}
+
+func wordsFromBlock(b *[BlockSize]byte) [16]uint32 {
+	var w [16]uint32
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return w
+}
+
+// output 是尚未决定是否作为根节点的压缩输入
+type output struct {
+	inputCV  [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *output) chainingValue() [8]uint32 {
+	return first8(compress(&o.inputCV, &o.block, o.counter, o.blockLen, o.flags))
+}
+
+func (o *output) rootBytes(out []byte) {
+	w := compress(&o.inputCV, &o.block, 0, o.blockLen, o.flags|flagRoot)
+	for i := 0; i < len(out); i += 4 {
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], w[i/4])
+		copy(out[i:], buf[:])
+	}
+}
+
+// chunkState 处理一个 1024 字节的块
+type chunkState struct {
+	cv               [8]uint32
+	chunkCounter     uint64
+	block            [BlockSize]byte
+	blockLen         int
+	blocksCompressed int
+}
+
+func newChunkState(key [8]uint32, chunkCounter uint64) chunkState {
+	return chunkState{cv: key, chunkCounter: chunkCounter}
+}
+
+func (c *chunkState) len() int {
+	return BlockSize*c.blocksCompressed + c.blockLen
+}
+
+func (c *chunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return flagChunkStart
+	}
+	return 0
+}
+
+func (c *chunkState) update(p []byte) {
+	for len(p) > 0 {
+		if c.blockLen == BlockSize {
+			w := wordsFromBlock(&c.block)
+			c.cv = first8(compress(&c.cv, &w, c.chunkCounter, BlockSize, c.startFlag()))
+			c.blocksCompressed++
+			c.block = [BlockSize]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], p)
+		c.blockLen += n
+		p = p[n:]
+	}
+}
+
+func (c *chunkState) output() output {
+	return output{
+		inputCV:  c.cv,
+		block:    wordsFromBlock(&c.block),
+		counter:  c.chunkCounter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.startFlag() | flagChunkEnd,
+	}
+}
+
+func parentOutput(left, right [8]uint32) output {
+	var block [16]uint32
+	copy(block[:8], left[:])
+	copy(block[8:], right[:])
+	return output{inputCV: iv, block: block, blockLen: BlockSize, flags: flagParent}
+}
+
+// digest 实现 hash.Hash
+type digest struct {
+	chunk   chunkState
+	cvStack [][8]uint32
+}
+
+// New 返回一个计算 32 字节 BLAKE3 哈希的 hash.Hash
+func New() hash.Hash {
+	return &digest{chunk: newChunkState(iv, 0)}
+}
+
+// Sum256 返回 data 的 BLAKE3 哈希
+func Sum256(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+func (d *digest) Size() int      { return Size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Reset() {
+	d.chunk = newChunkState(iv, 0)
+	d.cvStack = d.cvStack[:0]
+}
+
+// addChunkChainingValue 按已完成块数的二进制表示合并右侧的完整子树
+func (d *digest) addChunkChainingValue(cv [8]uint32, totalChunks uint64) {
+	for totalChunks&1 == 0 {
+		top := d.cvStack[len(d.cvStack)-1]
+		d.cvStack = d.cvStack[:len(d.cvStack)-1]
+		o := parentOutput(top, cv)
+		cv = o.chainingValue()
+		totalChunks >>= 1
+	}
+	d.cvStack = append(d.cvStack, cv)
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if d.chunk.len() == chunkLen {
+			o := d.chunk.output()
+			totalChunks := d.chunk.chunkCounter + 1
+			d.addChunkChainingValue(o.chainingValue(), totalChunks)
+			d.chunk = newChunkState(iv, totalChunks)
+		}
+		take := min(chunkLen-d.chunk.len(), len(p))
+		d.chunk.update(p[:take])
+		p = p[take:]
+	}
+	return n, nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	o := d.chunk.output()
+	for i := len(d.cvStack) - 1; i >= 0; i-- {
+		o = parentOutput(d.cvStack[i], o.chainingValue())
+	}
+	var out [Size]byte
+	o.rootBytes(out[:])
+	return append(b, out[:]...)
+}
@@ -0,0 +1,68 @@
+package blake3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSum256(t *testing.T) {
+	for _, tc := range []struct{ input, want string }{
+		{"", "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{"abc", "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+	} {
+		got := Sum256([]byte(tc.input))
+		if hex.EncodeToString(got[:]) != tc.want {
+			t.Errorf("Sum256(%q) = %x, want %s", tc.input, got, tc.want)
+		}
+	}
+}
+
+// TestSum256_Vectors 使用官方 test_vectors.json 中的部分用例，输入为 0, 1, ..., 250, 0, 1, ... 的循环
+func TestSum256_Vectors(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213"},
+		{1023, "10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11"},
+		{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+		{2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a"},
+		{3072, "b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd2"},
+	} {
+		input := make([]byte, tc.n)
+		for i := range input {
+			input[i] = byte(i % 251)
+		}
+		got := Sum256(input)
+		if hex.EncodeToString(got[:]) != tc.want {
+			t.Errorf("Sum256(len %d) = %x, want %s", tc.n, got, tc.want)
+		}
+	}
+}
+
+// TestWrite_Split 检查分多次写入与一次写入的结果相同，且 Sum 不改变状态
+func TestWrite_Split(t *testing.T) {
+	input := make([]byte, 5000)
+	for i := range input {
+		input[i] = byte(i * 7)
+	}
+	want := Sum256(input)
+
+	d := New()
+	for p := input; len(p) > 0; {
+		n := min(len(p), 333)
+		d.Write(p[:n])
+		p = p[n:]
+		d.Sum(nil)
+	}
+	if got := d.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("split write = %x, want %x", got, want)
+	}
+	d.Reset()
+	d.Write(input)
+	if got := d.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("after Reset = %x, want %x", got, want)
+	}
+}
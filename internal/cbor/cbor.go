@@ -0,0 +1,118 @@
+// Package cbor 实现本仓库所需的一个 CBOR (RFC 8949) 子集:
+// 无符号整数、字节串、数组与映射，编码遵循第 4.2.1 节的确定性编码规则
+// (整数与长度使用最短形式、只使用定长编码、映射的键按编码后的字节序升序排列)，
+// 解码时严格检查这些规则，不满足的输入会被拒绝
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 主类型
+const (
+	MajorUint  = 0
+	MajorBytes = 2
+	MajorArray = 4
+	MajorMap   = 5
+)
+
+// AppendHead 追加一个最短形式的头部: 主类型 major 与参数 n
+func AppendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= 0xff:
+		return append(b, m|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, m|27), n)
+}
+
+// AppendUint 追加一个无符号整数
+func AppendUint(b []byte, n uint64) []byte {
+	return AppendHead(b, MajorUint, n)
+}
+
+// AppendBytes 追加一个字节串
+func AppendBytes(b, data []byte) []byte {
+	return append(AppendHead(b, MajorBytes, uint64(len(data))), data...)
+}
+
+// Decoder 按顺序读取 CBOR 数据项
+type Decoder struct {
+	data []byte
+}
+
+// NewDecoder 返回读取 data 的 Decoder
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// Done 报告是否已经读完全部数据
+func (d *Decoder) Done() bool {
+	return len(d.data) == 0
+}
+
+// Head 读取一个头部，要求主类型为 major 且参数使用最短形式
+func (d *Decoder) Head(major byte) (uint64, error) {
+	if len(d.data) == 0 {
+		return 0, errors.New("cbor: unexpected end of data")
+	}
+	if got := d.data[0] >> 5; got != major {
+		return 0, fmt.Errorf("cbor: expected major type %d, got %d", major, got)
+	}
+	info := d.data[0] & 0x1f
+	d.data = d.data[1:]
+	var n uint64
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, errors.New("cbor: indefinite lengths and reserved values are not allowed")
+	}
+	if len(d.data) < size {
+		return 0, errors.New("cbor: unexpected end of data")
+	}
+	for _, c := range d.data[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.data = d.data[size:]
+	if len(AppendHead(nil, major, n)) != size+1 {
+		return 0, errors.New("cbor: integer is not in shortest form")
+	}
+	return n, nil
+}
+
+// Uint 读取一个无符号整数
+func (d *Decoder) Uint() (uint64, error) {
+	return d.Head(MajorUint)
+}
+
+// Bytes 读取一个字节串，返回值指向 Decoder 的输入
+func (d *Decoder) Bytes() ([]byte, error) {
+	n, err := d.Head(MajorBytes)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.data)) < n {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
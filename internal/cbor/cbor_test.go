@@ -0,0 +1,55 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestAppendUint 使用 RFC 8949 附录 A 中的编码示例
+func TestAppendUint(t *testing.T) {
+	for _, tc := range []struct {
+		n    uint64
+		want string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{1000000000000, "1b000000e8d4a51000"},
+	} {
+		enc := AppendUint(nil, tc.n)
+		if hex.EncodeToString(enc) != tc.want {
+			t.Errorf("AppendUint(%d) = %x, want %s", tc.n, enc, tc.want)
+		}
+		got, err := NewDecoder(enc).Uint()
+		if err != nil || got != tc.n {
+			t.Errorf("Uint(%x) = %d, %v", enc, got, err)
+		}
+	}
+	if got := hex.EncodeToString(AppendBytes(nil, []byte{1, 2, 3, 4})); got != "4401020304" {
+		t.Errorf("AppendBytes = %s", got)
+	}
+}
+
+func TestDecoder_Strict(t *testing.T) {
+	for name, data := range map[string]string{
+		"non-shortest": "1817",
+		"indefinite":   "5f",
+		"truncated":    "1903",
+		"short bytes":  "4401",
+	} {
+		b, _ := hex.DecodeString(data)
+		d := NewDecoder(b)
+		var err error
+		if b[0]>>5 == MajorBytes {
+			_, err = d.Bytes()
+		} else {
+			_, err = d.Uint()
+		}
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package config 从配置文件与环境变量读取服务的设置，供 slothd、sloth-beacon 与 sloth beacon run 共用
+//
+// 设置保存在结构体中，字段用 `config:"键"` 标记，命令行选项直接绑定到同一个字段上。Load 按
+// 默认值 < 配置文件 < 环境变量 < 显式指定的命令行选项 的优先级填充结构体。
+//
+// 配置文件是 YAML (由 gopkg.in/yaml.v3 解析)，顶层是一个映射，值是标量或者写成 [a, b] 或逐行 "- a"
+// 的字符串列表:
+//
+//	addr: ":7070"
+//	verify_cache_ttl: 10m   # time.ParseDuration 的格式
+//	peers:
+//	  - beacon-1:9000
+//
+// 环境变量名是前缀加上大写的键，例如前缀 SLOTHD_ 与键 verify_cache_ttl 对应 SLOTHD_VERIFY_CACHE_TTL，
+// 列表在环境变量中用逗号分隔
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// List 是字符串列表类型的设置，作为命令行选项时用逗号分隔
+type List []string
+
+// String 实现 flag.Value
+func (l *List) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+// Set 实现 flag.Value，替换原来的列表
+func (l *List) Set(s string) error {
+	*l = splitList(s)
+	return nil
+}
+
+func splitList(s string) List {
+	var l List
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			l = append(l, item)
+		}
+	}
+	return l
+}
+
+// Load 依次把配置文件 path (为空时跳过)、以 envPrefix 开头的环境变量与 fs 中显式指定的选项写入 v
+// v 必须是结构体指针; fs 必须已经解析过，其中的选项绑定到 v 的字段上
+func Load(v any, path, envPrefix string, fs *flag.FlagSet) error {
+	// 先记下显式指定的选项，最后重新设置一次，使它们覆盖配置文件与环境变量
+	explicit := make(map[string]string)
+	if fs != nil {
+		fs.Visit(func(f *flag.Flag) { explicit[f.Name] = f.Value.String() })
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := Decode(data, v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := ApplyEnv(v, envPrefix, os.LookupEnv); err != nil {
+		return err
+	}
+	for name, value := range explicit {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("-%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Decode 解析配置文件并写入 v，未知的键与重复的键都是错误
+func Decode(data []byte, v any) error {
+	fields, err := fieldsOf(v)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil // 空文件或只有注释
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected \"key: value\" pairs", root.Line)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < len(root.Content); i += 2 {
+		k, value := root.Content[i], root.Content[i+1]
+		key := k.Value
+		f, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("line %d: unknown key %q", k.Line, key)
+		}
+		if seen[key] {
+			return fmt.Errorf("line %d: duplicate key %q", k.Line, key)
+		}
+		seen[key] = true
+		if err := setNode(f, value); err != nil {
+			return fmt.Errorf("line %d: %s: %w", value.Line, key, err)
+		}
+	}
+	return nil
+}
+
+// setNode 把 YAML 的值写入字段: 列表字段接受字符串序列或空值，其余字段接受标量
+func setNode(f reflect.Value, n *yaml.Node) error {
+	if f.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode {
+		l := make(List, len(n.Content))
+		for i, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return errors.New("list items must be strings")
+			}
+			l[i] = item.Value
+		}
+		f.Set(reflect.ValueOf(l).Convert(f.Type()))
+		return nil
+	}
+	if n.Kind != yaml.ScalarNode {
+		return errors.New("expected a single value")
+	}
+	if n.ShortTag() == "!!null" {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+	return set(f, n.Value)
+}
+
+// ApplyEnv 把 lookup 返回的环境变量写入 v，没有设置的变量保持原值
+func ApplyEnv(v any, prefix string, lookup func(string) (string, bool)) error {
+	fields, err := fieldsOf(v)
+	if err != nil {
+		return err
+	}
+	for key, f := range fields {
+		name := prefix + strings.ToUpper(key)
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := set(f, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldsOf 返回 v 中带 config 标记的字段
+func fieldsOf(v any) (map[string]reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config: v must be a pointer to a struct")
+	}
+	rv = rv.Elem()
+	fields := make(map[string]reflect.Value)
+	for i := range rv.NumField() {
+		if key := rv.Type().Field(i).Tag.Get("config"); key != "" {
+			fields[key] = rv.Field(i)
+		}
+	}
+	return fields, nil
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// set 把字符串形式的值写入字段
+func set(f reflect.Value, s string) error {
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("config: unsupported type %s", f.Type())
+		}
+		f.Set(reflect.ValueOf(splitList(s)).Convert(f.Type()))
+	default:
+		return fmt.Errorf("config: unsupported type %s", f.Type())
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type settings struct {
+	Addr    string        `config:"addr"`
+	Workers int           `config:"workers"`
+	Max     uint64        `config:"max_iterations"`
+	TTL     time.Duration `config:"verify_cache_ttl"`
+	Follow  bool          `config:"follow"`
+	Peers   List          `config:"peers"`
+	Ignored string
+}
+
+// TestDecode 检查配置文件的解析与各种错误
+func TestDecode(t *testing.T) {
+	var s settings
+	err := Decode([]byte(`# 测试
+addr: "127.0.0.1:7070"   # 只监听本机
+workers: 4
+max_iterations: 1000000
+verify_cache_ttl: 10m
+follow: true
+peers:
+  - 'a:1'
+  - b:2 # 注释
+`), &s)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := settings{Addr: "127.0.0.1:7070", Workers: 4, Max: 1000000, TTL: 10 * time.Minute, Follow: true, Peers: List{"a:1", "b:2"}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if err := Decode([]byte("peers: [\"x:1\", y:2]\n"), &s); err != nil || !reflect.DeepEqual(s.Peers, List{"x:1", "y:2"}) {
+		t.Errorf("flow list decoded as %v, %v", s.Peers, err)
+	}
+
+	for name, data := range map[string]string{
+		"unknown key":      "port: 80\n",
+		"untagged field":   "Ignored: x\n",
+		"duplicate key":    "addr: :1\naddr: :2\n",
+		"invalid duration": "verify_cache_ttl: soon\n",
+		"invalid integer":  "workers: many\n",
+		"negative count":   "max_iterations: -1\n",
+		"invalid bool":     "follow: maybe\n",
+		"nested mapping":   "addr:\n  host: x\n",
+		"nested list":      "peers: [[a]]\n",
+		"list for scalar":  "addr: [a, b]\n",
+		"indented key":     "addr: :1\n  workers: 2\n",
+		"missing colon":    "addr\n",
+		"top-level list":   "- addr\n",
+	} {
+		if err := Decode([]byte(data), &s); err == nil {
+			t.Errorf("%s: config was accepted", name)
+		}
+	}
+	if err := Decode(nil, s); err == nil {
+		t.Error("non-pointer was accepted")
+	}
+}
+
+// TestLoad 检查优先级: 默认值 < 配置文件 < 环境变量 < 显式指定的命令行选项
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("addr: :1\nworkers: 2\nmax_iterations: 3\n"), 0o600)
+	t.Setenv("TEST_WORKERS", "5")
+	t.Setenv("TEST_MAX_ITERATIONS", "6")
+	t.Setenv("TEST_PEERS", "a:1, b:2")
+
+	s := settings{TTL: time.Second}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&s.Addr, "addr", ":0", "")
+	fs.IntVar(&s.Workers, "workers", 0, "")
+	fs.Uint64Var(&s.Max, "max-iterations", 0, "")
+	fs.Var(&s.Peers, "peers", "")
+	if err := fs.Parse([]string{"-max-iterations", "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(&s, path, "TEST_", fs); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := settings{Addr: ":1", Workers: 5, Max: 7, TTL: time.Second, Peers: List{"a:1", "b:2"}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+
+	t.Setenv("TEST_WORKERS", "five")
+	if err := Load(&s, "", "TEST_", nil); err == nil {
+		t.Error("invalid environment variable was accepted")
+	}
+	if err := Load(&s, filepath.Join(t.TempDir(), "missing.yaml"), "TEST_", nil); err == nil {
+		t.Error("missing config file was accepted")
+	}
+}
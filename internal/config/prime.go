@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// ReadPrime 读取素数文件，格式见 ParsePrime
+func ReadPrime(path string) (*big.Int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParsePrime(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// ParsePrime 解析十六进制的素数，允许 0x 前缀与首尾空白; 以 '{' 开头的内容按 sloth params gen
+// 生成的参数文件读取其中的素数
+func ParsePrime(data []byte) (*big.Int, error) {
+	s := strings.TrimSpace(string(data))
+	if strings.HasPrefix(s, "{") {
+		var f struct {
+			Params *slothgo.Params `json:"params"`
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		if f.Params == nil {
+			return nil, errors.New("no params")
+		}
+		return f.Params.P, nil
+	}
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	p, ok := new(big.Int).SetString(s, 16)
+	if !ok || p.Sign() <= 0 {
+		return nil, errors.New("not a hexadecimal number")
+	}
+	return p, nil
+}
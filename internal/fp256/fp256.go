@@ -0,0 +1,122 @@
+// Package fp256 实现 256 位 Montgomery 乘法与平方的定长内核，供 group.Field256 与 embedded 使用
+// amd64 上 (未设置 purego 构建标签、也不是 TinyGo 编译时) MontMul 与 MontSqr 由汇编实现，把 512 位乘积与约减全部放在寄存器中
+// 并直接用 ADCQ 传递进位; 其他平台使用本文件中基于 math/bits 的纯 Go 实现。
+// 内核单独成包是因为 group 在启用 gmp 构建标签时使用 cgo，而 cgo 包不能包含 Go 汇编文件
+package fp256
+
+import "math/bits"
+
+// Mul512 计算完整的 512 位乘积 x·y
+func Mul512(x, y *[4]uint64) (t [8]uint64) {
+	for i := range 4 {
+		var c uint64
+		for j := range 4 {
+			hi, lo := bits.Mul64(x[j], y[i])
+			lo, cc := bits.Add64(lo, t[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[i+j], c = lo, hi
+		}
+		t[i+4] = c
+	}
+	return t
+}
+
+// Sqr512 计算完整的 512 位平方 x²
+// 交叉项 x[i]·x[j] (i < j) 只算一次再整体左移一位，最后加上对角项，乘法次数从 16 降为 10
+func Sqr512(x *[4]uint64) (t [8]uint64) {
+	x0, x1, x2, x3 := x[0], x[1], x[2], x[3]
+	var c, t1, t2, t3, t4, t5, t6, t7 uint64
+
+	// 交叉项
+	t2, t1 = bits.Mul64(x0, x1)
+	c, t2 = madd(x0, x2, t2, 0)
+	t4, t3 = madd(x0, x3, c, 0)
+	c, t3 = madd(x1, x2, t3, 0)
+	c, t4 = madd(x1, x3, t4, c)
+	t6, t5 = madd(x2, x3, c, 0)
+
+	// 乘以 2
+	t7 = t6 >> 63
+	t6 = t6<<1 | t5>>63
+	t5 = t5<<1 | t4>>63
+	t4 = t4<<1 | t3>>63
+	t3 = t3<<1 | t2>>63
+	t2 = t2<<1 | t1>>63
+	t1 <<= 1
+
+	// 对角项
+	hi, lo := bits.Mul64(x0, x0)
+	t[0] = lo
+	t[1], c = bits.Add64(t1, hi, 0)
+	hi, lo = bits.Mul64(x1, x1)
+	t[2], c = bits.Add64(t2, lo, c)
+	t[3], c = bits.Add64(t3, hi, c)
+	hi, lo = bits.Mul64(x2, x2)
+	t[4], c = bits.Add64(t4, lo, c)
+	t[5], c = bits.Add64(t5, hi, c)
+	hi, lo = bits.Mul64(x3, x3)
+	t[6], c = bits.Add64(t6, lo, c)
+	t[7], _ = bits.Add64(t7, hi, c)
+	return t
+}
+
+// madd 返回 a·b + c + d 的高低 64 位，结果不会超过 128 位
+func madd(a, b, c, d uint64) (hi, lo uint64) {
+	hi, lo = bits.Mul64(a, b)
+	var cc uint64
+	lo, cc = bits.Add64(lo, c, 0)
+	hi += cc
+	lo, cc = bits.Add64(lo, d, 0)
+	hi += cc
+	return hi, lo
+}
+
+// Reduce 对 t < m·2^256 做 Montgomery 约减，返回 t·2^-256 mod m，mInv = -m⁻¹ mod 2^64
+// 四轮完全展开并把 m 读入局部变量，使所有中间值都留在寄存器中
+func Reduce(t *[8]uint64, m *[4]uint64, mInv uint64) (z [4]uint64) {
+	m0, m1, m2, m3, inv := m[0], m[1], m[2], m[3], mInv
+	t0, t1, t2, t3, t4, t5, t6, t7 := t[0], t[1], t[2], t[3], t[4], t[5], t[6], t[7]
+	var c, top uint64 // top 是 t7 之上的进位
+
+	// 每一轮选取 q 使当前最低 limb 变为 0，再把 q·p 的进位传到 t_{i+4}
+	q := t0 * inv
+	c, _ = madd(q, m0, t0, 0)
+	c, t1 = madd(q, m1, t1, c)
+	c, t2 = madd(q, m2, t2, c)
+	c, t3 = madd(q, m3, t3, c)
+	t4, top = bits.Add64(t4, c, 0)
+
+	q = t1 * inv
+	c, _ = madd(q, m0, t1, 0)
+	c, t2 = madd(q, m1, t2, c)
+	c, t3 = madd(q, m2, t3, c)
+	c, t4 = madd(q, m3, t4, c)
+	t5, top = bits.Add64(t5, c, top)
+
+	q = t2 * inv
+	c, _ = madd(q, m0, t2, 0)
+	c, t3 = madd(q, m1, t3, c)
+	c, t4 = madd(q, m2, t4, c)
+	c, t5 = madd(q, m3, t5, c)
+	t6, top = bits.Add64(t6, c, top)
+
+	q = t3 * inv
+	c, _ = madd(q, m0, t3, 0)
+	c, t4 = madd(q, m1, t4, c)
+	c, t5 = madd(q, m2, t5, c)
+	c, t6 = madd(q, m3, t6, c)
+	t7, top = bits.Add64(t7, c, top)
+
+	// 结果小于 2p，最多再减一次 p
+	var b uint64
+	z[0], b = bits.Sub64(t4, m0, 0)
+	z[1], b = bits.Sub64(t5, m1, b)
+	z[2], b = bits.Sub64(t6, m2, b)
+	z[3], b = bits.Sub64(t7, m3, b)
+	if top < b {
+		z = [4]uint64{t4, t5, t6, t7}
+	}
+	return z
+}
@@ -0,0 +1,88 @@
+package fp256
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func toLimbs(a *big.Int) (z [4]uint64) {
+	var buf [32]byte
+	a.FillBytes(buf[:])
+	for i := range z {
+		z[i] = new(big.Int).SetBytes(buf[24-8*i : 32-8*i]).Uint64()
+	}
+	return z
+}
+
+func fromLimbs(x [4]uint64) *big.Int {
+	z := new(big.Int)
+	for i := 3; i >= 0; i-- {
+		z.Lsh(z, 64).Or(z, new(big.Int).SetUint64(x[i]))
+	}
+	return z
+}
+
+// TestKernels 把 MontMul/MontSqr (amd64 上为汇编实现)、纯 Go 的 Mul512/Sqr512 + Reduce 与 big.Int 的结果逐一比较，
+// 包括 0、1、m-1、m-2 等边界值以及高位全为 1 的模数
+func TestKernels(t *testing.T) {
+	m256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	m256.Sub(m256, big.NewInt(189))
+	p, err := rand.Prime(rand.Reader, 255)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mod := range []*big.Int{m256, p, big.NewInt(1000003)} {
+		m := toLimbs(mod)
+		mInv := uint64(1)
+		for range 6 {
+			mInv *= 2 - m[0]*mInv
+		}
+		mInv = -mInv
+		rInv := new(big.Int).Lsh(big.NewInt(1), 256)
+		rInv.ModInverse(rInv, mod)
+
+		edges := []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(mod, big.NewInt(1)), new(big.Int).Sub(mod, big.NewInt(2))}
+		for i := range 2000 {
+			var a, b *big.Int
+			if i < len(edges)*len(edges) {
+				a, b = edges[i/len(edges)], edges[i%len(edges)]
+			} else {
+				a, _ = rand.Int(rand.Reader, mod)
+				b, _ = rand.Int(rand.Reader, mod)
+			}
+			want := new(big.Int).Mul(a, b)
+			want.Mul(want, rInv).Mod(want, mod)
+			x, y := toLimbs(a), toLimbs(b)
+
+			var z [4]uint64
+			MontMul(&z, &x, &y, &m, mInv)
+			tt := Mul512(&x, &y)
+			if r := Reduce(&tt, &m, mInv); fromLimbs(z).Cmp(want) != 0 || r != z {
+				t.Fatalf("MontMul(%x, %x) mod %x = %x, want %x", a, b, mod, fromLimbs(z), want)
+			}
+
+			want.Mul(a, a).Mul(want, rInv).Mod(want, mod)
+			MontSqr(&z, &x, &m, mInv)
+			tt = Sqr512(&x)
+			if r := Reduce(&tt, &m, mInv); fromLimbs(z).Cmp(want) != 0 || r != z {
+				t.Fatalf("MontSqr(%x) mod %x = %x, want %x", a, mod, fromLimbs(z), want)
+			}
+		}
+	}
+}
+
+func BenchmarkMontSqr(b *testing.B) {
+	mod := new(big.Int).Lsh(big.NewInt(1), 256)
+	mod.Sub(mod, big.NewInt(189))
+	m := toLimbs(mod)
+	mInv := uint64(1)
+	for range 6 {
+		mInv *= 2 - m[0]*mInv
+	}
+	mInv = -mInv
+	x := toLimbs(big.NewInt(123456789))
+	for b.Loop() {
+		MontSqr(&x, &x, &m, mInv)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build amd64 && !purego && !tinygo
+
+package fp256
+
+// MontMul 把 x·y·2^-256 mod m 写入 z，要求 x, y < m
+//
+//go:noescape
+func MontMul(z, x, y, m *[4]uint64, mInv uint64)
+
+// MontSqr 把 x²·2^-256 mod m 写入 z，要求 x < m
+//
+//go:noescape
+func MontSqr(z, x, m *[4]uint64, mInv uint64)
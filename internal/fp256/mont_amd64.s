@@ -0,0 +1,542 @@
+//go:build amd64 && !purego && !tinygo
+
+#include "textflag.h"
+
+// 两个函数都把 512 位乘积 t7..t0 放在 R15..R8 中，再做四轮 Montgomery 约减 (SOS)，
+// 全部中间值都留在寄存器中，进位直接由 ADCQ 链传递
+
+// func MontMul(z, x, y, m *[4]uint64, mInv uint64)
+TEXT ·MontMul(SB), NOSPLIT, $0-40
+	MOVQ x+8(FP), SI
+	MOVQ y+16(FP), DI
+
+	// t += x·y[0]·2^(64·0)
+	MOVQ 0(DI), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	MOVQ AX, R8
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	MOVQ AX, R9
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	MOVQ AX, R10
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	MOVQ AX, R11
+	MOVQ DX, BX
+	MOVQ BX, R12
+
+	// t += x·y[1]·2^(64·1)
+	MOVQ 8(DI), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ AX, R9
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ BX, R13
+
+	// t += x·y[2]·2^(64·2)
+	MOVQ 16(DI), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ BX, R14
+
+	// t += x·y[3]·2^(64·3)
+	MOVQ 24(DI), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R14
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ BX, R15
+
+	MOVQ m+24(FP), SI
+	XORQ DI, DI // DI 是 t7 之上的进位
+
+	// 第 0 轮: q = t0·mInv，t += q·m·2^(64·0)，t0 变为 0
+	MOVQ R8, CX
+	IMULQ mInv+32(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R8, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R9
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R12
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R12
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 1 轮: q = t1·mInv，t += q·m·2^(64·1)，t1 变为 0
+	MOVQ R9, CX
+	IMULQ mInv+32(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R9, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R13
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R13
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 2 轮: q = t2·mInv，t += q·m·2^(64·2)，t2 变为 0
+	MOVQ R10, CX
+	IMULQ mInv+32(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R10, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R14
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R14
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 3 轮: q = t3·mInv，t += q·m·2^(64·3)，t3 变为 0
+	MOVQ R11, CX
+	IMULQ mInv+32(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R11, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R14
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R15
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R15
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 结果 (DI:t7..t4) 小于 2p，减去 p; 若不够减则保留原值
+	MOVQ R12, AX
+	SUBQ 0(SI), AX
+	MOVQ R13, BX
+	SBBQ 8(SI), BX
+	MOVQ R14, CX
+	SBBQ 16(SI), CX
+	MOVQ R15, DX
+	SBBQ 24(SI), DX
+	SBBQ $0, DI
+	CMOVQCS R12, AX
+	CMOVQCS R13, BX
+	CMOVQCS R14, CX
+	CMOVQCS R15, DX
+	MOVQ z+0(FP), SI
+	MOVQ AX, 0(SI)
+	MOVQ BX, 8(SI)
+	MOVQ CX, 16(SI)
+	MOVQ DX, 24(SI)
+	RET
+
+// func MontSqr(z, x, m *[4]uint64, mInv uint64)
+// 交叉项 x[i]·x[j] (i < j) 只算一次再整体左移一位，最后加上对角项，共 10 次乘法
+TEXT ·MontSqr(SB), NOSPLIT, $0-32
+	MOVQ x+8(FP), SI
+
+	// 交叉项 t6..t1
+	MOVQ 0(SI), CX
+	MOVQ 8(SI), AX
+	MULQ CX
+	MOVQ AX, R9
+	MOVQ DX, R10
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, R11
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, R12
+	MOVQ 8(SI), CX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, R13
+	MOVQ 16(SI), CX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, R14
+
+	// 乘以 2
+	XORQ R15, R15
+	ADDQ R9, R9
+	ADCQ R10, R10
+	ADCQ R11, R11
+	ADCQ R12, R12
+	ADCQ R13, R13
+	ADCQ R14, R14
+	ADCQ $0, R15
+
+	// 对角项: MULQ 会改写标志位，进位暂存在 BX 中，再用 BTQ 恢复到 CF
+	XORQ BX, BX
+	MOVQ 0(SI), AX
+	MULQ AX
+	MOVQ AX, R8
+	ADDQ DX, R9
+	MOVQ $0, BX
+	ADCQ $0, BX
+	MOVQ 8(SI), AX
+	MULQ AX
+	BTQ $0, BX
+	ADCQ AX, R10
+	ADCQ DX, R11
+	MOVQ $0, BX
+	ADCQ $0, BX
+	MOVQ 16(SI), AX
+	MULQ AX
+	BTQ $0, BX
+	ADCQ AX, R12
+	ADCQ DX, R13
+	MOVQ $0, BX
+	ADCQ $0, BX
+	MOVQ 24(SI), AX
+	MULQ AX
+	BTQ $0, BX
+	ADCQ AX, R14
+	ADCQ DX, R15
+
+	MOVQ m+16(FP), SI
+	XORQ DI, DI // DI 是 t7 之上的进位
+
+	// 第 0 轮: q = t0·mInv，t += q·m·2^(64·0)，t0 变为 0
+	MOVQ R8, CX
+	IMULQ mInv+24(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R8, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R9
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R12
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R12
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 1 轮: q = t1·mInv，t += q·m·2^(64·1)，t1 变为 0
+	MOVQ R9, CX
+	IMULQ mInv+24(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R9, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R10
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R13
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R13
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 2 轮: q = t2·mInv，t += q·m·2^(64·2)，t2 变为 0
+	MOVQ R10, CX
+	IMULQ mInv+24(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R10, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R11
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R14
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R14
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 第 3 轮: q = t3·mInv，t += q·m·2^(64·3)，t3 变为 0
+	MOVQ R11, CX
+	IMULQ mInv+24(FP), CX
+	MOVQ 0(SI), AX
+	MULQ CX
+	ADDQ R11, AX
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 8(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R12
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 16(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R13
+	ADCQ $0, DX
+	MOVQ DX, BX
+	MOVQ 24(SI), AX
+	MULQ CX
+	ADDQ BX, AX
+	ADCQ $0, DX
+	ADDQ AX, R14
+	ADCQ $0, DX
+	MOVQ DX, BX
+	ADDQ BX, R15
+	MOVQ $0, BX
+	ADCQ $0, BX
+	ADDQ DI, R15
+	ADCQ $0, BX
+	MOVQ BX, DI
+
+	// 结果 (DI:t7..t4) 小于 2p，减去 p; 若不够减则保留原值
+	MOVQ R12, AX
+	SUBQ 0(SI), AX
+	MOVQ R13, BX
+	SBBQ 8(SI), BX
+	MOVQ R14, CX
+	SBBQ 16(SI), CX
+	MOVQ R15, DX
+	SBBQ 24(SI), DX
+	SBBQ $0, DI
+	CMOVQCS R12, AX
+	CMOVQCS R13, BX
+	CMOVQCS R14, CX
+	CMOVQCS R15, DX
+	MOVQ z+0(FP), SI
+	MOVQ AX, 0(SI)
+	MOVQ BX, 8(SI)
+	MOVQ CX, 16(SI)
+	MOVQ DX, 24(SI)
+	RET
@@ -0,0 +1,15 @@
+//go:build !amd64 || purego || tinygo
+
+package fp256
+
+// MontMul 把 x·y·2^-256 mod m 写入 z，要求 x, y < m
+func MontMul(z, x, y, m *[4]uint64, mInv uint64) {
+	t := Mul512(x, y)
+	*z = Reduce(&t, m, mInv)
+}
+
+// MontSqr 把 x²·2^-256 mod m 写入 z，要求 x < m
+func MontSqr(z, x, m *[4]uint64, mInv uint64) {
+	t := Sqr512(x)
+	*z = Reduce(&t, m, mInv)
+}
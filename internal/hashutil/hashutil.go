@@ -0,0 +1,100 @@
+// Package hashutil 提供各个 VDF 构造共享的哈希辅助函数
+package hashutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// Expand 以计数器模式扩展 SHA-256，输出 n 字节: H(domain || counter || data) || ...
+func Expand(domain string, data []byte, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	for counter := uint32(0); len(out) < n; counter++ {
+		h := sha256.New()
+		h.Write([]byte(domain))
+		binary.Write(h, binary.BigEndian, counter)
+		h.Write(data)
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+// ToModulus 把 data 映射为 [2, m-1] 中的整数
+// 先扩展出比 m 多 128 位的哈希值再取模，以降低取模偏差
+func ToModulus(domain string, data []byte, m *big.Int) *big.Int {
+	x := new(big.Int).SetBytes(Expand(domain, data, (m.BitLen()+7)/8+16))
+	x.Mod(x, m)
+	if x.Cmp(big.NewInt(1)) <= 0 {
+		x.SetInt64(2)
+	}
+	return x
+}
+
+// ToPrime 由 data 派生一个 bits 位的素数 (最高位与最低位均为 1)
+func ToPrime(domain string, data []byte, bits int) *big.Int {
+	for counter := uint64(0); ; counter++ {
+		buf := binary.BigEndian.AppendUint64(nil, counter)
+		l := new(big.Int).SetBytes(Expand(domain, append(buf, data...), (bits+7)/8))
+		l.Rsh(l, uint(l.BitLen()-bits))
+		l.SetBit(l, bits-1, 1)
+		l.SetBit(l, 0, 1)
+		if l.ProbablyPrime(20) {
+			return l
+		}
+	}
+}
+
+// ExpandMessageXMD 实现 RFC 9380 第 5.3.1 节的 expand_message_xmd，输出 n 字节
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, n int) ([]byte, error) {
+	return ExpandMessageXMDReader(h, bytes.NewReader(msg), dst, n)
+}
+
+// ExpandMessageXMDReader 与 ExpandMessageXMD 相同，但以流的方式从 r 读取消息
+// 超过 255 字节的 dst 按 RFC 9380 第 5.3.3 节先做哈希
+func ExpandMessageXMDReader(h func() hash.Hash, r io.Reader, dst []byte, n int) ([]byte, error) {
+	if len(dst) > 255 {
+		hasher := h()
+		hasher.Write([]byte("H2C-OVERSIZE-DST-"))
+		hasher.Write(dst)
+		dst = hasher.Sum(nil)
+	}
+	hasher := h()
+	bInBytes, rInBytes := hasher.Size(), hasher.BlockSize()
+	ell := (n + bInBytes - 1) / bInBytes
+	if ell > 255 || n > 0xffff || n <= 0 {
+		return nil, fmt.Errorf("expand_message_xmd cannot produce %d bytes", n)
+	}
+	dstPrime := append(bytes.Clone(dst), byte(len(dst)))
+
+	// b₀ = H(Z_pad || msg || I2OSP(n, 2) || I2OSP(0, 1) || DST_prime)
+	hasher.Write(make([]byte, rInBytes))
+	if _, err := io.Copy(hasher, r); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	binary.Write(hasher, binary.BigEndian, uint16(n))
+	hasher.Write([]byte{0})
+	hasher.Write(dstPrime)
+	b0 := hasher.Sum(nil)
+
+	// bᵢ = H(strxor(b₀, bᵢ₋₁) || I2OSP(i, 1) || DST_prime)，b₁ 中的 bᵢ₋₁ 视为全零
+	out := make([]byte, 0, ell*bInBytes)
+	prev := make([]byte, bInBytes)
+	for i := 1; i <= ell; i++ {
+		x := make([]byte, bInBytes)
+		for j := range x {
+			x[j] = b0[j] ^ prev[j]
+		}
+		hasher = h()
+		hasher.Write(x)
+		hasher.Write([]byte{byte(i)})
+		hasher.Write(dstPrime)
+		prev = hasher.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:n], nil
+}
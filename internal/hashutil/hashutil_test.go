@@ -0,0 +1,31 @@
+package hashutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// TestExpandMessageXMD 使用 RFC 9380 附录 K.1 中 expand_message_xmd(SHA-256) 的测试向量
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	for _, tc := range []struct {
+		msg  string
+		n    int
+		want string
+	}{
+		{"", 0x20, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", 0x20, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	} {
+		got, err := ExpandMessageXMD(sha256.New, []byte(tc.msg), dst, tc.n)
+		if err != nil {
+			t.Fatalf("ExpandMessageXMD failed: %v", err)
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("ExpandMessageXMD(%q) = %x, want %s", tc.msg, got, tc.want)
+		}
+	}
+	if _, err := ExpandMessageXMD(sha256.New, nil, dst, 256*32); err == nil {
+		t.Error("expected error for output longer than 255 blocks")
+	}
+}
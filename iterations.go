@@ -0,0 +1,36 @@
+package slothgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxIterations 是允许的最大迭代次数 (2^63 - 1)
+// 即使每次迭代只需 1ns，这也相当于两百多年，远超十年级别的时间锁;
+// 这是所有编码 (包括 ASN.1 INTEGER) 都能表示的最大值，两个不超过它的量相加也不会在 uint64 中溢出
+const MaxIterations uint64 = 1<<63 - 1
+
+// checkIterations 检查迭代次数是否在 [1, MaxIterations] 内
+func checkIterations(n uint64) error {
+	if n == 0 {
+		return errors.New("iterations must be positive")
+	}
+	if n > MaxIterations {
+		return fmt.Errorf("iterations %d exceed the maximum %d", n, MaxIterations)
+	}
+	return nil
+}
+
+// ceilDiv 计算 ⌈a / b⌉，b > 0，不会因 a + b - 1 溢出
+func ceilDiv(a, b uint64) uint64 {
+	q := a / b
+	if a%b != 0 {
+		q++
+	}
+	return q
+}
+
+// stepEnd 返回从 i 出发前进 k 步、但不超过 n 的位置，i <= n，k > 0，不会因 i + k 溢出
+func stepEnd(i, k, n uint64) uint64 {
+	return i + min(k, n-i)
+}
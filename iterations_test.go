@@ -0,0 +1,62 @@
+package slothgo
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestMaxIterations(t *testing.T) {
+	if _, err := New(testVDF.P, MaxIterations); err != nil {
+		t.Errorf("New rejected MaxIterations: %v", err)
+	}
+	if _, err := New(testVDF.P, MaxIterations+1); err == nil {
+		t.Error("New accepted more than MaxIterations")
+	}
+	if _, err := testVDF.WithIterations(math.MaxUint64); err == nil {
+		t.Error("WithIterations accepted more than MaxIterations")
+	}
+}
+
+func TestIterationArithmetic(t *testing.T) {
+	if got := ceilDiv(MaxIterations, math.MaxUint64); got != 1 {
+		t.Errorf("ceilDiv(MaxIterations, MaxUint64) = %d, want 1", got)
+	}
+	if got := ceilDiv(10, 3); got != 4 {
+		t.Errorf("ceilDiv(10, 3) = %d, want 4", got)
+	}
+	if got := stepEnd(MaxIterations-1, math.MaxUint64, MaxIterations); got != MaxIterations {
+		t.Errorf("stepEnd overflowed: %d", got)
+	}
+}
+
+// TestHugeIntervals 检查接近上限的间隔不会使循环溢出
+func TestHugeIntervals(t *testing.T) {
+	ctx := context.Background()
+	vdf, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := vdf.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if ok, err := vdf.VerifyProof(testInput, proof); !ok || err != nil {
+		t.Fatalf("VerifyProof failed: %v, %v", ok, err)
+	}
+
+	var saved *Checkpoint
+	if _, _, err := testVDF.ComputeCheckpointed(ctx, testInput, testIterations/2, func(cp *Checkpoint) error {
+		saved = cp
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := testVDF.ResumeFrom(ctx, saved, math.MaxUint64, nil); err != nil {
+		t.Fatalf("ResumeFrom with a huge interval failed: %v", err)
+	}
+
+	if _, _, err := testVDF.ComputeStateTree(ctx, testInput, math.MaxUint64); err != nil {
+		t.Fatalf("ComputeStateTree with a huge stride failed: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+)
+
+// journalMagic 是计算日志文件的魔数与格式版本
+var journalMagic = []byte("SLJ\x01")
+
+// journalMaxStride 是两次检查是否需要写入快照之间的最大迭代次数
+const journalMaxStride = 1 << 16
+
+// ErrJournalCorrupt 表示日志文件被截断或 HMAC 校验失败，文件不会被自动删除
+var ErrJournalCorrupt = errors.New("slothgo: journal is corrupt or was written with a different key")
+
+// Journal 是长时间计算的崩溃恢复日志: ComputeJournaled 在计算过程中定期把当前状态
+// (迭代次数、中间值 w、参数指纹与起点摘要) 连同 HMAC 原子地写入 path，
+// 进程重启后以相同的参数、输入与日志再次调用即从最近的快照继续，计算完成后删除日志
+//
+// 快照格式: 魔数 "SLJ\x01"(4) | 指纹长度(1) | 指纹 | 起点摘要(32) | 迭代次数(8) | w (定长) | HMAC-SHA256(32)
+type Journal struct {
+	path   string
+	key    []byte
+	period time.Duration
+}
+
+// NewJournal 创建写入 path 的日志，key 是 HMAC 密钥 (不能为空)，period 是两次写入快照的最小间隔，
+// period <= 0 时每次检查都写入。崩溃时最多丢失 period 加上一次检查间隔的计算，
+// 计算因 ctx 被取消而中止时立即写入最近一次检查的状态
+func NewJournal(path string, key []byte, period time.Duration) (*Journal, error) {
+	if path == "" {
+		return nil, errors.New("journal path cannot be empty")
+	}
+	if len(key) == 0 {
+		return nil, errors.New("journal key cannot be empty")
+	}
+	return &Journal{path: path, key: bytes.Clone(key), period: period}, nil
+}
+
+// Remove 删除日志文件，文件不存在时不返回错误
+func (j *Journal) Remove() error {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ComputeJournaled 与 ComputeCtx 相同，但把进度记录在 j 中: j 中已有同一参数与输入的快照时从快照继续，
+// 计算完成后删除日志。日志属于其他参数或输入时返回错误，日志损坏时返回 ErrJournalCorrupt
+func (s *Sloth) ComputeJournaled(ctx context.Context, input []byte, j *Journal) (hash []byte, witness *big.Int, err error) {
+	if input == nil {
+		return nil, nil, errors.New("input cannot be nil")
+	}
+	return s.computeJournaled(ctx, s.initialValue(input), j)
+}
+
+// ComputeFromJournaled 与 ComputeJournaled 相同，但从给定的起点 w₀ 开始，例如时间锁的公开起点
+func (s *Sloth) ComputeFromJournaled(ctx context.Context, start *big.Int, j *Journal) (hash []byte, witness *big.Int, err error) {
+	if start == nil {
+		return nil, nil, errors.New("start value cannot be nil")
+	}
+	if err := s.checkState(start, 0); err != nil {
+		return nil, nil, err
+	}
+	return s.computeJournaled(ctx, start, j)
+}
+
+func (s *Sloth) computeJournaled(ctx context.Context, start *big.Int, j *Journal) ([]byte, *big.Int, error) {
+	if j == nil {
+		return nil, nil, errors.New("journal cannot be nil")
+	}
+	digest := s.journalDigest(start)
+	cp, err := j.load(s, digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, iteration := new(big.Int).Set(start), uint64(0)
+	if cp != nil {
+		w, iteration = cp.W, cp.Iteration
+	}
+
+	// 每 stride 次迭代检查一次，距离上次写入超过 period 时写入快照
+	// 计算被取消时写入最近一次检查的状态，正常关闭的进程最多丢失一个 stride 的计算
+	stride := min(max(s.Iterations/1000, 1), journalMaxStride)
+	last := time.Now()
+	var unsaved *Checkpoint
+	hash, witness, err := s.computeCheckpointed(ctx, w, iteration, stride, func(cp *Checkpoint) error {
+		if time.Since(last) < j.period {
+			unsaved = cp
+			return nil
+		}
+		last, unsaved = time.Now(), nil
+		return j.save(cp, digest)
+	})
+	if err != nil {
+		if unsaved != nil && ctx.Err() != nil {
+			if serr := j.save(unsaved, digest); serr != nil {
+				return nil, nil, errors.Join(err, fmt.Errorf("failed to save journal: %w", serr))
+			}
+		}
+		return nil, nil, err
+	}
+	if err := j.Remove(); err != nil {
+		return nil, nil, fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return hash, witness, nil
+}
+
+// journalDigest 返回起点的摘要，快照只能用于恢复从同一起点开始的计算
+func (s *Sloth) journalDigest(start *big.Int) []byte {
+	sum := sha256.Sum256(encodeFixed(start, s.WitnessSize()))
+	return sum[:]
+}
+
+// load 读取并校验日志中的快照，没有日志时返回 nil
+func (j *Journal) load(s *Sloth, digest []byte) (*Checkpoint, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(journalMagic)+1+sha256.Size || !bytes.HasPrefix(data, journalMagic) {
+		return nil, ErrJournalCorrupt
+	}
+	body, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(mac, j.mac(body)) {
+		return nil, ErrJournalCorrupt
+	}
+	body = body[len(journalMagic):]
+	fpLen := int(body[0])
+	body = body[1:]
+	if len(body) < fpLen+sha256.Size+8 {
+		return nil, ErrJournalCorrupt
+	}
+	if !bytes.Equal(body[:fpLen], s.Fingerprint()) {
+		return nil, errors.New("journal was written with different parameters")
+	}
+	body = body[fpLen:]
+	if !bytes.Equal(body[:sha256.Size], digest) {
+		return nil, errors.New("journal was written for a different input")
+	}
+	body = body[sha256.Size:]
+	cp := &Checkpoint{
+		Iteration:   binary.BigEndian.Uint64(body),
+		W:           new(big.Int).SetBytes(body[8:]),
+		Fingerprint: s.Fingerprint(),
+		size:        s.WitnessSize(),
+	}
+	if err := s.checkState(cp.W, cp.Iteration); err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	return cp, nil
+}
+
+// save 原子地写入快照，返回时快照已同步到磁盘
+func (j *Journal) save(cp *Checkpoint, digest []byte) error {
+	var buf bytes.Buffer
+	buf.Write(journalMagic)
+	buf.WriteByte(byte(len(cp.Fingerprint)))
+	buf.Write(cp.Fingerprint)
+	buf.Write(digest)
+	binary.Write(&buf, binary.BigEndian, cp.Iteration)
+	buf.Write(encodeFixed(cp.W, cp.size))
+	buf.Write(j.mac(buf.Bytes()))
+
+	return atomicfile.WriteFile(j.path, buf.Bytes())
+}
+
+func (j *Journal) mac(data []byte) []byte {
+	m := hmac.New(sha256.New, j.key)
+	m.Write(data)
+	return m.Sum(nil)
+}
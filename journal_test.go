@@ -0,0 +1,100 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestComputeJournaled 模拟计算中途崩溃: 重新调用后从快照继续，结果与一次完成的计算相同
+func TestComputeJournaled(t *testing.T) {
+	const iterations = 20000
+	path := filepath.Join(t.TempDir(), "job.journal")
+	j, err := NewJournal(path, []byte("secret"), 0)
+	if err != nil {
+		t.Fatalf("NewJournal failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	crashing, err := New(testVDF.P, iterations, WithProgressInfo(func(pi ProgressInfo) {
+		if pi.Done >= iterations/2 {
+			cancel()
+		}
+	}, 100))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, _, err := crashing.ComputeJournaled(ctx, testInput, j); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the computation to be canceled, got %v", err)
+	}
+
+	vdf, _ := New(testVDF.P, iterations)
+	cp, err := j.load(vdf, vdf.journalDigest(vdf.initialValue(testInput)))
+	if err != nil || cp == nil || cp.Iteration < iterations/4 || cp.Iteration >= iterations {
+		t.Fatalf("unexpected snapshot %+v, %v", cp, err)
+	}
+
+	// 其他输入、其他密钥与被篡改的日志都不能用于恢复
+	if _, _, err := vdf.ComputeJournaled(context.Background(), []byte("other"), j); err == nil {
+		t.Error("journal was resumed for a different input")
+	}
+	other, _ := NewJournal(path, []byte("wrong"), 0)
+	if _, _, err := vdf.ComputeJournaled(context.Background(), testInput, other); !errors.Is(err, ErrJournalCorrupt) {
+		t.Errorf("journal with a different key returned %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	tampered := filepath.Join(t.TempDir(), "tampered.journal")
+	data[len(data)-40] ^= 1
+	os.WriteFile(tampered, data, 0o600)
+	tj, _ := NewJournal(tampered, []byte("secret"), 0)
+	if _, _, err := vdf.ComputeJournaled(context.Background(), testInput, tj); !errors.Is(err, ErrJournalCorrupt) {
+		t.Errorf("tampered journal returned %v", err)
+	}
+
+	hash, witness, err := vdf.ComputeJournaled(context.Background(), testInput, j)
+	if err != nil {
+		t.Fatalf("resumed computation failed: %v", err)
+	}
+	wantHash, wantWitness, _ := vdf.Compute(testInput)
+	if string(hash) != string(wantHash) || witness.Cmp(wantWitness) != 0 {
+		t.Fatal("resumed computation differs from an uninterrupted one")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal was not removed after completion: %v", err)
+	}
+}
+
+// TestComputeJournaledCancel 检查写入间隔很长时，取消计算仍然立即写入快照
+func TestComputeJournaledCancel(t *testing.T) {
+	const iterations = 20000
+	path := filepath.Join(t.TempDir(), "job.journal")
+	j, _ := NewJournal(path, []byte("secret"), time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vdf, _ := New(testVDF.P, iterations, WithProgressInfo(func(pi ProgressInfo) {
+		if pi.Done >= iterations/2 {
+			cancel()
+		}
+	}, 100))
+	if _, _, err := vdf.ComputeJournaled(ctx, testInput, j); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the computation to be canceled, got %v", err)
+	}
+	cp, err := j.load(vdf, vdf.journalDigest(vdf.initialValue(testInput)))
+	if err != nil || cp == nil || cp.Iteration < iterations/4 {
+		t.Fatalf("no snapshot was written on cancellation: %+v, %v", cp, err)
+	}
+}
+
+// TestNewJournal 检查参数校验
+func TestNewJournal(t *testing.T) {
+	if _, err := NewJournal("x", nil, 0); err == nil {
+		t.Error("empty key was accepted")
+	}
+	if _, err := NewJournal("", []byte("k"), 0); err == nil {
+		t.Error("empty path was accepted")
+	}
+}
@@ -0,0 +1,209 @@
+package slothgo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// proofJSON 是 Proof 的 JSON 表示，字节数组与大整数均为十六进制字符串
+type proofJSON struct {
+	Hash               string   `json:"hash"`
+	Witness            string   `json:"witness"`
+	Iterations         uint64   `json:"iterations"`
+	Fingerprint        string   `json:"fingerprint"`
+	CheckpointInterval uint64   `json:"checkpoint_interval,omitempty"`
+	Checkpoints        []string `json:"checkpoints,omitempty"`
+	StateStride        uint64   `json:"state_stride,omitempty"`
+	StateRoot          string   `json:"state_root,omitempty"`
+}
+
+// MarshalJSON 实现 json.Marshaler，witness 与检查点使用定长编码
+func (p Proof) MarshalJSON() ([]byte, error) {
+	if p.Witness == nil {
+		return nil, errors.New("proof witness cannot be nil")
+	}
+	v := proofJSON{
+		Hash:               hex.EncodeToString(p.Hash),
+		Witness:            hex.EncodeToString(encodeFixed(p.Witness, p.size)),
+		Iterations:         p.Iterations,
+		Fingerprint:        hex.EncodeToString(p.Fingerprint),
+		CheckpointInterval: p.CheckpointInterval,
+		StateStride:        p.StateStride,
+		StateRoot:          hex.EncodeToString(p.StateRoot),
+	}
+	for _, c := range p.Checkpoints {
+		v.Checkpoints = append(v.Checkpoints, hex.EncodeToString(encodeFixed(c, p.size)))
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	return proofEncodingError("json", p.unmarshalJSON(data))
+}
+
+func (p *Proof) unmarshalJSON(data []byte) error {
+	var v proofJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var proof Proof
+	var err error
+	if proof.Hash, err = decodeHexField("hash", v.Hash); err != nil {
+		return err
+	}
+	if proof.Fingerprint, err = decodeHexField("fingerprint", v.Fingerprint); err != nil {
+		return err
+	}
+	witness, err := decodeHexField("witness", v.Witness)
+	if err != nil {
+		return err
+	}
+	if len(witness) == 0 {
+		return errors.New("proof witness cannot be empty")
+	}
+	proof.Witness = new(big.Int).SetBytes(witness)
+	proof.size = len(witness)
+	proof.Iterations = v.Iterations
+	proof.CheckpointInterval = v.CheckpointInterval
+	for i, c := range v.Checkpoints {
+		b, err := decodeHexField(fmt.Sprintf("checkpoints[%d]", i), c)
+		if err != nil {
+			return err
+		}
+		proof.Checkpoints = append(proof.Checkpoints, new(big.Int).SetBytes(b))
+	}
+	proof.StateStride = v.StateStride
+	if v.StateRoot != "" {
+		if proof.StateRoot, err = decodeHexField("state_root", v.StateRoot); err != nil {
+			return err
+		}
+	}
+	*p = proof
+	return nil
+}
+
+// paramsJSON 是 Params 的 JSON 表示
+type paramsJSON struct {
+	Version     int    `json:"version"`
+	P           string `json:"p"`
+	Iterations  uint64 `json:"iterations"`
+	Hash        string `json:"hash"`
+	Permutation string `json:"permutation"`
+	DomainTag   string `json:"domain_tag,omitempty"`
+	BindParams  bool   `json:"bind_params,omitempty"`
+	HashToField bool   `json:"hash_to_field,omitempty"`
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (params *Params) MarshalJSON() ([]byte, error) {
+	if params.P == nil {
+		return nil, errors.New("params prime cannot be nil")
+	}
+	return json.Marshal(paramsJSON{
+		Version:     paramsVersion,
+		P:           hex.EncodeToString(params.P.Bytes()),
+		Iterations:  params.Iterations,
+		Hash:        params.Hash.String(),
+		Permutation: params.Permutation,
+		DomainTag:   hex.EncodeToString(params.DomainTag),
+		BindParams:  params.BindParams,
+		HashToField: params.HashToField,
+	})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (params *Params) UnmarshalJSON(data []byte) error {
+	var v paramsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Version != paramsVersion {
+		return fmt.Errorf("unsupported params version %d", v.Version)
+	}
+	p, err := decodeHexField("p", v.P)
+	if err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		return errors.New("params prime cannot be empty")
+	}
+	h, err := ParseHashID(v.Hash)
+	if err != nil {
+		return err
+	}
+	tag, err := decodeHexField("domain_tag", v.DomainTag)
+	if err != nil {
+		return err
+	}
+	*params = Params{
+		P:           new(big.Int).SetBytes(p),
+		Iterations:  v.Iterations,
+		Hash:        h,
+		Permutation: v.Permutation,
+		BindParams:  v.BindParams,
+		HashToField: v.HashToField,
+	}
+	if len(tag) > 0 {
+		params.DomainTag = tag
+	}
+	return nil
+}
+
+// certificateStepJSON 是 CertificateStep 的 JSON 表示
+type certificateStepJSON struct {
+	P string `json:"p"`
+	A string `json:"a,omitempty"`
+}
+
+// MarshalJSON 实现 json.Marshaler，每一步的 P 与 A 为十六进制字符串，由试除验证的第一步没有 A
+func (c *PrimeCertificate) MarshalJSON() ([]byte, error) {
+	steps := make([]certificateStepJSON, len(c.Steps))
+	for i, st := range c.Steps {
+		if st.P == nil {
+			return nil, fmt.Errorf("certificate step %d has no prime", i)
+		}
+		steps[i].P = hex.EncodeToString(st.P.Bytes())
+		if st.A != nil {
+			steps[i].A = hex.EncodeToString(st.A.Bytes())
+		}
+	}
+	return json.Marshal(steps)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler，只检查格式，证书是否有效由 VerifyPrimeCertificate 判断
+func (c *PrimeCertificate) UnmarshalJSON(data []byte) error {
+	var v []certificateStepJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	steps := make([]CertificateStep, len(v))
+	for i, st := range v {
+		p, err := decodeHexField(fmt.Sprintf("steps[%d].p", i), st.P)
+		if err != nil {
+			return err
+		}
+		steps[i].P = new(big.Int).SetBytes(p)
+		if st.A != "" {
+			a, err := decodeHexField(fmt.Sprintf("steps[%d].a", i), st.A)
+			if err != nil {
+				return err
+			}
+			steps[i].A = new(big.Int).SetBytes(a)
+		}
+	}
+	c.Steps = steps
+	return nil
+}
+
+// decodeHexField 解码一个十六进制字段，出错时在错误信息中指出字段名
+func decodeHexField(name, s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex in %s: %w", name, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,131 @@
+package slothgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestProof_JSON(t *testing.T) {
+	s, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(300))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := s.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, field := range []string{`"hash"`, `"witness"`, `"iterations"`, `"fingerprint"`, `"checkpoints"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("JSON %s is missing %s", data, field)
+		}
+	}
+
+	var decoded Proof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if ok, err := s.VerifyProof(testInput, &decoded); !ok {
+		t.Fatalf("VerifyProof of decoded proof failed: %v", err)
+	}
+	again, _ := json.Marshal(&decoded)
+	if !bytes.Equal(again, data) {
+		t.Errorf("JSON did not round-trip:\n%s\n%s", data, again)
+	}
+
+	if err := json.Unmarshal([]byte(`{"hash":"zz","witness":"01"}`), &decoded); err == nil {
+		t.Error("expected invalid hex to be rejected")
+	}
+}
+
+func TestParams_JSON(t *testing.T) {
+	s, err := New(testVDF.P, 300, WithHash(HashSHA3_256), WithDomainTag([]byte("app")))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	data, err := json.Marshal(s.Params())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"hash":"sha3-256"`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	var params Params
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	restored, err := NewFromParams(&params)
+	if err != nil {
+		t.Fatalf("NewFromParams failed: %v", err)
+	}
+	if !bytes.Equal(restored.Fingerprint(), s.Fingerprint()) {
+		t.Error("params did not round-trip through JSON")
+	}
+	if err := json.Unmarshal([]byte(`{"version":1,"p":"0b","hash":"md5"}`), &params); err == nil {
+		t.Error("expected unknown hash to be rejected")
+	}
+}
+
+func TestPrimeCertificate_JSON(t *testing.T) {
+	p, cert, err := GenerateCertifiedPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateCertifiedPrime failed: %v", err)
+	}
+	data, err := json.Marshal(cert)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var restored PrimeCertificate
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := VerifyPrimeCertificate(p, &restored); err != nil {
+		t.Errorf("certificate did not survive JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`[{"p":"zz","a":"02"}]`), &restored); err == nil {
+		t.Error("expected invalid hex to be rejected")
+	}
+}
+
+// TestProof_Literal 检查 NewProof 组装的证明使用定长编码，零 witness 的字面量证明也能往返
+func TestProof_Literal(t *testing.T) {
+	small := big.NewInt(1)
+	proof := testVDF.NewProof(testVDF.outputHash(small), small)
+	for name, encode := range map[string]func(*Proof) ([]byte, error){
+		"json":   func(p *Proof) ([]byte, error) { return json.Marshal(p) },
+		"binary": func(p *Proof) ([]byte, error) { return p.MarshalBinary() },
+	} {
+		data, err := encode(proof)
+		if err != nil {
+			t.Fatalf("%s: encoding failed: %v", name, err)
+		}
+		var decoded Proof
+		if name == "json" {
+			err = json.Unmarshal(data, &decoded)
+		} else {
+			err = decoded.UnmarshalBinary(data)
+		}
+		if err != nil {
+			t.Fatalf("%s: decoding failed: %v", name, err)
+		}
+		if got := testVDF.EncodeWitness(decoded.Witness); decoded.Witness.Cmp(small) != 0 || !bytes.Equal(encodeFixed(decoded.Witness, decoded.size), got) {
+			t.Errorf("%s: witness was not encoded with %d bytes", name, testVDF.WitnessSize())
+		}
+	}
+
+	zero := Proof{Hash: []byte{1}, Witness: new(big.Int), Iterations: 1, Fingerprint: []byte{2}}
+	data, err := json.Marshal(zero)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Proof
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Witness.Sign() != 0 {
+		t.Errorf("zero witness did not round-trip: %s, %v", data, err)
+	}
+}
@@ -0,0 +1,42 @@
+package slothgo
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// kdfInfo 是 DeriveKey 中 HKDF 使用的 info，同时作为输入的域分隔前缀
+const kdfInfo = "slothgo/kdf/v1"
+
+// kdfPrime 是 DeriveKey 使用的固定素数: secp256k1 的基域模数 2^256 - 2^32 - 977,
+// 满足 p ≡ 3 (mod 4)，选用公开的常数以避免对参数来源的怀疑
+var kdfPrime, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+// DeriveKey 使用 Sloth 的 τ 链作为密钥拉伸函数，从口令派生 keyLen 字节的密钥
+// 与内存困难的 KDF 不同，τ 链本身无法并行，因此每次猜测口令都至少需要 iterations 次顺序的开方;
+// 最终的 witness 经 HKDF-SHA256 扩展到所需的长度
+func DeriveKey(password, salt []byte, iterations uint64, keyLen int) ([]byte, error) {
+	if keyLen <= 0 {
+		return nil, errors.New("key length must be positive")
+	}
+	s, err := New(kdfPrime, iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	// 输入为 域分隔前缀 || len(salt)(4) || salt || password，避免 salt 与 password 的边界混淆
+	input := append([]byte(kdfInfo), binary.BigEndian.AppendUint32(nil, uint32(len(salt)))...)
+	input = append(input, salt...)
+	input = append(input, password...)
+
+	_, witness, err := s.ComputeCtx(context.Background(), input)
+	if err != nil {
+		return nil, err
+	}
+	secret := witness.FillBytes(make([]byte, (kdfPrime.BitLen()+7)/8))
+	return hkdf.Key(sha256.New, secret, salt, kdfInfo, keyLen)
+}
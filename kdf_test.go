@@ -0,0 +1,43 @@
+package slothgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	password, salt := []byte("correct horse battery staple"), []byte("salt")
+
+	k1, err := DeriveKey(password, salt, 100, 64)
+	if err != nil {
+		t.Fatalf("DeriveKey failed: %v", err)
+	}
+	if len(k1) != 64 {
+		t.Fatalf("expected 64-byte key, got %d", len(k1))
+	}
+	k2, _ := DeriveKey(password, salt, 100, 64)
+	if !bytes.Equal(k1, k2) {
+		t.Error("DeriveKey is not deterministic")
+	}
+
+	for name, args := range map[string]struct {
+		password, salt []byte
+		iterations     uint64
+	}{
+		"password":   {[]byte("wrong"), salt, 100},
+		"salt":       {password, []byte("pepper"), 100},
+		"iterations": {password, salt, 101},
+	} {
+		k, err := DeriveKey(args.password, args.salt, args.iterations, 64)
+		if err != nil {
+			t.Fatalf("DeriveKey failed: %v", err)
+		}
+		if bytes.Equal(k, k1) {
+			t.Errorf("changing the %s did not change the key", name)
+		}
+	}
+
+	if _, err := DeriveKey(password, salt, 0, 32); err == nil {
+		t.Error("expected error for zero iterations")
+	}
+}
@@ -0,0 +1,57 @@
+// Package lottery 把 VDF (例如 beacon 的轮次) 的输出映射为对已登记彩票的均匀抽取
+// 抽取结果只依赖于 VDF 输出和彩票列表的 Merkle 根，任何人都可以复现并验证
+package lottery
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/hashutil"
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// drawDomain 是抽取时使用的域分隔前缀
+const drawDomain = "slothgo/lottery/v1"
+
+// Draw 由 randomness 在 [0, n) 中均匀地选出一个序号
+// 每次从哈希中取出 ⌈log2 n⌉ 位，大于等于 n 时换一个计数器重新取 (拒绝采样)，因此没有取模偏差
+func Draw(randomness []byte, n int) (int, error) {
+	if n <= 0 {
+		return 0, errors.New("number of tickets must be positive")
+	}
+	k := bits.Len64(uint64(n - 1))
+	for counter := uint64(0); ; counter++ {
+		data := binary.BigEndian.AppendUint64(append([]byte(nil), randomness...), counter)
+		x := binary.BigEndian.Uint64(hashutil.Expand(drawDomain, data, 8)) & (1<<k - 1)
+		if x < uint64(n) {
+			return int(x), nil
+		}
+	}
+}
+
+// Select 从 tickets 中选出获胜者，proof 是彩票登记截止后计算的 VDF 证明
+// 抽取所用的随机数同时绑定了彩票列表的 Merkle 根，改变列表会得到不同的结果
+func Select(proof *slothgo.Proof, tickets [][]byte) (int, error) {
+	if proof == nil || proof.Hash == nil {
+		return 0, errors.New("proof cannot be nil")
+	}
+	return Draw(append(append([]byte(nil), proof.Hash...), merkle.Root(tickets)...), len(tickets))
+}
+
+// Verify 检查 proof 是 input 的正确 VDF 输出，并且由它选出的获胜者就是 winner
+func Verify(vdf *slothgo.Sloth, input []byte, proof *slothgo.Proof, tickets [][]byte, winner int) (bool, error) {
+	if ok, err := vdf.VerifyProof(input, proof); !ok {
+		return false, err
+	}
+	got, err := Select(proof, tickets)
+	if err != nil {
+		return false, err
+	}
+	if got != winner {
+		return false, fmt.Errorf("winner is ticket %d, not %d", got, winner)
+	}
+	return true, nil
+}
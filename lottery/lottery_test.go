@@ -0,0 +1,60 @@
+package lottery
+
+import (
+	"fmt"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func TestSelectAndVerify(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tickets := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+	input := []byte("draw #1")
+
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	winner, err := Select(proof, tickets)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if ok, err := Verify(vdf, input, proof, tickets, winner); !ok {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if ok, _ := Verify(vdf, input, proof, tickets, (winner+1)%len(tickets)); ok {
+		t.Error("expected wrong winner to be rejected")
+	}
+	if ok, _ := Verify(vdf, []byte("draw #2"), proof, tickets, winner); ok {
+		t.Error("expected proof for a different input to be rejected")
+	}
+}
+
+// TestDraw_Uniform 粗略检查抽取结果在非 2 的幂的 n 下是均匀的
+func TestDraw_Uniform(t *testing.T) {
+	const n, trials = 3, 30000
+	var counts [n]int
+	for i := range trials {
+		winner, err := Draw([]byte(fmt.Sprint(i)), n)
+		if err != nil {
+			t.Fatalf("Draw failed: %v", err)
+		}
+		counts[winner]++
+	}
+	for i, c := range counts {
+		if c < trials/n*9/10 || c > trials/n*11/10 {
+			t.Errorf("ticket %d drawn %d times out of %d", i, c, trials)
+		}
+	}
+	if _, err := Draw(nil, 0); err == nil {
+		t.Error("expected error for zero tickets")
+	}
+}
@@ -0,0 +1,167 @@
+// Package merkle 实现 RFC 6962 风格的 Merkle 树
+// 叶子哈希为 SHA-256(0x00 || data)，内部节点哈希为 SHA-256(0x01 || left || right)，
+// 不平衡的树按不超过 n 的最大 2 的幂次划分左右子树，因此无需复制叶子即可处理任意数量的叶子
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// 叶子与内部节点的哈希前缀，用于防止第二原像攻击
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash 计算叶子数据的哈希
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// nodeHash 计算内部节点的哈希
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Tree 是由一组叶子构成的 Merkle 树
+type Tree struct {
+	leaves [][]byte // 叶子哈希
+}
+
+// NewTree 使用叶子数据构建 Merkle 树
+func NewTree(leaves [][]byte) *Tree {
+	hashes := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		hashes[i] = LeafHash(leaf)
+	}
+	return &Tree{leaves: hashes}
+}
+
+// Len 返回叶子数量
+func (t *Tree) Len() int {
+	return len(t.leaves)
+}
+
+// Root 返回 Merkle 根，空树的根为 SHA-256("")
+func (t *Tree) Root() []byte {
+	if len(t.leaves) == 0 {
+		h := sha256.Sum256(nil)
+		return h[:]
+	}
+	return subtreeRoot(t.leaves)
+}
+
+// Proof 返回第 index 个叶子的包含证明 (从叶子到根方向的兄弟节点哈希)
+func (t *Tree) Proof(index int) ([][]byte, error) {
+	if index < 0 || index >= len(t.leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, len(t.leaves))
+	}
+	return auditPath(index, t.leaves), nil
+}
+
+// Proofs 返回全部叶子的包含证明，Proofs()[i] 与 Proof(i) 相同
+// 每个子树的根只计算一次，总代价为 O(n log n)；逐个调用 Proof 则是 O(n²)，叶子上千时差别明显
+func (t *Tree) Proofs() [][][]byte {
+	paths := make([][][]byte, len(t.leaves))
+	if len(t.leaves) > 0 {
+		allPaths(t.leaves, paths)
+	}
+	return paths
+}
+
+// Root 是 NewTree(leaves).Root() 的简写
+func Root(leaves [][]byte) []byte {
+	return NewTree(leaves).Root()
+}
+
+// Verify 检查 leaf 是否是大小为 size 的树中第 index 个叶子，其 Merkle 根为 root
+func Verify(root, leaf []byte, index, size int, path [][]byte) bool {
+	hash, err := RootFromPath(LeafHash(leaf), index, size, path)
+	return err == nil && bytes.Equal(hash, root)
+}
+
+// RootFromPath 根据叶子哈希和包含证明计算 Merkle 根 (RFC 9162 第 2.1.3.2 节)
+func RootFromPath(leafHash []byte, index, size int, path [][]byte) ([]byte, error) {
+	if index < 0 || index >= size {
+		return nil, errors.New("leaf index out of range")
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range path {
+		if sn == 0 {
+			return nil, errors.New("audit path too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, errors.New("audit path too short")
+	}
+	return r, nil
+}
+
+// subtreeRoot 计算一组叶子哈希构成的子树的根
+func subtreeRoot(hashes [][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	k := splitPoint(len(hashes))
+	return nodeHash(subtreeRoot(hashes[:k]), subtreeRoot(hashes[k:]))
+}
+
+// auditPath 计算第 index 个叶子在 hashes 构成的子树中的包含证明
+func auditPath(index int, hashes [][]byte) [][]byte {
+	if len(hashes) == 1 {
+		return nil
+	}
+	k := splitPoint(len(hashes))
+	if index < k {
+		return append(auditPath(index, hashes[:k]), subtreeRoot(hashes[k:]))
+	}
+	return append(auditPath(index-k, hashes[k:]), subtreeRoot(hashes[:k]))
+}
+
+// allPaths 把 hashes 构成的子树中各个叶子的兄弟节点依次追加到 paths 的对应位置，返回子树的根
+func allPaths(hashes [][]byte, paths [][][]byte) []byte {
+	if len(hashes) == 1 {
+		return hashes[0]
+	}
+	k := splitPoint(len(hashes))
+	left := allPaths(hashes[:k], paths[:k])
+	right := allPaths(hashes[k:], paths[k:])
+	for i := range paths[:k] {
+		paths[i] = append(paths[i], right)
+	}
+	for i := k; i < len(paths); i++ {
+		paths[i] = append(paths[i], left)
+	}
+	return nodeHash(left, right)
+}
+
+// splitPoint 返回小于 n 的最大 2 的幂次 (n > 1)
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// TestProofRoundTrip 检查各种大小的树中每个叶子的包含证明都能验证通过
+func TestProofRoundTrip(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		var leaves [][]byte
+		for i := range size {
+			leaves = append(leaves, []byte(fmt.Sprintf("leaf %d", i)))
+		}
+		tree := NewTree(leaves)
+		root := tree.Root()
+		for i := range size {
+			path, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("size %d: Proof(%d) failed: %v", size, i, err)
+			}
+			if !Verify(root, leaves[i], i, size, path) {
+				t.Fatalf("size %d: proof for leaf %d did not verify", size, i)
+			}
+			if Verify(root, []byte("forged"), i, size, path) {
+				t.Fatalf("size %d: forged leaf %d verified", size, i)
+			}
+			if size > 1 && Verify(root, leaves[i], (i+1)%size, size, path) {
+				t.Fatalf("size %d: proof for leaf %d verified at wrong index", size, i)
+			}
+		}
+	}
+}
+
+// TestProofs 检查一次生成的全部证明与逐个生成的相同
+func TestProofs(t *testing.T) {
+	for size := range 40 {
+		var leaves [][]byte
+		for i := range size {
+			leaves = append(leaves, []byte(fmt.Sprintf("leaf %d", i)))
+		}
+		tree := NewTree(leaves)
+		paths := tree.Proofs()
+		if len(paths) != size {
+			t.Fatalf("size %d: got %d proofs", size, len(paths))
+		}
+		for i, path := range paths {
+			want, _ := tree.Proof(i)
+			if !slices.EqualFunc(path, want, bytes.Equal) {
+				t.Fatalf("size %d: proof %d differs from Proof(%d)", size, i, i)
+			}
+		}
+	}
+}
+
+func BenchmarkProofs(b *testing.B) {
+	leaves := make([][]byte, 10_000)
+	for i := range leaves {
+		leaves[i] = []byte(fmt.Sprintf("contribution %d", i))
+	}
+	tree := NewTree(leaves)
+	for b.Loop() {
+		tree.Proofs()
+	}
+}
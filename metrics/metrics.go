@@ -0,0 +1,42 @@
+// Package metrics 定义 slothgo 计算与验证的监控接口，并提供 Prometheus 文本格式的实现
+// 通过 slothgo.WithMetrics 接入后，信标等服务可以对计算变慢、验证失败等情况设置告警
+package metrics
+
+import "time"
+
+// Op 是被监控的操作类型
+type Op string
+
+const (
+	Compute Op = "compute" // 正向迭代 (Compute、ComputeProof 等)
+	Verify  Op = "verify"  // 逆向迭代 (Verify、VerifyProof、VerifyBatch 等)
+)
+
+// Outcome 是一次操作的结果
+type Outcome string
+
+const (
+	OK       Outcome = "ok"       // 计算完成或证明有效
+	Rejected Outcome = "rejected" // 证明无效
+	Error    Outcome = "error"    // 计算失败或被取消
+)
+
+// Collector 接收操作的开始与结束事件，实现必须可以被并发调用，并且不应阻塞
+type Collector interface {
+	// Begin 在一次操作开始时调用
+	Begin(op Op)
+	// End 在操作结束时调用，iterations 是该操作的迭代次数，elapsed 是实际耗时
+	End(op Op, iterations uint64, elapsed time.Duration, outcome Outcome)
+}
+
+// CacheCollector 是 Collector 可选实现的接口，用于接收 slothgo.WithVerifyCache 的查询结果
+type CacheCollector interface {
+	// CacheLookup 在每次查询验证缓存时调用，hit 报告是否命中
+	CacheLookup(hit bool)
+}
+
+// QueueCollector 接收任务队列的深度，例如 scheduler.Scheduler 在每次入队、出队与任务结束时调用
+type QueueCollector interface {
+	// QueueDepth 报告等待中与运行中的任务数
+	QueueDepth(pending, running int)
+}
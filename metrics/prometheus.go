@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets 是耗时直方图的默认分桶上界 (秒)，覆盖毫秒级的验证到小时级的计算
+var DefaultBuckets = []float64{0.001, 0.01, 0.1, 1, 10, 30, 60, 300, 600, 1800, 3600}
+
+// ops 与 outcomes 是输出时固定的标签取值，尚未发生的组合也输出 0，便于告警规则计算增量
+var (
+	ops      = []Op{Compute, Verify}
+	outcomes = []Outcome{OK, Rejected, Error}
+)
+
+var metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Prometheus 是以 Prometheus 文本格式 (0.0.4) 导出的 Collector，可以直接作为 /metrics 的 http.Handler
+// 导出的指标 (前缀为命名空间):
+//
+//	<ns>_active_jobs{op}                     正在进行的操作数
+//	<ns>_operations_total{op,outcome}        按结果统计的操作数
+//	<ns>_operation_duration_seconds{op}      操作耗时的直方图
+//	<ns>_iterations_total{op}                成功操作的累计迭代次数
+//	<ns>_iterations_per_second{op}           最近一次成功操作的每秒迭代次数
+//	<ns>_verify_cache_lookups_total{result}  验证缓存的查询次数 (hit 或 miss)
+//	<ns>_queue_pending / <ns>_queue_running   调度队列中等待与运行的任务数
+type Prometheus struct {
+	namespace string
+	buckets   []float64
+
+	mu           sync.Mutex
+	stats        map[Op]*opStats
+	cacheHits    uint64
+	cacheMisses  uint64
+	queuePending int
+	queueRunning int
+}
+
+// opStats 是一种操作的累计统计
+type opStats struct {
+	active     int64
+	outcomes   map[Outcome]uint64
+	iterations uint64
+	rate       float64
+	buckets    []uint64 // 与 Prometheus.buckets 一一对应的累计计数
+	count      uint64
+	sum        float64
+}
+
+var (
+	_ Collector      = (*Prometheus)(nil)
+	_ CacheCollector = (*Prometheus)(nil)
+	_ QueueCollector = (*Prometheus)(nil)
+)
+
+// NewPrometheus 创建以 namespace 为指标前缀的收集器 (为空时使用 "sloth")
+// buckets 是耗时直方图的分桶上界 (秒)，为空时使用 DefaultBuckets
+func NewPrometheus(namespace string, buckets ...float64) (*Prometheus, error) {
+	if namespace == "" {
+		namespace = "sloth"
+	}
+	if !metricName.MatchString(namespace) {
+		return nil, fmt.Errorf("invalid metric namespace %q", namespace)
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	for i, b := range buckets {
+		if math.IsNaN(b) || math.IsInf(b, 0) || (i > 0 && b == buckets[i-1]) {
+			return nil, errors.New("histogram buckets must be finite and distinct")
+		}
+	}
+	p := &Prometheus{namespace: namespace, buckets: buckets, stats: make(map[Op]*opStats)}
+	for _, op := range ops {
+		p.op(op)
+	}
+	return p, nil
+}
+
+// op 返回 op 的统计，调用方必须持有 p.mu (构造时除外)
+func (p *Prometheus) op(op Op) *opStats {
+	st, ok := p.stats[op]
+	if !ok {
+		st = &opStats{outcomes: make(map[Outcome]uint64), buckets: make([]uint64, len(p.buckets))}
+		p.stats[op] = st
+	}
+	return st
+}
+
+func (p *Prometheus) Begin(op Op) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.op(op).active++
+}
+
+func (p *Prometheus) End(op Op, iterations uint64, elapsed time.Duration, outcome Outcome) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.op(op)
+	st.active--
+	st.outcomes[outcome]++
+	secs := elapsed.Seconds()
+	for i, b := range p.buckets {
+		if secs <= b {
+			st.buckets[i]++
+		}
+	}
+	st.count++
+	st.sum += secs
+	if outcome == OK && iterations > 0 {
+		st.iterations += iterations
+		if secs > 0 {
+			st.rate = float64(iterations) / secs
+		}
+	}
+}
+
+func (p *Prometheus) CacheLookup(hit bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if hit {
+		p.cacheHits++
+	} else {
+		p.cacheMisses++
+	}
+}
+
+func (p *Prometheus) QueueDepth(pending, running int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queuePending, p.queueRunning = pending, running
+}
+
+// ServeHTTP 以文本格式输出当前的全部指标
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo 以文本格式把当前的全部指标写入 w
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	names := make([]Op, 0, len(p.stats))
+	for op := range p.stats {
+		names = append(names, op)
+	}
+	slices.Sort(names)
+	snapshot := make([]opStats, len(names))
+	for i, op := range names {
+		st := *p.stats[op]
+		st.outcomes = make(map[Outcome]uint64, len(outcomes))
+		for o, n := range p.stats[op].outcomes {
+			st.outcomes[o] = n
+		}
+		st.buckets = slices.Clone(st.buckets)
+		snapshot[i] = st
+	}
+	cacheHits, cacheMisses := p.cacheHits, p.cacheMisses
+	queuePending, queueRunning := p.queuePending, p.queueRunning
+	p.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	ns := p.namespace
+	header := func(name, typ, help string) {
+		fmt.Fprintf(cw, "# HELP %s_%s %s\n# TYPE %s_%s %s\n", ns, name, help, ns, name, typ)
+	}
+
+	header("active_jobs", "gauge", "Number of operations in progress.")
+	for i, op := range names {
+		fmt.Fprintf(cw, "%s_active_jobs{op=%q} %d\n", ns, op, snapshot[i].active)
+	}
+	header("operations_total", "counter", "Number of finished operations by outcome.")
+	for i, op := range names {
+		seen := make(map[Outcome]bool)
+		for _, o := range outcomes {
+			seen[o] = true
+			fmt.Fprintf(cw, "%s_operations_total{op=%q,outcome=%q} %d\n", ns, op, o, snapshot[i].outcomes[o])
+		}
+		// Collector 的其他调用方可能使用自定义的结果
+		var extra []Outcome
+		for o := range snapshot[i].outcomes {
+			if !seen[o] {
+				extra = append(extra, o)
+			}
+		}
+		slices.Sort(extra)
+		for _, o := range extra {
+			fmt.Fprintf(cw, "%s_operations_total{op=%q,outcome=%q} %d\n", ns, op, o, snapshot[i].outcomes[o])
+		}
+	}
+	header("operation_duration_seconds", "histogram", "Duration of finished operations.")
+	for i, op := range names {
+		st := &snapshot[i]
+		for j, b := range p.buckets {
+			fmt.Fprintf(cw, "%s_operation_duration_seconds_bucket{op=%q,le=%q} %d\n", ns, op, formatFloat(b), st.buckets[j])
+		}
+		fmt.Fprintf(cw, "%s_operation_duration_seconds_bucket{op=%q,le=\"+Inf\"} %d\n", ns, op, st.count)
+		fmt.Fprintf(cw, "%s_operation_duration_seconds_sum{op=%q} %s\n", ns, op, formatFloat(st.sum))
+		fmt.Fprintf(cw, "%s_operation_duration_seconds_count{op=%q} %d\n", ns, op, st.count)
+	}
+	header("iterations_total", "counter", "Iterations performed by successful operations.")
+	for i, op := range names {
+		fmt.Fprintf(cw, "%s_iterations_total{op=%q} %d\n", ns, op, snapshot[i].iterations)
+	}
+	header("iterations_per_second", "gauge", "Iteration rate of the most recent successful operation.")
+	for i, op := range names {
+		fmt.Fprintf(cw, "%s_iterations_per_second{op=%q} %s\n", ns, op, formatFloat(snapshot[i].rate))
+	}
+	header("verify_cache_lookups_total", "counter", "Verification cache lookups by result.")
+	fmt.Fprintf(cw, "%s_verify_cache_lookups_total{result=\"hit\"} %d\n", ns, cacheHits)
+	fmt.Fprintf(cw, "%s_verify_cache_lookups_total{result=\"miss\"} %d\n", ns, cacheMisses)
+	header("queue_pending", "gauge", "Number of queued tasks waiting to run.")
+	fmt.Fprintf(cw, "%s_queue_pending %d\n", ns, queuePending)
+	header("queue_running", "gauge", "Number of queued tasks currently running.")
+	fmt.Fprintf(cw, "%s_queue_running %d\n", ns, queueRunning)
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter 统计写入的字节数并保留第一个错误
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPrometheus 检查文本格式输出中的计数、直方图与速度
+func TestPrometheus(t *testing.T) {
+	p, err := NewPrometheus("vdf", 1, 10)
+	if err != nil {
+		t.Fatalf("NewPrometheus failed: %v", err)
+	}
+	p.Begin(Compute)
+	p.Begin(Compute)
+	p.End(Compute, 1000, 2*time.Second, OK)
+	p.Begin(Verify)
+	p.End(Verify, 1000, 20*time.Millisecond, Rejected)
+	p.CacheLookup(true)
+	p.CacheLookup(false)
+	p.CacheLookup(true)
+	p.QueueDepth(3, 2)
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE vdf_operation_duration_seconds histogram",
+		`vdf_active_jobs{op="compute"} 1`,
+		`vdf_active_jobs{op="verify"} 0`,
+		`vdf_operations_total{op="compute",outcome="ok"} 1`,
+		`vdf_operations_total{op="compute",outcome="error"} 0`,
+		`vdf_operations_total{op="verify",outcome="rejected"} 1`,
+		`vdf_operation_duration_seconds_bucket{op="compute",le="1"} 0`,
+		`vdf_operation_duration_seconds_bucket{op="compute",le="10"} 1`,
+		`vdf_operation_duration_seconds_bucket{op="compute",le="+Inf"} 1`,
+		`vdf_operation_duration_seconds_bucket{op="verify",le="1"} 1`,
+		`vdf_operation_duration_seconds_sum{op="compute"} 2`,
+		`vdf_iterations_total{op="compute"} 1000`,
+		`vdf_iterations_total{op="verify"} 0`,
+		`vdf_iterations_per_second{op="compute"} 500`,
+		`vdf_verify_cache_lookups_total{result="hit"} 2`,
+		`vdf_verify_cache_lookups_total{result="miss"} 1`,
+		`vdf_queue_pending 3`,
+		`vdf_queue_running 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("output is missing %q", line)
+		}
+	}
+	if t.Failed() {
+		t.Log(body)
+	}
+
+	var sb strings.Builder
+	n, err := p.WriteTo(&sb)
+	if err != nil || n != int64(sb.Len()) {
+		t.Errorf("WriteTo = %d, %v; wrote %d bytes", n, err, sb.Len())
+	}
+}
+
+// TestNewPrometheus 检查命名空间与分桶的校验
+func TestNewPrometheus(t *testing.T) {
+	if _, err := NewPrometheus("bad-name"); err == nil {
+		t.Error("invalid namespace was accepted")
+	}
+	if _, err := NewPrometheus("", 1, 1); err == nil {
+		t.Error("duplicate buckets were accepted")
+	}
+	p, err := NewPrometheus("")
+	if err != nil {
+		t.Fatalf("NewPrometheus failed: %v", err)
+	}
+	var sb strings.Builder
+	p.WriteTo(&sb)
+	if !strings.Contains(sb.String(), `sloth_operation_duration_seconds_bucket{op="verify",le="0.001"} 0`) {
+		t.Error("default namespace or buckets were not used")
+	}
+}
@@ -0,0 +1,139 @@
+// Package minroot 实现 MinRoot 顺序函数 (Khovratovich, Maller, Tiwari):
+// 每一轮计算 (x, y) → ((x+y)^(1/5), x + i)，其中 i 是轮次常数，用于打破各轮之间的对称性
+// 正向的五次方根需要一次完整的模幂运算，而逆向只需计算五次方，验证方可以快速逆向检查。
+// 默认使用 BN254 的标量域，使得验证关系可以直接折叠进 zk 电路
+package minroot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+// ctxCheckInterval 迭代循环中每隔多少次检查一次 ctx 是否已取消
+const ctxCheckInterval = 1024
+
+// BN254 是 BN254 (alt_bn128) 曲线的标量域阶，Ethereum 的 SNARK 预编译合约使用该域
+var BN254, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+var bigFive = big.NewInt(5)
+
+// VDF 持有 MinRoot 的参数
+type VDF struct {
+	P          *big.Int // 域的阶, 需满足 gcd(5, p-1) = 1
+	Iterations int64    // 迭代轮数 (延迟参数)
+
+	rootExp *big.Int // 5⁻¹ mod (p-1)，用于计算五次方根
+}
+
+var _ slothgo.VDF = (*VDF)(nil)
+
+// New 在 BN254 标量域上创建 MinRoot 实例
+func New(iterations int64) (*VDF, error) {
+	return NewWithField(BN254, iterations)
+}
+
+// NewWithField 在素数域 F_p 上创建 MinRoot 实例
+func NewWithField(p *big.Int, iterations int64) (*VDF, error) {
+	if iterations <= 0 {
+		return nil, errors.New("iterations must be positive")
+	}
+	if !p.ProbablyPrime(20) {
+		return nil, errors.New("p is not a prime number")
+	}
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	rootExp := new(big.Int).ModInverse(bigFive, pMinusOne)
+	if rootExp == nil {
+		return nil, errors.New("p-1 must not be divisible by 5")
+	}
+	return &VDF{P: p, Iterations: iterations, rootExp: rootExp}, nil
+}
+
+// Compute 从 (x₀, y₀) = H(input) 出发执行 Iterations 轮 MinRoot，返回最终状态
+func (v *VDF) Compute(ctx context.Context, input []byte) (x, y *big.Int, err error) {
+	x, y = v.initialState(input)
+	sum := new(big.Int)
+	for i := int64(0); i < v.Iterations; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		sum.Add(x, y).Mod(sum, v.P)
+		// y' = x + i, x' = (x+y)^(1/5)
+		y.Add(x, big.NewInt(i)).Mod(y, v.P)
+		x.Exp(sum, v.rootExp, v.P)
+	}
+	return x, y, nil
+}
+
+// Verify 从最终状态逆向执行 Iterations 轮，检查是否回到 H(input)
+// 逆向一轮为: x = y' - i, y = x'^5 - x
+func (v *VDF) Verify(input []byte, x, y *big.Int) (bool, error) {
+	if x == nil || y == nil {
+		return false, errors.New("state cannot be nil")
+	}
+	if x.Sign() < 0 || x.Cmp(v.P) >= 0 || y.Sign() < 0 || y.Cmp(v.P) >= 0 {
+		return false, errors.New("state must be in the range [0, p-1]")
+	}
+	xi, yi := new(big.Int).Set(x), new(big.Int).Set(y)
+	prev := new(big.Int)
+	for i := v.Iterations - 1; i >= 0; i-- {
+		prev.Sub(yi, big.NewInt(i)).Mod(prev, v.P)
+		yi.Exp(xi, bigFive, v.P)
+		yi.Sub(yi, prev).Mod(yi, v.P)
+		xi.Set(prev)
+	}
+
+	x0, y0 := v.initialState(input)
+	if xi.Cmp(x0) != 0 || yi.Cmp(y0) != 0 {
+		return false, errors.New("verification failed: reversed state does not match initial state")
+	}
+	return true, nil
+}
+
+// Evaluate 实现 slothgo.VDF 接口: output 为最终状态 x || y (各按域元素长度定长编码)
+// MinRoot 的输出本身就足以验证，因此 proof 为空
+func (v *VDF) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	x, y, err := v.Compute(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	size := v.elementSize()
+	output = make([]byte, 2*size)
+	x.FillBytes(output[:size])
+	y.FillBytes(output[size:])
+	return output, nil, nil
+}
+
+// VerifyEvaluation 实现 slothgo.VDF 接口
+func (v *VDF) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	size := v.elementSize()
+	if len(output) != 2*size {
+		return false, fmt.Errorf("output must be %d bytes", 2*size)
+	}
+	if len(proof) != 0 {
+		return false, errors.New("minroot proofs must be empty")
+	}
+	return v.Verify(input, new(big.Int).SetBytes(output[:size]), new(big.Int).SetBytes(output[size:]))
+}
+
+// initialState 把输入映射为初始状态 (x₀, y₀)
+func (v *VDF) initialState(input []byte) (x, y *big.Int) {
+	size := v.elementSize() + 16
+	buf := hashutil.Expand("minroot/initial-state", input, 2*size)
+	x = new(big.Int).SetBytes(buf[:size])
+	y = new(big.Int).SetBytes(buf[size:])
+	return x.Mod(x, v.P), y.Mod(y, v.P)
+}
+
+func (v *VDF) elementSize() int {
+	return (v.P.BitLen() + 7) / 8
+}
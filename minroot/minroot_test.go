@@ -0,0 +1,48 @@
+package minroot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+// TestComputeAndVerify 检查 MinRoot 的计算结果可以逆向验证，且篡改会被拒绝
+func TestComputeAndVerify(t *testing.T) {
+	v, err := New(500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	input := []byte("A random zoo: sloth, unicorn, and trx")
+
+	x, y, err := v.Compute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if ok, err := v.Verify(input, x, y); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+	if ok, _ := v.Verify(input, new(big.Int).Add(x, big.NewInt(1)), y); ok {
+		t.Error("expected tampered state to be rejected")
+	}
+	if ok, _ := v.Verify([]byte("wrong input"), x, y); ok {
+		t.Error("expected wrong input to be rejected")
+	}
+
+	output, proof, err := v.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), input, output, proof); !ok || err != nil {
+		t.Fatalf("VerifyEvaluation failed: %v, %v", ok, err)
+	}
+}
+
+// TestNewWithField_RejectsBadField 检查 p-1 能被 5 整除的域会被拒绝
+func TestNewWithField_RejectsBadField(t *testing.T) {
+	if _, err := NewWithField(big.NewInt(11), 10); err == nil {
+		t.Error("expected error for p = 11 (5 divides p-1)")
+	}
+	if _, err := NewWithField(big.NewInt(13), 10); err != nil {
+		t.Errorf("unexpected error for p = 13: %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+// Package mobile 是供 gomobile 绑定的精简验证接口，iOS/Android 钱包可以在本地验证 Sloth 证明
+//
+// gomobile 只能导出基本类型、string、[]byte、error 以及本包中结构体的指针，因此这里的签名中没有
+// *big.Int、context 与其他包的类型: 素数用大端字节表示，证明与参数接受本仓库的任一编码
+// (二进制、Proof.String 的文本形式、JSON、CBOR 与 DER)，列表用逗号分隔的字符串返回。
+// gomobile 不支持无符号整数，迭代次数用 int64 表示，负数被拒绝。
+//
+//	gomobile bind -target=android -javapkg=io.github.alan22333.sloth ./mobile
+//	gomobile bind -target=ios -prefix=Sloth ./mobile
+//
+// 验证失败时返回的 error 在 Java 中是异常，在 Swift 中是 throws
+package mobile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// Proof 是解码后的证明
+type Proof struct {
+	p *slothgo.Proof
+}
+
+// DecodeProof 解码任一编码的证明
+func DecodeProof(data []byte) (*Proof, error) {
+	trimmed := bytes.TrimSpace(data)
+	p := new(slothgo.Proof)
+	var err error
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("%w: empty proof", slothgo.ErrInvalidProofEncoding)
+	case bytes.HasPrefix(data, []byte("SLTH")):
+		err = p.UnmarshalBinary(data)
+	case trimmed[0] == '{':
+		if err = json.Unmarshal(trimmed, p); err != nil && !errors.Is(err, slothgo.ErrInvalidProofEncoding) {
+			err = &slothgo.ProofEncodingError{Format: "json", Err: err} // JSON 语法错误
+		}
+	case data[0] == 0x30:
+		err = p.UnmarshalDER(data)
+	case data[0]>>5 == 5: // CBOR 映射
+		err = p.UnmarshalCBOR(data)
+	default:
+		p, err = slothgo.ParseProof(string(trimmed))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{p: p}, nil
+}
+
+// Output 返回证明的输出，即 VDF 的结果哈希
+func (p *Proof) Output() []byte {
+	return bytes.Clone(p.p.Hash)
+}
+
+// Iterations 返回证明声明的迭代次数
+func (p *Proof) Iterations() int64 {
+	return int64(p.p.Iterations)
+}
+
+// Fingerprint 返回证明声明的参数指纹
+func (p *Proof) Fingerprint() []byte {
+	return bytes.Clone(p.p.Fingerprint)
+}
+
+// Witness 返回 witness 的大端字节，不含前导零
+func (p *Proof) Witness() []byte {
+	return p.p.Witness.Bytes()
+}
+
+// String 返回证明的文本形式，便于复制粘贴
+func (p *Proof) String() string {
+	return p.p.String()
+}
+
+// StandardParams 返回指纹与证明一致的标准参数集名称，没有时返回空字符串
+func (p *Proof) StandardParams() string {
+	_, name := matchParamSet(p.p)
+	return name
+}
+
+// Verifier 验证同一组参数下的证明，可以在多个线程中共用
+type Verifier struct {
+	vdf *slothgo.Sloth
+}
+
+// NewStandardVerifier 使用名为 name 的标准参数集，iterations 为 0 时使用参数集的推荐值
+func NewStandardVerifier(name string, iterations int64) (*Verifier, error) {
+	ps, err := slothgo.LookupParamSet(name)
+	if err != nil {
+		return nil, err
+	}
+	n, err := iterationCount(iterations)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		n = ps.Iterations
+	}
+	vdf, err := slothgo.New(ps.Prime(), n, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{vdf: vdf}, nil
+}
+
+// NewVerifier 使用大端字节表示的素数 prime 与迭代次数 iterations，会检查 prime 的素性
+func NewVerifier(prime []byte, iterations int64) (*Verifier, error) {
+	n, err := iterationCount(iterations)
+	if err != nil {
+		return nil, err
+	}
+	vdf, err := slothgo.New(new(big.Int).SetBytes(prime), n)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{vdf: vdf}, nil
+}
+
+// NewVerifierFromParams 使用序列化的参数 (JSON、DER 或二进制格式)，哈希函数、置换与域分隔标签等选项
+// 都取自参数本身
+func NewVerifierFromParams(data []byte) (*Verifier, error) {
+	params := new(slothgo.Params)
+	var err error
+	switch trimmed := bytes.TrimSpace(data); {
+	case len(trimmed) == 0:
+		return nil, errors.New("empty params")
+	case trimmed[0] == '{':
+		err = json.Unmarshal(trimmed, params)
+	case data[0] == 0x30:
+		err = params.UnmarshalDER(data)
+	default:
+		err = params.UnmarshalBinary(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	vdf, err := slothgo.NewFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{vdf: vdf}, nil
+}
+
+// Iterations 返回验证方要求的迭代次数
+func (v *Verifier) Iterations() int64 {
+	return int64(v.vdf.Iterations)
+}
+
+// Prime 返回素数 p 的大端字节
+func (v *Verifier) Prime() []byte {
+	return v.vdf.P.Bytes()
+}
+
+// Fingerprint 返回参数指纹，与证明中的指纹比较即可知道证明是否使用了相同的参数
+func (v *Verifier) Fingerprint() []byte {
+	return v.vdf.Fingerprint()
+}
+
+// Verify 检查 proof 是 input 在这组参数下的正确计算结果，通过时返回 nil
+func (v *Verifier) Verify(input []byte, proof *Proof) error {
+	if proof == nil {
+		return errors.New("proof cannot be nil")
+	}
+	return verify(v.vdf, input, proof.p)
+}
+
+// VerifyProof 解码并验证 proof，通过时返回证明的输出
+func (v *Verifier) VerifyProof(input, proof []byte) ([]byte, error) {
+	p, err := DecodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.Verify(input, p); err != nil {
+		return nil, err
+	}
+	return p.Output(), nil
+}
+
+// VerifyStandard 按指纹找到证明使用的标准参数集并验证，迭代次数少于 minIterations 的证明被拒绝
+// 通过时返回证明的输出。钱包通常只接受标准参数集，可以直接使用这个函数
+func VerifyStandard(input, proof []byte, minIterations int64) ([]byte, error) {
+	least, err := iterationCount(minIterations)
+	if err != nil {
+		return nil, err
+	}
+	p, err := DecodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	if p.p.Iterations < least {
+		return nil, fmt.Errorf("proof claims %d iterations, at least %d required", p.p.Iterations, minIterations)
+	}
+	vdf, _ := matchParamSet(p.p)
+	if vdf == nil {
+		return nil, errors.New("proof does not match any standard parameter set")
+	}
+	if err := verify(vdf, input, p.p); err != nil {
+		return nil, err
+	}
+	return p.Output(), nil
+}
+
+// ParamSets 返回逗号分隔的标准参数集名称
+func ParamSets() string {
+	var names []string
+	for _, ps := range slothgo.ParamSets() {
+		names = append(names, ps.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// iterationCount 把绑定接口中的迭代次数转换为 uint64; slothgo.MaxIterations 不超过 int64 的范围，反方向的转换总是安全的
+func iterationCount(n int64) (uint64, error) {
+	if n < 0 {
+		return 0, errors.New("iterations cannot be negative")
+	}
+	return uint64(n), nil
+}
+
+func verify(vdf *slothgo.Sloth, input []byte, proof *slothgo.Proof) error {
+	ok, err := vdf.VerifyProof(input, proof)
+	if ok {
+		return nil
+	}
+	if err == nil {
+		err = errors.New("verification failed")
+	}
+	return err
+}
+
+// matchParamSet 返回指纹与证明一致的标准参数集实例及其名称，没有时返回 nil
+func matchParamSet(proof *slothgo.Proof) (*slothgo.Sloth, string) {
+	for _, ps := range slothgo.ParamSets() {
+		vdf, err := slothgo.New(ps.Prime(), proof.Iterations, slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck))
+		if err == nil && bytes.Equal(vdf.Fingerprint(), proof.Fingerprint) {
+			return vdf, ps.Name
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,173 @@
+package mobile
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func standardProof(t *testing.T, input []byte, iterations uint64) *slothgo.Proof {
+	t.Helper()
+	vdf, err := slothgo.NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vdf, err = vdf.WithIterations(iterations); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := vdf.ComputeProof(input)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	return proof
+}
+
+// TestDecodeProof 检查每一种编码都能解码为相同的证明
+func TestDecodeProof(t *testing.T) {
+	proof := standardProof(t, []byte("wallet"), 300)
+	binary, _ := proof.MarshalBinary()
+	jsonData, _ := json.Marshal(proof)
+	cbor, _ := proof.MarshalCBOR()
+	der, _ := proof.MarshalDER()
+	for name, data := range map[string][]byte{
+		"binary": binary,
+		"text":   []byte(proof.String() + "\n"),
+		"json":   jsonData,
+		"cbor":   cbor,
+		"der":    der,
+	} {
+		p, err := DecodeProof(data)
+		if err != nil {
+			t.Errorf("%s: DecodeProof failed: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(p.Output(), proof.Hash) || p.Iterations() != 300 || p.StandardParams() != "sloth-256-t30s" ||
+			!bytes.Equal(p.Witness(), proof.Witness.Bytes()) || p.String() != proof.String() {
+			t.Errorf("%s: decoded a different proof", name)
+		}
+	}
+	for _, data := range []string{"", "  ", "{", "SLTH"} {
+		if _, err := DecodeProof([]byte(data)); err == nil {
+			t.Errorf("%q was accepted", data)
+		}
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	input := []byte("wallet")
+	proof := standardProof(t, input, 300)
+	data := []byte(proof.String())
+
+	standard, err := NewStandardVerifier("sloth-256-t30s", 300)
+	if err != nil {
+		t.Fatalf("NewStandardVerifier failed: %v", err)
+	}
+	custom, err := NewVerifier(standard.Prime(), 300)
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	params, _ := json.Marshal(standard.vdf.Params())
+	binaryParams, _ := standard.vdf.Params().MarshalBinary()
+	fromJSON, err := NewVerifierFromParams(params)
+	if err != nil {
+		t.Fatalf("NewVerifierFromParams(json) failed: %v", err)
+	}
+	fromBinary, err := NewVerifierFromParams(binaryParams)
+	if err != nil {
+		t.Fatalf("NewVerifierFromParams(binary) failed: %v", err)
+	}
+	for i, v := range []*Verifier{standard, custom, fromJSON, fromBinary} {
+		out, err := v.VerifyProof(input, data)
+		if err != nil || !bytes.Equal(out, proof.Hash) {
+			t.Errorf("verifier %d rejected a valid proof: %v", i, err)
+		}
+		if !bytes.Equal(v.Fingerprint(), proof.Fingerprint) || v.Iterations() != 300 {
+			t.Errorf("verifier %d has different parameters", i)
+		}
+		if _, err := v.VerifyProof([]byte("other"), data); err == nil {
+			t.Errorf("verifier %d accepted a proof for another input", i)
+		}
+	}
+
+	// 默认迭代次数与证明不同，指纹不一致
+	def, err := NewStandardVerifier("sloth-256-t30s", 0)
+	if err != nil || def.Iterations() != 400_000 {
+		t.Fatalf("NewStandardVerifier with default iterations returned %v", err)
+	}
+	if _, err := def.VerifyProof(input, data); err == nil {
+		t.Error("proof with different iterations was accepted")
+	}
+	if err := standard.Verify(input, nil); err == nil {
+		t.Error("nil proof was accepted")
+	}
+	if _, err := NewStandardVerifier("nope", 0); err == nil {
+		t.Error("unknown parameter set was accepted")
+	}
+	if _, err := NewVerifier([]byte{15}, 10); err == nil {
+		t.Error("composite prime was accepted")
+	}
+	if _, err := NewVerifierFromParams([]byte("{}")); err == nil {
+		t.Error("incomplete params were accepted")
+	}
+}
+
+func TestVerifyStandard(t *testing.T) {
+	input := []byte("wallet")
+	proof := standardProof(t, input, 300)
+	data, _ := json.Marshal(proof)
+
+	if out, err := VerifyStandard(input, data, 300); err != nil || !bytes.Equal(out, proof.Hash) {
+		t.Errorf("VerifyStandard returned %x, %v", out, err)
+	}
+	if _, err := VerifyStandard(input, data, 301); err == nil || !strings.Contains(err.Error(), "at least 301") {
+		t.Errorf("short proof returned %v", err)
+	}
+	p, _ := slothgo.GenerateSlothPrime(64)
+	vdf, _ := slothgo.New(p, 100)
+	other, _ := vdf.ComputeProof(input)
+	if _, err := VerifyStandard(input, []byte(other.String()), 0); err == nil {
+		t.Error("proof with a non-standard prime was accepted")
+	}
+	if names := ParamSets(); !strings.HasPrefix(names, "sloth-256-t30s,") {
+		t.Errorf("ParamSets returned %q", names)
+	}
+}
+
+// TestBindingTypes 检查导出的函数与方法只使用 gomobile 支持的类型，避免绑定时被静默跳过
+func TestBindingTypes(t *testing.T) {
+	allowed := map[string]bool{
+		"bool": true, "int": true, "int32": true, "int64": true, "float64": true,
+		"string": true, "[]byte": true, "error": true, "*Proof": true, "*Verifier": true,
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "mobile.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+		if fn.Recv != nil && !ast.IsExported(strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*")) {
+			continue
+		}
+		var fields []*ast.Field
+		fields = append(fields, fn.Type.Params.List...)
+		if fn.Type.Results != nil {
+			fields = append(fields, fn.Type.Results.List...)
+		}
+		for _, field := range fields {
+			if typ := types.ExprString(field.Type); !allowed[typ] {
+				t.Errorf("%s uses %s, which gomobile cannot bind", fn.Name.Name, typ)
+			}
+		}
+	}
+}
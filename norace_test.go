@@ -0,0 +1,5 @@
+//go:build !race
+
+package slothgo
+
+const raceEnabled = false
@@ -0,0 +1,154 @@
+package slothgo
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/alan22333/sloth_go/group"
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// Option 用于在 New 中配置 Sloth 实例的可选行为
+type Option func(*Sloth)
+
+// ProgressFunc 是计算过程中的进度回调
+// done: 已完成的迭代次数; total: 总迭代次数
+type ProgressFunc func(done, total uint64)
+
+// WithProgress 设置进度回调, 每完成 stride 次迭代调用一次, 计算结束时再调用一次
+// stride 为 0 时使用默认步长 (总迭代次数的 1%)
+func WithProgress(fn ProgressFunc, stride uint64) Option {
+	if fn == nil {
+		return WithProgressInfo(nil, stride)
+	}
+	return WithProgressInfo(func(pi ProgressInfo) { fn(pi.Done, pi.Total) }, stride)
+}
+
+// ProgressInfo 是带有速度与剩余时间估计的进度信息
+type ProgressInfo struct {
+	Done  uint64        // 已完成的迭代次数
+	Total uint64        // 总迭代次数
+	Rate  float64       // 本次迭代循环中观测到的每秒迭代次数
+	ETA   time.Duration // 按 Rate 估计的剩余时间，计算结束时为 0
+}
+
+// WithProgressInfo 与 WithProgress 相同，但回调同时收到观测速度与剩余时间估计，
+// 便于界面显示 "大约还需 7 分钟"。计算开始前的估计见 Calibration.EstimateComputeTime
+func WithProgressInfo(fn func(ProgressInfo), stride uint64) Option {
+	return func(s *Sloth) {
+		s.progress = fn
+		s.progressStride = stride
+		s.progressStrideSet = stride != 0
+	}
+}
+
+// WithSegmentCheckpoints 让 ComputeProof 每隔 k 次迭代在证明中记录一个中间值
+// 验证方可以并行地检查相邻检查点之间的各个区段，k 为 0 表示不记录
+func WithSegmentCheckpoints(k uint64) Option {
+	return func(s *Sloth) {
+		s.segmentInterval = k
+	}
+}
+
+// WithField 替换 Sloth 使用的 F_p 算术后端，f 的模数必须与 New 的参数 p 相同
+func WithField(f group.Field) Option {
+	return func(s *Sloth) {
+		s.Field = f
+	}
+}
+
+// WithPermutation 替换每一轮使用的置换 τ，newPerm 会在 New 中以最终的算术后端调用
+func WithPermutation(newPerm PermutationFactory) Option {
+	return func(s *Sloth) {
+		s.newPerm = newPerm
+	}
+}
+
+// WithPrimalityCheck 替换 New 对 p 执行的素性检验，默认为 MillerRabin(20)
+// 对 2048 位的模数，默认检验是构造实例的主要开销; 参数来源可信时可以改用 BPSW、
+// CertificateCheck 或 SkipPrimalityCheck
+func WithPrimalityCheck(check PrimalityCheck) Option {
+	return func(s *Sloth) {
+		s.primality = check
+	}
+}
+
+// WithAccelerator 让 VerifyBatchFast 与 ComputeBatch 把批量迭代交给加速器 a 执行，
+// a 不支持当前的模数或置换时仍使用 CPU 路径。加速器只影响执行方式，不改变参数指纹与输出
+func WithAccelerator(a Accelerator) Option {
+	return func(s *Sloth) {
+		s.accel = a
+	}
+}
+
+// WithMetrics 把计算与验证的开始、结束、耗时与结果报告给收集器 c，例如 metrics.NewPrometheus
+// 覆盖 Compute、ComputeProof、Verify、VerifyProof、VerifyBatch 及其 Ctx 变体;
+// VerifyBatchFast 的批量路径与 ComputeBatch 的加速器路径不逐个报告
+func WithMetrics(c metrics.Collector) Option {
+	return func(s *Sloth) {
+		s.metrics = c
+	}
+}
+
+// WithVerifyCache 让 Verify 与 VerifyProof (及其 Ctx 变体) 先查询缓存 c，已经验证通过的证明直接返回 true，
+// 验证通过的新证明加入缓存。命中时不向 WithMetrics 报告验证操作; 收集器实现了 metrics.CacheCollector 时
+// 另外报告每次查询是否命中
+func WithVerifyCache(c *VerifyCache) Option {
+	return func(s *Sloth) {
+		s.verifyCache = c
+	}
+}
+
+// WithPaperConformance 使用 NewPaperSqrtPermutation，使输出与按论文约定实现的 Sloth 逐位一致
+// 要求 p ≡ 3 (mod 4)。置换名称写入参数指纹，因此该模式下的证明与默认模式互不兼容
+func WithPaperConformance() Option {
+	return WithPermutation(NewPaperSqrtPermutation)
+}
+
+// WithHash 选择用于派生 w₀ 和计算最终输出的哈希函数，默认为 HashSHA256
+// 哈希函数会写入参数指纹，不要在 New 之后直接修改 HashFunc
+func WithHash(h HashID) Option {
+	return func(s *Sloth) {
+		s.hash = h
+		s.HashFunc = h.New
+	}
+}
+
+// maxDomainTagLen 是域标签的最大字节数
+const maxDomainTagLen = 0xffff
+
+// WithDomainTag 设置应用相关的域标签，w₀ 的派生与最终哈希都会以它作为前缀
+// 不同协议使用不同的标签后，即使素数与输入相同，一个协议的证明也无法在另一个协议中重放
+func WithDomainTag(tag []byte) Option {
+	return func(s *Sloth) {
+		s.tag = bytes.Clone(tag)
+	}
+}
+
+// WithParamBinding 让最终哈希同时承诺参数指纹 (素数、迭代次数、置换、哈希函数与域标签)
+// 未启用时 g 只依赖 witness，攻击者可以声称一个 witness 是在任意参数下算出的;
+// 启用后 g 只在真实参数下才能复现。启用后的参数指纹使用 v2 前缀，与未启用的实例互不兼容
+func WithParamBinding() Option {
+	return func(s *Sloth) {
+		s.bind = true
+	}
+}
+
+// WithHashToField 使用 RFC 9380 的 hash_to_field (基于 expand_message_xmd) 派生 w₀，
+// 代替默认的 int(h(input)) mod p。扩展后的位数比 p 多 128 位，取模偏差可以忽略，
+// 并且与其他实现把输入映射到 F_p 的方式一致
+func WithHashToField() Option {
+	return func(s *Sloth) {
+		s.xmd = true
+	}
+}
+
+// WithZeroize 开启保密模式: 计算结束后清零迭代使用的临时缓冲区与 witness 的编码，
+// timelock 在派生密钥后清零密钥材料与封装时选取的终点。适合 VDF 输出用作密钥、
+// 不能从内存转储中恢复的部署。清零是尽力而为的: big.Int 扩容时留下的旧数组与算术后端内部的
+// 临时值不在范围内，返回给调用方的 witness 需要在用完后自行调用 Wipe
+func WithZeroize() Option {
+	return func(s *Sloth) {
+		s.zeroize = true
+	}
+}
@@ -0,0 +1,199 @@
+package ots
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultCalendars 是 ots 命令行工具默认使用的公共日历服务器
+var DefaultCalendars = []string{
+	"https://a.pool.opentimestamps.org",
+	"https://b.pool.opentimestamps.org",
+	"https://a.pool.eternitywall.com",
+	"https://ots.btc.catallaxy.com",
+}
+
+// nonceSize 是提交前追加到摘要后的随机数的字节数，日历服务器因此看不到原始摘要
+const nonceSize = 16
+
+// maxResponseSize 是日历服务器响应的最大字节数
+const maxResponseSize = 1 << 16
+
+// ErrPending 表示证明中还没有比特币证明，日历服务器尚未把摘要写入区块
+var ErrPending = errors.New("ots: timestamp is not yet confirmed in bitcoin")
+
+// Client 是 OpenTimestamps 日历服务器的客户端
+type Client struct {
+	Calendars []string     // 日历服务器，为空时使用 DefaultCalendars
+	Min       int          // Stamp 至少需要成功的日历数，为 0 时为 1
+	HTTP      *http.Client // 为 nil 时使用 http.DefaultClient
+}
+
+func (c *Client) calendars() []string {
+	if len(c.Calendars) == 0 {
+		return DefaultCalendars
+	}
+	return c.Calendars
+}
+
+func (c *Client) do(ctx context.Context, method, url string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/vnd.opentimestamps.v1")
+	req.Header.Set("User-Agent", "sloth_go-ots")
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	return data, resp.StatusCode, err
+}
+
+// Stamp 把 digest 提交给全部日历服务器，返回合并后的证明 (此时只有待定证明)
+// 提交的是 SHA-256(digest || 随机数)，随机数记录在证明中
+func (c *Client) Stamp(ctx context.Context, digest []byte) (*Timestamp, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	root := NewTimestamp(digest)
+	appended, err := root.Add(Op{Tag: OpAppend, Arg: nonce})
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := appended.Add(Op{Tag: OpSHA256})
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := c.calendars()
+	results := make([]*Timestamp, len(calendars))
+	errs := make([]error, len(calendars))
+	var wg sync.WaitGroup
+	for i, url := range calendars {
+		wg.Go(func() {
+			data, status, err := c.do(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/digest", commitment.Msg)
+			switch {
+			case err != nil:
+				errs[i] = err
+			case status != http.StatusOK:
+				errs[i] = fmt.Errorf("%s: unexpected status %d", url, status)
+			default:
+				if results[i], err = ParseTimestamp(commitment.Msg, data); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", url, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	n := 0
+	for _, r := range results {
+		if r != nil {
+			if err := commitment.Merge(r); err != nil {
+				return nil, err
+			}
+			n++
+		}
+	}
+	if n < max(c.Min, 1) {
+		return nil, fmt.Errorf("ots: only %d of %d calendars accepted the digest: %w", n, len(calendars), errors.Join(errs...))
+	}
+	return root, nil
+}
+
+// Upgrade 向待定证明所在的日历服务器查询完整的证明并合并进 t，返回 t 是否有变化
+// 还没有写入区块的日历返回 404，此时证明保持不变; 得到比特币证明的待定证明会被删除
+func (c *Client) Upgrade(ctx context.Context, t *Timestamp) (bool, error) {
+	type pending struct {
+		node *Timestamp
+		uri  string
+	}
+	var todo []pending
+	t.Walk(func(n *Timestamp) bool {
+		for _, a := range n.Attestations {
+			if uri, ok := a.URI(); ok {
+				todo = append(todo, pending{n, uri})
+			}
+		}
+		return true
+	})
+
+	changed := false
+	var errs []error
+	for _, p := range todo {
+		url := strings.TrimSuffix(p.uri, "/") + "/timestamp/" + hex.EncodeToString(p.node.Msg)
+		data, status, err := c.do(ctx, http.MethodGet, url, nil)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+			continue
+		case status == http.StatusNotFound:
+			continue
+		case status != http.StatusOK:
+			errs = append(errs, fmt.Errorf("%s: unexpected status %d", p.uri, status))
+			continue
+		}
+		upgraded, err := ParseTimestamp(p.node.Msg, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.uri, err))
+			continue
+		}
+		if !upgraded.confirmed() {
+			continue
+		}
+		if err := p.node.Merge(upgraded); err != nil {
+			return changed, err
+		}
+		p.node.Attestations = slices.DeleteFunc(p.node.Attestations, func(a Attestation) bool {
+			uri, ok := a.URI()
+			return ok && uri == p.uri
+		})
+		changed = true
+	}
+	return changed, errors.Join(errs...)
+}
+
+// confirmed 报告 t 中是否有比特币证明
+func (t *Timestamp) confirmed() bool {
+	found := false
+	t.Walk(func(n *Timestamp) bool {
+		for _, a := range n.Attestations {
+			if _, ok := a.BitcoinHeight(); ok {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// Pending 报告 t 中是否还有待定证明
+func (t *Timestamp) Pending() bool {
+	found := false
+	t.Walk(func(n *Timestamp) bool {
+		for _, a := range n.Attestations {
+			if _, ok := a.URI(); ok {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
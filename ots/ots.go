@@ -0,0 +1,402 @@
+// Package ots 把信标的轮次锚定到 OpenTimestamps: 每一轮完成后把它的摘要提交给 OpenTimestamps 日历服务器，
+// 日历把摘要汇总进比特币交易，之后的证明说明该轮在对应区块之前就已存在，这是独立于信标运营方的外部证据。
+//
+// 这里用标准库实现了 OpenTimestamps 的二进制格式 (与 ots 命令行工具的 .ots 文件兼容)、日历客户端
+// (Client.Stamp 与 Client.Upgrade) 以及对比特币区块头的验证 (Verify，区块头来自 BlockSource，例如 Esplora)。
+// Store 包装 beacon.Store，在写入时锚定，在读取时检查锚定确实指向读到的这一轮。
+//
+// 只支持日历服务器实际使用的操作 (sha256、sha1、append、prepend、reverse、hexlify)，
+// 含有 ripemd160 或 keccak256 的证明解码时返回 ErrUnsupportedOp
+package ots
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// 操作的标签
+const (
+	OpSHA1      byte = 0x02
+	OpRIPEMD160 byte = 0x03
+	OpSHA256    byte = 0x08
+	OpKeccak256 byte = 0x67
+	OpAppend    byte = 0xf0
+	OpPrepend   byte = 0xf1
+	OpReverse   byte = 0xf2
+	OpHexlify   byte = 0xf3
+)
+
+// 格式的限制，与参考实现相同
+const (
+	maxMsgLength      = 4096
+	maxPayloadLength  = 8192
+	maxRecursionDepth = 256
+)
+
+// headerMagic 是 .ots 文件的魔数
+var headerMagic = []byte("\x00OpenTimestamps\x00\x00Proof\x00\xbf\x89\xe2\xe8\x84\xe8\x92\x94")
+
+// 证明类型的标签
+var (
+	pendingTag = [8]byte{0x83, 0xdf, 0xe3, 0x0d, 0x2e, 0xf9, 0x0c, 0x8e}
+	bitcoinTag = [8]byte{0x05, 0x88, 0x96, 0x0d, 0x73, 0xd7, 0x19, 0x01}
+)
+
+var (
+	// ErrUnsupportedOp 表示证明中含有这里不支持的操作
+	ErrUnsupportedOp = errors.New("ots: unsupported operation")
+	// ErrInvalidTimestamp 表示无法解码的证明
+	ErrInvalidTimestamp = errors.New("ots: invalid timestamp")
+)
+
+// Op 是一个操作，Arg 只用于 append 与 prepend
+type Op struct {
+	Tag byte
+	Arg []byte
+}
+
+// Apply 对 msg 执行操作
+func (op Op) Apply(msg []byte) ([]byte, error) {
+	var out []byte
+	switch op.Tag {
+	case OpSHA256:
+		h := sha256.Sum256(msg)
+		out = h[:]
+	case OpSHA1:
+		h := sha1.Sum(msg)
+		out = h[:]
+	case OpAppend:
+		out = append(bytes.Clone(msg), op.Arg...)
+	case OpPrepend:
+		out = append(bytes.Clone(op.Arg), msg...)
+	case OpReverse:
+		out = bytes.Clone(msg)
+		slices.Reverse(out)
+	case OpHexlify:
+		out = []byte(hex.EncodeToString(msg))
+	default:
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnsupportedOp, op.Tag)
+	}
+	if len(out) > maxMsgLength {
+		return nil, fmt.Errorf("%w: message exceeds %d bytes", ErrInvalidTimestamp, maxMsgLength)
+	}
+	return out, nil
+}
+
+func (op Op) binary() bool {
+	return op.Tag == OpAppend || op.Tag == OpPrepend
+}
+
+func compareOps(a, b Op) int {
+	return cmp.Or(cmp.Compare(a.Tag, b.Tag), bytes.Compare(a.Arg, b.Arg))
+}
+
+// Attestation 是一条证明: 日历服务器的待定证明、比特币区块头证明或者未知类型
+type Attestation struct {
+	Tag     [8]byte
+	Payload []byte
+}
+
+// PendingAttestation 返回日历服务器 uri 的待定证明
+func PendingAttestation(uri string) Attestation {
+	return Attestation{Tag: pendingTag, Payload: appendVarBytes(nil, []byte(uri))}
+}
+
+// BitcoinAttestation 返回比特币第 height 个区块的证明: 消息等于该区块头中的 Merkle 根
+func BitcoinAttestation(height uint64) Attestation {
+	return Attestation{Tag: bitcoinTag, Payload: binary.AppendUvarint(nil, height)}
+}
+
+// URI 返回待定证明的日历地址
+func (a Attestation) URI() (string, bool) {
+	if a.Tag != pendingTag {
+		return "", false
+	}
+	r := bytes.NewReader(a.Payload)
+	uri, err := readVarBytes(r, maxPayloadLength)
+	if err != nil || r.Len() != 0 {
+		return "", false
+	}
+	return string(uri), true
+}
+
+// BitcoinHeight 返回比特币证明的区块高度
+func (a Attestation) BitcoinHeight() (uint64, bool) {
+	if a.Tag != bitcoinTag {
+		return 0, false
+	}
+	r := bytes.NewReader(a.Payload)
+	height, err := binary.ReadUvarint(r)
+	if err != nil || r.Len() != 0 {
+		return 0, false
+	}
+	return height, true
+}
+
+func (a Attestation) String() string {
+	if uri, ok := a.URI(); ok {
+		return "pending " + uri
+	}
+	if height, ok := a.BitcoinHeight(); ok {
+		return fmt.Sprintf("bitcoin block %d", height)
+	}
+	return fmt.Sprintf("unknown %x", a.Tag)
+}
+
+func compareAttestations(a, b Attestation) int {
+	return cmp.Or(bytes.Compare(a.Tag[:], b.Tag[:]), bytes.Compare(a.Payload, b.Payload))
+}
+
+// Branch 是 Timestamp 的一个分支: 对消息执行 Op 后得到 Next 的消息
+type Branch struct {
+	Op   Op
+	Next *Timestamp
+}
+
+// Timestamp 是证明树的一个节点，Msg 是到达该节点时的消息
+type Timestamp struct {
+	Msg          []byte
+	Attestations []Attestation
+	Branches     []Branch
+}
+
+// NewTimestamp 返回消息为 msg 的空节点
+func NewTimestamp(msg []byte) *Timestamp {
+	return &Timestamp{Msg: bytes.Clone(msg)}
+}
+
+// Add 对节点的消息执行 op，返回对应分支的节点，已有相同的分支时返回它
+func (t *Timestamp) Add(op Op) (*Timestamp, error) {
+	for _, b := range t.Branches {
+		if compareOps(b.Op, op) == 0 {
+			return b.Next, nil
+		}
+	}
+	msg, err := op.Apply(t.Msg)
+	if err != nil {
+		return nil, err
+	}
+	next := &Timestamp{Msg: msg}
+	t.Branches = append(t.Branches, Branch{Op: op, Next: next})
+	return next, nil
+}
+
+// Attest 给节点加上一条证明，已有相同的证明时不变
+func (t *Timestamp) Attest(a Attestation) {
+	if !slices.ContainsFunc(t.Attestations, func(b Attestation) bool { return compareAttestations(a, b) == 0 }) {
+		t.Attestations = append(t.Attestations, a)
+	}
+}
+
+// Merge 把消息相同的 other 合并进 t
+func (t *Timestamp) Merge(other *Timestamp) error {
+	if !bytes.Equal(t.Msg, other.Msg) {
+		return errors.New("ots: cannot merge timestamps of different messages")
+	}
+	for _, a := range other.Attestations {
+		t.Attest(a)
+	}
+	for _, b := range other.Branches {
+		next, err := t.Add(b.Op)
+		if err != nil {
+			return err
+		}
+		if err := next.Merge(b.Next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk 按深度优先的顺序对每个节点调用 fn，fn 返回 false 时不再进入该节点的分支
+func (t *Timestamp) Walk(fn func(*Timestamp) bool) {
+	if fn(t) {
+		for _, b := range t.Branches {
+			b.Next.Walk(fn)
+		}
+	}
+}
+
+// MarshalBinary 按 OpenTimestamps 的格式编码证明树 (不含文件头与根消息)
+func (t *Timestamp) MarshalBinary() ([]byte, error) {
+	return t.appendBinary(nil)
+}
+
+func (t *Timestamp) appendBinary(buf []byte) ([]byte, error) {
+	if len(t.Attestations) == 0 && len(t.Branches) == 0 {
+		return nil, fmt.Errorf("%w: empty node", ErrInvalidTimestamp)
+	}
+	atts := slices.SortedFunc(slices.Values(t.Attestations), compareAttestations)
+	branches := slices.SortedFunc(slices.Values(t.Branches), func(a, b Branch) int { return compareOps(a.Op, b.Op) })
+	for i, a := range atts {
+		// 除了最后一项，每一项之前都有 0xff
+		if i < len(atts)-1 || len(branches) > 0 {
+			buf = append(buf, 0xff)
+		}
+		buf = append(buf, 0x00)
+		buf = append(buf, a.Tag[:]...)
+		buf = appendVarBytes(buf, a.Payload)
+	}
+	for i, b := range branches {
+		if i < len(branches)-1 {
+			buf = append(buf, 0xff)
+		}
+		buf = append(buf, b.Op.Tag)
+		if b.Op.binary() {
+			buf = appendVarBytes(buf, b.Op.Arg)
+		}
+		var err error
+		if buf, err = b.Next.appendBinary(buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// ParseTimestamp 解码消息为 msg 的证明树，data 必须恰好是一棵树
+func ParseTimestamp(msg, data []byte) (*Timestamp, error) {
+	r := bytes.NewReader(data)
+	t, err := readTimestamp(r, msg, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidTimestamp)
+	}
+	return t, nil
+}
+
+func readTimestamp(r *bytes.Reader, msg []byte, depth int) (*Timestamp, error) {
+	if depth > maxRecursionDepth {
+		return nil, fmt.Errorf("%w: recursion limit exceeded", ErrInvalidTimestamp)
+	}
+	t := &Timestamp{Msg: msg}
+	item := func(tag byte) error {
+		if tag == 0x00 {
+			var a Attestation
+			if _, err := io.ReadFull(r, a.Tag[:]); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+			}
+			payload, err := readVarBytes(r, maxPayloadLength)
+			if err != nil {
+				return err
+			}
+			a.Payload = payload
+			t.Attestations = append(t.Attestations, a)
+			return nil
+		}
+		op := Op{Tag: tag}
+		switch tag {
+		case OpSHA1, OpSHA256, OpRIPEMD160, OpKeccak256, OpReverse, OpHexlify:
+		case OpAppend, OpPrepend:
+			arg, err := readVarBytes(r, maxMsgLength)
+			if err != nil {
+				return err
+			}
+			op.Arg = arg
+		default:
+			return fmt.Errorf("%w: unknown tag 0x%02x", ErrInvalidTimestamp, tag)
+		}
+		next, err := op.Apply(msg)
+		if err != nil {
+			return err
+		}
+		child, err := readTimestamp(r, next, depth+1)
+		if err != nil {
+			return err
+		}
+		t.Branches = append(t.Branches, Branch{Op: op, Next: child})
+		return nil
+	}
+	for {
+		tag, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+		}
+		if tag != 0xff {
+			return t, item(tag)
+		}
+		if tag, err = r.ReadByte(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+		}
+		if err := item(tag); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DetachedTimestamp 是 .ots 文件的内容: 文件摘要与以它为根消息的证明树
+// 摘要固定为 SHA-256
+type DetachedTimestamp struct {
+	Timestamp *Timestamp
+}
+
+// Digest 返回被证明的摘要
+func (d *DetachedTimestamp) Digest() []byte {
+	return d.Timestamp.Msg
+}
+
+// MarshalBinary 编码为 .ots 文件
+func (d *DetachedTimestamp) MarshalBinary() ([]byte, error) {
+	if len(d.Timestamp.Msg) != sha256.Size {
+		return nil, fmt.Errorf("%w: digest must be %d bytes", ErrInvalidTimestamp, sha256.Size)
+	}
+	buf := append(bytes.Clone(headerMagic), 0x01, OpSHA256)
+	buf = append(buf, d.Timestamp.Msg...)
+	return d.Timestamp.appendBinary(buf)
+}
+
+// UnmarshalBinary 解码 .ots 文件
+func (d *DetachedTimestamp) UnmarshalBinary(data []byte) error {
+	rest, ok := bytes.CutPrefix(data, headerMagic)
+	if !ok {
+		return fmt.Errorf("%w: not an OpenTimestamps file", ErrInvalidTimestamp)
+	}
+	r := bytes.NewReader(rest)
+	if version, err := binary.ReadUvarint(r); err != nil || version != 1 {
+		return fmt.Errorf("%w: unsupported version", ErrInvalidTimestamp)
+	}
+	if tag, err := r.ReadByte(); err != nil || tag != OpSHA256 {
+		return fmt.Errorf("%w: only SHA-256 file digests are supported", ErrInvalidTimestamp)
+	}
+	digest := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(r, digest); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+	}
+	tree, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	t, err := ParseTimestamp(digest, tree)
+	if err != nil {
+		return err
+	}
+	d.Timestamp = t
+	return nil
+}
+
+func appendVarBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func readVarBytes(r *bytes.Reader, limit int) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimestamp, err)
+	}
+	if n > uint64(limit) || n > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: length %d out of range", ErrInvalidTimestamp, n)
+	}
+	b := make([]byte, n)
+	io.ReadFull(r, b)
+	return b, nil
+}
@@ -0,0 +1,106 @@
+package ots
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func TestTimestampEncoding(t *testing.T) {
+	digest := sha256.Sum256([]byte("round"))
+	root := NewTimestamp(digest[:])
+	left, _ := root.Add(Op{Tag: OpPrepend, Arg: []byte{1, 2}})
+	left, _ = left.Add(Op{Tag: OpSHA256})
+	left.Attest(PendingAttestation("https://calendar.example"))
+	right, _ := root.Add(Op{Tag: OpAppend, Arg: []byte{3}})
+	right, _ = right.Add(Op{Tag: OpReverse})
+	right.Attest(BitcoinAttestation(358391))
+	right.Attest(BitcoinAttestation(358391))
+	if len(right.Attestations) != 1 {
+		t.Fatal("Attest added a duplicate attestation")
+	}
+
+	d := &DetachedTimestamp{Timestamp: root}
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, headerMagic) || !bytes.Equal(data[len(headerMagic):len(headerMagic)+2], []byte{1, OpSHA256}) {
+		t.Fatalf("unexpected header: %x", data[:len(headerMagic)+2])
+	}
+	var decoded DetachedTimestamp
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	again, _ := decoded.MarshalBinary()
+	if !bytes.Equal(again, data) || !bytes.Equal(decoded.Digest(), digest[:]) {
+		t.Error("round trip changed the timestamp")
+	}
+
+	var uris []string
+	var heights []uint64
+	decoded.Timestamp.Walk(func(n *Timestamp) bool {
+		for _, a := range n.Attestations {
+			if uri, ok := a.URI(); ok {
+				uris = append(uris, uri)
+			}
+			if h, ok := a.BitcoinHeight(); ok {
+				heights = append(heights, h)
+				if !bytes.Equal(n.Msg, right.Msg) {
+					t.Error("decoded message differs from the computed one")
+				}
+			}
+		}
+		return true
+	})
+	if len(uris) != 1 || uris[0] != "https://calendar.example" || len(heights) != 1 || heights[0] != 358391 {
+		t.Errorf("unexpected attestations: %v %v", uris, heights)
+	}
+	if !decoded.Timestamp.Pending() || !decoded.Timestamp.confirmed() {
+		t.Error("Pending or confirmed misreported")
+	}
+
+	// 单个待定证明: 0x00 || 标签 || varbytes(varbytes(uri))
+	leaf := NewTimestamp(digest[:])
+	leaf.Attest(PendingAttestation("ab"))
+	enc, _ := leaf.MarshalBinary()
+	want := append(append([]byte{0x00}, pendingTag[:]...), 3, 2, 'a', 'b')
+	if !bytes.Equal(enc, want) {
+		t.Errorf("pending attestation encoded as %x, want %x", enc, want)
+	}
+
+	for name, bad := range map[string][]byte{
+		"truncated":   data[:len(data)-1],
+		"trailing":    append(bytes.Clone(data), 0),
+		"no magic":    data[1:],
+		"unknown tag": append(append(bytes.Clone(data[:len(headerMagic)+2+32]), 0x55), 0),
+	} {
+		if err := decoded.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidTimestamp) {
+			t.Errorf("%s: UnmarshalBinary returned %v", name, err)
+		}
+	}
+	unsupported := append(bytes.Clone(data[:len(headerMagic)+2+32]), OpRIPEMD160)
+	unsupported = append(unsupported, enc...)
+	if err := decoded.UnmarshalBinary(unsupported); !errors.Is(err, ErrUnsupportedOp) {
+		t.Errorf("ripemd160 returned %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := NewTimestamp([]byte("m"))
+	b := NewTimestamp([]byte("m"))
+	x, _ := a.Add(Op{Tag: OpSHA256})
+	x.Attest(PendingAttestation("a"))
+	y, _ := b.Add(Op{Tag: OpSHA256})
+	y.Attest(PendingAttestation("b"))
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(a.Branches) != 1 || len(a.Branches[0].Next.Attestations) != 2 {
+		t.Errorf("unexpected merge result: %+v", a)
+	}
+	if err := a.Merge(NewTimestamp([]byte("other"))); err == nil {
+		t.Error("merged timestamps of different messages")
+	}
+}
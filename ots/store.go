@@ -0,0 +1,281 @@
+package ots
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+)
+
+// roundDomain 是轮次摘要的域分隔前缀
+const roundDomain = "slothgo/ots/round/v1"
+
+var (
+	// ErrNoAnchor 表示该轮没有锚定
+	ErrNoAnchor = errors.New("ots: round is not anchored")
+	// ErrBadAnchor 表示锚定与读到的轮次不一致，轮次或锚定文件被改动过
+	ErrBadAnchor = errors.New("ots: anchor does not match round")
+)
+
+// RoundDigest 返回锚定第 r 轮时提交的摘要:
+// SHA-256(域分隔前缀 || index(8, 大端) || seed || randomness)
+// 种子承诺了上一轮的随机数与本轮的全部提交，随机数由种子唯一确定，因此摘要覆盖了整轮
+func RoundDigest(r *beacon.Round) []byte {
+	h := sha256.New()
+	h.Write([]byte(roundDomain))
+	binary.Write(h, binary.BigEndian, r.Index)
+	h.Write(r.Seed)
+	h.Write(r.Randomness)
+	return h.Sum(nil)
+}
+
+// StoreOption 用于在 NewStore 中配置 Store
+type StoreOption func(*Store)
+
+// WithBlockSource 使读取时同时验证锚定中的比特币证明，验证成功的轮次会被缓存
+func WithBlockSource(blocks BlockSource) StoreOption {
+	return func(s *Store) {
+		s.blocks = blocks
+	}
+}
+
+// WithErrorHandler 设置后台锚定失败、读取时无法访问 BlockSource 等不影响读写结果的错误的处理函数
+func WithErrorHandler(fn func(index uint64, err error)) StoreOption {
+	return func(s *Store) {
+		s.onError = fn
+	}
+}
+
+// WithTimeout 设置每次访问日历服务器或 BlockSource 的超时时间，默认为 30 秒
+func WithTimeout(d time.Duration) StoreOption {
+	return func(s *Store) {
+		s.timeout = d
+	}
+}
+
+// Store 包装 beacon.Store: Put 在写入之后于后台锚定该轮，锚定保存为 dir 中的 .ots 文件 (可以用
+// ots 命令行工具验证); Get 与 Latest 在返回之前检查锚定的摘要与读到的轮次一致，不一致时返回 ErrBadAnchor。
+// 没有锚定的轮次 (例如启用锚定之前的轮次、锚定失败的轮次) 照常返回，可以用 Anchor 补上
+type Store struct {
+	beacon.Store
+	dir     string
+	client  *Client
+	blocks  BlockSource
+	onError func(index uint64, err error)
+	timeout time.Duration
+
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	confirmed map[uint64]time.Time // 已验证比特币证明的轮次
+}
+
+// NewStore 在 inner 之上启用锚定，锚定文件保存在 dir 中
+func NewStore(inner beacon.Store, dir string, client *Client, opts ...StoreOption) (*Store, error) {
+	if inner == nil || client == nil {
+		return nil, errors.New("store and client cannot be nil")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{Store: inner, dir: dir, client: client, timeout: 30 * time.Second, confirmed: make(map[uint64]time.Time)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *Store) path(index uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.ots", index))
+}
+
+func (s *Store) report(index uint64, err error) {
+	if s.onError != nil {
+		s.onError(index, err)
+	}
+}
+
+// Put 实现 beacon.Store，写入成功后在后台锚定
+func (s *Store) Put(r *beacon.Round) error {
+	if err := s.Store.Put(r); err != nil {
+		return err
+	}
+	s.wg.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		if err := s.Anchor(ctx, r); err != nil {
+			s.report(r.Index, err)
+		}
+	})
+	return nil
+}
+
+// Get 实现 beacon.Store，返回之前检查锚定
+func (s *Store) Get(index uint64) (*beacon.Round, error) {
+	r, err := s.Store.Get(index)
+	if err != nil {
+		return nil, err
+	}
+	return r, s.check(r)
+}
+
+// Latest 实现 beacon.Store，返回之前检查锚定
+func (s *Store) Latest() (*beacon.Round, error) {
+	r, err := s.Store.Latest()
+	if err != nil {
+		return nil, err
+	}
+	return r, s.check(r)
+}
+
+// check 检查 r 的锚定: 摘要必须一致; 设置了 BlockSource 时比特币证明必须与区块头一致，
+// 访问不到 BlockSource 时只报告错误
+func (s *Store) check(r *beacon.Round) error {
+	d, err := s.Timestamp(r.Index)
+	if errors.Is(err, ErrNoAnchor) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("round %d: %w: %w", r.Index, ErrBadAnchor, err)
+	}
+	if !bytes.Equal(d.Digest(), RoundDigest(r)) {
+		return fmt.Errorf("round %d: %w", r.Index, ErrBadAnchor)
+	}
+	if s.blocks == nil || !d.Timestamp.confirmed() {
+		return nil
+	}
+	s.mu.Lock()
+	_, ok := s.confirmed[r.Index]
+	s.mu.Unlock()
+	if ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	at, err := Verify(ctx, d.Timestamp, s.blocks)
+	switch {
+	case errors.Is(err, ErrAttestationMismatch):
+		return fmt.Errorf("round %d: %w: %w", r.Index, ErrBadAnchor, err)
+	case err != nil:
+		s.report(r.Index, err)
+	default:
+		s.mu.Lock()
+		s.confirmed[r.Index] = at
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Anchor 把 r 提交给日历服务器并保存锚定，已经锚定的轮次不会重复提交
+func (s *Store) Anchor(ctx context.Context, r *beacon.Round) error {
+	if _, err := s.Timestamp(r.Index); !errors.Is(err, ErrNoAnchor) {
+		return err
+	}
+	t, err := s.client.Stamp(ctx, RoundDigest(r))
+	if err != nil {
+		return err
+	}
+	return s.save(r.Index, &DetachedTimestamp{Timestamp: t})
+}
+
+// Timestamp 返回第 index 轮的锚定，没有时返回 ErrNoAnchor
+func (s *Store) Timestamp(index uint64) (*DetachedTimestamp, error) {
+	data, err := os.ReadFile(s.path(index))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoAnchor
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := new(DetachedTimestamp)
+	if err := d.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Attested 验证第 index 轮锚定中的比特币证明，返回该轮存在的时间上限
+// 需要 WithBlockSource; 日历服务器尚未写入区块时返回 ErrPending (先调用 Upgrade)
+func (s *Store) Attested(ctx context.Context, index uint64) (time.Time, error) {
+	if s.blocks == nil {
+		return time.Time{}, errors.New("ots: no block source configured")
+	}
+	r, err := s.Store.Get(index)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d, err := s.Timestamp(index)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !bytes.Equal(d.Digest(), RoundDigest(r)) {
+		return time.Time{}, fmt.Errorf("round %d: %w", index, ErrBadAnchor)
+	}
+	at, err := Verify(ctx, d.Timestamp, s.blocks)
+	if err == nil {
+		s.mu.Lock()
+		s.confirmed[index] = at
+		s.mu.Unlock()
+	}
+	return at, err
+}
+
+// Upgrade 为 from 到 to (含) 之间仍有待定证明的锚定向日历服务器查询完整的证明，返回有变化的锚定数
+// 日历服务器通常在提交后几个小时内写入区块，应定期调用
+func (s *Store) Upgrade(ctx context.Context, from, to uint64) (int, error) {
+	n := 0
+	var errs []error
+	for index := from; index <= to; index++ {
+		d, err := s.Timestamp(index)
+		if errors.Is(err, ErrNoAnchor) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("round %d: %w", index, err))
+			continue
+		}
+		if !d.Timestamp.Pending() {
+			continue
+		}
+		changed, err := s.client.Upgrade(ctx, d.Timestamp)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("round %d: %w", index, err))
+		}
+		if changed {
+			if err := s.save(index, d); err != nil {
+				return n, err
+			}
+			n++
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// Close 等待后台的锚定完成，inner 实现了 io.Closer 时关闭它
+func (s *Store) Close() error {
+	s.wg.Wait()
+	if c, ok := s.Store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *Store) save(index uint64, d *DetachedTimestamp) error {
+	data, err := d.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path(index), data)
+}
@@ -0,0 +1,268 @@
+package ots
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+const testHeight = 800000
+
+var testBlockTime = time.Unix(1690000000, 0).UTC()
+
+// calendar 是模拟的日历服务器: 提交后返回待定证明，confirm 之后升级查询返回比特币证明
+type calendar struct {
+	*httptest.Server
+	mu        sync.Mutex
+	confirmed bool
+	heights   map[string]uint64 // 比特币证明的消息 (即区块头中的 Merkle 根) 所在的高度
+}
+
+func newCalendar(t *testing.T) *calendar {
+	c := &calendar{heights: make(map[string]uint64)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/digest":
+			msg, _ := io.ReadAll(r.Body)
+			ts := NewTimestamp(msg)
+			next, _ := ts.Add(Op{Tag: OpPrepend, Arg: []byte("calendar")})
+			next, _ = next.Add(Op{Tag: OpSHA256})
+			next.Attest(PendingAttestation(c.URL))
+			data, _ := ts.MarshalBinary()
+			w.Write(data)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/timestamp/"):
+			msg, err := hex.DecodeString(strings.TrimPrefix(r.URL.Path, "/timestamp/"))
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if err != nil || !c.confirmed {
+				http.NotFound(w, r)
+				return
+			}
+			ts := NewTimestamp(msg)
+			next, _ := ts.Add(Op{Tag: OpAppend, Arg: []byte("block")})
+			next, _ = next.Add(Op{Tag: OpSHA256})
+			height, ok := c.heights[string(next.Msg)]
+			if !ok {
+				height = testHeight + uint64(len(c.heights))
+				c.heights[string(next.Msg)] = height
+			}
+			next.Attest(BitcoinAttestation(height))
+			data, _ := ts.MarshalBinary()
+			w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *calendar) confirm() {
+	c.mu.Lock()
+	c.confirmed = true
+	c.mu.Unlock()
+}
+
+// header 构造 Merkle 根为 root、时间为 testBlockTime 的区块头
+func header(root []byte) []byte {
+	h := make([]byte, 80)
+	copy(h[36:68], root)
+	binary.LittleEndian.PutUint32(h[68:72], uint32(testBlockTime.Unix()))
+	return h
+}
+
+// blocks 是模拟的 BlockSource，区块的 Merkle 根是日历在该高度写入的根，其他区块的根为零
+type blocks struct {
+	c    *calendar
+	fail bool
+}
+
+func (b *blocks) BlockHeader(_ context.Context, height uint64) ([]byte, error) {
+	if b.fail {
+		return nil, errors.New("unreachable")
+	}
+	b.c.mu.Lock()
+	defer b.c.mu.Unlock()
+	for root, h := range b.c.heights {
+		if h == height {
+			return header([]byte(root)), nil
+		}
+	}
+	return header(make([]byte, 32)), nil
+}
+
+func newTestStore(t *testing.T, opts ...StoreOption) (*Store, *calendar, string) {
+	t.Helper()
+	c := newCalendar(t)
+	dir := t.TempDir()
+	s, err := NewStore(beacon.NewMemoryStore(), dir, &Client{Calendars: []string{c.URL}}, opts...)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return s, c, dir
+}
+
+func publish(t *testing.T, s *Store, rounds int) {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 100)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, s)
+	for range rounds {
+		if _, err := b.Publish(context.Background()); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	s.Close()
+}
+
+func TestStore(t *testing.T) {
+	var failures []error
+	bs := &blocks{}
+	s, c, dir := newTestStore(t, WithBlockSource(bs), WithErrorHandler(func(_ uint64, err error) {
+		failures = append(failures, err)
+	}))
+	bs.c = c
+	publish(t, s, 2)
+	ctx := context.Background()
+
+	d, err := s.Timestamp(1)
+	if err != nil {
+		t.Fatalf("round 1 was not anchored: %v", err)
+	}
+	r, err := s.Get(1)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !slices.Equal(d.Digest(), RoundDigest(r)) || !d.Timestamp.Pending() {
+		t.Error("anchor does not commit to the round")
+	}
+	if _, err := s.Attested(ctx, 1); !errors.Is(err, ErrPending) {
+		t.Errorf("Attested before confirmation returned %v", err)
+	}
+
+	// 日历尚未写入区块时升级没有变化
+	if n, err := s.Upgrade(ctx, 0, 1); n != 0 || err != nil {
+		t.Errorf("Upgrade before confirmation returned %d, %v", n, err)
+	}
+	c.confirm()
+	if n, err := s.Upgrade(ctx, 0, 5); n != 2 || err != nil {
+		t.Fatalf("Upgrade returned %d, %v", n, err)
+	}
+	if d, _ := s.Timestamp(0); d.Timestamp.Pending() {
+		t.Error("pending attestation was kept after the upgrade")
+	}
+	if at, err := s.Attested(ctx, 0); err != nil || !at.Equal(testBlockTime) {
+		t.Errorf("Attested returned %v, %v", at, err)
+	}
+	if _, err := s.Latest(); err != nil {
+		t.Errorf("Latest failed: %v", err)
+	}
+
+	// 把第 0 轮的锚定换成第 1 轮的，读取时必须发现
+	data, _ := os.ReadFile(s.path(1))
+	os.WriteFile(s.path(0), data, 0o644)
+	if _, err := s.Get(0); !errors.Is(err, ErrBadAnchor) {
+		t.Errorf("Get with a swapped anchor returned %v", err)
+	}
+	os.WriteFile(s.path(0), []byte("garbage"), 0o644)
+	if _, err := s.Get(0); !errors.Is(err, ErrBadAnchor) {
+		t.Errorf("Get with a corrupt anchor returned %v", err)
+	}
+
+	// 区块头与比特币证明不一致时读取失败，BlockSource 不可用时只报告
+	fresh, err := NewStore(s.Store, dir, s.client, WithBlockSource(&blocks{c: &calendar{heights: map[string]uint64{}}}))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if _, err := fresh.Get(1); !errors.Is(err, ErrBadAnchor) {
+		t.Errorf("Get with a mismatched block header returned %v", err)
+	}
+	failures = nil
+	offline, _ := NewStore(s.Store, dir, s.client, WithBlockSource(&blocks{fail: true}), WithErrorHandler(func(_ uint64, err error) {
+		failures = append(failures, err)
+	}))
+	if _, err := offline.Get(1); err != nil || len(failures) != 1 {
+		t.Errorf("Get with an unreachable block source returned %v, reported %v", err, failures)
+	}
+}
+
+func TestStoreWithoutCalendar(t *testing.T) {
+	var mu sync.Mutex
+	var failures []uint64
+	s, err := NewStore(beacon.NewMemoryStore(), t.TempDir(), &Client{Calendars: []string{"http://127.0.0.1:1"}}, WithErrorHandler(func(index uint64, _ error) {
+		mu.Lock()
+		failures = append(failures, index)
+		mu.Unlock()
+	}))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	publish(t, s, 1)
+	if len(failures) != 1 {
+		t.Errorf("expected one anchoring failure, got %v", failures)
+	}
+	// 没有锚定的轮次照常读取
+	if _, err := s.Get(0); err != nil {
+		t.Errorf("Get of an unanchored round failed: %v", err)
+	}
+	if _, err := s.Timestamp(0); !errors.Is(err, ErrNoAnchor) {
+		t.Errorf("Timestamp returned %v", err)
+	}
+}
+
+func TestEsplora(t *testing.T) {
+	h := header(sha256.New().Sum(nil))
+	first := sha256.Sum256(h)
+	id := sha256.Sum256(first[:])
+	slices.Reverse(id[:])
+	hash := hex.EncodeToString(id[:])
+	tamper := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/block-height/5":
+			io.WriteString(w, hash)
+		case "/block/" + hash + "/header":
+			out := slices.Clone(h)
+			if tamper {
+				out[0] ^= 1
+			}
+			io.WriteString(w, hex.EncodeToString(out))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	e := &Esplora{URL: srv.URL}
+	got, err := e.BlockHeader(context.Background(), 5)
+	if err != nil || !slices.Equal(got, h) {
+		t.Fatalf("BlockHeader returned %x, %v", got, err)
+	}
+	if _, err := e.BlockHeader(context.Background(), 6); err == nil {
+		t.Error("missing block was returned")
+	}
+	tamper = true
+	if _, err := e.BlockHeader(context.Background(), 5); err == nil {
+		t.Error("header that does not match the block hash was accepted")
+	}
+}
@@ -0,0 +1,110 @@
+package ots
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAttestationMismatch 表示比特币证明的消息与区块头中的 Merkle 根不一致
+var ErrAttestationMismatch = errors.New("ots: attestation does not match block header")
+
+// BlockSource 提供比特币区块头，可以是自己的全节点、Esplora 等区块浏览器
+type BlockSource interface {
+	// BlockHeader 返回第 height 个区块的 80 字节区块头
+	BlockHeader(ctx context.Context, height uint64) ([]byte, error)
+}
+
+// Verify 检查 t 中的全部比特币证明，返回其中最早的区块时间，即 t 的根消息存在的时间上限
+// 没有比特币证明时返回 ErrPending
+func Verify(ctx context.Context, t *Timestamp, blocks BlockSource) (time.Time, error) {
+	type attested struct {
+		msg    []byte
+		height uint64
+	}
+	var todo []attested
+	t.Walk(func(n *Timestamp) bool {
+		for _, a := range n.Attestations {
+			if height, ok := a.BitcoinHeight(); ok {
+				todo = append(todo, attested{n.Msg, height})
+			}
+		}
+		return true
+	})
+	if len(todo) == 0 {
+		return time.Time{}, ErrPending
+	}
+	var earliest time.Time
+	for _, a := range todo {
+		header, err := blocks.BlockHeader(ctx, a.height)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("block %d: %w", a.height, err)
+		}
+		if len(header) != 80 {
+			return time.Time{}, fmt.Errorf("block %d: header must be 80 bytes", a.height)
+		}
+		// 证明的消息是区块头中按内部字节序保存的 Merkle 根
+		if !bytes.Equal(a.msg, header[36:68]) {
+			return time.Time{}, fmt.Errorf("%w: block %d", ErrAttestationMismatch, a.height)
+		}
+		at := time.Unix(int64(binary.LittleEndian.Uint32(header[68:72])), 0).UTC()
+		if earliest.IsZero() || at.Before(earliest) {
+			earliest = at
+		}
+	}
+	return earliest, nil
+}
+
+// Esplora 从 Esplora 接口 (blockstream.info、mempool.space 等) 读取区块头
+// 返回的区块头按其哈希与接口给出的区块哈希比对，但区块是否在最长链上取决于对该服务的信任，
+// 需要独立验证时应使用自己的节点
+type Esplora struct {
+	URL  string       // 接口地址，例如 https://blockstream.info/api
+	HTTP *http.Client // 为 nil 时使用 http.DefaultClient
+}
+
+// BlockHeader 实现 BlockSource
+func (e *Esplora) BlockHeader(ctx context.Context, height uint64) ([]byte, error) {
+	base := strings.TrimSuffix(e.URL, "/")
+	hash, err := e.get(ctx, base+"/block-height/"+strconv.FormatUint(height, 10))
+	if err != nil {
+		return nil, err
+	}
+	headerHex, err := e.get(ctx, base+"/block/"+hash+"/header")
+	if err != nil {
+		return nil, err
+	}
+	header, err := hex.DecodeString(headerHex)
+	if err != nil || len(header) != 80 {
+		return nil, errors.New("esplora: invalid block header")
+	}
+	// 区块哈希是区块头的双重 SHA-256，按字节反序显示
+	first := sha256.Sum256(header)
+	id := sha256.Sum256(first[:])
+	slices.Reverse(id[:])
+	if hex.EncodeToString(id[:]) != hash {
+		return nil, errors.New("esplora: block header does not match block hash")
+	}
+	return header, nil
+}
+
+func (e *Esplora) get(ctx context.Context, url string) (string, error) {
+	c := &Client{HTTP: e.HTTP}
+	data, status, err := c.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("esplora: %s: unexpected status %d", url, status)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,124 @@
+package p2p
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// ErrInvalidRound 表示收到的轮次验证失败或与本地的链冲突，发送方不可信
+var ErrInvalidRound = errors.New("invalid round")
+
+// Chain 是节点本地的轮次链: 收到的轮次按顺序验证后写入存储。Node 与基于 libp2p 的 p2p/gossip
+// 模块都通过它接收轮次，两种传输可以共用同一条链。Chain 可以被并发调用
+type Chain struct {
+	vdf   *slothgo.Sloth
+	store beacon.Store
+
+	// OnRound 在一轮验证并写入存储后按轮次顺序调用，必须在开始接收轮次之前设置
+	// 与本地信标共用存储时，信标自己发布的轮次不会触发 OnRound
+	OnRound func(r *beacon.Round)
+
+	// Difficulty 不为空时每一轮的迭代次数必须等于它按之前各轮算出的值 (第 0 轮为 vdf 的迭代次数)，
+	// 与发布方的 beacon.Beacon.Difficulty 使用相同的参数，必须在开始接收轮次之前设置
+	Difficulty *beacon.DifficultyController
+
+	mu sync.Mutex // 保证验证与写入存储按顺序进行
+}
+
+// NewChain 创建使用 vdf 的参数验证轮次、把轮次保存在 store 中的链
+func NewChain(vdf *slothgo.Sloth, store beacon.Store) *Chain {
+	return &Chain{vdf: vdf, store: store}
+}
+
+// VDF 返回验证轮次所用的实例
+func (c *Chain) VDF() *slothgo.Sloth {
+	return c.vdf
+}
+
+// Height 返回本地已有的轮数
+func (c *Chain) Height() (uint64, error) {
+	latest, err := c.store.Latest()
+	switch {
+	case errors.Is(err, beacon.ErrNotFound):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return latest.Index + 1, nil
+}
+
+// Rounds 返回第 from 轮起最多 count 轮 (不超过 MaxBatch)，本地没有的轮次不返回
+func (c *Chain) Rounds(from uint64, count int) ([]*beacon.Round, error) {
+	var rounds []*beacon.Round
+	for i := from; i < from+uint64(min(max(count, 0), MaxBatch)); i++ {
+		r, err := c.store.Get(i)
+		if errors.Is(err, beacon.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, r)
+	}
+	return rounds, nil
+}
+
+// Ingest 验证并保存紧接在本地最新一轮之后的一轮，added 报告是否是新的一轮
+// 已有的轮次必须与本地一致，更靠后的轮次暂时忽略 (之后由调用方按顺序补齐)。
+// 轮次本身有误时返回的错误包装 ErrInvalidRound，其他错误来自存储
+func (c *Chain) Ingest(r *beacon.Round) (added bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	height, err := c.Height()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case r.Index < height:
+		have, err := c.store.Get(r.Index)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(have.Randomness, r.Randomness) {
+			return false, fmt.Errorf("%w: round %d conflicts with the local chain", ErrInvalidRound, r.Index)
+		}
+		return false, nil
+	case r.Index > height:
+		return false, nil
+	}
+
+	var prev *beacon.Round
+	if r.Index > 0 {
+		if prev, err = c.store.Get(r.Index - 1); err != nil {
+			return false, err
+		}
+	}
+	vdf := c.vdf
+	if c.Difficulty != nil {
+		want, err := c.Difficulty.IterationsAt(c.store, c.vdf.Iterations, r.Index)
+		if err != nil {
+			return false, err
+		}
+		if r.Proof.Iterations != want {
+			return false, fmt.Errorf("%w: round %d uses %d iterations, expected %d", ErrInvalidRound, r.Index, r.Proof.Iterations, want)
+		}
+		if vdf, err = c.vdf.WithIterations(want); err != nil {
+			return false, err
+		}
+	}
+	if err := beacon.Verify(vdf, r, prev); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidRound, err)
+	}
+	if err := c.store.Put(r); err != nil {
+		return false, err
+	}
+	if c.OnRound != nil {
+		c.OnRound(r)
+	}
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// TestChainIngest 检查轮次按顺序写入、重复与更靠后的轮次被忽略、冲突与无效的轮次报告 ErrInvalidRound
+func TestChainIngest(t *testing.T) {
+	ctx := context.Background()
+	vdf := newTestVDF(t)
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	var rounds []*beacon.Round
+	for range 3 {
+		r, err := b.Publish(ctx)
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		rounds = append(rounds, r)
+	}
+
+	c := NewChain(vdf, beacon.NewMemoryStore())
+	var notified []uint64
+	c.OnRound = func(r *beacon.Round) { notified = append(notified, r.Index) }
+	if added, err := c.Ingest(rounds[1]); added || err != nil {
+		t.Fatalf("Ingest of a future round = %v, %v", added, err)
+	}
+	for _, r := range rounds[:2] {
+		if added, err := c.Ingest(r); !added || err != nil {
+			t.Fatalf("Ingest of round %d = %v, %v", r.Index, added, err)
+		}
+	}
+	if added, err := c.Ingest(rounds[0]); added || err != nil {
+		t.Fatalf("Ingest of a known round = %v, %v", added, err)
+	}
+	if height, _ := c.Height(); height != 2 || len(notified) != 2 {
+		t.Fatalf("height %d, notified %v", height, notified)
+	}
+	if got, _ := c.Rounds(1, 10); len(got) != 1 || got[0].Index != 1 {
+		t.Errorf("Rounds(1, 10) = %v", got)
+	}
+
+	conflict := *rounds[1]
+	conflict.Randomness = []byte("other")
+	if _, err := c.Ingest(&conflict); !errors.Is(err, ErrInvalidRound) {
+		t.Errorf("Ingest of a conflicting round returned %v", err)
+	}
+	forged := *rounds[2]
+	forged.Randomness = append([]byte(nil), forged.Randomness...)
+	forged.Randomness[0] ^= 1
+	if _, err := c.Ingest(&forged); !errors.Is(err, ErrInvalidRound) {
+		t.Errorf("Ingest of a forged round returned %v", err)
+	}
+}
+
+// TestChainDifficulty 检查设置了 Difficulty 的链按之前各轮算出每一轮应有的迭代次数，拒绝不一致的轮次
+func TestChainDifficulty(t *testing.T) {
+	vdf := newTestVDF(t)
+	newController := func(target time.Duration) *beacon.DifficultyController {
+		t.Helper()
+		dc, err := beacon.NewDifficultyController(beacon.DifficultyConfig{Target: target, MaxIterations: 4000})
+		if err != nil {
+			t.Fatalf("NewDifficultyController failed: %v", err)
+		}
+		return dc
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	b.Difficulty = newController(time.Hour)
+	var rounds []*beacon.Round
+	for range 3 {
+		r, err := b.Publish(context.Background())
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		rounds = append(rounds, r)
+	}
+
+	c := NewChain(vdf, beacon.NewMemoryStore())
+	c.Difficulty = newController(time.Hour)
+	for _, r := range rounds {
+		if added, err := c.Ingest(r); !added || err != nil {
+			t.Fatalf("Ingest of round %d with %d iterations = %v, %v", r.Index, r.Proof.Iterations, added, err)
+		}
+	}
+
+	// 参数不同的链在第 1 轮就算出不同的迭代次数
+	other := NewChain(vdf, beacon.NewMemoryStore())
+	other.Difficulty = newController(time.Nanosecond)
+	if _, err := other.Ingest(rounds[0]); err != nil {
+		t.Fatalf("Ingest of round 0 failed: %v", err)
+	}
+	if _, err := other.Ingest(rounds[1]); !errors.Is(err, ErrInvalidRound) {
+		t.Errorf("Ingest of a round with unexpected iterations returned %v", err)
+	}
+	// 不设置 Difficulty 的链只接受固定迭代次数的轮次
+	fixed := NewChain(vdf, beacon.NewMemoryStore())
+	fixed.Ingest(rounds[0])
+	if _, err := fixed.Ingest(rounds[1]); !errors.Is(err, ErrInvalidRound) {
+		t.Errorf("Ingest without Difficulty returned %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// sloth-beacon-libp2p 与 sloth-beacon 相同，但通过 libp2p 分发轮次 (见 p2p/gossip):
+// -p2p 是逗号分隔的监听 multiaddr (例如 /ip4/0.0.0.0/tcp/4001)，-peers 是带 /p2p/<peer ID> 的引导节点，
+// -mdns 在局域网中自动发现其他节点。-identity 指定的文件保存节点的身份密钥，不存在时生成，
+// 使重启后 peer ID 不变。其他选项、配置文件与环境变量见 sloth-beacon
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+	"github.com/alan22333/sloth_go/internal/beaconnode"
+	"github.com/alan22333/sloth_go/internal/config"
+	"github.com/alan22333/sloth_go/p2p"
+	"github.com/alan22333/sloth_go/p2p/gossip"
+)
+
+func main() {
+	s := beaconnode.DefaultSettings()
+	s.Register(flag.CommandLine)
+	configFile := flag.String("config", os.Getenv(beaconnode.EnvPrefix+"CONFIG"), "配置文件 (YAML), 键见 beaconnode.Settings")
+	mdns := flag.Bool("mdns", false, "在局域网中用 mDNS 发现其他节点")
+	identity := flag.String("identity", "", "保存节点身份密钥的文件, 为空时每次启动随机生成")
+	flag.Parse()
+	if err := config.Load(&s, *configFile, beaconnode.EnvPrefix, flag.CommandLine); err != nil {
+		log.Fatalf("加载设置失败: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		log.Fatalf("设置有误: %v", err)
+	}
+	cfg, err := s.Config()
+	if err != nil {
+		log.Fatalf("加载素数失败: %v", err)
+	}
+	gcfg := gossip.Config{Bootstrap: cfg.Peers, MDNS: *mdns}
+	if cfg.P2PAddr != "" {
+		gcfg.ListenAddrs = strings.Split(cfg.P2PAddr, ",")
+	}
+	if *identity != "" {
+		if gcfg.Identity, err = loadIdentity(*identity); err != nil {
+			log.Fatalf("加载身份密钥失败: %v", err)
+		}
+	}
+	cfg.NewNetwork = func(chain *p2p.Chain) (beaconnode.Network, error) {
+		return gossip.New(chain, gcfg)
+	}
+	node, err := beaconnode.New(cfg)
+	if err != nil {
+		log.Fatalf("创建信标节点失败: %v", err)
+	}
+	defer node.Close()
+	lis, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		log.Fatalf("监听失败: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := node.Run(ctx, lis); err != nil {
+		log.Fatalf("HTTP 服务失败: %v", err)
+	}
+}
+
+// loadIdentity 读取 path 中的身份密钥，文件不存在时生成 Ed25519 密钥并保存
+func loadIdentity(path string) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return crypto.UnmarshalPrivateKey(data)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = crypto.MarshalPrivateKey(key); err != nil {
+		return nil, err
+	}
+	return key, atomicfile.WriteFile(path, data)
+}
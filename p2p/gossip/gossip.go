@@ -0,0 +1,369 @@
+// Package gossip 在 go-libp2p 上分发信标轮次，是 p2p.Node 的 TCP 协议之外的另一种传输:
+//
+//   - 新的轮次通过 GossipSub 主题 /sloth/beacon/<参数指纹> 发布，消息是 JSON 编码的 beacon.Round
+//   - 主题的校验函数把轮次交给 p2p.Chain: 有效的新轮次才继续转发，无效的轮次降低发送方的评分
+//   - 历史轮次通过请求/响应协议 /sloth/<参数指纹>/rounds/1.0.0 按批拉取，建立连接与发现缺口时触发
+//   - 节点通过引导节点、局域网 mDNS 与 GossipSub 的 peer exchange 发现彼此
+//
+// 它依赖 go-libp2p，因此是单独的 Go 模块，版本固定在 go.mod 中。其他语言的 libp2p 实现
+// 只要订阅同一主题并使用相同的 JSON 编码即可互通
+package gossip
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/p2p"
+)
+
+const (
+	// MDNSService 是局域网 mDNS 发现使用的服务名
+	MDNSService = "sloth-beacon"
+	// maxMessageSize 是单条消息的最大字节数，与 p2p.Node 一致
+	maxMessageSize = 16 << 20
+	// syncTimeout 是拉取一批历史轮次的超时
+	syncTimeout = 30 * time.Second
+	// retry 是与引导节点断开后重连的间隔
+	retry = 5 * time.Second
+)
+
+// ErrNotStarted 表示 Network 还没有 Start
+var ErrNotStarted = errors.New("network not started")
+
+// Config 是 libp2p 网络的配置
+type Config struct {
+	ListenAddrs []string       // 监听的 multiaddr, 例如 /ip4/0.0.0.0/tcp/4001; 为空时使用 libp2p 的默认地址
+	Bootstrap   []string       // 引导节点, 带 /p2p/<peer ID> 的 multiaddr; 断开后自动重连
+	MDNS        bool           // 在局域网中用 mDNS 发现其他节点
+	Identity    crypto.PrivKey // 节点的身份密钥, 为空时每次启动随机生成
+}
+
+// Network 通过 libp2p 收发 chain 上的轮次，实现 beaconnode.Network
+type Network struct {
+	chain     *p2p.Chain
+	cfg       Config
+	bootstrap []peer.AddrInfo
+	topicName string
+	protocol  protocol.ID
+
+	mu    sync.Mutex
+	ctx   context.Context
+	host  host.Host
+	topic *pubsub.Topic
+
+	syncMu  sync.Mutex
+	syncing map[peer.ID]bool
+}
+
+// New 创建把收到的轮次交给 chain 的网络，Start 之后才开始监听
+func New(chain *p2p.Chain, cfg Config) (*Network, error) {
+	n := &Network{
+		chain:   chain,
+		cfg:     cfg,
+		syncing: make(map[peer.ID]bool),
+	}
+	for _, addr := range cfg.Bootstrap {
+		info, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bootstrap peer %q: %w", addr, err)
+		}
+		n.bootstrap = append(n.bootstrap, *info)
+	}
+	fp := hex.EncodeToString(chain.VDF().Fingerprint())
+	n.topicName = "/sloth/beacon/" + fp
+	n.protocol = protocol.ID("/sloth/" + fp + "/rounds/1.0.0")
+	return n, nil
+}
+
+// Host 返回 Start 创建的 libp2p 主机，Start 之前为 nil
+func (n *Network) Host() host.Host {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.host
+}
+
+// Start 创建 libp2p 主机、加入主题并开始发现与同步，ctx 被取消时关闭主机
+func (n *Network) Start(ctx context.Context) error {
+	var opts []libp2p.Option
+	if len(n.cfg.ListenAddrs) > 0 {
+		opts = append(opts, libp2p.ListenAddrStrings(n.cfg.ListenAddrs...))
+	}
+	if n.cfg.Identity != nil {
+		opts = append(opts, libp2p.Identity(n.cfg.Identity))
+	}
+	h, err := libp2p.New(opts...)
+	if err != nil {
+		return err
+	}
+	topic, sub, err := n.join(ctx, h)
+	if err != nil {
+		h.Close()
+		return err
+	}
+	var disc mdns.Service
+	if n.cfg.MDNS {
+		disc = mdns.NewMdnsService(h, MDNSService, &notifee{ctx: ctx, h: h})
+		if err := disc.Start(); err != nil {
+			topic.Close()
+			h.Close()
+			return err
+		}
+	}
+	n.mu.Lock()
+	n.ctx, n.host, n.topic = ctx, h, topic
+	n.mu.Unlock()
+
+	h.SetStreamHandler(n.protocol, n.serveRounds)
+	h.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) { go n.sync(ctx, c.RemotePeer()) },
+	})
+	for _, info := range n.bootstrap {
+		go n.bootstrapLoop(ctx, h, info)
+	}
+	go func() {
+		// 轮次已经在校验函数中写入 chain，这里只需要取走消息
+		for {
+			if _, err := sub.Next(ctx); err != nil {
+				break
+			}
+		}
+		sub.Cancel()
+		if disc != nil {
+			disc.Close()
+		}
+		topic.Close()
+		h.Close()
+	}()
+	log.Printf("libp2p 节点 %s 监听于 %v", h.ID(), h.Addrs())
+	return nil
+}
+
+// join 在 h 上启动 GossipSub 并订阅轮次主题
+// 本地发布的轮次直接发给主题中的全部节点 (flood publish): 轮次很少且不大，而且刚连接、
+// 还没有经过心跳建立 mesh 时只按 mesh 发送会把这一轮丢掉
+func (n *Network) join(ctx context.Context, h host.Host) (*pubsub.Topic, *pubsub.Subscription, error) {
+	ps, err := pubsub.NewGossipSub(ctx, h,
+		pubsub.WithPeerExchange(true),
+		pubsub.WithFloodPublish(true),
+		pubsub.WithMaxMessageSize(maxMessageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	validate := func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		return n.validate(ctx, h, msg)
+	}
+	if err := ps.RegisterTopicValidator(n.topicName, validate); err != nil {
+		return nil, nil, err
+	}
+	topic, err := ps.Join(n.topicName)
+	if err != nil {
+		return nil, nil, err
+	}
+	sub, err := topic.Subscribe()
+	if err != nil {
+		topic.Close()
+		return nil, nil, err
+	}
+	return topic, sub, nil
+}
+
+// validate 把收到的轮次交给 chain: 新的轮次继续转发，重复的轮次不再转发，
+// 无效的轮次拒绝，比本地更靠后的轮次先忽略，并从转发它的节点拉取缺少的轮次
+func (n *Network) validate(ctx context.Context, h host.Host, msg *pubsub.Message) pubsub.ValidationResult {
+	if msg.GetFrom() == h.ID() {
+		// 本地发布的轮次已经在存储中
+		return pubsub.ValidationAccept
+	}
+	var r beacon.Round
+	if err := json.Unmarshal(msg.Data, &r); err != nil {
+		return pubsub.ValidationReject
+	}
+	added, err := n.chain.Ingest(&r)
+	switch {
+	case errors.Is(err, p2p.ErrInvalidRound):
+		log.Printf("节点 %s 转发了无效的第 %d 轮: %v", msg.ReceivedFrom, r.Index, err)
+		return pubsub.ValidationReject
+	case err != nil:
+		log.Printf("保存第 %d 轮失败: %v", r.Index, err)
+		return pubsub.ValidationIgnore
+	case added:
+		return pubsub.ValidationAccept
+	}
+	if height, err := n.chain.Height(); err == nil && r.Index > height {
+		go n.sync(n.context(), msg.ReceivedFrom)
+	}
+	return pubsub.ValidationIgnore
+}
+
+// Announce 在主题上发布本地的一轮
+func (n *Network) Announce(r *beacon.Round) error {
+	n.mu.Lock()
+	ctx, topic := n.ctx, n.topic
+	n.mu.Unlock()
+	if topic == nil {
+		return ErrNotStarted
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return topic.Publish(ctx, data)
+}
+
+func (n *Network) context() context.Context {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.ctx
+}
+
+// syncRequest 请求第 From 轮起最多 Count 轮，响应是每行一个 JSON 编码的轮次
+type syncRequest struct {
+	From  uint64 `json:"from"`
+	Count int    `json:"count"`
+}
+
+// serveRounds 响应一次历史轮次请求
+func (n *Network) serveRounds(s network.Stream) {
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(syncTimeout))
+	var req syncRequest
+	if err := json.NewDecoder(io.LimitReader(s, 1<<10)).Decode(&req); err != nil {
+		s.Reset()
+		return
+	}
+	rounds, err := n.chain.Rounds(req.From, req.Count)
+	if err != nil {
+		s.Reset()
+		return
+	}
+	enc := json.NewEncoder(s)
+	for _, r := range rounds {
+		if err := enc.Encode(r); err != nil {
+			s.Reset()
+			return
+		}
+	}
+}
+
+// sync 从 p 按批拉取本地缺少的轮次，直到对方没有更多的轮次。
+// 同一节点同时只有一次同步，网络错误不记录，下一次发现缺口时会重试
+func (n *Network) sync(ctx context.Context, p peer.ID) {
+	n.syncMu.Lock()
+	if n.syncing[p] {
+		n.syncMu.Unlock()
+		return
+	}
+	n.syncing[p] = true
+	n.syncMu.Unlock()
+	defer func() {
+		n.syncMu.Lock()
+		delete(n.syncing, p)
+		n.syncMu.Unlock()
+	}()
+
+	for {
+		height, err := n.chain.Height()
+		if err != nil {
+			return
+		}
+		rounds, err := n.fetch(ctx, p, height)
+		if err != nil {
+			return
+		}
+		for _, r := range rounds {
+			if _, err := n.chain.Ingest(r); err != nil {
+				log.Printf("从节点 %s 同步第 %d 轮失败: %v", p, r.Index, err)
+				return
+			}
+		}
+		if len(rounds) < p2p.MaxBatch {
+			return
+		}
+	}
+}
+
+// fetch 向 p 请求第 from 轮起的一批轮次
+func (n *Network) fetch(ctx context.Context, p peer.ID, from uint64) ([]*beacon.Round, error) {
+	h := n.Host()
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+	s, err := h.NewStream(ctx, p, n.protocol)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	deadline, _ := ctx.Deadline()
+	s.SetDeadline(deadline)
+	if err := json.NewEncoder(s).Encode(syncRequest{From: from, Count: p2p.MaxBatch}); err != nil {
+		s.Reset()
+		return nil, err
+	}
+	if err := s.CloseWrite(); err != nil {
+		s.Reset()
+		return nil, err
+	}
+	var rounds []*beacon.Round
+	sc := bufio.NewScanner(s)
+	sc.Buffer(make([]byte, 0, 64<<10), maxMessageSize)
+	for sc.Scan() {
+		var r beacon.Round
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			s.Reset()
+			return nil, err
+		}
+		rounds = append(rounds, &r)
+	}
+	if err := sc.Err(); err != nil {
+		s.Reset()
+		return nil, err
+	}
+	return rounds, nil
+}
+
+// bootstrapLoop 保持与引导节点的连接，直到 ctx 被取消
+func (n *Network) bootstrapLoop(ctx context.Context, h host.Host, info peer.AddrInfo) {
+	h.ConnManager().Protect(info.ID, "sloth-bootstrap")
+	for {
+		if h.Network().Connectedness(info.ID) != network.Connected {
+			if err := h.Connect(ctx, info); err != nil && ctx.Err() == nil {
+				log.Printf("连接引导节点 %s 失败: %v, %v 后重试", info.ID, err, retry)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// notifee 连接 mDNS 发现的节点
+type notifee struct {
+	ctx context.Context
+	h   host.Host
+}
+
+func (d *notifee) HandlePeerFound(info peer.AddrInfo) {
+	if info.ID == d.h.ID() {
+		return
+	}
+	go d.h.Connect(d.ctx, info)
+}
@@ -0,0 +1,89 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/p2p"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// waitFor 等待 cond 成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestGossip 检查新节点通过引导节点连接后拉取超过一批的历史轮次，之后通过 GossipSub 收到新的轮次
+func TestGossip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vdf := newTestVDF(t)
+
+	storeA := beacon.NewMemoryStore()
+	b := beacon.New(vdf, storeA)
+	for range p2p.MaxBatch + 6 {
+		if _, err := b.Publish(ctx); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	a, err := New(p2p.NewChain(vdf, storeA), Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	addrA := a.Host().Addrs()[0].String() + "/p2p/" + a.Host().ID().String()
+	chainB := p2p.NewChain(vdf, beacon.NewMemoryStore())
+	nB, err := New(chainB, Config{ListenAddrs: []string{"/ip4/127.0.0.1/tcp/0"}, Bootstrap: []string{addrA}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := nB.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	synced := func() bool {
+		latest, _ := storeA.Latest()
+		h, _ := chainB.Height()
+		return h == latest.Index+1
+	}
+	waitFor(t, "history sync", synced)
+
+	// 不等待心跳建立 mesh，刚看到对方订阅就发布
+	waitFor(t, "topic peers", func() bool { return len(a.topic.ListPeers()) > 0 })
+	r, err := b.Publish(ctx)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := a.Announce(r); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	waitFor(t, "gossiped round", synced)
+
+	if _, err := New(chainB, Config{Bootstrap: []string{"not a multiaddr"}}); err == nil {
+		t.Error("New accepted an invalid bootstrap address")
+	}
+}
@@ -0,0 +1,265 @@
+// Package p2p 实现信标节点之间的 gossip 协议: 节点把新发布的轮次转发给所有相邻节点，
+// 收到的每一轮都先用 beacon.Verify 验证再写入本地存储并继续转发，
+// 新加入或落后的节点从相邻节点拉取缺失的历史轮次。
+//
+// 传输层是 TCP 上以换行分隔的 JSON 消息，连接建立后双方先交换 hello:
+//
+//	{"type":"hello","fingerprint":..., "height":n}  参数指纹与本地轮数，指纹不一致时断开
+//	{"type":"get_rounds","from":i,"count":k}       请求第 i 轮起的 k 轮 (k <= MaxBatch)
+//	{"type":"round","round":{...}}                 一轮信标输出，应答 get_rounds 或转发新轮次
+//
+// 发送无效轮次的节点会被断开。收到的轮次经 Chain 验证与保存，同一条链也可以交给
+// 基于 libp2p pubsub 的 p2p/gossip 模块 (单独的 Go 模块)，后者提供节点发现并与其他 libp2p 节点互通
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+const (
+	// MaxBatch 是一次 get_rounds 请求的最大轮数
+	MaxBatch = 64
+	// maxMessageSize 是单条消息的最大字节数，足够容纳带有大量提交的一轮
+	maxMessageSize = 16 << 20
+	// writeTimeout 是单条消息的写超时，避免一个卡住的节点阻塞转发
+	writeTimeout = 10 * time.Second
+)
+
+// 消息类型
+const (
+	msgHello     = "hello"
+	msgGetRounds = "get_rounds"
+	msgRound     = "round"
+)
+
+// message 是协议中的一条消息，各类型只使用其中的部分字段
+type message struct {
+	Type        string        `json:"type"`
+	Fingerprint []byte        `json:"fingerprint,omitempty"`
+	Height      uint64        `json:"height,omitempty"`
+	From        uint64        `json:"from,omitempty"`
+	Count       int           `json:"count,omitempty"`
+	Round       *beacon.Round `json:"round,omitempty"`
+}
+
+// Node 是一个信标节点，可以同时接受入站连接并主动连接其他节点
+type Node struct {
+	chain *Chain
+
+	mu    sync.Mutex
+	peers map[*peer]struct{}
+}
+
+// peer 是一条已建立的连接，send 可以被并发调用
+// height 与 requested 只由该连接的读协程访问
+type peer struct {
+	conn net.Conn
+	mu   sync.Mutex
+	enc  *json.Encoder
+
+	height    uint64 // 已知的对方轮数
+	requested uint64 // 已经请求到的位置 (不含)
+}
+
+func (p *peer) send(m *message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return p.enc.Encode(m)
+}
+
+// NewNode 创建使用 vdf 的参数验证轮次、把轮次保存在 store 中的节点
+func NewNode(vdf *slothgo.Sloth, store beacon.Store) *Node {
+	return NewChainNode(NewChain(vdf, store))
+}
+
+// NewChainNode 创建通过 chain 接收轮次的节点
+func NewChainNode(chain *Chain) *Node {
+	return &Node{chain: chain, peers: make(map[*peer]struct{})}
+}
+
+// Chain 返回节点的轮次链，需要在同步之前设置 Chain.OnRound
+func (n *Node) Chain() *Chain {
+	return n.chain
+}
+
+// Peers 返回当前连接的节点数
+func (n *Node) Peers() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.peers)
+}
+
+// Height 返回本地已有的轮数
+func (n *Node) Height() (uint64, error) {
+	return n.chain.Height()
+}
+
+// Listen 在 lis 上接受连接，直到 ctx 结束或 lis 关闭
+func (n *Node) Listen(ctx context.Context, lis net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		lis.Close()
+	}()
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		go n.ServeConn(ctx, conn)
+	}
+}
+
+// Dial 连接 addr 上的节点并处理该连接，直到连接断开或 ctx 结束
+func (n *Node) Dial(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return n.ServeConn(ctx, conn)
+}
+
+// ServeConn 在已建立的连接上运行协议，直到连接断开或 ctx 结束，返回断开的原因
+// 可以用于 Dial 以外建立的连接 (例如 TLS 连接)；连接需要有写缓冲，双方会同时发送 hello
+func (n *Node) ServeConn(ctx context.Context, conn net.Conn) error {
+	p := &peer{conn: conn, enc: json.NewEncoder(conn)}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	height, err := n.Height()
+	if err != nil {
+		return err
+	}
+	if err := p.send(&message{Type: msgHello, Fingerprint: n.chain.vdf.Fingerprint(), Height: height}); err != nil {
+		return err
+	}
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 0, 64<<10), maxMessageSize)
+	registered := false
+	defer func() {
+		if registered {
+			n.mu.Lock()
+			delete(n.peers, p)
+			n.mu.Unlock()
+		}
+	}()
+	for sc.Scan() {
+		var m message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			return fmt.Errorf("malformed message: %w", err)
+		}
+		if !registered {
+			// 第一条消息必须是 hello，之后该连接才参与转发
+			if m.Type != msgHello {
+				return errors.New("expected hello")
+			}
+			if !bytes.Equal(m.Fingerprint, n.chain.vdf.Fingerprint()) {
+				return errors.New("peer uses different parameters")
+			}
+			n.mu.Lock()
+			n.peers[p] = struct{}{}
+			n.mu.Unlock()
+			registered = true
+		}
+		if err := n.handle(p, &m); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// handle 处理一条消息，返回 error 时断开连接
+func (n *Node) handle(p *peer, m *message) error {
+	switch m.Type {
+	case msgHello:
+		p.height = m.Height
+		return n.requestMissing(p)
+	case msgGetRounds:
+		rounds, err := n.chain.Rounds(m.From, m.Count)
+		if err != nil {
+			return err
+		}
+		for _, r := range rounds {
+			if err := p.send(&message{Type: msgRound, Round: r}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case msgRound:
+		if m.Round == nil {
+			return errors.New("round message without a round")
+		}
+		added, err := n.chain.Ingest(m.Round)
+		if err != nil {
+			return err
+		}
+		if added {
+			n.broadcast(m.Round, p)
+		}
+		p.height = max(p.height, m.Round.Index+1)
+		return n.requestMissing(p)
+	}
+	return fmt.Errorf("unknown message type %q", m.Type)
+}
+
+// requestMissing 在对方的轮数比本地多、并且上一批请求已经处理完时请求下一批
+func (n *Node) requestMissing(p *peer) error {
+	height, err := n.Height()
+	if err != nil || p.height <= height || height < p.requested {
+		return err
+	}
+	count := min(p.height-height, MaxBatch)
+	p.requested = height + count
+	return p.send(&message{Type: msgGetRounds, From: height, Count: int(count)})
+}
+
+// Announce 向所有相邻节点发布一轮，例如本地 beacon.Beacon.Publish 的结果
+// 如果该轮尚未在存储中 (节点与信标使用不同的存储)，先验证并保存
+func (n *Node) Announce(r *beacon.Round) error {
+	if _, err := n.chain.Ingest(r); err != nil {
+		return err
+	}
+	n.broadcast(r, nil)
+	return nil
+}
+
+// broadcast 把一轮发送给除 except 之外的所有节点，发送失败的连接会被关闭
+func (n *Node) broadcast(r *beacon.Round, except *peer) {
+	n.mu.Lock()
+	peers := make([]*peer, 0, len(n.peers))
+	for p := range n.peers {
+		if p != except {
+			peers = append(peers, p)
+		}
+	}
+	n.mu.Unlock()
+	m := &message{Type: msgRound, Round: r}
+	for _, p := range peers {
+		if err := p.send(m); err != nil {
+			p.conn.Close()
+		}
+	}
+}
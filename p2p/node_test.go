@@ -0,0 +1,153 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+func newTestVDF(t *testing.T) *slothgo.Sloth {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 200)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return vdf
+}
+
+// listen 让 n 在本地回环地址上接受连接，返回监听地址
+func listen(t *testing.T, ctx context.Context, n *Node) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go n.Listen(ctx, lis)
+	return lis.Addr().String()
+}
+
+// waitHeight 等待 n 同步到 height 轮
+func waitHeight(t *testing.T, n *Node, height uint64) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		h, _ := n.Height()
+		if h == height {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("node stuck at height %d, want %d", h, height)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestGossip 检查历史轮次的同步 (超过一批) 与新轮次沿 A -> B -> C 的转发
+func TestGossip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vdf := newTestVDF(t)
+
+	storeA := beacon.NewMemoryStore()
+	b := beacon.New(vdf, storeA)
+	a := NewNode(vdf, storeA)
+	for range MaxBatch + 6 {
+		if _, err := b.Publish(ctx); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	addrA := listen(t, ctx, a)
+
+	bNode := NewNode(vdf, beacon.NewMemoryStore())
+	var mu sync.Mutex
+	var received []uint64
+	bNode.Chain().OnRound = func(r *beacon.Round) {
+		mu.Lock()
+		received = append(received, r.Index)
+		mu.Unlock()
+	}
+	addrB := listen(t, ctx, bNode)
+	go bNode.Dial(ctx, addrA)
+	waitHeight(t, bNode, MaxBatch+6)
+
+	c := NewNode(vdf, beacon.NewMemoryStore())
+	go c.Dial(ctx, addrB)
+	waitHeight(t, c, MaxBatch+6)
+
+	// 新的一轮由 A 发布，经 B 转发到 C
+	b.Contribute([]byte("late entropy"))
+	r, err := b.Publish(ctx)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := a.Announce(r); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	waitHeight(t, c, MaxBatch+7)
+	got, _ := c.chain.store.Get(r.Index)
+	if string(got.Randomness) != string(r.Randomness) || string(got.Contributions[0]) != "late entropy" {
+		t.Error("gossiped round differs from the published round")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != MaxBatch+7 {
+		t.Fatalf("OnRound called %d times, want %d", len(received), MaxBatch+7)
+	}
+	for i, idx := range received {
+		if idx != uint64(i) {
+			t.Fatalf("OnRound received rounds out of order: %v", received)
+		}
+	}
+}
+
+// TestRejectInvalid 检查参数不同的节点与发送伪造轮次的节点会被断开
+func TestRejectInvalid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vdf := newTestVDF(t)
+	n := NewNode(vdf, beacon.NewMemoryStore())
+	addr := listen(t, ctx, n)
+
+	other := newTestVDF(t)
+	if err := NewNode(other, beacon.NewMemoryStore()).Dial(ctx, addr); err == nil {
+		t.Error("expected the connection to be closed for different parameters")
+	}
+
+	// 手工发送一个伪造的第 0 轮
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	enc.Encode(&message{Type: msgHello, Fingerprint: vdf.Fingerprint()})
+	storeX := beacon.NewMemoryStore()
+	r, _ := beacon.New(vdf, storeX).Publish(ctx)
+	forged := *r
+	forged.Randomness = []byte("forged")
+	enc.Encode(&message{Type: msgRound, Round: &forged})
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		// 忽略对方的 hello，直到连接被关闭
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("connection was not closed: %v", err)
+	}
+	if h, _ := n.Height(); h != 0 {
+		t.Errorf("forged round was stored")
+	}
+}
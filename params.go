@@ -0,0 +1,162 @@
+package slothgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/group"
+)
+
+// paramsVersion 是参数二进制格式的版本号
+const paramsVersion = 1
+
+// Params 的标志位
+const (
+	paramsFlagBind = 1 << iota // WithParamBinding
+	paramsFlagXMD              // WithHashToField
+)
+
+// Params 是一组可以序列化的 Sloth 参数，用于保存在配置中或在证明方与验证方之间传递
+type Params struct {
+	P           *big.Int
+	Iterations  uint64
+	Hash        HashID
+	Permutation string // 置换名称，只支持内置的 "sqrt"、"sqrt-lw15" 与 "cbrt"
+	DomainTag   []byte
+	BindParams  bool
+	HashToField bool
+}
+
+// builtinPermutations 是可以按名称从 Params 中恢复的置换
+var builtinPermutations = map[string]PermutationFactory{
+	"sqrt":      NewSqrtPermutation,
+	"sqrt-lw15": NewPaperSqrtPermutation,
+	"cbrt":      NewCubeRootPermutation,
+}
+
+// NewPermutation 按名称 (Permutation.Name() 的返回值) 在模 p 的默认算术后端上构造内置置换
+// 外部进程 (例如 external 包中的加速器 sidecar) 可以据此从参数中恢复置换
+func NewPermutation(name string, p *big.Int) (Permutation, error) {
+	newPerm, ok := builtinPermutations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown permutation %q", name)
+	}
+	f, err := group.NewField(p)
+	if err != nil {
+		return nil, err
+	}
+	return newPerm(f)
+}
+
+// Params 返回实例的参数
+func (s *Sloth) Params() *Params {
+	return &Params{
+		P:           new(big.Int).Set(s.P),
+		Iterations:  s.Iterations,
+		Hash:        s.hash,
+		Permutation: s.perm.Name(),
+		DomainTag:   bytes.Clone(s.tag),
+		BindParams:  s.bind,
+		HashToField: s.xmd,
+	}
+}
+
+// NewFromParams 使用 params 创建实例，opts 在 params 之后应用 (例如 WithProgress)
+func NewFromParams(params *Params, opts ...Option) (*Sloth, error) {
+	if params == nil || params.P == nil {
+		return nil, errors.New("params are incomplete")
+	}
+	newPerm, ok := builtinPermutations[params.Permutation]
+	if !ok {
+		return nil, fmt.Errorf("unknown permutation %q", params.Permutation)
+	}
+	base := []Option{WithHash(params.Hash), WithPermutation(newPerm), WithDomainTag(params.DomainTag)}
+	if params.BindParams {
+		base = append(base, WithParamBinding())
+	}
+	if params.HashToField {
+		base = append(base, WithHashToField())
+	}
+	return New(params.P, params.Iterations, append(base, opts...)...)
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+// 格式: 版本(1) | 标志(1) | 哈希(1) | 置换名长度(1) | 置换名 | 迭代次数(8) | 标签长度(2) | 标签 | p 长度(2) | p
+func (params *Params) MarshalBinary() ([]byte, error) {
+	if params.P == nil {
+		return nil, errors.New("params prime cannot be nil")
+	}
+	pBytes := params.P.Bytes()
+	if len(params.Permutation) > 255 || len(params.DomainTag) > maxDomainTagLen || len(pBytes) > 0xffff {
+		return nil, errors.New("params field too long")
+	}
+	var flags byte
+	if params.BindParams {
+		flags |= paramsFlagBind
+	}
+	if params.HashToField {
+		flags |= paramsFlagXMD
+	}
+	var buf bytes.Buffer
+	buf.Write([]byte{paramsVersion, flags, byte(params.Hash), byte(len(params.Permutation))})
+	buf.WriteString(params.Permutation)
+	binary.Write(&buf, binary.BigEndian, params.Iterations)
+	binary.Write(&buf, binary.BigEndian, uint16(len(params.DomainTag)))
+	buf.Write(params.DomainTag)
+	binary.Write(&buf, binary.BigEndian, uint16(len(pBytes)))
+	buf.Write(pBytes)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，未知的版本或标志位会被拒绝
+func (params *Params) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return errors.New("params data too short")
+	}
+	if head[0] != paramsVersion {
+		return fmt.Errorf("unsupported params version %d", head[0])
+	}
+	if head[1]&^(paramsFlagBind|paramsFlagXMD) != 0 {
+		return fmt.Errorf("unknown params flags %#x", head[1])
+	}
+	perm := make([]byte, head[3])
+	var iterations uint64
+	var tagLen, pLen uint16
+	if _, err := io.ReadFull(r, perm); err != nil {
+		return errors.New("params data too short")
+	}
+	if err := binary.Read(r, binary.BigEndian, &iterations); err != nil {
+		return errors.New("params data too short")
+	}
+	if err := binary.Read(r, binary.BigEndian, &tagLen); err != nil {
+		return errors.New("params data too short")
+	}
+	tag := make([]byte, tagLen)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return errors.New("params data too short")
+	}
+	if err := binary.Read(r, binary.BigEndian, &pLen); err != nil || r.Len() != int(pLen) {
+		return errors.New("params prime has the wrong length")
+	}
+	pBytes := make([]byte, pLen)
+	io.ReadFull(r, pBytes)
+
+	*params = Params{
+		P:           new(big.Int).SetBytes(pBytes),
+		Iterations:  iterations,
+		Hash:        HashID(head[2]),
+		Permutation: string(perm),
+		BindParams:  head[1]&paramsFlagBind != 0,
+		HashToField: head[1]&paramsFlagXMD != 0,
+	}
+	if tagLen > 0 {
+		params.DomainTag = tag
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package slothgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParams_RoundTrip(t *testing.T) {
+	s, err := New(testVDF.P, 300, WithHash(HashBLAKE3), WithDomainTag([]byte("app")), WithParamBinding(), WithHashToField())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	data, err := s.Params().MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	var params Params
+	if err := params.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	restored, err := NewFromParams(&params)
+	if err != nil {
+		t.Fatalf("NewFromParams failed: %v", err)
+	}
+	if !bytes.Equal(restored.Fingerprint(), s.Fingerprint()) {
+		t.Fatal("restored instance has different parameters")
+	}
+
+	proof, err := s.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if ok, err := restored.VerifyProof(testInput, proof); !ok {
+		t.Fatalf("VerifyProof with restored params failed: %v", err)
+	}
+
+	// 默认参数同样可以往返
+	data, _ = testVDF.Params().MarshalBinary()
+	if err := params.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if restored, err := NewFromParams(&params); err != nil || !bytes.Equal(restored.Fingerprint(), testVDF.Fingerprint()) {
+		t.Errorf("default params did not round-trip: %v", err)
+	}
+}
+
+func TestParams_UnmarshalStrict(t *testing.T) {
+	data, _ := testVDF.Params().MarshalBinary()
+	var params Params
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-1],
+		"trailing":  append(bytes.Clone(data), 0),
+		"version":   append([]byte{99}, data[1:]...),
+		"flags":     append([]byte{data[0], 0x80}, data[2:]...),
+	} {
+		if err := params.UnmarshalBinary(bad); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package slothgo
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/alan22333/sloth_go/group"
+)
+
+// Permutation 是 Sloth 每一轮使用的 F_p 上的置换 τ
+// Forward 应当慢 (顺序计算的瓶颈)，Inverse 应当快 (验证方使用)
+// 两个方法都原地修改参数，tmp 是调用方提供的可复用临时缓冲区
+type Permutation interface {
+	// Name 返回置换的唯一名称，它会被纳入参数指纹
+	Name() string
+	// Forward 原地计算 x = τ(x)
+	Forward(x, tmp *big.Int)
+	// Inverse 原地计算 y = τ⁻¹(y)
+	Inverse(y, tmp *big.Int)
+}
+
+// PermutationFactory 使用给定的算术后端构造置换，并检查域参数是否适用
+type PermutationFactory func(f group.Field) (Permutation, error)
+
+// sqrtPermutation 是论文中的 τ = ρ ∘ σ: 先做邻居交换，再开模平方根
+type sqrtPermutation struct {
+	f group.Field
+
+	// c 是固定的二次非剩余，cInv = c⁻¹; p ≡ 3 (mod 4) 时 c = -1，此时二者都为 nil
+	c, cInv *big.Int
+
+	// combined 在 p ≡ 3 (mod 4) 时非 nil，ρ 只需一次幂运算
+	// 后端未实现 group.CombinedSqrt 时使用基于 Field.Exp 的 expSqrt
+	combined group.CombinedSqrt
+}
+
+// expSqrt 用任意 Field 的 Exp 与 Square 实现 group.CombinedSqrt，要求 p ≡ 3 (mod 4):
+// r = a^((p+1)/4) 满足 r² = ±a，比较 r² 与 a 即可得到二次剩余性，无需再计算 Legendre 符号
+type expSqrt struct {
+	f   group.Field
+	exp *big.Int  // (p+1)/4
+	sq  sync.Pool // *big.Int，存放 r²
+}
+
+func newExpSqrt(f group.Field) *expSqrt {
+	exp := new(big.Int).Add(f.Modulus(), bigOne)
+	return &expSqrt{f: f, exp: exp.Rsh(exp, 2)}
+}
+
+func (es *expSqrt) SqrtOrNeg(z, a *big.Int) bool {
+	sq, ok := es.sq.Get().(*big.Int)
+	if !ok {
+		sq = new(big.Int)
+	}
+	defer es.sq.Put(sq)
+	es.f.Exp(z, a, es.exp)
+	return es.f.Square(sq, z).Cmp(a) == 0
+}
+
+// NewSqrtPermutation 构造默认的平方根置换，适用于任意奇素数 p
+// p ≡ 3 (mod 4) 时 -1 是二次非剩余，ρ 与论文完全一致，并使用快速的 (p+1)/4 指数开方;
+// p ≡ 1 (mod 4) 时改用最小的二次非剩余 c 代替 -1，开方使用 Tonelli–Shanks
+func NewSqrtPermutation(f group.Field) (Permutation, error) {
+	p := f.Modulus()
+	if p.Bit(0) == 0 {
+		return nil, fmt.Errorf("%w: p must be odd", ErrBadCongruence)
+	}
+	sp := &sqrtPermutation{f: f}
+	if p.Bit(1) == 1 { // p ≡ 3 (mod 4)
+		var ok bool
+		if sp.combined, ok = f.(group.CombinedSqrt); !ok {
+			sp.combined = newExpSqrt(f)
+		}
+	} else { // p ≡ 1 (mod 4)
+		sp.c = big.NewInt(2)
+		for f.Legendre(sp.c) != -1 {
+			sp.c.Add(sp.c, bigOne)
+		}
+		sp.cInv = new(big.Int).ModInverse(sp.c, p)
+	}
+	return sp, nil
+}
+
+func (sp *sqrtPermutation) Name() string {
+	return "sqrt"
+}
+
+func (sp *sqrtPermutation) Forward(x, tmp *big.Int) {
+	sigma(x)
+	sp.rho(x, tmp)
+}
+
+func (sp *sqrtPermutation) Inverse(y, tmp *big.Int) {
+	sp.rhoInverse(y, tmp)
+	sigma(y)
+}
+
+// sigma (σ) 原地实现 "邻居交换" 置换
+// 如果 x_hat 是偶数, σ(x) = x - 1
+// 如果 x_hat 是奇数, σ(x) = x + 1
+// p 为奇数，因此 0 是唯一没有邻居可交换的元素，令 σ(0) = 0 以保证 σ 是对合 (σ⁻¹ = σ)
+// x 在 [0, p-1] 内时结果仍在该范围内，不需要取模
+func sigma(x *big.Int) {
+	// big.Int.Bit(0) 返回最低有效位, 0 表示偶数, 1 表示奇数
+	if x.Sign() == 0 {
+		return
+	}
+	if x.Bit(0) == 0 { // 偶数
+		x.Sub(x, bigOne)
+	} else { // 奇数
+		x.Add(x, bigOne)
+	}
+}
+
+// rho (ρ) 原地计算模平方根，并用根的奇偶性记录 x 是否为二次剩余:
+// 如果 x 是二次剩余, 返回 x 的偶数提升值的根
+// 否则 (此时 c·x 是二次剩余, 默认 c = -1), 返回 c·x 的奇数提升值的根
+func (sp *sqrtPermutation) rho(x, tmp *big.Int) {
+	// 检查 x 是否是二次剩余 (0 也视为二次剩余)
+	var isResidue bool
+	if sp.combined != nil {
+		// 一次幂运算同时得到 x 或 -x 的根
+		isResidue = sp.combined.SqrtOrNeg(tmp, x)
+	} else if isResidue = sp.f.Sqrt(tmp, x); !isResidue {
+		// 如果不是，取 c·x 的根
+		sp.f.Sqrt(tmp, sp.mulC(x))
+	}
+	x.Set(tmp)
+
+	// 二次剩余选择偶数提升值的根，非二次剩余选择奇数提升值的根
+	if (x.Bit(0) == 0) != isResidue {
+		// 另一个根是 p - root，其奇偶性与 root 相反
+		sp.f.Neg(x, x)
+	}
+}
+
+// rhoInverse (ρ⁻¹) 原地计算 ρ 的逆运算
+// 如果 y_hat 是偶数, ρ⁻¹(y) = y²
+// 如果 y_hat 是奇数, ρ⁻¹(y) = c⁻¹·y² (默认 c = -1，即 -y²)
+func (sp *sqrtPermutation) rhoInverse(y, tmp *big.Int) {
+	odd := y.Bit(0) == 1
+	y.Set(sp.f.Square(tmp, y))
+	if odd {
+		if sp.cInv == nil {
+			sp.f.Neg(y, y)
+		} else {
+			sp.f.Mul(y, y, sp.cInv)
+		}
+	}
+}
+
+// mulC 原地计算 x = c·x
+func (sp *sqrtPermutation) mulC(x *big.Int) *big.Int {
+	if sp.c == nil {
+		return sp.f.Neg(x, x)
+	}
+	return sp.f.Mul(x, x, sp.c)
+}
+
+// paperSqrtPermutation 按 Lenstra–Wesolowski 论文 ("A random zoo: sloth, unicorn, and trx") 的约定实现 τ = ρ ∘ σ
+// ρ 与 sqrtPermutation 在 p ≡ 3 (mod 4) 时相同; σ 则把 x 与提升值只差最低位的邻居交换 (x̂ ⊕ 1)，
+// 即 (0,1), (2,3), ..., (p-3,p-2) 成对交换，没有邻居的 p-1 是不动点;
+// sqrtPermutation 的配对是 (1,2), (3,4), ..., (p-2,p-1)，不动点是 0，两者的输出因此不同
+type paperSqrtPermutation struct {
+	sqrtPermutation
+	top *big.Int // p - 1
+}
+
+// NewPaperSqrtPermutation 构造严格遵循论文约定的平方根置换，要求 p ≡ 3 (mod 4)
+// 需要与其他按论文实现的 Sloth 逐位一致时使用，见 WithPaperConformance
+func NewPaperSqrtPermutation(f group.Field) (Permutation, error) {
+	p := f.Modulus()
+	if p.Bit(0) == 0 || p.Bit(1) == 0 {
+		return nil, fmt.Errorf("%w: p must be congruent to 3 (mod 4)", ErrBadCongruence)
+	}
+	sp, err := NewSqrtPermutation(f)
+	if err != nil {
+		return nil, err
+	}
+	return &paperSqrtPermutation{
+		sqrtPermutation: *sp.(*sqrtPermutation),
+		top:             new(big.Int).Sub(p, bigOne),
+	}, nil
+}
+
+func (pp *paperSqrtPermutation) Name() string {
+	return "sqrt-lw15"
+}
+
+func (pp *paperSqrtPermutation) Forward(x, tmp *big.Int) {
+	pp.sigma(x)
+	pp.rho(x, tmp)
+}
+
+func (pp *paperSqrtPermutation) Inverse(y, tmp *big.Int) {
+	pp.rhoInverse(y, tmp)
+	pp.sigma(y)
+}
+
+// sigma 原地翻转 x 的最低位，p - 1 保持不变; 它同样是对合
+func (pp *paperSqrtPermutation) sigma(x *big.Int) {
+	if x.Cmp(pp.top) == 0 {
+		return
+	}
+	x.SetBit(x, 0, x.Bit(0)^1)
+}
+
+// cubeRootPermutation 是 τ = κ ∘ σ，其中 κ(x) = x^((2p-1)/3) 是模立方根
+// p ≡ 2 (mod 3) 时 x ↦ x³ 是 F_p 上的双射，因此立方根唯一，逆运算只需一次立方
+type cubeRootPermutation struct {
+	f       group.Field
+	cbrtExp *big.Int // (2p-1)/3
+}
+
+// NewCubeRootPermutation 构造立方根置换，要求 p ≡ 2 (mod 3)
+// 与平方根相比，每轮的计算/验证代价比更高 (一次完整幂运算对两次乘法)
+func NewCubeRootPermutation(f group.Field) (Permutation, error) {
+	p := f.Modulus()
+	if new(big.Int).Mod(p, bigThree).Cmp(bigTwo) != 0 {
+		return nil, fmt.Errorf("%w: p must be congruent to 2 (mod 3)", ErrBadCongruence)
+	}
+	e := new(big.Int).Lsh(p, 1)
+	e.Sub(e, bigOne)
+	e.Div(e, bigThree)
+	return &cubeRootPermutation{f: f, cbrtExp: e}, nil
+}
+
+func (cp *cubeRootPermutation) Name() string {
+	return "cbrt"
+}
+
+func (cp *cubeRootPermutation) Forward(x, tmp *big.Int) {
+	sigma(x)
+	x.Set(cp.f.Exp(tmp, x, cp.cbrtExp))
+}
+
+func (cp *cubeRootPermutation) Inverse(y, tmp *big.Int) {
+	cp.f.Square(tmp, y)
+	cp.f.Mul(y, tmp, y)
+	sigma(y)
+}
+
+// batchInverter 是可以同时对一组值各做 n 次 τ⁻¹ 的置换，供 reverse 与 VerifyBatchFast 使用
+// inverseBatch 返回 false 表示当前后端不支持批量路径，此时 ys 保持不变
+type batchInverter interface {
+	inverseBatch(ys []*big.Int, n uint64) bool
+}
+
+func (sp *sqrtPermutation) inverseBatch(ys []*big.Int, n uint64) bool {
+	return sp.inverseElems(ys, n, nil)
+}
+
+func (pp *paperSqrtPermutation) inverseBatch(ys []*big.Int, n uint64) bool {
+	return pp.inverseElems(ys, n, pp.top)
+}
+
+// inverseElems 在 Field256 的定长表示上对 ys 中的每个值做 n 次 ρ⁻¹ 与 σ，只支持 c = -1
+// top 为 nil 时使用 sigma，否则使用论文的 σ (p-1 = top 为不动点，其余翻转最低位);
+// 整个过程只在开始和结束时与 big.Int 转换一次，各个值的平方交错进行，
+// 不超过 batchLanes 个值时缓冲区都在栈上，不分配内存
+func (sp *sqrtPermutation) inverseElems(ys []*big.Int, n uint64, top *big.Int) bool {
+	f, ok := sp.f.(*group.Field256)
+	if !ok || sp.cInv != nil {
+		return false
+	}
+	var topElem group.Elem256
+	if top != nil {
+		topElem = f.Elem(top)
+	}
+	var xsBuf [batchLanes]group.Elem256
+	var oddBuf [batchLanes]bool
+	xs, odd := xsBuf[:], oddBuf[:]
+	if len(ys) > batchLanes {
+		xs, odd = make([]group.Elem256, len(ys)), make([]bool, len(ys))
+	}
+	xs = xs[:len(ys)]
+	for i, y := range ys {
+		xs[i] = f.Elem(y)
+	}
+	for range n {
+		for i := range xs {
+			odd[i] = xs[i][0]&1 == 1
+		}
+		f.SquareElems(xs)
+		for i := range xs {
+			if odd[i] {
+				f.NegElem(&xs[i])
+			}
+			if top == nil {
+				sigmaElem(&xs[i])
+			} else if xs[i] != topElem {
+				xs[i][0] ^= 1
+			}
+		}
+	}
+	for i, y := range ys {
+		f.SetElem(y, &xs[i])
+	}
+	return true
+}
+
+// sigmaElem 是 sigma 在定长表示上的版本: 0 不动，偶数减一，奇数加一
+// x 在 [0, p-1] 内且 p 为奇数，因此加一不会越过 p-1，也不会产生 limb 之外的进位
+func sigmaElem(x *group.Elem256) {
+	if x[0]&1 == 1 {
+		var c uint64
+		x[0], c = bits.Add64(x[0], 1, 0)
+		x[1], c = bits.Add64(x[1], 0, c)
+		x[2], c = bits.Add64(x[2], 0, c)
+		x[3] += c
+		return
+	}
+	if x[0]|x[1]|x[2]|x[3] == 0 {
+		return
+	}
+	var b uint64
+	x[0], b = bits.Sub64(x[0], 1, 0)
+	x[1], b = bits.Sub64(x[1], 0, b)
+	x[2], b = bits.Sub64(x[2], 0, b)
+	x[3] -= b
+}
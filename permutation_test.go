@@ -0,0 +1,181 @@
+package slothgo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/alan22333/sloth_go/group"
+)
+
+// shiftPermutation 是测试用的玩具置换 τ(x) = x + 1 mod p
+type shiftPermutation struct {
+	f group.Field
+}
+
+func (sp *shiftPermutation) Name() string            { return "shift" }
+func (sp *shiftPermutation) Forward(x, tmp *big.Int) { sp.f.Add(x, x, big.NewInt(1)) }
+func (sp *shiftPermutation) Inverse(y, tmp *big.Int) { sp.f.Sub(y, y, big.NewInt(1)) }
+
+// TestWithPermutation 检查自定义置换可以复用 Compute/Verify 流程，并且会改变参数指纹
+func TestWithPermutation(t *testing.T) {
+	vdf, err := New(testVDF.P, testIterations, WithPermutation(func(f group.Field) (Permutation, error) {
+		return &shiftPermutation{f: f}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	hash, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	want := new(big.Int).Add(vdf.initialValue(testInput), new(big.Int).SetUint64(testIterations))
+	if witness.Cmp(want.Mod(want, vdf.P)) != 0 {
+		t.Error("custom permutation was not used")
+	}
+	if ok, err := vdf.Verify(testInput, hash, witness); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+
+	if bytes.Equal(vdf.Fingerprint(), testVDF.Fingerprint()) {
+		t.Error("fingerprint does not depend on the permutation")
+	}
+}
+
+// TestCubeRootPermutation 检查 p ≡ 2 (mod 3) 时立方根变体的计算与验证
+func TestCubeRootPermutation(t *testing.T) {
+	var p *big.Int
+	for {
+		var err error
+		p, err = rand.Prime(rand.Reader, testPrimeBits)
+		if err != nil {
+			t.Fatalf("rand.Prime failed: %v", err)
+		}
+		if new(big.Int).Mod(p, big.NewInt(3)).Int64() == 2 {
+			break
+		}
+	}
+
+	vdf, err := New(p, testIterations, WithPermutation(NewCubeRootPermutation))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	hash, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if ok, err := vdf.Verify(testInput, hash, witness); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+
+	// p = 7 ≡ 1 (mod 3) 必须被拒绝
+	if _, err := New(big.NewInt(7), 10, WithPermutation(NewCubeRootPermutation)); err == nil {
+		t.Error("expected error for p not congruent to 2 (mod 3)")
+	}
+}
+
+// TestPaperConformance 在小素数上穷举检查论文约定的 τ 是双射且 Inverse 是其逆，并检查计算与验证
+func TestPaperConformance(t *testing.T) {
+	small, err := New(big.NewInt(23), 1, WithPaperConformance())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	seen := make(map[int64]bool)
+	for x := int64(0); x < 23; x++ {
+		y := small.Tau(big.NewInt(x))
+		if seen[y.Int64()] {
+			t.Fatalf("tau is not a permutation: %d repeated", y.Int64())
+		}
+		seen[y.Int64()] = true
+		if small.TauInverse(y).Int64() != x {
+			t.Fatalf("TauInverse(Tau(%d)) != %d", x, x)
+		}
+	}
+
+	vdf, err := New(testVDF.P, testIterations, WithPaperConformance())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	hash, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if ok, err := vdf.Verify(testInput, hash, witness); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+	if _, defaultWitness, _ := testVDF.Compute(testInput); defaultWitness.Cmp(witness) == 0 {
+		t.Error("paper conformance mode produced the default output")
+	}
+
+	// p = 13 ≡ 1 (mod 4) 必须被拒绝
+	if _, err := New(big.NewInt(13), 10, WithPaperConformance()); err == nil {
+		t.Error("expected error for p not congruent to 3 (mod 4)")
+	}
+}
+
+// TestFieldBackends 检查不同算术后端得到完全相同的输出
+func TestFieldBackends(t *testing.T) {
+	for _, p := range []*big.Int{testVDF.P, ParamSets()[0].Prime()} {
+		pf, _ := group.NewPrimeField(p)
+		mf, _ := group.NewMontgomeryField(p)
+		var want []byte
+		for _, f := range []group.Field{pf, mf} {
+			vdf, err := New(p, testIterations, WithField(f))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			hash, witness, err := vdf.Compute(testInput)
+			if err != nil {
+				t.Fatalf("Compute failed: %v", err)
+			}
+			if ok, err := vdf.Verify(testInput, hash, witness); !ok || err != nil {
+				t.Fatalf("%T: Verify failed: %v, %v", f, ok, err)
+			}
+			if want == nil {
+				want = hash
+			} else if !bytes.Equal(hash, want) {
+				t.Errorf("%T produced a different output", f)
+			}
+		}
+	}
+}
+
+// plainField 只暴露 group.Field 接口，并统计 Legendre 与 Sqrt 的调用次数
+type plainField struct {
+	group.Field
+	legendre, sqrt int
+}
+
+func (f *plainField) Legendre(a *big.Int) int {
+	f.legendre++
+	return f.Field.Legendre(a)
+}
+
+func (f *plainField) Sqrt(z, a *big.Int) bool {
+	f.sqrt++
+	return f.Field.Sqrt(z, a)
+}
+
+// TestFusedSqrt 检查不支持 group.CombinedSqrt 的后端在 p ≡ 3 (mod 4) 时也只用一次幂运算完成 ρ，
+// 结果与默认后端相同
+func TestFusedSqrt(t *testing.T) {
+	pf, _ := group.NewPrimeField(testVDF.P)
+	f := &plainField{Field: pf}
+	vdf, err := New(testVDF.P, testIterations, WithField(f))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	hash, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if f.legendre != 0 || f.sqrt != 0 {
+		t.Errorf("Compute called Legendre %d times and Sqrt %d times", f.legendre, f.sqrt)
+	}
+	wantHash, wantWitness, _ := testVDF.Compute(testInput)
+	if !bytes.Equal(hash, wantHash) || witness.Cmp(wantWitness) != 0 {
+		t.Error("fused square root changed the output")
+	}
+}
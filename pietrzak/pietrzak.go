@@ -0,0 +1,192 @@
+// Package pietrzak 实现 Pietrzak 在 "Simple verifiable delay functions" 中提出的 VDF:
+// 在未知阶的群中计算 y = x^(2^T)，并用递归折半协议 (经 Fiat–Shamir 变换) 生成 O(log T) 大小的证明
+// 群可以是 RSA 群 (需要可信模数，见 New) 或类群 (无需可信参数，见 NewClassGroup)
+package pietrzak
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/classgroup"
+	"github.com/alan22333/sloth_go/group"
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+// challengeBytes 是每一轮 Fiat–Shamir 挑战 r 的字节数
+const challengeBytes = 16
+
+// ctxCheckInterval 平方循环中每隔多少次检查一次 ctx 是否已取消
+const ctxCheckInterval = 1024
+
+// VDF 持有 Pietrzak VDF 的参数，E 是群元素的类型
+type VDF[E any] struct {
+	G group.Group[E] // 未知阶的群
+	T int64          // 连续平方的次数 (延迟参数)
+}
+
+var (
+	_ slothgo.VDF = (*VDF[*big.Int])(nil)
+	_ slothgo.VDF = (*VDF[*classgroup.Form])(nil)
+)
+
+// New 在模数为 n 的 RSA 群中创建 Pietrzak VDF 实例
+func New(n *big.Int, t int64) (*VDF[*big.Int], error) {
+	g, err := group.NewRSA(n)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithGroup[*big.Int](g, t)
+}
+
+// NewClassGroup 在判别式为 d 的类群中创建 Pietrzak VDF 实例
+func NewClassGroup(d *big.Int, t int64) (*VDF[*classgroup.Form], error) {
+	g, err := classgroup.New(d)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithGroup[*classgroup.Form](g, t)
+}
+
+// NewWithGroup 在任意未知阶的群 g 中创建 Pietrzak VDF 实例
+func NewWithGroup[E any](g group.Group[E], t int64) (*VDF[E], error) {
+	if t <= 0 {
+		return nil, errors.New("t must be positive")
+	}
+	if g == nil {
+		return nil, errors.New("group cannot be nil")
+	}
+	return &VDF[E]{G: g, T: t}, nil
+}
+
+// Prove 计算 y = x^(2^T)，其中 x = H(input)，并返回折半协议每一轮的中间值 μ
+// 每一轮把断言 x^(2^T) = y 归约为 x'^(2^(T/2)) = y'，其中
+// μ = x^(2^(T/2)), r = H(x, y, μ, T), x' = x^r·μ, y' = μ^r·y
+// T 为奇数时双方先把断言改写为 x^(2^(T+1)) = y²
+func (v *VDF[E]) Prove(ctx context.Context, input []byte) (y E, mus []E, err error) {
+	x := v.G.HashToElement("pietrzak/hash-to-group", input)
+	y, err = v.square(ctx, x, v.T)
+	if err != nil {
+		return y, nil, err
+	}
+
+	xi, yi, t := x, y, v.T
+	for t > 1 {
+		if t%2 == 1 {
+			yi = v.G.Square(yi)
+			t++
+		}
+		t /= 2
+		mu, err := v.square(ctx, xi, t)
+		if err != nil {
+			return y, nil, err
+		}
+		mus = append(mus, mu)
+		xi, yi = v.fold(xi, yi, mu, t)
+	}
+	return y, mus, nil
+}
+
+// Verify 按与 Prove 相同的方式逐轮折半，最后检查 y' = x'²
+func (v *VDF[E]) Verify(input []byte, y E, mus []E) (bool, error) {
+	if err := v.G.Validate(y); err != nil {
+		return false, err
+	}
+	if len(mus) != rounds(v.T) {
+		return false, fmt.Errorf("proof has %d rounds, expected %d", len(mus), rounds(v.T))
+	}
+
+	xi, yi, t := v.G.HashToElement("pietrzak/hash-to-group", input), y, v.T
+	for _, mu := range mus {
+		if err := v.G.Validate(mu); err != nil {
+			return false, err
+		}
+		if t%2 == 1 {
+			yi = v.G.Square(yi)
+			t++
+		}
+		t /= 2
+		xi, yi = v.fold(xi, yi, mu, t)
+	}
+
+	if !v.G.Equal(v.G.Square(xi), yi) {
+		return false, errors.New("verification failed: final round does not hold")
+	}
+	return true, nil
+}
+
+// Evaluate 实现 slothgo.VDF 接口: output 为 y, proof 为依次拼接的 μ, 均使用群的定长编码
+func (v *VDF[E]) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	y, mus, err := v.Prove(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof = make([]byte, 0, v.G.ElementSize()*len(mus))
+	for _, mu := range mus {
+		proof = append(proof, v.G.Encode(mu)...)
+	}
+	return v.G.Encode(y), proof, nil
+}
+
+// VerifyEvaluation 实现 slothgo.VDF 接口
+func (v *VDF[E]) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	y, err := v.G.Decode(output)
+	if err != nil {
+		return false, err
+	}
+	size := v.G.ElementSize()
+	if len(proof)%size != 0 {
+		return false, errors.New("proof length is not a multiple of the element size")
+	}
+	mus := make([]E, 0, len(proof)/size)
+	for i := 0; i < len(proof); i += size {
+		mu, err := v.G.Decode(proof[i : i+size])
+		if err != nil {
+			return false, err
+		}
+		mus = append(mus, mu)
+	}
+	return v.Verify(input, y, mus)
+}
+
+// square 计算 x^(2^t)
+func (v *VDF[E]) square(ctx context.Context, x E, t int64) (E, error) {
+	y := x
+	for i := int64(0); i < t; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return y, err
+			}
+		}
+		y = v.G.Square(y)
+	}
+	return y, nil
+}
+
+// fold 执行一轮折半: 返回 x' = x^r·μ, y' = μ^r·y, 其中 t 为折半后的指数
+func (v *VDF[E]) fold(x, y, mu E, t int64) (E, E) {
+	var data []byte
+	for _, e := range []E{x, y, mu} {
+		data = append(data, v.G.Encode(e)...)
+	}
+	data = binary.BigEndian.AppendUint64(data, uint64(t))
+	r := new(big.Int).SetBytes(hashutil.Expand("pietrzak/challenge", data, challengeBytes))
+
+	return v.G.Mul(v.G.Exp(x, r), mu), v.G.Mul(v.G.Exp(mu, r), y)
+}
+
+// rounds 返回 T 对应的折半轮数
+func rounds(t int64) int {
+	n := 0
+	for t > 1 {
+		t = (t + 1) / 2
+		n++
+	}
+	return n
+}
@@ -0,0 +1,73 @@
+package pietrzak
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/alan22333/sloth_go/classgroup"
+
+	"github.com/alan22333/sloth_go/group"
+)
+
+// TestProveAndVerify 检查不同 T (包括奇数) 下的证明可以验证通过，且篡改会被拒绝
+func TestProveAndVerify(t *testing.T) {
+	n, err := group.GenerateModulus(512)
+	if err != nil {
+		t.Fatalf("GenerateModulus failed: %v", err)
+	}
+	input := []byte("A random zoo: sloth, unicorn, and trx")
+
+	for _, T := range []int64{1, 2, 7, 1000, 1025} {
+		v, err := New(n, T)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		y, mus, err := v.Prove(context.Background(), input)
+		if err != nil {
+			t.Fatalf("T=%d: Prove failed: %v", T, err)
+		}
+		if ok, err := v.Verify(input, y, mus); !ok || err != nil {
+			t.Fatalf("T=%d: Verify failed: %v, %v", T, ok, err)
+		}
+		if ok, _ := v.Verify(input, new(big.Int).Add(y, big.NewInt(1)), mus); ok {
+			t.Errorf("T=%d: expected tampered output to be rejected", T)
+		}
+		if len(mus) > 0 {
+			forged := append([]*big.Int(nil), mus...)
+			forged[0] = new(big.Int).Add(forged[0], big.NewInt(1))
+			if ok, _ := v.Verify(input, y, forged); ok {
+				t.Errorf("T=%d: expected tampered proof to be rejected", T)
+			}
+		}
+
+		output, proof, err := v.Evaluate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("T=%d: Evaluate failed: %v", T, err)
+		}
+		if ok, err := v.VerifyEvaluation(context.Background(), input, output, proof); !ok || err != nil {
+			t.Fatalf("T=%d: VerifyEvaluation failed: %v, %v", T, ok, err)
+		}
+	}
+}
+
+// TestClassGroup 检查类群后端 (无需可信参数) 的证明可以验证通过
+func TestClassGroup(t *testing.T) {
+	d := classgroup.DeriveDiscriminant([]byte("pietrzak test"), 256)
+	v, err := NewClassGroup(d, 200)
+	if err != nil {
+		t.Fatalf("NewClassGroup failed: %v", err)
+	}
+	input := []byte("A random zoo: sloth, unicorn, and trx")
+
+	output, proof, err := v.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), input, output, proof); !ok || err != nil {
+		t.Fatalf("VerifyEvaluation failed: %v, %v", ok, err)
+	}
+	if ok, _ := v.VerifyEvaluation(context.Background(), []byte("wrong input"), output, proof); ok {
+		t.Error("expected wrong input to be rejected")
+	}
+}
@@ -0,0 +1,229 @@
+// Package posw 实现 Cohen 与 Pietrzak 在 "Simple Proofs of Sequential Work" 中提出的顺序工作证明
+// 证明方在深度为 n 的完全二叉树构成的 DAG 上依次计算 2^(n+1)-1 个标签:
+//   - 内部节点的父节点是它的两个子节点
+//   - 叶子节点的父节点是从根到它的路径上所有节点的左兄弟
+//
+// 根标签 φ 作为承诺，验证方通过 Fiat–Shamir 随机挑战若干叶子并检查其路径。
+// 与 VDF 不同，PoSW 的输出不唯一，只保证证明方确实完成了顺序的工作
+package posw
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+// MaxDepth 是支持的最大树深度，证明方需要保存全部 2^(n+1)-1 个标签
+const MaxDepth = 30
+
+// ctxCheckInterval 每计算多少个叶子检查一次 ctx 是否已取消
+const ctxCheckInterval = 1024
+
+// PoSW 持有顺序工作证明的参数
+type PoSW struct {
+	Depth      int // 树的深度 n，顺序工作量约为 2^(n+1) 次哈希
+	Challenges int // 挑战的叶子数量 t，作弊成功的概率随 t 指数下降
+}
+
+// Opening 是对一个被挑战叶子的打开: 路径上每个节点的兄弟标签 (自下而上)
+type Opening struct {
+	Leaf     uint64
+	Siblings [][]byte
+}
+
+// Proof 是顺序工作证明
+type Proof struct {
+	Root     []byte // 根标签 φ
+	Openings []Opening
+}
+
+// New 创建一个新的 PoSW 实例
+func New(depth, challenges int) (*PoSW, error) {
+	if depth <= 0 || depth > MaxDepth {
+		return nil, fmt.Errorf("depth must be in the range [1, %d]", MaxDepth)
+	}
+	if challenges <= 0 {
+		return nil, errors.New("challenges must be positive")
+	}
+	return &PoSW{Depth: depth, Challenges: challenges}, nil
+}
+
+// Prove 对陈述 chi 计算全部标签并生成证明
+func (p *PoSW) Prove(ctx context.Context, chi []byte) (*Proof, error) {
+	// labels[d][i] 是深度 d、序号 i 的节点标签
+	labels := make([][][]byte, p.Depth+1)
+	for d := range labels {
+		labels[d] = make([][]byte, 1<<d)
+	}
+	var lefts [][]byte
+	var leaves uint64
+	var compute func(d int, i uint64) ([]byte, error)
+	compute = func(d int, i uint64) ([]byte, error) {
+		if d == p.Depth {
+			if leaves%ctxCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			leaves++
+			labels[d][i] = label(chi, d, i, lefts...)
+			return labels[d][i], nil
+		}
+		left, err := compute(d+1, 2*i)
+		if err != nil {
+			return nil, err
+		}
+		lefts = append(lefts, left)
+		right, err := compute(d+1, 2*i+1)
+		lefts = lefts[:len(lefts)-1]
+		if err != nil {
+			return nil, err
+		}
+		labels[d][i] = label(chi, d, i, left, right)
+		return labels[d][i], nil
+	}
+	root, err := compute(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{Root: root}
+	for _, leaf := range p.challenges(chi, root) {
+		opening := Opening{Leaf: leaf}
+		i := leaf
+		for d := p.Depth; d > 0; d-- {
+			opening.Siblings = append(opening.Siblings, labels[d][i^1])
+			i >>= 1
+		}
+		proof.Openings = append(proof.Openings, opening)
+	}
+	return proof, nil
+}
+
+// Verify 检查每个被挑战叶子的标签与路径都与根标签一致
+func (p *PoSW) Verify(chi []byte, proof *Proof) error {
+	if proof == nil || len(proof.Root) != sha256.Size {
+		return errors.New("proof is incomplete")
+	}
+	challenges := p.challenges(chi, proof.Root)
+	if len(proof.Openings) != len(challenges) {
+		return fmt.Errorf("proof has %d openings, expected %d", len(proof.Openings), len(challenges))
+	}
+	for k, opening := range proof.Openings {
+		if opening.Leaf != challenges[k] {
+			return fmt.Errorf("opening %d is for leaf %d, expected %d", k, opening.Leaf, challenges[k])
+		}
+		if len(opening.Siblings) != p.Depth {
+			return fmt.Errorf("opening %d has %d siblings, expected %d", k, len(opening.Siblings), p.Depth)
+		}
+
+		// 叶子的父节点是路径上右孩子节点的左兄弟，按自上而下的顺序排列
+		var lefts [][]byte
+		for d := 1; d <= p.Depth; d++ {
+			if (opening.Leaf>>(p.Depth-d))&1 == 1 {
+				lefts = append(lefts, opening.Siblings[p.Depth-d])
+			}
+		}
+		cur := label(chi, p.Depth, opening.Leaf, lefts...)
+
+		// 沿路径向上重新计算到根
+		i := opening.Leaf
+		for d := p.Depth; d > 0; d-- {
+			sibling := opening.Siblings[p.Depth-d]
+			if i&1 == 0 {
+				cur = label(chi, d-1, i>>1, cur, sibling)
+			} else {
+				cur = label(chi, d-1, i>>1, sibling, cur)
+			}
+			i >>= 1
+		}
+		if !bytes.Equal(cur, proof.Root) {
+			return fmt.Errorf("opening %d does not match the root label", k)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+// 格式: 根标签(32) | 打开数量(4) | 每个打开: 叶子序号(8) | 兄弟数量(1) | 兄弟标签(各 32)
+func (proof *Proof) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(append([]byte(nil), proof.Root...))
+	binary.Write(buf, binary.BigEndian, uint32(len(proof.Openings)))
+	for _, o := range proof.Openings {
+		binary.Write(buf, binary.BigEndian, o.Leaf)
+		buf.WriteByte(byte(len(o.Siblings)))
+		for _, s := range o.Siblings {
+			if len(s) != sha256.Size {
+				return nil, errors.New("sibling label has the wrong length")
+			}
+			buf.Write(s)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (proof *Proof) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	root := make([]byte, sha256.Size)
+	var n uint32
+	if _, err := r.Read(root); err != nil {
+		return errors.New("proof data too short")
+	}
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return errors.New("proof data too short")
+	}
+	openings := make([]Opening, 0, min(n, 1024))
+	for range n {
+		var o Opening
+		if err := binary.Read(r, binary.BigEndian, &o.Leaf); err != nil {
+			return errors.New("proof data too short")
+		}
+		count, err := r.ReadByte()
+		if err != nil {
+			return errors.New("proof data too short")
+		}
+		for range count {
+			s := make([]byte, sha256.Size)
+			if n, _ := r.Read(s); n != sha256.Size {
+				return errors.New("proof data too short")
+			}
+			o.Siblings = append(o.Siblings, s)
+		}
+		openings = append(openings, o)
+	}
+	if r.Len() != 0 {
+		return errors.New("trailing bytes after proof")
+	}
+	proof.Root, proof.Openings = root, openings
+	return nil
+}
+
+// challenges 由 (chi, root) 通过 Fiat–Shamir 派生被挑战的叶子序号
+func (p *PoSW) challenges(chi, root []byte) []uint64 {
+	data := append(append([]byte(nil), root...), chi...)
+	buf := hashutil.Expand("posw/challenges", data, 8*p.Challenges)
+	leaves := make([]uint64, p.Challenges)
+	for k := range leaves {
+		leaves[k] = binary.BigEndian.Uint64(buf[8*k:]) & (1<<p.Depth - 1)
+	}
+	return leaves
+}
+
+// label 计算节点 (d, i) 的标签 H(chi || d || i || 父节点标签...)
+func label(chi []byte, d int, i uint64, parents ...[]byte) []byte {
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, uint32(len(chi)))
+	h.Write(chi)
+	binary.Write(h, binary.BigEndian, uint8(d))
+	binary.Write(h, binary.BigEndian, i)
+	for _, parent := range parents {
+		h.Write(parent)
+	}
+	return h.Sum(nil)
+}
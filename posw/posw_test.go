@@ -0,0 +1,43 @@
+package posw
+
+import (
+	"context"
+	"testing"
+)
+
+// TestProveAndVerify 检查证明可以验证通过、序列化往返，以及篡改会被拒绝
+func TestProveAndVerify(t *testing.T) {
+	p, err := New(10, 16)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	chi := []byte("A random zoo: sloth, unicorn, and trx")
+
+	proof, err := p.Prove(context.Background(), chi)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	if err := p.Verify(chi, proof); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := p.Verify([]byte("other statement"), proof); err == nil {
+		t.Error("expected proof for a different statement to be rejected")
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	var decoded Proof
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if err := p.Verify(chi, &decoded); err != nil {
+		t.Fatalf("Verify of decoded proof failed: %v", err)
+	}
+
+	decoded.Openings[3].Siblings[5][0] ^= 1
+	if err := p.Verify(chi, &decoded); err == nil {
+		t.Error("expected tampered sibling label to be rejected")
+	}
+}
@@ -0,0 +1,132 @@
+package slothgo
+
+import (
+	"crypto/sha3"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// PrimalityCheck 是 New 对模数 p 执行的素性检验，返回非 nil 错误表示拒绝 p
+// 内置的检验有 MillerRabin (默认 20 轮)、BPSW、CertificateCheck 与 SkipPrimalityCheck，见 WithPrimalityCheck
+type PrimalityCheck func(p *big.Int) error
+
+// MillerRabin 返回执行 rounds 轮随机底数 Miller–Rabin 的检验
+// 它基于 big.Int.ProbablyPrime，因此在 rounds 轮之外总会再做一次 Baillie–PSW
+func MillerRabin(rounds int) PrimalityCheck {
+	return func(p *big.Int) error {
+		if !p.ProbablyPrime(rounds) {
+			return ErrNotPrime
+		}
+		return nil
+	}
+}
+
+// BPSW 只执行 Baillie–PSW 检验，目前没有已知的反例，比默认的 20 轮 Miller–Rabin 快得多
+func BPSW(p *big.Int) error {
+	return MillerRabin(0)(p)
+}
+
+// SkipPrimalityCheck 跳过素性检验，只应当用于来源可信的参数 (例如 ParamSets 中的标准参数)
+// p 不是素数时实例的行为没有任何保证
+func SkipPrimalityCheck(p *big.Int) error {
+	return nil
+}
+
+// CertificateCheck 返回用 Pocklington 证书确定性地检验 p 的检验，见 VerifyPrimeCertificate
+func CertificateCheck(cert *PrimeCertificate) PrimalityCheck {
+	return func(p *big.Int) error {
+		if err := VerifyPrimeCertificate(p, cert); err != nil {
+			return fmt.Errorf("%w: %w", ErrNotPrime, err)
+		}
+		return nil
+	}
+}
+
+// derivePrimeDomain 是 DerivePrime 使用的 cSHAKE256 定制串
+const derivePrimeDomain = "slothgo/prime/v1"
+
+// DerivePrime 由公开的 seed 确定性地派生一个 bits 位、满足 p ≡ 3 (mod 4) 的素数
+// 以 cSHAKE256(N = "", S = "slothgo/prime/v1") 吸收 seed 得到一个字节流，依次从中读取 ⌈bits/8⌉ 字节
+// 作为大端整数，右移掉多余的低位后把最高位与最低两位置 1，返回第一个通过素性检验的候选值。
+// 任何人都可以由同一个 seed 复现该搜索，从而审计模数，不必信任生成方的随机数
+func DerivePrime(seed []byte, bits int) (*big.Int, error) {
+	if bits < 3 {
+		return nil, errors.New("prime size must be at least 3 bits")
+	}
+	xof := sha3.NewCSHAKE256(nil, []byte(derivePrimeDomain))
+	xof.Write(seed)
+	buf := make([]byte, (bits+7)/8)
+	p := new(big.Int)
+	for {
+		xof.Read(buf)
+		p.SetBytes(buf)
+		p.Rsh(p, uint(len(buf)*8-bits))
+		p.SetBit(p, bits-1, 1)
+		p.SetBit(p, 1, 1)
+		p.SetBit(p, 0, 1)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// deriveSafePrimeDomain 是 DeriveSafePrime 使用的 cSHAKE256 定制串
+const deriveSafePrimeDomain = "slothgo/safe-prime/v1"
+
+// safePrimeSieve 是搜索安全素数时用于试除 q 与 2q+1 的小素数，safeSieveModulus 是它们的乘积 (小于 2^64)
+var (
+	safePrimeSieve   = []uint64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47}
+	safeSieveModulus = new(big.Int).SetUint64(3 * 5 * 7 * 11 * 13 * 17 * 19 * 23 * 29 * 31 * 37 * 41 * 43 * 47)
+)
+
+// DeriveSafePrime 与 DerivePrime 相同，但派生安全素数 p = 2q + 1 (q 也是素数)，此时 p - 1 没有小于 q 的奇素因子。
+// 以 cSHAKE256(N = "", S = "slothgo/safe-prime/v1") 吸收 seed，依次读取 bits-1 位的奇数候选值 q
+// (最高位置 1)，返回第一个 q 与 2q + 1 都通过素性检验的 p。q 为奇数，因此 p ≡ 3 (mod 4)。
+// 安全素数稀疏得多，2048 位时搜索可能需要数分钟
+func DeriveSafePrime(seed []byte, bits int) (*big.Int, error) {
+	if bits < 4 {
+		return nil, errors.New("safe prime size must be at least 4 bits")
+	}
+	xof := sha3.NewCSHAKE256(nil, []byte(deriveSafePrimeDomain))
+	xof.Write(seed)
+	qBits := bits - 1
+	buf := make([]byte, (qBits+7)/8)
+	q, p, tmp := new(big.Int), new(big.Int), new(big.Int)
+	for {
+		xof.Read(buf)
+		q.SetBytes(buf)
+		q.Rsh(q, uint(len(buf)*8-qBits))
+		q.SetBit(q, qBits-1, 1)
+		q.SetBit(q, 0, 1)
+		if !passesSafeSieve(q, tmp) {
+			continue
+		}
+		p.Lsh(q, 1).SetBit(p, 0, 1)
+		if q.ProbablyPrime(20) && p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// passesSafeSieve 检查 q 与 2q+1 都不被小素数整除，q 不超过 8 位时不做检查以免排除小素数本身
+func passesSafeSieve(q, tmp *big.Int) bool {
+	if q.BitLen() <= 8 {
+		return true
+	}
+	m := tmp.Mod(q, safeSieveModulus).Uint64()
+	for _, r := range safePrimeSieve {
+		if x := m % r; x == 0 || (2*x+1)%r == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSafePrime 报告 p 是否 (以压倒性的概率) 是安全素数，即 p 与 (p-1)/2 都是素数
+func IsSafePrime(p *big.Int) bool {
+	if p.Sign() <= 0 || p.Bit(0) == 0 || !p.ProbablyPrime(20) {
+		return false
+	}
+	return new(big.Int).Rsh(p, 1).ProbablyPrime(20)
+}
@@ -0,0 +1,102 @@
+package slothgo
+
+import (
+	"math/big"
+	"testing"
+)
+
+// derivePrimeVector 是 DerivePrime("abc", 64) 的结果
+const derivePrimeVector = "e985185c8f446b6f"
+
+func TestDerivePrime(t *testing.T) {
+	seed := []byte("slothgo community ceremony")
+	p, err := DerivePrime(seed, testPrimeBits)
+	if err != nil {
+		t.Fatalf("DerivePrime failed: %v", err)
+	}
+	if p.BitLen() != testPrimeBits || p.Bit(1) != 1 || p.Bit(0) != 1 || !p.ProbablyPrime(20) {
+		t.Fatalf("derived value %x is not a %d-bit prime congruent to 3 (mod 4)", p, testPrimeBits)
+	}
+
+	// 同一个种子必须得到同一个素数，不同的种子得到不同的素数
+	again, _ := DerivePrime(seed, testPrimeBits)
+	if again.Cmp(p) != 0 {
+		t.Error("DerivePrime is not deterministic")
+	}
+	other, _ := DerivePrime([]byte("another seed"), testPrimeBits)
+	if other.Cmp(p) == 0 {
+		t.Error("different seeds derived the same prime")
+	}
+
+	// 固定向量，防止派生过程被无意中修改
+	small, _ := DerivePrime([]byte("abc"), 64)
+	want, _ := new(big.Int).SetString(derivePrimeVector, 16)
+	if small.Cmp(want) != 0 {
+		t.Errorf("DerivePrime(abc, 64) = %x, want %x", small, want)
+	}
+
+	if _, err := DerivePrime(seed, 2); err == nil {
+		t.Error("expected error for tiny prime size")
+	}
+}
+
+func TestWithPrimalityCheck(t *testing.T) {
+	composite := new(big.Int).Mul(testVDF.P, big.NewInt(7))
+	if _, err := New(composite, 10); err == nil {
+		t.Error("default check accepted a composite modulus")
+	}
+	if _, err := New(composite, 10, WithPrimalityCheck(BPSW)); err == nil {
+		t.Error("BPSW accepted a composite modulus")
+	}
+	if _, err := New(testVDF.P, 10, WithPrimalityCheck(BPSW)); err != nil {
+		t.Errorf("BPSW rejected a prime: %v", err)
+	}
+	if _, err := New(testVDF.P, 10, WithPrimalityCheck(SkipPrimalityCheck)); err != nil {
+		t.Errorf("skipped check failed: %v", err)
+	}
+	// 9 是完全平方数，必须在构造算术后端 (搜索二次非剩余) 之前被拒绝
+	if _, err := New(big.NewInt(9), 10, WithPrimalityCheck(MillerRabin(5))); err == nil {
+		t.Error("Miller–Rabin accepted 9")
+	}
+
+	p, cert, err := GenerateCertifiedPrime(testPrimeBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(p, 10, WithPrimalityCheck(CertificateCheck(cert))); err != nil {
+		t.Errorf("certificate check rejected a certified prime: %v", err)
+	}
+	if _, err := New(testVDF.P, 10, WithPrimalityCheck(CertificateCheck(cert))); err == nil {
+		t.Error("certificate check accepted a prime it does not certify")
+	}
+
+	if _, err := New(testVDF.P, 10, WithPrimalityCheck(nil)); err == nil {
+		t.Error("expected error for nil primality check")
+	}
+}
+
+func TestDeriveSafePrime(t *testing.T) {
+	seed := []byte("slothgo community ceremony")
+	p, err := DeriveSafePrime(seed, testPrimeBits)
+	if err != nil {
+		t.Fatalf("DeriveSafePrime failed: %v", err)
+	}
+	if p.BitLen() != testPrimeBits || p.Bit(1) != 1 || !IsSafePrime(p) {
+		t.Fatalf("derived value %x is not a %d-bit safe prime congruent to 3 (mod 4)", p, testPrimeBits)
+	}
+	if again, _ := DeriveSafePrime(seed, testPrimeBits); again.Cmp(p) != 0 {
+		t.Error("DeriveSafePrime is not deterministic")
+	}
+	// 很小的位数也能找到: 4 位的安全素数只有 11
+	if small, err := DeriveSafePrime(seed, 4); err != nil || small.Int64() != 11 {
+		t.Errorf("DeriveSafePrime(4) = %v, %v", small, err)
+	}
+	if _, err := DeriveSafePrime(seed, 3); err == nil {
+		t.Error("expected error for tiny prime size")
+	}
+
+	// 23 = 2·11 + 1 是安全素数，13 = 2·6 + 1 不是
+	if !IsSafePrime(big.NewInt(23)) || IsSafePrime(big.NewInt(13)) {
+		t.Error("IsSafePrime misclassified a small prime")
+	}
+}
@@ -0,0 +1,140 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha3"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// outputDomain 是 ExpandOutput 中 cSHAKE256 的定制字符串
+const outputDomain = "slothgo/output/v1"
+
+// Proof 打包一次 VDF 计算的全部结果，便于传递、存储和验证
+type Proof struct {
+	Hash        []byte   // 最终输出的哈希值 (论文中的 g)
+	Witness     *big.Int // 用于验证的最终值 (论文中的 w)
+	Iterations  uint64   // 计算时使用的迭代次数
+	Fingerprint []byte   // 计算时使用的参数指纹, 见 Sloth.Fingerprint
+
+	// 可选的区段检查点: Checkpoints[i] 是第 (i+1)*CheckpointInterval 次迭代后的值
+	// 有检查点时验证方可以并行检查各个区段, 见 WithSegmentCheckpoints
+	CheckpointInterval uint64
+	Checkpoints        []*big.Int
+
+	// 可选的状态承诺: 对每隔 StateStride 次迭代采样的中间值构建的 Merkle 树的根
+	// 见 Sloth.ComputeStateTree
+	StateStride uint64
+	StateRoot   []byte
+
+	size int // witness 与检查点的编码长度，见 Checkpoint
+}
+
+// ComputeProof 执行 VDF 计算并以 Proof 的形式返回结果
+func (s *Sloth) ComputeProof(input []byte) (*Proof, error) {
+	return s.ComputeProofCtx(context.Background(), input)
+}
+
+// ComputeProofCtx 与 ComputeProof 相同，但支持通过 ctx 取消
+func (s *Sloth) ComputeProofCtx(ctx context.Context, input []byte) (*Proof, error) {
+	if s.segmentInterval > 0 {
+		done := s.track(metrics.Compute)
+		proof, err := s.computeSegmented(ctx, input)
+		done(computeOutcome(err))
+		return proof, err
+	}
+	hash, witness, err := s.ComputeCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.NewProof(hash, witness), nil
+}
+
+// VerifyProof 验证 proof 是否是 input 在当前参数下的正确输出
+func (s *Sloth) VerifyProof(input []byte, proof *Proof) (bool, error) {
+	return s.VerifyProofCtx(context.Background(), input, proof)
+}
+
+// VerifyProofCtx 与 VerifyProof 相同，但支持通过 ctx 取消
+func (s *Sloth) VerifyProofCtx(ctx context.Context, input []byte, proof *Proof) (ok bool, err error) {
+	hit, remember := s.cachedVerify(input, proof)
+	if hit {
+		return true, nil
+	}
+	done := s.track(metrics.Verify)
+	defer func() { done(verifyOutcome(ok, err)) }()
+	if ok, err = s.verifyProof(ctx, input, proof); ok {
+		remember()
+	}
+	return ok, err
+}
+
+// verifyProof 是 VerifyProofCtx 的实现，不向监控收集器报告
+func (s *Sloth) verifyProof(ctx context.Context, input []byte, proof *Proof) (bool, error) {
+	if err := s.checkProofParams(proof); err != nil {
+		return false, err
+	}
+	if proof.StateRoot != nil {
+		if input == nil {
+			return false, errors.New("input cannot be nil")
+		}
+		return s.verifyStateCommitted(ctx, s.initialValue(input), proof)
+	}
+	if len(proof.Checkpoints) > 0 {
+		if input == nil {
+			return false, errors.New("input cannot be nil")
+		}
+		return s.verifySegmented(ctx, s.initialValue(input), proof)
+	}
+	return s.verifyCtx(ctx, input, proof.Hash, proof.Witness)
+}
+
+// NewProof 使用当前实例的参数组装 hash 与 witness 的 Proof
+// 在包外组装证明时应使用 NewProof 而不是结构体字面量，后者不知道 witness 与检查点的定长编码长度
+func (s *Sloth) NewProof(hash []byte, witness *big.Int) *Proof {
+	return &Proof{
+		Hash:        hash,
+		Witness:     witness,
+		Iterations:  s.Iterations,
+		Fingerprint: s.Fingerprint(),
+		size:        s.WitnessSize(),
+	}
+}
+
+// checkProofParams 检查 proof 声明的参数是否与当前实例一致
+func (s *Sloth) checkProofParams(proof *Proof) error {
+	if proof == nil {
+		return errors.New("proof cannot be nil")
+	}
+	if proof.Iterations != s.Iterations {
+		return fmt.Errorf("proof iterations %d do not match parameters (%d)", proof.Iterations, s.Iterations)
+	}
+	if !bytes.Equal(proof.Fingerprint, s.Fingerprint()) {
+		return errors.New("proof fingerprint does not match parameters")
+	}
+	return nil
+}
+
+// ExpandOutput 把证明中的单个哈希输出扩展为 n 字节的随机数
+// 使用 cSHAKE256 并以 outputDomain 作为定制字符串进行域分隔，同时吸收参数指纹，
+// 因此同一输出在不同参数下得到的扩展结果互不相关。需要多段独立随机数时可以一次取出后切分
+func ExpandOutput(proof *Proof, n int) ([]byte, error) {
+	if proof == nil || proof.Hash == nil {
+		return nil, errors.New("proof cannot be nil")
+	}
+	if n < 0 {
+		return nil, errors.New("output length must not be negative")
+	}
+	xof := sha3.NewCSHAKE256(nil, []byte(outputDomain))
+	binary.Write(xof, binary.BigEndian, uint32(len(proof.Fingerprint)))
+	xof.Write(proof.Fingerprint)
+	xof.Write(proof.Hash)
+	out := make([]byte, n)
+	xof.Read(out)
+	return out, nil
+}
@@ -0,0 +1,193 @@
+package slothgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// proofMagic 是二进制证明格式的魔数
+var proofMagic = []byte("SLTH")
+
+// proofFormatVersion 是二进制证明格式的版本号，核心字段的布局改变时递增
+const proofFormatVersion = 1
+
+// 扩展记录的类型。类型的最高位表示 "关键" 扩展: 不认识的关键扩展必须拒绝，
+// 不认识的非关键扩展可以跳过，这样新版本增加的可选信息不会妨碍旧版本验证
+const (
+	proofExtCheckpoints = 0x01
+	proofExtStateRoot   = 0x02
+
+	proofExtCritical = 0x80
+)
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，输出自描述的版本化二进制格式:
+//
+//	魔数 "SLTH"(4) | 版本(1) | 指纹长度(1) | 指纹 | 迭代次数(8) |
+//	哈希长度(1) | 哈希 | witness 长度(2) | witness | 扩展记录...
+//
+// 每个扩展记录为 类型(1) | 长度(4) | 内容，目前定义的扩展:
+//   - 0x01 区段检查点: 间隔(8) | 检查点 (各为 witness 长度的定长编码)
+//   - 0x02 状态承诺: 采样间隔(8) | Merkle 根
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	if p.Witness == nil {
+		return nil, errors.New("proof witness cannot be nil")
+	}
+	witness := encodeFixed(p.Witness, p.size)
+	if len(p.Fingerprint) > 255 || len(p.Hash) > 255 || len(witness) > 0xffff {
+		return nil, errors.New("proof field too long")
+	}
+	var buf bytes.Buffer
+	buf.Write(proofMagic)
+	buf.WriteByte(proofFormatVersion)
+	buf.WriteByte(byte(len(p.Fingerprint)))
+	buf.Write(p.Fingerprint)
+	binary.Write(&buf, binary.BigEndian, p.Iterations)
+	buf.WriteByte(byte(len(p.Hash)))
+	buf.Write(p.Hash)
+	binary.Write(&buf, binary.BigEndian, uint16(len(witness)))
+	buf.Write(witness)
+
+	if len(p.Checkpoints) > 0 {
+		ext := binary.BigEndian.AppendUint64(nil, p.CheckpointInterval)
+		for _, c := range p.Checkpoints {
+			cb := encodeFixed(c, len(witness))
+			if len(cb) != len(witness) {
+				return nil, errors.New("checkpoint is wider than the witness")
+			}
+			ext = append(ext, cb...)
+		}
+		writeProofExt(&buf, proofExtCheckpoints, ext)
+	}
+	if p.StateRoot != nil {
+		ext := binary.BigEndian.AppendUint64(nil, uint64(p.StateStride))
+		writeProofExt(&buf, proofExtStateRoot, append(ext, p.StateRoot...))
+	}
+	return buf.Bytes(), nil
+}
+
+func writeProofExt(buf *bytes.Buffer, typ byte, data []byte) {
+	buf.WriteByte(typ)
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+// 魔数或版本不符、字段被截断、扩展重复或格式错误、出现未知的关键扩展时返回 *ProofEncodingError
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	return proofEncodingError("binary", p.unmarshalBinary(data))
+}
+
+func (p *Proof) unmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, proofMagic) {
+		return errors.New("not a binary proof: bad magic")
+	}
+	r := bytes.NewReader(data[len(proofMagic):])
+	errShort := errors.New("proof data too short")
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return errShort
+	}
+	if version != proofFormatVersion {
+		return fmt.Errorf("unsupported proof format version %d", version)
+	}
+	var proof Proof
+	if proof.Fingerprint, err = readPrefixed8(r); err != nil {
+		return errShort
+	}
+	if err := binary.Read(r, binary.BigEndian, &proof.Iterations); err != nil {
+		return errShort
+	}
+	if proof.Hash, err = readPrefixed8(r); err != nil {
+		return errShort
+	}
+	var wLen uint16
+	if err := binary.Read(r, binary.BigEndian, &wLen); err != nil || wLen == 0 {
+		return errShort
+	}
+	witness := make([]byte, wLen)
+	if _, err := io.ReadFull(r, witness); err != nil {
+		return errShort
+	}
+	proof.Witness = new(big.Int).SetBytes(witness)
+	proof.size = int(wLen)
+
+	seen := make(map[byte]bool)
+	for r.Len() > 0 {
+		typ, _ := r.ReadByte()
+		var n uint32
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil || int64(n) > int64(r.Len()) {
+			return errShort
+		}
+		ext := make([]byte, n)
+		io.ReadFull(r, ext)
+		if seen[typ] {
+			return fmt.Errorf("duplicate proof extension %#x", typ)
+		}
+		seen[typ] = true
+
+		switch typ {
+		case proofExtCheckpoints:
+			if len(ext) < 8 || (len(ext)-8)%int(wLen) != 0 {
+				return errors.New("malformed checkpoint extension")
+			}
+			proof.CheckpointInterval = binary.BigEndian.Uint64(ext)
+			for off := 8; off < len(ext); off += int(wLen) {
+				proof.Checkpoints = append(proof.Checkpoints, new(big.Int).SetBytes(ext[off:off+int(wLen)]))
+			}
+		case proofExtStateRoot:
+			if len(ext) <= 8 {
+				return errors.New("malformed state commitment extension")
+			}
+			proof.StateStride = binary.BigEndian.Uint64(ext)
+			proof.StateRoot = ext[8:]
+		default:
+			if typ&proofExtCritical != 0 {
+				return fmt.Errorf("unsupported critical proof extension %#x", typ)
+			}
+		}
+	}
+	*p = proof
+	return nil
+}
+
+// readPrefixed8 读取 长度(1) | 内容
+func readPrefixed8(r *bytes.Reader) ([]byte, error) {
+	n, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// String 返回证明的 base64url (无填充) 字符串，内容为 MarshalBinary 的输出
+// 在已知参数的情况下，它包含验证所需的全部信息，适合嵌入 URL、二维码或聊天消息
+func (p *Proof) String() string {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return "slothgo.Proof(invalid)"
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseProof 解析 Proof.String 生成的字符串，失败时返回 *ProofEncodingError
+func ParseProof(s string) (*Proof, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, proofEncodingError("text", err)
+	}
+	var p Proof
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,103 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProof_Binary(t *testing.T) {
+	s, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(250))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	segmented, err := s.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	committed, _, err := testVDF.ComputeStateTree(context.Background(), testInput, 100)
+	if err != nil {
+		t.Fatalf("ComputeStateTree failed: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		s     *Sloth
+		proof *Proof
+	}{
+		"segmented": {s, segmented},
+		"committed": {testVDF, committed},
+	} {
+		data, err := tc.proof.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary failed: %v", name, err)
+		}
+		if !bytes.HasPrefix(data, []byte("SLTH\x01")) {
+			t.Errorf("%s: missing magic header", name)
+		}
+		var decoded Proof
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: UnmarshalBinary failed: %v", name, err)
+		}
+		if ok, err := tc.s.VerifyProof(testInput, &decoded); !ok {
+			t.Fatalf("%s: VerifyProof of decoded proof failed: %v", name, err)
+		}
+		if again, _ := decoded.MarshalBinary(); !bytes.Equal(again, data) {
+			t.Errorf("%s: binary encoding did not round-trip", name)
+		}
+	}
+}
+
+func TestProof_BinaryForwardCompat(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	data, _ := proof.MarshalBinary()
+	var decoded Proof
+
+	// 未知的非关键扩展被跳过
+	optional := append(bytes.Clone(data), 0x33, 0, 0, 0, 2, 0xaa, 0xbb)
+	if err := decoded.UnmarshalBinary(optional); err != nil {
+		t.Fatalf("expected unknown optional extension to be skipped: %v", err)
+	}
+	if ok, err := testVDF.VerifyProof(testInput, &decoded); !ok {
+		t.Fatalf("VerifyProof failed: %v", err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"magic":     append([]byte("SLTX"), data[4:]...),
+		"version":   append([]byte("SLTH\x02"), data[5:]...),
+		"truncated": data[:len(data)-1],
+		"critical":  append(bytes.Clone(data), 0x83, 0, 0, 0, 0),
+		"ext len":   append(bytes.Clone(data), 0x33, 0, 0, 0, 9),
+	} {
+		if err := decoded.UnmarshalBinary(bad); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestProof_String(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	token := proof.String()
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q is not unpadded base64url", token)
+	}
+	parsed, err := ParseProof(token)
+	if err != nil {
+		t.Fatalf("ParseProof failed: %v", err)
+	}
+	if ok, err := testVDF.VerifyProof(testInput, parsed); !ok {
+		t.Fatalf("VerifyProof of parsed proof failed: %v", err)
+	}
+	if _, err := ParseProof(token + "!"); err == nil {
+		t.Error("expected invalid base64 to be rejected")
+	}
+	if _, err := ParseProof(token[:len(token)-4]); err == nil {
+		t.Error("expected truncated token to be rejected")
+	}
+}
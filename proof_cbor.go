@@ -0,0 +1,145 @@
+package slothgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/internal/cbor"
+)
+
+// Proof 的 CBOR 映射中使用的整数键，值为零的字段不编码
+const (
+	cborKeyHash = iota + 1
+	cborKeyWitness
+	cborKeyIterations
+	cborKeyFingerprint
+	cborKeyCheckpointInterval
+	cborKeyCheckpoints
+	cborKeyStateStride
+	cborKeyStateRoot
+)
+
+// MarshalCBOR 以确定性 CBOR (RFC 8949 第 4.2.1 节) 编码证明
+// 证明编码为以小整数为键的映射，键按升序排列，witness 与检查点使用定长编码，
+// 因此同一个证明总是得到完全相同的字节，适合签名或写入区块链
+func (p *Proof) MarshalCBOR() ([]byte, error) {
+	if p.Witness == nil {
+		return nil, errors.New("proof witness cannot be nil")
+	}
+
+	var body []byte
+	var fields uint64
+	addBytes := func(key uint64, v []byte) {
+		if len(v) > 0 {
+			body = cbor.AppendBytes(cbor.AppendUint(body, key), v)
+			fields++
+		}
+	}
+	addUint := func(key uint64, v uint64) {
+		if v > 0 {
+			body = cbor.AppendUint(cbor.AppendUint(body, key), uint64(v))
+			fields++
+		}
+	}
+	addBytes(cborKeyHash, p.Hash)
+	addBytes(cborKeyWitness, encodeFixed(p.Witness, p.size))
+	addUint(cborKeyIterations, p.Iterations)
+	addBytes(cborKeyFingerprint, p.Fingerprint)
+	addUint(cborKeyCheckpointInterval, p.CheckpointInterval)
+	if len(p.Checkpoints) > 0 {
+		body = cbor.AppendHead(cbor.AppendUint(body, cborKeyCheckpoints), cbor.MajorArray, uint64(len(p.Checkpoints)))
+		for _, c := range p.Checkpoints {
+			body = cbor.AppendBytes(body, encodeFixed(c, p.size))
+		}
+		fields++
+	}
+	addUint(cborKeyStateStride, p.StateStride)
+	addBytes(cborKeyStateRoot, p.StateRoot)
+
+	return append(cbor.AppendHead(nil, cbor.MajorMap, fields), body...), nil
+}
+
+// UnmarshalCBOR 解码 MarshalCBOR 的输出
+// 非确定性的编码 (非最短整数、不定长、键乱序或重复、未知键、尾随数据) 都会被拒绝
+func (p *Proof) UnmarshalCBOR(data []byte) error {
+	return proofEncodingError("cbor", p.unmarshalCBOR(data))
+}
+
+func (p *Proof) unmarshalCBOR(data []byte) error {
+	d := cbor.NewDecoder(data)
+	fields, err := d.Head(cbor.MajorMap)
+	if err != nil {
+		return err
+	}
+	var proof Proof
+	var last uint64
+	for range fields {
+		key, err := d.Uint()
+		if err != nil {
+			return err
+		}
+		if key <= last {
+			return errors.New("cbor: map keys must be unique and in ascending order")
+		}
+		last = key
+		switch key {
+		case cborKeyHash, cborKeyWitness, cborKeyFingerprint, cborKeyStateRoot:
+			b, err := d.Bytes()
+			if err != nil {
+				return err
+			}
+			b = append([]byte(nil), b...)
+			switch key {
+			case cborKeyHash:
+				proof.Hash = b
+			case cborKeyWitness:
+				proof.Witness = new(big.Int).SetBytes(b)
+				proof.size = len(b)
+			case cborKeyFingerprint:
+				proof.Fingerprint = b
+			case cborKeyStateRoot:
+				proof.StateRoot = b
+			}
+		case cborKeyIterations, cborKeyCheckpointInterval, cborKeyStateStride:
+			n, err := d.Uint()
+			if err != nil {
+				return err
+			}
+			if n > math.MaxInt64 {
+				return errors.New("cbor: integer out of range")
+			}
+			switch key {
+			case cborKeyIterations:
+				proof.Iterations = n
+			case cborKeyCheckpointInterval:
+				proof.CheckpointInterval = n
+			case cborKeyStateStride:
+				proof.StateStride = n
+			}
+		case cborKeyCheckpoints:
+			n, err := d.Head(cbor.MajorArray)
+			if err != nil {
+				return err
+			}
+			for range n {
+				b, err := d.Bytes()
+				if err != nil {
+					return err
+				}
+				proof.Checkpoints = append(proof.Checkpoints, new(big.Int).SetBytes(b))
+			}
+		default:
+			return fmt.Errorf("cbor: unknown proof field %d", key)
+		}
+	}
+	if !d.Done() {
+		return errors.New("cbor: trailing data after proof")
+	}
+	if proof.Witness == nil {
+		return errors.New("proof witness is missing")
+	}
+	*p = proof
+	return nil
+}
@@ -0,0 +1,49 @@
+package slothgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProof_CBOR(t *testing.T) {
+	s, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(400))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := s.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	data, err := proof.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("MarshalCBOR failed: %v", err)
+	}
+	// 映射头 (6 个字段) 之后第一个键为 1 (hash)
+	if data[0] != 0xa6 || data[1] != 0x01 {
+		t.Errorf("unexpected CBOR prefix %x", data[:2])
+	}
+
+	var decoded Proof
+	if err := decoded.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("UnmarshalCBOR failed: %v", err)
+	}
+	if ok, err := s.VerifyProof(testInput, &decoded); !ok {
+		t.Fatalf("VerifyProof of decoded proof failed: %v", err)
+	}
+	again, _ := decoded.MarshalCBOR()
+	if !bytes.Equal(again, data) {
+		t.Error("CBOR encoding is not deterministic")
+	}
+
+	for name, bad := range map[string][]byte{
+		"trailing":  append(bytes.Clone(data), 0),
+		"truncated": data[:len(data)-1],
+		"unsorted":  {0xa2, 0x02, 0x41, 0x01, 0x01, 0x41, 0x01},
+		"unknown":   {0xa2, 0x02, 0x41, 0x01, 0x18, 0x63, 0x00},
+		"long int":  {0xa2, 0x02, 0x41, 0x01, 0x03, 0x18, 0x05},
+	} {
+		if err := decoded.UnmarshalCBOR(bad); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
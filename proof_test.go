@@ -0,0 +1,75 @@
+package slothgo
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestProof_ComputeAndVerify 检查 Proof 的计算与验证，以及参数不一致时的拒绝
+func TestProof_ComputeAndVerify(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if proof.Iterations != testIterations {
+		t.Errorf("proof iterations = %d, want %d", proof.Iterations, testIterations)
+	}
+
+	verified, err := testVDF.VerifyProof(testInput, proof)
+	if err != nil || !verified {
+		t.Fatalf("VerifyProof failed: %v, %v", verified, err)
+	}
+
+	// 迭代次数被篡改
+	tampered := *proof
+	tampered.Iterations++
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected error for tampered iterations")
+	}
+
+	// 指纹被篡改
+	tampered = *proof
+	tampered.Fingerprint = make([]byte, len(proof.Fingerprint))
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected error for tampered fingerprint")
+	}
+
+	// witness 被篡改
+	tampered = *proof
+	tampered.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected error for tampered witness")
+	}
+
+	if verified, err := testVDF.VerifyProof(testInput, nil); verified || err == nil {
+		t.Error("expected error for nil proof")
+	}
+}
+
+func TestExpandOutput(t *testing.T) {
+	proof, err := testVDF.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	long, err := ExpandOutput(proof, 1024)
+	if err != nil {
+		t.Fatalf("ExpandOutput failed: %v", err)
+	}
+	short, _ := ExpandOutput(proof, 32)
+	if len(long) != 1024 || !bytes.Equal(long[:32], short) {
+		t.Error("shorter expansion should be a prefix of the longer one")
+	}
+	if bytes.Equal(short, proof.Hash) {
+		t.Error("expanded output should be domain separated from the hash")
+	}
+
+	other := *proof
+	other.Fingerprint = []byte("other parameters")
+	if o, _ := ExpandOutput(&other, 32); bytes.Equal(o, short) {
+		t.Error("expanded output should depend on the parameter fingerprint")
+	}
+	if _, err := ExpandOutput(nil, 32); err == nil {
+		t.Error("expected error for nil proof")
+	}
+}
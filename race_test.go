@@ -0,0 +1,7 @@
+//go:build race
+
+package slothgo
+
+// raceEnabled 报告测试是否以 -race 构建
+// 竞态检测下 sync.Pool 会随机丢弃缓存的值，分配次数不稳定
+const raceEnabled = true
@@ -0,0 +1,47 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ComputeReader 与 Compute 相同，但以流的方式从 r 读取输入
+// 输入不会被整体载入内存，适用于文件、区块等任意大小的数据
+func (s *Sloth) ComputeReader(r io.Reader) (hash []byte, witness *big.Int, err error) {
+	w, err := s.initialValueReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err = s.iterate(context.Background(), w, 0, s.Iterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.outputHash(w), w, nil
+}
+
+// VerifyReader 与 Verify 相同，但以流的方式从 r 读取原始输入
+func (s *Sloth) VerifyReader(r io.Reader, hash []byte, witness *big.Int) (bool, error) {
+	w, err := s.initialValueReader(r)
+	if err != nil {
+		return false, err
+	}
+	return s.verifyFrom(context.Background(), w, hash, witness)
+}
+
+// initialValueReader 流式计算 w₀ = int(h(r)) mod p
+func (s *Sloth) initialValueReader(r io.Reader) (*big.Int, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	if s.xmd {
+		return s.hashToField(r)
+	}
+	hasher := s.newHasher(hashRoleInput)
+	if _, err := io.Copy(hasher, r); err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return s.digestToField(hasher.Sum(nil)), nil
+}
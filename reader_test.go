@@ -0,0 +1,32 @@
+package slothgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestComputeReader 检查流式接口与字节数组接口的结果一致
+func TestComputeReader(t *testing.T) {
+	wantHash, wantWitness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	hash, witness, err := testVDF.ComputeReader(bytes.NewReader(testInput))
+	if err != nil {
+		t.Fatalf("ComputeReader failed: %v", err)
+	}
+	if !bytes.Equal(hash, wantHash) || witness.Cmp(wantWitness) != 0 {
+		t.Fatal("ComputeReader result differs from Compute")
+	}
+
+	verified, err := testVDF.VerifyReader(bytes.NewReader(testInput), hash, witness)
+	if err != nil || !verified {
+		t.Fatalf("VerifyReader failed: %v, %v", verified, err)
+	}
+
+	verified, err = testVDF.VerifyReader(bytes.NewReader([]byte("wrong input")), hash, witness)
+	if verified || err == nil {
+		t.Error("expected VerifyReader to reject wrong input")
+	}
+}
@@ -0,0 +1,240 @@
+// Package scheduler 按优先级调度顺序计算任务，并限制同时运行的任务数
+// Sloth 的计算无法并行，每个任务只占用一个核; 同时运行的任务超过核数时它们互相争抢核与缓存，
+// 全部任务都会变慢。Scheduler 让多出的任务排队等待，优先级高的任务先运行，同一优先级按提交顺序运行
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+var (
+	// ErrQueueFull 表示等待中的任务已达到上限
+	ErrQueueFull = errors.New("scheduler: queue is full")
+	// ErrClosed 表示调度器已关闭，关闭时仍在等待的任务也以该错误结束
+	ErrClosed = errors.New("scheduler: scheduler is closed")
+	// ErrCanceled 表示任务在开始运行前被取消
+	ErrCanceled = errors.New("scheduler: task was canceled")
+)
+
+// Func 是被调度的任务，ctx 在任务被取消或调度器关闭时结束
+type Func func(ctx context.Context) error
+
+// Config 是调度器的配置，零值字段使用括号中的默认值
+type Config struct {
+	Concurrency int                    // 同时运行的任务数 (CPU 核数)
+	MaxPending  int                    // 等待中的任务上限 (不限制)
+	Metrics     metrics.QueueCollector // 接收队列深度的收集器 (不报告)
+}
+
+// Stats 是调度器在某一时刻的状态
+type Stats struct {
+	Pending    int         // 等待中的任务数
+	Running    int         // 正在运行的任务数
+	ByPriority map[int]int // 各优先级等待中的任务数
+}
+
+// Scheduler 是任务调度器，可以被并发调用
+type Scheduler struct {
+	cfg Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	pending taskHeap
+	running int
+	seq     uint64
+}
+
+// New 创建调度器，使用完毕后应调用 Close
+func New(cfg Config) *Scheduler {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	s := &Scheduler{cfg: cfg}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.report()
+	return s
+}
+
+// Task 是已提交的任务
+type Task struct {
+	s        *Scheduler
+	priority int
+	seq      uint64
+	fn       Func
+	index    int // 在等待堆中的位置，不在堆中时为 -1
+
+	cancel context.CancelFunc // 运行时设置，调用方持有 s.mu
+	done   chan struct{}
+	err    error
+}
+
+// Submit 提交一个任务，priority 越大越先运行
+func (s *Scheduler) Submit(priority int, fn Func) (*Task, error) {
+	if fn == nil {
+		return nil, errors.New("task cannot be nil")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return nil, ErrClosed
+	}
+	if s.cfg.MaxPending > 0 && len(s.pending) >= s.cfg.MaxPending {
+		return nil, ErrQueueFull
+	}
+	s.seq++
+	t := &Task{s: s, priority: priority, seq: s.seq, fn: fn, done: make(chan struct{})}
+	heap.Push(&s.pending, t)
+	s.dispatch()
+	s.report()
+	return t, nil
+}
+
+// Priority 返回任务的优先级
+func (t *Task) Priority() int {
+	return t.priority
+}
+
+// Done 返回在任务结束时关闭的 channel
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err 返回任务的结果，任务结束前返回 nil
+func (t *Task) Err() error {
+	select {
+	case <-t.done:
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// Wait 等待任务结束并返回其结果; ctx 先结束时返回 ctx.Err()，任务不受影响
+func (t *Task) Wait(ctx context.Context) error {
+	select {
+	case <-t.done:
+		return t.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancel 取消任务: 等待中的任务立即以 ErrCanceled 结束，运行中的任务的 ctx 被取消，
+// 已经结束的任务不受影响
+func (t *Task) Cancel() {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case t.index >= 0:
+		heap.Remove(&s.pending, t.index)
+		t.finish(ErrCanceled)
+		s.report()
+	case t.cancel != nil:
+		t.cancel()
+	}
+}
+
+// finish 记录结果并唤醒等待者，每个任务只调用一次
+func (t *Task) finish(err error) {
+	t.err = err
+	close(t.done)
+}
+
+// dispatch 在有空闲名额时启动优先级最高的等待任务，调用方必须持有 s.mu
+func (s *Scheduler) dispatch() {
+	for s.running < s.cfg.Concurrency && len(s.pending) > 0 {
+		t := heap.Pop(&s.pending).(*Task)
+		var ctx context.Context
+		ctx, t.cancel = context.WithCancel(s.ctx)
+		s.running++
+		s.wg.Add(1)
+		go s.run(ctx, t)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, t *Task) {
+	defer s.wg.Done()
+	err := t.fn(ctx)
+	t.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	t.finish(err)
+	if s.ctx.Err() == nil {
+		s.dispatch()
+	}
+	s.report()
+}
+
+// Stats 返回当前的队列深度
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Stats{Pending: len(s.pending), Running: s.running, ByPriority: make(map[int]int)}
+	for _, t := range s.pending {
+		st.ByPriority[t.priority]++
+	}
+	return st
+}
+
+// report 把队列深度报告给收集器，调用方必须持有 s.mu
+func (s *Scheduler) report() {
+	if s.cfg.Metrics != nil {
+		s.cfg.Metrics.QueueDepth(len(s.pending), s.running)
+	}
+}
+
+// Close 让等待中的任务以 ErrClosed 结束，取消运行中的任务并等待它们返回
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	s.cancel()
+	for len(s.pending) > 0 {
+		heap.Pop(&s.pending).(*Task).finish(ErrClosed)
+	}
+	s.report()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// taskHeap 按优先级从高到低、同一优先级按提交顺序排列
+type taskHeap []*Task
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *taskHeap) Push(x any) {
+	t := x.(*Task)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*h = old[:len(old)-1]
+	return t
+}
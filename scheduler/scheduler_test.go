@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// depthRecorder 记录最近一次报告的队列深度
+type depthRecorder struct {
+	mu               sync.Mutex
+	pending, running int
+}
+
+func (d *depthRecorder) QueueDepth(pending, running int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending, d.running = pending, running
+}
+
+// TestPriority 检查并发上限、优先级顺序与同一优先级内的提交顺序
+func TestPriority(t *testing.T) {
+	d := &depthRecorder{}
+	s := New(Config{Concurrency: 1, Metrics: d})
+	defer s.Close()
+
+	// 第一个任务占住唯一的名额，其余任务全部排队
+	release := make(chan struct{})
+	blocker, _ := s.Submit(0, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) Func {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	var tasks []*Task
+	for _, tc := range []struct {
+		name     string
+		priority int
+	}{{"low", -1}, {"normal-1", 0}, {"high", 5}, {"normal-2", 0}} {
+		task, err := s.Submit(tc.priority, record(tc.name))
+		if err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	st := s.Stats()
+	if st.Pending != 4 || st.Running != 1 || st.ByPriority[0] != 2 || st.ByPriority[5] != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	d.mu.Lock()
+	if d.pending != 4 || d.running != 1 {
+		t.Errorf("collector saw pending %d, running %d", d.pending, d.running)
+	}
+	d.mu.Unlock()
+
+	close(release)
+	for _, task := range append(tasks, blocker) {
+		if err := task.Wait(context.Background()); err != nil {
+			t.Fatalf("task failed: %v", err)
+		}
+	}
+	want := []string{"high", "normal-1", "normal-2", "low"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("tasks ran in order %v, want %v", order, want)
+		}
+	}
+	if st := s.Stats(); st.Pending != 0 || st.Running != 0 {
+		t.Errorf("unexpected stats after completion %+v", st)
+	}
+}
+
+// TestConcurrency 检查同时运行的任务数不超过上限
+func TestConcurrency(t *testing.T) {
+	s := New(Config{Concurrency: 3})
+	defer s.Close()
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var tasks []*Task
+	for range 12 {
+		task, _ := s.Submit(0, func(ctx context.Context) error {
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		})
+		tasks = append(tasks, task)
+	}
+	for _, task := range tasks {
+		task.Wait(context.Background())
+	}
+	if peak != 3 {
+		t.Errorf("peak concurrency %d, want 3", peak)
+	}
+}
+
+// TestCancel 检查取消等待中与运行中的任务，以及关闭调度器
+func TestCancel(t *testing.T) {
+	s := New(Config{Concurrency: 1, MaxPending: 2})
+	started := make(chan struct{})
+	running, _ := s.Submit(0, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	queued, _ := s.Submit(0, func(ctx context.Context) error { return nil })
+	other, _ := s.Submit(0, func(ctx context.Context) error { return nil })
+	if _, err := s.Submit(0, func(ctx context.Context) error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+
+	queued.Cancel()
+	if err := queued.Err(); !errors.Is(err, ErrCanceled) {
+		t.Errorf("canceled pending task returned %v", err)
+	}
+	if st := s.Stats(); st.Pending != 1 {
+		t.Errorf("canceled task is still pending: %+v", st)
+	}
+	running.Cancel()
+	if err := running.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled running task returned %v", err)
+	}
+	if err := other.Wait(context.Background()); err != nil {
+		t.Errorf("next task failed: %v", err)
+	}
+
+	blocked, _ := s.Submit(0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	waiting, _ := s.Submit(0, func(ctx context.Context) error { return nil })
+	s.Close()
+	if err := blocked.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("running task returned %v after Close", err)
+	}
+	if err := waiting.Err(); !errors.Is(err, ErrClosed) {
+		t.Errorf("pending task returned %v after Close", err)
+	}
+	if _, err := s.Submit(0, func(ctx context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close returned %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package slothgo
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// computeSegmented 执行计算并每隔 s.segmentInterval 次迭代记录一个检查点
+func (s *Sloth) computeSegmented(ctx context.Context, input []byte) (*Proof, error) {
+	k := s.segmentInterval
+	w := s.initialValue(input)
+	var checkpoints []*big.Int
+	for i := uint64(0); i < s.Iterations; i += k {
+		end := stepEnd(i, k, s.Iterations)
+		var err error
+		w, err = s.iterate(ctx, w, i, end)
+		if err != nil {
+			return nil, err
+		}
+		if end < s.Iterations {
+			checkpoints = append(checkpoints, new(big.Int).Set(w))
+		}
+	}
+
+	witness := new(big.Int).Set(w)
+	proof := s.NewProof(s.outputHash(witness), witness)
+	proof.CheckpointInterval = k
+	proof.Checkpoints = checkpoints
+	return proof, nil
+}
+
+// verifySegmented 并行验证相邻检查点之间的各个区段
+// 区段 j 从第 j*k 次迭代到第 min((j+1)*k, l) 次迭代, 两端分别是 w₀、检查点或最终的 witness
+func (s *Sloth) verifySegmented(ctx context.Context, wStart *big.Int, proof *Proof) (bool, error) {
+	k := proof.CheckpointInterval
+	if k == 0 {
+		return false, errors.New("checkpoint interval must be positive")
+	}
+	if want := ceilDiv(s.Iterations, k) - 1; uint64(len(proof.Checkpoints)) != want {
+		return false, fmt.Errorf("proof has %d checkpoints, expected %d", len(proof.Checkpoints), want)
+	}
+	if proof.Hash == nil {
+		return false, errors.New("hash cannot be nil")
+	}
+	if proof.Witness == nil {
+		return false, errors.New("witness cannot be nil")
+	}
+
+	// boundaries[j] 是第 j 个区段的起点, boundaries[j+1] 是它的终点
+	boundaries := make([]*big.Int, 0, len(proof.Checkpoints)+2)
+	boundaries = append(boundaries, wStart)
+	boundaries = append(boundaries, proof.Checkpoints...)
+	boundaries = append(boundaries, proof.Witness)
+	if proof.Witness.Cmp(s.P) >= 0 || proof.Witness.Sign() < 0 {
+		return false, ErrWitnessOutOfRange
+	}
+	for _, b := range proof.Checkpoints {
+		if b == nil || b.Cmp(s.P) >= 0 || b.Sign() < 0 {
+			return false, errors.New("checkpoint must be in the range [0, p-1]")
+		}
+	}
+
+	if subtle.ConstantTimeCompare(proof.Hash, s.outputHash(proof.Witness)) != 1 {
+		return false, ErrHashMismatch
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	segments := len(boundaries) - 1
+	failed := make([]error, segments)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), segments) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc := newScratch()
+			for j := range jobs {
+				n := min(uint64(j+1)*k, s.Iterations) - uint64(j)*k
+				w, err := s.reverse(ctx, boundaries[j+1], n, sc)
+				if err != nil {
+					failed[j] = err
+					continue
+				}
+				if w.Cmp(boundaries[j]) != 0 {
+					failed[j] = fmt.Errorf("segment %d: %w", j, ErrReversalMismatch)
+					cancel()
+				}
+			}
+		}()
+	}
+	for j := range segments {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	// 优先报告真正的区段错误，而不是因提前取消产生的 ctx 错误
+	var firstErr error
+	for _, err := range failed {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return false, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return false, firstErr
+	}
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package slothgo
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestSegmentCheckpoints 检查带区段检查点的证明可以被并行验证，且能发现被篡改的检查点
+func TestSegmentCheckpoints(t *testing.T) {
+	vdf, err := New(testVDF.P, testIterations, WithSegmentCheckpoints(300))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := vdf.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	if len(proof.Checkpoints) != 3 {
+		t.Fatalf("got %d checkpoints, want 3", len(proof.Checkpoints))
+	}
+
+	_, wantWitness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if proof.Witness.Cmp(wantWitness) != 0 {
+		t.Fatal("segmented proof witness differs from Compute")
+	}
+
+	// 不带该选项的实例同样可以验证带检查点的证明
+	verified, err := testVDF.VerifyProof(testInput, proof)
+	if err != nil || !verified {
+		t.Fatalf("VerifyProof failed: %v, %v", verified, err)
+	}
+
+	tampered := *proof
+	tampered.Checkpoints = append([]*big.Int(nil), proof.Checkpoints...)
+	tampered.Checkpoints[1] = new(big.Int).Add(proof.Checkpoints[1], big.NewInt(1))
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected tampered checkpoint to be rejected")
+	}
+
+	tampered.Checkpoints = proof.Checkpoints[:2]
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected missing checkpoint to be rejected")
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+)
+
+var (
+	// ErrKeyNotFound 表示 API 密钥不存在
+	ErrKeyNotFound = errors.New("service: API key not found")
+	// ErrQuotaExceeded 表示 API 密钥在当前 24 小时窗口内的迭代次数已用完
+	ErrQuotaExceeded = errors.New("service: iteration quota exceeded")
+)
+
+// quotaWindow 是 DailyIterations 的统计窗口
+const quotaWindow = 24 * time.Hour
+
+// Limits 是一个 API 密钥的限额，零值字段表示不限制
+// 验证的耗时与迭代次数成正比，因此配额按迭代次数而不是请求数计算
+type Limits struct {
+	Rate            float64 `json:"rate,omitempty"`             // 每秒请求数
+	Burst           int     `json:"burst,omitempty"`            // 允许的突发请求数 (默认为 Rate 向上取整)
+	DailyIterations uint64  `json:"daily_iterations,omitempty"` // 每 24 小时提交计算与验证的迭代次数之和
+}
+
+// APIKey 是 API 密钥的公开信息，不包含密钥本身
+type APIKey struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Limits  Limits    `json:"limits"`
+	Created time.Time `json:"created"`
+	Revoked time.Time `json:"revoked,omitzero"`
+}
+
+// storedKey 是保存在文件中的密钥，只保存密钥的 SHA-256
+type storedKey struct {
+	APIKey
+	Hash string `json:"hash"`
+}
+
+// keyState 是密钥及其运行时的限流状态，限流状态不持久化
+type keyState struct {
+	storedKey
+	tokens   float64
+	refilled time.Time
+	window   time.Time // 当前配额窗口的开始时间
+	used     uint64
+}
+
+// Keyring 管理 REST 接口的 API 密钥并实现按密钥的限流，可以被并发调用
+// 客户端以 "Authorization: Bearer <密钥>" 或 "X-API-Key: <密钥>" 发送密钥，
+// 密钥的格式为 "<ID>.<随机数>"，服务端只保存随机数的 SHA-256
+type Keyring struct {
+	path string
+	now  func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*keyState
+}
+
+// NewKeyring 创建密钥环，path 不为空时从该文件加载密钥并在每次修改后保存
+func NewKeyring(path string) (*Keyring, error) {
+	k := &Keyring{path: path, now: time.Now, keys: make(map[string]*keyState)}
+	if path == "" {
+		return k, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored []storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, sk := range stored {
+		k.keys[sk.ID] = &keyState{storedKey: sk}
+	}
+	return k, nil
+}
+
+// Create 创建一个密钥，返回只在此时可见的完整密钥
+func (k *Keyring) Create(name string, limits Limits) (secret string, key APIKey, err error) {
+	if limits.Rate < 0 || limits.Burst < 0 || math.IsNaN(limits.Rate) || math.IsInf(limits.Rate, 0) {
+		return "", APIKey{}, errors.New("limits must not be negative")
+	}
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", APIKey{}, err
+	}
+	id, random := hex.EncodeToString(b[:8]), hex.EncodeToString(b[8:])
+	sum := sha256.Sum256([]byte(random))
+	st := &keyState{storedKey: storedKey{
+		APIKey: APIKey{ID: id, Name: name, Limits: limits, Created: k.now()},
+		Hash:   hex.EncodeToString(sum[:]),
+	}}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = st
+	if err := k.save(); err != nil {
+		delete(k.keys, id)
+		return "", APIKey{}, err
+	}
+	return id + "." + random, st.APIKey, nil
+}
+
+// Revoke 吊销密钥，之后使用该密钥的请求返回 401
+func (k *Keyring) Revoke(id string) (APIKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st, ok := k.keys[id]
+	if !ok {
+		return APIKey{}, ErrKeyNotFound
+	}
+	if st.Revoked.IsZero() {
+		st.Revoked = k.now()
+		if err := k.save(); err != nil {
+			st.Revoked = time.Time{}
+			return APIKey{}, err
+		}
+	}
+	return st.APIKey, nil
+}
+
+// List 按创建时间返回全部密钥，包括已吊销的密钥
+func (k *Keyring) List() []APIKey {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	keys := make([]APIKey, 0, len(k.keys))
+	for _, st := range k.keys {
+		keys = append(keys, st.APIKey)
+	}
+	slices.SortFunc(keys, func(a, b APIKey) int { return a.Created.Compare(b.Created) })
+	return keys
+}
+
+// save 把密钥写入文件，调用方必须持有 k.mu
+func (k *Keyring) save() error {
+	if k.path == "" {
+		return nil
+	}
+	stored := make([]storedKey, 0, len(k.keys))
+	for _, st := range k.keys {
+		stored = append(stored, st.storedKey)
+	}
+	slices.SortFunc(stored, func(a, b storedKey) int { return strings.Compare(a.ID, b.ID) })
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(k.path, data)
+}
+
+// authenticate 查找与 secret 对应的有效密钥
+func (k *Keyring) authenticate(secret string) (*keyState, bool) {
+	id, random, ok := strings.Cut(secret, ".")
+	if !ok {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(random))
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st, ok := k.keys[id]
+	if !ok || !st.Revoked.IsZero() {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(st.Hash)) != 1 {
+		return nil, false
+	}
+	return st, true
+}
+
+// allow 按令牌桶判断是否允许一个请求，不允许时返回需要等待的时间
+func (k *Keyring) allow(st *keyState) (bool, time.Duration) {
+	rate := st.Limits.Rate
+	if rate == 0 {
+		return true, 0
+	}
+	burst := float64(st.Limits.Burst)
+	if burst == 0 {
+		burst = math.Ceil(rate)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	if st.refilled.IsZero() {
+		st.tokens = burst
+	} else {
+		st.tokens = min(burst, st.tokens+now.Sub(st.refilled).Seconds()*rate)
+	}
+	st.refilled = now
+	if st.tokens < 1 {
+		return false, time.Duration((1 - st.tokens) / rate * float64(time.Second))
+	}
+	st.tokens--
+	return true, 0
+}
+
+// charge 从 st 的配额中扣除 iterations，配额不足时不扣除并返回 ErrQuotaExceeded
+func (k *Keyring) charge(st *keyState, iterations uint64) error {
+	limit := st.Limits.DailyIterations
+	if limit == 0 {
+		return nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	if now.Sub(st.window) >= quotaWindow {
+		st.window, st.used = now, 0
+	}
+	if st.used > limit || iterations > limit-st.used {
+		return ErrQuotaExceeded
+	}
+	st.used += iterations
+	return nil
+}
+
+// refund 退还 charge 扣除的迭代次数，用于请求在扣除配额之后失败的情况
+func (k *Keyring) refund(st *keyState, iterations uint64) {
+	if st.Limits.DailyIterations == 0 {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	st.used -= min(iterations, st.used)
+}
+
+// Middleware 要求每个请求携带有效的 API 密钥并按密钥限流: 缺少或无效的密钥返回 401，
+// 超过请求速率返回 429 与 Retry-After。NewHTTPHandler 的计算与验证接口另外按迭代次数扣除每日配额
+func (k *Keyring) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			secret = bearer
+		}
+		if secret == "" {
+			writeError(w, http.StatusUnauthorized, errors.New("missing API key"))
+			return
+		}
+		st, ok := k.authenticate(secret)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid API key"))
+			return
+		}
+		if ok, wait := k.allow(st); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyContext{}, &keyCharge{k: k, st: st})))
+	})
+}
+
+// keyContext 是请求上下文中 keyCharge 的键
+type keyContext struct{}
+
+// keyCharge 把请求的密钥与其密钥环一起传给处理函数
+type keyCharge struct {
+	k  *Keyring
+	st *keyState
+}
+
+// chargeRequest 从请求所用密钥的配额中扣除 iterations，返回退还配额的函数
+// 请求没有经过 Middleware 或 iterations 为 0 时不做任何事，非法的迭代次数由之后的校验拒绝
+func chargeRequest(r *http.Request, iterations uint64) (refund func(), err error) {
+	c, ok := r.Context().Value(keyContext{}).(*keyCharge)
+	if !ok || iterations == 0 {
+		return func() {}, nil
+	}
+	if err := c.k.charge(c.st, iterations); err != nil {
+		return nil, err
+	}
+	return func() { c.k.refund(c.st, iterations) }, nil
+}
+
+// requestKeyID 返回请求所用密钥的 ID，请求没有经过 Middleware 时返回空字符串
+func requestKeyID(r *http.Request) string {
+	if c, ok := r.Context().Value(keyContext{}).(*keyCharge); ok {
+		return c.st.ID
+	}
+	return ""
+}
+
+// createKeyRequest 是 POST /admin/keys 的请求体
+type createKeyRequest struct {
+	Name   string `json:"name"`
+	Limits Limits `json:"limits"`
+}
+
+// createKeyResponse 是 POST /admin/keys 的响应，key 是只在此时返回的完整密钥
+type createKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
+
+// NewKeyAdminHandler 提供密钥管理接口，请求必须携带 "Authorization: Bearer <adminToken>":
+//
+//	GET    /admin/keys       全部密钥
+//	POST   /admin/keys       {"name": 名称, "limits": 限额}，返回 201 与完整密钥
+//	DELETE /admin/keys/{id}  吊销密钥
+func NewKeyAdminHandler(k *Keyring, adminToken string) (http.Handler, error) {
+	if adminToken == "" {
+		return nil, errors.New("admin token cannot be empty")
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, k.List())
+	})
+	mux.HandleFunc("POST /admin/keys", func(w http.ResponseWriter, r *http.Request) {
+		var req createKeyRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		secret, key, err := k.Create(req.Name, req.Limits)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, createKeyResponse{Key: secret, APIKey: key})
+	})
+	mux.HandleFunc("DELETE /admin/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		key, err := k.Revoke(r.PathValue("id"))
+		if errors.Is(err, ErrKeyNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, key)
+	})
+	want := []byte("Bearer " + adminToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid admin token"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// doJSON 发送以 v 为请求体的请求，auth 不为空时作为 Bearer 令牌
+func doJSON(t *testing.T, method, url, auth string, v any) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	if v != nil {
+		json.NewEncoder(&body).Encode(v)
+	}
+	req, _ := http.NewRequest(method, url, &body)
+	if auth != "" {
+		req.Header.Set("Authorization", "Bearer "+auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	return resp
+}
+
+// TestKeyring 检查密钥的创建、吊销与持久化
+func TestKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	k, err := NewKeyring(path)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	secret, key, err := k.Create("alice", Limits{Rate: 2})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, ok := k.authenticate(secret); !ok {
+		t.Fatal("fresh key was rejected")
+	}
+	if _, ok := k.authenticate(key.ID + ".00"); ok {
+		t.Fatal("wrong secret was accepted")
+	}
+	if _, _, err := k.Create("bad", Limits{Rate: -1}); err == nil {
+		t.Error("negative rate was accepted")
+	}
+
+	// 重新加载后密钥仍然有效，吊销同样会被保存
+	k, err = NewKeyring(path)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if keys := k.List(); len(keys) != 1 || keys[0].ID != key.ID || keys[0].Limits.Rate != 2 {
+		t.Fatalf("reloaded keys = %+v", keys)
+	}
+	if _, ok := k.authenticate(secret); !ok {
+		t.Fatal("reloaded key was rejected")
+	}
+	if _, err := k.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := k.Revoke("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Revoke(missing) = %v, want ErrKeyNotFound", err)
+	}
+	k, _ = NewKeyring(path)
+	if _, ok := k.authenticate(secret); ok {
+		t.Error("revoked key was accepted after reload")
+	}
+}
+
+// TestKeyringLimits 检查令牌桶与每日配额
+func TestKeyringLimits(t *testing.T) {
+	k, _ := NewKeyring("")
+	now := time.Unix(1000, 0)
+	k.now = func() time.Time { return now }
+	secret, _, _ := k.Create("bob", Limits{Rate: 1, Burst: 2, DailyIterations: 100})
+	st, _ := k.authenticate(secret)
+
+	for i := range 2 {
+		if ok, _ := k.allow(st); !ok {
+			t.Fatalf("request %d within the burst was limited", i)
+		}
+	}
+	if ok, wait := k.allow(st); ok || wait != time.Second {
+		t.Fatalf("allow after the burst = %v, %v", ok, wait)
+	}
+	now = now.Add(time.Second)
+	if ok, _ := k.allow(st); !ok {
+		t.Fatal("request after refilling was limited")
+	}
+
+	if err := k.charge(st, 60); err != nil {
+		t.Fatalf("charge failed: %v", err)
+	}
+	if err := k.charge(st, 50); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("charge over quota = %v", err)
+	}
+	k.refund(st, 60)
+	if err := k.charge(st, 100); err != nil {
+		t.Fatalf("charge after refund failed: %v", err)
+	}
+	now = now.Add(quotaWindow)
+	if err := k.charge(st, 100); err != nil {
+		t.Errorf("quota was not reset after a day: %v", err)
+	}
+}
+
+// TestAuthHTTP 检查中间件的状态码以及管理接口
+func TestAuthHTTP(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1})
+	k, _ := NewKeyring("")
+	admin, err := NewKeyAdminHandler(k, "root")
+	if err != nil {
+		t.Fatalf("NewKeyAdminHandler failed: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", admin)
+	mux.Handle("/", k.Middleware(NewHTTPHandler(q)))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/admin/keys", "wrong", createKeyRequest{Name: "x"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong admin token: status %d", resp.StatusCode)
+	}
+	resp = doJSON(t, http.MethodPost, ts.URL+"/admin/keys", "root", createKeyRequest{Name: "x", Limits: Limits{DailyIterations: 3000}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /admin/keys status = %d", resp.StatusCode)
+	}
+	var created createKeyResponse
+	decodeBody(t, resp, &created)
+
+	resp = doJSON(t, http.MethodPost, ts.URL+"/compute", "", computeRequest{Input: "00"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("missing key: status %d", resp.StatusCode)
+	}
+	// 默认的 2000 次迭代在配额之内，再提交一次就超出配额
+	resp = doJSON(t, http.MethodPost, ts.URL+"/compute", created.Key, computeRequest{Input: "00"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("authorized compute: status %d", resp.StatusCode)
+	}
+	resp = doJSON(t, http.MethodPost, ts.URL+"/compute", created.Key, computeRequest{Input: "00"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("compute over quota: status %d", resp.StatusCode)
+	}
+
+	resp = doJSON(t, http.MethodDelete, ts.URL+"/admin/keys/"+created.APIKey.ID, "root", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE key: status %d", resp.StatusCode)
+	}
+	resp = doJSON(t, http.MethodGet, ts.URL+"/jobs/missing", created.Key, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("revoked key: status %d", resp.StatusCode)
+	}
+	var keys []APIKey
+	decodeBody(t, doJSON(t, http.MethodGet, ts.URL+"/admin/keys", "root", nil), &keys)
+	if len(keys) != 1 || keys[0].Revoked.IsZero() {
+		t.Errorf("listed keys = %+v", keys)
+	}
+}
+
+// TestJobOwner 检查任务记在提交它的密钥名下，其他密钥查询与取消都返回 404
+func TestJobOwner(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 1 << 40})
+	k, _ := NewKeyring("")
+	alice, aliceKey, _ := k.Create("alice", Limits{})
+	bob, _, _ := k.Create("bob", Limits{})
+	ts := httptest.NewServer(k.Middleware(NewHTTPHandler(q)))
+	defer ts.Close()
+
+	resp := doJSON(t, http.MethodPost, ts.URL+"/compute", alice, computeRequest{Input: "00", Iterations: 1 << 40})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /compute status = %d", resp.StatusCode)
+	}
+	var job jobResponse
+	decodeBody(t, resp, &job)
+	if got, _ := q.Get(job.ID); got.Owner != aliceKey.ID {
+		t.Errorf("job owner = %q, want %q", got.Owner, aliceKey.ID)
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/jobs/" + job.ID},
+		{http.MethodGet, "/jobs/" + job.ID + "/proof"},
+		{http.MethodGet, "/jobs/" + job.ID + "/events"},
+		{http.MethodDelete, "/jobs/" + job.ID},
+	} {
+		resp := doJSON(t, req.method, ts.URL+req.path, bob, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s %s with another key: status %d", req.method, req.path, resp.StatusCode)
+		}
+	}
+	if got, _ := q.Get(job.ID); got.State.Finished() {
+		t.Fatalf("another key canceled the job: %s", got.State)
+	}
+
+	resp = doJSON(t, http.MethodGet, ts.URL+"/jobs/"+job.ID, alice, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET with the owner key: status %d", resp.StatusCode)
+	}
+	resp = doJSON(t, http.MethodDelete, ts.URL+"/jobs/"+job.ID, alice, nil)
+	decodeBody(t, resp, &job)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE with the owner key: status %d", resp.StatusCode)
+	}
+}
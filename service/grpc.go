@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/service/slothpb"
+)
+
+// GRPC 把 Queue 导出为 gRPC 服务 sloth.v1.Sloth (见 slothpb/sloth.proto):
+//
+//	SubmitCompute  提交计算任务
+//	GetJob         查询任务
+//	CancelJob      取消任务
+//	StreamProgress 服务端流: 发送任务的每次更新，任务结束后关闭
+//	Verify         按证明中声明的迭代次数验证
+//
+// 证明以 Proof.MarshalBinary 编码。Verify 拒绝超过 Config.MaxIterations 的证明，
+// 调用方需要自行检查 Proof.Iterations 是否满足要求
+type GRPC struct {
+	slothpb.UnimplementedSlothServer
+	q *Queue
+}
+
+// NewGRPCServer 返回注册了 q 的服务的 grpc.Server，opts 用于设置 TLS 等选项
+func NewGRPCServer(q *Queue, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	slothpb.RegisterSlothServer(srv, &GRPC{q: q})
+	return srv
+}
+
+func (g *GRPC) SubmitCompute(_ context.Context, req *slothpb.SubmitComputeRequest) (*slothpb.Job, error) {
+	// proto3 不区分空的与缺省的 bytes 字段，两者都是空输入
+	input := req.GetInput()
+	if input == nil {
+		input = []byte{}
+	}
+	if len(input) > MaxInputSize {
+		return nil, status.Errorf(codes.InvalidArgument, "input exceeds %d bytes", MaxInputSize)
+	}
+	job, err := g.q.SubmitPriority(input, req.GetIterations(), int(req.GetPriority()))
+	switch {
+	case errors.Is(err, ErrQueueFull) || errors.Is(err, ErrClosed):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return newPBJob(job)
+}
+
+func (g *GRPC) GetJob(_ context.Context, req *slothpb.GetJobRequest) (*slothpb.Job, error) {
+	job, err := g.q.Get(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return newPBJob(job)
+}
+
+func (g *GRPC) CancelJob(_ context.Context, req *slothpb.CancelJobRequest) (*slothpb.Job, error) {
+	job, err := g.q.Cancel(req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return newPBJob(job)
+}
+
+// StreamProgress 先发送版本大于 req.Version 的快照 (Version 为 0 时即当前快照)，之后每次更新发送一次，
+// 任务结束后返回。进度更新比客户端读取快时只发送最新的快照
+func (g *GRPC) StreamProgress(req *slothpb.StreamProgressRequest, stream grpc.ServerStreamingServer[slothpb.Job]) error {
+	ctx := stream.Context()
+	version := req.GetVersion()
+	if version == 0 {
+		// 新提交的任务版本为 0，Wait 会等到下一次更新，因此先发送当前快照
+		job, err := g.q.Get(req.GetId())
+		if err != nil {
+			return grpcError(err)
+		}
+		if err := g.send(stream, job); err != nil || job.State.Finished() {
+			return err
+		}
+		version = job.Version
+	}
+	for {
+		job, err := g.q.Wait(ctx, req.GetId(), version)
+		if err != nil {
+			return grpcError(err)
+		}
+		if err := g.send(stream, job); err != nil || job.State.Finished() {
+			return err
+		}
+		version = job.Version
+	}
+}
+
+func (g *GRPC) send(stream grpc.ServerStreamingServer[slothpb.Job], job Job) error {
+	pb, err := newPBJob(job)
+	if err != nil {
+		return err
+	}
+	return stream.Send(pb)
+}
+
+func (g *GRPC) Verify(ctx context.Context, req *slothpb.VerifyRequest) (*slothpb.VerifyResponse, error) {
+	if len(req.GetProof()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "proof cannot be empty")
+	}
+	var proof slothgo.Proof
+	if err := proof.UnmarshalBinary(req.GetProof()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	v, err := g.q.verifier(proof.Iterations)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &slothpb.VerifyResponse{}
+	resp.Valid, err = v.VerifyProofCtx(ctx, req.GetInput(), &proof)
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+// grpcError 把队列的错误转换为 gRPC 状态
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+var pbStates = map[State]slothpb.JobState{
+	Queued:   slothpb.JobState_JOB_STATE_QUEUED,
+	Running:  slothpb.JobState_JOB_STATE_RUNNING,
+	Done:     slothpb.JobState_JOB_STATE_DONE,
+	Failed:   slothpb.JobState_JOB_STATE_FAILED,
+	Canceled: slothpb.JobState_JOB_STATE_CANCELED,
+}
+
+// newPBJob 把任务快照转换为 gRPC 消息，零值的时间不设置
+func newPBJob(job Job) (*slothpb.Job, error) {
+	pb := &slothpb.Job{
+		Id:         job.ID,
+		State:      pbStates[job.State],
+		Input:      job.Input,
+		Iterations: job.Iterations,
+		Priority:   int64(job.Priority),
+		Progress:   job.Progress,
+		Error:      job.Error,
+		Version:    job.Version,
+		Created:    timestamppb.New(job.Created),
+	}
+	if job.State == Running {
+		pb.Eta = durationpb.New(job.ETA)
+	}
+	if job.Proof != nil {
+		proof, err := job.Proof.MarshalBinary()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode proof: %v", err)
+		}
+		pb.Proof = proof
+	}
+	if !job.Started.IsZero() {
+		pb.Started = timestamppb.New(job.Started)
+	}
+	if !job.Finished.IsZero() {
+		pb.Finished = timestamppb.New(job.Finished)
+	}
+	return pb, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/service/slothpb"
+)
+
+func newTestGRPCClient(t *testing.T, q *Queue) slothpb.SlothClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(q)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return slothpb.NewSlothClient(conn)
+}
+
+// TestGRPC 通过 gRPC 提交任务、以服务端流接收进度直到完成，再验证证明
+func TestGRPC(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1})
+	c := newTestGRPCClient(t, q)
+	ctx := context.Background()
+
+	job, err := c.SubmitCompute(ctx, &slothpb.SubmitComputeRequest{Input: []byte("grpc")})
+	if err != nil {
+		t.Fatalf("SubmitCompute failed: %v", err)
+	}
+	stream, err := c.StreamProgress(ctx, &slothpb.StreamProgressRequest{Id: job.Id})
+	if err != nil {
+		t.Fatalf("StreamProgress failed: %v", err)
+	}
+	var last *slothpb.Job
+	for {
+		next, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if last != nil && next.Version <= last.Version {
+			t.Fatalf("stream sent a stale version %d after %d", next.Version, last.Version)
+		}
+		last = next
+	}
+	if last == nil || last.State != slothpb.JobState_JOB_STATE_DONE {
+		t.Fatalf("stream ended with %v", last)
+	}
+
+	got, err := c.GetJob(ctx, &slothpb.GetJobRequest{Id: job.Id})
+	if err != nil || len(got.Proof) == 0 || got.Finished == nil {
+		t.Fatalf("GetJob = %v, %v", got, err)
+	}
+	if _, err := c.GetJob(ctx, &slothpb.GetJobRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetJob of a missing job returned %v", err)
+	}
+
+	reply, err := c.Verify(ctx, &slothpb.VerifyRequest{Input: []byte("grpc"), Proof: got.Proof})
+	if err != nil || !reply.Valid {
+		t.Fatalf("Verify = %v, %v", reply, err)
+	}
+	reply, err = c.Verify(ctx, &slothpb.VerifyRequest{Input: []byte("other"), Proof: got.Proof})
+	if err != nil || reply.Valid || reply.Error == "" {
+		t.Errorf("Verify of the wrong input = %v, %v", reply, err)
+	}
+
+	// 超过 MaxIterations 的证明不验证
+	var oversized slothgo.Proof
+	if err := oversized.UnmarshalBinary(got.Proof); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	oversized.Iterations = 1 << 40
+	data, err := oversized.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	_, err = c.Verify(ctx, &slothpb.VerifyRequest{Input: []byte("grpc"), Proof: data})
+	if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "iterations") {
+		t.Errorf("Verify of an oversized proof returned %v", err)
+	}
+}
+
+// TestGRPCStreamFinished 检查已经结束的任务只发送一次快照就关闭流
+func TestGRPCStreamFinished(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1})
+	c := newTestGRPCClient(t, q)
+	ctx := context.Background()
+
+	job, err := c.SubmitCompute(ctx, &slothpb.SubmitComputeRequest{Input: []byte("cancel"), Priority: 1})
+	if err != nil {
+		t.Fatalf("SubmitCompute failed: %v", err)
+	}
+	if _, err := c.CancelJob(ctx, &slothpb.CancelJobRequest{Id: job.Id}); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+	// 版本不会超过最大值，Wait 在任务结束时返回; 取消之前任务可能已经完成
+	final, err := q.Wait(ctx, job.Id, ^uint64(0))
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	stream, err := c.StreamProgress(ctx, &slothpb.StreamProgressRequest{Id: job.Id})
+	if err != nil {
+		t.Fatalf("StreamProgress failed: %v", err)
+	}
+	last, err := stream.Recv()
+	if err != nil || last.State != pbStates[final.State] || last.Version != final.Version {
+		t.Fatalf("Recv = %v, %v", last, err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("stream did not end after the final state: %v", err)
+	}
+
+	stream, err = c.StreamProgress(ctx, &slothpb.StreamProgressRequest{Id: "missing"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("StreamProgress of a missing job returned %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import "net/http"
+
+// healthResponse 是健康检查的响应
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+// NewHealthHandler 提供供编排系统探测的接口:
+//
+//	GET /healthz  进程存活即返回 200
+//	GET /readyz   ready 返回 nil 时返回 200，否则返回 503 与原因，负载均衡应暂时不向其发送请求
+//
+// ready 通常是 Queue.Ready，启动过程中恢复任务时可以传入返回错误的函数
+func NewHealthHandler(ready func() error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, healthResponse{Status: "ready"})
+	})
+	return mux
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHealth 检查存活与就绪接口，以及队列关闭后不再就绪
+func TestHealth(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1})
+	ts := httptest.NewServer(NewHealthHandler(q.Ready))
+	defer ts.Close()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		resp := mustGet(t, ts.URL+path)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d", path, resp.StatusCode)
+		}
+	}
+
+	q.Close()
+	if err := q.Ready(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Ready after Close = %v, want ErrClosed", err)
+	}
+	var body map[string]string
+	resp := mustGet(t, ts.URL+"/readyz")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz after Close status = %d", resp.StatusCode)
+	}
+	decodeBody(t, resp, &body)
+	if body["error"] == "" {
+		t.Errorf("GET /readyz after Close body = %v", body)
+	}
+	resp = mustGet(t, ts.URL+"/healthz")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz after Close status = %d", resp.StatusCode)
+	}
+}
+
+// TestQueueReadyFull 检查等待中的任务达到上限时不再就绪
+func TestQueueReadyFull(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxPending: 1, MaxIterations: 1 << 40})
+	q.Submit([]byte("running"), 1<<40)
+	for range 2 {
+		q.Submit([]byte("waiting"), 1<<40)
+	}
+	if err := q.Ready(); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Ready with a full queue = %v, want ErrQueueFull", err)
+	}
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// MaxInputSize 是 REST 接口接受的输入的最大字节数
+const MaxInputSize = 64 << 10
+
+// keepAlive 是事件流在没有事件时发送注释行的间隔，避免代理关闭空闲连接
+const keepAlive = 15 * time.Second
+
+// computeRequest 是 POST /compute 的请求体，input 为十六进制，iterations 为 0 时使用默认值
+// priority 越大越先计算，默认为 0
+type computeRequest struct {
+	Input      string `json:"input"`
+	Iterations uint64 `json:"iterations"`
+	Priority   int    `json:"priority,omitempty"`
+}
+
+// verifyRequest 是 POST /verify 的请求体
+type verifyRequest struct {
+	Input string         `json:"input"`
+	Proof *slothgo.Proof `json:"proof"`
+}
+
+// verifyResponse 是 POST /verify 的响应，error 是证明无效的原因
+type verifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// jobResponse 是任务的 JSON 表示，不包含输入与证明
+type jobResponse struct {
+	ID         string    `json:"id"`
+	State      State     `json:"state"`
+	Iterations uint64    `json:"iterations"`
+	Priority   int       `json:"priority"`
+	Progress   uint64    `json:"progress"`
+	Percent    float64   `json:"percent"`
+	ETASeconds float64   `json:"eta_seconds,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Version    uint64    `json:"version"`
+	Created    time.Time `json:"created"`
+	Started    time.Time `json:"started,omitzero"`
+	Finished   time.Time `json:"finished,omitzero"`
+}
+
+func newJobResponse(job Job) jobResponse {
+	return jobResponse{
+		ID:         job.ID,
+		State:      job.State,
+		Iterations: job.Iterations,
+		Priority:   job.Priority,
+		Progress:   job.Progress,
+		Percent:    float64(job.Progress) * 100 / float64(job.Iterations),
+		ETASeconds: job.ETA.Seconds(),
+		Error:      job.Error,
+		Version:    job.Version,
+		Created:    job.Created,
+		Started:    job.Started,
+		Finished:   job.Finished,
+	}
+}
+
+// NewHTTPHandler 把 q 暴露为 REST 接口:
+//
+//	POST   /compute          {"input": 十六进制, "iterations": n, "priority": k}，返回 202 与任务，Location 指向任务
+//	GET    /jobs/{id}        任务的状态与进度
+//	DELETE /jobs/{id}        取消任务，返回取消后的任务
+//	GET    /jobs/{id}/proof  已完成任务的证明，未完成时返回 409
+//	GET    /jobs/{id}/events 任务状态与进度的 Server-Sent Events 流，任务结束后关闭
+//	POST   /verify           {"input": 十六进制, "proof": 证明}，按证明声明的迭代次数验证
+//
+// 事件流中每个事件的名称是任务的状态 (queued、running、done、failed、canceled)，
+// 数据与 GET /jobs/{id} 的响应相同，事件 ID 是任务的 Version。
+// 经过 Keyring.Middleware 时，compute 与 verify 按迭代次数扣除密钥的配额，配额用完返回 429;
+// 任务记在提交它的密钥名下，用其他密钥访问 /jobs/{id} 及其子路径时与任务不存在一样返回 404
+func NewHTTPHandler(q *Queue) http.Handler {
+	h := &httpHandler{q: q}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /compute", h.handleCompute)
+	mux.HandleFunc("GET /jobs/{id}", h.handleJob)
+	mux.HandleFunc("DELETE /jobs/{id}", h.handleCancel)
+	mux.HandleFunc("GET /jobs/{id}/proof", h.handleProof)
+	mux.HandleFunc("GET /jobs/{id}/events", h.handleEvents)
+	mux.HandleFunc("POST /verify", h.handleVerify)
+	return mux
+}
+
+type httpHandler struct {
+	q *Queue
+}
+
+func (h *httpHandler) handleCompute(w http.ResponseWriter, r *http.Request) {
+	var req computeRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	input, err := decodeInput(req.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	iterations := req.Iterations
+	if iterations == 0 {
+		iterations = h.q.vdf.Iterations
+	}
+	refund, err := chargeRequest(r, iterations)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+	job, err := h.q.SubmitFor(requestKeyID(r), input, req.Iterations, req.Priority)
+	if err != nil {
+		refund()
+	}
+	switch {
+	case errors.Is(err, ErrQueueFull) || errors.Is(err, ErrClosed):
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, newJobResponse(job))
+}
+
+func (h *httpHandler) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, newJobResponse(job))
+}
+
+func (h *httpHandler) handleCancel(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	job, err := h.q.Cancel(job.ID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newJobResponse(job))
+}
+
+func (h *httpHandler) handleProof(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	switch job.State {
+	case Done:
+		writeJSON(w, http.StatusOK, job.Proof)
+	case Failed:
+		writeError(w, http.StatusConflict, fmt.Errorf("job failed: %s", job.Error))
+	case Canceled:
+		writeError(w, http.StatusConflict, errors.New("job was canceled"))
+	default:
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s", job.State))
+	}
+}
+
+// handleEvents 推送任务的每次更新，直到任务结束或客户端断开
+// 进度更新比客户端读取快时只推送最新的快照
+func (h *httpHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for {
+		if err := writeEvent(w, job.Version, string(job.State), newJobResponse(job)); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil || job.State.Finished() {
+			return
+		}
+		next, err := h.wait(r.Context(), job)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == nil:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+			continue
+		case err != nil:
+			return
+		}
+		job = next
+	}
+}
+
+// wait 等待任务的下一次更新，最多等待 keepAlive
+func (h *httpHandler) wait(ctx context.Context, job Job) (Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, keepAlive)
+	defer cancel()
+	return h.q.Wait(ctx, job.ID, job.Version)
+}
+
+func (h *httpHandler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	input, err := decodeInput(req.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Proof == nil {
+		writeError(w, http.StatusBadRequest, errors.New("proof cannot be nil"))
+		return
+	}
+	v, err := h.q.verifier(req.Proof.Iterations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := chargeRequest(r, req.Proof.Iterations); err != nil {
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+	var resp verifyResponse
+	resp.Valid, err = v.VerifyProofCtx(r.Context(), input, req.Proof)
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// lookup 按路径中的 id 查找请求所用密钥的任务，找不到或属于其他密钥时写入 404
+func (h *httpHandler) lookup(w http.ResponseWriter, r *http.Request) (Job, bool) {
+	job, err := h.q.Get(r.PathValue("id"))
+	if err == nil && job.Owner != requestKeyID(r) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return Job{}, false
+	}
+	return job, true
+}
+
+// readJSON 解析请求体，失败时写入 400
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	// 十六进制输入是原始大小的两倍，再为证明与其他字段留出余量
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4*MaxInputSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func decodeInput(s string) ([]byte, error) {
+	input, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("input must be hexadecimal: %w", err)
+	}
+	if len(input) > MaxInputSize {
+		return nil, fmt.Errorf("input exceeds %d bytes", MaxInputSize)
+	}
+	return input, nil
+}
+
+// writeEvent 写入一个 Server-Sent Events 事件，数据为 v 的单行 JSON
+func writeEvent(w http.ResponseWriter, id uint64, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
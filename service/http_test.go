@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func postJSON(t *testing.T, url string, v any) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(v)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	return resp
+}
+
+func decodeBody(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+// TestHTTP 检查提交、查询、取回证明与验证的完整流程
+func TestHTTP(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1})
+	ts := httptest.NewServer(NewHTTPHandler(q))
+	defer ts.Close()
+	input := []byte("rest")
+
+	resp := postJSON(t, ts.URL+"/compute", computeRequest{Input: hex.EncodeToString(input)})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /compute status = %d", resp.StatusCode)
+	}
+	location := resp.Header.Get("Location")
+	var job jobResponse
+	decodeBody(t, resp, &job)
+	if location != "/jobs/"+job.ID || job.Iterations != 2000 {
+		t.Fatalf("unexpected job %+v at %q", job, location)
+	}
+
+	waitFinished(t, q, job.ID)
+	resp, _ = http.Get(ts.URL + location)
+	decodeBody(t, resp, &job)
+	if job.State != Done || job.Percent != 100 || job.Finished.IsZero() {
+		t.Fatalf("unexpected finished job %+v", job)
+	}
+
+	resp, _ = http.Get(ts.URL + location + "/proof")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET proof status = %d", resp.StatusCode)
+	}
+	var proof slothgo.Proof
+	decodeBody(t, resp, &proof)
+
+	var verdict verifyResponse
+	decodeBody(t, postJSON(t, ts.URL+"/verify", verifyRequest{Input: hex.EncodeToString(input), Proof: &proof}), &verdict)
+	if !verdict.Valid {
+		t.Fatalf("proof did not verify: %s", verdict.Error)
+	}
+	decodeBody(t, postJSON(t, ts.URL+"/verify", verifyRequest{Input: "00", Proof: &proof}), &verdict)
+	if verdict.Valid || verdict.Error == "" {
+		t.Errorf("proof verified for the wrong input: %+v", verdict)
+	}
+
+	// 超过 MaxIterations 的证明在验证之前被拒绝
+	oversized := proof
+	oversized.Iterations = 1 << 40
+	resp = postJSON(t, ts.URL+"/verify", verifyRequest{Input: hex.EncodeToString(input), Proof: &oversized})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("oversized proof: status %d, want 400", resp.StatusCode)
+	}
+}
+
+// TestHTTPEvents 检查事件流按顺序推送进度，并在任务结束后关闭
+func TestHTTPEvents(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 1 << 20})
+	ts := httptest.NewServer(NewHTTPHandler(q))
+	defer ts.Close()
+
+	var job jobResponse
+	decodeBody(t, postJSON(t, ts.URL+"/compute", computeRequest{Input: "00", Iterations: 200000}), &job)
+	resp, err := http.Get(ts.URL + "/jobs/" + job.ID + "/events")
+	if err != nil {
+		t.Fatalf("GET events failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// 读到连接关闭为止，逐个解析 event 与 data 行
+	var events []string
+	var last jobResponse
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			events = append(events, strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			var next jobResponse
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &next); err != nil {
+				t.Fatalf("malformed data %q: %v", line, err)
+			}
+			if next.Version <= last.Version && len(events) > 1 || next.Percent < last.Percent {
+				t.Fatalf("events out of order: %+v after %+v", next, last)
+			}
+			last = next
+		}
+	}
+	if len(events) < 3 || events[len(events)-1] != string(Done) || last.Percent != 100 {
+		t.Fatalf("unexpected events %v, last %+v", events, last)
+	}
+
+	resp, _ = http.Get(ts.URL + "/jobs/00000000000000000000000000000000/events")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("events of unknown job status = %d", resp.StatusCode)
+	}
+}
+
+// TestHTTPErrors 检查错误请求的状态码
+func TestHTTPErrors(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 1 << 40})
+	ts := httptest.NewServer(NewHTTPHandler(q))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		body any
+		want int
+	}{
+		{computeRequest{Input: "zz"}, http.StatusBadRequest},
+		{map[string]any{"input": "00", "iterations": -1}, http.StatusBadRequest},
+		{map[string]any{"input": "00", "extra": 1}, http.StatusBadRequest},
+	} {
+		resp := postJSON(t, ts.URL+"/compute", tc.body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("POST /compute %+v: status %d, want %d", tc.body, resp.StatusCode, tc.want)
+		}
+	}
+
+	resp, _ := http.Get(ts.URL + "/jobs/missing")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing job: status %d, want 404", resp.StatusCode)
+	}
+
+	// 迭代次数很大的任务在测试期间不会完成
+	resp = postJSON(t, ts.URL+"/compute", computeRequest{Input: "00", Iterations: 1 << 40})
+	var job jobResponse
+	decodeBody(t, resp, &job)
+	resp, _ = http.Get(ts.URL + "/jobs/" + job.ID + "/proof")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("unfinished proof: status %d, want 409", resp.StatusCode)
+	}
+
+	// 取消之后任务结束，证明仍然不可用
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/jobs/"+job.ID, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE status %d, want 200", resp.StatusCode)
+	}
+	waitFinished(t, q, job.ID)
+	decodeBody(t, mustGet(t, ts.URL+"/jobs/"+job.ID), &job)
+	if job.State != Canceled {
+		t.Errorf("canceled job is %s", job.State)
+	}
+	req, _ = http.NewRequest(http.MethodDelete, ts.URL+"/jobs/missing", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("DELETE missing job: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func mustGet(t *testing.T, url string) *http.Response {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	return resp
+}
@@ -0,0 +1,512 @@
+// Package service 实现 slothd 的证明任务队列及其 gRPC 与 REST 接口
+// 集群中的其他服务提交输入后立即得到任务 ID，由队列中的工作协程顺序计算，
+// 调用方通过任务 ID 查询状态、等待进度更新并取回证明，无需链接本库; 任务可以通过 JobStore 持久化
+// 任务由 scheduler.Scheduler 按优先级调度，同时计算的任务数受 Config.Workers 限制
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/metrics"
+	"github.com/alan22333/sloth_go/scheduler"
+)
+
+var (
+	// ErrNotFound 表示任务不存在或已过期被清理
+	ErrNotFound = errors.New("service: job not found")
+	// ErrQueueFull 表示等待中的任务已达到上限
+	ErrQueueFull = errors.New("service: queue is full")
+	// ErrClosed 表示队列已关闭
+	ErrClosed = errors.New("service: queue is closed")
+)
+
+// State 是任务的状态
+type State string
+
+const (
+	Queued   State = "queued"
+	Running  State = "running"
+	Done     State = "done"
+	Failed   State = "failed"
+	Canceled State = "canceled"
+)
+
+// Finished 报告任务是否已经结束 (成功、失败或被取消)
+func (s State) Finished() bool {
+	return s == Done || s == Failed || s == Canceled
+}
+
+// Job 是任务在某一时刻的快照，Input 与 Proof 只读
+type Job struct {
+	ID         string
+	State      State
+	Input      []byte
+	Iterations uint64
+	Priority   int           // 越大越先计算，同一优先级按提交顺序
+	Owner      string        // 提交任务的 API 密钥 ID，没有经过 Keyring.Middleware 提交时为空
+	Progress   uint64        // 已完成的迭代次数
+	ETA        time.Duration // 按观测速度估计的剩余时间，只在 Running 时有意义
+	Proof      *slothgo.Proof
+	Error      string
+	Version    uint64 // 状态或进度每变化一次加一
+	Created    time.Time
+	Started    time.Time
+	Finished   time.Time
+}
+
+// Config 是任务队列的配置，零值字段使用括号中的默认值
+type Config struct {
+	Workers       int                    // 同时计算的任务数 (CPU 核数)，每个任务只占用一个核
+	MaxPending    int                    // 等待中的任务上限 (1024)
+	MaxIterations uint64                 // 单个任务允许的最大迭代次数 (实例的迭代次数)
+	Retention     time.Duration          // 已结束的任务保留多久 (24 小时)
+	Options       []slothgo.Option       // 为每个任务构造实例时追加的选项，例如 slothgo.WithMetrics
+	Store         JobStore               // 任务的持久化存储 (不持久化)
+	Metrics       metrics.QueueCollector // 接收等待与运行中的任务数 (不报告)
+
+	// JournalDir 不为空时运行中的任务把进度写入该目录中的计算日志 (见 slothgo.Journal)，
+	// 队列关闭或进程重启后恢复的任务从日志继续而不是从头计算; JournalKey 是日志的 HMAC 密钥，
+	// JournalPeriod 是两次写入日志的最小间隔 (1 分钟)。使用日志时证明不包含 Options 要求的区段检查点等可选字段
+	JournalDir    string
+	JournalKey    []byte
+	JournalPeriod time.Duration
+}
+
+// Queue 是证明任务队列，可以被并发调用
+// 每个任务使用与 vdf 相同的参数 (迭代次数除外)，因此 vdf 必须使用内置的置换
+type Queue struct {
+	vdf    *slothgo.Sloth
+	params *slothgo.Params
+	cfg    Config
+	sched  *scheduler.Scheduler
+
+	// ctx 在 Close 时结束，用于区分队列关闭与任务被取消
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+}
+
+// entry 是队列内部保存的任务，changed 在任务每次更新时关闭并替换，用于唤醒等待者
+// task 是任务在调度器中的句柄，任务结束后不再使用
+type entry struct {
+	job     Job
+	changed chan struct{}
+	task    *scheduler.Task
+}
+
+// NewQueue 创建任务队列并启动工作协程，使用完毕后应调用 Close
+// 配置了 Store 时先恢复保存的任务: 已结束的任务原样保留，未结束的任务按原来的优先级与提交顺序重新排队并从头计算
+func NewQueue(vdf *slothgo.Sloth, cfg Config) (*Queue, error) {
+	if vdf == nil {
+		return nil, errors.New("vdf cannot be nil")
+	}
+	params := vdf.Params()
+	if _, err := slothgo.NewPermutation(params.Permutation, params.P); err != nil {
+		return nil, err
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 1024
+	}
+	if cfg.MaxIterations == 0 {
+		cfg.MaxIterations = vdf.Iterations
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	if cfg.JournalDir != "" {
+		if len(cfg.JournalKey) == 0 {
+			return nil, errors.New("journal key cannot be empty")
+		}
+		if cfg.JournalPeriod <= 0 {
+			cfg.JournalPeriod = time.Minute
+		}
+		if err := os.MkdirAll(cfg.JournalDir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	var saved []Job
+	if cfg.Store != nil {
+		var err error
+		if saved, err = cfg.Store.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load jobs: %w", err)
+		}
+	}
+	q := &Queue{
+		vdf:    vdf,
+		params: params,
+		cfg:    cfg,
+		sched:  scheduler.New(scheduler.Config{Concurrency: cfg.Workers, Metrics: cfg.Metrics}),
+		jobs:   make(map[string]*entry),
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.restore(saved); err != nil {
+		q.sched.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// restore 把保存的任务放回队列，未结束的任务重置为排队状态，调用方必须持有 q.mu
+// 恢复的任务不受 MaxPending 限制
+func (q *Queue) restore(saved []Job) error {
+	slices.SortFunc(saved, func(a, b Job) int { return a.Created.Compare(b.Created) })
+	for _, job := range saved {
+		e := &entry{job: job, changed: make(chan struct{})}
+		if !job.State.Finished() {
+			e.job.State = Queued
+			e.job.Progress, e.job.ETA, e.job.Started = 0, 0, time.Time{}
+			if err := q.schedule(e); err != nil {
+				return err
+			}
+		}
+		q.jobs[job.ID] = e
+	}
+	return nil
+}
+
+// schedule 把任务交给调度器，调用方必须持有 q.mu
+func (q *Queue) schedule(e *entry) error {
+	id := e.job.ID
+	task, err := q.sched.Submit(e.job.Priority, func(ctx context.Context) error {
+		q.run(ctx, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	e.task = task
+	return nil
+}
+
+// VDF 返回队列使用的实例，调用方可以用它在本地验证证明
+func (q *Queue) VDF() *slothgo.Sloth {
+	return q.vdf
+}
+
+// Close 取消正在运行的任务并等待它们返回，之后的 Submit 返回 ErrClosed
+// 因关闭而中断的任务不记为失败或取消，配置了 Store 时会在下次启动后重新计算
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.cancel()
+	q.mu.Unlock()
+	q.sched.Close()
+}
+
+// Shutdown 与 Close 相同，但最多等待到 ctx 结束: 不再接受新任务，取消运行中的任务并等待它们
+// 写入计算日志与保存状态。ctx 先结束时返回 ctx.Err()，剩余的任务在后台继续退出
+func (q *Queue) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// verifier 返回验证 iterations 次迭代的证明所用的实例
+// 迭代次数由客户端声明，超过 MaxIterations 时拒绝，否则一个请求就能长时间占用 CPU
+func (q *Queue) verifier(iterations uint64) (*slothgo.Sloth, error) {
+	if iterations == 0 || iterations > q.cfg.MaxIterations {
+		return nil, fmt.Errorf("iterations must be in [1, %d]", q.cfg.MaxIterations)
+	}
+	return q.vdf.WithIterations(iterations)
+}
+
+// Submit 以优先级 0 提交一个计算任务，iterations 为 0 时使用实例的迭代次数
+func (q *Queue) Submit(input []byte, iterations uint64) (Job, error) {
+	return q.SubmitPriority(input, iterations, 0)
+}
+
+// SubmitPriority 与 Submit 相同，但指定任务的优先级，优先级越大越先计算
+func (q *Queue) SubmitPriority(input []byte, iterations uint64, priority int) (Job, error) {
+	return q.SubmitFor("", input, iterations, priority)
+}
+
+// SubmitFor 与 SubmitPriority 相同，但把任务记在 owner (API 密钥 ID) 名下，REST 接口只允许同一密钥访问该任务
+func (q *Queue) SubmitFor(owner string, input []byte, iterations uint64, priority int) (Job, error) {
+	if input == nil {
+		return Job{}, errors.New("input cannot be nil")
+	}
+	if iterations == 0 {
+		iterations = q.vdf.Iterations
+	}
+	if iterations > q.cfg.MaxIterations {
+		return Job{}, fmt.Errorf("iterations must be in [1, %d]", q.cfg.MaxIterations)
+	}
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now()
+	e := &entry{
+		job: Job{
+			ID:         id,
+			State:      Queued,
+			Input:      append([]byte(nil), input...),
+			Iterations: iterations,
+			Priority:   priority,
+			Owner:      owner,
+			Created:    now,
+		},
+		changed: make(chan struct{}),
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ctx.Err() != nil {
+		return Job{}, ErrClosed
+	}
+	q.prune(now)
+	if q.sched.Stats().Pending >= q.cfg.MaxPending {
+		return Job{}, ErrQueueFull
+	}
+	if err := q.save(e.job); err != nil {
+		return Job{}, fmt.Errorf("failed to save job: %w", err)
+	}
+	if err := q.schedule(e); err != nil {
+		q.deleteSaved(id)
+		return Job{}, err
+	}
+	q.jobs[id] = e
+	return e.job, nil
+}
+
+// Ready 返回 nil 表示队列可以接受新任务，否则返回 ErrClosed 或 ErrQueueFull
+func (q *Queue) Ready() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ctx.Err() != nil {
+		return ErrClosed
+	}
+	if q.sched.Stats().Pending >= q.cfg.MaxPending {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// Cancel 取消任务: 等待中的任务立即结束，运行中的任务在下一次检查取消时结束，返回此时的快照
+// 已经结束的任务不受影响
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	e, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	task := e.task
+	q.mu.Unlock()
+	if task != nil {
+		task.Cancel()
+		if errors.Is(task.Err(), scheduler.ErrCanceled) {
+			// 任务还没有开始运行，run 不会被调用
+			q.update(id, true, func(j *Job) {
+				j.State = Canceled
+				j.Finished = time.Now()
+			})
+			q.removeJournal(id)
+		}
+	}
+	return q.Get(id)
+}
+
+// Get 返回任务的当前快照
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return e.job, nil
+}
+
+// Wait 阻塞直到任务的 Version 大于 version 或任务已经结束，返回此时的快照
+// 调用方以上一次得到的 Version 反复调用即可逐个接收进度更新; ctx 结束时返回 ctx.Err()
+func (q *Queue) Wait(ctx context.Context, id string, version uint64) (Job, error) {
+	for {
+		q.mu.Lock()
+		e, ok := q.jobs[id]
+		if !ok {
+			q.mu.Unlock()
+			return Job{}, ErrNotFound
+		}
+		job, changed := e.job, e.changed
+		q.mu.Unlock()
+		if job.Version > version || job.State.Finished() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-q.ctx.Done():
+			return job, ErrClosed
+		case <-changed:
+		}
+	}
+}
+
+// update 在持有锁的情况下修改任务并唤醒所有等待者，persist 为 true 时同时保存到 Store
+// 保存失败只影响重启后的恢复 (任务会被重新计算)，不影响内存中的状态
+func (q *Queue) update(id string, persist bool, fn func(*Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, ok := q.jobs[id]
+	if !ok {
+		return
+	}
+	fn(&e.job)
+	e.job.Version++
+	close(e.changed)
+	e.changed = make(chan struct{})
+	if persist {
+		q.save(e.job)
+	}
+}
+
+// save 在配置了 Store 时保存任务
+func (q *Queue) save(job Job) error {
+	if q.cfg.Store == nil {
+		return nil
+	}
+	return q.cfg.Store.Save(job)
+}
+
+// prune 删除结束时间早于保留期限的任务，调用方必须持有 q.mu
+func (q *Queue) prune(now time.Time) {
+	for id, e := range q.jobs {
+		if e.job.State.Finished() && now.Sub(e.job.Finished) > q.cfg.Retention {
+			delete(q.jobs, id)
+			q.deleteSaved(id)
+		}
+	}
+}
+
+// deleteSaved 在配置了 Store 时删除保存的任务
+func (q *Queue) deleteSaved(id string) {
+	if q.cfg.Store != nil {
+		q.cfg.Store.Delete(id)
+	}
+}
+
+// run 在调度器分配的名额中计算一个任务，进度通过 WithProgressInfo 写回任务
+// ctx 在任务被取消或队列关闭时结束
+func (q *Queue) run(ctx context.Context, id string) {
+	job, err := q.Get(id)
+	if err != nil {
+		return
+	}
+	q.update(id, true, func(j *Job) {
+		j.State = Running
+		j.Started = time.Now()
+	})
+
+	proof, err := q.compute(ctx, job, func(pi slothgo.ProgressInfo) {
+		q.update(id, false, func(j *Job) {
+			j.Progress = pi.Done
+			j.ETA = pi.ETA
+		})
+	})
+	if err != nil && q.ctx.Err() != nil {
+		// 队列正在关闭: 保存的状态仍是 Running，重启后重新排队，配置了 JournalDir 时从日志继续
+		q.update(id, false, func(j *Job) {
+			j.State = Queued
+			j.Progress, j.ETA = 0, 0
+		})
+		return
+	}
+	if err != nil {
+		q.removeJournal(id)
+	}
+	q.update(id, true, func(j *Job) {
+		j.Finished = time.Now()
+		j.ETA = 0
+		if err != nil && ctx.Err() != nil {
+			j.State = Canceled
+			return
+		}
+		if err != nil {
+			j.State = Failed
+			j.Error = err.Error()
+			return
+		}
+		j.State = Done
+		j.Progress = j.Iterations
+		j.Proof = proof
+	})
+}
+
+// compute 以任务的迭代次数构造实例并计算证明
+// 素数已经在构造 q.vdf 时检验过，这里跳过素性检验并复用其算术后端
+func (q *Queue) compute(ctx context.Context, job Job, progress func(slothgo.ProgressInfo)) (*slothgo.Proof, error) {
+	params := *q.params
+	params.Iterations = job.Iterations
+	opts := append([]slothgo.Option{
+		slothgo.WithPrimalityCheck(slothgo.SkipPrimalityCheck),
+		slothgo.WithField(q.vdf.Field),
+		slothgo.WithProgressInfo(progress, 0),
+	}, q.cfg.Options...)
+	vdf, err := slothgo.NewFromParams(&params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if q.cfg.JournalDir == "" {
+		return vdf.ComputeProofCtx(ctx, job.Input)
+	}
+	j, err := slothgo.NewJournal(q.journalPath(job.ID), q.cfg.JournalKey, q.cfg.JournalPeriod)
+	if err != nil {
+		return nil, err
+	}
+	hash, witness, err := vdf.ComputeJournaled(ctx, job.Input, j)
+	if errors.Is(err, slothgo.ErrJournalCorrupt) {
+		// 日志损坏时丢弃日志从头计算
+		if err := j.Remove(); err != nil {
+			return nil, err
+		}
+		hash, witness, err = vdf.ComputeJournaled(ctx, job.Input, j)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vdf.NewProof(hash, witness), nil
+}
+
+// journalPath 返回任务的计算日志路径
+func (q *Queue) journalPath(id string) string {
+	return filepath.Join(q.cfg.JournalDir, id+".journal")
+}
+
+// removeJournal 在配置了 JournalDir 时删除任务的计算日志
+func (q *Queue) removeJournal(id string) {
+	if q.cfg.JournalDir != "" {
+		os.Remove(q.journalPath(id))
+	}
+}
+
+// newJobID 返回 128 位随机数的十六进制表示
+func newJobID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func newTestQueue(t *testing.T, cfg Config) *Queue {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 2000)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	q, err := NewQueue(vdf, cfg)
+	if err != nil {
+		t.Fatalf("NewQueue failed: %v", err)
+	}
+	t.Cleanup(q.Close)
+	return q
+}
+
+// waitFinished 反复调用 Wait 直到任务结束，返回看到的全部快照
+func waitFinished(t *testing.T, q *Queue, id string) []Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var seen []Job
+	var version uint64
+	for {
+		job, err := q.Wait(ctx, id, version)
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		seen = append(seen, job)
+		if job.State.Finished() {
+			return seen
+		}
+		version = job.Version
+	}
+}
+
+// TestQueue 检查任务从排队到完成的状态变化，以及结果与直接计算一致
+func TestQueue(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 5000})
+	job, err := q.Submit([]byte("hello"), 3000)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.State != Queued || job.Iterations != 3000 || len(job.ID) != 32 {
+		t.Fatalf("unexpected submitted job %+v", job)
+	}
+
+	seen := waitFinished(t, q, job.ID)
+	last := seen[len(seen)-1]
+	if last.State != Done || last.Proof == nil || last.Progress != 3000 || last.Finished.Before(last.Started) {
+		t.Fatalf("unexpected finished job %+v", last)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i].Version <= seen[i-1].Version || seen[i].Progress < seen[i-1].Progress {
+			t.Fatalf("updates out of order: %+v then %+v", seen[i-1], seen[i])
+		}
+	}
+
+	v, _ := q.VDF().WithIterations(3000)
+	if ok, err := v.VerifyProof([]byte("hello"), last.Proof); !ok {
+		t.Errorf("proof does not verify: %v", err)
+	}
+	if got, err := q.Get(job.ID); err != nil || got.Version != last.Version {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+
+	// 默认迭代次数
+	job, _ = q.Submit([]byte("default"), 0)
+	if job.Iterations != 2000 {
+		t.Errorf("default iterations = %d, want 2000", job.Iterations)
+	}
+}
+
+// TestQueueErrors 检查参数校验、队列上限与不存在的任务
+func TestQueueErrors(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxPending: 1})
+	if _, err := q.Submit(nil, 0); err == nil {
+		t.Error("nil input was accepted")
+	}
+	if _, err := q.Submit([]byte("x"), 2001); err == nil {
+		t.Error("iterations above the limit were accepted")
+	}
+	if _, err := q.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) = %v, want ErrNotFound", err)
+	}
+
+	// 唯一的工作协程可能已经取走第一个任务，所以最多再提交两个就会填满队列
+	var full bool
+	for range 3 {
+		if _, err := q.Submit([]byte("x"), 0); errors.Is(err, ErrQueueFull) {
+			full = true
+			break
+		}
+	}
+	if !full {
+		t.Error("queue never reported ErrQueueFull")
+	}
+
+	q.Close()
+	if _, err := q.Submit([]byte("x"), 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close = %v, want ErrClosed", err)
+	}
+}
+
+// TestQueuePriority 检查高优先级的任务先计算，以及取消等待中与运行中的任务
+func TestQueuePriority(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 1 << 40})
+	// 第一个任务在测试期间不会完成，占住唯一的名额直到被取消
+	blocker, _ := q.Submit([]byte("blocker"), 1<<40)
+	low, _ := q.SubmitPriority([]byte("low"), 0, 0)
+	dropped, _ := q.SubmitPriority([]byte("dropped"), 0, 0)
+	high, _ := q.SubmitPriority([]byte("high"), 0, 5)
+
+	job, err := q.Cancel(dropped.ID)
+	if err != nil || job.State != Canceled || job.Finished.IsZero() {
+		t.Fatalf("Cancel of a queued job = %+v, %v", job, err)
+	}
+	if _, err := q.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := q.Cancel(blocker.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if seen := waitFinished(t, q, blocker.ID); seen[len(seen)-1].State != Canceled {
+		t.Fatalf("running job ended as %s", seen[len(seen)-1].State)
+	}
+
+	lowJob := waitFinished(t, q, low.ID)
+	highJob := waitFinished(t, q, high.ID)
+	last := func(seen []Job) Job { return seen[len(seen)-1] }
+	if last(lowJob).State != Done || last(highJob).State != Done {
+		t.Fatalf("jobs ended as %s and %s", last(lowJob).State, last(highJob).State)
+	}
+	if last(lowJob).Started.Before(last(highJob).Finished) {
+		t.Error("lower priority job started before the higher priority job finished")
+	}
+	if job, _ := q.Get(dropped.ID); job.State != Canceled || !job.Started.IsZero() {
+		t.Errorf("canceled queued job was run: %+v", job)
+	}
+}
+
+// TestQueuePrune 检查过期的已结束任务被清理
+func TestQueuePrune(t *testing.T) {
+	q := newTestQueue(t, Config{Workers: 1, Retention: time.Millisecond})
+	job, _ := q.Submit([]byte("old"), 10)
+	waitFinished(t, q, job.ID)
+	time.Sleep(5 * time.Millisecond)
+	q.Submit([]byte("new"), 10)
+	if _, err := q.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired job was not pruned: %v", err)
+	}
+}
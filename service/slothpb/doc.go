@@ -0,0 +1,5 @@
+// Package slothpb 是 slothd 的 gRPC 接口 (sloth.proto) 生成的消息类型与桩代码，服务端实现见 service.GRPC。
+// 修改 sloth.proto 之后用 protoc-gen-go 与 protoc-gen-go-grpc 重新生成
+package slothpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative sloth.proto
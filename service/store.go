@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+)
+
+// JobStore 持久化任务，使队列在进程重启后恢复; 实现必须可以被并发调用
+// 队列只在任务状态变化时保存 (提交、开始、结束)，不保存进度
+type JobStore interface {
+	// Save 保存任务的快照，覆盖同一 ID 的旧快照
+	Save(job Job) error
+	// Load 返回保存的全部任务
+	Load() ([]Job, error)
+	// Delete 删除任务，任务不存在时不返回错误
+	Delete(id string) error
+}
+
+// FileStore 是把每个任务保存为目录中一个 JSON 文件的 JobStore
+// 写入先写入并同步临时文件再重命名，进程在写入中途退出不会留下损坏的任务
+type FileStore struct {
+	dir string
+}
+
+var _ JobStore = (*FileStore)(nil)
+
+// NewFileStore 使用目录 dir 保存任务，目录不存在时创建
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Save(job Job) error {
+	path, err := s.path(job.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data)
+}
+
+func (s *FileStore) Load() ([]Job, error) {
+	names, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(names))
+	for _, name := range names {
+		if !validJobID(strings.TrimSuffix(filepath.Base(name), ".json")) {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *FileStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path 返回任务文件的路径，只接受 newJobID 格式的 ID，避免路径穿越
+func (s *FileStore) path(id string) (string, error) {
+	if !validJobID(id) {
+		return "", fmt.Errorf("invalid job id %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// validJobID 报告 id 是否是 32 个小写十六进制字符
+func validJobID(id string) bool {
+	if len(id) != 32 || strings.ToLower(id) != id {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// TestFileStore 检查保存、加载、覆盖与删除
+func TestFileStore(t *testing.T) {
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	id, _ := newJobID()
+	job := Job{ID: id, State: Queued, Input: []byte("x"), Iterations: 10, Created: time.Now()}
+	if err := s.Save(job); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	job.State = Running
+	s.Save(job)
+	jobs, err := s.Load()
+	if err != nil || len(jobs) != 1 || jobs[0].State != Running || string(jobs[0].Input) != "x" {
+		t.Fatalf("Load = %+v, %v", jobs, err)
+	}
+	if err := s.Delete(id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Delete(id); err != nil {
+		t.Errorf("Delete of a missing job failed: %v", err)
+	}
+	if jobs, _ := s.Load(); len(jobs) != 0 {
+		t.Errorf("Load after Delete = %+v", jobs)
+	}
+	if err := s.Save(Job{ID: "../escape"}); err == nil {
+		t.Error("Save accepted an invalid id")
+	}
+}
+
+// TestQueueRestart 检查关闭时被取消的任务不记为失败，重启后已完成的任务保留、未完成的任务重新计算
+func TestQueueRestart(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	q := newTestQueue(t, Config{Workers: 1, MaxIterations: 1 << 40, Store: store})
+	done, _ := q.Submit([]byte("done"), 100)
+	waitFinished(t, q, done.ID)
+
+	slow, _ := q.Submit([]byte("slow"), 1<<40)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for job, _ := q.Get(slow.ID); job.State != Running; {
+		if job, err = q.Wait(ctx, slow.ID, job.Version); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	q.Close()
+
+	jobs, _ := store.Load()
+	states := make(map[string]State)
+	for _, job := range jobs {
+		states[job.ID] = job.State
+	}
+	if states[done.ID] != Done || states[slow.ID] != Running {
+		t.Fatalf("saved states = %v", states)
+	}
+
+	// 把被中断的任务改为很短的计算，模拟重启后完成
+	for _, job := range jobs {
+		if job.ID == slow.ID {
+			job.Iterations = 100
+			store.Save(job)
+		}
+	}
+	vdf, _ := slothgo.New(q.VDF().P, 2000)
+	q2, err := NewQueue(vdf, Config{Workers: 1, Store: store})
+	if err != nil {
+		t.Fatalf("NewQueue failed: %v", err)
+	}
+	defer q2.Close()
+	if job, err := q2.Get(done.ID); err != nil || job.State != Done || job.Proof == nil {
+		t.Fatalf("finished job was not restored: %+v, %v", job, err)
+	}
+	seen := waitFinished(t, q2, slow.ID)
+	if last := seen[len(seen)-1]; last.State != Done {
+		t.Fatalf("interrupted job did not complete: %+v", last)
+	}
+	v, _ := vdf.WithIterations(100)
+	job, _ := q2.Get(slow.ID)
+	if ok, err := v.VerifyProof([]byte("slow"), job.Proof); !ok {
+		t.Errorf("restored job produced an invalid proof: %v", err)
+	}
+}
+
+// TestQueueShutdownJournal 检查 Shutdown 时运行中的任务写入计算日志，重启后从日志继续并得到有效的证明
+func TestQueueShutdownJournal(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileStore(filepath.Join(dir, "jobs"))
+	cfg := Config{
+		Workers:       1,
+		MaxIterations: 1 << 20,
+		Store:         store,
+		JournalDir:    filepath.Join(dir, "journals"),
+		JournalKey:    []byte("secret"),
+		JournalPeriod: time.Hour,
+	}
+	const iterations = 400000
+	q := newTestQueue(t, cfg)
+	job, _ := q.Submit([]byte("long"), iterations)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var err error
+	for job.Progress < iterations/4 {
+		if job, err = q.Wait(ctx, job.ID, job.Version); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if err := q.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := q.Submit([]byte("late"), 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Shutdown = %v, want ErrClosed", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.JournalDir, job.ID+".journal")); err != nil {
+		t.Fatalf("no journal was written on shutdown: %v", err)
+	}
+
+	q2, err := NewQueue(q.VDF(), cfg)
+	if err != nil {
+		t.Fatalf("NewQueue failed: %v", err)
+	}
+	defer q2.Close()
+	seen := waitFinished(t, q2, job.ID)
+	last := seen[len(seen)-1]
+	if last.State != Done {
+		t.Fatalf("resumed job ended as %s: %s", last.State, last.Error)
+	}
+	// 从日志继续时第一次报告的进度不小于中断前的进度
+	for _, s := range seen {
+		if s.State == Running && s.Progress > 0 {
+			if s.Progress < iterations/4 {
+				t.Errorf("job restarted from scratch: first progress %d", s.Progress)
+			}
+			break
+		}
+	}
+	v, _ := q2.VDF().WithIterations(iterations)
+	if ok, err := v.VerifyProof([]byte("long"), last.Proof); !ok {
+		t.Errorf("resumed job produced an invalid proof: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.JournalDir, job.ID+".journal")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal was not removed after completion: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// TLSFiles 是 slothd 的证书文件，ClientCAFile 不为空时要求客户端出示由其中的 CA 签发的证书 (mTLS)
+type TLSFiles struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// CertReloader 持有当前的证书与客户端 CA，Reload 之后新的连接使用新文件，已建立的连接不受影响
+// 证书轮换时不需要重启服务，正在计算的任务不会丢失
+type CertReloader struct {
+	files TLSFiles
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+}
+
+// NewCertReloader 加载 files 中的证书
+func NewCertReloader(files TLSFiles) (*CertReloader, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+	r := &CertReloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取证书文件，失败时保留原来的证书
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return err
+	}
+	var pool *x509.CertPool
+	if r.files.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.files.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%s: no certificates found", r.files.ClientCAFile)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCA = &cert, pool
+	return nil
+}
+
+// TLSConfig 返回每次握手都使用当前证书的服务端配置，最低版本为 TLS 1.2
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+			}
+			if r.clientCA != nil {
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				cfg.ClientCAs = r.clientCA
+			}
+			return cfg, nil
+		},
+	}
+}
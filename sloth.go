@@ -2,24 +2,50 @@ package slothgo
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"hash"
 	"math/big"
+	"time"
+
+	"github.com/alan22333/sloth_go/group"
+	"github.com/alan22333/sloth_go/metrics"
 )
 
 // Sloth 结构体持有 VDF 的所有参数
 type Sloth struct {
-	P          *big.Int // 大素数模数, p ≡ 3 (mod 4)
-	Iterations int64    // 迭代次数 (延迟参数)
+	P          *big.Int // 大素数模数, p ≡ 3 (mod 4) 时使用最快的开方路径
+	Iterations uint64   // 迭代次数 (延迟参数)
 	HashFunc   func() hash.Hash
+	Field      group.Field // F_p 上的算术后端，默认由 group.NewField 按 p 的大小选择
+
+	perm Permutation // 每一轮使用的置换 τ，默认为 NewSqrtPermutation
+	hash HashID      // HashFunc 对应的哈希函数标识，写入参数指纹
+	tag  []byte      // 应用相关的域标签，见 WithDomainTag
+	bind bool        // 最终哈希是否绑定参数指纹，见 WithParamBinding
+	xmd  bool        // 是否使用 RFC 9380 的 hash_to_field 派生 w₀，见 WithHashToField
 
-	// 预计算的值，用于加速
-	sqrtExp *big.Int // (p+1)/4 用于计算平方根
+	// 可选配置，见 options.go
+	newPerm           PermutationFactory // 构造置换 τ 的函数
+	primality         PrimalityCheck     // New 对 p 执行的素性检验
+	progress          func(ProgressInfo) // 进度回调
+	progressStride    uint64             // 进度回调的调用间隔
+	progressStrideSet bool               // progressStride 是否由调用方指定，否则随迭代次数取 1%
+	segmentInterval   uint64             // 证明中记录中间值的间隔
+	accel             Accelerator        // 批量工作负载的执行后端，见 WithAccelerator
+	metrics           metrics.Collector  // 监控收集器，见 WithMetrics
+	verifyCache       *VerifyCache       // 已验证证明的缓存，见 WithVerifyCache
+	zeroize           bool               // 是否清零临时缓冲区，见 WithZeroize
 }
 
+// ctxCheckInterval 迭代循环中每隔多少次检查一次 ctx 是否已取消
+const ctxCheckInterval = 1024
+
 // big.Int 常量
 var (
 	bigZero  = big.NewInt(0)
@@ -32,29 +58,141 @@ var (
 // New 创建一个新的 Sloth VDF 实例
 // p: 十六进制表示的大素数
 // iterations: 延迟循环的次数
-func New(p *big.Int, iterations int64) (*Sloth, error) {
-	if iterations <= 0 {
-		return nil, errors.New("iterations must be positive")
+// opts: 可选配置, 例如 WithProgress
+func New(p *big.Int, iterations uint64, opts ...Option) (*Sloth, error) {
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
 	}
 
-	// 验证 p 是一个素数且 p ≡ 3 (mod 4)
-	if !p.ProbablyPrime(20) {
-		return nil, errors.New("p is not a prime number")
-	}
-	if new(big.Int).Mod(p, bigFour).Cmp(bigThree) != 0 {
-		return nil, errors.New("p must be congruent to 3 (mod 4)")
+	if p == nil {
+		return nil, errors.New("p cannot be nil")
 	}
 
-	// 预计算 (p+1)/4 用于平方根
-	sqrtExp := new(big.Int).Add(p, bigOne)
-	sqrtExp.Div(sqrtExp, bigFour)
-
-	return &Sloth{
+	s := &Sloth{
 		P:          p,
 		Iterations: iterations,
 		HashFunc:   sha256.New,
-		sqrtExp:    sqrtExp,
-	}, nil
+		newPerm:    NewSqrtPermutation,
+		primality:  MillerRabin(20),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// 验证 p 是一个素数，其余的同余条件由置换自行检查
+	// 必须在构造默认算术后端之前检查: 对合数 p 搜索二次非剩余可能不会终止
+	if s.primality == nil {
+		return nil, errors.New("primality check cannot be nil")
+	}
+	if err := s.primality(p); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.Field == nil {
+		if s.Field, err = group.NewField(p); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.tag) > maxDomainTagLen {
+		return nil, fmt.Errorf("domain tag exceeds %d bytes", maxDomainTagLen)
+	}
+	if !s.hash.Available() {
+		return nil, fmt.Errorf("unknown hash function %v", s.hash)
+	}
+	if s.xmd {
+		if _, err := s.hashToField(bytes.NewReader(nil)); err != nil {
+			return nil, err
+		}
+	}
+	if s.Field.Modulus().Cmp(p) != 0 {
+		return nil, errors.New("field modulus does not match p")
+	}
+	if s.perm, err = s.newPerm(s.Field); err != nil {
+		return nil, err
+	}
+	if s.progress != nil && !s.progressStrideSet {
+		s.progressStride = max(iterations/100, 1)
+	}
+	return s, nil
+}
+
+// Fingerprint 返回参数 (p, 迭代次数, 置换, 哈希函数, 输入映射, 域标签) 的 SHA-256 指纹
+// 用于确认检查点、证明等数据与当前实例的参数一致
+func (s *Sloth) Fingerprint() []byte {
+	h := sha256.New()
+	if s.bind {
+		h.Write([]byte("slothgo/params/v2"))
+	} else {
+		h.Write([]byte("slothgo/params/v1"))
+	}
+	pBytes := s.P.Bytes()
+	binary.Write(h, binary.BigEndian, uint32(len(pBytes)))
+	h.Write(pBytes)
+	binary.Write(h, binary.BigEndian, s.Iterations)
+	for _, name := range []string{s.perm.Name(), s.hash.String(), s.inputMapping()} {
+		h.Write([]byte{byte(len(name))})
+		h.Write([]byte(name))
+	}
+	binary.Write(h, binary.BigEndian, uint16(len(s.tag)))
+	h.Write(s.tag)
+	return h.Sum(nil)
+}
+
+// WitnessSize 返回 witness 等域元素规范编码的字节数 ⌈bits(p)/8⌉
+func (s *Sloth) WitnessSize() int {
+	return (s.P.BitLen() + 7) / 8
+}
+
+// EncodeWitness 把 w 编码为长度固定为 WitnessSize() 的大端字节数组
+// 与 big.Int.Bytes() 不同，它保留前导零字节，使得哈希结果与其他实现一致
+func (s *Sloth) EncodeWitness(w *big.Int) []byte {
+	return w.FillBytes(make([]byte, s.WitnessSize()))
+}
+
+// DecodeWitness 是 EncodeWitness 的逆运算，要求长度恰好为 WitnessSize() 且值小于 p
+func (s *Sloth) DecodeWitness(data []byte) (*big.Int, error) {
+	if len(data) != s.WitnessSize() {
+		return nil, fmt.Errorf("witness must be %d bytes", s.WitnessSize())
+	}
+	w := new(big.Int).SetBytes(data)
+	if w.Cmp(s.P) >= 0 {
+		return nil, ErrWitnessOutOfRange
+	}
+	return w, nil
+}
+
+// Hash 返回实例使用的哈希函数
+func (s *Sloth) Hash() HashID {
+	return s.hash
+}
+
+// Zeroizes 返回实例是否开启了保密模式，见 WithZeroize
+func (s *Sloth) Zeroizes() bool {
+	return s.zeroize
+}
+
+// Wipe 清零 x 的底层数组 (包括容量内未使用的部分) 并把 x 置为 0，x 为 nil 时什么也不做
+func Wipe(x *big.Int) {
+	if x == nil {
+		return
+	}
+	words := x.Bits()
+	clear(words[:cap(words)])
+	x.SetInt64(0)
+}
+
+// WithIterations 返回一个除迭代次数外与 s 完全相同的新实例
+// 由于 p 已经在 New 中校验过，这里不会重复素性检测
+func (s *Sloth) WithIterations(iterations uint64) (*Sloth, error) {
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
+	}
+	c := *s
+	c.Iterations = iterations
+	if c.progress != nil && !c.progressStrideSet {
+		c.progressStride = max(iterations/100, 1)
+	}
+	return &c, nil
 }
 
 // Compute (编码) 执行可验证延迟函数
@@ -64,27 +202,120 @@ func New(p *big.Int, iterations int64) (*Sloth, error) {
 //   - witness: 用于验证的最终值 (论文中的 w)
 //   - error: 计算过程中的错误
 func (s *Sloth) Compute(input []byte) (hash []byte, witness *big.Int, err error) {
-	// 步骤 1 & 3: h(s) 并转换为 w₀
-	hasher := s.HashFunc()
-	hasher.Write(input)
-	uBytes := hasher.Sum(nil)
+	return s.ComputeCtx(context.Background(), input)
+}
+
+// ComputeCtx 与 Compute 相同，但会在迭代循环中定期检查 ctx，
+// 一旦 ctx 被取消则立即返回 ctx.Err()
+func (s *Sloth) ComputeCtx(ctx context.Context, input []byte) (hash []byte, witness *big.Int, err error) {
+	done := s.track(metrics.Compute)
+	defer func() { done(computeOutcome(err)) }()
 
-	w := new(big.Int).SetBytes(uBytes)
-	w.Mod(w, s.P) // w₀ = int(h(s))
+	// 步骤 1 & 3: h(s) 并转换为 w₀
+	w := s.initialValue(input)
 
 	// 步骤 4: 迭代 l 次
-	for i := int64(0); i < s.Iterations; i++ {
-		w = s.Tau(w)
+	w, err = s.iterate(ctx, w, 0, s.Iterations)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	witness = new(big.Int).Set(w)
 
 	// 步骤 5: 计算最终哈希 g = h(hex(wₗ))
-	hasher.Reset()
-	hasher.Write(witness.Bytes())
-	hash = hasher.Sum(nil)
+	return s.outputHash(witness), witness, nil
+}
+
+// initialValue 计算 w₀ = int(h(input)) mod p
+func (s *Sloth) initialValue(input []byte) *big.Int {
+	if s.xmd {
+		// 输出长度已在 New 中检查，读取内存中的输入不会失败
+		w, _ := s.hashToField(bytes.NewReader(input))
+		return w
+	}
+	hasher := s.newHasher(hashRoleInput)
+	hasher.Write(input)
+	return s.digestToField(hasher.Sum(nil))
+}
 
-	return hash, witness, nil
+// digestToField 将输入的哈希摘要映射为 F_p 中的元素
+func (s *Sloth) digestToField(digest []byte) *big.Int {
+	w := new(big.Int).SetBytes(digest)
+	return w.Mod(w, s.P)
+}
+
+// outputHash 计算最终输出 g = h(w)，启用参数绑定时为 g = h(指纹 || w)
+// w 使用 EncodeWitness 的定长编码
+func (s *Sloth) outputHash(witness *big.Int) []byte {
+	hasher := s.newHasher(hashRoleOutput)
+	if s.bind {
+		hasher.Write(s.Fingerprint())
+	}
+	encoded := s.EncodeWitness(witness)
+	hasher.Write(encoded)
+	if s.zeroize {
+		clear(encoded)
+	}
+	return hasher.Sum(nil)
+}
+
+// 域标签之后写入的角色字节，区分 w₀ 的派生与最终输出
+const (
+	hashRoleInput  = 0
+	hashRoleOutput = 1
+)
+
+// newHasher 返回一个新的哈希实例
+// 设置了域标签时先写入 len(tag)(2, 大端) || tag || role，未设置时与论文中的定义相同
+func (s *Sloth) newHasher(role byte) hash.Hash {
+	hasher := s.HashFunc()
+	if len(s.tag) > 0 {
+		binary.Write(hasher, binary.BigEndian, uint16(len(s.tag)))
+		hasher.Write(s.tag)
+		hasher.Write([]byte{role})
+	}
+	return hasher
+}
+
+// iterate 从第 start 次迭代开始对 w 连续应用 τ 直到第 end 次,
+// 期间定期检查 ctx 并按配置调用进度回调。w 会被原地修改
+func (s *Sloth) iterate(ctx context.Context, w *big.Int, start, end uint64) (*big.Int, error) {
+	tmp := new(big.Int)
+	began := time.Now()
+	for i := start; i < end; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		s.perm.Forward(w, tmp)
+		if s.progress != nil && ((i+1)%s.progressStride == 0 || i+1 == end) {
+			s.progress(s.progressInfo(i+1-start, time.Since(began), i+1))
+		}
+	}
+	if s.zeroize {
+		Wipe(tmp)
+	}
+	return w, nil
+}
+
+// progressInfo 根据本次循环在 elapsed 内完成的 n 次迭代估计速度，并生成第 done 次迭代时的进度信息
+func (s *Sloth) progressInfo(n uint64, elapsed time.Duration, done uint64) ProgressInfo {
+	pi := ProgressInfo{Done: done, Total: s.Iterations}
+	if elapsed > 0 {
+		pi.Rate = float64(n) / elapsed.Seconds()
+		pi.ETA = rateDuration(s.Iterations-done, pi.Rate)
+	}
+	return pi
+}
+
+// reverse 从 w 开始连续应用 n 次 τ⁻¹，结果保存在 sc.w 中并返回
+func (s *Sloth) reverse(ctx context.Context, w *big.Int, n uint64, sc *scratch) (*big.Int, error) {
+	sc.lane[0] = sc.w.Set(w)
+	if err := inverseN(ctx, s.perm, sc.lane[:], n, sc.tmp); err != nil {
+		return nil, err
+	}
+	return sc.w, nil
 }
 
 // Verify (解码/验证) 验证 VDF 的输出是否正确
@@ -95,9 +326,53 @@ func (s *Sloth) Compute(input []byte) (hash []byte, witness *big.Int, err error)
 //   - bool: 验证是否成功
 //   - error: 验证过程中的错误
 func (s *Sloth) Verify(input []byte, hash []byte, witness *big.Int) (bool, error) {
+	return s.VerifyCtx(context.Background(), input, hash, witness)
+}
+
+// VerifyCtx 与 Verify 相同，但会在逆向迭代中定期检查 ctx，
+// 一旦 ctx 被取消则立即返回 ctx.Err()
+func (s *Sloth) VerifyCtx(ctx context.Context, input []byte, hash []byte, witness *big.Int) (ok bool, err error) {
+	// 缓存的键是证明的编码，没有配置缓存时不必构造
+	remember := func() {}
+	if s.verifyCache != nil {
+		var hit bool
+		if hit, remember = s.cachedVerify(input, s.NewProof(hash, witness)); hit {
+			return true, nil
+		}
+	}
+	done := s.track(metrics.Verify)
+	defer func() { done(verifyOutcome(ok, err)) }()
+	if ok, err = s.verifyCtx(ctx, input, hash, witness); ok {
+		remember()
+	}
+	return ok, err
+}
+
+// verifyCtx 是 VerifyCtx 的实现，不向监控收集器报告
+func (s *Sloth) verifyCtx(ctx context.Context, input []byte, hash []byte, witness *big.Int) (bool, error) {
 	if input == nil {
 		return false, errors.New("input cannot be nil")
 	}
+	return s.verifyFrom(ctx, s.initialValue(input), hash, witness)
+}
+
+// verifyFrom 验证 witness 经过 l 次 τ⁻¹ 后是否回到预期的初始值 wStart
+func (s *Sloth) verifyFrom(ctx context.Context, wStart *big.Int, hash []byte, witness *big.Int) (bool, error) {
+	return s.verifyFromScratch(ctx, wStart, hash, witness, newScratch())
+}
+
+// scratch 是逆向迭代使用的可复用缓冲区
+type scratch struct {
+	w, tmp *big.Int
+	lane   [1]*big.Int // 传给 batchInverter 的单元素切片，避免每次分配
+}
+
+func newScratch() *scratch {
+	return &scratch{w: new(big.Int), tmp: new(big.Int)}
+}
+
+// verifyFromScratch 与 verifyFrom 相同，但使用调用方提供的缓冲区
+func (s *Sloth) verifyFromScratch(ctx context.Context, wStart *big.Int, hash []byte, witness *big.Int, sc *scratch) (bool, error) {
 	if hash == nil {
 		return false, errors.New("hash cannot be nil")
 	}
@@ -106,100 +381,40 @@ func (s *Sloth) Verify(input []byte, hash []byte, witness *big.Int) (bool, error
 	}
 	// 确保 witness 在 F_p 域内，这是一个很好的健壮性检查
 	if witness.Cmp(s.P) >= 0 || witness.Sign() < 0 {
-		return false, fmt.Errorf("witness must be in the range [0, p-1]")
+		return false, ErrWitnessOutOfRange
 	}
 
 	// 验证 g = h(hex(w))
-	hasher := s.HashFunc()
-	hasher.Write(witness.Bytes())
-	expectedHash := hasher.Sum(nil)
-	if !bytes.Equal(hash, expectedHash) {
-		return false, errors.New("hash of witness does not match provided hash")
+	if subtle.ConstantTimeCompare(hash, s.outputHash(witness)) != 1 {
+		return false, ErrHashMismatch
 	}
 
 	// 步骤 4 & 5 (逆向): 从 w 开始，迭代 l 次 τ⁻¹
-	wCheck := new(big.Int).Set(witness)
-	for i := int64(0); i < s.Iterations; i++ {
-		wCheck = s.TauInverse(wCheck)
+	wCheck, err := s.reverse(ctx, witness, s.Iterations, sc)
+	if err != nil {
+		return false, err
 	}
 
-	// 计算预期的初始值 w₀
-	hasher.Reset()
-	hasher.Write(input)
-	uBytes := hasher.Sum(nil)
-	wStartExpected := new(big.Int).SetBytes(uBytes)
-	wStartExpected.Mod(wStartExpected, s.P)
-
 	// 比较逆向计算的结果和预期的初始值
-	if wCheck.Cmp(wStartExpected) == 0 {
+	if wCheck.Cmp(wStart) == 0 {
 		return true, nil
 	}
 
-	return false, errors.New("verification failed: reversed witness does not match initial value")
-}
-
-// sigma (σ) 实现 "邻居交换" 置换
-// 如果 x_hat 是偶数, σ(x) = x - 1
-// 如果 x_hat 是奇数, σ(x) = x + 1
-func (s *Sloth) sigma(x *big.Int) *big.Int {
-	// big.Int.Bit(0) 返回最低有效位, 0 表示偶数, 1 表示奇数
-	res := new(big.Int)
-	if x.Bit(0) == 0 { // 偶数
-		res.Sub(x, bigOne)
-	} else { // 奇数
-		res.Add(x, bigOne)
-	}
-	// 确保结果在 F_p 域内
-	return res.Mod(res, s.P)
-}
-
-// sigmaInverse (σ⁻¹) "邻居交换"的逆也是它本身
-func (s *Sloth) sigmaInverse(x *big.Int) *big.Int {
-	return s.sigma(x)
-}
-
-// rho (ρ) 计算具有偶数提升值的模平方根
-func (s *Sloth) rho(x *big.Int) *big.Int {
-	// 检查 x 是否是二次剩余
-	valToRoot := new(big.Int)
-	if big.Jacobi(x, s.P) == 1 {
-		valToRoot.Set(x)
-	} else {
-		// 如果不是，取 -x 的根
-		valToRoot.Neg(x).Mod(valToRoot, s.P)
-	}
-
-	// 计算根 y = valToRoot^((p+1)/4) mod p
-	root := new(big.Int).Exp(valToRoot, s.sqrtExp, s.P)
-
-	// 选择偶数提升值的根
-	if root.Bit(0) == 0 { // 偶数
-		return root
-	}
-	// 否则，另一个根是 p - root，它一定是偶数
-	return new(big.Int).Sub(s.P, root)
-}
-
-// rhoInverse (ρ⁻¹) 是 ρ 的逆运算
-// 如果 y_hat 是偶数, ρ⁻¹(y) = y²
-// 如果 y_hat 是奇数, ρ⁻¹(y) = -y²
-func (s *Sloth) rhoInverse(y *big.Int) *big.Int {
-	ySquared := new(big.Int).Exp(y, bigTwo, s.P)
-	if y.Bit(0) == 0 { // 偶数
-		return ySquared
-	}
-	// 奇数
-	return new(big.Int).Neg(ySquared).Mod(ySquared, s.P)
+	return false, ErrReversalMismatch
 }
 
-// tau (τ) 是核心的迭代函数
+// Tau (τ) 是核心的迭代函数，默认为 τ = ρ ∘ σ
 func (s *Sloth) Tau(x *big.Int) *big.Int {
-	return s.rho(s.sigma(x))
+	y := new(big.Int).Set(x)
+	s.perm.Forward(y, new(big.Int))
+	return y
 }
 
-// tauInverse (τ⁻¹) 是 τ 的逆函数
+// TauInverse (τ⁻¹) 是 τ 的逆函数
 func (s *Sloth) TauInverse(y *big.Int) *big.Int {
-	return s.sigmaInverse(s.rhoInverse(y))
+	x := new(big.Int).Set(y)
+	s.perm.Inverse(x, new(big.Int))
+	return x
 }
 
 // GenerateSlothPrime 生成一个满足 p ≡ 3 (mod 4) 的大素数
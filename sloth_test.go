@@ -1,15 +1,22 @@
 package slothgo
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"math/big"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/alan22333/sloth_go/group"
 )
 
 // 全局变量，用于在测试和基准测试之间共享一个昂贵的 VDF 实例
 var testVDF *Sloth
 var testInput = []byte("A random zoo: sloth, unicorn, and trx")
 var testPrimeBits = 64 // 使用较小的素数以加快测试速度
-var testIterations int64 = 1000
+var testIterations uint64 = 1000
 
 func init() {
 	// 在所有测试开始前，初始化一个 VDF 实例
@@ -106,14 +113,178 @@ func TestVerify_FailureCases(t *testing.T) {
 	t.Log("Failure cases test passed!")
 }
 
+// TestTau_Bijective 检查 τ⁻¹(τ(x)) = x，覆盖 0、p-1 附近的边界值与伪随机值，
+// 二次剩余与非二次剩余、奇数与偶数都会出现
+func TestTau_Bijective(t *testing.T) {
+	p := testVDF.P
+	var values []*big.Int
+	for i := range int64(1000) {
+		values = append(values, big.NewInt(i), new(big.Int).Sub(p, big.NewInt(i+1)))
+	}
+	x := new(big.Int).SetBytes(testInput)
+	for i := 0; i < 100; i++ {
+		x = testVDF.Tau(x.Mod(x, p))
+		values = append(values, new(big.Int).Sub(p, x), new(big.Int).Set(x))
+	}
+
+	for _, v := range values {
+		if got := testVDF.TauInverse(testVDF.Tau(v)); got.Cmp(v) != 0 {
+			t.Fatalf("TauInverse(Tau(%v)) = %v", v, got)
+		}
+	}
+}
+
+// TestWithField 检查通过 WithField 替换算术后端后结果不变，且模数不匹配时会被拒绝
+func TestWithField(t *testing.T) {
+	field, err := group.NewPrimeField(testVDF.P)
+	if err != nil {
+		t.Fatalf("NewPrimeField failed: %v", err)
+	}
+	vdf, err := New(testVDF.P, testIterations, WithField(field))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	_, wantWitness, _ := testVDF.Compute(testInput)
+	if witness.Cmp(wantWitness) != 0 {
+		t.Error("custom field backend changed the result")
+	}
+
+	other, _ := group.NewPrimeField(big.NewInt(1000003))
+	if _, err := New(testVDF.P, testIterations, WithField(other)); err == nil {
+		t.Error("expected error for mismatched field modulus")
+	}
+}
+
+// TestNew_PrimeCongruentToOneModFour 检查 p ≡ 1 (mod 4) 时使用 Tonelli–Shanks 的路径
+func TestNew_PrimeCongruentToOneModFour(t *testing.T) {
+	// 2^64 - 59 ≡ 5 (mod 8)，97 和 17 ≡ 1 (mod 8)，覆盖 Tonelli–Shanks 中 s 的不同取值
+	for _, s := range []string{"18446744073709551557", "97", "17"} {
+		p, _ := new(big.Int).SetString(s, 10)
+		vdf, err := New(p, 200)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", s, err)
+		}
+		for i := int64(0); i < 2000; i++ {
+			x := new(big.Int).Mod(big.NewInt(i), p)
+			if got := vdf.TauInverse(vdf.Tau(x)); got.Cmp(x) != 0 {
+				t.Fatalf("p=%s: TauInverse(Tau(%v)) = %v", s, x, got)
+			}
+		}
+		hash, witness, err := vdf.Compute(testInput)
+		if err != nil {
+			t.Fatalf("Compute failed: %v", err)
+		}
+		if ok, err := vdf.Verify(testInput, hash, witness); !ok || err != nil {
+			t.Fatalf("p=%s: Verify failed: %v, %v", s, ok, err)
+		}
+	}
+}
+
+// TestComputeCtx_Cancel 检查已取消的 ctx 能让计算与验证提前返回
+func TestComputeCtx_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := testVDF.ComputeCtx(ctx, testInput)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ComputeCtx: expected context.Canceled, got %v", err)
+	}
+
+	hash, witness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed unexpectedly: %v", err)
+	}
+	verified, err := testVDF.VerifyCtx(ctx, testInput, hash, witness)
+	if verified || !errors.Is(err, context.Canceled) {
+		t.Fatalf("VerifyCtx: expected context.Canceled, got %v, %v", verified, err)
+	}
+}
+
+// TestWithProgress 检查进度回调按步长调用，并在结束时报告完成
+func TestWithProgress(t *testing.T) {
+	var calls []uint64
+	vdf, err := New(testVDF.P, testIterations, WithProgress(func(done, total uint64) {
+		if total != testIterations {
+			t.Errorf("total = %d, want %d", total, testIterations)
+		}
+		calls = append(calls, done)
+	}, 300))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, _, err := vdf.Compute(testInput); err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	want := []uint64{300, 600, 900, 1000}
+	if len(calls) != len(want) {
+		t.Fatalf("progress calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("progress calls = %v, want %v", calls, want)
+		}
+	}
+}
+
+// TestWithProgressIterations 检查 WithIterations 保留调用方指定的步长
+func TestWithProgressIterations(t *testing.T) {
+	var calls []uint64
+	vdf, err := New(testVDF.P, testIterations, WithProgress(func(done, total uint64) {
+		calls = append(calls, done)
+	}, 7))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if vdf, err = vdf.WithIterations(30); err != nil {
+		t.Fatalf("WithIterations failed: %v", err)
+	}
+	if _, _, err := vdf.Compute(testInput); err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if want := []uint64{7, 14, 21, 28, 30}; !slices.Equal(calls, want) {
+		t.Fatalf("progress calls = %v, want %v", calls, want)
+	}
+}
+
+// TestWithProgressInfo 检查进度信息中的速度与剩余时间估计
+func TestWithProgressInfo(t *testing.T) {
+	var infos []ProgressInfo
+	vdf, err := New(testVDF.P, testIterations, WithProgressInfo(func(pi ProgressInfo) {
+		infos = append(infos, pi)
+	}, 500))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, _, err := vdf.Compute(testInput); err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("got %d progress calls, want 2", len(infos))
+	}
+	mid, last := infos[0], infos[1]
+	if mid.Done != 500 || mid.Total != testIterations || mid.Rate <= 0 || mid.ETA <= 0 {
+		t.Errorf("unexpected midway progress %+v", mid)
+	}
+	// 剩余一半迭代时的估计应与已用时间同一量级
+	if elapsed := time.Duration(float64(mid.Done) / mid.Rate * float64(time.Second)); mid.ETA > 10*elapsed {
+		t.Errorf("ETA %v is far from elapsed time %v", mid.ETA, elapsed)
+	}
+	if last.Done != testIterations || last.ETA != 0 {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
 // TestNew_ParameterValidation 测试 New 函数的参数校验
 func TestNew_ParameterValidation(t *testing.T) {
-	// 1. 测试 p 不满足 p ≡ 3 (mod 4) 的情况
-	// 找到一个 p ≡ 1 (mod 4) 的素数，例如 5, 13, 17...
-	pNotCongruent, _ := new(big.Int).SetString("11", 16) // 17 = 4*4 + 1
-	_, err := New(pNotCongruent, 100)
+	// 1. 测试 p 是偶数的情况 (2 是素数，但不是奇素数)
+	_, err := New(big.NewInt(2), 100)
 	if err == nil {
-		t.Error("Expected error for p not congruent to 3 (mod 4), but got nil")
+		t.Error("Expected error for even p, but got nil")
 	}
 
 	// 2. 测试 p 不是素数的情况
@@ -159,3 +330,81 @@ func BenchmarkVerify(b *testing.B) {
 		_, _ = testVDF.Verify(testInput, hash, witness)
 	}
 }
+
+// TestIterationAllocs 检查稳定运行后 τ 与 τ⁻¹ 的每次迭代不再分配内存
+// iterate 每次调用会分配一个临时值 (big.Int 本身及其底层数组)，与迭代次数无关;
+// PrimeField 的正向迭代依赖 big.Int.Exp，其内部分配无法避免，因此只检查逆向迭代
+func TestIterationAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not stable under the race detector")
+	}
+	const n = 64
+	p := ParamSets()[0].Prime()
+	p320, err := DerivePrime([]byte("allocs"), 320)
+	if err != nil {
+		t.Fatalf("DerivePrime failed: %v", err)
+	}
+	pf, _ := group.NewPrimeField(p)
+	mf, _ := group.NewMontgomeryField(p320)
+	f256, _ := group.NewField256(p)
+
+	for _, tc := range []struct {
+		f       group.Field
+		forward bool
+	}{{f256, true}, {mf, true}, {pf, false}} {
+		vdf, err := New(tc.f.Modulus(), n, WithField(tc.f))
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		w := vdf.initialValue(testInput)
+		sc := &scratch{w: new(big.Int), tmp: new(big.Int)}
+		ctx := context.Background()
+		if tc.forward {
+			vdf.iterate(ctx, w, 0, n) // 预热缓冲池并让 w 的底层数组达到最大长度
+			if allocs := testing.AllocsPerRun(10, func() { vdf.iterate(ctx, w, 0, n) }); allocs > 2 {
+				t.Errorf("%T: forward pass made %v allocations per %d iterations", tc.f, allocs, n)
+			}
+		}
+		vdf.reverse(ctx, w, n, sc)
+		if allocs := testing.AllocsPerRun(10, func() { vdf.reverse(ctx, w, n, sc) }); allocs > 0 {
+			t.Errorf("%T: inverse pass made %v allocations per %d iterations", tc.f, allocs, n)
+		}
+	}
+}
+
+// TestZeroize 检查保密模式不改变输出与参数指纹，并且 Wipe 清零了底层数组
+func TestZeroize(t *testing.T) {
+	vdf, err := New(testVDF.P, testIterations, WithZeroize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !vdf.Zeroizes() || testVDF.Zeroizes() || !bytes.Equal(vdf.Fingerprint(), testVDF.Fingerprint()) {
+		t.Fatal("WithZeroize changed the parameters")
+	}
+	hash, witness, err := vdf.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if ok, err := testVDF.Verify(testInput, hash, witness); !ok {
+		t.Fatalf("output of the zeroizing instance was rejected: %v", err)
+	}
+
+	words := witness.Bits()
+	Wipe(witness)
+	if witness.Sign() != 0 {
+		t.Error("Wipe did not reset the value")
+	}
+	for _, w := range words[:cap(words)] {
+		if w != 0 {
+			t.Fatal("Wipe left data in the backing array")
+		}
+	}
+	Wipe(nil)
+
+	st := vdf.NewStepper(testInput)
+	st.Next()
+	st.Wipe()
+	if st.Value().Sign() != 0 {
+		t.Error("Stepper.Wipe did not reset the current value")
+	}
+}
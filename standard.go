@@ -0,0 +1,66 @@
+package slothgo
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ParamSet 是一组预先审定的标准参数
+// 素数取 p = 2^Bits - Offset，其中 Offset 是使 p 为素数且 p ≡ 3 (mod 4) 的最小正整数，
+// 任何人都可以复现这一搜索过程，素数中没有可以隐藏后门的自由度
+type ParamSet struct {
+	Name       string
+	Bits       int
+	Offset     int64
+	Iterations uint64        // 推荐的迭代次数
+	Delay      time.Duration // 按 Iterations 迭代在参考硬件上 (单核 x86-64，math/big) 的大致耗时
+}
+
+// standardParamSets 是内置的标准参数集，按安全级别与延迟排序
+var standardParamSets = []ParamSet{
+	{Name: "sloth-256-t30s", Bits: 256, Offset: 189, Iterations: 400_000, Delay: 30 * time.Second},
+	{Name: "sloth-512-t1m", Bits: 512, Offset: 569, Iterations: 200_000, Delay: time.Minute},
+	{Name: "sloth-1024-t5m", Bits: 1024, Offset: 105, Iterations: 330_000, Delay: 5 * time.Minute},
+	{Name: "sloth-2048-t10m", Bits: 2048, Offset: 1557, Iterations: 140_000, Delay: 10 * time.Minute},
+}
+
+// ParamSets 返回所有内置的标准参数集
+func ParamSets() []ParamSet {
+	return append([]ParamSet(nil), standardParamSets...)
+}
+
+// LookupParamSet 按名称返回标准参数集
+func LookupParamSet(name string) (ParamSet, error) {
+	for _, ps := range standardParamSets {
+		if ps.Name == name {
+			return ps, nil
+		}
+	}
+	return ParamSet{}, fmt.Errorf("unknown parameter set %q", name)
+}
+
+// Prime 返回 p = 2^Bits - Offset
+func (ps ParamSet) Prime() *big.Int {
+	p := new(big.Int).Lsh(bigOne, uint(ps.Bits))
+	return p.Sub(p, big.NewInt(ps.Offset))
+}
+
+// Params 返回参数集对应的默认配置 (SHA-256、平方根置换)
+func (ps ParamSet) Params() *Params {
+	return &Params{
+		P:           ps.Prime(),
+		Iterations:  ps.Iterations,
+		Hash:        HashSHA256,
+		Permutation: "sqrt",
+	}
+}
+
+// NewStandard 使用名为 name 的标准参数集创建实例，opts 在参数集之后应用
+func NewStandard(name string, opts ...Option) (*Sloth, error) {
+	ps, err := LookupParamSet(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromParams(ps.Params(), opts...)
+}
@@ -0,0 +1,55 @@
+package slothgo
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestParamSets 复现每个标准参数集的素数搜索: Offset 必须是满足条件的最小值
+func TestParamSets(t *testing.T) {
+	for _, ps := range ParamSets() {
+		p := ps.Prime()
+		if p.BitLen() != ps.Bits || p.Bit(1) != 1 || !p.ProbablyPrime(20) {
+			t.Errorf("%s: 2^%d - %d is not a prime congruent to 3 (mod 4)", ps.Name, ps.Bits, ps.Offset)
+			continue
+		}
+		if testing.Short() && ps.Bits > 1024 {
+			continue
+		}
+		base := new(big.Int).Lsh(bigOne, uint(ps.Bits))
+		for k := int64(1); k < ps.Offset; k += 2 {
+			q := new(big.Int).Sub(base, big.NewInt(k))
+			if q.Bit(1) == 1 && q.ProbablyPrime(20) {
+				t.Errorf("%s: offset %d is not minimal, 2^%d - %d also qualifies", ps.Name, ps.Offset, ps.Bits, k)
+				break
+			}
+		}
+	}
+}
+
+func TestNewStandard(t *testing.T) {
+	s, err := NewStandard("sloth-256-t30s")
+	if err != nil {
+		t.Fatalf("NewStandard failed: %v", err)
+	}
+	if s.Iterations != 400_000 || s.P.Cmp(ParamSets()[0].Prime()) != 0 {
+		t.Error("parameter set was not applied")
+	}
+
+	// 缩短迭代次数后检查计算与验证
+	short, err := s.WithIterations(testIterations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, witness, err := short.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if ok, err := short.Verify(testInput, hash, witness); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+
+	if _, err := NewStandard("sloth-unknown"); err == nil {
+		t.Error("expected error for unknown parameter set")
+	}
+}
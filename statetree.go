@@ -0,0 +1,182 @@
+package slothgo
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// StateTree 是对计算过程中采样的中间值构建的 Merkle 树
+// 第 j 个叶子承诺第 min(j*stride, l) 次迭代后的值，证明方保留它以便在争议时出示任意迭代的包含证明
+type StateTree struct {
+	s       *Sloth
+	stride  uint64
+	samples []*big.Int
+	tree    *merkle.Tree
+}
+
+// StateInclusion 证明第 Iteration 次迭代后的值为 Value
+// Sample 是该迭代之前最近的采样值，验证方从 Sample 向前迭代 (不超过 stride 次) 得到 Value
+type StateInclusion struct {
+	Iteration uint64
+	Value     *big.Int
+	Sample    *big.Int
+	Path      [][]byte
+}
+
+// ComputeStateTree 执行计算并对每隔 stride 次迭代的中间值 (以及 w₀ 和最终 witness) 构建 Merkle 树
+// 返回的证明中包含 StateRoot，可用于乐观验证方案中的简洁争议 ("第 523,001 次迭代是错的")
+func (s *Sloth) ComputeStateTree(ctx context.Context, input []byte, stride uint64) (*Proof, *StateTree, error) {
+	if stride == 0 {
+		return nil, nil, errors.New("state stride must be positive")
+	}
+	w := s.initialValue(input)
+	samples := []*big.Int{new(big.Int).Set(w)}
+	for i := uint64(0); i < s.Iterations; i += stride {
+		end := stepEnd(i, stride, s.Iterations)
+		var err error
+		w, err = s.iterate(ctx, w, i, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		samples = append(samples, new(big.Int).Set(w))
+	}
+
+	t := s.newStateTree(stride, samples)
+	witness := new(big.Int).Set(w)
+	proof := s.NewProof(s.outputHash(witness), witness)
+	proof.StateStride = stride
+	proof.StateRoot = t.Root()
+	return proof, t, nil
+}
+
+// newStateTree 使用按顺序排列的采样值构建 StateTree
+func (s *Sloth) newStateTree(stride uint64, samples []*big.Int) *StateTree {
+	leaves := make([][]byte, len(samples))
+	for j, v := range samples {
+		leaves[j] = s.stateLeaf(min(uint64(j)*stride, s.Iterations), v)
+	}
+	return &StateTree{s: s, stride: stride, samples: samples, tree: merkle.NewTree(leaves)}
+}
+
+// Root 返回状态承诺的 Merkle 根
+func (t *StateTree) Root() []byte {
+	return t.tree.Root()
+}
+
+// Prove 生成第 iteration 次迭代后的值的包含证明
+func (t *StateTree) Prove(iteration uint64) (*StateInclusion, error) {
+	if iteration > t.s.Iterations {
+		return nil, fmt.Errorf("iteration %d out of range [0, %d]", iteration, t.s.Iterations)
+	}
+	j := iteration / t.stride
+	path, err := t.tree.Proof(int(j))
+	if err != nil {
+		return nil, err
+	}
+	sample := t.samples[j]
+	value, err := t.s.iterate(context.Background(), new(big.Int).Set(sample), 0, iteration-j*t.stride)
+	if err != nil {
+		return nil, err
+	}
+	return &StateInclusion{
+		Iteration: iteration,
+		Value:     value,
+		Sample:    new(big.Int).Set(sample),
+		Path:      path,
+	}, nil
+}
+
+// VerifyStateInclusion 检查 inc 是否与 proof 中的状态承诺一致
+func (s *Sloth) VerifyStateInclusion(proof *Proof, inc *StateInclusion) error {
+	if proof == nil || proof.StateRoot == nil || proof.StateStride == 0 {
+		return errors.New("proof has no state commitment")
+	}
+	if inc == nil || inc.Value == nil || inc.Sample == nil {
+		return errors.New("state inclusion is incomplete")
+	}
+	if err := s.checkState(inc.Sample, inc.Iteration); err != nil {
+		return err
+	}
+
+	stride := proof.StateStride
+	j := inc.Iteration / stride
+	size := int(ceilDiv(s.Iterations, stride) + 1)
+	leaf := s.stateLeaf(j*stride, inc.Sample)
+	if !merkle.Verify(proof.StateRoot, leaf, int(j), size, inc.Path) {
+		return errors.New("state inclusion path does not match state root")
+	}
+
+	value, err := s.iterate(context.Background(), new(big.Int).Set(inc.Sample), 0, inc.Iteration-j*stride)
+	if err != nil {
+		return err
+	}
+	if value.Cmp(inc.Value) != 0 {
+		return fmt.Errorf("value at iteration %d does not follow from the committed sample", inc.Iteration)
+	}
+	return nil
+}
+
+// verifyStateCommitted 在一次逆向迭代中同时验证 witness、区段检查点和状态承诺
+func (s *Sloth) verifyStateCommitted(ctx context.Context, wStart *big.Int, proof *Proof) (bool, error) {
+	stride := proof.StateStride
+	if stride == 0 {
+		return false, errors.New("state stride must be positive")
+	}
+	if proof.Witness == nil {
+		return false, errors.New("witness cannot be nil")
+	}
+	if proof.Witness.Cmp(s.P) >= 0 || proof.Witness.Sign() < 0 {
+		return false, ErrWitnessOutOfRange
+	}
+	if subtle.ConstantTimeCompare(proof.Hash, s.outputHash(proof.Witness)) != 1 {
+		return false, ErrHashMismatch
+	}
+	k := proof.CheckpointInterval
+	if len(proof.Checkpoints) > 0 {
+		if k == 0 {
+			return false, errors.New("checkpoint interval must be positive")
+		}
+		if want := ceilDiv(s.Iterations, k) - 1; uint64(len(proof.Checkpoints)) != want {
+			return false, fmt.Errorf("proof has %d checkpoints, expected %d", len(proof.Checkpoints), want)
+		}
+	}
+
+	samples := make([]*big.Int, ceilDiv(s.Iterations, stride)+1)
+	samples[len(samples)-1] = new(big.Int).Set(proof.Witness)
+	w := new(big.Int).Set(proof.Witness)
+	tmp := new(big.Int)
+	for i := s.Iterations; i > 0; {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+		}
+		s.perm.Inverse(w, tmp)
+		i--
+		if i%stride == 0 {
+			samples[i/stride] = new(big.Int).Set(w)
+		}
+		if len(proof.Checkpoints) > 0 && i > 0 && i%k == 0 && proof.Checkpoints[i/k-1].Cmp(w) != 0 {
+			return false, fmt.Errorf("checkpoint at iteration %d: %w", i, ErrReversalMismatch)
+		}
+	}
+	if w.Cmp(wStart) != 0 {
+		return false, ErrReversalMismatch
+	}
+	if subtle.ConstantTimeCompare(s.newStateTree(stride, samples).Root(), proof.StateRoot) != 1 {
+		return false, errors.New("verification failed: state root does not match intermediate values")
+	}
+	return true, nil
+}
+
+// stateLeaf 编码一个状态承诺叶子: 迭代序号 (8 字节大端) || w (定长编码)
+func (s *Sloth) stateLeaf(iteration uint64, w *big.Int) []byte {
+	leaf := binary.BigEndian.AppendUint64(nil, iteration)
+	return append(leaf, s.EncodeWitness(w)...)
+}
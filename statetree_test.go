@@ -0,0 +1,50 @@
+package slothgo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+// TestStateTree 检查状态承诺可以被验证，并能为任意迭代生成包含证明
+func TestStateTree(t *testing.T) {
+	proof, tree, err := testVDF.ComputeStateTree(context.Background(), testInput, 64)
+	if err != nil {
+		t.Fatalf("ComputeStateTree failed: %v", err)
+	}
+
+	verified, err := testVDF.VerifyProof(testInput, proof)
+	if err != nil || !verified {
+		t.Fatalf("VerifyProof failed: %v, %v", verified, err)
+	}
+
+	stepper := testVDF.NewStepper(testInput)
+	for _, iteration := range []uint64{0, 1, 63, 64, 523, 999, 1000} {
+		for stepper.Index() < iteration {
+			stepper.Next()
+		}
+		inc, err := tree.Prove(iteration)
+		if err != nil {
+			t.Fatalf("Prove(%d) failed: %v", iteration, err)
+		}
+		if inc.Value.Cmp(stepper.Value()) != 0 {
+			t.Fatalf("Prove(%d) returned wrong value", iteration)
+		}
+		if err := testVDF.VerifyStateInclusion(proof, inc); err != nil {
+			t.Fatalf("VerifyStateInclusion(%d) failed: %v", iteration, err)
+		}
+
+		forged := *inc
+		forged.Value = new(big.Int).Add(inc.Value, big.NewInt(1))
+		if err := testVDF.VerifyStateInclusion(proof, &forged); err == nil {
+			t.Fatalf("forged value at iteration %d verified", iteration)
+		}
+	}
+
+	// 状态根被篡改时，完整验证必须失败
+	tampered := *proof
+	tampered.StateRoot = make([]byte, len(proof.StateRoot))
+	if verified, err := testVDF.VerifyProof(testInput, &tampered); verified || err == nil {
+		t.Error("expected tampered state root to be rejected")
+	}
+}
@@ -0,0 +1,67 @@
+package slothgo
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Stepper 逐步地在 Sloth 的迭代链上前进 (τ) 或后退 (τ⁻¹)
+// 内部缓冲区在各步之间复用，适合把 Sloth 组合进更大的协议中
+// Stepper 不是并发安全的
+type Stepper struct {
+	s     *Sloth
+	w     *big.Int
+	tmp   *big.Int
+	index uint64
+}
+
+// NewStepper 创建一个从 w₀ = int(h(input)) mod p 出发的 Stepper
+func (s *Sloth) NewStepper(input []byte) *Stepper {
+	return &Stepper{s: s, w: s.initialValue(input), tmp: new(big.Int)}
+}
+
+// NewStepperAt 创建一个从第 index 次迭代后的中间值 w 出发的 Stepper
+func (s *Sloth) NewStepperAt(w *big.Int, index uint64) (*Stepper, error) {
+	if w == nil {
+		return nil, errors.New("intermediate value cannot be nil")
+	}
+	if w.Cmp(s.P) >= 0 || w.Sign() < 0 {
+		return nil, errors.New("intermediate value must be in the range [0, p-1]")
+	}
+	return &Stepper{s: s, w: new(big.Int).Set(w), tmp: new(big.Int), index: index}, nil
+}
+
+// Next 应用一次 τ 并返回新的当前值
+// 返回值指向内部缓冲区，仅在下一次调用 Next/Prev 之前有效，如需保留请使用 Value
+func (st *Stepper) Next() *big.Int {
+	st.s.perm.Forward(st.w, st.tmp)
+	st.index++
+	return st.w
+}
+
+// Prev 应用一次 τ⁻¹ 并返回新的当前值，返回值的有效期与 Next 相同
+// 序号为 0 时没有上一个值，Prev 不做任何事
+func (st *Stepper) Prev() *big.Int {
+	if st.index == 0 {
+		return st.w
+	}
+	st.s.perm.Inverse(st.w, st.tmp)
+	st.index--
+	return st.w
+}
+
+// Value 返回当前值的副本
+func (st *Stepper) Value() *big.Int {
+	return new(big.Int).Set(st.w)
+}
+
+// Index 返回当前值对应的迭代序号，w₀ 的序号为 0
+func (st *Stepper) Index() uint64 {
+	return st.index
+}
+
+// Wipe 清零内部缓冲区，之后 Stepper 停在 0 上。当前值是秘密 (例如时间锁的终点) 时在用完后调用
+func (st *Stepper) Wipe() {
+	Wipe(st.w)
+	Wipe(st.tmp)
+}
@@ -0,0 +1,49 @@
+package slothgo
+
+import (
+	"testing"
+)
+
+// TestStepper 检查 Stepper 与 Compute 的结果一致，并能逐步退回 w₀
+func TestStepper(t *testing.T) {
+	_, wantWitness, err := testVDF.Compute(testInput)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	st := testVDF.NewStepper(testInput)
+	start := st.Value()
+	for i := uint64(0); i < testIterations; i++ {
+		st.Next()
+	}
+	if st.Index() != testIterations {
+		t.Fatalf("Index() = %d, want %d", st.Index(), testIterations)
+	}
+	if st.Value().Cmp(wantWitness) != 0 {
+		t.Fatal("Stepper forward result differs from Compute")
+	}
+
+	for st.Index() > 0 {
+		st.Prev()
+	}
+	if st.Value().Cmp(start) != 0 {
+		t.Fatal("Stepper did not return to w₀")
+	}
+}
+
+// TestStepperPrevAtStart 检查在 w₀ 上调用 Prev 不会让序号回绕
+func TestStepperPrevAtStart(t *testing.T) {
+	st := testVDF.NewStepper(testInput)
+	start := st.Value()
+	st.Prev()
+	if st.Index() != 0 {
+		t.Fatalf("Index() = %d after Prev at the start, want 0", st.Index())
+	}
+	if st.Value().Cmp(start) != 0 {
+		t.Fatal("Prev at the start changed the current value")
+	}
+	st.Next()
+	if st.Index() != 1 {
+		t.Fatalf("Index() = %d after Next, want 1", st.Index())
+	}
+}
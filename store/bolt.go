@@ -0,0 +1,278 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	bolt "go.etcd.io/bbolt"
+)
+
+// 数据库中的三个桶: 轮次以 8 字节大端序号为键，按键排序即按序号排序;
+// 证明与检查点以应用的键为键，值是 8 字节大端的写入时间 (Unix 纳秒) 加上二进制编码
+var (
+	roundsBucket      = []byte("rounds")
+	proofsBucket      = []byte("proofs")
+	checkpointsBucket = []byte("checkpoints")
+)
+
+// boltLockTimeout 是等待其他进程释放数据库文件锁的最长时间
+const boltLockTimeout = 5 * time.Second
+
+// boltStore 是把全部记录保存在一个 bbolt 数据库文件中的 Store，纯 Go 实现，不需要 cgo
+// bbolt 的事务本身可以并发使用，mu 只保护 next，保证轮次按序号连续写入
+type boltStore struct {
+	db *bolt.DB
+
+	mu   sync.Mutex
+	next uint64 // 下一轮的序号
+}
+
+// OpenBolt 打开 path 处的 bbolt 数据库，文件不存在时创建
+// 同一个文件同时只能被一个进程打开，其他进程持有时等待 boltLockTimeout 后返回错误
+func OpenBolt(path string) (Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltLockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	s := &boltStore{db: db}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{roundsBucket, proofsBucket, checkpointsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		if k, _ := tx.Bucket(roundsBucket).Cursor().Last(); k != nil {
+			s.next = binary.BigEndian.Uint64(k) + 1
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close 关闭数据库
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+// Put 实现 beacon.Store，轮次必须按序号连续写入
+func (s *boltStore) Put(r *beacon.Round) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkNext(r, s.next); err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(roundsBucket).Put(roundKey(r.Index), data)
+	})
+	if err != nil {
+		return err
+	}
+	s.next++
+	return nil
+}
+
+// Get 实现 beacon.Store
+func (s *boltStore) Get(index uint64) (*beacon.Round, error) {
+	var r *beacon.Round
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(roundsBucket).Get(roundKey(index))
+		if data == nil {
+			return ErrNotFound
+		}
+		r = new(beacon.Round)
+		return json.Unmarshal(data, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Latest 实现 beacon.Store
+func (s *boltStore) Latest() (*beacon.Round, error) {
+	var r *beacon.Round
+	err := s.db.View(func(tx *bolt.Tx) error {
+		k, data := tx.Bucket(roundsBucket).Cursor().Last()
+		if k == nil {
+			return ErrNotFound
+		}
+		r = new(beacon.Round)
+		return json.Unmarshal(data, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *boltStore) Rounds(from, to uint64) ([]*beacon.Round, error) {
+	if from >= to {
+		return nil, nil
+	}
+	var rounds []*beacon.Round
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(roundsBucket).Cursor()
+		for k, data := c.Seek(roundKey(from)); k != nil && binary.BigEndian.Uint64(k) < to; k, data = c.Next() {
+			r := new(beacon.Round)
+			if err := json.Unmarshal(data, r); err != nil {
+				return err
+			}
+			rounds = append(rounds, r)
+		}
+		return nil
+	})
+	return rounds, err
+}
+
+func (s *boltStore) PutProof(key string, p *slothgo.Proof) error {
+	if p == nil {
+		return errors.New("proof cannot be nil")
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey(proofsBucket, key, data)
+}
+
+func (s *boltStore) Proof(key string) (*slothgo.Proof, error) {
+	data, err := s.getKey(proofsBucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProof(data)
+}
+
+func (s *boltStore) DeleteProof(key string) error {
+	return s.deleteKey(proofsBucket, key)
+}
+
+func (s *boltStore) PutCheckpoint(key string, cp *slothgo.Checkpoint) error {
+	if cp == nil {
+		return errors.New("checkpoint cannot be nil")
+	}
+	data, err := cp.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey(checkpointsBucket, key, data)
+}
+
+func (s *boltStore) Checkpoint(key string) (*slothgo.Checkpoint, error) {
+	data, err := s.getKey(checkpointsBucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+func (s *boltStore) DeleteCheckpoint(key string) error {
+	return s.deleteKey(checkpointsBucket, key)
+}
+
+func (s *boltStore) putKey(bucket []byte, key string, data []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	value := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(data)), uint64(time.Now().UnixNano()))
+	value = append(value, data...)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), value)
+	})
+}
+
+func (s *boltStore) getKey(bucket []byte, key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(bucket).Get([]byte(key))
+		if value == nil {
+			return ErrNotFound
+		}
+		if len(value) < 8 {
+			return errors.New("store: truncated record")
+		}
+		// value 只在事务内有效，需要复制
+		data = append([]byte{}, value[8:]...)
+		return nil
+	})
+	return data, err
+}
+
+func (s *boltStore) deleteKey(bucket []byte, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	})
+}
+
+func (s *boltStore) Prune(r Retention) (Pruned, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pruned Pruned
+	before := r.pruneBefore(s.next)
+	cutoff := time.Now().Add(-r.MaxAge).UnixNano()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if pruned.Rounds, err = deleteKeys(tx.Bucket(roundsBucket), func(k, _ []byte) bool {
+			return binary.BigEndian.Uint64(k) < before
+		}); err != nil || r.MaxAge <= 0 {
+			return err
+		}
+		older := func(_, v []byte) bool {
+			return len(v) < 8 || int64(binary.BigEndian.Uint64(v)) < cutoff
+		}
+		if pruned.Proofs, err = deleteKeys(tx.Bucket(proofsBucket), older); err != nil {
+			return err
+		}
+		pruned.Checkpoints, err = deleteKeys(tx.Bucket(checkpointsBucket), older)
+		return err
+	})
+	if err != nil {
+		return Pruned{}, err
+	}
+	return pruned, nil
+}
+
+// deleteKeys 删除桶中 match 返回 true 的记录，返回删除的条数
+// 遍历时用游标删除会跳过下一条记录，因此先收集键再删除
+func deleteKeys(b *bolt.Bucket, match func(k, v []byte) bool) (int, error) {
+	var keys [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		if match(k, v) {
+			keys = append(keys, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+// roundKey 返回轮次在 rounds 桶中的键
+func roundKey(index uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, index)
+}
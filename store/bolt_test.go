@@ -0,0 +1,24 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBolt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sloth.bolt")
+	s, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt failed: %v", err)
+	}
+	testStore(t, s, func(old Store) Store {
+		if err := old.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		s, err := OpenBolt(path)
+		if err != nil {
+			t.Fatalf("reopening failed: %v", err)
+		}
+		return s
+	})
+}
@@ -0,0 +1,288 @@
+package store
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+	"github.com/alan22333/sloth_go/internal/atomicfile"
+)
+
+// FileStore 是把每条记录保存为目录中一个文件的 Store:
+//
+//	rounds/<序号>.json       信标轮次，序号补零到 20 位，按文件名排序即按序号排序
+//	proofs/<键的十六进制>       证明的二进制编码 (Proof.MarshalBinary)
+//	checkpoints/<键的十六进制>  检查点的二进制编码 (Checkpoint.MarshalBinary)
+//
+// 写入先写临时文件再重命名，进程在写入中途退出不会留下损坏的记录; 证明与检查点的写入时间取自文件的修改时间
+type FileStore struct {
+	dir string
+
+	mu          sync.Mutex
+	first, next uint64 // 保存的最早一轮与下一轮的序号，first == next 表示没有轮次
+}
+
+var _ Store = (*FileStore)(nil)
+
+const (
+	roundsDir      = "rounds"
+	proofsDir      = "proofs"
+	checkpointsDir = "checkpoints"
+)
+
+// OpenFileStore 打开目录 dir 中的存储，目录不存在时创建
+func OpenFileStore(dir string) (*FileStore, error) {
+	for _, sub := range []string{roundsDir, proofsDir, checkpointsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	s := &FileStore{dir: dir}
+	indices, err := s.roundIndices()
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) > 0 {
+		s.first, s.next = indices[0], indices[len(indices)-1]+1
+	}
+	return s, nil
+}
+
+// roundIndices 按升序返回保存的全部轮次序号
+func (s *FileStore) roundIndices() ([]uint64, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, roundsDir))
+	if err != nil {
+		return nil, err
+	}
+	var indices []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if i, err := strconv.ParseUint(name, 10, 64); err == nil {
+			indices = append(indices, i)
+		}
+	}
+	slices.Sort(indices)
+	return indices, nil
+}
+
+func (s *FileStore) roundPath(index uint64) string {
+	return filepath.Join(s.dir, roundsDir, fmt.Sprintf("%020d.json", index))
+}
+
+func (s *FileStore) keyPath(sub, key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, sub, hex.EncodeToString([]byte(key))), nil
+}
+
+// Put 实现 beacon.Store，轮次必须按序号连续写入
+func (s *FileStore) Put(r *beacon.Round) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkNext(r, s.next); err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(s.roundPath(r.Index), data); err != nil {
+		return err
+	}
+	s.next++
+	return nil
+}
+
+// Get 实现 beacon.Store
+func (s *FileStore) Get(index uint64) (*beacon.Round, error) {
+	data, err := os.ReadFile(s.roundPath(index))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := new(beacon.Round)
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("round %d: %w", index, err)
+	}
+	return r, nil
+}
+
+// Latest 实现 beacon.Store
+func (s *FileStore) Latest() (*beacon.Round, error) {
+	s.mu.Lock()
+	first, next := s.first, s.next
+	s.mu.Unlock()
+	if first == next {
+		return nil, ErrNotFound
+	}
+	return s.Get(next - 1)
+}
+
+func (s *FileStore) Rounds(from, to uint64) ([]*beacon.Round, error) {
+	s.mu.Lock()
+	from, to = max(from, s.first), min(to, s.next)
+	s.mu.Unlock()
+	var rounds []*beacon.Round
+	for i := from; i < to; i++ {
+		r, err := s.Get(i)
+		if errors.Is(err, ErrNotFound) {
+			// 与 Prune 并发时可能已被删除
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, r)
+	}
+	return rounds, nil
+}
+
+func (s *FileStore) PutProof(key string, p *slothgo.Proof) error {
+	if p == nil {
+		return errors.New("proof cannot be nil")
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey(proofsDir, key, data)
+}
+
+func (s *FileStore) Proof(key string) (*slothgo.Proof, error) {
+	data, err := s.getKey(proofsDir, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProof(data)
+}
+
+func (s *FileStore) DeleteProof(key string) error {
+	return s.deleteKey(proofsDir, key)
+}
+
+func (s *FileStore) PutCheckpoint(key string, cp *slothgo.Checkpoint) error {
+	if cp == nil {
+		return errors.New("checkpoint cannot be nil")
+	}
+	data, err := cp.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey(checkpointsDir, key, data)
+}
+
+func (s *FileStore) Checkpoint(key string) (*slothgo.Checkpoint, error) {
+	data, err := s.getKey(checkpointsDir, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+func (s *FileStore) DeleteCheckpoint(key string) error {
+	return s.deleteKey(checkpointsDir, key)
+}
+
+func (s *FileStore) putKey(sub, key string, data []byte) error {
+	path, err := s.keyPath(sub, key)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data)
+}
+
+func (s *FileStore) getKey(sub, key string) ([]byte, error) {
+	path, err := s.keyPath(sub, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *FileStore) deleteKey(sub, key string) error {
+	path, err := s.keyPath(sub, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Prune(r Retention) (Pruned, error) {
+	var pruned Pruned
+	s.mu.Lock()
+	before := r.pruneBefore(s.next)
+	for ; s.first < before; s.first++ {
+		if err := os.Remove(s.roundPath(s.first)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.mu.Unlock()
+			return pruned, err
+		}
+		pruned.Rounds++
+	}
+	s.mu.Unlock()
+	if r.MaxAge <= 0 {
+		return pruned, nil
+	}
+	cutoff := time.Now().Add(-r.MaxAge)
+	var err error
+	if pruned.Proofs, err = s.pruneDir(proofsDir, cutoff); err != nil {
+		return pruned, err
+	}
+	pruned.Checkpoints, err = s.pruneDir(checkpointsDir, cutoff)
+	return pruned, err
+}
+
+// pruneDir 删除 sub 中修改时间早于 cutoff 的记录
+func (s *FileStore) pruneDir(sub string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, sub))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(s.dir, sub, e.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Close 实现 Store，FileStore 不持有需要释放的资源
+func (s *FileStore) Close() error {
+	return nil
+}
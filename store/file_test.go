@@ -0,0 +1,19 @@
+package store
+
+import "testing"
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	testStore(t, s, func(old Store) Store {
+		old.Close()
+		s, err := OpenFileStore(dir)
+		if err != nil {
+			t.Fatalf("reopening failed: %v", err)
+		}
+		return s
+	})
+}
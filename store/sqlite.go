@@ -0,0 +1,373 @@
+//go:build cgo && sqlite
+
+package store
+
+/*
+#cgo LDFLAGS: -lsqlite3
+#include <stdlib.h>
+#include <sqlite3.h>
+
+// SQLITE_TRANSIENT 是函数指针宏，cgo 无法直接使用
+static int slothgo_bind_blob(sqlite3_stmt *stmt, int i, const void *data, int n) {
+	return sqlite3_bind_blob(stmt, i, data, n, SQLITE_TRANSIENT);
+}
+
+static int slothgo_bind_text(sqlite3_stmt *stmt, int i, const char *data, int n) {
+	return sqlite3_bind_text(stmt, i, data, n, SQLITE_TRANSIENT);
+}
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// SQLiteAvailable 报告当前构建是否包含 SQLite 后端
+const SQLiteAvailable = true
+
+// schema 在打开数据库时执行; created 是最后一次写入的 Unix 纳秒时间，用于按 MaxAge 清理
+const schema = `
+PRAGMA journal_mode = WAL;
+CREATE TABLE IF NOT EXISTS rounds (idx INTEGER PRIMARY KEY, data BLOB NOT NULL);
+CREATE TABLE IF NOT EXISTS proofs (key TEXT PRIMARY KEY, data BLOB NOT NULL, created INTEGER NOT NULL);
+CREATE TABLE IF NOT EXISTS checkpoints (key TEXT PRIMARY KEY, data BLOB NOT NULL, created INTEGER NOT NULL);
+`
+
+// sqliteStore 是把全部记录保存在一个 SQLite 数据库文件中的 Store，轮次的范围查询与清理都由索引完成
+// 所有调用在 mu 下按顺序执行
+type sqliteStore struct {
+	mu   sync.Mutex
+	db   *C.sqlite3
+	next uint64 // 下一轮的序号
+}
+
+// OpenSQLite 打开 path 处的数据库，文件不存在时创建
+func OpenSQLite(path string) (Store, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	var db *C.sqlite3
+	flags := C.SQLITE_OPEN_READWRITE | C.SQLITE_OPEN_CREATE | C.SQLITE_OPEN_FULLMUTEX
+	if rc := C.sqlite3_open_v2(cpath, &db, C.int(flags), nil); rc != C.SQLITE_OK {
+		err := sqliteError(db, rc)
+		C.sqlite3_close(db)
+		return nil, err
+	}
+	s := &sqliteStore{db: db}
+	if err := s.exec(schema); err != nil {
+		s.Close()
+		return nil, err
+	}
+	err := s.query("SELECT MAX(idx) FROM rounds", nil, func(st *stmt) {
+		if !st.null(0) {
+			s.next = uint64(st.int64(0)) + 1
+		}
+	})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close 关闭数据库
+func (s *sqliteStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return nil
+	}
+	rc := C.sqlite3_close(s.db)
+	if rc != C.SQLITE_OK {
+		return sqliteError(s.db, rc)
+	}
+	s.db = nil
+	return nil
+}
+
+// Put 实现 beacon.Store，轮次必须按序号连续写入
+func (s *sqliteStore) Put(r *beacon.Round) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkNext(r, s.next); err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.execArgs("INSERT INTO rounds (idx, data) VALUES (?, ?)", int64(r.Index), data); err != nil {
+		return err
+	}
+	s.next++
+	return nil
+}
+
+// Get 实现 beacon.Store
+func (s *sqliteStore) Get(index uint64) (*beacon.Round, error) {
+	rounds, err := s.rounds("SELECT data FROM rounds WHERE idx = ?", int64(index))
+	if err != nil {
+		return nil, err
+	}
+	if len(rounds) == 0 {
+		return nil, ErrNotFound
+	}
+	return rounds[0], nil
+}
+
+// Latest 实现 beacon.Store
+func (s *sqliteStore) Latest() (*beacon.Round, error) {
+	rounds, err := s.rounds("SELECT data FROM rounds ORDER BY idx DESC LIMIT 1")
+	if err != nil {
+		return nil, err
+	}
+	if len(rounds) == 0 {
+		return nil, ErrNotFound
+	}
+	return rounds[0], nil
+}
+
+func (s *sqliteStore) Rounds(from, to uint64) ([]*beacon.Round, error) {
+	if from >= to {
+		return nil, nil
+	}
+	// 序号以有符号整数保存，超出范围的上界等价于不设上界
+	return s.rounds("SELECT data FROM rounds WHERE idx >= ? AND idx < ? ORDER BY idx",
+		int64(min(from, 1<<63-1)), int64(min(to, 1<<63-1)))
+}
+
+func (s *sqliteStore) rounds(query string, args ...any) ([]*beacon.Round, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rounds []*beacon.Round
+	var decodeErr error
+	err := s.query(query, args, func(st *stmt) {
+		r := new(beacon.Round)
+		if err := json.Unmarshal(st.blob(0), r); err != nil && decodeErr == nil {
+			decodeErr = err
+		}
+		rounds = append(rounds, r)
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	return rounds, err
+}
+
+func (s *sqliteStore) PutProof(key string, p *slothgo.Proof) error {
+	if p == nil {
+		return errors.New("proof cannot be nil")
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey("proofs", key, data)
+}
+
+func (s *sqliteStore) Proof(key string) (*slothgo.Proof, error) {
+	data, err := s.getKey("proofs", key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProof(data)
+}
+
+func (s *sqliteStore) DeleteProof(key string) error {
+	return s.deleteKey("proofs", key)
+}
+
+func (s *sqliteStore) PutCheckpoint(key string, cp *slothgo.Checkpoint) error {
+	if cp == nil {
+		return errors.New("checkpoint cannot be nil")
+	}
+	data, err := cp.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.putKey("checkpoints", key, data)
+}
+
+func (s *sqliteStore) Checkpoint(key string) (*slothgo.Checkpoint, error) {
+	data, err := s.getKey("checkpoints", key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+func (s *sqliteStore) DeleteCheckpoint(key string) error {
+	return s.deleteKey("checkpoints", key)
+}
+
+// putKey、getKey 与 deleteKey 的 table 只取固定的表名，不来自用户输入
+func (s *sqliteStore) putKey(table, key string, data []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.execArgs("INSERT OR REPLACE INTO "+table+" (key, data, created) VALUES (?, ?, ?)",
+		key, data, time.Now().UnixNano())
+	return err
+}
+
+func (s *sqliteStore) getKey(table, key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var data []byte
+	found := false
+	err := s.query("SELECT data FROM "+table+" WHERE key = ?", []any{key}, func(st *stmt) {
+		data, found = st.blob(0), true
+	})
+	if err == nil && !found {
+		err = ErrNotFound
+	}
+	return data, err
+}
+
+func (s *sqliteStore) deleteKey(table, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.execArgs("DELETE FROM "+table+" WHERE key = ?", key)
+	return err
+}
+
+func (s *sqliteStore) Prune(r Retention) (Pruned, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pruned Pruned
+	before := r.pruneBefore(s.next)
+	var err error
+	if before > 0 {
+		if pruned.Rounds, err = s.execArgs("DELETE FROM rounds WHERE idx < ?", int64(before)); err != nil {
+			return pruned, err
+		}
+	}
+	if r.MaxAge <= 0 {
+		return pruned, nil
+	}
+	cutoff := time.Now().Add(-r.MaxAge).UnixNano()
+	if pruned.Proofs, err = s.execArgs("DELETE FROM proofs WHERE created < ?", cutoff); err != nil {
+		return pruned, err
+	}
+	pruned.Checkpoints, err = s.execArgs("DELETE FROM checkpoints WHERE created < ?", cutoff)
+	return pruned, err
+}
+
+// exec 执行不带参数的多条语句
+func (s *sqliteStore) exec(sql string) error {
+	csql := C.CString(sql)
+	defer C.free(unsafe.Pointer(csql))
+	if rc := C.sqlite3_exec(s.db, csql, nil, nil, nil); rc != C.SQLITE_OK {
+		return sqliteError(s.db, rc)
+	}
+	return nil
+}
+
+// execArgs 执行一条带参数的语句，返回受影响的行数
+func (s *sqliteStore) execArgs(sql string, args ...any) (int, error) {
+	var n int
+	err := s.query(sql, args, nil)
+	if err == nil {
+		n = int(C.sqlite3_changes(s.db))
+	}
+	return n, err
+}
+
+// query 准备并执行一条语句，对结果的每一行调用 row (可以为 nil)
+// exec、execArgs 与 query 的调用方必须持有 s.mu (OpenSQLite 除外)，sqlite3_changes 依赖同一连接上的上一条语句
+func (s *sqliteStore) query(sql string, args []any, row func(*stmt)) error {
+	if s.db == nil {
+		return errors.New("store: database is closed")
+	}
+	csql := C.CString(sql)
+	defer C.free(unsafe.Pointer(csql))
+	st := &stmt{}
+	if rc := C.sqlite3_prepare_v2(s.db, csql, -1, &st.s, nil); rc != C.SQLITE_OK {
+		return sqliteError(s.db, rc)
+	}
+	defer C.sqlite3_finalize(st.s)
+	for i, arg := range args {
+		if err := st.bind(C.int(i+1), arg); err != nil {
+			return err
+		}
+	}
+	for {
+		switch rc := C.sqlite3_step(st.s); rc {
+		case C.SQLITE_ROW:
+			if row != nil {
+				row(st)
+			}
+		case C.SQLITE_DONE:
+			return nil
+		default:
+			return sqliteError(s.db, rc)
+		}
+	}
+}
+
+// stmt 是一条已准备的语句
+type stmt struct {
+	s *C.sqlite3_stmt
+}
+
+func (st *stmt) bind(i C.int, arg any) error {
+	var rc C.int
+	switch v := arg.(type) {
+	case int64:
+		rc = C.sqlite3_bind_int64(st.s, i, C.sqlite3_int64(v))
+	case string:
+		cs := C.CString(v)
+		defer C.free(unsafe.Pointer(cs))
+		rc = C.slothgo_bind_text(st.s, i, cs, C.int(len(v)))
+	case []byte:
+		if len(v) == 0 {
+			rc = C.sqlite3_bind_zeroblob(st.s, i, 0)
+			break
+		}
+		rc = C.slothgo_bind_blob(st.s, i, unsafe.Pointer(&v[0]), C.int(len(v)))
+	default:
+		return fmt.Errorf("store: unsupported argument type %T", arg)
+	}
+	if rc != C.SQLITE_OK {
+		return fmt.Errorf("store: bind failed: %s", C.GoString(C.sqlite3_errstr(rc)))
+	}
+	return nil
+}
+
+func (st *stmt) null(col C.int) bool {
+	return C.sqlite3_column_type(st.s, col) == C.SQLITE_NULL
+}
+
+func (st *stmt) int64(col C.int) int64 {
+	return int64(C.sqlite3_column_int64(st.s, col))
+}
+
+func (st *stmt) blob(col C.int) []byte {
+	n := C.sqlite3_column_bytes(st.s, col)
+	if n == 0 {
+		return []byte{}
+	}
+	return C.GoBytes(C.sqlite3_column_blob(st.s, col), n)
+}
+
+func sqliteError(db *C.sqlite3, rc C.int) error {
+	if db == nil {
+		return fmt.Errorf("store: sqlite: %s", C.GoString(C.sqlite3_errstr(rc)))
+	}
+	return fmt.Errorf("store: sqlite: %s", C.GoString(C.sqlite3_errmsg(db)))
+}
@@ -0,0 +1,13 @@
+//go:build !cgo || !sqlite
+
+package store
+
+import "errors"
+
+// SQLiteAvailable 报告当前构建是否包含 SQLite 后端
+const SQLiteAvailable = false
+
+// OpenSQLite 在未启用 cgo 或 sqlite 构建标签时总是返回错误，可以改用 OpenFileStore
+func OpenSQLite(path string) (Store, error) {
+	return nil, errors.New("store: built without the sqlite build tag")
+}
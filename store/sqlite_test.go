@@ -0,0 +1,31 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSQLite 只在启用 sqlite 构建标签时运行
+func TestSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sloth.db")
+	if !SQLiteAvailable {
+		if _, err := OpenSQLite(path); err == nil {
+			t.Error("OpenSQLite succeeded without the sqlite build tag")
+		}
+		t.Skip("built without the sqlite build tag")
+	}
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	testStore(t, s, func(old Store) Store {
+		if err := old.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		s, err := OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("reopening failed: %v", err)
+		}
+		return s
+	})
+}
@@ -0,0 +1,102 @@
+// Package store 持久化证明、检查点与信标轮次，提供按轮次序号的范围查询与清理策略
+// 三种后端实现相同的 Store 接口: FileStore 把每条记录保存为目录中的一个文件，只依赖标准库;
+// OpenBolt 把全部记录保存在一个 bbolt 数据库文件中，纯 Go 实现;
+// SQLite 同样使用一个数据库文件，需要 cgo 与 sqlite 构建标签 (见 SQLiteAvailable)。
+// Store 同时实现 beacon.Store，可以直接传给 beacon.New 与 p2p.NewNode
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// ErrNotFound 表示记录不存在或已被清理，与 beacon.ErrNotFound 相同
+var ErrNotFound = beacon.ErrNotFound
+
+// MaxKeySize 是证明与检查点的键的最大字节数
+const MaxKeySize = 100
+
+// Store 是证明、检查点与信标轮次的持久化存储，实现必须可以被并发调用
+// 证明与检查点以应用自定义的键保存 (例如任务 ID)，同一个键再次写入时覆盖旧值
+type Store interface {
+	beacon.Store
+
+	// Rounds 按序号升序返回 [from, to) 中仍然保存的轮次
+	Rounds(from, to uint64) ([]*beacon.Round, error)
+
+	// PutProof 保存证明，Proof 返回保存的证明，DeleteProof 删除证明 (不存在时不返回错误)
+	PutProof(key string, p *slothgo.Proof) error
+	Proof(key string) (*slothgo.Proof, error)
+	DeleteProof(key string) error
+
+	// PutCheckpoint 保存检查点，通常用作 slothgo.SaveFunc 的实现; 其余方法与证明相同
+	PutCheckpoint(key string, cp *slothgo.Checkpoint) error
+	Checkpoint(key string) (*slothgo.Checkpoint, error)
+	DeleteCheckpoint(key string) error
+
+	// Prune 按保留策略删除过期的记录
+	Prune(r Retention) (Pruned, error)
+	// Close 释放存储占用的资源
+	Close() error
+}
+
+// Retention 是 Prune 使用的保留策略，零值字段表示不按该条件清理
+type Retention struct {
+	KeepRounds uint64        // 保留最近的多少轮，更早的轮次被删除
+	MaxAge     time.Duration // 证明与检查点最后一次写入之后保留多久
+}
+
+// Pruned 是一次 Prune 删除的记录数
+type Pruned struct {
+	Rounds      int
+	Proofs      int
+	Checkpoints int
+}
+
+// checkKey 检查证明与检查点的键
+func checkKey(key string) error {
+	if key == "" || len(key) > MaxKeySize {
+		return fmt.Errorf("key must be 1 to %d bytes", MaxKeySize)
+	}
+	return nil
+}
+
+// checkNext 检查新的一轮是否紧接在最新的一轮之后，next 是下一轮的序号 (还没有任何轮次时为 0)
+func checkNext(r *beacon.Round, next uint64) error {
+	if r == nil {
+		return errors.New("round cannot be nil")
+	}
+	if r.Index != next {
+		return fmt.Errorf("round %d is out of order, expected %d", r.Index, next)
+	}
+	return nil
+}
+
+// pruneBefore 返回按 KeepRounds 应当删除的轮次的上界 (不含)，next 是下一轮的序号
+// KeepRounds 至少为 1，因此最新的一轮总是保留，重新打开存储后仍然可以确定下一轮的序号
+func (r Retention) pruneBefore(next uint64) uint64 {
+	if r.KeepRounds == 0 || next <= r.KeepRounds {
+		return 0
+	}
+	return next - r.KeepRounds
+}
+
+func decodeProof(data []byte) (*slothgo.Proof, error) {
+	p := new(slothgo.Proof)
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func decodeCheckpoint(data []byte) (*slothgo.Checkpoint, error) {
+	cp := new(slothgo.Checkpoint)
+	if err := cp.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/beacon"
+)
+
+// testRounds 发布 n 轮信标并返回它们与使用的实例
+func testRounds(t *testing.T, n int) (*slothgo.Sloth, []*beacon.Round) {
+	t.Helper()
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 100)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	b := beacon.New(vdf, beacon.NewMemoryStore())
+	rounds := make([]*beacon.Round, n)
+	for i := range rounds {
+		b.Contribute([]byte{byte(i)})
+		if rounds[i], err = b.Publish(context.Background()); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	return vdf, rounds
+}
+
+// testStore 是各个后端共用的行为检查，reopen 关闭存储后重新打开同一位置
+func testStore(t *testing.T, s Store, reopen func(Store) Store) {
+	vdf, rounds := testRounds(t, 7)
+	if _, err := s.Latest(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Latest on an empty store returned %v", err)
+	}
+	if err := s.Put(rounds[1]); err == nil {
+		t.Error("out-of-order round was accepted")
+	}
+	for _, r := range rounds[:6] {
+		if err := s.Put(r); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	if err := s.Put(rounds[5]); err == nil {
+		t.Error("duplicate round was accepted")
+	}
+
+	got, err := s.Rounds(2, 4)
+	if err != nil || len(got) != 2 || got[0].Index != 2 || got[1].Index != 3 {
+		t.Fatalf("Rounds(2, 4) = %v, %v", got, err)
+	}
+	if got, _ := s.Rounds(4, ^uint64(0)); len(got) != 2 {
+		t.Errorf("Rounds(4, max) returned %d rounds", len(got))
+	}
+	// 读回的轮次仍然可以被验证
+	for i := 1; i < 6; i++ {
+		r, err := s.Get(uint64(i))
+		if err != nil {
+			t.Fatalf("Get(%d) failed: %v", i, err)
+		}
+		if err := beacon.Verify(vdf, r, rounds[i-1]); err != nil {
+			t.Fatalf("stored round %d does not verify: %v", i, err)
+		}
+	}
+
+	proof, err := vdf.ComputeProof([]byte("job"))
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+	var cp *slothgo.Checkpoint
+	vdf.ComputeCheckpointed(context.Background(), []byte("job"), 40, func(c *slothgo.Checkpoint) error {
+		cp = c
+		return nil
+	})
+	if err := s.PutProof("job-1", proof); err != nil {
+		t.Fatalf("PutProof failed: %v", err)
+	}
+	if err := s.PutCheckpoint("job-1", cp); err != nil {
+		t.Fatalf("PutCheckpoint failed: %v", err)
+	}
+	if err := s.PutProof(strings.Repeat("k", MaxKeySize+1), proof); err == nil {
+		t.Error("oversized key was accepted")
+	}
+
+	s = reopen(s)
+	if latest, err := s.Latest(); err != nil || latest.Index != 5 {
+		t.Fatalf("Latest after reopen = %v, %v", latest, err)
+	}
+	if err := s.Put(rounds[0]); err == nil {
+		t.Error("round 0 was accepted after reopen")
+	}
+	p, err := s.Proof("job-1")
+	if err != nil {
+		t.Fatalf("Proof failed: %v", err)
+	}
+	if ok, err := vdf.VerifyProof([]byte("job"), p); !ok {
+		t.Fatalf("stored proof does not verify: %v", err)
+	}
+	c, err := s.Checkpoint("job-1")
+	if err != nil || c.Iteration != cp.Iteration || c.W.Cmp(cp.W) != 0 || !bytes.Equal(c.Fingerprint, cp.Fingerprint) {
+		t.Fatalf("Checkpoint = %+v, %v", c, err)
+	}
+
+	// 轮次按 KeepRounds 清理，证明与检查点按 MaxAge 清理
+	pruned, err := s.Prune(Retention{KeepRounds: 2, MaxAge: time.Hour})
+	if err != nil || pruned != (Pruned{Rounds: 4}) {
+		t.Fatalf("Prune = %+v, %v", pruned, err)
+	}
+	if _, err := s.Get(3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("pruned round is still stored: %v", err)
+	}
+	if got, _ := s.Rounds(0, 10); len(got) != 2 || got[0].Index != 4 {
+		t.Errorf("Rounds after Prune returned %d rounds", len(got))
+	}
+	time.Sleep(time.Millisecond)
+	pruned, err = s.Prune(Retention{MaxAge: time.Nanosecond})
+	if err != nil || pruned != (Pruned{Proofs: 1, Checkpoints: 1}) {
+		t.Fatalf("Prune = %+v, %v", pruned, err)
+	}
+	if _, err := s.Proof("job-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("pruned proof is still stored: %v", err)
+	}
+	if err := s.DeleteCheckpoint("job-1"); err != nil {
+		t.Errorf("deleting a missing checkpoint failed: %v", err)
+	}
+
+	// 清理之后新的一轮仍然接在最新的一轮之后
+	s = reopen(s)
+	if err := s.Put(rounds[6]); err != nil {
+		t.Errorf("Put after Prune failed: %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,84 @@
+#!/usr/bin/env python3
+"""独立于 Go 实现的 Sloth 参考实现，用于生成 reference.json 中的测试向量。
+
+只支持 p ≡ 3 (mod 4)、SHA-256 与 w₀ = int(h(input)) mod p; 置换为默认的 "sqrt"
+或按论文约定的 "sqrt-lw15" (σ 翻转提升值的最低位，p - 1 为不动点)。
+运行: python3 sloth_reference.py > reference.json
+"""
+import hashlib
+import json
+import sys
+
+
+def sigma(x):
+    if x == 0:
+        return 0
+    return x - 1 if x % 2 == 0 else x + 1
+
+
+def sigma_lw15(x, p):
+    if x == p - 1:
+        return x
+    return x ^ 1
+
+
+def rho(x, p):
+    e = (p + 1) // 4
+    r = pow(x, e, p)
+    residue = r * r % p == x
+    if not residue:
+        r = pow(-x % p, e, p)
+    if (r % 2 == 0) != residue:
+        r = (p - r) % p
+    return r
+
+
+SIGMAS = {
+    "sqrt": lambda x, p: sigma(x),
+    "sqrt-lw15": sigma_lw15,
+}
+
+
+def sloth(p, iterations, data, permutation):
+    sig = SIGMAS[permutation]
+    w = int.from_bytes(hashlib.sha256(data).digest(), "big") % p
+    for _ in range(iterations):
+        w = rho(sig(w, p), p)
+    size = (p.bit_length() + 7) // 8
+    witness = w.to_bytes(size, "big")
+    return witness, hashlib.sha256(witness).digest()
+
+
+PRIMES = [
+    2**61 - 1,
+    2**127 - 1,
+    2**256 - 2**32 - 977,
+]
+INPUTS = [b"", b"abc", b"A random zoo: sloth, unicorn, and trx"]
+
+
+def main():
+    vectors = []
+    for permutation in SIGMAS:
+        for p in PRIMES:
+            for iterations in (1, 1000):
+                for data in INPUTS:
+                    witness, digest = sloth(p, iterations, data, permutation)
+                    vectors.append({
+                        "params": {
+                            "version": 1,
+                            "p": format(p, "x").zfill((p.bit_length() + 7) // 8 * 2),
+                            "iterations": iterations,
+                            "hash": "sha256",
+                            "permutation": permutation,
+                        },
+                        "input": data.hex(),
+                        "witness": witness.hex(),
+                        "hash": digest.hex(),
+                    })
+    json.dump({"source": "sloth_reference.py", "vectors": vectors}, sys.stdout, indent=2)
+    print()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,122 @@
+// Package testvectors 生成与加载跨实现的 Sloth 测试向量
+// 向量以 JSON 保存，其他语言的实现 (例如 testdata/sloth_reference.py) 生成的向量可以用 Check 在 Go 实现上回放
+package testvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// Vector 是一条测试向量: 在 Params 下对 Input 计算得到的见证与输出哈希
+// Witness 使用 EncodeWitness 的定长大端编码
+type Vector struct {
+	Params  *slothgo.Params `json:"params"`
+	Input   string          `json:"input"`
+	Witness string          `json:"witness"`
+	Hash    string          `json:"hash"`
+}
+
+// File 是向量文件的顶层结构
+type File struct {
+	Source  string   `json:"source,omitempty"` // 生成向量的实现
+	Vectors []Vector `json:"vectors"`
+}
+
+// Generate 使用 s 对每个输入计算一条向量
+func Generate(s *slothgo.Sloth, inputs [][]byte) ([]Vector, error) {
+	vectors := make([]Vector, 0, len(inputs))
+	for _, input := range inputs {
+		hash, witness, err := s.Compute(input)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, Vector{
+			Params:  s.Params(),
+			Input:   hex.EncodeToString(input),
+			Witness: hex.EncodeToString(s.EncodeWitness(witness)),
+			Hash:    hex.EncodeToString(hash),
+		})
+	}
+	return vectors, nil
+}
+
+// Write 把向量以带缩进的 JSON 写入 w
+func Write(w io.Writer, f *File) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
+
+// Load 从 r 读取向量文件
+func Load(r io.Reader) (*File, error) {
+	var f File
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to decode test vectors: %w", err)
+	}
+	for i, v := range f.Vectors {
+		if v.Params == nil {
+			return nil, fmt.Errorf("vector %d has no params", i)
+		}
+	}
+	return &f, nil
+}
+
+// Check 在 Go 实现上重新计算 v，并确认见证与哈希一致、且能通过验证
+func Check(v Vector) error {
+	if v.Params == nil {
+		return errors.New("vector has no params")
+	}
+	s, err := slothgo.NewFromParams(v.Params)
+	if err != nil {
+		return err
+	}
+	input, err := hex.DecodeString(v.Input)
+	if err != nil {
+		return fmt.Errorf("invalid hex in input: %w", err)
+	}
+	wantWitness, err := hex.DecodeString(v.Witness)
+	if err != nil {
+		return fmt.Errorf("invalid hex in witness: %w", err)
+	}
+	wantHash, err := hex.DecodeString(v.Hash)
+	if err != nil {
+		return fmt.Errorf("invalid hex in hash: %w", err)
+	}
+
+	hash, witness, err := s.Compute(input)
+	if err != nil {
+		return err
+	}
+	if got := s.EncodeWitness(witness); !bytes.Equal(got, wantWitness) {
+		return fmt.Errorf("witness mismatch: got %x, want %x", got, wantWitness)
+	}
+	if !bytes.Equal(hash, wantHash) {
+		return fmt.Errorf("hash mismatch: got %x, want %x", hash, wantHash)
+	}
+	ok, err := s.Verify(input, wantHash, witness)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("vector does not verify")
+	}
+	return nil
+}
+
+// CheckAll 依次检查 f 中的所有向量，返回第一个失败的向量的错误
+func CheckAll(f *File) error {
+	for i, v := range f.Vectors {
+		if err := Check(v); err != nil {
+			return fmt.Errorf("vector %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package testvectors
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// TestReferenceVectors 回放 testdata/sloth_reference.py 生成的向量
+func TestReferenceVectors(t *testing.T) {
+	data, err := os.Open("testdata/reference.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+	f, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Vectors) == 0 {
+		t.Fatal("no vectors loaded")
+	}
+	if err := CheckAll(f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	p, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	s, err := slothgo.New(p, 50, slothgo.WithHash(slothgo.HashSHA3_256), slothgo.WithDomainTag([]byte("vectors")), slothgo.WithHashToField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := Generate(s, [][]byte{nil, []byte("abc")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &File{Source: "sloth_go", Vectors: vectors}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckAll(f); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Vectors[1].Hash = f.Vectors[0].Hash
+	if err := CheckAll(f); err == nil {
+		t.Fatal("tampered vector passed")
+	}
+}
@@ -0,0 +1,235 @@
+// Package timelock 基于 Sloth 实现时间锁加密
+// Sloth 的 τ 是置换且 τ⁻¹ 很快，因此封装方可以随机选取终点 w_l，
+// 快速逆向迭代 l 次得到起点 w₀ 并公开。打开方必须从 w₀ 顺序地正向迭代 l 次才能得到 w_l，
+// 再由 w_l 派生出对称密钥。w_l 同时就是 Sloth 的 witness，第三方可以快速验证打开方确实完成了计算
+package timelock
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+// keyInfo 是 HKDF 派生密钥时使用的 info
+const keyInfo = "slothgo/timelock/v1"
+
+// Capsule 是时间锁密文
+type Capsule struct {
+	Start       *big.Int // 公开的起点 w₀
+	Iterations  uint64   // 打开所需的迭代次数
+	Fingerprint []byte   // 封装时使用的参数指纹
+	Nonce       []byte
+	Ciphertext  []byte
+}
+
+// capsuleJSON 是 Capsule 的 JSON 表示，字节数组与起点均为十六进制字符串
+type capsuleJSON struct {
+	Start       string `json:"start"`
+	Iterations  uint64 `json:"iterations"`
+	Fingerprint string `json:"fingerprint"`
+	Nonce       string `json:"nonce"`
+	Ciphertext  string `json:"ciphertext"`
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (c Capsule) MarshalJSON() ([]byte, error) {
+	if c.Start == nil {
+		return nil, errors.New("capsule start cannot be nil")
+	}
+	return json.Marshal(capsuleJSON{
+		Start:       c.Start.Text(16),
+		Iterations:  c.Iterations,
+		Fingerprint: hex.EncodeToString(c.Fingerprint),
+		Nonce:       hex.EncodeToString(c.Nonce),
+		Ciphertext:  hex.EncodeToString(c.Ciphertext),
+	})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (c *Capsule) UnmarshalJSON(data []byte) error {
+	var v capsuleJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	start, ok := new(big.Int).SetString(v.Start, 16)
+	if !ok || start.Sign() < 0 {
+		return errors.New("invalid hex in start")
+	}
+	capsule := Capsule{Start: start, Iterations: v.Iterations}
+	for _, f := range []struct {
+		name string
+		dst  *[]byte
+		src  string
+	}{
+		{"fingerprint", &capsule.Fingerprint, v.Fingerprint},
+		{"nonce", &capsule.Nonce, v.Nonce},
+		{"ciphertext", &capsule.Ciphertext, v.Ciphertext},
+	} {
+		b, err := hex.DecodeString(f.src)
+		if err != nil {
+			return fmt.Errorf("invalid hex in %s: %w", f.name, err)
+		}
+		*f.dst = b
+	}
+	*c = capsule
+	return nil
+}
+
+// Seal 使用 delay 的参数封装 plaintext，打开时需要顺序计算 delay.Iterations 次 τ
+// 封装本身只需要逆向迭代，耗时与一次验证相当。delay 开启了 slothgo.WithZeroize 时，
+// 终点与派生的密钥在返回前清零
+func Seal(delay *slothgo.Sloth, plaintext []byte) (*Capsule, error) {
+	end, err := rand.Int(rand.Reader, delay.P)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample end value: %w", err)
+	}
+	if delay.Zeroizes() {
+		defer slothgo.Wipe(end)
+	}
+	st, err := delay.NewStepperAt(end, delay.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	for st.Index() > 0 {
+		st.Prev()
+	}
+	if delay.Zeroizes() {
+		defer st.Wipe()
+	}
+
+	c := &Capsule{
+		Start:       st.Value(),
+		Iterations:  delay.Iterations,
+		Fingerprint: delay.Fingerprint(),
+	}
+	aead, err := newAEAD(delay, end)
+	if err != nil {
+		return nil, err
+	}
+	c.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(c.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	c.Ciphertext = aead.Seal(nil, c.Nonce, plaintext, c.additionalData(delay))
+	return c, nil
+}
+
+// Open 顺序计算 τ 恢复密钥并解密，同时返回可供第三方验证的证明
+// proof.Witness 就是密钥材料，delay 开启了 slothgo.WithZeroize 时调用方应在用完后调用 slothgo.Wipe
+func Open(ctx context.Context, delay *slothgo.Sloth, c *Capsule) (plaintext []byte, proof *slothgo.Proof, err error) {
+	if err := checkCapsule(delay, c); err != nil {
+		return nil, nil, err
+	}
+	hash, witness, err := delay.ComputeFromCtx(ctx, c.Start, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return finishOpening(delay, c, hash, witness)
+}
+
+// OpenJournaled 与 Open 相同，但把进度记录在 j 中 (见 slothgo.Journal)，
+// 进程重启后以同一个日志再次调用即从最近的快照继续，适合需要计算数小时的密文
+func OpenJournaled(ctx context.Context, delay *slothgo.Sloth, c *Capsule, j *slothgo.Journal) (plaintext []byte, proof *slothgo.Proof, err error) {
+	if err := checkCapsule(delay, c); err != nil {
+		return nil, nil, err
+	}
+	hash, witness, err := delay.ComputeFromJournaled(ctx, c.Start, j)
+	if err != nil {
+		return nil, nil, err
+	}
+	return finishOpening(delay, c, hash, witness)
+}
+
+// finishOpening 用计算得到的 witness 解密并组装证明
+func finishOpening(delay *slothgo.Sloth, c *Capsule, hash []byte, witness *big.Int) (plaintext []byte, proof *slothgo.Proof, err error) {
+	plaintext, err = decrypt(delay, witness, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, delay.NewProof(hash, witness), nil
+}
+
+// VerifyOpening 检查 proof 是 c 的正确打开: witness 逆向迭代后回到 c.Start，且能够解密 c
+func VerifyOpening(ctx context.Context, delay *slothgo.Sloth, c *Capsule, proof *slothgo.Proof) (bool, error) {
+	if _, err := Decrypt(ctx, delay, c, proof); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Decrypt 用他人公布的打开证明解密 c，只需要一次验证的时间而不必重新计算 τ
+// proof 不是 c 的正确打开时返回错误
+func Decrypt(ctx context.Context, delay *slothgo.Sloth, c *Capsule, proof *slothgo.Proof) ([]byte, error) {
+	if err := checkCapsule(delay, c); err != nil {
+		return nil, err
+	}
+	if proof == nil {
+		return nil, errors.New("proof cannot be nil")
+	}
+	if ok, err := delay.VerifyFrom(ctx, c.Start, proof.Hash, proof.Witness); !ok {
+		if err == nil {
+			err = errors.New("proof does not open capsule")
+		}
+		return nil, err
+	}
+	return decrypt(delay, proof.Witness, c)
+}
+
+// checkCapsule 检查密文与 delay 的参数一致
+func checkCapsule(delay *slothgo.Sloth, c *Capsule) error {
+	if c == nil || c.Start == nil {
+		return errors.New("capsule is incomplete")
+	}
+	if c.Iterations != delay.Iterations || !bytes.Equal(c.Fingerprint, delay.Fingerprint()) {
+		return errors.New("capsule was sealed with different parameters")
+	}
+	return nil
+}
+
+func decrypt(delay *slothgo.Sloth, witness *big.Int, c *Capsule) ([]byte, error) {
+	aead, err := newAEAD(delay, witness)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, c.Nonce, c.Ciphertext, c.additionalData(delay))
+	if err != nil {
+		return nil, errors.New("failed to decrypt capsule")
+	}
+	return plaintext, nil
+}
+
+// newAEAD 由终点 w_l 的定长编码经 HKDF-SHA256 派生 AES-256-GCM 密钥
+// 保密模式下 w_l 的编码与密钥在创建密码后清零，AES 内部展开的轮密钥无法清零
+func newAEAD(delay *slothgo.Sloth, end *big.Int) (cipher.AEAD, error) {
+	secret := delay.EncodeWitness(end)
+	key, err := hkdf.Key(sha256.New, secret, nil, keyInfo, 32)
+	if delay.Zeroizes() {
+		clear(secret)
+		defer clear(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData 把公开参数绑定到密文上，防止替换起点或参数
+func (c *Capsule) additionalData(delay *slothgo.Sloth) []byte {
+	ad := append([]byte(keyInfo), c.Fingerprint...)
+	return append(ad, delay.EncodeWitness(c.Start)...)
+}
@@ -0,0 +1,184 @@
+package timelock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	delay, err := slothgo.New(p, 1000)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+	msg := []byte("open me later")
+
+	c, err := Seal(delay, msg)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	plaintext, proof, err := Open(ctx, delay, c)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(plaintext, msg) {
+		t.Errorf("Open returned %q, want %q", plaintext, msg)
+	}
+	if ok, err := VerifyOpening(ctx, delay, c, proof); !ok {
+		t.Fatalf("VerifyOpening failed: %v", err)
+	}
+
+	// 错误的 witness 不能通过验证
+	bad := *proof
+	bad.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	if ok, _ := VerifyOpening(ctx, delay, c, &bad); ok {
+		t.Error("expected wrong witness to be rejected")
+	}
+
+	// 迭代次数不同的参数不能打开
+	other, _ := delay.WithIterations(999)
+	if _, _, err := Open(ctx, other, c); err == nil {
+		t.Error("expected capsule to be rejected with different parameters")
+	}
+}
+
+// TestOpenJournaled 检查中断后用同一个日志继续打开
+func TestOpenJournaled(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	delay, err := slothgo.New(p, 5000)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	msg := []byte("survives a reboot")
+	c, err := Seal(delay, msg)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "open.journal")
+	j, err := slothgo.NewJournal(path, []byte("node key"), 0)
+	if err != nil {
+		t.Fatalf("NewJournal failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted, _ := slothgo.New(p, 5000, slothgo.WithProgress(func(done, total uint64) {
+		if done >= total/2 {
+			cancel()
+		}
+	}, 50))
+	if _, _, err := OpenJournaled(ctx, interrupted, c, j); err == nil {
+		t.Fatal("expected the interrupted opening to fail")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("no journal was written: %v", err)
+	}
+
+	plaintext, proof, err := OpenJournaled(context.Background(), delay, c, j)
+	if err != nil {
+		t.Fatalf("OpenJournaled failed: %v", err)
+	}
+	if !bytes.Equal(plaintext, msg) {
+		t.Errorf("OpenJournaled returned %q, want %q", plaintext, msg)
+	}
+	if ok, err := VerifyOpening(context.Background(), delay, c, proof); !ok {
+		t.Errorf("VerifyOpening failed: %v", err)
+	}
+}
+
+// TestCapsuleJSON 检查密文的 JSON 往返，以及只凭公布的证明解密
+func TestCapsuleJSON(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	delay, err := slothgo.New(p, 500)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+	c, err := Seal(delay, []byte("published later"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	_, proof, err := Open(ctx, delay, c)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Capsule
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	plaintext, err := Decrypt(ctx, delay, &decoded, proof)
+	if err != nil || string(plaintext) != "published later" {
+		t.Fatalf("Decrypt returned %q, %v", plaintext, err)
+	}
+
+	bad := *proof
+	bad.Witness = new(big.Int).Add(proof.Witness, big.NewInt(1))
+	if _, err := Decrypt(ctx, delay, &decoded, &bad); err == nil {
+		t.Error("expected wrong witness to be rejected")
+	}
+	for _, data := range []string{`{"start":"zz"}`, `{"start":"1","nonce":"x"}`} {
+		if err := json.Unmarshal([]byte(data), &decoded); err == nil {
+			t.Errorf("%s was accepted", data)
+		}
+	}
+}
+
+// TestZeroize 检查保密模式下的密文与普通模式互通
+func TestZeroize(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := slothgo.New(p, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := slothgo.New(p, 300, slothgo.WithZeroize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	msg := []byte("wiped after use")
+
+	c, err := Seal(secret, msg)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	got, proof, err := Open(ctx, plain, c)
+	if err != nil || !bytes.Equal(got, msg) {
+		t.Fatalf("Open returned %q, %v", got, err)
+	}
+	if got, err = Decrypt(ctx, secret, c, proof); err != nil || !bytes.Equal(got, msg) {
+		t.Fatalf("Decrypt returned %q, %v", got, err)
+	}
+	if got, _, err = Open(ctx, secret, c); err != nil || !bytes.Equal(got, msg) {
+		t.Fatalf("Open in secret mode returned %q, %v", got, err)
+	}
+
+	// 清零后的 witness 不再能打开密文
+	slothgo.Wipe(proof.Witness)
+	if _, err := Decrypt(ctx, secret, c, proof); err == nil {
+		t.Error("wiped proof still opens the capsule")
+	}
+}
@@ -0,0 +1,176 @@
+// Package timestamp 基于 Sloth 链实现去中心化的可信时间戳
+// 文档哈希被绑定进一条持续运行的 VDF 链: 第 k 段的输入同时承诺上一段的输出以及本段绑定的文档的 Merkle 根。
+// 之后的每一段都必须在绑定之后顺序地计算，因此从绑定所在的段到最新一段的证明说明:
+// 该文档在至少 (段数 × 每段迭代次数) 次顺序计算之前就已经存在
+package timestamp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/merkle"
+)
+
+// inputDomain 是每一段 Sloth 输入的域分隔前缀
+const inputDomain = "slothgo/timestamp/v1"
+
+// Segment 是链中的一段
+type Segment struct {
+	Index         uint64
+	Previous      []byte        // 上一段的输出哈希，第 0 段为创世值
+	DocumentsRoot []byte        // 本段绑定的文档哈希的 Merkle 根
+	Proof         slothgo.Proof // 本段的 Sloth 证明
+}
+
+// Attestation 证明文档在 Segments[0] 开始之前就已绑定，之后至少经过了 len(Segments) 段顺序计算
+type Attestation struct {
+	Document []byte   // 文档哈希
+	Position int      // 文档在 Segments[0] 绑定的文档中的序号
+	Size     int      // Segments[0] 绑定的文档数量
+	Path     [][]byte // 文档的 Merkle 包含证明
+	Segments []Segment
+}
+
+// Chain 是一条持续运行的时间戳链，可以被并发调用
+type Chain struct {
+	vdf *slothgo.Sloth
+
+	mu        sync.Mutex
+	pending   [][]byte
+	last      []byte // 最新一段的输出哈希，尚无任何段时为创世值
+	segments  []Segment
+	documents [][][]byte // documents[k] 是第 k 段绑定的文档哈希
+	index     map[string][2]int
+
+	stepMu sync.Mutex // 保证同一时间只有一段在计算
+}
+
+// NewChain 创建一条从 genesis 开始的时间戳链
+func NewChain(vdf *slothgo.Sloth, genesis []byte) *Chain {
+	return &Chain{vdf: vdf, last: bytes.Clone(genesis), index: make(map[string][2]int)}
+}
+
+// Bind 把文档哈希绑定进下一段，返回它将被绑定的段序号
+func (c *Chain) Bind(document []byte) (uint64, error) {
+	if len(document) != sha256.Size {
+		return 0, fmt.Errorf("document hash must be %d bytes", sha256.Size)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.index[string(document)]; ok {
+		return 0, errors.New("document is already bound")
+	}
+	for _, d := range c.pending {
+		if bytes.Equal(d, document) {
+			return 0, errors.New("document is already bound")
+		}
+	}
+	c.pending = append(c.pending, bytes.Clone(document))
+	return uint64(len(c.segments)), nil
+}
+
+// Step 计算下一段，把目前为止绑定的文档承诺进去
+func (c *Chain) Step(ctx context.Context) (*Segment, error) {
+	c.stepMu.Lock()
+	defer c.stepMu.Unlock()
+
+	c.mu.Lock()
+	docs := c.pending
+	c.pending = nil
+	k := len(c.segments)
+	seg := Segment{
+		Index:         uint64(k),
+		Previous:      c.last,
+		DocumentsRoot: merkle.Root(docs),
+	}
+	c.mu.Unlock()
+
+	proof, err := c.vdf.ComputeProofCtx(ctx, segmentInput(&seg))
+	if err != nil {
+		c.mu.Lock()
+		c.pending = append(docs, c.pending...)
+		c.mu.Unlock()
+		return nil, err
+	}
+	seg.Proof = *proof
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.segments = append(c.segments, seg)
+	c.last = proof.Hash
+	c.documents = append(c.documents, docs)
+	for i, d := range docs {
+		c.index[string(d)] = [2]int{k, i}
+	}
+	return &seg, nil
+}
+
+// Len 返回已经完成的段数
+func (c *Chain) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.segments)
+}
+
+// Attest 为已绑定且所在段已完成的文档生成时间戳证明，覆盖从绑定到最新一段的全部段
+func (c *Chain) Attest(document []byte) (*Attestation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	loc, ok := c.index[string(document)]
+	if !ok {
+		return nil, errors.New("document is not bound in a completed segment")
+	}
+	k, pos := loc[0], loc[1]
+	path, err := merkle.NewTree(c.documents[k]).Proof(pos)
+	if err != nil {
+		return nil, err
+	}
+	return &Attestation{
+		Document: bytes.Clone(document),
+		Position: pos,
+		Size:     len(c.documents[k]),
+		Path:     path,
+		Segments: append([]Segment(nil), c.segments[k:]...),
+	}, nil
+}
+
+// Verify 验证时间戳证明，返回文档绑定之后至少经过的顺序迭代次数
+func Verify(vdf *slothgo.Sloth, a *Attestation) (uint64, error) {
+	if a == nil || len(a.Segments) == 0 {
+		return 0, errors.New("attestation has no segments")
+	}
+	if !merkle.Verify(a.Segments[0].DocumentsRoot, a.Document, a.Position, a.Size, a.Path) {
+		return 0, errors.New("document is not included in the first segment")
+	}
+	for i := range a.Segments {
+		seg := &a.Segments[i]
+		if i > 0 {
+			prev := &a.Segments[i-1]
+			if seg.Index != prev.Index+1 || !bytes.Equal(seg.Previous, prev.Proof.Hash) {
+				return 0, fmt.Errorf("segment %d does not follow segment %d", seg.Index, prev.Index)
+			}
+		}
+		if ok, err := vdf.VerifyProof(segmentInput(seg), &seg.Proof); !ok {
+			return 0, fmt.Errorf("segment %d: %w", seg.Index, err)
+		}
+	}
+	return uint64(len(a.Segments)) * vdf.Iterations, nil
+}
+
+// segmentInput 计算第 k 段的 Sloth 输入:
+// SHA-256(域分隔前缀 || k(8, 大端) || len(previous)(8, 大端) || previous || 文档根)
+func segmentInput(seg *Segment) []byte {
+	h := sha256.New()
+	h.Write([]byte(inputDomain))
+	binary.Write(h, binary.BigEndian, seg.Index)
+	binary.Write(h, binary.BigEndian, uint64(len(seg.Previous)))
+	h.Write(seg.Previous)
+	h.Write(seg.DocumentsRoot)
+	return h.Sum(nil)
+}
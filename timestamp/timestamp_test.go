@@ -0,0 +1,84 @@
+package timestamp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	slothgo "github.com/alan22333/sloth_go"
+)
+
+func TestAttest(t *testing.T) {
+	p, err := slothgo.GenerateSlothPrime(64)
+	if err != nil {
+		t.Fatalf("GenerateSlothPrime failed: %v", err)
+	}
+	vdf, err := slothgo.New(p, 300)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+	c := NewChain(vdf, []byte("genesis"))
+
+	step := func() {
+		t.Helper()
+		if _, err := c.Step(ctx); err != nil {
+			t.Fatalf("Step failed: %v", err)
+		}
+	}
+
+	step()
+	doc := sha256.Sum256([]byte("contract.pdf"))
+	other := sha256.Sum256([]byte("other.pdf"))
+	if k, err := c.Bind(doc[:]); err != nil || k != 1 {
+		t.Fatalf("Bind returned %d, %v", k, err)
+	}
+	if _, err := c.Bind(other[:]); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if _, err := c.Bind(doc[:]); err == nil {
+		t.Error("expected duplicate binding to be rejected")
+	}
+	if _, err := c.Attest(doc[:]); err == nil {
+		t.Error("expected attestation before the segment completes to fail")
+	}
+	step()
+	step()
+	step()
+
+	a, err := c.Attest(doc[:])
+	if err != nil {
+		t.Fatalf("Attest failed: %v", err)
+	}
+	elapsed, err := Verify(vdf, a)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if elapsed != 3*vdf.Iterations {
+		t.Errorf("expected %d elapsed iterations, got %d", 3*vdf.Iterations, elapsed)
+	}
+
+	// 删去中间一段会破坏链接
+	broken := *a
+	broken.Segments = append([]Segment{a.Segments[0]}, a.Segments[2:]...)
+	if _, err := Verify(vdf, &broken); err == nil {
+		t.Error("expected attestation with a missing segment to be rejected")
+	}
+
+	// 换成未绑定的文档
+	forged := *a
+	forged.Document = make([]byte, sha256.Size)
+	if _, err := Verify(vdf, &forged); err == nil {
+		t.Error("expected unbound document to be rejected")
+	}
+}
+
+// TestSegmentInputLongPrevious 检查长度前缀不会在 256 字节处截断
+func TestSegmentInputLongPrevious(t *testing.T) {
+	a := &Segment{Index: 1, Previous: make([]byte, 256), DocumentsRoot: []byte{1}}
+	b := &Segment{Index: 1, Previous: make([]byte, 0), DocumentsRoot: append(make([]byte, 256), 1)}
+	if bytes.Equal(segmentInput(a), segmentInput(b)) {
+		t.Error("segment inputs with different previous values collide")
+	}
+}
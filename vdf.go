@@ -0,0 +1,38 @@
+package slothgo
+
+import (
+	"context"
+	"errors"
+)
+
+// VDF 是各种可验证延迟函数构造 (Sloth、Wesolowski、Pietrzak 等) 共享的接口
+// 输出和证明都以字节数组表示，具体编码由各构造自行定义
+type VDF interface {
+	// Evaluate 计算 input 的 VDF 输出以及验证所需的证明 (慢)
+	Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error)
+	// VerifyEvaluation 验证 output 和 proof 是否是 input 的正确 VDF 结果 (快)
+	VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error)
+}
+
+var _ VDF = (*Sloth)(nil)
+
+// Evaluate 实现 VDF 接口: output 为最终哈希 g, proof 为 witness 的定长大端编码 (见 EncodeWitness)
+func (s *Sloth) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	hash, witness, err := s.ComputeCtx(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hash, s.EncodeWitness(witness), nil
+}
+
+// VerifyEvaluation 实现 VDF 接口，proof 的编码与 Evaluate 相同
+func (s *Sloth) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	if proof == nil {
+		return false, errors.New("proof cannot be nil")
+	}
+	witness, err := s.DecodeWitness(proof)
+	if err != nil {
+		return false, err
+	}
+	return s.VerifyCtx(ctx, input, output, witness)
+}
@@ -0,0 +1,60 @@
+package slothgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// TestSloth_VDFInterface 检查 Sloth 通过 VDF 接口的计算与验证
+func TestSloth_VDFInterface(t *testing.T) {
+	var v VDF = testVDF
+	ctx := context.Background()
+
+	output, proof, err := v.Evaluate(ctx, testInput)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	verified, err := v.VerifyEvaluation(ctx, testInput, output, proof)
+	if err != nil || !verified {
+		t.Fatalf("VerifyEvaluation failed: %v, %v", verified, err)
+	}
+	if verified, _ := v.VerifyEvaluation(ctx, []byte("other"), output, proof); verified {
+		t.Error("expected VerifyEvaluation to reject wrong input")
+	}
+}
+
+// TestEncodeWitness 检查 witness 使用保留前导零的定长编码
+func TestEncodeWitness(t *testing.T) {
+	size := testVDF.WitnessSize()
+	small := big.NewInt(1)
+	enc := testVDF.EncodeWitness(small)
+	if len(enc) != size || enc[size-1] != 1 {
+		t.Fatalf("EncodeWitness(1) = %x, want %d bytes", enc, size)
+	}
+	if dec, err := testVDF.DecodeWitness(enc); err != nil || dec.Cmp(small) != 0 {
+		t.Errorf("DecodeWitness returned %v, %v", dec, err)
+	}
+	if _, err := testVDF.DecodeWitness(small.Bytes()); err == nil {
+		t.Error("expected short encoding to be rejected")
+	}
+	if _, err := testVDF.DecodeWitness(testVDF.EncodeWitness(testVDF.P)); err == nil {
+		t.Error("expected p to be rejected")
+	}
+
+	// 最终哈希作用于定长编码，而不是 big.Int.Bytes()
+	h := sha256.Sum256(enc)
+	if !bytes.Equal(testVDF.outputHash(small), h[:]) {
+		t.Error("output hash should use the fixed-width encoding")
+	}
+
+	_, proof, err := testVDF.Evaluate(context.Background(), testInput)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(proof) != size {
+		t.Errorf("Evaluate proof has %d bytes, want %d", len(proof), size)
+	}
+}
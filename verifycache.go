@@ -0,0 +1,170 @@
+package slothgo
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/alan22333/sloth_go/metrics"
+)
+
+// VerifyCache 记录最近验证通过的证明，同一个证明再次验证时直接返回 true
+// 许多请求引用同一轮信标时，只有第一次验证需要执行逆向迭代。缓存以参数指纹、输入与证明的全部字段
+// 为键，只保存有效的证明; 可以被多个实例与多个协程共享
+type VerifyCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // 最近使用的在前，元素为 *cacheEntry
+	entries map[[sha256.Size]byte]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	expires time.Time // 零值表示不过期
+}
+
+// VerifyCacheStats 是缓存的累计统计
+type VerifyCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// HitRate 返回命中次数占查询次数的比例，没有查询时为 0
+func (st VerifyCacheStats) HitRate() float64 {
+	if st.Hits+st.Misses == 0 {
+		return 0
+	}
+	return float64(st.Hits) / float64(st.Hits+st.Misses)
+}
+
+// NewVerifyCache 创建最多保存 size 个证明的缓存 (size <= 0 时为 1024)，超出时淘汰最久未使用的证明
+// ttl > 0 时证明在加入 ttl 之后过期，ttl <= 0 表示不过期
+func NewVerifyCache(size int, ttl time.Duration) *VerifyCache {
+	if size <= 0 {
+		size = 1024
+	}
+	return &VerifyCache{
+		size:    size,
+		ttl:     max(ttl, 0),
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// Stats 返回命中、未命中次数与当前保存的证明数
+func (c *VerifyCache) Stats() VerifyCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return VerifyCacheStats{Hits: c.hits, Misses: c.misses, Entries: c.order.Len()}
+}
+
+// Purge 清空缓存，统计保留
+func (c *VerifyCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
+
+// lookup 报告 key 是否在缓存中且未过期，命中时把它移到最前
+func (c *VerifyCache) lookup(key [sha256.Size]byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.expires.IsZero() || c.now().Before(e.expires) {
+			c.order.MoveToFront(el)
+			c.hits++
+			return true
+		}
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	c.misses++
+	return false
+}
+
+// add 加入一个验证通过的证明，必要时淘汰最久未使用的证明
+func (c *VerifyCache) add(key [sha256.Size]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// verifyCacheKey 返回 (实例参数, input, proof) 的缓存键; 输入或证明不完整时 ok 为 false，
+// 这类调用交给正常的验证路径返回错误
+func (s *Sloth) verifyCacheKey(input []byte, proof *Proof) (key [sha256.Size]byte, ok bool) {
+	if input == nil || proof == nil || proof.Witness == nil {
+		return key, false
+	}
+	h := sha256.New()
+	writeField := func(b []byte) {
+		binary.Write(h, binary.BigEndian, uint64(len(b)))
+		h.Write(b)
+	}
+	writeInt := func(x *big.Int) {
+		// Text 保留符号，避免 x 与 -x 得到相同的键
+		writeField([]byte(x.Text(16)))
+	}
+	writeField(s.Fingerprint())
+	binary.Write(h, binary.BigEndian, s.Iterations)
+	writeField(input)
+	writeField(proof.Fingerprint)
+	binary.Write(h, binary.BigEndian, proof.Iterations)
+	writeField(proof.Hash)
+	writeInt(proof.Witness)
+	binary.Write(h, binary.BigEndian, proof.CheckpointInterval)
+	binary.Write(h, binary.BigEndian, uint64(len(proof.Checkpoints)))
+	for _, cp := range proof.Checkpoints {
+		if cp == nil {
+			return key, false
+		}
+		writeInt(cp)
+	}
+	binary.Write(h, binary.BigEndian, proof.StateStride)
+	binary.Write(h, binary.BigEndian, proof.StateRoot != nil)
+	writeField(proof.StateRoot)
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+// cachedVerify 在配置了 WithVerifyCache 时查询缓存，hit 为 true 时证明已经验证过;
+// 未命中时返回的 remember 应在验证通过后调用，把证明加入缓存
+func (s *Sloth) cachedVerify(input []byte, proof *Proof) (hit bool, remember func()) {
+	if s.verifyCache == nil {
+		return false, func() {}
+	}
+	key, ok := s.verifyCacheKey(input, proof)
+	if !ok {
+		return false, func() {}
+	}
+	hit = s.verifyCache.lookup(key)
+	if cc, ok := s.metrics.(metrics.CacheCollector); ok {
+		cc.CacheLookup(hit)
+	}
+	return hit, func() { s.verifyCache.add(key) }
+}
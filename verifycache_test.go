@@ -0,0 +1,96 @@
+package slothgo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestVerifyCache 检查命中、只缓存有效证明、被篡改的证明不会命中以及命中率统计
+func TestVerifyCache(t *testing.T) {
+	cache := NewVerifyCache(8, 0)
+	vdf, err := New(testVDF.P, 200, WithVerifyCache(cache))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	proof, err := vdf.ComputeProof(testInput)
+	if err != nil {
+		t.Fatalf("ComputeProof failed: %v", err)
+	}
+
+	for range 3 {
+		if ok, err := vdf.VerifyProof(testInput, proof); !ok {
+			t.Fatalf("VerifyProof failed: %v", err)
+		}
+	}
+	// Verify 与 VerifyProof 共用同一个缓存项
+	if ok, err := vdf.Verify(testInput, proof.Hash, proof.Witness); !ok {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	// 已缓存的证明在被取消的 ctx 下也直接返回
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ok, _ := vdf.VerifyProofCtx(ctx, testInput, proof); !ok {
+		t.Error("cached proof was not accepted")
+	}
+	if st := cache.Stats(); st.Hits != 4 || st.Misses != 1 || st.Entries != 1 || st.HitRate() != 0.8 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	// 输入、witness 的符号、检查点的差异都不能命中
+	tampered := *proof
+	tampered.Witness = new(big.Int).Neg(proof.Witness)
+	segmented := *proof
+	segmented.CheckpointInterval, segmented.Checkpoints = 100, []*big.Int{big.NewInt(1)}
+	for name, verify := range map[string]func() (bool, error){
+		"input":      func() (bool, error) { return vdf.VerifyProof([]byte("other"), proof) },
+		"witness":    func() (bool, error) { return vdf.VerifyProof(testInput, &tampered) },
+		"checkpoint": func() (bool, error) { return vdf.VerifyProof(testInput, &segmented) },
+		"nil input":  func() (bool, error) { return vdf.VerifyProof(nil, proof) },
+	} {
+		for range 2 {
+			if ok, _ := verify(); ok {
+				t.Errorf("%s: invalid proof was accepted", name)
+			}
+		}
+	}
+	if st := cache.Stats(); st.Entries != 1 || st.Misses != 7 {
+		t.Errorf("invalid proofs were cached: %+v", st)
+	}
+
+	// 其他参数的实例共享缓存时不会命中
+	other, _ := New(testVDF.P, 100, WithVerifyCache(cache))
+	if ok, _ := other.VerifyProof(testInput, proof); ok {
+		t.Error("proof was accepted by an instance with different iterations")
+	}
+
+	cache.Purge()
+	if st := cache.Stats(); st.Entries != 0 || st.Hits != 4 {
+		t.Errorf("unexpected stats after Purge %+v", st)
+	}
+}
+
+// TestVerifyCacheEviction 检查容量淘汰与过期
+func TestVerifyCacheEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewVerifyCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+	key := func(b byte) [32]byte { return [32]byte{b} }
+
+	cache.add(key(1))
+	cache.add(key(2))
+	cache.lookup(key(1)) // 1 成为最近使用的
+	cache.add(key(3))    // 淘汰 2
+	if !cache.lookup(key(1)) || cache.lookup(key(2)) || !cache.lookup(key(3)) {
+		t.Error("least recently used entry was not evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if cache.lookup(key(1)) {
+		t.Error("expired entry was returned")
+	}
+	if st := cache.Stats(); st.Entries != 1 {
+		t.Errorf("expired entry was not removed: %+v", st)
+	}
+}
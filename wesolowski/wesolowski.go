@@ -0,0 +1,154 @@
+// Package wesolowski 实现 Wesolowski 在 "Efficient verifiable delay functions" 中提出的 VDF:
+// 在未知阶的群中计算 y = x^(2^T)，并给出只需两次幂运算即可验证的简洁证明 π
+// 群可以是 RSA 群 (需要可信模数，见 New) 或类群 (无需可信参数，见 NewClassGroup)
+package wesolowski
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	slothgo "github.com/alan22333/sloth_go"
+	"github.com/alan22333/sloth_go/classgroup"
+	"github.com/alan22333/sloth_go/group"
+	"github.com/alan22333/sloth_go/internal/hashutil"
+)
+
+// challengeBits 是 Fiat–Shamir 挑战素数 ℓ 的位数
+const challengeBits = 128
+
+// ctxCheckInterval 平方循环中每隔多少次检查一次 ctx 是否已取消
+const ctxCheckInterval = 1024
+
+// VDF 持有 Wesolowski VDF 的参数，E 是群元素的类型
+type VDF[E any] struct {
+	G group.Group[E] // 未知阶的群
+	T int64          // 连续平方的次数 (延迟参数)
+}
+
+var (
+	_ slothgo.VDF = (*VDF[*big.Int])(nil)
+	_ slothgo.VDF = (*VDF[*classgroup.Form])(nil)
+)
+
+// New 在模数为 n 的 RSA 群中创建 Wesolowski VDF 实例
+func New(n *big.Int, t int64) (*VDF[*big.Int], error) {
+	g, err := group.NewRSA(n)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithGroup[*big.Int](g, t)
+}
+
+// NewClassGroup 在判别式为 d 的类群中创建 Wesolowski VDF 实例
+func NewClassGroup(d *big.Int, t int64) (*VDF[*classgroup.Form], error) {
+	g, err := classgroup.New(d)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithGroup[*classgroup.Form](g, t)
+}
+
+// NewWithGroup 在任意未知阶的群 g 中创建 Wesolowski VDF 实例
+func NewWithGroup[E any](g group.Group[E], t int64) (*VDF[E], error) {
+	if t <= 0 {
+		return nil, errors.New("t must be positive")
+	}
+	if g == nil {
+		return nil, errors.New("group cannot be nil")
+	}
+	return &VDF[E]{G: g, T: t}, nil
+}
+
+// GenerateModulus 生成一个 bits 位的 RSA 模数，见 group.GenerateModulus
+func GenerateModulus(bits int) (*big.Int, error) {
+	return group.GenerateModulus(bits)
+}
+
+// Prove 计算 y = x^(2^T) 以及证明 π = x^⌊2^T/ℓ⌋，其中 x = H(input)
+func (v *VDF[E]) Prove(ctx context.Context, input []byte) (y, pi E, err error) {
+	x := v.G.HashToElement("wesolowski/hash-to-group", input)
+
+	// y = x^(2^T)
+	y = x
+	for i := int64(0); i < v.T; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return y, pi, err
+			}
+		}
+		y = v.G.Square(y)
+	}
+
+	// 使用长除法逐位计算 π = x^⌊2^T/ℓ⌋
+	l := v.hashToPrime(x, y)
+	pi = v.G.Identity()
+	r := big.NewInt(1)
+	for i := int64(0); i < v.T; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return y, pi, err
+			}
+		}
+		r.Lsh(r, 1)
+		pi = v.G.Square(pi)
+		if r.Cmp(l) >= 0 {
+			r.Sub(r, l)
+			pi = v.G.Mul(pi, x)
+		}
+	}
+	return y, pi, nil
+}
+
+// Verify 检查 π^ℓ · x^r = y，其中 r = 2^T mod ℓ
+func (v *VDF[E]) Verify(input []byte, y, pi E) (bool, error) {
+	if err := v.G.Validate(y); err != nil {
+		return false, err
+	}
+	if err := v.G.Validate(pi); err != nil {
+		return false, err
+	}
+	x := v.G.HashToElement("wesolowski/hash-to-group", input)
+	l := v.hashToPrime(x, y)
+	r := new(big.Int).Exp(big.NewInt(2), big.NewInt(v.T), l)
+
+	lhs := v.G.Mul(v.G.Exp(pi, l), v.G.Exp(x, r))
+	if !v.G.Equal(lhs, y) {
+		return false, errors.New("verification failed: π^ℓ·x^r does not equal y")
+	}
+	return true, nil
+}
+
+// Evaluate 实现 slothgo.VDF 接口: output 为 y, proof 为 π, 均使用群的定长编码
+func (v *VDF[E]) Evaluate(ctx context.Context, input []byte) (output, proof []byte, err error) {
+	y, pi, err := v.Prove(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v.G.Encode(y), v.G.Encode(pi), nil
+}
+
+// VerifyEvaluation 实现 slothgo.VDF 接口
+func (v *VDF[E]) VerifyEvaluation(ctx context.Context, input, output, proof []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	y, err := v.G.Decode(output)
+	if err != nil {
+		return false, err
+	}
+	pi, err := v.G.Decode(proof)
+	if err != nil {
+		return false, err
+	}
+	return v.Verify(input, y, pi)
+}
+
+// hashToPrime 由 (x, y) 派生 Fiat–Shamir 挑战素数 ℓ
+func (v *VDF[E]) hashToPrime(x, y E) *big.Int {
+	data := binary.BigEndian.AppendUint64(nil, uint64(v.T))
+	data = append(data, v.G.Encode(x)...)
+	data = append(data, v.G.Encode(y)...)
+	return hashutil.ToPrime("wesolowski/hash-to-prime", data, challengeBits)
+}
@@ -0,0 +1,69 @@
+package wesolowski
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/alan22333/sloth_go/classgroup"
+)
+
+// TestProveAndVerify 检查证明可以验证通过，且被篡改的输出或证明会被拒绝
+func TestProveAndVerify(t *testing.T) {
+	n, err := GenerateModulus(512)
+	if err != nil {
+		t.Fatalf("GenerateModulus failed: %v", err)
+	}
+	v, err := New(n, 2000)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	input := []byte("A random zoo: sloth, unicorn, and trx")
+
+	y, pi, err := v.Prove(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Prove failed: %v", err)
+	}
+	if ok, err := v.Verify(input, y, pi); !ok || err != nil {
+		t.Fatalf("Verify failed: %v, %v", ok, err)
+	}
+
+	if ok, _ := v.Verify([]byte("wrong input"), y, pi); ok {
+		t.Error("expected wrong input to be rejected")
+	}
+	if ok, _ := v.Verify(input, new(big.Int).Add(y, big.NewInt(1)), pi); ok {
+		t.Error("expected tampered output to be rejected")
+	}
+	if ok, _ := v.Verify(input, y, new(big.Int).Add(pi, big.NewInt(1))); ok {
+		t.Error("expected tampered proof to be rejected")
+	}
+
+	output, proof, err := v.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), input, output, proof); !ok || err != nil {
+		t.Fatalf("VerifyEvaluation failed: %v, %v", ok, err)
+	}
+}
+
+// TestClassGroup 检查类群后端 (无需可信参数) 的证明可以验证通过
+func TestClassGroup(t *testing.T) {
+	d := classgroup.DeriveDiscriminant([]byte("wesolowski test"), 256)
+	v, err := NewClassGroup(d, 200)
+	if err != nil {
+		t.Fatalf("NewClassGroup failed: %v", err)
+	}
+	input := []byte("A random zoo: sloth, unicorn, and trx")
+
+	output, proof, err := v.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := v.VerifyEvaluation(context.Background(), input, output, proof); !ok || err != nil {
+		t.Fatalf("VerifyEvaluation failed: %v, %v", ok, err)
+	}
+	if ok, _ := v.VerifyEvaluation(context.Background(), []byte("wrong input"), output, proof); ok {
+		t.Error("expected wrong input to be rejected")
+	}
+}